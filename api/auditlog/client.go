@@ -0,0 +1,33 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package auditlog
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client allows access to the audit log API end point.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient creates a new client for accessing the audit log API.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "AuditLog")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// Records returns the environment's audit records selected by the
+// filter, oldest first.
+func (c *Client) Records(filter params.AuditRecordFilter) ([]params.AuditRecord, error) {
+	var result params.AuditRecordsResult
+	if err := c.facade.FacadeCall("Records", filter, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return result.Records, nil
+}
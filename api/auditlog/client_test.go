@@ -0,0 +1,70 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package auditlog_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api/auditlog"
+	basetesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type auditLogMockSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&auditLogMockSuite{})
+
+func (s *auditLogMockSuite) TestRecords(c *gc.C) {
+	called := false
+	filter := params.AuditRecordFilter{User: "user-bob@local", Limit: 10}
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			called = true
+			c.Check(objType, gc.Equals, "AuditLog")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "Records")
+			c.Check(a, jc.DeepEquals, filter)
+
+			result, ok := response.(*params.AuditRecordsResult)
+			c.Assert(ok, jc.IsTrue)
+			result.Records = []params.AuditRecord{{
+				User:   "user-bob@local",
+				Facade: "Client",
+				Method: "ServiceDestroy",
+			}}
+			return nil
+		})
+	client := auditlog.NewClient(apiCaller)
+	records, err := client.Records(filter)
+	c.Assert(called, jc.IsTrue)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, jc.DeepEquals, []params.AuditRecord{{
+		User:   "user-bob@local",
+		Facade: "Client",
+		Method: "ServiceDestroy",
+	}})
+}
+
+func (s *auditLogMockSuite) TestRecordsError(c *gc.C) {
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			return errors.New("boom")
+		})
+	client := auditlog.NewClient(apiCaller)
+	_, err := client.Records(params.AuditRecordFilter{})
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
@@ -10,10 +10,22 @@ import (
 )
 
 // Create sends a request to create a backup of juju's state.  It
-// returns the metadata associated with the resulting backup.
-func (c *Client) Create(notes string) (*params.BackupsMetadataResult, error) {
+// returns the metadata associated with the resulting backup.  The
+// backup archive is encrypted with the key, if one is given.
+func (c *Client) Create(notes string, key *params.BackupsKey) (*params.BackupsMetadataResult, error) {
+	return c.create(params.BackupsCreateArgs{Notes: notes, Key: key})
+}
+
+// CreateIncremental sends a request to create an incremental backup of
+// juju's state, holding the changes since the last backup built on the
+// latest full backup.  It returns the metadata associated with the
+// resulting backup.
+func (c *Client) CreateIncremental(notes string, key *params.BackupsKey) (*params.BackupsMetadataResult, error) {
+	return c.create(params.BackupsCreateArgs{Notes: notes, Key: key, Incremental: true})
+}
+
+func (c *Client) create(args params.BackupsCreateArgs) (*params.BackupsMetadataResult, error) {
 	var result params.BackupsMetadataResult
-	args := params.BackupsCreateArgs{Notes: notes}
 	if err := c.facade.FacadeCall("Create", args, &result); err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -27,6 +27,8 @@ func (s *createSuite) TestCreate(c *gc.C) {
 			c.Assert(paramsIn, gc.FitsTypeOf, params.BackupsCreateArgs{})
 			p := paramsIn.(params.BackupsCreateArgs)
 			c.Check(p.Notes, gc.Equals, "important")
+			c.Check(p.Key, gc.IsNil)
+			c.Check(p.Incremental, jc.IsFalse)
 
 			if result, ok := resp.(*params.BackupsMetadataResult); ok {
 				*result = apiserverbackups.ResultFromMetadata(s.Meta)
@@ -39,9 +41,49 @@ func (s *createSuite) TestCreate(c *gc.C) {
 	)
 	defer cleanup()
 
-	result, err := s.client.Create("important")
+	result, err := s.client.Create("important", nil)
 	c.Assert(err, jc.ErrorIsNil)
 
 	meta := backupstesting.UpdateNotes(s.Meta, "important")
 	s.checkMetadataResult(c, result, meta)
 }
+
+func (s *createSuite) TestCreateEncrypted(c *gc.C) {
+	key := &params.BackupsKey{Passphrase: "secret"}
+	cleanup := backups.PatchClientFacadeCall(s.client,
+		func(req string, paramsIn interface{}, resp interface{}) error {
+			c.Assert(paramsIn, gc.FitsTypeOf, params.BackupsCreateArgs{})
+			c.Check(paramsIn.(params.BackupsCreateArgs).Key, gc.Equals, key)
+
+			result := resp.(*params.BackupsMetadataResult)
+			*result = apiserverbackups.ResultFromMetadata(s.Meta)
+			result.KeyFingerprint = "passphrase"
+			return nil
+		},
+	)
+	defer cleanup()
+
+	result, err := s.client.Create("", key)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result.KeyFingerprint, gc.Equals, "passphrase")
+}
+
+func (s *createSuite) TestCreateIncremental(c *gc.C) {
+	cleanup := backups.PatchClientFacadeCall(s.client,
+		func(req string, paramsIn interface{}, resp interface{}) error {
+			c.Check(req, gc.Equals, "Create")
+			c.Assert(paramsIn, gc.FitsTypeOf, params.BackupsCreateArgs{})
+			c.Check(paramsIn.(params.BackupsCreateArgs).Incremental, jc.IsTrue)
+
+			result := resp.(*params.BackupsMetadataResult)
+			*result = apiserverbackups.ResultFromMetadata(s.Meta)
+			result.Base = "20140924-010319.spam"
+			return nil
+		},
+	)
+	defer cleanup()
+
+	result, err := s.client.CreateIncremental("", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result.Base, gc.Equals, "20140924-010319.spam")
+}
@@ -61,7 +61,8 @@ func prepareRestore(newClient ClientConnection) error {
 	return errors.Annotatef(err, "could not start restore process: %v", remoteError)
 }
 
-// RestoreReader restores the contents of backupFile as backup.
+// RestoreReader restores the contents of backupFile as backup.  An
+// encrypted backup must be decrypted before it is restored.
 func (c *Client) RestoreReader(r io.Reader, meta *params.BackupsMetadataResult, newClient ClientConnection) error {
 	if err := prepareRestore(newClient); err != nil {
 		return errors.Trace(err)
@@ -75,16 +76,25 @@ func (c *Client) RestoreReader(r io.Reader, meta *params.BackupsMetadataResult,
 		logger.Errorf("could not exit restoring status: %v", finishErr)
 		return errors.Annotatef(err, "cannot upload backup file")
 	}
-	return c.restore(backupId, newClient)
+	return c.restore(backupId, time.Time{}, newClient)
 }
 
 // Restore performs restore using a backup id corresponding to a backup stored in the server.
 func (c *Client) Restore(backupId string, newClient ClientConnection) error {
+	return c.RestoreUntil(backupId, time.Time{}, newClient)
+}
+
+// RestoreUntil performs restore using a backup id corresponding to a
+// backup stored in the server, replaying the changes recorded by the
+// backup and by the incremental backups built on it up to the given
+// point in time.  All the changes recorded by the backup are restored
+// if until is zero.  Encrypted backups cannot be restored by id.
+func (c *Client) RestoreUntil(backupId string, until time.Time, newClient ClientConnection) error {
 	if err := prepareRestore(newClient); err != nil {
 		return errors.Trace(err)
 	}
 	logger.Debugf("Server in 'about to restore' mode")
-	return c.restore(backupId, newClient)
+	return c.restore(backupId, until, newClient)
 }
 
 func restoreAttempt(client *Client, closer closerFunc, restoreArgs params.RestoreArgs) (error, error) {
@@ -97,15 +107,17 @@ func restoreAttempt(client *Client, closer closerFunc, restoreArgs params.Restor
 // restore is responsible for triggering the whole restore process in a remote
 // machine. The backup information for the process should already be in the
 // server and loaded in the backup storage under the backupId id.
-// It takes backupId as the identifier for the remote backup file and a
-// client connection factory newClient (newClient should no longer be
-// necessary when lp:1399722 is sorted out).
-func (c *Client) restore(backupId string, newClient ClientConnection) error {
+// It takes backupId as the identifier for the remote backup file, the
+// point in time to restore until, if any, and a client connection
+// factory newClient (newClient should no longer be necessary when
+// lp:1399722 is sorted out).
+func (c *Client) restore(backupId string, until time.Time, newClient ClientConnection) error {
 	var err, remoteError error
 
 	// Restore
 	restoreArgs := params.RestoreArgs{
 		BackupId: backupId,
+		Until:    until,
 	}
 
 	for a := restoreStrategy.Start(); a.Next(); {
@@ -0,0 +1,27 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Schedule returns the schedule of automatic backups.
+func (c *Client) Schedule() (*params.BackupsSchedule, error) {
+	var result params.BackupsSchedule
+	if err := c.facade.FacadeCall("Schedule", nil, &result); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &result, nil
+}
+
+// SetSchedule replaces the schedule of automatic backups.
+func (c *Client) SetSchedule(schedule params.BackupsSchedule) error {
+	if err := c.facade.FacadeCall("SetSchedule", schedule, nil); err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api/backups"
+	"github.com/juju/juju/apiserver/params"
+)
+
+type scheduleSuite struct {
+	backupsSuite
+}
+
+var _ = gc.Suite(&scheduleSuite{})
+
+func (s *scheduleSuite) TestSchedule(c *gc.C) {
+	expected := params.BackupsSchedule{
+		Interval:   24 * time.Hour,
+		KeepDaily:  7,
+		KeepWeekly: 4,
+	}
+	cleanup := backups.PatchClientFacadeCall(s.client,
+		func(req string, paramsIn interface{}, resp interface{}) error {
+			c.Check(req, gc.Equals, "Schedule")
+			c.Check(paramsIn, gc.IsNil)
+
+			if result, ok := resp.(*params.BackupsSchedule); ok {
+				*result = expected
+			} else {
+				c.Fatalf("wrong output structure")
+			}
+			return nil
+		},
+	)
+	defer cleanup()
+
+	result, err := s.client.Schedule()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result, jc.DeepEquals, &expected)
+}
+
+func (s *scheduleSuite) TestSetSchedule(c *gc.C) {
+	schedule := params.BackupsSchedule{Interval: 12 * time.Hour, KeepDaily: 3}
+	cleanup := backups.PatchClientFacadeCall(s.client,
+		func(req string, paramsIn interface{}, resp interface{}) error {
+			c.Check(req, gc.Equals, "SetSchedule")
+			c.Check(paramsIn, jc.DeepEquals, schedule)
+			c.Check(resp, gc.IsNil)
+			return nil
+		},
+	)
+	defer cleanup()
+
+	err := s.client.SetSchedule(schedule)
+	c.Assert(err, jc.ErrorIsNil)
+}
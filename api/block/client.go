@@ -4,6 +4,8 @@
 package block
 
 import (
+	"time"
+
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
 
@@ -49,9 +51,21 @@ func (c *Client) List() ([]params.Block, error) {
 // SwitchBlockOn switches desired block on for the current environment.
 // Valid block types are "BlockDestroy", "BlockRemove" and "BlockChange".
 func (c *Client) SwitchBlockOn(blockType, msg string) error {
+	return c.SwitchEntityBlockOn(blockType, "", msg, 0)
+}
+
+// SwitchEntityBlockOn switches desired block on for the service or
+// machine with the given tag, or for the current environment if the
+// tag is empty. If ttl is positive, the block switches itself off
+// once it has passed.
+// Valid block types are "BlockRemove" and "BlockChange", and also
+// "BlockDestroy" for the environment.
+func (c *Client) SwitchEntityBlockOn(blockType, tag, msg string, ttl time.Duration) error {
 	args := params.BlockSwitchParams{
 		Type:    blockType,
 		Message: msg,
+		Tag:     tag,
+		TTL:     ttl,
 	}
 	result := params.ErrorResult{}
 	if err := c.facade.FacadeCall("SwitchBlockOn", args, &result); err != nil {
@@ -66,8 +80,16 @@ func (c *Client) SwitchBlockOn(blockType, msg string) error {
 // SwitchBlockOff switches desired block off for the current environment.
 // Valid block types are "BlockDestroy", "BlockRemove" and "BlockChange".
 func (c *Client) SwitchBlockOff(blockType string) error {
+	return c.SwitchEntityBlockOff(blockType, "")
+}
+
+// SwitchEntityBlockOff switches desired block off for the service or
+// machine with the given tag, or for the current environment if the
+// tag is empty.
+func (c *Client) SwitchEntityBlockOff(blockType, tag string) error {
 	args := params.BlockSwitchParams{
 		Type: blockType,
+		Tag:  tag,
 	}
 	result := params.ErrorResult{}
 	if err := c.facade.FacadeCall("SwitchBlockOff", args, &result); err != nil {
@@ -4,6 +4,8 @@
 package block_test
 
 import (
+	"time"
+
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
@@ -168,3 +170,55 @@ func (s *blockMockSuite) TestList(c *gc.C) {
 	c.Assert(errors.Cause(err), gc.ErrorMatches, errmsg)
 	c.Assert(found, gc.HasLen, 1)
 }
+
+func (s *blockMockSuite) TestSwitchEntityBlockOn(c *gc.C) {
+	called := false
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			called = true
+			c.Check(objType, gc.Equals, "Block")
+			c.Check(request, gc.Equals, "SwitchBlockOn")
+			c.Check(a, jc.DeepEquals, params.BlockSwitchParams{
+				Type:    state.RemoveBlock.String(),
+				Message: "keep mysql",
+				Tag:     "service-mysql",
+				TTL:     time.Hour,
+			})
+			_, ok := response.(*params.ErrorResult)
+			c.Assert(ok, jc.IsTrue)
+			return nil
+		})
+	blockClient := block.NewClient(apiCaller)
+	err := blockClient.SwitchEntityBlockOn(state.RemoveBlock.String(), "service-mysql", "keep mysql", time.Hour)
+	c.Assert(called, jc.IsTrue)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *blockMockSuite) TestSwitchEntityBlockOff(c *gc.C) {
+	called := false
+	apiCaller := basetesting.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, response interface{},
+		) error {
+			called = true
+			c.Check(objType, gc.Equals, "Block")
+			c.Check(request, gc.Equals, "SwitchBlockOff")
+			c.Check(a, jc.DeepEquals, params.BlockSwitchParams{
+				Type: state.RemoveBlock.String(),
+				Tag:  "machine-1",
+			})
+			_, ok := response.(*params.ErrorResult)
+			c.Assert(ok, jc.IsTrue)
+			return nil
+		})
+	blockClient := block.NewClient(apiCaller)
+	err := blockClient.SwitchEntityBlockOff(state.RemoveBlock.String(), "machine-1")
+	c.Assert(called, jc.IsTrue)
+	c.Assert(err, jc.ErrorIsNil)
+}
@@ -204,6 +204,20 @@ func (c *Client) UnitStatusHistory(kind params.HistoryKind, unitName string, siz
 	return &results, nil
 }
 
+// StatusHistory retrieves the status history of a unit, machine,
+// service, volume or filesystem, oldest first, as args specify.
+func (c *Client) StatusHistory(args params.StatusHistoryArgs) (*params.StatusHistoryResult, error) {
+	var results params.StatusHistoryResult
+	err := c.facade.FacadeCall("StatusHistory", args, &results)
+	if err != nil {
+		if params.IsCodeNotImplemented(err) {
+			return &params.StatusHistoryResult{}, errors.NotImplementedf("StatusHistory")
+		}
+		return &params.StatusHistoryResult{}, errors.Trace(err)
+	}
+	return &results, nil
+}
+
 // LegacyStatus is a stub version of Status that 1.16 introduced. Should be
 // removed along with structs when api versioning makes it safe to do so.
 func (c *Client) LegacyStatus() (*params.LegacyStatus, error) {
@@ -560,12 +574,21 @@ func (c *Client) EnvironmentUUID() string {
 
 // ShareEnvironment allows the given users access to the environment.
 func (c *Client) ShareEnvironment(users ...names.UserTag) error {
+	return c.ShareEnvironmentWithAccess("", users...)
+}
+
+// ShareEnvironmentWithAccess allows the given users the given access
+// ("read", "write" or "admin") to the environment. Users already
+// sharing the environment have their access changed. An empty access
+// gives new users write access, as ShareEnvironment does.
+func (c *Client) ShareEnvironmentWithAccess(access string, users ...names.UserTag) error {
 	var args params.ModifyEnvironUsers
 	for _, user := range users {
 		if &user != nil {
 			args.Changes = append(args.Changes, params.ModifyEnvironUser{
 				UserTag: user.String(),
 				Action:  params.AddEnvUser,
+				Access:  access,
 			})
 		}
 	}
@@ -1070,6 +1093,25 @@ type DebugLogParams struct {
 	// Replay tells the server to start at the start of the log file rather
 	// than the end. If replay is true, backlog is ignored.
 	Replay bool
+	// StartTime, if set, tells the server to only send lines logged at or
+	// after it. Like replay, backlog is then ignored.
+	StartTime time.Time
+	// EndTime, if set, tells the server to only send lines logged before
+	// it, and to close the connection once it has passed.
+	EndTime time.Time
+	// MessageRegexp, if set, tells the server to only send lines whose
+	// message matches the regular expression.
+	MessageRegexp string
+	// NoTail tells the server to close the connection once it has sent
+	// the matching lines already logged, rather than waiting for more.
+	NoTail bool
+	// Format tells the server how to send each log message, either as a
+	// line of text ("text", the default) or as a line holding a
+	// params.DebugLogRecord JSON object ("json").
+	Format string
+	// Fields, if set, tells the server to only send these fields of each
+	// JSON object. It may only be set if Format is "json".
+	Fields []string
 }
 
 // WatchDebugLog returns a ReadCloser that the caller can read the log
@@ -1106,6 +1148,22 @@ func (c *Client) WatchDebugLog(args DebugLogParams) (io.ReadCloser, error) {
 	attrs["includeModule"] = args.IncludeModule
 	attrs["excludeEntity"] = args.ExcludeEntity
 	attrs["excludeModule"] = args.ExcludeModule
+	if !args.StartTime.IsZero() {
+		attrs.Set("startTime", args.StartTime.UTC().Format(time.RFC3339Nano))
+	}
+	if !args.EndTime.IsZero() {
+		attrs.Set("endTime", args.EndTime.UTC().Format(time.RFC3339Nano))
+	}
+	if args.MessageRegexp != "" {
+		attrs.Set("message", args.MessageRegexp)
+	}
+	if args.NoTail {
+		attrs.Set("noTail", fmt.Sprint(args.NoTail))
+	}
+	if args.Format != "" {
+		attrs.Set("format", args.Format)
+	}
+	attrs["fields"] = args.Fields
 
 	path := "/log"
 	if _, ok := c.st.ServerVersion(); ok {
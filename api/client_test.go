@@ -15,6 +15,7 @@ import (
 	"net/url"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
@@ -310,6 +311,36 @@ func (s *clientSuite) TestShareEnvironmentThreeUsers(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, `existing user`)
 }
 
+func (s *clientSuite) TestShareEnvironmentWithAccess(c *gc.C) {
+	client := s.APIState.Client()
+	user := names.NewUserTag("foo@bar")
+	var called bool
+	cleanup := api.PatchClientFacadeCall(client,
+		func(request string, paramsIn interface{}, response interface{}) error {
+			called = true
+			c.Assert(request, gc.Equals, "ShareEnvironment")
+			c.Assert(paramsIn, jc.DeepEquals, params.ModifyEnvironUsers{
+				Changes: []params.ModifyEnvironUser{{
+					UserTag: user.String(),
+					Action:  params.AddEnvUser,
+					Access:  "read",
+				}},
+			})
+			if result, ok := response.(*params.ErrorResults); ok {
+				*result = params.ErrorResults{Results: []params.ErrorResult{{}}}
+			} else {
+				c.Fatalf("wrong output structure")
+			}
+			return nil
+		},
+	)
+	defer cleanup()
+
+	err := client.ShareEnvironmentWithAccess("read", user)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+}
+
 func (s *clientSuite) TestUnshareEnvironmentThreeUsers(c *gc.C) {
 	client := s.APIState.Client()
 	missingUser := s.Factory.MakeEnvUser(c, nil)
@@ -460,6 +491,50 @@ func (s *clientSuite) TestParamsEncoded(c *gc.C) {
 	})
 }
 
+func (s *clientSuite) TestTimeRangeParamsEncoded(c *gc.C) {
+	s.PatchValue(api.WebsocketDialConfig, echoURL(c))
+
+	params := api.DebugLogParams{
+		StartTime:     time.Date(2015, 6, 19, 15, 0, 0, 0, time.UTC),
+		EndTime:       time.Date(2015, 6, 19, 16, 30, 0, 0, time.FixedZone("", 3600)),
+		MessageRegexp: "hook .* failed",
+		NoTail:        true,
+	}
+
+	client := s.APIState.Client()
+	reader, err := client.WatchDebugLog(params)
+	c.Assert(err, jc.ErrorIsNil)
+
+	connectURL := connectURLFromReader(c, reader)
+	values := connectURL.Query()
+	c.Assert(values, jc.DeepEquals, url.Values{
+		"startTime": {"2015-06-19T15:00:00Z"},
+		"endTime":   {"2015-06-19T15:30:00Z"},
+		"message":   {"hook .* failed"},
+		"noTail":    {"true"},
+	})
+}
+
+func (s *clientSuite) TestFormatParamsEncoded(c *gc.C) {
+	s.PatchValue(api.WebsocketDialConfig, echoURL(c))
+
+	params := api.DebugLogParams{
+		Format: "json",
+		Fields: []string{"entity", "message"},
+	}
+
+	client := s.APIState.Client()
+	reader, err := client.WatchDebugLog(params)
+	c.Assert(err, jc.ErrorIsNil)
+
+	connectURL := connectURLFromReader(c, reader)
+	values := connectURL.Query()
+	c.Assert(values, jc.DeepEquals, url.Values{
+		"format": {"json"},
+		"fields": {"entity", "message"},
+	})
+}
+
 func (s *clientSuite) TestDebugLogRootPath(c *gc.C) {
 	s.PatchValue(api.WebsocketDialConfig, echoURL(c))
 
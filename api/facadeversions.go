@@ -17,6 +17,7 @@ var facadeVersions = map[string]int{
 	"AllWatcher":                   0,
 	"AllEnvWatcher":                1,
 	"Annotations":                  1,
+	"AuditLog":                     1,
 	"Backups":                      0,
 	"Block":                        1,
 	"Charms":                       1,
@@ -43,6 +44,7 @@ var facadeVersions = map[string]int{
 	"MetricsManager":               0,
 	"Networker":                    0,
 	"NotifyWatcher":                0,
+	"Orphans":                      1,
 	"Pinger":                       0,
 	"Provisioner":                  1,
 	"Reboot":                       1,
@@ -0,0 +1,34 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package orphans
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/apiserver/params"
+)
+
+// Client provides access to the orphaned provider resources of an
+// environment.
+type Client struct {
+	base.ClientFacade
+	facade base.FacadeCaller
+}
+
+// NewClient returns a new orphans client.
+func NewClient(st base.APICallCloser) *Client {
+	frontend, backend := base.NewClientFacade(st, "Orphans")
+	return &Client{ClientFacade: frontend, facade: backend}
+}
+
+// List returns the orphaned provider resources of the environment,
+// which the orphan collector would remove.
+func (c *Client) List() ([]params.Orphan, error) {
+	var out params.OrphansResults
+	if err := c.facade.FacadeCall("List", nil, &out); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return out.Orphans, nil
+}
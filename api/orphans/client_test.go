@@ -0,0 +1,60 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package orphans_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/orphans"
+	"github.com/juju/juju/apiserver/params"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type orphansSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&orphansSuite{})
+
+func (s *orphansSuite) TestList(c *gc.C) {
+	called := false
+	apiCaller := testing.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			called = true
+			c.Check(objType, gc.Equals, "Orphans")
+			c.Check(id, gc.Equals, "")
+			c.Check(request, gc.Equals, "List")
+			c.Check(a, gc.IsNil)
+			*(result.(*params.OrphansResults)) = params.OrphansResults{
+				Orphans: []params.Orphan{{Kind: "server", Id: "uuid-0"}},
+			}
+			return nil
+		})
+	client := orphans.NewClient(apiCaller)
+	found, err := client.List()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+	c.Assert(found, jc.DeepEquals, []params.Orphan{{Kind: "server", Id: "uuid-0"}})
+}
+
+func (s *orphansSuite) TestListFacadeCallError(c *gc.C) {
+	apiCaller := testing.APICallerFunc(
+		func(objType string,
+			version int,
+			id, request string,
+			a, result interface{},
+		) error {
+			return errors.New("boom")
+		})
+	client := orphans.NewClient(apiCaller)
+	_, err := client.List()
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
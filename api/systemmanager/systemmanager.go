@@ -82,6 +82,15 @@ func (c *Client) ListBlockedEnvironments() ([]params.EnvironmentBlockInfo, error
 	return result.Environments, err
 }
 
+// LogPruneStats returns the stats of the pruning of the log records
+// stored in the database of the system, or nil if the logs have not
+// been pruned.
+func (c *Client) LogPruneStats() (*params.LogPruneStats, error) {
+	result := params.LogPruneStatsResult{}
+	err := c.facade.FacadeCall("LogPruneStats", nil, &result)
+	return result.Stats, err
+}
+
 // RemoveBlocks removes all the blocks in the system.
 func (c *Client) RemoveBlocks() error {
 	args := params.RemoveBlocksArgs{All: true}
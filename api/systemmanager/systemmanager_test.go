@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/juju/loggo"
 	"github.com/juju/names"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
@@ -103,6 +104,30 @@ func (s *systemManagerSuite) TestListBlockedEnvironments(c *gc.C) {
 	})
 }
 
+func (s *systemManagerSuite) TestLogPruneStats(c *gc.C) {
+	sysManager := s.OpenAPI(c)
+	stats, err := sysManager.LogPruneStats()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(stats, gc.IsNil)
+
+	now := time.Now().Truncate(time.Millisecond)
+	dbLogger := state.NewDbLogger(s.State, names.NewMachineTag("0"))
+	defer dbLogger.Close()
+	err = dbLogger.Log(now.Add(-2*time.Hour), "module", "loc", loggo.INFO, "old")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = state.PruneLogsWithPolicy(s.State, now, state.LogPrunePolicy{
+		MaxAge:          time.Hour,
+		MaxCollectionMB: 100,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	stats, err = sysManager.LogPruneStats()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(stats, gc.NotNil)
+	c.Assert(stats.Time.Equal(now), jc.IsTrue)
+	c.Assert(stats.Removed, gc.Equals, params.LogPruneCounts{Age: 1})
+}
+
 func (s *systemManagerSuite) TestRemoveBlocks(c *gc.C) {
 	s.State.SwitchBlockOn(state.DestroyBlock, "TestBlockDestroyEnvironment")
 	s.State.SwitchBlockOn(state.ChangeBlock, "TestChangeBlock")
@@ -6,6 +6,7 @@ package usermanager
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
@@ -33,11 +34,21 @@ func NewClient(st base.APICallCloser) *Client {
 
 // AddUser creates a new local user in the juju server.
 func (c *Client) AddUser(username, displayName, password string) (names.UserTag, error) {
-	if !names.IsValidUser(username) {
-		return names.UserTag{}, fmt.Errorf("invalid user name %q", username)
+	return c.addUser(params.AddUser{Username: username, DisplayName: displayName, Password: password})
+}
+
+// AddExternalUser creates a new local user in the juju server whose
+// password is checked by the named identity provider, such as "ldap".
+func (c *Client) AddExternalUser(username, displayName, identityProvider string) (names.UserTag, error) {
+	return c.addUser(params.AddUser{Username: username, DisplayName: displayName, IdentityProvider: identityProvider})
+}
+
+func (c *Client) addUser(arg params.AddUser) (names.UserTag, error) {
+	if !names.IsValidUser(arg.Username) {
+		return names.UserTag{}, fmt.Errorf("invalid user name %q", arg.Username)
 	}
 	userArgs := params.AddUsers{
-		Users: []params.AddUser{{Username: username, DisplayName: displayName, Password: password}},
+		Users: []params.AddUser{arg},
 	}
 	var results params.AddUserResults
 	err := c.facade.FacadeCall("AddUser", userArgs, &results)
@@ -166,3 +177,62 @@ func (c *Client) SetPassword(username, password string) error {
 	}
 	return results.OneError()
 }
+
+// AddAPIToken adds an API token for the current user, which they may
+// log in to the environment with in place of their password until it
+// expires. The calls made with the token are limited to those that do
+// not change the environment if readOnly is true, and to those on the
+// given facades if there are any. The token is returned with the
+// credentials to log in with, which cannot be had again.
+func (c *Client) AddAPIToken(expires time.Time, readOnly bool, facades []string) (params.APIToken, string, error) {
+	args := params.AddAPITokens{
+		Tokens: []params.AddAPIToken{{
+			Expires:  expires,
+			ReadOnly: readOnly,
+			Facades:  facades,
+		}},
+	}
+	var results params.AddAPITokenResults
+	if err := c.facade.FacadeCall("AddAPITokens", args, &results); err != nil {
+		return params.APIToken{}, "", errors.Trace(err)
+	}
+	if count := len(results.Results); count != 1 {
+		return params.APIToken{}, "", errors.Errorf("expected 1 result, got %d", count)
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return params.APIToken{}, "", errors.Trace(result.Error)
+	}
+	if result.Token == nil {
+		return params.APIToken{}, "", errors.New("no token returned")
+	}
+	return *result.Token, result.Credentials, nil
+}
+
+// APITokens returns the current user's API tokens, including those
+// that have expired.
+func (c *Client) APITokens() ([]params.APIToken, error) {
+	var results params.APITokensResults
+	if err := c.facade.FacadeCall("APITokens", params.Entities{}, &results); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if count := len(results.Results); count != 1 {
+		return nil, errors.Errorf("expected 1 result, got %d", count)
+	}
+	result := results.Results[0]
+	if result.Error != nil {
+		return nil, errors.Trace(result.Error)
+	}
+	return result.Result, nil
+}
+
+// RevokeAPIToken revokes the API token with the given id, so that it
+// is no longer accepted.
+func (c *Client) RevokeAPIToken(id string) error {
+	args := params.APITokenIds{Ids: []string{id}}
+	var results params.ErrorResults
+	if err := c.facade.FacadeCall("RevokeAPITokens", args, &results); err != nil {
+		return errors.Trace(err)
+	}
+	return results.OneError()
+}
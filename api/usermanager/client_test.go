@@ -4,7 +4,10 @@
 package usermanager_test
 
 import (
+	"time"
+
 	"github.com/juju/errors"
+	"github.com/juju/names"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
@@ -39,6 +42,17 @@ func (s *usermanagerSuite) TestAddUser(c *gc.C) {
 	c.Assert(user.PasswordValid("password"), jc.IsTrue)
 }
 
+func (s *usermanagerSuite) TestAddExternalUser(c *gc.C) {
+	tag, err := s.usermanager.AddExternalUser("foobar", "Foo Bar", "ldap")
+	c.Assert(err, jc.ErrorIsNil)
+
+	user, err := s.State.User(tag)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.Name(), gc.Equals, "foobar")
+	c.Assert(user.DisplayName(), gc.Equals, "Foo Bar")
+	c.Assert(user.IdentityProvider(), gc.Equals, "ldap")
+}
+
 func (s *usermanagerSuite) TestAddExistingUser(c *gc.C) {
 	s.Factory.MakeUser(c, &factory.UserParams{Name: "foobar"})
 
@@ -177,3 +191,31 @@ func (s *usermanagerSuite) TestSetUserPasswordBadName(c *gc.C) {
 	err := s.usermanager.SetPassword("not@home", "new-password")
 	c.Assert(err, gc.ErrorMatches, `"not@home" is not a valid username`)
 }
+
+func (s *usermanagerSuite) TestAPITokens(c *gc.C) {
+	token, credentials, err := s.usermanager.AddAPIToken(time.Now().Add(time.Hour), true, []string{"Client"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(token.User, gc.Equals, names.NewUserTag(s.AdminUserTag(c).Username()).String())
+	c.Assert(token.ReadOnly, jc.IsTrue)
+	c.Assert(token.Facades, jc.DeepEquals, []string{"Client"})
+	c.Assert(credentials, gc.Not(gc.Equals), "")
+
+	tokens, err := s.usermanager.APITokens()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(tokens, gc.HasLen, 1)
+	c.Assert(tokens[0].Id, gc.Equals, token.Id)
+
+	err = s.usermanager.RevokeAPIToken(token.Id)
+	c.Assert(err, jc.ErrorIsNil)
+	tokens, err = s.usermanager.APITokens()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(tokens, gc.HasLen, 0)
+
+	err = s.usermanager.RevokeAPIToken(token.Id)
+	c.Assert(err, gc.ErrorMatches, `API token ".*" not found`)
+}
+
+func (s *usermanagerSuite) TestAddAPITokenExpired(c *gc.C) {
+	_, _, err := s.usermanager.AddAPIToken(time.Now().Add(-time.Hour), false, nil)
+	c.Assert(err, gc.ErrorMatches, "failed to add API token: expiry time .* in the past not valid")
+}
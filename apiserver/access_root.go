@@ -0,0 +1,160 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"github.com/juju/utils/set"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/rpc/rpcreflect"
+	"github.com/juju/juju/state"
+)
+
+// accessRoot restricts the API calls an environment user may make to
+// those allowed by their access level to the environment.
+type accessRoot struct {
+	rpc.MethodFinder
+	access state.EnvironmentAccess
+}
+
+// newAccessRoot returns a new accessRoot for a user with the given
+// access to the environment.
+func newAccessRoot(finder rpc.MethodFinder, access state.EnvironmentAccess) *accessRoot {
+	return &accessRoot{
+		MethodFinder: finder,
+		access:       access,
+	}
+}
+
+// readOnlyCalls are the API calls users with read access may make.
+// None of them change the environment.
+var readOnlyCalls = set.NewStrings(
+	"Action.Actions",
+	"Action.FindActionTagsByPrefix",
+	"Action.ListAll",
+	"Action.ListCompleted",
+	"Action.ListPending",
+	"Action.ListRunning",
+	"Action.ServicesCharmActions",
+	"AllWatcher.Next",
+	"AllWatcher.Stop",
+	"Annotations.Get",
+	"Block.List",
+	"Charms.CharmInfo",
+	"Charms.IsMetered",
+	"Charms.List",
+	"Client.APIHostPorts",
+	"Client.AgentVersion",
+	"Client.CharmInfo",
+	"Client.EnvUserInfo",
+	"Client.EnvironmentGet",
+	"Client.EnvironmentInfo",
+	"Client.FindTools",
+	"Client.FullStatus",
+	"Client.GetAnnotations",
+	"Client.GetEnvironmentConstraints",
+	"Client.GetServiceConstraints",
+	"Client.PrivateAddress",
+	"Client.PublicAddress",
+	"Client.ResolveCharms",
+	"Client.ServiceCharmRelations",
+	"Client.ServiceGet",
+	"Client.ServiceGetCharmURL",
+	"Client.Status",
+	"Client.StatusHistory",
+	"Client.UnitStatusHistory",
+	"Client.WatchAll",
+	"ImageManager.ListImages",
+	"ImageMetadata.List",
+	"KeyManager.ListKeys",
+	"Orphans.List",
+	"Pinger.Ping",
+	"Pinger.Stop",
+	"Spaces.ListSpaces",
+	"Storage.List",
+	"Storage.ListPools",
+	"Storage.ListVolumes",
+	"Storage.Show",
+	"Subnets.AllSpaces",
+	"Subnets.AllZones",
+	"Subnets.ListSubnets",
+	// Users may always change their own password and manage their
+	// own API tokens. Only admins may change the passwords of others,
+	// as the user manager checks.
+	"UserManager.APITokens",
+	"UserManager.AddAPITokens",
+	"UserManager.RevokeAPITokens",
+	"UserManager.SetPassword",
+	"UserManager.UserInfo",
+)
+
+// adminOnlyCalls are the API calls only users with admin access may
+// make: those managing users and who may access the environment, those
+// changing the environment config, and those handling backups, which
+// hold the secrets of the environment. Users with write access may make
+// any other call.
+var adminOnlyCalls = set.NewStrings(
+	"AuditLog.Records",
+	"Backups.Create",
+	"Backups.FinishRestore",
+	"Backups.Info",
+	"Backups.List",
+	"Backups.PrepareRestore",
+	"Backups.Remove",
+	"Backups.Restore",
+	"Backups.Schedule",
+	"Backups.SetSchedule",
+	"Client.DestroyEnvironment",
+	"Client.EnvironmentSet",
+	"Client.EnvironmentUnset",
+	"Client.ShareEnvironment",
+	"UserManager.AddUser",
+	"UserManager.DisableUser",
+	"UserManager.EnableUser",
+)
+
+// FindMethod returns common.ErrPerm if the user's access to the
+// environment does not allow them to make the call.
+func (r *accessRoot) FindMethod(rootName string, version int, methodName string) (rpcreflect.MethodCaller, error) {
+	// The lookup of the name is done first to return a not found error if the
+	// user is looking for a method that we just don't have.
+	caller, err := r.MethodFinder.FindMethod(rootName, version, methodName)
+	if err != nil {
+		return nil, err
+	}
+	if !isCallAllowed(r.access, rootName, methodName) {
+		return nil, common.ErrPerm
+	}
+	return caller, nil
+}
+
+// accessLevels orders the access levels, from the least to the most
+// privileged.
+var accessLevels = map[state.EnvironmentAccess]int{
+	state.EnvironmentReadAccess:  1,
+	state.EnvironmentWriteAccess: 2,
+	state.EnvironmentAdminAccess: 3,
+}
+
+// hasAccess reports whether the given access to the environment
+// includes the needed one.
+func hasAccess(access, need state.EnvironmentAccess) bool {
+	return accessLevels[access] >= accessLevels[need]
+}
+
+// isCallAllowed reports whether a user with the given access to the
+// environment may call the facade method.
+func isCallAllowed(access state.EnvironmentAccess, rootName, methodName string) bool {
+	fullName := rootName + "." + methodName
+	switch access {
+	case state.EnvironmentAdminAccess:
+		return true
+	case state.EnvironmentWriteAccess:
+		return !adminOnlyCalls.Contains(fullName)
+	case state.EnvironmentReadAccess:
+		return readOnlyCalls.Contains(fullName)
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing"
+)
+
+type accessRootSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&accessRootSuite{})
+
+func (r *accessRootSuite) assertMethodAllowed(c *gc.C, access state.EnvironmentAccess, rootName string, version int, method string) {
+	root := apiserver.TestingAccessRoot(nil, access)
+	caller, err := root.FindMethod(rootName, version, method)
+	c.Check(err, jc.ErrorIsNil)
+	c.Check(caller, gc.NotNil)
+}
+
+func (r *accessRootSuite) assertMethodDenied(c *gc.C, access state.EnvironmentAccess, rootName string, version int, method string) {
+	root := apiserver.TestingAccessRoot(nil, access)
+	caller, err := root.FindMethod(rootName, version, method)
+	c.Check(err, gc.Equals, common.ErrPerm)
+	c.Check(caller, gc.IsNil)
+}
+
+func (r *accessRootSuite) TestReadAccess(c *gc.C) {
+	r.assertMethodAllowed(c, state.EnvironmentReadAccess, "Client", 0, "FullStatus")
+	r.assertMethodAllowed(c, state.EnvironmentReadAccess, "Client", 0, "WatchAll")
+	r.assertMethodAllowed(c, state.EnvironmentReadAccess, "Client", 0, "StatusHistory")
+	r.assertMethodAllowed(c, state.EnvironmentReadAccess, "AllWatcher", 0, "Next")
+	r.assertMethodAllowed(c, state.EnvironmentReadAccess, "Pinger", 0, "Ping")
+	r.assertMethodAllowed(c, state.EnvironmentReadAccess, "UserManager", 0, "UserInfo")
+	r.assertMethodAllowed(c, state.EnvironmentReadAccess, "UserManager", 0, "AddAPITokens")
+	r.assertMethodAllowed(c, state.EnvironmentReadAccess, "UserManager", 0, "RevokeAPITokens")
+
+	r.assertMethodDenied(c, state.EnvironmentReadAccess, "Client", 0, "ServiceDeploy")
+	r.assertMethodDenied(c, state.EnvironmentReadAccess, "Client", 0, "DestroyEnvironment")
+	r.assertMethodDenied(c, state.EnvironmentReadAccess, "Client", 0, "ShareEnvironment")
+	r.assertMethodDenied(c, state.EnvironmentReadAccess, "Client", 0, "AddMachinesV2")
+	r.assertMethodDenied(c, state.EnvironmentReadAccess, "Backups", 0, "Create")
+}
+
+func (r *accessRootSuite) TestWriteAccess(c *gc.C) {
+	r.assertMethodAllowed(c, state.EnvironmentWriteAccess, "Client", 0, "FullStatus")
+	r.assertMethodAllowed(c, state.EnvironmentWriteAccess, "Client", 0, "ServiceDeploy")
+	r.assertMethodAllowed(c, state.EnvironmentWriteAccess, "Client", 0, "AddMachinesV2")
+
+	r.assertMethodAllowed(c, state.EnvironmentWriteAccess, "UserManager", 0, "SetPassword")
+
+	r.assertMethodDenied(c, state.EnvironmentWriteAccess, "Client", 0, "DestroyEnvironment")
+	r.assertMethodDenied(c, state.EnvironmentWriteAccess, "Client", 0, "ShareEnvironment")
+	r.assertMethodDenied(c, state.EnvironmentWriteAccess, "Client", 0, "EnvironmentSet")
+	r.assertMethodDenied(c, state.EnvironmentWriteAccess, "Client", 0, "EnvironmentUnset")
+	r.assertMethodDenied(c, state.EnvironmentWriteAccess, "UserManager", 0, "AddUser")
+	r.assertMethodDenied(c, state.EnvironmentWriteAccess, "UserManager", 0, "DisableUser")
+	r.assertMethodDenied(c, state.EnvironmentWriteAccess, "UserManager", 0, "EnableUser")
+	r.assertMethodDenied(c, state.EnvironmentWriteAccess, "Backups", 0, "Create")
+	r.assertMethodDenied(c, state.EnvironmentWriteAccess, "Backups", 0, "List")
+}
+
+func (r *accessRootSuite) TestAdminAccess(c *gc.C) {
+	r.assertMethodAllowed(c, state.EnvironmentAdminAccess, "Client", 0, "FullStatus")
+	r.assertMethodAllowed(c, state.EnvironmentAdminAccess, "Client", 0, "ServiceDeploy")
+	r.assertMethodAllowed(c, state.EnvironmentAdminAccess, "Client", 0, "DestroyEnvironment")
+	r.assertMethodAllowed(c, state.EnvironmentAdminAccess, "Client", 0, "ShareEnvironment")
+	r.assertMethodAllowed(c, state.EnvironmentAdminAccess, "Client", 0, "EnvironmentSet")
+	r.assertMethodAllowed(c, state.EnvironmentAdminAccess, "UserManager", 0, "AddUser")
+	r.assertMethodAllowed(c, state.EnvironmentAdminAccess, "Backups", 0, "Create")
+}
+
+func (r *accessRootSuite) TestFindNonExistentMethod(c *gc.C) {
+	root := apiserver.TestingAccessRoot(nil, state.EnvironmentReadAccess)
+	caller, err := root.FindMethod("Client", 0, "Bar")
+
+	c.Assert(err, gc.ErrorMatches, `no such request - method Client\(0\).Bar is not implemented`)
+	c.Assert(caller, gc.IsNil)
+}
@@ -162,6 +162,9 @@ func (a *admin) doLogin(req params.LoginRequest, loginVersion int) (params.Login
 		ServerVersion: version.Current.Number.String(),
 	}
 
+	// unrestrictedApi finds the calls the restrictions below refuse.
+	unrestrictedApi := authedApi
+
 	// For sufficiently modern login versions, stop serving the
 	// state server environment at the root of the API.
 	if serverOnlyLogin {
@@ -177,6 +180,24 @@ func (a *admin) doLogin(req params.LoginRequest, loginVersion int) (params.Login
 			}
 		}
 		loginResult.Facades = facades
+	} else if isUser {
+		// Limit the calls users may make to those their access to
+		// the environment allows.
+		envUser, err := a.root.state.EnvironmentUser(entity.Tag().(names.UserTag))
+		if err != nil {
+			return fail, errors.Annotate(err, "cannot get environment user")
+		}
+		authedApi = newAccessRoot(authedApi, envUser.Access())
+		if tokenID, _, ok := state.ParseAPITokenCredentials(req.Credentials); ok {
+			// Limit the calls to those the token allows, for as
+			// long as it does.
+			authedApi = newTokenRoot(authedApi, a.root.state, tokenID)
+		}
+	}
+	if userTag, ok := entity.Tag().(names.UserTag); ok {
+		// Record the calls users make that may change things,
+		// including those they are not allowed to make.
+		authedApi = newAuditRoot(authedApi, unrestrictedApi, a.root.state, userTag)
 	}
 
 	a.root.rpcConn.ServeFinder(authedApi, serverError)
@@ -239,7 +260,21 @@ func checkCreds(st *state.State, req params.LoginRequest, lookForEnvUser bool) (
 	if err != nil {
 		return nil, nil, err
 	}
+	_, _, isToken := state.ParseAPITokenCredentials(req.Credentials)
+	if isToken && !lookForEnvUser {
+		// API tokens are only accepted by the environment they were
+		// added to.
+		logger.Debugf("API token used outside an environment")
+		return nil, nil, common.ErrBadCreds
+	}
 	entity, err := st.FindEntity(tag)
+	provisioned := false
+	if userTag, ok := tag.(names.UserTag); ok && errors.IsNotFound(err) && !isToken {
+		// Users unknown to juju may be known to the identity
+		// provider, in which case they are added on first login.
+		entity, err = provisionExternalUser(st, userTag, req.Credentials)
+		provisioned = err == nil
+	}
 	if errors.IsNotFound(err) {
 		// We return the same error when an entity does not exist as for a bad
 		// password, so that we don't allow unauthenticated users to find
@@ -251,14 +286,15 @@ func checkCreds(st *state.State, req params.LoginRequest, lookForEnvUser bool) (
 		return nil, nil, errors.Trace(err)
 	}
 
-	authenticator, err := authentication.FindEntityAuthenticator(entity)
-	if err != nil {
-		return nil, nil, err
-	}
-
-	if err = authenticator.Authenticate(entity, req.Credentials, req.Nonce); err != nil {
-		logger.Debugf("bad credentials")
-		return nil, nil, err
+	if !provisioned {
+		authenticator, err := findEntityAuthenticator(st, entity, isToken)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err = authenticator.Authenticate(entity, req.Credentials, req.Nonce); err != nil {
+			logger.Debugf("bad credentials")
+			return nil, nil, err
+		}
 	}
 
 	// For user logins, update the last login time.
@@ -295,6 +331,76 @@ func checkCreds(st *state.State, req params.LoginRequest, lookForEnvUser bool) (
 	return entity, lastLogin, nil
 }
 
+// findEntityAuthenticator returns the authenticator for the entity,
+// which for users juju holds no password for checks the password with
+// the state server's identity provider, and for users logging in with
+// an API token checks the token.
+func findEntityAuthenticator(st *state.State, entity state.Entity, isToken bool) (authentication.EntityAuthenticator, error) {
+	if _, ok := entity.(*state.User); ok && isToken {
+		return &authentication.APITokenAuthenticator{Tokens: st}, nil
+	}
+	if user, ok := entity.(*state.User); ok && user.IdentityProvider() != "" {
+		external, err := newExternalAuthenticator(st)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return &authentication.UserAuthenticator{External: external}, nil
+	}
+	return authentication.FindEntityAuthenticator(entity)
+}
+
+// newExternalAuthenticator returns the authenticator for the identity
+// provider set up in the state server environment's configuration, or
+// nil if there is none.
+var newExternalAuthenticator = func(st *state.State) (authentication.ExternalAuthenticator, error) {
+	env, err := st.StateServerEnvironment()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	cfg, err := env.Config()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return authentication.NewExternalAuthenticator(cfg), nil
+}
+
+// provisionExternalUser checks the password of a user juju knows
+// nothing of with the state server's identity provider, and adds the
+// user if it is right. The user is given no access to environments,
+// which must be shared with them. A not found error is returned if
+// there is no identity provider.
+func provisionExternalUser(st *state.State, tag names.UserTag, password string) (*state.User, error) {
+	if !tag.IsLocal() {
+		return nil, errors.NotFoundf("user %q", tag.Username())
+	}
+	external, err := newExternalAuthenticator(st)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if external == nil {
+		return nil, errors.NotFoundf("user %q", tag.Username())
+	}
+	externalUser, err := external.AuthenticateUser(tag.Name(), password)
+	if err != nil {
+		logger.Debugf("bad credentials")
+		return nil, err
+	}
+	env, err := st.StateServerEnvironment()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	user, err := st.AddExternalUser(tag.Name(), externalUser.DisplayName, external.IdentityProvider(), env.Owner().Name())
+	if errors.IsAlreadyExists(err) {
+		// Another login added the user first.
+		user, err = st.User(tag)
+	}
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot add user %q", tag.Name())
+	}
+	logger.Infof("added user %q authenticated by %s", tag.Name(), external.IdentityProvider())
+	return user, nil
+}
+
 func checkForValidMachineAgent(entity state.Entity, req params.LoginRequest) error {
 	// If this is a machine agent connecting, we need to check the
 	// nonce matches, otherwise the wrong agent might be trying to
@@ -4,13 +4,19 @@
 package apiserver_test
 
 import (
-	"github.com/juju/juju/api"
+	"time"
+
+	"github.com/juju/errors"
 	"github.com/juju/names"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
+	"github.com/juju/juju/api"
 	"github.com/juju/juju/apiserver"
+	ldaptesting "github.com/juju/juju/apiserver/authentication/ldap/testing"
+	"github.com/juju/juju/state"
 	"github.com/juju/juju/testing/factory"
+	"github.com/juju/juju/version"
 )
 
 type loginV2Suite struct {
@@ -95,3 +101,225 @@ func (s *loginV2Suite) TestClientLoginToRootOldClient(c *gc.C) {
 	_, err = client.GetEnvironmentConstraints()
 	c.Assert(err, jc.ErrorIsNil)
 }
+
+func (s *loginV2Suite) setUpLDAP(c *gc.C) *ldaptesting.Server {
+	server, err := ldaptesting.NewServer()
+	c.Assert(err, jc.ErrorIsNil)
+	s.AddCleanup(func(*gc.C) { server.Close() })
+	server.AddEntry("uid=bob,ou=people,dc=example,dc=com", ldaptesting.Entry{
+		Password: "sekrit",
+		Attributes: map[string][]string{
+			"displayName": {"Bob Brown"},
+		},
+	})
+	err = s.State.UpdateEnvironConfig(map[string]interface{}{
+		"ldap-url":            server.URL(),
+		"ldap-user-dn":        "uid={username},ou=people,dc=example,dc=com",
+		"ldap-allow-insecure": true,
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	return server
+}
+
+func (s *loginV2Suite) TestLDAPUserAddedOnLogin(c *gc.C) {
+	_, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	s.setUpLDAP(c)
+
+	info := s.APIInfo(c)
+	info.Tag = names.NewUserTag("bob")
+	info.Password = "sekrit"
+	info.EnvironTag = names.EnvironTag{}
+	apiState, err := api.Open(info, api.DialOpts{})
+	c.Assert(err, jc.ErrorIsNil)
+	apiState.Close()
+
+	user, err := s.State.User(names.NewUserTag("bob"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.DisplayName(), gc.Equals, "Bob Brown")
+	c.Assert(user.IdentityProvider(), gc.Equals, "ldap")
+	c.Assert(user.CreatedBy(), gc.Equals, s.AdminUserTag(c).Name())
+	_, err = user.LastLogin()
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Later logins are checked against the directory too.
+	apiState, err = api.Open(info, api.DialOpts{})
+	c.Assert(err, jc.ErrorIsNil)
+	apiState.Close()
+
+	info.Password = "wrong"
+	_, err = api.Open(info, api.DialOpts{})
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
+
+func (s *loginV2Suite) TestLDAPUserBadPassword(c *gc.C) {
+	_, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	s.setUpLDAP(c)
+
+	info := s.APIInfo(c)
+	info.Tag = names.NewUserTag("bob")
+	info.Password = "wrong"
+	info.EnvironTag = names.EnvironTag{}
+	_, err := api.Open(info, api.DialOpts{})
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+
+	_, err = s.State.User(names.NewUserTag("bob"))
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *loginV2Suite) TestLDAPUserAddedOnLoginToEnvironment(c *gc.C) {
+	_, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	s.setUpLDAP(c)
+
+	info := s.APIInfo(c)
+	info.Tag = names.NewUserTag("bob")
+	info.Password = "sekrit"
+	_, err := api.Open(info, api.DialOpts{})
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+
+	// The user is added, but the environment must be shared with them.
+	user, err := s.State.User(names.NewUserTag("bob"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.IdentityProvider(), gc.Equals, "ldap")
+	_, err = s.State.EnvironmentUser(names.NewUserTag("bob"))
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	_, err = s.State.AddEnvironmentUser(names.NewUserTag("bob"), s.AdminUserTag(c), "", state.EnvironmentReadAccess)
+	c.Assert(err, jc.ErrorIsNil)
+	apiState, err := api.Open(info, api.DialOpts{})
+	c.Assert(err, jc.ErrorIsNil)
+	apiState.Close()
+}
+
+func (s *loginV2Suite) TestLDAPUserNeedsEnvironmentUser(c *gc.C) {
+	_, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	s.setUpLDAP(c)
+
+	// The user is added on first login to the system only.
+	info := s.APIInfo(c)
+	info.Tag = names.NewUserTag("bob")
+	info.Password = "sekrit"
+	envTag := info.EnvironTag
+	info.EnvironTag = names.EnvironTag{}
+	apiState, err := api.Open(info, api.DialOpts{})
+	c.Assert(err, jc.ErrorIsNil)
+	apiState.Close()
+
+	// Environments must then be shared with them.
+	info.EnvironTag = envTag
+	_, err = api.Open(info, api.DialOpts{})
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+
+	_, err = s.State.AddEnvironmentUser(names.NewUserTag("bob"), s.AdminUserTag(c), "", state.EnvironmentReadAccess)
+	c.Assert(err, jc.ErrorIsNil)
+	apiState, err = api.Open(info, api.DialOpts{})
+	c.Assert(err, jc.ErrorIsNil)
+	apiState.Close()
+}
+
+func (s *loginV2Suite) TestLDAPUserDirectoryRemoved(c *gc.C) {
+	_, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	_, err := s.State.AddExternalUser("bob", "Bob Brown", "ldap", s.AdminUserTag(c).Name())
+	c.Assert(err, jc.ErrorIsNil)
+
+	info := s.APIInfo(c)
+	info.Tag = names.NewUserTag("bob")
+	info.Password = "sekrit"
+	info.EnvironTag = names.EnvironTag{}
+	_, err = api.Open(info, api.DialOpts{})
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
+
+func (s *loginV2Suite) addAPIToken(c *gc.C, user names.UserTag, readOnly bool) (*state.APIToken, string) {
+	token, credentials, err := s.State.AddAPIToken(state.APITokenParams{
+		User:     user,
+		Expires:  time.Now().Add(time.Hour),
+		ReadOnly: readOnly,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return token, credentials
+}
+
+func (s *loginV2Suite) TestAPITokenLogin(c *gc.C) {
+	_, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "bob"})
+	token, credentials := s.addAPIToken(c, user.UserTag(), true)
+
+	info := s.APIInfo(c)
+	info.Tag = user.Tag()
+	info.Password = credentials
+	apiState, err := api.Open(info, api.DialOpts{})
+	c.Assert(err, jc.ErrorIsNil)
+	defer apiState.Close()
+
+	client := apiState.Client()
+	_, err = client.GetEnvironmentConstraints()
+	c.Assert(err, jc.ErrorIsNil)
+	// The token is read-only.
+	err = client.SetEnvironAgentVersion(version.Current.Number)
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+
+	// Calls fail as soon as the token is revoked.
+	err = s.State.RevokeAPIToken(token.ID())
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = client.GetEnvironmentConstraints()
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+
+	_, err = api.Open(info, api.DialOpts{})
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
+
+func (s *loginV2Suite) TestAPITokenLoginWrongUser(c *gc.C) {
+	_, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	bob := s.Factory.MakeUser(c, &factory.UserParams{Name: "bob"})
+	alice := s.Factory.MakeUser(c, &factory.UserParams{Name: "alice"})
+	_, credentials := s.addAPIToken(c, bob.UserTag(), false)
+
+	info := s.APIInfo(c)
+	info.Tag = alice.Tag()
+	info.Password = credentials
+	_, err := api.Open(info, api.DialOpts{})
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
+
+func (s *loginV2Suite) TestAPITokenLoginToServerFails(c *gc.C) {
+	_, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "bob"})
+	_, credentials := s.addAPIToken(c, user.UserTag(), false)
+
+	info := s.APIInfo(c)
+	info.Tag = user.Tag()
+	info.Password = credentials
+	info.EnvironTag = names.EnvironTag{}
+	_, err := api.Open(info, api.DialOpts{})
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
+
+func (s *loginV2Suite) TestAPITokenLoginOtherEnvironmentFails(c *gc.C) {
+	_, cleanup := s.setupServerWithValidator(c, nil)
+	defer cleanup()
+	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "bob"})
+	_, credentials := s.addAPIToken(c, user.UserTag(), false)
+
+	envOwner := s.Factory.MakeUser(c, nil)
+	envState := s.Factory.MakeEnvironment(c, &factory.EnvParams{
+		Owner: envOwner.UserTag(),
+	})
+	defer envState.Close()
+	_, err := envState.AddEnvironmentUser(user.UserTag(), envOwner.UserTag(), "", state.EnvironmentAdminAccess)
+	c.Assert(err, jc.ErrorIsNil)
+
+	info := s.APIInfo(c)
+	info.Tag = user.Tag()
+	info.Password = credentials
+	info.EnvironTag = envState.EnvironTag()
+	_, err = api.Open(info, api.DialOpts{})
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
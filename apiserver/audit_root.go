@@ -0,0 +1,234 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/juju/names"
+	"github.com/juju/utils/set"
+
+	"github.com/juju/juju/audit"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/rpc/rpcreflect"
+	"github.com/juju/juju/state"
+)
+
+// auditRoot records the API calls a user makes that may change the
+// environment in its audit log, including those the user is not
+// allowed to make.
+type auditRoot struct {
+	rpc.MethodFinder
+	// unrestricted finds the methods the MethodFinder refuses to,
+	// so that refused calls can be recorded with their arguments.
+	unrestricted rpc.MethodFinder
+	st           *state.State
+	user         names.UserTag
+	// secretAttrs holds the names of the environment settings the
+	// provider holds secret.
+	secretAttrs set.Strings
+}
+
+// newAuditRoot returns a new auditRoot recording the calls made by the
+// given user with finder, which restricts the calls unrestricted finds.
+func newAuditRoot(finder, unrestricted rpc.MethodFinder, st *state.State, user names.UserTag) *auditRoot {
+	return &auditRoot{
+		MethodFinder: finder,
+		unrestricted: unrestricted,
+		st:           st,
+		// Record the user by its canonical tag, so that records of
+		// the calls made by local users can be found by either name.
+		user:        names.NewUserTag(user.Username()),
+		secretAttrs: providerSecretAttrs(st),
+	}
+}
+
+// providerSecretAttrs returns the names of the environment settings
+// the provider of the environment holds secret, such as its
+// credentials.
+func providerSecretAttrs(st *state.State) set.Strings {
+	attrs := make(set.Strings)
+	cfg, err := st.EnvironConfig()
+	if err != nil {
+		logger.Warningf("cannot get environment config to redact audit records: %v", err)
+		return attrs
+	}
+	provider, err := environs.Provider(cfg.Type())
+	if err != nil {
+		logger.Warningf("cannot get provider to redact audit records: %v", err)
+		return attrs
+	}
+	secrets, err := provider.SecretAttrs(cfg)
+	if err != nil {
+		logger.Warningf("cannot get secret settings to redact audit records: %v", err)
+		return attrs
+	}
+	for name := range secrets {
+		attrs.Add(name)
+	}
+	return attrs
+}
+
+// unauditedCalls are the API calls, other than those read-only users
+// may make, that change nothing and are not recorded.
+var unauditedCalls = set.NewStrings(
+	"AuditLog.Records",
+)
+
+// alwaysAuditedCalls are the API calls read-only users may make that
+// are recorded all the same.
+var alwaysAuditedCalls = set.NewStrings(
+	"UserManager.AddAPITokens",
+	"UserManager.RevokeAPITokens",
+	"UserManager.SetPassword",
+)
+
+// settingsFields holds, for the API calls that take charm settings,
+// the names of the fields that hold them. The values of the settings
+// are not recorded, as charms may take secrets as settings of any
+// name.
+var settingsFields = map[string]set.Strings{
+	"Client.ServiceDeploy":                set.NewStrings("Config", "ConfigYAML"),
+	"Client.ServiceDeployWithNetworks":    set.NewStrings("Config", "ConfigYAML"),
+	"Client.ServiceSet":                   set.NewStrings("Options"),
+	"Client.ServiceSetYAML":               set.NewStrings("Config"),
+	"Client.ServiceUpdate":                set.NewStrings("SettingsStrings", "SettingsYAML"),
+	"Service.ServicesDeploy":              set.NewStrings("Config", "ConfigYAML"),
+	"Service.ServicesDeployWithPlacement": set.NewStrings("Config", "ConfigYAML"),
+}
+
+// FindMethod implements rpc.MethodFinder, returning a caller that
+// records the call unless it cannot change the environment. Calls
+// the user may not make are recorded with the error refusing them.
+func (r *auditRoot) FindMethod(rootName string, version int, methodName string) (rpcreflect.MethodCaller, error) {
+	caller, err := r.MethodFinder.FindMethod(rootName, version, methodName)
+	if !isAuditedCall(rootName, methodName) {
+		return caller, err
+	}
+	var refusal error
+	if err != nil {
+		// Record the refused call only if the method exists.
+		refusal = err
+		if caller, err = r.unrestricted.FindMethod(rootName, version, methodName); err != nil {
+			return nil, refusal
+		}
+	}
+	return &auditCaller{
+		MethodCaller: caller,
+		root:         r,
+		rootName:     rootName,
+		version:      version,
+		methodName:   methodName,
+		refusal:      refusal,
+	}, nil
+}
+
+// isAuditedCall reports whether calls of the facade method are
+// recorded in the audit log.
+func isAuditedCall(rootName, methodName string) bool {
+	// The watchers only report changes.
+	if strings.HasSuffix(rootName, "Watcher") {
+		return false
+	}
+	fullName := rootName + "." + methodName
+	if alwaysAuditedCalls.Contains(fullName) {
+		return true
+	}
+	return !readOnlyCalls.Contains(fullName) && !unauditedCalls.Contains(fullName)
+}
+
+// auditCaller records the calls made with it.
+type auditCaller struct {
+	rpcreflect.MethodCaller
+	root       *auditRoot
+	rootName   string
+	version    int
+	methodName string
+	// refusal holds the error refusing the call, if the user may
+	// not make it.
+	refusal error
+}
+
+// Call implements rpcreflect.MethodCaller.
+func (c *auditCaller) Call(objId string, arg reflect.Value) (reflect.Value, error) {
+	start := time.Now()
+	if c.refusal != nil {
+		c.record(start, arg, c.refusal)
+		return reflect.Value{}, c.refusal
+	}
+	result, err := c.MethodCaller.Call(objId, arg)
+	c.record(start, arg, err)
+	return result, err
+}
+
+// record adds a record of the call to the audit log. Failing to do
+// so does not fail the call.
+func (c *auditCaller) record(start time.Time, arg reflect.Value, callErr error) {
+	var args interface{}
+	if arg.IsValid() {
+		args = arg.Interface()
+	}
+	record := state.AuditRecord{
+		Time:    start,
+		User:    c.root.user.String(),
+		Facade:  c.rootName,
+		Version: c.version,
+		Method:  c.methodName,
+	}
+	var err error
+	fields := settingsFields[c.rootName+"."+c.methodName]
+	if record.Args, err = audit.Redact(args, c.root.secretAttrs, fields); err != nil {
+		logger.Warningf("cannot record arguments of %s.%s: %v", c.rootName, c.methodName, err)
+	}
+	if record.Entities, err = audit.Entities(args); err != nil {
+		logger.Warningf("cannot record entities of %s.%s: %v", c.rootName, c.methodName, err)
+	}
+	if callErr != nil {
+		record.Error = callErr.Error()
+	}
+	if err := c.root.st.AddAuditRecord(record); err != nil {
+		logger.Errorf("cannot record %s.%s call by %s: %v", c.rootName, c.methodName, c.root.user, err)
+	}
+}
+
+// auditRequest adds a record of an HTTP request the user made to change
+// the environment, such as a charm upload, to its audit log, including
+// requests the user is not allowed to make. The request is recorded as
+// a call of the given facade, named by its HTTP method, taking its
+// query arguments. Failing to record it does not fail the request.
+func (h *httpStateWrapper) auditRequest(user names.UserTag, facade string, r *http.Request, start time.Time, reqErr error) {
+	if user.Id() == "" {
+		// The request was not made by a known user.
+		return
+	}
+	user = names.NewUserTag(user.Username())
+	args := make(map[string][]string)
+	for name, values := range r.URL.Query() {
+		// Arguments taken from the path, such as the environment UUID,
+		// are prefixed with a colon.
+		if !strings.HasPrefix(name, ":") {
+			args[name] = values
+		}
+	}
+	record := state.AuditRecord{
+		Time:   start,
+		User:   user.String(),
+		Facade: facade,
+		Method: r.Method,
+	}
+	var err error
+	if record.Args, err = audit.Redact(args, nil, nil); err != nil {
+		logger.Warningf("cannot record arguments of %s %s: %v", r.Method, r.URL.Path, err)
+	}
+	if reqErr != nil {
+		record.Error = reqErr.Error()
+	}
+	if err := h.state.AddAuditRecord(record); err != nil {
+		logger.Errorf("cannot record %s %s request by %s: %v", r.Method, r.URL.Path, user, err)
+	}
+}
@@ -0,0 +1,246 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/rpc/rpcreflect"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type auditRootSuite struct {
+	jujutesting.JujuConnSuite
+}
+
+var _ = gc.Suite(&auditRootSuite{})
+
+type fakeFinder struct {
+	err    error
+	called bool
+}
+
+func (f *fakeFinder) FindMethod(rootName string, version int, methodName string) (rpcreflect.MethodCaller, error) {
+	if methodName == "Missing" {
+		return nil, errors.NotFoundf("method")
+	}
+	return &fakeCaller{finder: f}, nil
+}
+
+type fakeCaller struct {
+	rpcreflect.MethodCaller
+	finder *fakeFinder
+}
+
+func (c *fakeCaller) Call(objId string, arg reflect.Value) (reflect.Value, error) {
+	c.finder.called = true
+	return reflect.Value{}, c.finder.err
+}
+
+// refusingFinder refuses to find any method.
+type refusingFinder struct{}
+
+func (refusingFinder) FindMethod(rootName string, version int, methodName string) (rpcreflect.MethodCaller, error) {
+	return nil, common.ErrPerm
+}
+
+func (s *auditRootSuite) call(c *gc.C, finder *fakeFinder, rootName, methodName string, args interface{}) error {
+	root := apiserver.TestingAuditRoot(finder, finder, s.State, names.NewLocalUserTag("bob"))
+	caller, err := root.FindMethod(rootName, 1, methodName)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = caller.Call("", reflect.ValueOf(args))
+	return err
+}
+
+func (s *auditRootSuite) TestRecordsCall(c *gc.C) {
+	start := time.Now().Add(-time.Second)
+	err := s.call(c, &fakeFinder{}, "Client", "ServiceDestroy", params.ServiceDestroy{ServiceName: "wordpress"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	records, err := s.State.AuditRecords(state.AuditFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 1)
+	record := records[0]
+	c.Assert(record.Time.After(start), jc.IsTrue)
+	record.Time = time.Time{}
+	c.Assert(record, jc.DeepEquals, state.AuditRecord{
+		User:     "user-bob@local",
+		Facade:   "Client",
+		Version:  1,
+		Method:   "ServiceDestroy",
+		Entities: []string{"service-wordpress"},
+		Args:     `{"ServiceName":"wordpress"}`,
+	})
+}
+
+func (s *auditRootSuite) TestRecordsError(c *gc.C) {
+	finder := &fakeFinder{err: errors.New("boom")}
+	err := s.call(c, finder, "Client", "ServiceDestroy", params.ServiceDestroy{ServiceName: "wordpress"})
+	c.Assert(err, gc.ErrorMatches, "boom")
+
+	records, err := s.State.AuditRecords(state.AuditFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 1)
+	c.Assert(records[0].Error, gc.Equals, "boom")
+}
+
+func (s *auditRootSuite) TestRedactsSecrets(c *gc.C) {
+	args := params.EntityPasswords{
+		Changes: []params.EntityPassword{{Tag: "user-alice@local", Password: "sekrit"}},
+	}
+	// Read-only users may change their own passwords, but the calls
+	// are recorded all the same.
+	err := s.call(c, &fakeFinder{}, "UserManager", "SetPassword", args)
+	c.Assert(err, jc.ErrorIsNil)
+
+	records, err := s.State.AuditRecords(state.AuditFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 1)
+	c.Assert(records[0].Args, gc.Equals, `{"Changes":[{"Password":"<redacted>","Tag":"user-alice@local"}]}`)
+	c.Assert(records[0].Entities, jc.DeepEquals, []string{"user-alice@local"})
+}
+
+func (s *auditRootSuite) TestReadOnlyCallsNotRecorded(c *gc.C) {
+	for _, call := range []struct {
+		rootName   string
+		methodName string
+	}{
+		{"Client", "FullStatus"},
+		{"Client", "StatusHistory"},
+		{"AllWatcher", "Next"},
+		{"NotifyWatcher", "Next"},
+		{"AuditLog", "Records"},
+	} {
+		err := s.call(c, &fakeFinder{}, call.rootName, call.methodName, params.Entities{})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	records, err := s.State.AuditRecords(state.AuditFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 0)
+}
+
+func (s *auditRootSuite) TestRecordsCanonicalUser(c *gc.C) {
+	finder := &fakeFinder{}
+	root := apiserver.TestingAuditRoot(finder, finder, s.State, names.NewUserTag("bob"))
+	caller, err := root.FindMethod("Client", 1, "ServiceDestroy")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = caller.Call("", reflect.ValueOf(params.ServiceDestroy{ServiceName: "wordpress"}))
+	c.Assert(err, jc.ErrorIsNil)
+
+	records, err := s.State.AuditRecords(state.AuditFilter{User: "user-bob@local"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 1)
+}
+
+func (s *auditRootSuite) TestFindMissingMethod(c *gc.C) {
+	finder := &fakeFinder{}
+	root := apiserver.TestingAuditRoot(finder, finder, s.State, names.NewLocalUserTag("bob"))
+	_, err := root.FindMethod("Client", 1, "Missing")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *auditRootSuite) TestRecordsRefusedCall(c *gc.C) {
+	unrestricted := &fakeFinder{}
+	root := apiserver.TestingAuditRoot(refusingFinder{}, unrestricted, s.State, names.NewLocalUserTag("bob"))
+	caller, err := root.FindMethod("Client", 1, "ServiceDestroy")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = caller.Call("", reflect.ValueOf(params.ServiceDestroy{ServiceName: "wordpress"}))
+	c.Assert(err, gc.Equals, common.ErrPerm)
+	c.Assert(unrestricted.called, jc.IsFalse)
+
+	records, err := s.State.AuditRecords(state.AuditFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 1)
+	c.Assert(records[0].Method, gc.Equals, "ServiceDestroy")
+	c.Assert(records[0].Args, gc.Equals, `{"ServiceName":"wordpress"}`)
+	c.Assert(records[0].Error, gc.Equals, common.ErrPerm.Error())
+}
+
+func (s *auditRootSuite) TestRefusedMissingMethodNotRecorded(c *gc.C) {
+	root := apiserver.TestingAuditRoot(refusingFinder{}, &fakeFinder{}, s.State, names.NewLocalUserTag("bob"))
+	_, err := root.FindMethod("Client", 1, "Missing")
+	c.Assert(err, gc.Equals, common.ErrPerm)
+
+	records, err := s.State.AuditRecords(state.AuditFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 0)
+}
+
+func (s *auditRootSuite) TestRefusedReadOnlyCallNotRecorded(c *gc.C) {
+	root := apiserver.TestingAuditRoot(refusingFinder{}, &fakeFinder{}, s.State, names.NewLocalUserTag("bob"))
+	_, err := root.FindMethod("Client", 1, "FullStatus")
+	c.Assert(err, gc.Equals, common.ErrPerm)
+}
+
+func (s *auditRootSuite) TestRedactsProviderSecrets(c *gc.C) {
+	args := params.EnvironmentSet{Config: map[string]interface{}{
+		"secret":         "pork",
+		"logging-config": "<root>=DEBUG",
+	}}
+	err := s.call(c, &fakeFinder{}, "Client", "EnvironmentSet", args)
+	c.Assert(err, jc.ErrorIsNil)
+
+	records, err := s.State.AuditRecords(state.AuditFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 1)
+	c.Assert(records[0].Args, gc.Equals, `{"Config":{"logging-config":"<root>=DEBUG","secret":"<redacted>"}}`)
+}
+
+func (s *auditRootSuite) TestRedactsCharmSettings(c *gc.C) {
+	args := params.ServiceDeploy{
+		ServiceName: "wordpress",
+		CharmUrl:    "cs:trusty/wordpress-1",
+		Config:      map[string]string{"db-pass": "sekrit"},
+		ConfigYAML:  "wordpress:\n  db-pass: sekrit\n",
+	}
+	err := s.call(c, &fakeFinder{}, "Client", "ServiceDeploy", args)
+	c.Assert(err, jc.ErrorIsNil)
+
+	records, err := s.State.AuditRecords(state.AuditFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 1)
+	c.Assert(records[0].Args, jc.Contains, `"Config":{"db-pass":"<redacted>"},"ConfigYAML":"<redacted>"`)
+	c.Assert(records[0].Args, gc.Not(jc.Contains), "sekrit")
+}
+
+func (s *auditRootSuite) TestUserCallsRecorded(c *gc.C) {
+	err := s.APIState.Client().DestroyServiceUnits("wordpress/0")
+	c.Assert(err, gc.NotNil)
+
+	records, err := s.State.AuditRecords(state.AuditFilter{Entity: "unit-wordpress-0"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 1)
+	c.Assert(records[0].User, gc.Equals, names.NewUserTag(s.AdminUserTag(c).Username()).String())
+	c.Assert(records[0].Facade, gc.Equals, "Client")
+	c.Assert(records[0].Method, gc.Equals, "DestroyServiceUnits")
+	c.Assert(records[0].Error, gc.Not(gc.Equals), "")
+}
+
+func (s *auditRootSuite) TestRefusedUserCallsRecorded(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "reader", Password: "secret", NoEnvUser: true})
+	s.Factory.MakeEnvUser(c, &factory.EnvUserParams{User: user.Name(), Access: state.EnvironmentReadAccess})
+	st := s.OpenAPIAs(c, user.Tag(), "secret")
+	defer st.Close()
+
+	err := st.Client().DestroyServiceUnits("wordpress/0")
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+
+	records, err := s.State.AuditRecords(state.AuditFilter{Entity: "unit-wordpress-0"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 1)
+	c.Assert(records[0].User, gc.Equals, "user-reader@local")
+	c.Assert(records[0].Method, gc.Equals, "DestroyServiceUnits")
+	c.Assert(records[0].Error, gc.Equals, "permission denied")
+}
@@ -0,0 +1,89 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package auditlog
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("AuditLog", 1, NewAPI)
+}
+
+// auditLogAccess defines the state methods the AuditLog facade uses.
+type auditLogAccess interface {
+	AuditRecords(filter state.AuditFilter) ([]state.AuditRecord, error)
+}
+
+var getState = func(st *state.State) auditLogAccess {
+	return st
+}
+
+// API implements the AuditLog facade, which gives access to the
+// records of the API calls users have made against the environment.
+type API struct {
+	access auditLogAccess
+}
+
+// NewAPI returns a new AuditLog API facade.
+func NewAPI(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*API, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	return &API{access: getState(st)}, nil
+}
+
+// Records returns the environment's audit records selected by the
+// filter, oldest first.
+func (api *API) Records(args params.AuditRecordFilter) (params.AuditRecordsResult, error) {
+	filter := state.AuditFilter{
+		From:  args.From,
+		To:    args.To,
+		Limit: args.Limit,
+	}
+	if args.User != "" {
+		tag, err := names.ParseUserTag(args.User)
+		if err != nil {
+			return params.AuditRecordsResult{}, errors.Trace(err)
+		}
+		// Calls are recorded against users' canonical tags.
+		filter.User = names.NewUserTag(tag.Username()).String()
+	}
+	if args.Entity != "" {
+		tag, err := names.ParseTag(args.Entity)
+		if err != nil {
+			return params.AuditRecordsResult{}, errors.Trace(err)
+		}
+		filter.Entity = tag.String()
+	}
+	records, err := api.access.AuditRecords(filter)
+	if err != nil {
+		return params.AuditRecordsResult{}, errors.Trace(err)
+	}
+	result := params.AuditRecordsResult{
+		Records: make([]params.AuditRecord, len(records)),
+	}
+	for i, record := range records {
+		result.Records[i] = params.AuditRecord{
+			Time:     record.Time,
+			User:     record.User,
+			Facade:   record.Facade,
+			Version:  record.Version,
+			Method:   record.Method,
+			Entities: record.Entities,
+			Args:     record.Args,
+			Error:    record.Error,
+		}
+	}
+	return result, nil
+}
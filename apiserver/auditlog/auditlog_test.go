@@ -0,0 +1,127 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package auditlog_test
+
+import (
+	"time"
+
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/auditlog"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+)
+
+type auditLogSuite struct {
+	jujutesting.JujuConnSuite
+	api *auditlog.API
+}
+
+var _ = gc.Suite(&auditLogSuite{})
+
+func (s *auditLogSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+
+	var err error
+	auth := apiservertesting.FakeAuthorizer{
+		Tag:            s.AdminUserTag(c),
+		EnvironManager: true,
+	}
+	s.api, err = auditlog.NewAPI(s.State, common.NewResources(), auth)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *auditLogSuite) TestNewAPIRefusesAgents(c *gc.C) {
+	auth := apiservertesting.FakeAuthorizer{
+		Tag: names.NewMachineTag("0"),
+	}
+	_, err := auditlog.NewAPI(s.State, common.NewResources(), auth)
+	c.Assert(err, gc.Equals, common.ErrPerm)
+}
+
+func (s *auditLogSuite) addRecord(c *gc.C, t time.Time, user, method string, entities ...string) {
+	err := s.State.AddAuditRecord(state.AuditRecord{
+		Time:     t,
+		User:     user,
+		Facade:   "Client",
+		Version:  1,
+		Method:   method,
+		Entities: entities,
+		Args:     "{}",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *auditLogSuite) TestRecords(c *gc.C) {
+	t0 := time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+	s.addRecord(c, t0, "user-bob@local", "ServiceDestroy", "service-wordpress")
+	s.addRecord(c, t0.Add(time.Minute), "user-alice@local", "DestroyMachines", "machine-1")
+
+	result, err := s.api.Records(params.AuditRecordFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Records, jc.DeepEquals, []params.AuditRecord{{
+		Time:     t0,
+		User:     "user-bob@local",
+		Facade:   "Client",
+		Version:  1,
+		Method:   "ServiceDestroy",
+		Entities: []string{"service-wordpress"},
+		Args:     "{}",
+	}, {
+		Time:     t0.Add(time.Minute),
+		User:     "user-alice@local",
+		Facade:   "Client",
+		Version:  1,
+		Method:   "DestroyMachines",
+		Entities: []string{"machine-1"},
+		Args:     "{}",
+	}})
+}
+
+func (s *auditLogSuite) TestRecordsFiltered(c *gc.C) {
+	t0 := time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+	s.addRecord(c, t0, "user-bob@local", "ServiceDestroy", "service-wordpress")
+	s.addRecord(c, t0.Add(time.Minute), "user-bob@local", "DestroyMachines", "machine-1")
+	s.addRecord(c, t0.Add(2*time.Minute), "user-alice@local", "DestroyMachines", "machine-2")
+
+	for i, test := range []struct {
+		filter  params.AuditRecordFilter
+		methods []string
+	}{{
+		// Local users are found by either name.
+		filter:  params.AuditRecordFilter{User: "user-bob"},
+		methods: []string{"ServiceDestroy", "DestroyMachines"},
+	}, {
+		filter:  params.AuditRecordFilter{Entity: "machine-2"},
+		methods: []string{"DestroyMachines"},
+	}, {
+		filter:  params.AuditRecordFilter{From: t0.Add(time.Minute), To: t0.Add(2 * time.Minute)},
+		methods: []string{"DestroyMachines"},
+	}, {
+		filter:  params.AuditRecordFilter{Limit: 2},
+		methods: []string{"DestroyMachines", "DestroyMachines"},
+	}} {
+		c.Logf("test %d", i)
+		result, err := s.api.Records(test.filter)
+		c.Assert(err, jc.ErrorIsNil)
+		methods := make([]string, len(result.Records))
+		for i, record := range result.Records {
+			methods[i] = record.Method
+		}
+		c.Check(methods, jc.DeepEquals, test.methods)
+	}
+}
+
+func (s *auditLogSuite) TestRecordsBadFilter(c *gc.C) {
+	_, err := s.api.Records(params.AuditRecordFilter{User: "machine-0"})
+	c.Assert(err, gc.ErrorMatches, `"machine-0" is not a valid user tag`)
+
+	_, err = s.api.Records(params.AuditRecordFilter{Entity: "wordpress"})
+	c.Assert(err, gc.ErrorMatches, `"wordpress" is not a valid tag`)
+}
@@ -0,0 +1,106 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package authentication
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/authentication/ldap"
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/environs/config"
+)
+
+// LDAPIdentityProvider is the identity provider of users whose
+// passwords are checked against an LDAP directory.
+const LDAPIdentityProvider = "ldap"
+
+// ExternalUser holds what an identity provider knows about a user it
+// has authenticated.
+type ExternalUser struct {
+	Name        string
+	DisplayName string
+}
+
+// ExternalAuthenticator checks the passwords of users that juju does
+// not hold passwords for.
+type ExternalAuthenticator interface {
+	// IdentityProvider returns the name of the identity provider, as
+	// recorded against the users it authenticates.
+	IdentityProvider() string
+
+	// AuthenticateUser checks the password of the named user, and
+	// returns common.ErrBadCreds if it is wrong.
+	AuthenticateUser(name, password string) (*ExternalUser, error)
+}
+
+// NewExternalAuthenticator returns the authenticator for the identity
+// provider set up in the given configuration, or nil if there is none.
+func NewExternalAuthenticator(cfg *config.Config) ExternalAuthenticator {
+	if cfg.LDAPURL() == "" {
+		return nil
+	}
+	return &LDAPAuthenticator{cfg: cfg}
+}
+
+// LDAPAuthenticator authenticates users with simple binds to an LDAP
+// directory, as the entry named by the ldap-user-dn setting.
+type LDAPAuthenticator struct {
+	cfg *config.Config
+}
+
+var _ ExternalAuthenticator = (*LDAPAuthenticator)(nil)
+
+// IdentityProvider implements ExternalAuthenticator.
+func (*LDAPAuthenticator) IdentityProvider() string {
+	return LDAPIdentityProvider
+}
+
+// AuthenticateUser implements ExternalAuthenticator.
+func (a *LDAPAuthenticator) AuthenticateUser(name, password string) (*ExternalUser, error) {
+	tlsConfig, err := a.tlsConfig()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	conn, err := ldap.Dial(a.cfg.LDAPURL(), tlsConfig)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer conn.Close()
+
+	dn := a.cfg.LDAPUserDN(ldap.EscapeDN(name))
+	if err := conn.Bind(dn, password); ldap.IsInvalidCredentials(err) {
+		return nil, common.ErrBadCreds
+	} else if err != nil {
+		return nil, errors.Annotatef(err, "cannot bind as %q", dn)
+	}
+
+	displayNameAttr := a.cfg.LDAPDisplayNameAttribute()
+	attrs, err := conn.Attributes(dn, displayNameAttr)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot read attributes of %q", dn)
+	}
+	user := &ExternalUser{Name: name}
+	if values := attrs[displayNameAttr]; len(values) > 0 {
+		user.DisplayName = values[0]
+	}
+	return user, nil
+}
+
+// tlsConfig returns the TLS configuration to connect to an ldaps://
+// directory with, checking its certificate against the configured CA
+// certificate if there is one, or against the system's otherwise.
+func (a *LDAPAuthenticator) tlsConfig() (*tls.Config, error) {
+	caCert := a.cfg.LDAPCACert()
+	if caCert == "" {
+		return nil, nil
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(caCert)) {
+		return nil, errors.New("invalid LDAP CA certificate")
+	}
+	return &tls.Config{RootCAs: pool}, nil
+}
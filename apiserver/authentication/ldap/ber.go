@@ -0,0 +1,236 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ldap
+
+import (
+	"io"
+
+	"github.com/juju/errors"
+)
+
+// The classes of BER-encoded elements.
+const (
+	ClassUniversal   = 0
+	ClassApplication = 1
+	ClassContext     = 2
+)
+
+// The universal tags of the BER-encoded types LDAP uses.
+const (
+	TagBoolean     = 1
+	TagInteger     = 2
+	TagOctetString = 4
+	TagNull        = 5
+	TagEnumerated  = 10
+	TagSequence    = 16
+	TagSet         = 17
+)
+
+// maxPacketSize is the size of the largest packet that will be read.
+// LDAP responses to the requests we make are small.
+const maxPacketSize = 1 << 20
+
+// Packet is a BER-encoded element of an LDAP message. Only the
+// definite-length, low-tag-number form of BER, which is all LDAP uses,
+// is supported.
+type Packet struct {
+	Class    int
+	Tag      int
+	Compound bool
+	// Value holds the content of primitive packets.
+	Value []byte
+	// Children holds the elements of compound packets.
+	Children []*Packet
+}
+
+// Sequence returns a compound universal SEQUENCE packet.
+func Sequence(children ...*Packet) *Packet {
+	return &Packet{Class: ClassUniversal, Tag: TagSequence, Compound: true, Children: children}
+}
+
+// Set returns a compound universal SET packet.
+func Set(children ...*Packet) *Packet {
+	return &Packet{Class: ClassUniversal, Tag: TagSet, Compound: true, Children: children}
+}
+
+// Application returns a compound packet with the given application tag.
+func Application(tag int, children ...*Packet) *Packet {
+	return &Packet{Class: ClassApplication, Tag: tag, Compound: true, Children: children}
+}
+
+// OctetString returns a universal OCTET STRING packet.
+func OctetString(s string) *Packet {
+	return &Packet{Class: ClassUniversal, Tag: TagOctetString, Value: []byte(s)}
+}
+
+// Boolean returns a universal BOOLEAN packet.
+func Boolean(b bool) *Packet {
+	value := byte(0)
+	if b {
+		value = 0xff
+	}
+	return &Packet{Class: ClassUniversal, Tag: TagBoolean, Value: []byte{value}}
+}
+
+// Integer returns a universal INTEGER packet.
+func Integer(i int) *Packet {
+	return &Packet{Class: ClassUniversal, Tag: TagInteger, Value: encodeInt(i)}
+}
+
+// Enumerated returns a universal ENUMERATED packet.
+func Enumerated(i int) *Packet {
+	return &Packet{Class: ClassUniversal, Tag: TagEnumerated, Value: encodeInt(i)}
+}
+
+// Int returns the value of an INTEGER or ENUMERATED packet.
+func (p *Packet) Int() (int, error) {
+	if p.Compound || len(p.Value) == 0 || len(p.Value) > 8 {
+		return 0, errors.Errorf("invalid integer")
+	}
+	// Sign-extend from the first byte.
+	i := int(int8(p.Value[0]))
+	for _, b := range p.Value[1:] {
+		i = i<<8 | int(b)
+	}
+	return i, nil
+}
+
+// Bytes returns the BER encoding of the packet.
+func (p *Packet) Bytes() []byte {
+	content := p.Value
+	if p.Compound {
+		content = nil
+		for _, child := range p.Children {
+			content = append(content, child.Bytes()...)
+		}
+	}
+	identifier := byte(p.Class<<6) | byte(p.Tag)
+	if p.Compound {
+		identifier |= 0x20
+	}
+	data := append([]byte{identifier}, encodeLength(len(content))...)
+	return append(data, content...)
+}
+
+// ReadPacket reads one BER-encoded packet from r.
+func ReadPacket(r io.Reader) (*Packet, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	length := int(header[1])
+	if length&0x80 != 0 {
+		lengthBytes := make([]byte, length&0x7f)
+		if len(lengthBytes) == 0 || len(lengthBytes) > 4 {
+			return nil, errors.New("unsupported packet length")
+		}
+		if _, err := io.ReadFull(r, lengthBytes); err != nil {
+			return nil, errors.Trace(err)
+		}
+		header = append(header, lengthBytes...)
+	}
+	_, length, err := parseHeader(header)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	data := make([]byte, len(header)+length)
+	copy(data, header)
+	if _, err := io.ReadFull(r, data[len(header):]); err != nil {
+		return nil, errors.Trace(err)
+	}
+	p, rest, err := ParsePacket(data)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(rest) != 0 {
+		return nil, errors.New("trailing data after packet")
+	}
+	return p, nil
+}
+
+// ParsePacket parses the BER-encoded packet at the start of data,
+// returning the packet and the data following it.
+func ParsePacket(data []byte) (*Packet, []byte, error) {
+	headerLen, length, err := parseHeader(data)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	if len(data) < headerLen+length {
+		return nil, nil, errors.New("truncated packet")
+	}
+	p := &Packet{
+		Class:    int(data[0] >> 6),
+		Tag:      int(data[0] & 0x1f),
+		Compound: data[0]&0x20 != 0,
+	}
+	content := data[headerLen : headerLen+length]
+	rest := data[headerLen+length:]
+	if !p.Compound {
+		p.Value = content
+		return p, rest, nil
+	}
+	for len(content) > 0 {
+		var child *Packet
+		child, content, err = ParsePacket(content)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		p.Children = append(p.Children, child)
+	}
+	return p, rest, nil
+}
+
+// parseHeader returns the length of the identifier and length octets
+// at the start of data, and the length of the content following them.
+func parseHeader(data []byte) (int, int, error) {
+	if len(data) < 2 {
+		return 0, 0, errors.New("truncated packet")
+	}
+	if data[0]&0x1f == 0x1f {
+		return 0, 0, errors.New("unsupported high tag number")
+	}
+	length := int(data[1])
+	if length&0x80 == 0 {
+		return 2, length, nil
+	}
+	n := length & 0x7f
+	if n == 0 || n > 4 {
+		return 0, 0, errors.New("unsupported packet length")
+	}
+	if len(data) < 2+n {
+		return 0, 0, errors.New("truncated packet")
+	}
+	length = 0
+	for _, b := range data[2 : 2+n] {
+		length = length<<8 | int(b)
+	}
+	if length > maxPacketSize {
+		return 0, 0, errors.Errorf("packet of %d bytes too large", length)
+	}
+	return 2 + n, length, nil
+}
+
+func encodeLength(length int) []byte {
+	if length < 0x80 {
+		return []byte{byte(length)}
+	}
+	var lengthBytes []byte
+	for ; length > 0; length >>= 8 {
+		lengthBytes = append([]byte{byte(length)}, lengthBytes...)
+	}
+	return append([]byte{0x80 | byte(len(lengthBytes))}, lengthBytes...)
+}
+
+func encodeInt(i int) []byte {
+	value := []byte{byte(i)}
+	for {
+		next := i >> 8
+		// Stop once the remaining bytes are only sign extension.
+		if (next == 0 && value[0]&0x80 == 0) || (next == -1 && value[0]&0x80 != 0) {
+			return value
+		}
+		i = next
+		value = append([]byte{byte(i)}, value...)
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ldap_test
+
+import (
+	"bytes"
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/authentication/ldap"
+	"github.com/juju/juju/testing"
+)
+
+type berSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&berSuite{})
+
+func (s *berSuite) TestIntegerEncoding(c *gc.C) {
+	for i, test := range []struct {
+		value   int
+		encoded []byte
+	}{
+		{0, []byte{0x02, 0x01, 0x00}},
+		{3, []byte{0x02, 0x01, 0x03}},
+		{127, []byte{0x02, 0x01, 0x7f}},
+		{128, []byte{0x02, 0x02, 0x00, 0x80}},
+		{256, []byte{0x02, 0x02, 0x01, 0x00}},
+		{-1, []byte{0x02, 0x01, 0xff}},
+		{-129, []byte{0x02, 0x02, 0xff, 0x7f}},
+	} {
+		c.Logf("test %d: %d", i, test.value)
+		p := ldap.Integer(test.value)
+		c.Check(p.Bytes(), jc.DeepEquals, test.encoded)
+		value, err := p.Int()
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(value, gc.Equals, test.value)
+	}
+}
+
+func (s *berSuite) TestRoundTrip(c *gc.C) {
+	p := ldap.Sequence(
+		ldap.Integer(1),
+		ldap.Application(ldap.BindRequestTag,
+			ldap.Integer(3),
+			ldap.OctetString("uid=bob,dc=example,dc=com"),
+			&ldap.Packet{Class: ldap.ClassContext, Tag: 0, Value: []byte("sekrit")},
+		),
+	)
+	data := p.Bytes()
+	c.Check(data[:2], jc.DeepEquals, []byte{0x30, byte(len(data) - 2)})
+
+	parsed, err := ldap.ReadPacket(bytes.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(parsed, jc.DeepEquals, p)
+}
+
+func (s *berSuite) TestLongLength(c *gc.C) {
+	value := strings.Repeat("x", 300)
+	p := ldap.OctetString(value)
+	data := p.Bytes()
+	c.Check(data[:4], jc.DeepEquals, []byte{0x04, 0x82, 0x01, 0x2c})
+
+	parsed, err := ldap.ReadPacket(bytes.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(parsed.Value), gc.Equals, value)
+}
+
+func (s *berSuite) TestNonMinimalLength(c *gc.C) {
+	// Some servers always use the long form of lengths.
+	data := []byte{0x04, 0x84, 0x00, 0x00, 0x00, 0x03, 'b', 'o', 'b'}
+	parsed, err := ldap.ReadPacket(bytes.NewReader(data))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(parsed.Value), gc.Equals, "bob")
+}
+
+func (s *berSuite) TestParseTruncated(c *gc.C) {
+	data := ldap.Sequence(ldap.OctetString("bob")).Bytes()
+	_, _, err := ldap.ParsePacket(data[:len(data)-1])
+	c.Check(err, gc.ErrorMatches, "truncated packet")
+}
+
+func (s *berSuite) TestReadTooLarge(c *gc.C) {
+	data := []byte{0x04, 0x84, 0x7f, 0x00, 0x00, 0x00}
+	_, err := ldap.ReadPacket(bytes.NewReader(data))
+	c.Check(err, gc.ErrorMatches, "packet of 2130706432 bytes too large")
+}
@@ -0,0 +1,303 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package ldap implements the small part of the LDAPv3 protocol (RFC
+// 4511) juju needs to authenticate users against a directory: simple
+// binds and reading the attributes of an entry.
+package ldap
+
+import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// The application tags of the LDAP protocol operations.
+const (
+	BindRequestTag       = 0
+	BindResponseTag      = 1
+	UnbindRequestTag     = 2
+	SearchRequestTag     = 3
+	SearchResultEntryTag = 4
+	SearchResultDoneTag  = 5
+)
+
+// The LDAP result codes juju cares about.
+const (
+	ResultSuccess            = 0
+	ResultNoSuchObject       = 32
+	ResultInvalidCredentials = 49
+)
+
+// The context tags of the parts of requests.
+const (
+	// simpleAuthTag tags the password of a simple bind.
+	simpleAuthTag = 0
+	// presentFilterTag tags a filter matching entries that have an
+	// attribute.
+	presentFilterTag = 7
+)
+
+// dialTimeout is how long to wait to connect to the LDAP server.
+var dialTimeout = 30 * time.Second
+
+// requestTimeout is how long to wait for the LDAP server to answer a
+// request, so that a server that stops answering does not hold up
+// logins.
+var requestTimeout = 30 * time.Second
+
+// Error is the result of an LDAP operation that did not succeed.
+type Error struct {
+	Code    int
+	Message string
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("LDAP result code %d", e.Code)
+	}
+	return fmt.Sprintf("LDAP result code %d: %s", e.Code, e.Message)
+}
+
+// IsInvalidCredentials reports whether err is an LDAP error saying the
+// credentials of a bind were wrong.
+func IsInvalidCredentials(err error) bool {
+	ldapErr, ok := errors.Cause(err).(*Error)
+	return ok && ldapErr.Code == ResultInvalidCredentials
+}
+
+// IsNoSuchObject reports whether err is an LDAP error saying the entry
+// does not exist.
+func IsNoSuchObject(err error) bool {
+	ldapErr, ok := errors.Cause(err).(*Error)
+	return ok && ldapErr.Code == ResultNoSuchObject
+}
+
+// Conn is a connection to an LDAP server. Requests on it are made one
+// at a time.
+type Conn struct {
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+	lastID int
+}
+
+// Dial connects to the LDAP server at the given ldap:// or ldaps://
+// URL. The server's certificate is checked against tlsConfig for
+// ldaps:// URLs, or against the system's CA certificates if tlsConfig
+// is nil. Whether an ldap:// URL, over which passwords are sent
+// unencrypted, may be used is up to the caller.
+func Dial(rawurl string, tlsConfig *tls.Config) (*Conn, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, errors.Annotatef(err, "invalid LDAP URL %q", rawurl)
+	}
+	host := u.Host
+	var conn net.Conn
+	switch u.Scheme {
+	case "ldap":
+		if !strings.Contains(host, ":") {
+			host += ":389"
+		}
+		conn, err = net.DialTimeout("tcp", host, dialTimeout)
+	case "ldaps":
+		if !strings.Contains(host, ":") {
+			host += ":636"
+		}
+		dialer := &net.Dialer{Timeout: dialTimeout}
+		conn, err = tls.DialWithDialer(dialer, "tcp", host, tlsConfig)
+	default:
+		return nil, errors.NotValidf("LDAP URL %q", rawurl)
+	}
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot connect to LDAP server %q", host)
+	}
+	return &Conn{
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+	}, nil
+}
+
+// Close ends the LDAP session and closes the connection.
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// The server does not respond to an unbind request.
+	c.lastID++
+	unbind := &Packet{Class: ClassApplication, Tag: UnbindRequestTag}
+	c.conn.SetDeadline(time.Now().Add(requestTimeout))
+	c.conn.Write(Sequence(Integer(c.lastID), unbind).Bytes())
+	return c.conn.Close()
+}
+
+// Bind authenticates the connection as the entry with the given DN,
+// using its password. An *Error with code ResultInvalidCredentials is
+// returned if the password is wrong.
+func (c *Conn) Bind(dn, password string) error {
+	if password == "" {
+		// An empty password asks for an unauthenticated bind, which
+		// most servers allow and which proves nothing.
+		return &Error{Code: ResultInvalidCredentials, Message: "empty password"}
+	}
+	request := Application(BindRequestTag,
+		Integer(3),
+		OctetString(dn),
+		&Packet{Class: ClassContext, Tag: simpleAuthTag, Value: []byte(password)},
+	)
+	responses, err := c.roundTrip(request, BindResponseTag)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return resultError(responses[len(responses)-1])
+}
+
+// Attributes returns the values of the named attributes of the entry
+// with the given DN. Attributes the entry does not have are omitted.
+func (c *Conn) Attributes(dn string, attrs ...string) (map[string][]string, error) {
+	var attrPackets []*Packet
+	for _, attr := range attrs {
+		attrPackets = append(attrPackets, OctetString(attr))
+	}
+	request := Application(SearchRequestTag,
+		OctetString(dn),
+		// Search only the base object itself...
+		Enumerated(0),
+		// ...never dereferencing aliases...
+		Enumerated(0),
+		// ...for at most one entry, with no time limit...
+		Integer(1),
+		Integer(0),
+		// ...returning attribute values as well as types...
+		Boolean(false),
+		// ...matching any entry.
+		&Packet{Class: ClassContext, Tag: presentFilterTag, Value: []byte("objectClass")},
+		Sequence(attrPackets...),
+	)
+	responses, err := c.roundTrip(request, SearchResultDoneTag)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := resultError(responses[len(responses)-1]); err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := make(map[string][]string)
+	for _, response := range responses[:len(responses)-1] {
+		if response.Tag != SearchResultEntryTag {
+			// Search result references are not followed.
+			continue
+		}
+		if len(response.Children) != 2 {
+			return nil, errors.New("invalid search result entry")
+		}
+		for _, attr := range response.Children[1].Children {
+			if len(attr.Children) != 2 {
+				return nil, errors.New("invalid search result attribute")
+			}
+			name := string(attr.Children[0].Value)
+			for _, value := range attr.Children[1].Children {
+				result[name] = append(result[name], string(value.Value))
+			}
+		}
+	}
+	return result, nil
+}
+
+// roundTrip sends the request to the server and returns the protocol
+// operations of its responses, up to and including the one with the
+// final tag.
+func (c *Conn) roundTrip(request *Packet, finalTag int) ([]*Packet, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastID++
+	id := c.lastID
+	if err := c.conn.SetDeadline(time.Now().Add(requestTimeout)); err != nil {
+		return nil, errors.Annotate(err, "cannot set LDAP request deadline")
+	}
+	if _, err := c.conn.Write(Sequence(Integer(id), request).Bytes()); err != nil {
+		return nil, errors.Annotate(err, "cannot send LDAP request")
+	}
+	var responses []*Packet
+	for {
+		message, err := ReadPacket(c.reader)
+		if err != nil {
+			return nil, errors.Annotate(err, "cannot read LDAP response")
+		}
+		if len(message.Children) < 2 {
+			return nil, errors.New("invalid LDAP message")
+		}
+		messageID, err := message.Children[0].Int()
+		if err != nil {
+			return nil, errors.Annotate(err, "invalid LDAP message ID")
+		}
+		if messageID != id {
+			// Unsolicited notifications have ID 0, and tell of the
+			// server ending the session.
+			return nil, errors.Errorf("unexpected LDAP message with ID %d", messageID)
+		}
+		op := message.Children[1]
+		if op.Class != ClassApplication {
+			return nil, errors.New("invalid LDAP protocol operation")
+		}
+		responses = append(responses, op)
+		if op.Tag == finalTag {
+			return responses, nil
+		}
+	}
+}
+
+// resultError returns the error described by an LDAPResult, or nil if
+// the result is a success.
+func resultError(result *Packet) error {
+	if len(result.Children) < 3 {
+		return errors.New("invalid LDAP result")
+	}
+	code, err := result.Children[0].Int()
+	if err != nil {
+		return errors.Annotate(err, "invalid LDAP result code")
+	}
+	if code == ResultSuccess {
+		return nil
+	}
+	return &Error{
+		Code:    code,
+		Message: string(result.Children[2].Value),
+	}
+}
+
+// Result returns an LDAPResult protocol operation with the given tag,
+// as sent in responses by LDAP servers.
+func Result(tag, code int, message string) *Packet {
+	return Application(tag,
+		Enumerated(code),
+		// The matched DN.
+		OctetString(""),
+		OctetString(message),
+	)
+}
+
+// EscapeDN escapes the characters that are special in the attribute
+// values of distinguished names (RFC 4514), so s can be used as one.
+func EscapeDN(s string) string {
+	var escaped []byte
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case strings.IndexByte(`,+"\<>;=`, ch) >= 0,
+			ch == '#' && i == 0,
+			ch == ' ' && (i == 0 || i == len(s)-1):
+			escaped = append(escaped, '\\', ch)
+		case ch == 0:
+			escaped = append(escaped, `\00`...)
+		default:
+			escaped = append(escaped, ch)
+		}
+	}
+	return string(escaped)
+}
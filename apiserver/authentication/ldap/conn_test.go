@@ -0,0 +1,130 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ldap_test
+
+import (
+	"net"
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/authentication/ldap"
+	ldaptesting "github.com/juju/juju/apiserver/authentication/ldap/testing"
+	"github.com/juju/juju/testing"
+)
+
+const bobDN = "uid=bob,ou=people,dc=example,dc=com"
+
+type connSuite struct {
+	testing.BaseSuite
+	server *ldaptesting.Server
+	conn   *ldap.Conn
+}
+
+var _ = gc.Suite(&connSuite{})
+
+func (s *connSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	server, err := ldaptesting.NewServer()
+	c.Assert(err, jc.ErrorIsNil)
+	s.server = server
+	s.AddCleanup(func(*gc.C) { server.Close() })
+	s.server.AddEntry(bobDN, ldaptesting.Entry{
+		Password: "sekrit",
+		Attributes: map[string][]string{
+			"displayName": {"Bob Brown"},
+			"mail":        {"bob@example.com", "bobby@example.com"},
+		},
+	})
+
+	s.conn, err = ldap.Dial(s.server.URL(), nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.AddCleanup(func(*gc.C) { s.conn.Close() })
+}
+
+func (s *connSuite) TestBind(c *gc.C) {
+	err := s.conn.Bind(bobDN, "sekrit")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.server.Binds(), jc.DeepEquals, []string{bobDN})
+}
+
+func (s *connSuite) TestBindWrongPassword(c *gc.C) {
+	err := s.conn.Bind(bobDN, "wrong")
+	c.Check(err, gc.ErrorMatches, "LDAP result code 49: invalid credentials")
+	c.Check(ldap.IsInvalidCredentials(err), jc.IsTrue)
+
+	// The connection can still be used.
+	err = s.conn.Bind(bobDN, "sekrit")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *connSuite) TestBindUnknownEntry(c *gc.C) {
+	err := s.conn.Bind("uid=alice,ou=people,dc=example,dc=com", "sekrit")
+	c.Check(ldap.IsInvalidCredentials(err), jc.IsTrue)
+}
+
+func (s *connSuite) TestBindEmptyPassword(c *gc.C) {
+	err := s.conn.Bind(bobDN, "")
+	c.Check(ldap.IsInvalidCredentials(err), jc.IsTrue)
+	// Unauthenticated binds are never attempted.
+	c.Check(s.server.Binds(), gc.HasLen, 0)
+}
+
+func (s *connSuite) TestAttributes(c *gc.C) {
+	err := s.conn.Bind(bobDN, "sekrit")
+	c.Assert(err, jc.ErrorIsNil)
+
+	attrs, err := s.conn.Attributes(bobDN, "displayName", "mail", "telephoneNumber")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(attrs, jc.DeepEquals, map[string][]string{
+		"displayName": {"Bob Brown"},
+		"mail":        {"bob@example.com", "bobby@example.com"},
+	})
+}
+
+func (s *connSuite) TestAttributesNoSuchObject(c *gc.C) {
+	_, err := s.conn.Attributes("uid=alice,ou=people,dc=example,dc=com", "displayName")
+	c.Check(err, gc.ErrorMatches, "LDAP result code 32: no such object")
+	c.Check(ldap.IsNoSuchObject(err), jc.IsTrue)
+}
+
+func (s *connSuite) TestRequestTimeout(c *gc.C) {
+	// The listener accepts connections, but nothing answers on them.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, jc.ErrorIsNil)
+	defer listener.Close()
+	s.PatchValue(ldap.RequestTimeout, 10*time.Millisecond)
+
+	conn, err := ldap.Dial("ldap://"+listener.Addr().String(), nil)
+	c.Assert(err, jc.ErrorIsNil)
+	defer conn.Close()
+	err = conn.Bind(bobDN, "sekrit")
+	c.Check(err, gc.ErrorMatches, "cannot read LDAP response: .*i/o timeout")
+}
+
+func (s *connSuite) TestDialInvalidURL(c *gc.C) {
+	_, err := ldap.Dial("http://ldap.example.com", nil)
+	c.Check(err, gc.ErrorMatches, `LDAP URL "http://ldap.example.com" not valid`)
+}
+
+type escapeDNSuite struct{}
+
+var _ = gc.Suite(&escapeDNSuite{})
+
+func (*escapeDNSuite) TestEscapeDN(c *gc.C) {
+	for _, test := range []struct {
+		value   string
+		escaped string
+	}{
+		{"bob", "bob"},
+		{"bob+admin", `bob\+admin`},
+		{`a,b=c"d\e<f>g;h`, `a\,b\=c\"d\\e\<f\>g\;h`},
+		{"#bob", `\#bob`},
+		{"bob#", "bob#"},
+		{" bob ", `\ bob\ `},
+	} {
+		c.Check(ldap.EscapeDN(test.value), gc.Equals, test.escaped)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package testing
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/juju/juju/apiserver/authentication/ldap"
+)
+
+// Entry is an entry in the directory of a Server.
+type Entry struct {
+	// Password is the password simple binds as the entry need.
+	Password string
+	// Attributes holds the values of the entry's attributes.
+	Attributes map[string][]string
+}
+
+// Server is an in-process LDAP server for tests. It supports simple
+// binds and base object searches of the entries added to it.
+type Server struct {
+	listener net.Listener
+	wg       sync.WaitGroup
+
+	mu      sync.Mutex
+	entries map[string]Entry
+	binds   []string
+}
+
+// NewServer starts a new LDAP server listening on a local port.
+func NewServer() (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	srv := &Server{
+		listener: listener,
+		entries:  make(map[string]Entry),
+	}
+	srv.wg.Add(1)
+	go srv.serve()
+	return srv, nil
+}
+
+// URL returns the ldap:// URL of the server.
+func (srv *Server) URL() string {
+	return "ldap://" + srv.listener.Addr().String()
+}
+
+// AddEntry adds an entry with the given DN to the server's directory.
+func (srv *Server) AddEntry(dn string, entry Entry) {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.entries[normalizeDN(dn)] = entry
+}
+
+// Binds returns the DNs of the binds attempted so far.
+func (srv *Server) Binds() []string {
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	return append([]string(nil), srv.binds...)
+}
+
+// Close stops the server.
+func (srv *Server) Close() error {
+	err := srv.listener.Close()
+	srv.wg.Wait()
+	return err
+}
+
+func (srv *Server) serve() {
+	defer srv.wg.Done()
+	for {
+		conn, err := srv.listener.Accept()
+		if err != nil {
+			return
+		}
+		go srv.serveConn(conn)
+	}
+}
+
+func (srv *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		message, err := ldap.ReadPacket(reader)
+		if err != nil || len(message.Children) < 2 {
+			return
+		}
+		id := message.Children[0]
+		op := message.Children[1]
+		var responses []*ldap.Packet
+		switch op.Tag {
+		case ldap.BindRequestTag:
+			responses = srv.bind(op)
+		case ldap.SearchRequestTag:
+			responses = srv.search(op)
+		default:
+			// Unbind, or a request we know nothing of.
+			return
+		}
+		for _, response := range responses {
+			if _, err := conn.Write(ldap.Sequence(id, response).Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (srv *Server) bind(op *ldap.Packet) []*ldap.Packet {
+	if len(op.Children) != 3 {
+		return []*ldap.Packet{ldap.Result(ldap.BindResponseTag, 2, "protocol error")}
+	}
+	dn := string(op.Children[1].Value)
+	password := string(op.Children[2].Value)
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	srv.binds = append(srv.binds, dn)
+	entry, ok := srv.entries[normalizeDN(dn)]
+	if !ok || password == "" || entry.Password != password {
+		return []*ldap.Packet{ldap.Result(ldap.BindResponseTag, ldap.ResultInvalidCredentials, "invalid credentials")}
+	}
+	return []*ldap.Packet{ldap.Result(ldap.BindResponseTag, ldap.ResultSuccess, "")}
+}
+
+func (srv *Server) search(op *ldap.Packet) []*ldap.Packet {
+	if len(op.Children) != 8 {
+		return []*ldap.Packet{ldap.Result(ldap.SearchResultDoneTag, 2, "protocol error")}
+	}
+	dn := string(op.Children[0].Value)
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	entry, ok := srv.entries[normalizeDN(dn)]
+	if !ok {
+		return []*ldap.Packet{ldap.Result(ldap.SearchResultDoneTag, ldap.ResultNoSuchObject, "no such object")}
+	}
+	var attrs []*ldap.Packet
+	for _, attr := range op.Children[7].Children {
+		name := string(attr.Value)
+		values, ok := entry.Attributes[name]
+		if !ok {
+			continue
+		}
+		var valuePackets []*ldap.Packet
+		for _, value := range values {
+			valuePackets = append(valuePackets, ldap.OctetString(value))
+		}
+		attrs = append(attrs, ldap.Sequence(ldap.OctetString(name), ldap.Set(valuePackets...)))
+	}
+	return []*ldap.Packet{
+		ldap.Application(ldap.SearchResultEntryTag, ldap.OctetString(dn), ldap.Sequence(attrs...)),
+		ldap.Result(ldap.SearchResultDoneTag, ldap.ResultSuccess, ""),
+	}
+}
+
+// normalizeDN returns the DN in a form that compares equal for DNs
+// that differ only in case, as is usual for the attributes in them.
+func normalizeDN(dn string) string {
+	return strings.ToLower(dn)
+}
@@ -0,0 +1,92 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package authentication_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/authentication"
+	ldaptesting "github.com/juju/juju/apiserver/authentication/ldap/testing"
+	"github.com/juju/juju/apiserver/common"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type ldapAuthenticatorSuite struct {
+	coretesting.BaseSuite
+	server        *ldaptesting.Server
+	authenticator authentication.ExternalAuthenticator
+}
+
+var _ = gc.Suite(&ldapAuthenticatorSuite{})
+
+func (s *ldapAuthenticatorSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	server, err := ldaptesting.NewServer()
+	c.Assert(err, jc.ErrorIsNil)
+	s.server = server
+	s.AddCleanup(func(*gc.C) { server.Close() })
+	s.server.AddEntry("uid=bob,ou=people,dc=example,dc=com", ldaptesting.Entry{
+		Password: "sekrit",
+		Attributes: map[string][]string{
+			"cn": {"Bob Brown"},
+		},
+	})
+
+	cfg := coretesting.CustomEnvironConfig(c, coretesting.Attrs{
+		"ldap-url":                    s.server.URL(),
+		"ldap-user-dn":                "uid={username},ou=people,dc=example,dc=com",
+		"ldap-display-name-attribute": "cn",
+		"ldap-allow-insecure":         true,
+	})
+	s.authenticator = authentication.NewExternalAuthenticator(cfg)
+	c.Assert(s.authenticator, gc.NotNil)
+}
+
+func (s *ldapAuthenticatorSuite) TestNotConfigured(c *gc.C) {
+	cfg := coretesting.EnvironConfig(c)
+	c.Assert(authentication.NewExternalAuthenticator(cfg), gc.IsNil)
+}
+
+func (s *ldapAuthenticatorSuite) TestIdentityProvider(c *gc.C) {
+	c.Assert(s.authenticator.IdentityProvider(), gc.Equals, "ldap")
+}
+
+func (s *ldapAuthenticatorSuite) TestAuthenticateUser(c *gc.C) {
+	user, err := s.authenticator.AuthenticateUser("bob", "sekrit")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user, jc.DeepEquals, &authentication.ExternalUser{
+		Name:        "bob",
+		DisplayName: "Bob Brown",
+	})
+	c.Assert(s.server.Binds(), jc.DeepEquals, []string{"uid=bob,ou=people,dc=example,dc=com"})
+}
+
+func (s *ldapAuthenticatorSuite) TestAuthenticateUserWrongPassword(c *gc.C) {
+	_, err := s.authenticator.AuthenticateUser("bob", "wrong")
+	c.Assert(err, gc.Equals, common.ErrBadCreds)
+}
+
+func (s *ldapAuthenticatorSuite) TestAuthenticateUserEmptyPassword(c *gc.C) {
+	_, err := s.authenticator.AuthenticateUser("bob", "")
+	c.Assert(err, gc.Equals, common.ErrBadCreds)
+	c.Assert(s.server.Binds(), gc.HasLen, 0)
+}
+
+func (s *ldapAuthenticatorSuite) TestAuthenticateUserUnknown(c *gc.C) {
+	_, err := s.authenticator.AuthenticateUser("alice", "sekrit")
+	c.Assert(err, gc.Equals, common.ErrBadCreds)
+}
+
+func (s *ldapAuthenticatorSuite) TestAuthenticateUserEscapesName(c *gc.C) {
+	_, err := s.authenticator.AuthenticateUser("bob,ou=admins", "sekrit")
+	c.Assert(err, gc.Equals, common.ErrBadCreds)
+	c.Assert(s.server.Binds(), jc.DeepEquals, []string{`uid=bob\,ou\=admins,ou=people,dc=example,dc=com`})
+}
+
+func (s *ldapAuthenticatorSuite) TestAuthenticateUserServerDown(c *gc.C) {
+	s.server.Close()
+	_, err := s.authenticator.AuthenticateUser("bob", "sekrit")
+	c.Assert(err, gc.ErrorMatches, `cannot connect to LDAP server .*`)
+}
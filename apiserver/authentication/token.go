@@ -0,0 +1,52 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package authentication
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/state"
+)
+
+// APITokenGetter gets API tokens by id.
+type APITokenGetter interface {
+	APIToken(id string) (*state.APIToken, error)
+}
+
+// APITokenAuthenticator authenticates users logging in with the
+// credentials of API tokens rather than their passwords.
+type APITokenAuthenticator struct {
+	Tokens APITokenGetter
+}
+
+var _ EntityAuthenticator = (*APITokenAuthenticator)(nil)
+
+// Authenticate authenticates the provided entity and returns an error on authentication failure.
+func (a *APITokenAuthenticator) Authenticate(entity state.Entity, credentials, nonce string) error {
+	user, ok := entity.(*state.User)
+	if !ok {
+		return common.ErrBadRequest
+	}
+	if user.IsDisabled() {
+		return common.ErrBadCreds
+	}
+	id, secret, ok := state.ParseAPITokenCredentials(credentials)
+	if !ok {
+		return common.ErrBadCreds
+	}
+	token, err := a.Tokens.APIToken(id)
+	if errors.IsNotFound(err) {
+		return common.ErrBadCreds
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	if token.User().Username() != user.UserTag().Username() {
+		return common.ErrBadCreds
+	}
+	if token.Expired() || !token.SecretValid(secret) {
+		return common.ErrBadCreds
+	}
+	return nil
+}
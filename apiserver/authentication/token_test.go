@@ -0,0 +1,87 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package authentication_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/authentication"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type apiTokenAuthenticatorSuite struct {
+	jujutesting.JujuConnSuite
+	user          *state.User
+	credentials   string
+	authenticator *authentication.APITokenAuthenticator
+}
+
+var _ = gc.Suite(&apiTokenAuthenticatorSuite{})
+
+func (s *apiTokenAuthenticatorSuite) SetUpTest(c *gc.C) {
+	s.JujuConnSuite.SetUpTest(c)
+	s.user = s.Factory.MakeUser(c, &factory.UserParams{Name: "bob"})
+	var err error
+	_, s.credentials, err = s.State.AddAPIToken(state.APITokenParams{
+		User:    s.user.UserTag(),
+		Expires: time.Now().Add(time.Hour),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	s.authenticator = &authentication.APITokenAuthenticator{Tokens: s.State}
+}
+
+func (s *apiTokenAuthenticatorSuite) TestValidToken(c *gc.C) {
+	err := s.authenticator.Authenticate(s.user, s.credentials, "")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *apiTokenAuthenticatorSuite) TestWrongSecret(c *gc.C) {
+	id, _, ok := state.ParseAPITokenCredentials(s.credentials)
+	c.Assert(ok, jc.IsTrue)
+	err := s.authenticator.Authenticate(s.user, state.APITokenCredentials(id, "wrong"), "")
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
+
+func (s *apiTokenAuthenticatorSuite) TestUnknownToken(c *gc.C) {
+	err := s.authenticator.Authenticate(s.user, state.APITokenCredentials("missing", "secret"), "")
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
+
+func (s *apiTokenAuthenticatorSuite) TestPasswordRejected(c *gc.C) {
+	err := s.authenticator.Authenticate(s.user, "password", "")
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
+
+func (s *apiTokenAuthenticatorSuite) TestOtherUsersToken(c *gc.C) {
+	alice := s.Factory.MakeUser(c, &factory.UserParams{Name: "alice"})
+	err := s.authenticator.Authenticate(alice, s.credentials, "")
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
+
+func (s *apiTokenAuthenticatorSuite) TestDisabledUser(c *gc.C) {
+	err := s.user.Disable()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.authenticator.Authenticate(s.user, s.credentials, "")
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
+
+func (s *apiTokenAuthenticatorSuite) TestRevokedToken(c *gc.C) {
+	id, _, _ := state.ParseAPITokenCredentials(s.credentials)
+	err := s.State.RevokeAPIToken(id)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.authenticator.Authenticate(s.user, s.credentials, "")
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+}
+
+func (s *apiTokenAuthenticatorSuite) TestMachineLoginFails(c *gc.C) {
+	machine, err := s.State.AddMachine("quantal", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.authenticator.Authenticate(machine, s.credentials, "")
+	c.Assert(err, gc.ErrorMatches, "invalid request")
+}
@@ -11,15 +11,29 @@ import (
 // UserIdentityProvider performs authentication for users.
 type UserAuthenticator struct {
 	AgentAuthenticator
+
+	// External checks the passwords of users juju does not hold
+	// passwords for. Such users cannot log in if it is nil.
+	External ExternalAuthenticator
 }
 
 var _ EntityAuthenticator = (*UserAuthenticator)(nil)
 
 // Authenticate authenticates the provided entity and returns an error on authentication failure.
 func (u *UserAuthenticator) Authenticate(entity state.Entity, password, nonce string) error {
-	if _, ok := entity.(*state.User); ok {
+	user, ok := entity.(*state.User)
+	if !ok {
+		return common.ErrBadRequest
+	}
+	if user.IdentityProvider() == "" {
 		return u.AgentAuthenticator.Authenticate(entity, password, nonce)
 	}
-
-	return common.ErrBadRequest
+	if user.IsDisabled() {
+		return common.ErrBadCreds
+	}
+	if u.External == nil || u.External.IdentityProvider() != user.IdentityProvider() {
+		return common.ErrBadCreds
+	}
+	_, err := u.External.AuthenticateUser(user.Name(), password)
+	return err
 }
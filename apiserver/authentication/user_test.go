@@ -9,6 +9,7 @@ import (
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/apiserver/authentication"
+	"github.com/juju/juju/apiserver/common"
 	jujutesting "github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/testing/factory"
@@ -102,3 +103,81 @@ func (s *userAuthenticatorSuite) TestInvalidRelationLogin(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "invalid request")
 
 }
+
+type fakeExternalAuthenticator struct {
+	provider string
+	password string
+	names    []string
+}
+
+func (a *fakeExternalAuthenticator) IdentityProvider() string {
+	return a.provider
+}
+
+func (a *fakeExternalAuthenticator) AuthenticateUser(name, password string) (*authentication.ExternalUser, error) {
+	a.names = append(a.names, name)
+	if password != a.password {
+		return nil, common.ErrBadCreds
+	}
+	return &authentication.ExternalUser{Name: name}, nil
+}
+
+func (s *userAuthenticatorSuite) TestExternalUserLogin(c *gc.C) {
+	user, err := s.State.AddExternalUser("bob", "Bob Brown", "ldap", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+
+	external := &fakeExternalAuthenticator{provider: "ldap", password: "sekrit"}
+	authenticator := &authentication.UserAuthenticator{External: external}
+	err = authenticator.Authenticate(user, "sekrit", "")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(external.names, jc.DeepEquals, []string{"bob"})
+
+	err = authenticator.Authenticate(user, "wrong", "")
+	c.Assert(err, gc.Equals, common.ErrBadCreds)
+}
+
+func (s *userAuthenticatorSuite) TestExternalUserLoginNoAuthenticator(c *gc.C) {
+	user, err := s.State.AddExternalUser("bob", "Bob Brown", "ldap", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+
+	authenticator := &authentication.UserAuthenticator{}
+	err = authenticator.Authenticate(user, "sekrit", "")
+	c.Assert(err, gc.Equals, common.ErrBadCreds)
+}
+
+func (s *userAuthenticatorSuite) TestExternalUserLoginOtherProvider(c *gc.C) {
+	user, err := s.State.AddExternalUser("bob", "Bob Brown", "ldap", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+
+	external := &fakeExternalAuthenticator{provider: "other", password: "sekrit"}
+	authenticator := &authentication.UserAuthenticator{External: external}
+	err = authenticator.Authenticate(user, "sekrit", "")
+	c.Assert(err, gc.Equals, common.ErrBadCreds)
+	c.Assert(external.names, gc.HasLen, 0)
+}
+
+func (s *userAuthenticatorSuite) TestExternalUserLoginDisabled(c *gc.C) {
+	user, err := s.State.AddExternalUser("bob", "Bob Brown", "ldap", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+	err = user.Disable()
+	c.Assert(err, jc.ErrorIsNil)
+
+	external := &fakeExternalAuthenticator{provider: "ldap", password: "sekrit"}
+	authenticator := &authentication.UserAuthenticator{External: external}
+	err = authenticator.Authenticate(user, "sekrit", "")
+	c.Assert(err, gc.Equals, common.ErrBadCreds)
+	c.Assert(external.names, gc.HasLen, 0)
+}
+
+func (s *userAuthenticatorSuite) TestLocalUserLoginIgnoresExternal(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{
+		Name:     "bobbrown",
+		Password: "password",
+	})
+
+	external := &fakeExternalAuthenticator{provider: "ldap", password: "sekrit"}
+	authenticator := &authentication.UserAuthenticator{External: external}
+	err := authenticator.Authenticate(user, "sekrit", "")
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+	c.Assert(external.names, gc.HasLen, 0)
+}
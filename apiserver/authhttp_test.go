@@ -121,7 +121,7 @@ func (s *userAuthHttpSuite) setupOtherEnvironment(c *gc.C) *state.State {
 	envState := s.Factory.MakeEnvironment(c, nil)
 	s.AddCleanup(func(*gc.C) { envState.Close() })
 	user := s.Factory.MakeUser(c, nil)
-	_, err := envState.AddEnvironmentUser(user.UserTag(), s.userTag, "")
+	_, err := envState.AddEnvironmentUser(user.UserTag(), s.userTag, "", state.EnvironmentAdminAccess)
 	c.Assert(err, jc.ErrorIsNil)
 	s.userTag = user.UserTag()
 	s.password = "password"
@@ -129,6 +129,14 @@ func (s *userAuthHttpSuite) setupOtherEnvironment(c *gc.C) *state.State {
 	return envState
 }
 
+// setUserAccess makes the suite's requests be made by a new user with
+// the given access to the environment.
+func (s *userAuthHttpSuite) setUserAccess(c *gc.C, access state.EnvironmentAccess) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Password: s.password, NoEnvUser: true})
+	s.Factory.MakeEnvUser(c, &factory.EnvUserParams{User: user.Name(), Access: access})
+	s.userTag = user.UserTag()
+}
+
 func (s *userAuthHttpSuite) authRequest(c *gc.C, method, uri, contentType string, body io.Reader) (*http.Response, error) {
 	return s.sendRequest(c, s.userTag.String(), s.password, method, uri, contentType, body)
 }
@@ -9,6 +9,7 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"time"
 
 	"github.com/juju/errors"
 
@@ -38,8 +39,14 @@ func (h *backupHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	if err := stateWrapper.authenticateUser(req); err != nil {
-		h.authError(resp, h)
+	// Backups hold the secrets of the environment.
+	start := time.Now()
+	user, err := stateWrapper.authorizeUser(req, state.EnvironmentAdminAccess)
+	if err != nil {
+		if req.Method == "PUT" {
+			stateWrapper.auditRequest(user, "Backups", req, start, err)
+		}
+		h.authorizeError(resp, h, err)
 		return
 	}
 
@@ -58,6 +65,7 @@ func (h *backupHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 	case "PUT":
 		logger.Infof("handling backups upload request")
 		id, err := h.upload(backups, resp, req)
+		stateWrapper.auditRequest(user, "Backups", req, start, err)
 		if err != nil {
 			h.sendError(resp, http.StatusInternalServerError, err.Error())
 			return
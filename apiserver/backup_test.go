@@ -76,6 +76,13 @@ func (s *backupsSuite) TestRequiresAuth(c *gc.C) {
 	s.checkErrorResponse(c, resp, http.StatusUnauthorized, "unauthorized")
 }
 
+func (s *backupsSuite) TestRequiresAdminAccess(c *gc.C) {
+	s.setUserAccess(c, state.EnvironmentWriteAccess)
+	resp, err := s.authRequest(c, "GET", s.backupURL(c), "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkErrorResponse(c, resp, http.StatusForbidden, "permission denied")
+}
+
 func (s *backupsSuite) checkInvalidMethod(c *gc.C, method, url string) {
 	resp, err := s.authRequest(c, method, url, "", nil)
 	c.Assert(err, jc.ErrorIsNil)
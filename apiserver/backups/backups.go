@@ -8,6 +8,7 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
+	"gopkg.in/mgo.v2/bson"
 
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/params"
@@ -105,6 +106,11 @@ func ResultFromMetadata(meta *backups.Metadata) params.BackupsMetadataResult {
 		result.Finished = *meta.Finished
 	}
 	result.Notes = meta.Notes
+	result.KeyFingerprint = meta.KeyFingerprint
+	result.Location = meta.Location
+	result.Base = meta.Base
+	result.OplogStart = int64(meta.OplogStart)
+	result.OplogEnd = int64(meta.OplogEnd)
 
 	result.Environment = meta.Origin.Environment
 	result.Machine = meta.Origin.Machine
@@ -128,6 +134,22 @@ func MetadataFromResult(result params.BackupsMetadataResult) *backups.Metadata {
 	meta.Origin.Hostname = result.Hostname
 	meta.Origin.Version = result.Version
 	meta.Notes = result.Notes
+	meta.KeyFingerprint = result.KeyFingerprint
+	meta.Base = result.Base
+	meta.OplogStart = bson.MongoTimestamp(result.OplogStart)
+	meta.OplogEnd = bson.MongoTimestamp(result.OplogEnd)
 	meta.SetFileInfo(result.Size, result.Checksum, result.ChecksumFormat)
 	return meta
 }
+
+// KeyFromParams returns the backups encryption key held by the params,
+// or nil if there is none.
+func KeyFromParams(key *params.BackupsKey) *backups.EncryptionKey {
+	if key == nil {
+		return nil
+	}
+	return &backups.EncryptionKey{
+		Passphrase: key.Passphrase,
+		PublicKey:  key.PublicKey,
+	}
+}
@@ -8,13 +8,15 @@ import (
 	"github.com/juju/replicaset"
 
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/mongo"
 	"github.com/juju/juju/state/backups"
 )
 
 var waitUntilReady = replicaset.WaitUntilReady
 
 // Create is the API method that requests juju to create a new backup
-// of its state.  It returns the metadata for that backup.
+// of its state.  It returns the metadata for that backup.  An
+// incremental backup builds on the latest full backup.
 func (a *API) Create(args params.BackupsCreateArgs) (p params.BackupsMetadataResult, err error) {
 	backupsMethods, closer := newBackups(a.st)
 	defer closer.Close()
@@ -33,14 +35,29 @@ func (a *API) Create(args params.BackupsCreateArgs) (p params.BackupsMetadataRes
 	if err != nil {
 		return p, errors.Trace(err)
 	}
+	dbInfo.Oplog = mongo.GetOplog(session)
 
 	meta, err := backups.NewMetadataState(a.st, a.machineID)
 	if err != nil {
 		return p, errors.Trace(err)
 	}
 	meta.Notes = args.Notes
+	if args.Incremental {
+		if args.Key != nil {
+			return p, errors.New("cannot encrypt incremental backups")
+		}
+		list, err := backupsMethods.List()
+		if err != nil {
+			return p, errors.Trace(err)
+		}
+		base := backups.IncrementBase(list)
+		if base == nil {
+			return p, errors.New("no full backup to build an incremental backup on; create a full backup first")
+		}
+		meta.Base = base.ID()
+	}
 
-	err = backupsMethods.Create(meta, a.paths, dbInfo)
+	err = backupsMethods.Create(meta, a.paths, dbInfo, KeyFromParams(args.Key))
 	if err != nil {
 		return p, errors.Trace(err)
 	}
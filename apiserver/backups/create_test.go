@@ -10,6 +10,8 @@ import (
 
 	"github.com/juju/juju/apiserver/backups"
 	"github.com/juju/juju/apiserver/params"
+	statebackups "github.com/juju/juju/state/backups"
+	backupstesting "github.com/juju/juju/state/backups/testing"
 )
 
 func (s *backupsSuite) TestCreateOkay(c *gc.C) {
@@ -42,6 +44,20 @@ func (s *backupsSuite) TestCreateNotes(c *gc.C) {
 	c.Check(result, gc.DeepEquals, expected)
 }
 
+func (s *backupsSuite) TestCreateEncrypted(c *gc.C) {
+	s.PatchValue(backups.WaitUntilReady,
+		func(*mgo.Session, int) error { return nil },
+	)
+	fake := s.setBackups(c, s.meta, "")
+	args := params.BackupsCreateArgs{
+		Key: &params.BackupsKey{Passphrase: "secret"},
+	}
+	_, err := s.api.Create(args)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(fake.KeyArg, jc.DeepEquals, &statebackups.EncryptionKey{Passphrase: "secret"})
+}
+
 func (s *backupsSuite) TestCreateError(c *gc.C) {
 	s.setBackups(c, nil, "failed!")
 	s.PatchValue(backups.WaitUntilReady,
@@ -52,3 +68,44 @@ func (s *backupsSuite) TestCreateError(c *gc.C) {
 
 	c.Check(err, gc.ErrorMatches, "failed!")
 }
+
+func (s *backupsSuite) TestCreateIncremental(c *gc.C) {
+	s.PatchValue(backups.WaitUntilReady,
+		func(*mgo.Session, int) error { return nil },
+	)
+	base := backupstesting.NewMetadata()
+	base.OplogEnd = 6163845091342417920
+	fake := s.setBackups(c, s.meta, "")
+	fake.MetaList = []*statebackups.Metadata{base}
+	args := params.BackupsCreateArgs{Incremental: true}
+	_, err := s.api.Create(args)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(fake.BaseArg, gc.Equals, base.ID())
+	c.Check(fake.DBInfoArg.Oplog, gc.NotNil)
+}
+
+func (s *backupsSuite) TestCreateIncrementalEncrypted(c *gc.C) {
+	s.PatchValue(backups.WaitUntilReady,
+		func(*mgo.Session, int) error { return nil },
+	)
+	s.setBackups(c, s.meta, "")
+	args := params.BackupsCreateArgs{
+		Incremental: true,
+		Key:         &params.BackupsKey{Passphrase: "secret"},
+	}
+	_, err := s.api.Create(args)
+
+	c.Check(err, gc.ErrorMatches, "cannot encrypt incremental backups")
+}
+
+func (s *backupsSuite) TestCreateIncrementalWithoutBase(c *gc.C) {
+	s.PatchValue(backups.WaitUntilReady,
+		func(*mgo.Session, int) error { return nil },
+	)
+	s.setBackups(c, s.meta, "")
+	args := params.BackupsCreateArgs{Incremental: true}
+	_, err := s.api.Create(args)
+
+	c.Check(err, gc.ErrorMatches, "no full backup to build an incremental backup on; create a full backup first")
+}
@@ -63,6 +63,7 @@ func (a *API) Restore(p params.RestoreArgs) error {
 		NewInstId:      instanceId,
 		NewInstTag:     machine.Tag(),
 		NewInstSeries:  machine.Series(),
+		Until:          p.Until,
 	}
 	if err := backup.Restore(p.BackupId, restoreArgs); err != nil {
 		return errors.Annotate(err, "restore failed")
@@ -0,0 +1,42 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state/backups"
+)
+
+// Schedule returns the schedule of automatic backups.
+func (a *API) Schedule() (params.BackupsSchedule, error) {
+	var result params.BackupsSchedule
+	schedule, err := backups.GetSchedule(a.st)
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	result.Interval = schedule.Interval
+	result.KeepDaily = schedule.KeepDaily
+	result.KeepWeekly = schedule.KeepWeekly
+	result.PublicKey = schedule.PublicKey
+	if key := schedule.Key(); key != nil {
+		result.KeyFingerprint, err = key.Fingerprint()
+		if err != nil {
+			return result, errors.Trace(err)
+		}
+	}
+	return result, nil
+}
+
+// SetSchedule replaces the schedule of automatic backups.
+func (a *API) SetSchedule(args params.BackupsSchedule) error {
+	schedule := backups.Schedule{
+		Interval:   args.Interval,
+		KeepDaily:  args.KeepDaily,
+		KeepWeekly: args.KeepWeekly,
+		PublicKey:  args.PublicKey,
+	}
+	return errors.Trace(backups.SetSchedule(a.st, schedule))
+}
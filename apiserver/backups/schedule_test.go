@@ -0,0 +1,46 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+)
+
+func (s *backupsSuite) TestScheduleNotSet(c *gc.C) {
+	result, err := s.api.Schedule()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result, jc.DeepEquals, params.BackupsSchedule{})
+}
+
+func (s *backupsSuite) TestSetSchedule(c *gc.C) {
+	args := params.BackupsSchedule{
+		Interval:   24 * time.Hour,
+		KeepDaily:  7,
+		KeepWeekly: 4,
+		// The fingerprint is computed from the key.
+		KeyFingerprint: "ignored",
+	}
+	err := s.api.SetSchedule(args)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.api.Schedule()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result, jc.DeepEquals, params.BackupsSchedule{
+		Interval:   24 * time.Hour,
+		KeepDaily:  7,
+		KeepWeekly: 4,
+	})
+}
+
+func (s *backupsSuite) TestSetScheduleInvalid(c *gc.C) {
+	err := s.api.SetSchedule(params.BackupsSchedule{Interval: time.Minute})
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(err, gc.ErrorMatches, "interval 1m0s shorter than 1h0m0s not valid")
+}
@@ -4,7 +4,10 @@
 package block
 
 import (
+	"time"
+
 	"github.com/juju/errors"
+	"github.com/juju/names"
 
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/params"
@@ -21,11 +24,11 @@ type Block interface {
 	List() (params.BlockResults, error)
 
 	// SwitchBlockOn switches desired block type on for this
-	// environment.
+	// environment, or for a service or machine in it.
 	SwitchBlockOn(params.BlockSwitchParams) params.ErrorResult
 
 	// SwitchBlockOff switches desired block type off for this
-	// environment.
+	// environment, or for a service or machine in it.
 	SwitchBlockOff(params.BlockSwitchParams) params.ErrorResult
 }
 
@@ -82,17 +85,59 @@ func convertBlock(b state.Block) params.BlockResult {
 		Type:    b.Type().String(),
 		Message: b.Message(),
 	}
+	if expires := b.Expires(); !expires.IsZero() {
+		result.Result.Expires = &expires
+	}
 	return result
 }
 
 // SwitchBlockOn implements Block.SwitchBlockOn().
 func (a *API) SwitchBlockOn(args params.BlockSwitchParams) params.ErrorResult {
-	err := a.access.SwitchBlockOn(state.ParseBlockType(args.Type), args.Message)
+	blockType := state.ParseBlockType(args.Type)
+	if args.Tag == "" && args.TTL == 0 {
+		err := a.access.SwitchBlockOn(blockType, args.Message)
+		return params.ErrorResult{Error: common.ServerError(err)}
+	}
+	tag, err := a.blockTarget(args.Tag)
+	if err != nil {
+		return params.ErrorResult{Error: common.ServerError(err)}
+	}
+	if args.TTL < 0 {
+		err := errors.NotValidf("block TTL %v", args.TTL)
+		return params.ErrorResult{Error: common.ServerError(err)}
+	}
+	var expires time.Time
+	if args.TTL > 0 {
+		expires = time.Now().Add(args.TTL)
+	}
+	err = a.access.SwitchEntityBlockOn(blockType, tag, args.Message, expires)
 	return params.ErrorResult{Error: common.ServerError(err)}
 }
 
 // SwitchBlockOff implements Block.SwitchBlockOff().
 func (a *API) SwitchBlockOff(args params.BlockSwitchParams) params.ErrorResult {
-	err := a.access.SwitchBlockOff(state.ParseBlockType(args.Type))
+	blockType := state.ParseBlockType(args.Type)
+	if args.Tag == "" {
+		err := a.access.SwitchBlockOff(blockType)
+		return params.ErrorResult{Error: common.ServerError(err)}
+	}
+	tag, err := a.blockTarget(args.Tag)
+	if err != nil {
+		return params.ErrorResult{Error: common.ServerError(err)}
+	}
+	err = a.access.SwitchEntityBlockOff(blockType, tag)
 	return params.ErrorResult{Error: common.ServerError(err)}
 }
+
+// blockTarget returns the tag of the entity a block is switched
+// for: the one given, or the environment if none is.
+func (a *API) blockTarget(tagString string) (names.Tag, error) {
+	if tagString == "" {
+		return a.access.EnvironTag(), nil
+	}
+	tag, err := names.ParseTag(tagString)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return tag, nil
+}
@@ -4,6 +4,8 @@
 package block_test
 
 import (
+	"time"
+
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
@@ -13,6 +15,7 @@ import (
 	"github.com/juju/juju/apiserver/testing"
 	jujutesting "github.com/juju/juju/juju/testing"
 	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
 )
 
 type blockSuite struct {
@@ -79,3 +82,69 @@ func (s *blockSuite) TestSwitchBlockOff(c *gc.C) {
 	c.Assert(err.Error, gc.IsNil)
 	s.assertBlockList(c, 0)
 }
+
+func (s *blockSuite) TestSwitchEntityBlockOn(c *gc.C) {
+	s.Factory.MakeService(c, &factory.ServiceParams{Name: "mysql"})
+	on := params.BlockSwitchParams{
+		Type:    state.RemoveBlock.String(),
+		Message: "keep mysql",
+		Tag:     "service-mysql",
+		TTL:     time.Hour,
+	}
+	err := s.api.SwitchBlockOn(on)
+	c.Assert(err.Error, gc.IsNil)
+
+	all, listErr := s.api.List()
+	c.Assert(listErr, jc.ErrorIsNil)
+	c.Assert(all.Results, gc.HasLen, 1)
+	result := all.Results[0]
+	c.Assert(result.Error, gc.IsNil)
+	c.Assert(result.Result.Tag, gc.Equals, "service-mysql")
+	c.Assert(result.Result.Message, gc.Equals, "keep mysql")
+	c.Assert(result.Result.Expires, gc.NotNil)
+	c.Assert(result.Result.Expires.After(time.Now()), jc.IsTrue)
+
+	// The environment itself is not blocked.
+	_, found, stateErr := s.State.GetBlockForType(state.RemoveBlock)
+	c.Assert(stateErr, jc.ErrorIsNil)
+	c.Assert(found, jc.IsFalse)
+
+	off := params.BlockSwitchParams{
+		Type: state.RemoveBlock.String(),
+		Tag:  "service-mysql",
+	}
+	err = s.api.SwitchBlockOff(off)
+	c.Assert(err.Error, gc.IsNil)
+	s.assertBlockList(c, 0)
+}
+
+func (s *blockSuite) TestSwitchEnvironmentBlockOnWithTTL(c *gc.C) {
+	on := params.BlockSwitchParams{
+		Type: state.ChangeBlock.String(),
+		TTL:  time.Hour,
+	}
+	err := s.api.SwitchBlockOn(on)
+	c.Assert(err.Error, gc.IsNil)
+
+	aBlock, found, stateErr := s.State.GetBlockForType(state.ChangeBlock)
+	c.Assert(stateErr, jc.ErrorIsNil)
+	c.Assert(found, jc.IsTrue)
+	c.Assert(aBlock.Expires().IsZero(), jc.IsFalse)
+}
+
+func (s *blockSuite) TestSwitchEntityBlockOnInvalid(c *gc.C) {
+	on := params.BlockSwitchParams{
+		Type: state.RemoveBlock.String(),
+		Tag:  "mysql",
+	}
+	err := s.api.SwitchBlockOn(on)
+	c.Assert(err.Error, gc.ErrorMatches, `"mysql" is not a valid tag`)
+
+	on = params.BlockSwitchParams{
+		Type: state.RemoveBlock.String(),
+		TTL:  -time.Hour,
+	}
+	err = s.api.SwitchBlockOn(on)
+	c.Assert(err.Error, gc.ErrorMatches, `block TTL -1h0m0s not valid`)
+	s.assertBlockList(c, 0)
+}
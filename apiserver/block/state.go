@@ -3,12 +3,21 @@
 
 package block
 
-import "github.com/juju/juju/state"
+import (
+	"time"
+
+	"github.com/juju/names"
+
+	"github.com/juju/juju/state"
+)
 
 type blockAccess interface {
 	AllBlocks() ([]state.Block, error)
 	SwitchBlockOn(t state.BlockType, msg string) error
 	SwitchBlockOff(t state.BlockType) error
+	SwitchEntityBlockOn(t state.BlockType, tag names.Tag, msg string, expires time.Time) error
+	SwitchEntityBlockOff(t state.BlockType, tag names.Tag) error
+	EnvironTag() names.EnvironTag
 }
 
 type stateShim struct {
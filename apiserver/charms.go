@@ -20,6 +20,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	ziputil "github.com/juju/utils/zip"
@@ -51,13 +52,17 @@ func (h *charmsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	switch r.Method {
 	case "POST":
-		if err := stateWrapper.authenticateUser(r); err != nil {
-			h.authError(w, h)
+		start := time.Now()
+		user, err := stateWrapper.authorizeUser(r, state.EnvironmentWriteAccess)
+		if err != nil {
+			stateWrapper.auditRequest(user, "Charms", r, start, err)
+			h.authorizeError(w, h, err)
 			return
 		}
 		// Add a local charm to the store provider.
 		// Requires a "series" query specifying the series to use for the charm.
 		charmURL, err := h.processPost(r, stateWrapper.state)
+		stateWrapper.auditRequest(user, "Charms", r, start, err)
 		if err != nil {
 			h.sendError(w, http.StatusBadRequest, err.Error())
 			return
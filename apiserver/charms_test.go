@@ -13,6 +13,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"time"
 
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/utils"
@@ -87,6 +88,54 @@ func (s *charmsSuite) TestAuthRequiresUser(c *gc.C) {
 	s.assertErrorResponse(c, resp, http.StatusBadRequest, "expected series=URL argument")
 }
 
+func (s *charmsSuite) TestPOSTRequiresWriteAccess(c *gc.C) {
+	s.setUserAccess(c, state.EnvironmentReadAccess)
+	resp, err := s.authRequest(c, "POST", s.charmsURI(c, ""), "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertErrorResponse(c, resp, http.StatusForbidden, "permission denied")
+
+	s.setUserAccess(c, state.EnvironmentWriteAccess)
+	resp, err = s.authRequest(c, "POST", s.charmsURI(c, ""), "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertErrorResponse(c, resp, http.StatusBadRequest, "expected series=URL argument")
+}
+
+func (s *charmsSuite) TestPOSTRejectsAPIToken(c *gc.C) {
+	_, credentials, err := s.State.AddAPIToken(state.APITokenParams{
+		User:    s.userTag,
+		Expires: time.Now().Add(time.Hour),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	resp, err := s.sendRequest(c, s.userTag.String(), credentials, "POST", s.charmsURI(c, ""), "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertErrorResponse(c, resp, http.StatusUnauthorized, "unauthorized")
+}
+
+func (s *charmsSuite) TestPOSTIsAudited(c *gc.C) {
+	s.setUserAccess(c, state.EnvironmentReadAccess)
+	ch := testcharms.Repo.CharmArchive(c.MkDir(), "dummy")
+	resp, err := s.uploadRequest(c, s.charmsURI(c, "?series=quantal"), true, ch.Path)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertErrorResponse(c, resp, http.StatusForbidden, "permission denied")
+
+	s.setUserAccess(c, state.EnvironmentWriteAccess)
+	resp, err = s.uploadRequest(c, s.charmsURI(c, "?series=quantal"), true, ch.Path)
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertUploadResponse(c, resp, "local:quantal/dummy-1")
+
+	records, err := s.State.AuditRecords(state.AuditFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, gc.HasLen, 2)
+	c.Check(records[0].User, gc.Not(gc.Equals), records[1].User)
+	for i, expectErr := range []string{"permission denied", ""} {
+		record := records[i]
+		c.Check(record.Facade, gc.Equals, "Charms")
+		c.Check(record.Method, gc.Equals, "POST")
+		c.Check(record.Args, gc.Equals, `{"series":["quantal"]}`)
+		c.Check(record.Error, gc.Equals, expectErr)
+	}
+}
+
 func (s *charmsSuite) TestUploadRequiresSeries(c *gc.C) {
 	resp, err := s.authRequest(c, "POST", s.charmsURI(c, ""), "", nil)
 	c.Assert(err, jc.ErrorIsNil)
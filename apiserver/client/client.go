@@ -18,6 +18,7 @@ import (
 	"github.com/juju/juju/apiserver/highavailability"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/apiserver/service"
+	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/environs/manual"
 	"github.com/juju/juju/instance"
@@ -79,7 +80,7 @@ func (c *Client) WatchAll() (params.AllWatcherId, error) {
 // (Deprecated) Use NewServiceSetForClientAPI instead, to preserve values set to
 // an empty string, and use ServiceUnset to unset values.
 func (c *Client) ServiceSet(p params.ServiceSet) error {
-	if err := c.check.ChangeAllowed(); err != nil {
+	if err := c.check.ChangeAllowed(names.NewServiceTag(p.ServiceName)); err != nil {
 		return errors.Trace(err)
 	}
 	svc, err := c.api.state.Service(p.ServiceName)
@@ -108,7 +109,7 @@ func (c *Client) NewServiceSetForClientAPI(p params.ServiceSet) error {
 // ServiceUnset implements the server side of Client.ServiceUnset.
 // TODO(mattyw, all): This api call should be move to the new service facade. The client api version will then need bumping.
 func (c *Client) ServiceUnset(p params.ServiceUnset) error {
-	if err := c.check.ChangeAllowed(); err != nil {
+	if err := c.check.ChangeAllowed(names.NewServiceTag(p.ServiceName)); err != nil {
 		return errors.Trace(err)
 	}
 	svc, err := c.api.state.Service(p.ServiceName)
@@ -125,7 +126,7 @@ func (c *Client) ServiceUnset(p params.ServiceUnset) error {
 // ServiceSetYAML implements the server side of Client.ServerSetYAML.
 // TODO(mattyw, all): This api call should be move to the new service facade. The client api version will then need bumping.
 func (c *Client) ServiceSetYAML(p params.ServiceSetYAML) error {
-	if err := c.check.ChangeAllowed(); err != nil {
+	if err := c.check.ChangeAllowed(names.NewServiceTag(p.ServiceName)); err != nil {
 		return errors.Trace(err)
 	}
 	svc, err := c.api.state.Service(p.ServiceName)
@@ -156,7 +157,7 @@ func (c *Client) ServiceCharmRelations(p params.ServiceCharmRelations) (params.S
 
 // Resolved implements the server side of Client.Resolved.
 func (c *Client) Resolved(p params.Resolved) error {
-	if err := c.check.ChangeAllowed(); err != nil {
+	if err := c.check.ChangeAllowed(unitServices([]string{p.UnitName})...); err != nil {
 		return errors.Trace(err)
 	}
 	unit, err := c.api.state.Unit(p.UnitName)
@@ -226,7 +227,7 @@ func (c *Client) PrivateAddress(p params.PrivateAddress) (results params.Private
 // were also explicitly marked by units as open.
 // TODO(mattyw, all): This api call should be move to the new service facade. The client api version will then need bumping.
 func (c *Client) ServiceExpose(args params.ServiceExpose) error {
-	if err := c.check.ChangeAllowed(); err != nil {
+	if err := c.check.ChangeAllowed(names.NewServiceTag(args.ServiceName)); err != nil {
 		return errors.Trace(err)
 	}
 	svc, err := c.api.state.Service(args.ServiceName)
@@ -240,7 +241,7 @@ func (c *Client) ServiceExpose(args params.ServiceExpose) error {
 // were also explicitly marked by units as open.
 // TODO(mattyw, all): This api call should be move to the new service facade. The client api version will then need bumping.
 func (c *Client) ServiceUnexpose(args params.ServiceUnexpose) error {
-	if err := c.check.ChangeAllowed(); err != nil {
+	if err := c.check.ChangeAllowed(names.NewServiceTag(args.ServiceName)); err != nil {
 		return errors.Trace(err)
 	}
 	svc, err := c.api.state.Service(args.ServiceName)
@@ -255,7 +256,7 @@ func (c *Client) ServiceUnexpose(args params.ServiceUnexpose) error {
 // before calling ServiceDeploy, although for backward compatibility
 // this is not necessary until 1.16 support is removed.
 func (c *Client) ServiceDeploy(args params.ServiceDeploy) error {
-	if err := c.check.ChangeAllowed(); err != nil {
+	if err := c.check.ChangeAllowed(service.PlacementMachines(args)...); err != nil {
 		return errors.Trace(err)
 	}
 	return service.DeployService(c.api.state, c.api.auth.GetAuthTag().String(), args)
@@ -277,7 +278,7 @@ func (c *Client) ServiceDeployWithNetworks(args params.ServiceDeploy) error {
 // All parameters in params.ServiceUpdate except the service name are optional.
 func (c *Client) ServiceUpdate(args params.ServiceUpdate) error {
 	if !args.ForceCharmUrl {
-		if err := c.check.ChangeAllowed(); err != nil {
+		if err := c.check.ChangeAllowed(names.NewServiceTag(args.ServiceName)); err != nil {
 			return errors.Trace(err)
 		}
 	}
@@ -394,7 +395,7 @@ func newServiceSetSettingsStringsForClientAPI(service *state.Service, settings m
 func (c *Client) ServiceSetCharm(args params.ServiceSetCharm) error {
 	// when forced, don't block
 	if !args.Force {
-		if err := c.check.ChangeAllowed(); err != nil {
+		if err := c.check.ChangeAllowed(names.NewServiceTag(args.ServiceName)); err != nil {
 			return errors.Trace(err)
 		}
 	}
@@ -441,7 +442,7 @@ func (c *Client) AddServiceUnits(args params.AddServiceUnits) (params.AddService
 
 // AddServiceUnits adds a given number of units to a service.
 func (c *Client) AddServiceUnitsWithPlacement(args params.AddServiceUnits) (params.AddServiceUnitsResults, error) {
-	if err := c.check.ChangeAllowed(); err != nil {
+	if err := c.check.ChangeAllowed(names.NewServiceTag(args.ServiceName)); err != nil {
 		return params.AddServiceUnitsResults{}, errors.Trace(err)
 	}
 	units, err := addServiceUnits(c.api.state, args)
@@ -457,7 +458,7 @@ func (c *Client) AddServiceUnitsWithPlacement(args params.AddServiceUnits) (para
 
 // DestroyServiceUnits removes a given set of service units.
 func (c *Client) DestroyServiceUnits(args params.DestroyServiceUnits) error {
-	if err := c.check.RemoveAllowed(); err != nil {
+	if err := c.check.RemoveAllowed(unitServices(args.UnitNames)...); err != nil {
 		return errors.Trace(err)
 	}
 	var errs []string
@@ -484,7 +485,7 @@ func (c *Client) DestroyServiceUnits(args params.DestroyServiceUnits) error {
 // ServiceDestroy destroys a given service.
 // TODO(mattyw, all): This api call should be move to the new service facade. The client api version will then need bumping.
 func (c *Client) ServiceDestroy(args params.ServiceDestroy) error {
-	if err := c.check.RemoveAllowed(); err != nil {
+	if err := c.check.RemoveAllowed(names.NewServiceTag(args.ServiceName)); err != nil {
 		return errors.Trace(err)
 	}
 	svc, err := c.api.state.Service(args.ServiceName)
@@ -517,7 +518,7 @@ func (c *Client) GetEnvironmentConstraints() (params.GetConstraintsResults, erro
 // SetServiceConstraints sets the constraints for a given service.
 // TODO(mattyw, all): This api call should be move to the new service facade. The client api version will then need bumping.
 func (c *Client) SetServiceConstraints(args params.SetConstraints) error {
-	if err := c.check.ChangeAllowed(); err != nil {
+	if err := c.check.ChangeAllowed(names.NewServiceTag(args.ServiceName)); err != nil {
 		return errors.Trace(err)
 	}
 	svc, err := c.api.state.Service(args.ServiceName)
@@ -544,6 +545,9 @@ func (c *Client) AddRelation(args params.AddRelation) (params.AddRelationResults
 	if err != nil {
 		return params.AddRelationResults{}, err
 	}
+	if err := c.check.ChangeAllowed(endpointServices(inEps)...); err != nil {
+		return params.AddRelationResults{}, errors.Trace(err)
+	}
 	rel, err := c.api.state.AddRelation(inEps...)
 	if err != nil {
 		return params.AddRelationResults{}, err
@@ -568,6 +572,9 @@ func (c *Client) DestroyRelation(args params.DestroyRelation) error {
 	if err != nil {
 		return err
 	}
+	if err := c.check.RemoveAllowed(endpointServices(eps)...); err != nil {
+		return errors.Trace(err)
+	}
 	rel, err := c.api.state.EndpointsRelation(eps...)
 	if err != nil {
 		return err
@@ -575,6 +582,40 @@ func (c *Client) DestroyRelation(args params.DestroyRelation) error {
 	return rel.Destroy()
 }
 
+// endpointServices returns the tags of the services of the endpoints.
+func endpointServices(eps []state.Endpoint) []names.Tag {
+	services := make([]names.Tag, len(eps))
+	for i, ep := range eps {
+		services[i] = names.NewServiceTag(ep.ServiceName)
+	}
+	return services
+}
+
+// unitServices returns the tags of the services of the named units.
+func unitServices(unitNames []string) []names.Tag {
+	var services []names.Tag
+	for _, name := range unitNames {
+		if serviceName, err := names.UnitService(name); err == nil {
+			services = append(services, names.NewServiceTag(serviceName))
+		}
+	}
+	return services
+}
+
+// machineEntities returns the tags of the machine and of the services
+// of the units it hosts, whose blocks protect the machine too.
+func machineEntities(machine *state.Machine) ([]names.Tag, error) {
+	units, err := machine.Units()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	entities := []names.Tag{machine.Tag()}
+	for _, unit := range units {
+		entities = append(entities, names.NewServiceTag(unit.ServiceName()))
+	}
+	return entities, nil
+}
+
 // AddMachines adds new machines with the supplied parameters.
 func (c *Client) AddMachines(args params.AddMachines) (params.AddMachinesResults, error) {
 	return c.AddMachinesV2(args)
@@ -619,6 +660,12 @@ func (c *Client) addOneMachine(p params.AddMachineParams) (*state.Machine, error
 			p.Placement = nil
 		}
 	}
+	if p.ParentId != "" {
+		// Adding a container changes its parent machine.
+		if err := c.check.ChangeAllowed(names.NewMachineTag(p.ParentId)); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
 
 	if p.ContainerType != "" || p.Placement != nil {
 		// Guard against dubious client by making sure that
@@ -714,12 +761,15 @@ func (c *Client) DestroyMachines(args params.DestroyMachines) error {
 			err = fmt.Errorf("machine %s does not exist", id)
 		case err != nil:
 		case args.Force:
+			if err := c.machineRemoveAllowed(machine, true); err != nil {
+				return errors.Trace(err)
+			}
 			err = machine.ForceDestroy()
 		case machine.Life() != state.Alive:
 			continue
 		default:
 			{
-				if err := c.check.RemoveAllowed(); err != nil {
+				if err := c.machineRemoveAllowed(machine, false); err != nil {
 					return errors.Trace(err)
 				}
 				err = machine.Destroy()
@@ -732,6 +782,20 @@ func (c *Client) DestroyMachines(args params.DestroyMachines) error {
 	return destroyErr("machines", args.MachineNames, errs)
 }
 
+// machineRemoveAllowed checks if blocks are in place that prevent the
+// removal of the machine, including those of the services it hosts
+// units of. Forced removals ignore the blocks of the environment.
+func (c *Client) machineRemoveAllowed(machine *state.Machine, force bool) error {
+	entities, err := machineEntities(machine)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if force {
+		return c.check.ForcedRemoveAllowed(entities...)
+	}
+	return c.check.RemoveAllowed(entities...)
+}
+
 // CharmInfo returns information about the requested charm.
 func (c *Client) CharmInfo(args params.CharmInfo) (api.CharmInfo, error) {
 	curl, err := charm.ParseURL(args.CharmURL)
@@ -799,7 +863,7 @@ func (c *Client) ShareEnvironment(args params.ModifyEnvironUsers) (result params
 		}
 		switch arg.Action {
 		case params.AddEnvUser:
-			_, err := c.api.state.AddEnvironmentUser(user, createdBy, "")
+			err := c.shareEnvironment(user, createdBy, arg.Access)
 			if err != nil {
 				err = errors.Annotate(err, "could not share environment")
 				result.Results[i].Error = common.ServerError(err)
@@ -817,6 +881,29 @@ func (c *Client) ShareEnvironment(args params.ModifyEnvironUsers) (result params
 	return result, nil
 }
 
+// shareEnvironment gives the user the requested access to the
+// environment, adding them as an environment user if need be. Users are
+// given write access when no access is requested. Asking for a specific
+// access for a user already sharing the environment changes their access.
+func (c *Client) shareEnvironment(user, createdBy names.UserTag, accessArg string) error {
+	access := state.EnvironmentWriteAccess
+	if accessArg != "" {
+		access = state.EnvironmentAccess(accessArg)
+	}
+	if err := access.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	_, err := c.api.state.AddEnvironmentUser(user, createdBy, "", access)
+	if !errors.IsAlreadyExists(err) || accessArg == "" {
+		return err
+	}
+	envUser, err := c.api.state.EnvironmentUser(user)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return envUser.SetAccess(access)
+}
+
 // EnvUserInfo returns information on all users in the environment.
 func (c *Client) EnvUserInfo() (params.EnvUserInfoResults, error) {
 	var results params.EnvUserInfoResults
@@ -846,6 +933,7 @@ func (c *Client) EnvUserInfo() (params.EnvUserInfoResults, error) {
 				CreatedBy:      user.CreatedBy(),
 				DateCreated:    user.DateCreated(),
 				LastConnection: lastConn,
+				Access:         string(user.Access()),
 			},
 		})
 	}
@@ -925,9 +1013,42 @@ func (c *Client) EnvironmentGet() (params.EnvironmentConfigResults, error) {
 		return result, err
 	}
 	result.Config = config.AllAttrs()
+	// The secrets of the provider, such as its credentials, are only
+	// shown to admins of the environment.
+	isAdmin, err := c.isEnvironAdmin()
+	if err != nil {
+		return result, errors.Trace(err)
+	}
+	if !isAdmin {
+		provider, err := environs.Provider(config.Type())
+		if err != nil {
+			return result, errors.Trace(err)
+		}
+		secrets, err := provider.SecretAttrs(config)
+		if err != nil {
+			return result, errors.Trace(err)
+		}
+		for name := range secrets {
+			delete(result.Config, name)
+		}
+	}
 	return result, nil
 }
 
+// isEnvironAdmin reports whether the authenticated user has admin
+// access to the environment.
+func (c *Client) isEnvironAdmin() (bool, error) {
+	userTag, ok := c.api.auth.GetAuthTag().(names.UserTag)
+	if !ok {
+		return false, nil
+	}
+	envUser, err := c.api.state.EnvironmentUser(userTag)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return envUser.Access() == state.EnvironmentAdminAccess, nil
+}
+
 // EnvironmentSet implements the server-side part of the
 // set-environment CLI command.
 func (c *Client) EnvironmentSet(args params.EnvironmentSet) error {
@@ -942,7 +1063,7 @@ func (c *Client) EnvironmentSet(args params.EnvironmentSet) error {
 				return fmt.Errorf("agent-version cannot be changed")
 			}
 		}
-		return nil
+		return checkAPIImmutableAttributes(updateAttrs, removeAttrs, oldConfig)
 	}
 	// Replace any deprecated attributes with their new values.
 	attrs := config.ProcessDeprecatedAttributes(args.Config)
@@ -952,6 +1073,36 @@ func (c *Client) EnvironmentSet(args params.EnvironmentSet) error {
 	return c.api.state.UpdateEnvironConfig(attrs, nil, checkAgentVersion)
 }
 
+// apiImmutableAttributes are the environment settings that can only be
+// set when the environment is bootstrapped. They set up the directory
+// that authenticates users, which anyone able to change them could
+// point at a directory of their own.
+var apiImmutableAttributes = []string{
+	config.LDAPURLKey,
+	config.LDAPUserDNKey,
+	config.LDAPDisplayNameAttributeKey,
+	config.LDAPCACertKey,
+	config.LDAPAllowInsecureKey,
+}
+
+// checkAPIImmutableAttributes returns an error if the changes to the
+// environment config change any of apiImmutableAttributes.
+func checkAPIImmutableAttributes(updateAttrs map[string]interface{}, removeAttrs []string, oldConfig *config.Config) error {
+	oldAttrs := oldConfig.AllAttrs()
+	for _, attr := range apiImmutableAttributes {
+		oldValue, oldFound := oldAttrs[attr]
+		if v, found := updateAttrs[attr]; found && (!oldFound || v != oldValue) {
+			return errors.Errorf("%s cannot be changed", attr)
+		}
+		for _, removed := range removeAttrs {
+			if removed == attr && oldFound {
+				return errors.Errorf("%s cannot be changed", attr)
+			}
+		}
+	}
+	return nil
+}
+
 // EnvironmentUnset implements the server-side part of the
 // set-environment CLI command.
 func (c *Client) EnvironmentUnset(args params.EnvironmentUnset) error {
@@ -961,7 +1112,7 @@ func (c *Client) EnvironmentUnset(args params.EnvironmentUnset) error {
 	// TODO(waigani) 2014-3-11 #1167616
 	// Add a txn retry loop to ensure that the settings on disk have not
 	// changed underneath us.
-	return c.api.state.UpdateEnvironConfig(nil, args.Keys, nil)
+	return c.api.state.UpdateEnvironConfig(nil, args.Keys, checkAPIImmutableAttributes)
 }
 
 // SetEnvironAgentVersion sets the environment agent version.
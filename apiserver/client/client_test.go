@@ -173,6 +173,7 @@ func (s *serverSuite) TestEnvUsersInfo(c *gc.C) {
 		},
 	} {
 		r.info.CreatedBy = owner.UserName()
+		r.info.Access = "admin"
 		r.info.DateCreated = r.user.DateCreated()
 		r.info.LastConnection = lastConnPointer(c, r.user)
 		expected.Results = append(expected.Results, params.EnvUserInfoResult{Result: r.info})
@@ -283,6 +284,7 @@ func (s *serverSuite) TestShareEnvironmentAddLocalUser(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(envUser.UserName(), gc.Equals, user.UserTag().Username())
 	c.Assert(envUser.CreatedBy(), gc.Equals, dummy.AdminUserTag().Username())
+	c.Assert(envUser.Access(), gc.Equals, state.EnvironmentWriteAccess)
 	lastConn, err := envUser.LastConnection()
 	c.Assert(err, jc.Satisfies, state.IsNeverConnectedError)
 	c.Assert(lastConn, gc.Equals, time.Time{})
@@ -334,6 +336,59 @@ func (s *serverSuite) TestShareEnvironmentAddUserTwice(c *gc.C) {
 	c.Assert(envUser.UserName(), gc.Equals, user.UserTag().Username())
 }
 
+func (s *serverSuite) TestShareEnvironmentAddUserWithAccess(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "foobar", NoEnvUser: true})
+	args := params.ModifyEnvironUsers{
+		Changes: []params.ModifyEnvironUser{{
+			UserTag: user.Tag().String(),
+			Action:  params.AddEnvUser,
+			Access:  "read",
+		}}}
+
+	result, err := s.client.ShareEnvironment(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.OneError(), gc.IsNil)
+
+	envUser, err := s.State.EnvironmentUser(user.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.Access(), gc.Equals, state.EnvironmentReadAccess)
+}
+
+func (s *serverSuite) TestShareEnvironmentChangeAccess(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "foobar"})
+	args := params.ModifyEnvironUsers{
+		Changes: []params.ModifyEnvironUser{{
+			UserTag: user.Tag().String(),
+			Action:  params.AddEnvUser,
+			Access:  "write",
+		}}}
+
+	result, err := s.client.ShareEnvironment(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.OneError(), gc.IsNil)
+
+	envUser, err := s.State.EnvironmentUser(user.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(envUser.Access(), gc.Equals, state.EnvironmentWriteAccess)
+}
+
+func (s *serverSuite) TestShareEnvironmentInvalidAccess(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "foobar", NoEnvUser: true})
+	args := params.ModifyEnvironUsers{
+		Changes: []params.ModifyEnvironUser{{
+			UserTag: user.Tag().String(),
+			Action:  params.AddEnvUser,
+			Access:  "superuser",
+		}}}
+
+	result, err := s.client.ShareEnvironment(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.OneError(), gc.ErrorMatches, `could not share environment: environment access "superuser" not valid`)
+
+	_, err = s.State.EnvironmentUser(user.UserTag())
+	c.Assert(errors.IsNotFound(err), jc.IsTrue)
+}
+
 func (s *serverSuite) TestShareEnvironmentInvalidTags(c *gc.C) {
 	for _, testParam := range []struct {
 		tag      string
@@ -2676,6 +2731,23 @@ func (s *serverSuite) TestClientEnvironmentGet(c *gc.C) {
 	c.Assert(result.Config, gc.DeepEquals, envConfig.AllAttrs())
 }
 
+func (s *serverSuite) TestClientEnvironmentGetHidesSecrets(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "writer", NoEnvUser: true})
+	s.Factory.MakeEnvUser(c, &factory.EnvUserParams{User: user.Name(), Access: state.EnvironmentWriteAccess})
+	auth := testing.FakeAuthorizer{Tag: user.Tag()}
+	writer, err := client.NewClient(s.State, common.NewResources(), auth)
+	c.Assert(err, jc.ErrorIsNil)
+
+	envConfig, err := s.State.EnvironConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	expected := envConfig.AllAttrs()
+	c.Assert(expected["secret"], gc.NotNil)
+	delete(expected, "secret")
+	result, err := writer.EnvironmentGet()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Config, gc.DeepEquals, expected)
+}
+
 func (s *serverSuite) assertEnvValue(c *gc.C, key string, expected interface{}) {
 	envConfig, err := s.State.EnvironConfig()
 	c.Assert(err, jc.ErrorIsNil)
@@ -2760,6 +2832,34 @@ func (s *serverSuite) TestClientEnvironmentSetCannotChangeAgentVersion(c *gc.C)
 	c.Assert(err, jc.ErrorIsNil)
 }
 
+func (s *serverSuite) TestClientEnvironmentSetCannotChangeLDAP(c *gc.C) {
+	args := params.EnvironmentSet{
+		map[string]interface{}{"ldap-url": "ldap://ldap.invalid"},
+	}
+	err := s.client.EnvironmentSet(args)
+	c.Assert(err, gc.ErrorMatches, "ldap-url cannot be changed")
+	s.assertEnvValueMissing(c, "ldap-url")
+
+	err = s.State.UpdateEnvironConfig(map[string]interface{}{
+		"ldap-url":            "ldap://ldap.example.com",
+		"ldap-user-dn":        "uid={username},ou=people,dc=example,dc=com",
+		"ldap-allow-insecure": true,
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	args.Config["ldap-url"] = "ldap://ldap.invalid"
+	err = s.client.EnvironmentSet(args)
+	c.Assert(err, gc.ErrorMatches, "ldap-url cannot be changed")
+
+	// It's okay to pass the settings back unchanged.
+	args.Config["ldap-url"] = "ldap://ldap.example.com"
+	err = s.client.EnvironmentSet(args)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.client.EnvironmentUnset(params.EnvironmentUnset{[]string{"ldap-user-dn"}})
+	c.Assert(err, gc.ErrorMatches, "ldap-user-dn cannot be changed")
+	s.assertEnvValue(c, "ldap-user-dn", "uid={username},ou=people,dc=example,dc=com")
+}
+
 func (s *serverSuite) TestClientEnvironmentUnset(c *gc.C) {
 	err := s.State.UpdateEnvironConfig(map[string]interface{}{"abc": 123}, nil, nil)
 	c.Assert(err, jc.ErrorIsNil)
@@ -3435,6 +3535,143 @@ func (s *serverSuite) TestBlockServiceDestroy(c *gc.C) {
 	}
 }
 
+func (s *serverSuite) TestEntityBlockServiceDestroy(c *gc.C) {
+	mysql := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	wordpress := s.AddTestingService(c, "wordpress", s.AddTestingCharm(c, "wordpress"))
+	err := s.State.SwitchEntityBlockOn(state.RemoveBlock, mysql.Tag(), "TestEntityBlockServiceDestroy", time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.APIState.Client().ServiceDestroy("mysql")
+	s.AssertBlocked(c, err, "TestEntityBlockServiceDestroy")
+	assertLife(c, mysql, state.Alive)
+
+	// Other services are not protected.
+	err = s.APIState.Client().ServiceDestroy("wordpress")
+	c.Assert(err, jc.ErrorIsNil)
+	err = wordpress.Refresh()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *serverSuite) TestEntityBlockDestroyServiceUnits(c *gc.C) {
+	mysql := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	unit, err := mysql.AddUnit()
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SwitchEntityBlockOn(state.RemoveBlock, mysql.Tag(), "TestEntityBlockDestroyServiceUnits", time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.APIState.Client().DestroyServiceUnits(unit.Name())
+	s.AssertBlocked(c, err, "TestEntityBlockDestroyServiceUnits")
+	assertLife(c, unit, state.Alive)
+}
+
+func (s *serverSuite) TestEntityBlockChangesServiceExpose(c *gc.C) {
+	mysql := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	err := s.State.SwitchEntityBlockOn(state.ChangeBlock, mysql.Tag(), "TestEntityBlockChangesServiceExpose", time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = s.APIState.Client().ServiceExpose("mysql")
+	s.AssertBlocked(c, err, "TestEntityBlockChangesServiceExpose")
+	err = mysql.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(mysql.IsExposed(), jc.IsFalse)
+
+	// A change block also prevents removal.
+	err = s.APIState.Client().ServiceDestroy("mysql")
+	s.AssertBlocked(c, err, "TestEntityBlockChangesServiceExpose")
+}
+
+func (s *serverSuite) TestEntityBlockExpired(c *gc.C) {
+	mysql := s.AddTestingService(c, "mysql", s.AddTestingCharm(c, "mysql"))
+	err := s.State.SwitchEntityBlockOn(state.RemoveBlock, mysql.Tag(), "", time.Now().Add(100*time.Millisecond))
+	c.Assert(err, jc.ErrorIsNil)
+	time.Sleep(200 * time.Millisecond)
+
+	err = s.APIState.Client().ServiceDestroy("mysql")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *clientSuite) TestEntityBlockDestroyMachines(c *gc.C) {
+	m0, m1, m2, u := s.setupDestroyMachinesTest(c)
+	err := s.State.SwitchEntityBlockOn(state.RemoveBlock, m2.Tag(), "TestEntityBlockDestroyMachines", time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.APIState.Client().DestroyMachines("2")
+	s.assertBlockedErrorAndLiveliness(c, err, "TestEntityBlockDestroyMachines", m0, m1, m2, u)
+}
+
+func (s *clientSuite) TestEntityBlockForceDestroyMachines(c *gc.C) {
+	m0, m1, m2, u := s.setupDestroyMachinesTest(c)
+	err := s.State.SwitchEntityBlockOn(state.RemoveBlock, names.NewServiceTag("wordpress"), "TestEntityBlockForceDestroyMachines", time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Force does not bypass the blocks of the services the machine
+	// hosts units of.
+	err = s.APIState.Client().ForceDestroyMachines("1")
+	s.assertBlockedErrorAndLiveliness(c, err, "TestEntityBlockForceDestroyMachines", m0, m1, m2, u)
+
+	err = s.APIState.Client().ForceDestroyMachines("2")
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.Cleanup()
+	c.Assert(err, jc.ErrorIsNil)
+	assertLife(c, m1, state.Alive)
+	assertLife(c, m2, state.Dead)
+}
+
+func (s *clientSuite) TestEntityBlockForceDestroyBlockedMachine(c *gc.C) {
+	m0, m1, m2, u := s.setupDestroyMachinesTest(c)
+	err := s.State.SwitchEntityBlockOn(state.ChangeBlock, m2.Tag(), "TestEntityBlockForceDestroyBlockedMachine", time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.APIState.Client().ForceDestroyMachines("2")
+	s.assertBlockedErrorAndLiveliness(c, err, "TestEntityBlockForceDestroyBlockedMachine", m0, m1, m2, u)
+}
+
+func (s *clientSuite) TestEntityBlockResolved(c *gc.C) {
+	u := s.setupResolved(c)
+	err := s.State.SwitchEntityBlockOn(state.ChangeBlock, names.NewServiceTag("wordpress"), "TestEntityBlockResolved", time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertResolvedBlocked(c, u, "TestEntityBlockResolved")
+	err = u.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(u.Resolved(), gc.Equals, state.ResolvedNone)
+}
+
+func (s *clientSuite) TestEntityBlockServiceDeployToMachine(c *gc.C) {
+	machine, err := s.State.AddMachine("precise", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SwitchEntityBlockOn(state.ChangeBlock, machine.Tag(), "TestEntityBlockServiceDeployToMachine", time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	for _, toMachineSpec := range []string{machine.Id(), "lxc:" + machine.Id()} {
+		err = s.APIState.Client().ServiceDeploy(
+			"cs:precise/wordpress-3", "wordpress", 1, "", constraints.Value{}, toMachineSpec,
+		)
+		s.AssertBlocked(c, err, "TestEntityBlockServiceDeployToMachine")
+	}
+	_, err = s.State.Service("wordpress")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *clientSuite) TestEntityBlockAddContainerToMachine(c *gc.C) {
+	machine, err := s.State.AddMachine("precise", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SwitchEntityBlockOn(state.ChangeBlock, machine.Tag(), "TestEntityBlockAddContainerToMachine", time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	results, err := s.APIState.Client().AddMachines([]params.AddMachineParams{{
+		Jobs:      []multiwatcher.MachineJob{multiwatcher.JobHostUnits},
+		Placement: instance.MustParsePlacement("lxc:" + machine.Id()),
+	}, {
+		Jobs: []multiwatcher.MachineJob{multiwatcher.JobHostUnits},
+	}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, gc.HasLen, 2)
+	s.AssertBlocked(c, results[0].Error, "TestEntityBlockAddContainerToMachine")
+	// Machines not added to the blocked one are allowed.
+	c.Assert(results[1].Error, gc.IsNil)
+	containers, err := machine.Containers()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(containers, gc.HasLen, 0)
+}
+
 func (s *clientSuite) assertDestroyMachineSuccess(c *gc.C, u *state.Unit, m0, m1, m2 *state.Machine) {
 	err := s.APIState.Client().DestroyMachines("0", "1", "2")
 	c.Assert(err, gc.ErrorMatches, `some machines were not destroyed: machine 0 is required by the environment; machine 1 has unit "wordpress/0" assigned`)
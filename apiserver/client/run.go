@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/juju/errors"
+	"github.com/juju/names"
 	"github.com/juju/utils"
 	"github.com/juju/utils/set"
 
@@ -91,6 +92,9 @@ func (c *Client) Run(run params.RunParams) (results params.RunResults, err error
 	if err != nil {
 		return results, err
 	}
+	// The commands are blocked if any of the services or machines
+	// they run on are.
+	var entities []names.Tag
 	// We want to create a RemoteExec for each unit and each machine.
 	// If we have both a unit and a machine request, we run it twice,
 	// once for the unit inside the exec context using juju-run, and
@@ -105,6 +109,7 @@ func (c *Client) Run(run params.RunParams) (results params.RunResults, err error
 		if err != nil {
 			return results, err
 		}
+		entities = append(entities, names.NewServiceTag(unit.ServiceName()), machine.Tag())
 		command := fmt.Sprintf("juju-run %s %s", unit.Name(), quotedCommands)
 		execParam := remoteParamsForMachine(machine, command, run.Timeout)
 		execParam.UnitId = unit.Name()
@@ -115,10 +120,18 @@ func (c *Client) Run(run params.RunParams) (results params.RunResults, err error
 		if err != nil {
 			return results, err
 		}
+		machineTags, err := machineEntities(machine)
+		if err != nil {
+			return results, err
+		}
+		entities = append(entities, machineTags...)
 		command := fmt.Sprintf("juju-run --no-context %s", quotedCommands)
 		execParam := remoteParamsForMachine(machine, command, run.Timeout)
 		params = append(params, execParam)
 	}
+	if err := c.check.ChangeAllowed(entities...); err != nil {
+		return results, errors.Trace(err)
+	}
 	return ParallelExecute(c.getDataDir(), params), nil
 }
 
@@ -131,6 +144,17 @@ func (c *Client) RunOnAllMachines(run params.RunParams) (params.RunResults, erro
 	if err != nil {
 		return params.RunResults{}, err
 	}
+	var entities []names.Tag
+	for _, machine := range machines {
+		machineTags, err := machineEntities(machine)
+		if err != nil {
+			return params.RunResults{}, err
+		}
+		entities = append(entities, machineTags...)
+	}
+	if err := c.check.ChangeAllowed(entities...); err != nil {
+		return params.RunResults{}, errors.Trace(err)
+	}
 	var params []*RemoteExec
 	quotedCommands := utils.ShQuote(run.Commands)
 	command := fmt.Sprintf("juju-run --no-context %s", quotedCommands)
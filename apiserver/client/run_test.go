@@ -270,6 +270,17 @@ func (s *runSuite) TestBlockRunOnAllMachines(c *gc.C) {
 	s.AssertBlocked(c, err, "TestBlockRunOnAllMachines")
 }
 
+func (s *runSuite) TestEntityBlockRunOnAllMachines(c *gc.C) {
+	s.addMachineWithAddress(c, "10.3.2.1")
+	machine := s.addMachineWithAddress(c, "10.3.2.2")
+	s.mockSSH(c, echoInput)
+
+	err := s.State.SwitchEntityBlockOn(state.ChangeBlock, machine.Tag(), "TestEntityBlockRunOnAllMachines", time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.APIState.Client().RunOnAllMachines("hostname", testing.LongWait)
+	s.AssertBlocked(c, err, "TestEntityBlockRunOnAllMachines")
+}
+
 func (s *runSuite) TestRunMachineAndService(c *gc.C) {
 	// Make three machines.
 	s.addMachineWithAddress(c, "10.3.2.1")
@@ -346,3 +357,41 @@ func (s *runSuite) TestBlockRunMachineAndService(c *gc.C) {
 		})
 	s.AssertBlocked(c, err, "TestBlockRunMachineAndService")
 }
+
+func (s *runSuite) TestEntityBlockRun(c *gc.C) {
+	charm := s.AddTestingCharm(c, "dummy")
+	owner := s.Factory.MakeUser(c, nil).Tag()
+	magic, err := s.State.AddService("magic", owner.String(), charm, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	unit := s.addUnit(c, magic)
+	machineId, err := unit.AssignedMachineId()
+	c.Assert(err, jc.ErrorIsNil)
+	other := s.addMachineWithAddress(c, "10.3.2.2")
+
+	s.mockSSH(c, echoInput)
+
+	err = s.State.SwitchEntityBlockOn(state.ChangeBlock, magic.Tag(), "TestEntityBlockRun", time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+	client := s.APIState.Client()
+	for i, run := range []params.RunParams{
+		{Services: []string{"magic"}},
+		{Units: []string{unit.Name()}},
+		// Commands run on a machine affect the units it hosts.
+		{Machines: []string{machineId}},
+	} {
+		c.Logf("test %d: %+v", i, run)
+		run.Commands = "hostname"
+		run.Timeout = testing.LongWait
+		_, err = client.Run(run)
+		s.AssertBlocked(c, err, "TestEntityBlockRun")
+	}
+
+	// Other machines are not protected.
+	results, err := client.Run(params.RunParams{
+		Commands: "hostname",
+		Timeout:  testing.LongWait,
+		Machines: []string{other.Id()},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, gc.HasLen, 1)
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"github.com/juju/errors"
+	"github.com/juju/names"
 	"github.com/juju/utils/set"
 	"gopkg.in/juju/charm.v5"
 	"gopkg.in/juju/charm.v5/hooks"
@@ -101,6 +102,80 @@ func (c *Client) UnitStatusHistory(args params.StatusHistory) (params.UnitStatus
 	return statuses, nil
 }
 
+// StatusHistory returns the status history of a unit, machine,
+// service, volume or filesystem, oldest first.
+func (c *Client) StatusHistory(args params.StatusHistoryArgs) (params.StatusHistoryResult, error) {
+	if args.Size < 0 {
+		return params.StatusHistoryResult{}, errors.Errorf("invalid history size: %d", args.Size)
+	}
+	tag, err := names.ParseTag(args.Tag)
+	if err != nil {
+		return params.StatusHistoryResult{}, errors.Trace(err)
+	}
+	filter := state.StatusHistoryFilter{Size: args.Size}
+	if args.FromDate != nil {
+		filter.FromDate = *args.FromDate
+	}
+	if args.ToDate != nil {
+		filter.ToDate = *args.ToDate
+	}
+
+	var statuses []params.AgentStatus
+	if unitTag, ok := tag.(names.UnitTag); ok {
+		kind := args.Kind
+		if kind == "" {
+			kind = params.KindCombined
+		}
+		switch kind {
+		case params.KindCombined, params.KindWorkload, params.KindAgent:
+		default:
+			return params.StatusHistoryResult{}, errors.NotValidf("unit status history kind %q", kind)
+		}
+		if kind == params.KindCombined || kind == params.KindWorkload {
+			unitStatuses, err := c.api.state.StatusHistory(unitTag, filter)
+			if err != nil {
+				return params.StatusHistoryResult{}, errors.Trace(err)
+			}
+			statuses = append(statuses, agentStatusFromStatusInfo(unitStatuses, params.KindWorkload)...)
+		}
+		if kind == params.KindCombined || kind == params.KindAgent {
+			agentStatuses, err := c.api.state.UnitAgentStatusHistory(unitTag, filter)
+			if err != nil {
+				return params.StatusHistoryResult{}, errors.Trace(err)
+			}
+			statuses = append(statuses, agentStatusFromStatusInfo(agentStatuses, params.KindAgent)...)
+		}
+	} else {
+		if args.Kind != "" {
+			return params.StatusHistoryResult{}, errors.NotValidf("status history kind %q for %s", args.Kind, names.ReadableString(tag))
+		}
+		kind, ok := historyKinds[tag.Kind()]
+		if !ok {
+			return params.StatusHistoryResult{}, errors.NotSupportedf("status history of %s", names.ReadableString(tag))
+		}
+		history, err := c.api.state.StatusHistory(tag, filter)
+		if err != nil {
+			return params.StatusHistoryResult{}, errors.Trace(err)
+		}
+		statuses = agentStatusFromStatusInfo(history, kind)
+	}
+
+	sort.Sort(sortableStatuses(statuses))
+	if args.Size > 0 && len(statuses) > args.Size {
+		statuses = statuses[len(statuses)-args.Size:]
+	}
+	return params.StatusHistoryResult{Statuses: statuses}, nil
+}
+
+// historyKinds holds the kinds of the entries in the status history of
+// the entities other than units, by tag kind.
+var historyKinds = map[string]params.HistoryKind{
+	names.MachineTagKind:    params.KindMachine,
+	names.ServiceTagKind:    params.KindService,
+	names.VolumeTagKind:     params.KindVolume,
+	names.FilesystemTagKind: params.KindFilesystem,
+}
+
 // FullStatus gives the information needed for juju status over the api
 func (c *Client) FullStatus(args params.StatusParams) (params.FullStatus, error) {
 	cfg, err := c.api.state.EnvironConfig()
@@ -62,6 +62,107 @@ func (s *statusSuite) TestLegacyStatus(c *gc.C) {
 	c.Check(resultMachine.InstanceId, gc.Equals, instanceId)
 }
 
+func (s *statusSuite) TestStatusHistoryUnit(c *gc.C) {
+	unit := s.Factory.MakeUnit(c, nil)
+	err := unit.SetAgentStatus(state.StatusIdle, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	err = unit.SetStatus(state.StatusActive, "ready", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	client := s.APIState.Client()
+	history, err := client.StatusHistory(params.StatusHistoryArgs{
+		Tag:  unit.Tag().String(),
+		Size: 2,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history.Statuses, gc.HasLen, 2)
+	c.Check(history.Statuses[0].Kind, gc.Equals, params.KindAgent)
+	c.Check(history.Statuses[0].Status, gc.Equals, params.StatusIdle)
+	c.Check(history.Statuses[1].Kind, gc.Equals, params.KindWorkload)
+	c.Check(history.Statuses[1].Status, gc.Equals, params.StatusActive)
+	c.Check(history.Statuses[1].Info, gc.Equals, "ready")
+
+	history, err = client.StatusHistory(params.StatusHistoryArgs{
+		Tag:  unit.Tag().String(),
+		Kind: params.KindAgent,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	for _, status := range history.Statuses {
+		c.Check(status.Kind, gc.Equals, params.KindAgent)
+	}
+}
+
+func (s *statusSuite) TestStatusHistoryMachineTimeRange(c *gc.C) {
+	machine := s.addMachine(c)
+	err := machine.SetStatus(state.StatusStarted, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	history, err := s.State.StatusHistory(machine.Tag(), state.StatusHistoryFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 2)
+
+	client := s.APIState.Client()
+	result, err := client.StatusHistory(params.StatusHistoryArgs{
+		Tag:      machine.Tag().String(),
+		FromDate: history[0].Since,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Statuses, gc.HasLen, 1)
+	c.Check(result.Statuses[0].Kind, gc.Equals, params.KindMachine)
+	c.Check(result.Statuses[0].Status, gc.Equals, params.StatusStarted)
+
+	result, err = client.StatusHistory(params.StatusHistoryArgs{
+		Tag:    machine.Tag().String(),
+		ToDate: history[0].Since,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Statuses, gc.HasLen, 1)
+	c.Check(result.Statuses[0].Status, gc.Equals, params.StatusPending)
+}
+
+func (s *statusSuite) TestStatusHistoryService(c *gc.C) {
+	service := s.Factory.MakeService(c, nil)
+	err := service.SetStatus(state.StatusMaintenance, "upgrading", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.APIState.Client().StatusHistory(params.StatusHistoryArgs{
+		Tag:  service.Tag().String(),
+		Size: 1,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Statuses, gc.HasLen, 1)
+	c.Check(result.Statuses[0].Kind, gc.Equals, params.KindService)
+	c.Check(result.Statuses[0].Status, gc.Equals, params.StatusMaintenance)
+	c.Check(result.Statuses[0].Info, gc.Equals, "upgrading")
+}
+
+func (s *statusSuite) TestStatusHistoryErrors(c *gc.C) {
+	machine := s.addMachine(c)
+	client := s.APIState.Client()
+	for i, test := range []struct {
+		args params.StatusHistoryArgs
+		err  string
+	}{{
+		args: params.StatusHistoryArgs{Tag: "foo"},
+		err:  `"foo" is not a valid tag`,
+	}, {
+		args: params.StatusHistoryArgs{Tag: machine.Tag().String(), Size: -1},
+		err:  "invalid history size: -1",
+	}, {
+		args: params.StatusHistoryArgs{Tag: machine.Tag().String(), Kind: params.KindAgent},
+		err:  `status history kind "agent" for machine.*0.* not valid`,
+	}, {
+		args: params.StatusHistoryArgs{Tag: "unit-mysql-0", Kind: params.KindMachine},
+		err:  `unit status history kind "machine" not valid`,
+	}, {
+		args: params.StatusHistoryArgs{Tag: "user-bob"},
+		err:  `status history of user.*bob.* not supported`,
+	}} {
+		c.Logf("test %d: %+v", i, test.args)
+		_, err := client.StatusHistory(test.args)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
 var _ = gc.Suite(&statusUnitTestSuite{})
 
 type statusUnitTestSuite struct {
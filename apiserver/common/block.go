@@ -4,7 +4,10 @@
 package common
 
 import (
+	"fmt"
+
 	"github.com/juju/errors"
+	"github.com/juju/names"
 
 	"github.com/juju/juju/state"
 )
@@ -13,6 +16,13 @@ type BlockGetter interface {
 	GetBlockForType(t state.BlockType) (state.Block, bool, error)
 }
 
+// EntityBlockGetter is a BlockGetter that can also get the blocks
+// that protect single services or machines.
+type EntityBlockGetter interface {
+	BlockGetter
+	GetEntityBlockForType(t state.BlockType, tag names.Tag) (state.Block, bool, error)
+}
+
 // BlockChecker checks for current blocks if any.
 type BlockChecker struct {
 	getter BlockGetter
@@ -25,39 +35,54 @@ func NewBlockChecker(s BlockGetter) *BlockChecker {
 // ChangeAllowed checks if change block is in place.
 // Change block prevents all operations that may change
 // current environment in any way from running successfully.
-func (c *BlockChecker) ChangeAllowed() error {
-	return c.checkBlock(state.ChangeBlock)
+// Any entities given are checked for change blocks of their own.
+func (c *BlockChecker) ChangeAllowed(entities ...names.Tag) error {
+	return c.checkBlock(state.ChangeBlock, entities)
 }
 
 // RemoveAllowed checks if remove block is in place.
 // Remove block prevents removal of machine, service, unit
 // and relation from current environment.
-func (c *BlockChecker) RemoveAllowed() error {
-	if err := c.checkBlock(state.RemoveBlock); err != nil {
+// Any entities given are checked for remove and change
+// blocks of their own.
+func (c *BlockChecker) RemoveAllowed(entities ...names.Tag) error {
+	if err := c.checkBlock(state.RemoveBlock, entities); err != nil {
 		return err
 	}
 	// Check if change block has been enabled
-	return c.checkBlock(state.ChangeBlock)
+	return c.checkBlock(state.ChangeBlock, entities)
+}
+
+// ForcedRemoveAllowed checks if remove or change blocks are in place
+// for any of the given entities. Forced removals ignore the blocks of
+// the environment, but not those protecting single services or
+// machines.
+func (c *BlockChecker) ForcedRemoveAllowed(entities ...names.Tag) error {
+	if err := c.checkEntityBlocks(state.RemoveBlock, entities); err != nil {
+		return err
+	}
+	return c.checkEntityBlocks(state.ChangeBlock, entities)
 }
 
 // DestroyAllowed checks if destroy block is in place.
 // Destroy block prevents destruction of current environment.
 func (c *BlockChecker) DestroyAllowed() error {
-	if err := c.checkBlock(state.DestroyBlock); err != nil {
+	if err := c.checkBlock(state.DestroyBlock, nil); err != nil {
 		return err
 	}
 	// Check if remove block has been enabled
-	if err := c.checkBlock(state.RemoveBlock); err != nil {
+	if err := c.checkBlock(state.RemoveBlock, nil); err != nil {
 		return err
 	}
 	// Check if change block has been enabled
-	return c.checkBlock(state.ChangeBlock)
+	return c.checkBlock(state.ChangeBlock, nil)
 }
 
-// checkBlock checks if specified operation must be blocked.
+// checkBlock checks if specified operation must be blocked,
+// for the environment or for any of the given entities.
 // If it does, the method throws specific error that can be examined
 // to stop operation execution.
-func (c *BlockChecker) checkBlock(blockType state.BlockType) error {
+func (c *BlockChecker) checkBlock(blockType state.BlockType, entities []names.Tag) error {
 	aBlock, isEnabled, err := c.getter.GetBlockForType(blockType)
 	if err != nil {
 		return errors.Trace(err)
@@ -65,5 +90,31 @@ func (c *BlockChecker) checkBlock(blockType state.BlockType) error {
 	if isEnabled {
 		return ErrOperationBlocked(aBlock.Message())
 	}
+	return c.checkEntityBlocks(blockType, entities)
+}
+
+// checkEntityBlocks checks if the specified operation must be blocked
+// for any of the given entities.
+func (c *BlockChecker) checkEntityBlocks(blockType state.BlockType, entities []names.Tag) error {
+	if len(entities) == 0 {
+		return nil
+	}
+	getter, ok := c.getter.(EntityBlockGetter)
+	if !ok {
+		return errors.NotSupportedf("checking blocks for %s", names.ReadableString(entities[0]))
+	}
+	for _, entity := range entities {
+		aBlock, isEnabled, err := getter.GetEntityBlockForType(blockType, entity)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if isEnabled {
+			msg := aBlock.Message()
+			if msg == "" {
+				msg = fmt.Sprintf("The operation has been blocked for %s.", names.ReadableString(entity))
+			}
+			return ErrOperationBlocked(msg)
+		}
+	}
 	return nil
 }
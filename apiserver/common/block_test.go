@@ -98,3 +98,78 @@ func (s *blockCheckerSuite) assertErrorBlocked(c *gc.C, blocked bool, err error,
 		c.Assert(errors.Cause(err), jc.ErrorIsNil)
 	}
 }
+
+type mockEntityBlockGetter struct {
+	envBlock     state.Block
+	entityBlocks map[names.Tag]state.Block
+}
+
+func (m *mockEntityBlockGetter) GetBlockForType(t state.BlockType) (state.Block, bool, error) {
+	if m.envBlock != nil && m.envBlock.Type() == t {
+		return m.envBlock, true, nil
+	}
+	return nil, false, nil
+}
+
+func (m *mockEntityBlockGetter) GetEntityBlockForType(t state.BlockType, tag names.Tag) (state.Block, bool, error) {
+	if aBlock, ok := m.entityBlocks[tag]; ok && aBlock.Type() == t {
+		return aBlock, true, nil
+	}
+	return nil, false, nil
+}
+
+func (s *blockCheckerSuite) TestEntityBlockChecker(c *gc.C) {
+	mysql := names.NewServiceTag("mysql")
+	wordpress := names.NewServiceTag("wordpress")
+	getter := &mockEntityBlockGetter{
+		entityBlocks: map[names.Tag]state.Block{mysql: s.remove},
+	}
+	checker := common.NewBlockChecker(getter)
+
+	s.assertErrorBlocked(c, true, checker.RemoveAllowed(wordpress, mysql), s.remove.Message())
+	s.assertErrorBlocked(c, false, checker.RemoveAllowed(wordpress), "")
+	s.assertErrorBlocked(c, false, checker.RemoveAllowed(), "")
+	s.assertErrorBlocked(c, false, checker.ChangeAllowed(mysql), "")
+
+	getter.entityBlocks[mysql] = s.change
+	s.assertErrorBlocked(c, true, checker.RemoveAllowed(mysql), s.change.Message())
+	s.assertErrorBlocked(c, true, checker.ChangeAllowed(mysql), s.change.Message())
+	s.assertErrorBlocked(c, false, checker.ChangeAllowed(wordpress), "")
+
+	// Blocks for the environment apply to every entity.
+	getter.envBlock = s.remove
+	s.assertErrorBlocked(c, true, checker.RemoveAllowed(wordpress), s.remove.Message())
+}
+
+func (s *blockCheckerSuite) TestEntityBlockCheckerDefaultMessage(c *gc.C) {
+	mysql := names.NewServiceTag("mysql")
+	getter := &mockEntityBlockGetter{
+		entityBlocks: map[names.Tag]state.Block{mysql: mockBlock{t: state.RemoveBlock}},
+	}
+	err := common.NewBlockChecker(getter).RemoveAllowed(mysql)
+	s.assertErrorBlocked(c, true, err, `The operation has been blocked for service mysql\.`)
+}
+
+func (s *blockCheckerSuite) TestEntityBlockCheckerNotSupported(c *gc.C) {
+	s.aBlock = s.destroy
+	err := s.blockchecker.RemoveAllowed(names.NewServiceTag("mysql"))
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *blockCheckerSuite) TestForcedRemoveBlockChecker(c *gc.C) {
+	mysql := names.NewServiceTag("mysql")
+	wordpress := names.NewServiceTag("wordpress")
+	getter := &mockEntityBlockGetter{
+		envBlock:     s.remove,
+		entityBlocks: map[names.Tag]state.Block{mysql: s.remove},
+	}
+	checker := common.NewBlockChecker(getter)
+
+	// Forced removals ignore the blocks of the environment.
+	s.assertErrorBlocked(c, false, checker.ForcedRemoveAllowed(wordpress), "")
+	s.assertErrorBlocked(c, false, checker.ForcedRemoveAllowed(), "")
+	s.assertErrorBlocked(c, true, checker.ForcedRemoveAllowed(wordpress, mysql), s.remove.Message())
+
+	getter.entityBlocks[mysql] = s.change
+	s.assertErrorBlocked(c, true, checker.ForcedRemoveAllowed(mysql), s.change.Message())
+}
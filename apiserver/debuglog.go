@@ -10,11 +10,14 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
+	"github.com/juju/utils/set"
 	"golang.org/x/net/websocket"
 
 	"github.com/juju/juju/apiserver/params"
@@ -69,6 +72,20 @@ func newDebugLogHandler(
 //      - has no meaning if 'replay' is true
 //   level -> string one of [TRACE, DEBUG, INFO, WARNING, ERROR]
 //   replay -> string - one of [true, false], if true, start the file from the start
+//   startTime -> string - RFC3339 time, only show lines logged at or after it
+//   endTime -> string - RFC3339 time, only show lines logged before it
+//      - the connection is closed once it has passed
+//   message -> string - only show lines whose message matches this regular expression,
+//      which is matched by the database with PCRE
+//   noTail -> string - one of [true, false], if true, close the connection after
+//      sending the lines already logged, rather than waiting for more
+//   format -> string - one of [text, json], if json, each line is a JSON
+//      object with the fields of params.DebugLogRecord
+//   fields -> []string - lists the fields of the JSON objects to send
+//      - if none are set, then all fields are sent
+//
+// The startTime, endTime, message, noTail and format arguments are only
+// supported when logs are stored in the database.
 func (h *debugLogHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	server := websocket.Server{
 		Handler: func(conn *websocket.Conn) {
@@ -84,7 +101,7 @@ func (h *debugLogHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 				socket.sendError(err)
 				return
 			}
-			if err := stateWrapper.authenticateUser(req); err != nil {
+			if _, err := stateWrapper.authorizeUser(req, state.EnvironmentReadAccess); err != nil {
 				socket.sendError(fmt.Errorf("auth failed: %v", err))
 				return
 			}
@@ -165,10 +182,37 @@ type debugLogParams struct {
 	excludeEntity []string
 	includeModule []string
 	excludeModule []string
+	startTime     time.Time
+	endTime       time.Time
+	messageRegexp string
+	noTail        bool
+	format        string
+	fields        []string
+}
+
+const (
+	// debugLogFormatText is the debug-log format that sends each log
+	// message as a preformatted line of text.
+	debugLogFormatText = "text"
+
+	// debugLogFormatJSON is the debug-log format that sends each log
+	// message as a line holding a params.DebugLogRecord JSON object.
+	debugLogFormatJSON = "json"
+)
+
+// validDebugLogFields holds the fields of the JSON objects sent by the
+// debug-log API that may be selected.
+var validDebugLogFields = set.NewStrings(params.DebugLogFields...)
+
+// hasDBOnlyParams reports whether any of the parameters that are only
+// supported when logs are stored in the database are set.
+func (p *debugLogParams) hasDBOnlyParams() bool {
+	return !p.startTime.IsZero() || !p.endTime.IsZero() || p.messageRegexp != "" || p.noTail ||
+		p.format == debugLogFormatJSON
 }
 
 func readDebugLogParams(queryMap url.Values) (*debugLogParams, error) {
-	params := new(debugLogParams)
+	params := &debugLogParams{format: debugLogFormatText}
 
 	if value := queryMap.Get("maxLines"); value != "" {
 		num, err := strconv.ParseUint(value, 10, 64)
@@ -204,10 +248,71 @@ func readDebugLogParams(queryMap url.Values) (*debugLogParams, error) {
 		params.filterLevel = level
 	}
 
+	if value := queryMap.Get("startTime"); value != "" {
+		startTime, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, errors.Errorf("startTime value %q is not a valid RFC3339 time", value)
+		}
+		params.startTime = startTime
+	}
+
+	if value := queryMap.Get("endTime"); value != "" {
+		endTime, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			return nil, errors.Errorf("endTime value %q is not a valid RFC3339 time", value)
+		}
+		params.endTime = endTime
+	}
+
+	if !params.startTime.IsZero() && !params.endTime.IsZero() && !params.endTime.After(params.startTime) {
+		return nil, errors.Errorf("endTime %s is not after startTime %s",
+			queryMap.Get("endTime"), queryMap.Get("startTime"))
+	}
+
+	if value := queryMap.Get("message"); value != "" {
+		// The expression is matched by the database, which uses PCRE.
+		// Checking it with Go's regexp package, whose syntax is that
+		// of PCRE less some extensions, refuses expressions that the
+		// database would fail to compile only once logs are read.
+		if _, err := regexp.Compile(value); err != nil {
+			return nil, errors.Errorf("message value %q is not a valid regular expression: %v", value, err)
+		}
+		params.messageRegexp = value
+	}
+
+	if value := queryMap.Get("noTail"); value != "" {
+		noTail, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.Errorf("noTail value %q is not a valid boolean", value)
+		}
+		params.noTail = noTail
+	}
+
 	params.includeEntity = queryMap["includeEntity"]
 	params.excludeEntity = queryMap["excludeEntity"]
 	params.includeModule = queryMap["includeModule"]
 	params.excludeModule = queryMap["excludeModule"]
 
+	if value := queryMap.Get("format"); value != "" {
+		if value != debugLogFormatText && value != debugLogFormatJSON {
+			return nil, errors.Errorf("format value %q is not one of %q, %q",
+				value, debugLogFormatText, debugLogFormatJSON)
+		}
+		params.format = value
+	}
+
+	if fields := queryMap["fields"]; len(fields) > 0 {
+		if params.format != debugLogFormatJSON {
+			return nil, errors.Errorf("fields are only supported with the %q format", debugLogFormatJSON)
+		}
+		for _, field := range fields {
+			if !validDebugLogFields.Contains(field) {
+				return nil, errors.Errorf("fields value %q is not one of %q",
+					field, validDebugLogFields.SortedValues())
+			}
+		}
+		params.fields = fields
+	}
+
 	return params, nil
 }
@@ -4,12 +4,16 @@
 package apiserver
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/juju/errors"
+	"github.com/juju/utils/set"
 
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/state"
 )
 
@@ -42,8 +46,11 @@ func handleDebugLogDBRequest(
 				return errors.Annotate(tailer.Err(), "tailer stopped")
 			}
 
-			line := formatLogRecord(rec)
-			_, err := socket.Write([]byte(line))
+			line, err := formatLogRecordAs(reqParams, rec)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			_, err = socket.Write([]byte(line))
 			if err != nil {
 				return errors.Annotate(err, "sending failed")
 			}
@@ -60,19 +67,72 @@ func handleDebugLogDBRequest(
 
 func makeLogTailerParams(reqParams *debugLogParams) *state.LogTailerParams {
 	params := &state.LogTailerParams{
+		StartTime:     reqParams.startTime,
+		EndTime:       reqParams.endTime,
 		MinLevel:      reqParams.filterLevel,
 		InitialLines:  int(reqParams.backlog),
 		IncludeEntity: reqParams.includeEntity,
 		ExcludeEntity: reqParams.excludeEntity,
 		IncludeModule: reqParams.includeModule,
 		ExcludeModule: reqParams.excludeModule,
+		MessageRegexp: reqParams.messageRegexp,
+		NoTail:        reqParams.noTail,
 	}
-	if reqParams.fromTheStart {
+	// A start time, like replay, asks for all the lines after it
+	// rather than the last few.
+	if reqParams.fromTheStart || !reqParams.startTime.IsZero() {
 		params.InitialLines = 0
 	}
 	return params
 }
 
+// formatLogRecordAs formats the log record in the format requested.
+func formatLogRecordAs(reqParams *debugLogParams, r *state.LogRecord) (string, error) {
+	if reqParams.format == debugLogFormatJSON {
+		return formatLogRecordJSON(r, reqParams.fields)
+	}
+	return formatLogRecord(r), nil
+}
+
+// formatLogRecordJSON formats the log record as a line holding a
+// params.DebugLogRecord JSON object. If any fields are given, only
+// those are included, in the order of params.DebugLogFields.
+func formatLogRecordJSON(r *state.LogRecord, fields []string) (string, error) {
+	data, err := json.Marshal(params.DebugLogRecord{
+		Entity:    r.Entity,
+		Timestamp: r.Time.UTC(),
+		Level:     r.Level.String(),
+		Module:    r.Module,
+		Location:  r.Location,
+		Message:   r.Message,
+	})
+	if err != nil {
+		return "", errors.Annotate(err, "cannot marshal log record")
+	}
+	if len(fields) == 0 {
+		return string(data) + "\n", nil
+	}
+
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return "", errors.Annotate(err, "cannot select log record fields")
+	}
+	selected := set.NewStrings(fields...)
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for _, field := range params.DebugLogFields {
+		if !selected.Contains(field) {
+			continue
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		fmt.Fprintf(&buf, "%q:%s", field, values[field])
+	}
+	buf.WriteString("}\n")
+	return buf.String(), nil
+}
+
 func formatLogRecord(r *state.LogRecord) string {
 	return fmt.Sprintf("%s: %s %s %s %s %s\n",
 		r.Entity,
@@ -5,6 +5,7 @@ package apiserver
 
 import (
 	"fmt"
+	"net/url"
 	"time"
 
 	"github.com/juju/loggo"
@@ -42,9 +43,8 @@ func (s *debugLogDBIntSuite) TestParamConversion(c *gc.C) {
 	s.PatchValue(&newLogTailer, func(_ state.LoggingState, params *state.LogTailerParams) state.LogTailer {
 		called = true
 
-		// Start time will be used once the client is extended to send
-		// time range arguments.
 		c.Assert(params.StartTime.IsZero(), jc.IsTrue)
+		c.Assert(params.EndTime.IsZero(), jc.IsTrue)
 
 		c.Assert(params.MinLevel, gc.Equals, loggo.INFO)
 		c.Assert(params.InitialLines, gc.Equals, 11)
@@ -86,6 +86,180 @@ func (s *debugLogDBIntSuite) TestParamConversionReplay(c *gc.C) {
 	c.Assert(called, jc.IsTrue)
 }
 
+func (s *debugLogDBIntSuite) TestParamConversionTimeRange(c *gc.C) {
+	startTime := time.Date(2015, 6, 19, 15, 0, 0, 0, time.UTC)
+	endTime := time.Date(2015, 6, 19, 16, 0, 0, 0, time.UTC)
+	reqParams := &debugLogParams{
+		backlog:       10,
+		startTime:     startTime,
+		endTime:       endTime,
+		messageRegexp: "hook .* failed",
+		noTail:        true,
+	}
+
+	called := false
+	s.PatchValue(&newLogTailer, func(_ state.LoggingState, params *state.LogTailerParams) state.LogTailer {
+		called = true
+
+		c.Assert(params.StartTime, gc.Equals, startTime)
+		c.Assert(params.EndTime, gc.Equals, endTime)
+		c.Assert(params.MessageRegexp, gc.Equals, "hook .* failed")
+		c.Assert(params.NoTail, jc.IsTrue)
+		// All the lines after the start time are wanted.
+		c.Assert(params.InitialLines, gc.Equals, 0)
+
+		return newFakeLogTailer()
+	})
+
+	stop := make(chan struct{})
+	close(stop) // Stop the request immediately.
+	err := handleDebugLogDBRequest(nil, reqParams, s.sock, stop)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(called, jc.IsTrue)
+}
+
+func (s *debugLogDBIntSuite) TestReadTimeRangeParams(c *gc.C) {
+	params, err := readDebugLogParams(url.Values{
+		"startTime": []string{"2015-06-19T15:00:00Z"},
+		"endTime":   []string{"2015-06-19T16:00:00.5+01:00"},
+		"message":   []string{"(?i)error"},
+		"noTail":    []string{"true"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(params.startTime.Equal(time.Date(2015, 6, 19, 15, 0, 0, 0, time.UTC)), jc.IsTrue)
+	c.Assert(params.endTime.Equal(time.Date(2015, 6, 19, 15, 0, 0, 5e8, time.UTC)), jc.IsTrue)
+	c.Assert(params.messageRegexp, gc.Equals, "(?i)error")
+	c.Assert(params.noTail, jc.IsTrue)
+	c.Assert(params.hasDBOnlyParams(), jc.IsTrue)
+
+	params, err = readDebugLogParams(url.Values{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(params.hasDBOnlyParams(), jc.IsFalse)
+}
+
+func (s *debugLogDBIntSuite) TestTimeRangeParamErrors(c *gc.C) {
+	_, err := readDebugLogParams(url.Values{"startTime": []string{"yesterday"}})
+	c.Assert(err, gc.ErrorMatches, `startTime value "yesterday" is not a valid RFC3339 time`)
+
+	_, err = readDebugLogParams(url.Values{"endTime": []string{"2015-06-19"}})
+	c.Assert(err, gc.ErrorMatches, `endTime value "2015-06-19" is not a valid RFC3339 time`)
+
+	_, err = readDebugLogParams(url.Values{
+		"startTime": []string{"2015-06-19T16:00:00Z"},
+		"endTime":   []string{"2015-06-19T15:00:00Z"},
+	})
+	c.Assert(err, gc.ErrorMatches, `endTime 2015-06-19T15:00:00Z is not after startTime 2015-06-19T16:00:00Z`)
+
+	_, err = readDebugLogParams(url.Values{"message": []string{"(unclosed"}})
+	c.Assert(err, gc.ErrorMatches, `message value "\(unclosed" is not a valid regular expression: .*`)
+
+	_, err = readDebugLogParams(url.Values{"noTail": []string{"foo"}})
+	c.Assert(err, gc.ErrorMatches, `noTail value "foo" is not a valid boolean`)
+}
+
+func (s *debugLogDBIntSuite) TestNoTailRequestStops(c *gc.C) {
+	tailer := newFakeLogTailer()
+	tailer.logsCh <- &state.LogRecord{
+		Time:     time.Date(2015, 6, 19, 15, 34, 37, 0, time.UTC),
+		Entity:   "machine-99",
+		Module:   "some.where",
+		Location: "code.go:42",
+		Level:    loggo.INFO,
+		Message:  "stuff happened",
+	}
+	// The tailer stops once it has sent the logs already recorded.
+	close(tailer.logsCh)
+	s.PatchValue(&newLogTailer, func(_ state.LoggingState, params *state.LogTailerParams) state.LogTailer {
+		return tailer
+	})
+
+	done := s.runRequest(&debugLogParams{noTail: true}, nil)
+	s.assertOutput(c, []string{
+		"ok",
+		"machine-99: 2015-06-19 15:34:37 INFO some.where code.go:42 stuff happened\n",
+	})
+	s.assertStops(c, done, tailer)
+}
+
+func (s *debugLogDBIntSuite) TestReadFormatParams(c *gc.C) {
+	params, err := readDebugLogParams(url.Values{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(params.format, gc.Equals, "text")
+
+	params, err = readDebugLogParams(url.Values{
+		"format": []string{"json"},
+		"fields": []string{"message", "entity"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(params.format, gc.Equals, "json")
+	c.Assert(params.fields, jc.DeepEquals, []string{"message", "entity"})
+	c.Assert(params.hasDBOnlyParams(), jc.IsTrue)
+}
+
+func (s *debugLogDBIntSuite) TestFormatParamErrors(c *gc.C) {
+	_, err := readDebugLogParams(url.Values{"format": []string{"xml"}})
+	c.Assert(err, gc.ErrorMatches, `format value "xml" is not one of "text", "json"`)
+
+	_, err = readDebugLogParams(url.Values{"fields": []string{"message"}})
+	c.Assert(err, gc.ErrorMatches, `fields are only supported with the "json" format`)
+
+	_, err = readDebugLogParams(url.Values{
+		"format": []string{"json"},
+		"fields": []string{"message", "host"},
+	})
+	c.Assert(err, gc.ErrorMatches, `fields value "host" is not one of \[.*\]`)
+}
+
+func (s *debugLogDBIntSuite) TestJSONRequest(c *gc.C) {
+	tailer := newFakeLogTailer()
+	tailer.logsCh <- &state.LogRecord{
+		Time:     time.Date(2015, 6, 19, 15, 34, 37, 0, time.UTC),
+		Entity:   "machine-99",
+		Module:   "some.where",
+		Location: "code.go:42",
+		Level:    loggo.INFO,
+		Message:  "stuff \"happened\"",
+	}
+	close(tailer.logsCh)
+	s.PatchValue(&newLogTailer, func(_ state.LoggingState, params *state.LogTailerParams) state.LogTailer {
+		return tailer
+	})
+
+	done := s.runRequest(&debugLogParams{format: "json"}, nil)
+	s.assertOutput(c, []string{
+		"ok",
+		`{"entity":"machine-99","timestamp":"2015-06-19T15:34:37Z","level":"INFO",` +
+			`"module":"some.where","location":"code.go:42","message":"stuff \"happened\""}` + "\n",
+	})
+	s.assertStops(c, done, tailer)
+}
+
+func (s *debugLogDBIntSuite) TestJSONRequestFields(c *gc.C) {
+	tailer := newFakeLogTailer()
+	tailer.logsCh <- &state.LogRecord{
+		Time:     time.Date(2015, 6, 19, 15, 34, 37, 0, time.UTC),
+		Entity:   "unit-foo-2",
+		Module:   "else.where",
+		Location: "go.go:22",
+		Level:    loggo.ERROR,
+		Message:  "whoops",
+	}
+	close(tailer.logsCh)
+	s.PatchValue(&newLogTailer, func(_ state.LoggingState, params *state.LogTailerParams) state.LogTailer {
+		return tailer
+	})
+
+	done := s.runRequest(&debugLogParams{
+		format: "json",
+		fields: []string{"message", "level", "entity"},
+	}, nil)
+	s.assertOutput(c, []string{
+		"ok",
+		`{"entity":"unit-foo-2","level":"ERROR","message":"whoops"}` + "\n",
+	})
+	s.assertStops(c, done, tailer)
+}
+
 func (s *debugLogDBIntSuite) TestFullRequest(c *gc.C) {
 	// Set up a fake log tailer with a 2 log records ready to send.
 	tailer := newFakeLogTailer()
@@ -12,6 +12,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/juju/errors"
 	"github.com/juju/juju/state"
 	"github.com/juju/loggo"
 	"github.com/juju/names"
@@ -34,6 +35,11 @@ func (h *debugLogFileHandler) handle(
 	socket debugLogSocket,
 	stop <-chan struct{},
 ) error {
+	if params.hasDBOnlyParams() {
+		err := errors.NotSupportedf("time range, message search, noTail and JSON format without the db-log feature")
+		socket.sendError(err)
+		return err
+	}
 	stream := newLogFileStream(params)
 
 	// Open log file.
@@ -457,3 +457,11 @@ func checkAgentParsing(c *gc.C, about, line, tag, name string) {
 	c.Assert(logLine.LogLineAgentTag(), gc.Equals, tag)
 	c.Assert(logLine.LogLineAgentName(), gc.Equals, name)
 }
+
+func (s *debugLogFileIntSuite) TestDBOnlyParamsNotSupported(c *gc.C) {
+	handler := &debugLogFileHandler{logDir: c.MkDir()}
+	sock := newFakeDebugLogSocket()
+	err := handler.handle(nil, &debugLogParams{noTail: true}, sock, nil)
+	c.Assert(err, gc.ErrorMatches, "time range, message search, noTail and JSON format without the db-log feature not supported")
+	c.Assert(<-sock.writes, gc.Equals, "err: time range, message search, noTail and JSON format without the db-log feature not supported")
+}
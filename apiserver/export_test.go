@@ -90,6 +90,26 @@ func TestingRestrictedApiHandler(st *state.State) rpc.MethodFinder {
 	return newRestrictedRoot(r)
 }
 
+// TestingAccessRoot returns an accessRoot for a user with the given
+// access to the environment.
+func TestingAccessRoot(st *state.State, access state.EnvironmentAccess) rpc.MethodFinder {
+	r := TestingApiRoot(st)
+	return newAccessRoot(r, access)
+}
+
+// TestingTokenRoot returns a tokenRoot for a user logged in with the
+// API token with the given id.
+func TestingTokenRoot(st *state.State, tokenID string) rpc.MethodFinder {
+	r := TestingApiRoot(st)
+	return newTokenRoot(r, st, tokenID)
+}
+
+// TestingAuditRoot returns an auditRoot recording the calls the user
+// makes to the given finder, which restricts those unrestricted finds.
+func TestingAuditRoot(finder, unrestricted rpc.MethodFinder, st *state.State, user names.UserTag) rpc.MethodFinder {
+	return newAuditRoot(finder, unrestricted, st, user)
+}
+
 type preFacadeAdminApi struct{}
 
 func newPreFacadeAdminApi(srv *Server, root *apiHandler, reqNotifier *requestNotifier) interface{} {
@@ -7,6 +7,7 @@ import (
 	"fmt"
 
 	"github.com/juju/errors"
+	"github.com/juju/names"
 
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/params"
@@ -83,6 +84,12 @@ func (mm *MachineManagerAPI) addOneMachine(p params.AddMachineParams) (*state.Ma
 			p.Placement = nil
 		}
 	}
+	if p.ParentId != "" {
+		// Adding a container changes its parent machine.
+		if err := mm.check.ChangeAllowed(names.NewMachineTag(p.ParentId)); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
 
 	if p.ContainerType != "" || p.Placement != nil {
 		// Guard against dubious client by making sure that
@@ -118,10 +118,32 @@ func (s *MachineManagerSuite) TestAddMachinesStateError(c *gc.C) {
 	c.Assert(s.st.calls, gc.Equals, 1)
 }
 
+func (s *MachineManagerSuite) TestAddMachinesContainerOnBlockedMachine(c *gc.C) {
+	s.st.blockedEntity = names.NewMachineTag("1")
+	results, err := s.api.AddMachines(params.AddMachines{
+		MachineParams: []params.AddMachineParams{{
+			Series:    "trusty",
+			Placement: instance.MustParsePlacement("lxc:1"),
+		}, {
+			Series:        "trusty",
+			ContainerType: instance.KVM,
+			ParentId:      "1",
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Machines, gc.HasLen, 2)
+	for _, result := range results.Machines {
+		c.Assert(result.Error, gc.ErrorMatches, "not allowed")
+		c.Assert(result.Error, jc.Satisfies, params.IsCodeOperationBlocked)
+	}
+	c.Assert(s.st.calls, gc.Equals, 0)
+}
+
 type mockState struct {
-	calls    int
-	machines []state.MachineTemplate
-	err      error
+	calls         int
+	machines      []state.MachineTemplate
+	err           error
+	blockedEntity names.Tag
 }
 
 func (st *mockState) AddOneMachine(template state.MachineTemplate) (*state.Machine, error) {
@@ -135,6 +157,13 @@ func (st *mockState) GetBlockForType(t state.BlockType) (state.Block, bool, erro
 	return &mockBlock{}, false, nil
 }
 
+func (st *mockState) GetEntityBlockForType(t state.BlockType, tag names.Tag) (state.Block, bool, error) {
+	if tag == st.blockedEntity && t == state.ChangeBlock {
+		return &mockBlock{}, true, nil
+	}
+	return nil, false, nil
+}
+
 func (st *mockState) EnvironConfig() (*config.Config, error) {
 	panic("not implemented")
 }
@@ -0,0 +1,58 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package orphans
+
+import (
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/state"
+)
+
+func init() {
+	common.RegisterStandardFacade("Orphans", 1, NewAPI)
+}
+
+// findOrphans is patched by tests.
+var findOrphans = environs.FindOrphans
+
+// API lists the orphaned provider resources of an environment, that
+// is what the orphan collector would remove.
+type API struct {
+	st         *state.State
+	authorizer common.Authorizer
+}
+
+// NewAPI returns a new orphans API facade.
+func NewAPI(
+	st *state.State,
+	resources *common.Resources,
+	authorizer common.Authorizer,
+) (*API, error) {
+	if !authorizer.AuthClient() {
+		return nil, common.ErrPerm
+	}
+	return &API{
+		st:         st,
+		authorizer: authorizer,
+	}, nil
+}
+
+// List returns the orphaned provider resources of the environment.
+func (api *API) List() (params.OrphansResults, error) {
+	_, orphans, err := findOrphans(api.st)
+	if err != nil {
+		return params.OrphansResults{}, common.ServerError(err)
+	}
+	result := params.OrphansResults{Orphans: make([]params.Orphan, len(orphans))}
+	for i, o := range orphans {
+		result.Orphans[i] = params.Orphan{
+			Kind: o.Kind,
+			Id:   o.Id,
+			Name: o.Name,
+			Zone: o.Zone,
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package orphans_test
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/apiserver/orphans"
+	"github.com/juju/juju/apiserver/params"
+	apiservertesting "github.com/juju/juju/apiserver/testing"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type orphansSuite struct {
+	coretesting.BaseSuite
+	authorizer apiservertesting.FakeAuthorizer
+}
+
+var _ = gc.Suite(&orphansSuite{})
+
+func (s *orphansSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.authorizer = apiservertesting.FakeAuthorizer{Tag: names.NewUserTag("admin")}
+}
+
+func (s *orphansSuite) TestNewAPIRequiresClient(c *gc.C) {
+	s.authorizer.Tag = names.NewMachineTag("0")
+	_, err := orphans.NewAPI(nil, common.NewResources(), s.authorizer)
+	c.Assert(err, gc.Equals, common.ErrPerm)
+}
+
+func (s *orphansSuite) TestList(c *gc.C) {
+	s.PatchValue(orphans.FindOrphans, func(*state.State) (environs.OrphanCollector, []environs.Orphan, error) {
+		return nil, []environs.Orphan{{Kind: "drive", Id: "uuid-0", Name: "juju-env-2", Zone: "zrh"}}, nil
+	})
+	api, err := orphans.NewAPI(nil, common.NewResources(), s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := api.List()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result, jc.DeepEquals, params.OrphansResults{
+		Orphans: []params.Orphan{{Kind: "drive", Id: "uuid-0", Name: "juju-env-2", Zone: "zrh"}},
+	})
+}
+
+func (s *orphansSuite) TestListNotSupported(c *gc.C) {
+	s.PatchValue(orphans.FindOrphans, func(*state.State) (environs.OrphanCollector, []environs.Orphan, error) {
+		return nil, nil, errors.NotSupportedf("dummy provider orphan collection")
+	})
+	api, err := orphans.NewAPI(nil, common.NewResources(), s.authorizer)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = api.List()
+	c.Assert(err, gc.ErrorMatches, "dummy provider orphan collection not supported")
+}
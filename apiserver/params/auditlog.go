@@ -0,0 +1,58 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+import "time"
+
+// AuditRecord describes an API call made by a user that changed, or
+// tried to change, an environment.
+type AuditRecord struct {
+	// Time is when the call was made.
+	Time time.Time `json:"time"`
+
+	// User is the tag of the user that made the call.
+	User string `json:"user"`
+
+	// Facade, Version and Method identify the API call.
+	Facade  string `json:"facade"`
+	Version int    `json:"version"`
+	Method  string `json:"method"`
+
+	// Entities holds the tags of the entities the call refers to.
+	Entities []string `json:"entities,omitempty"`
+
+	// Args holds the arguments of the call as JSON, with any secrets
+	// in them redacted.
+	Args string `json:"args,omitempty"`
+
+	// Error holds the error the call failed with, if it did.
+	Error string `json:"error,omitempty"`
+}
+
+// AuditRecordFilter holds the parameters for an AuditLog.Records
+// call. The zero value selects all records.
+type AuditRecordFilter struct {
+	// User, if set, selects records of calls made by the user with
+	// the given tag.
+	User string `json:"user,omitempty"`
+
+	// Entity, if set, selects records of calls that refer to the
+	// entity with the given tag.
+	Entity string `json:"entity,omitempty"`
+
+	// From and To, if set, select records of calls made at or after
+	// From, and before To.
+	From time.Time `json:"from,omitempty"`
+	To   time.Time `json:"to,omitempty"`
+
+	// Limit, if positive, selects only that many of the newest
+	// matching records.
+	Limit int `json:"limit,omitempty"`
+}
+
+// AuditRecordsResult holds the result of an AuditLog.Records call.
+type AuditRecordsResult struct {
+	// Records holds the selected records, oldest first.
+	Records []AuditRecord `json:"records"`
+}
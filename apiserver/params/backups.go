@@ -9,9 +9,22 @@ import (
 	"github.com/juju/juju/version"
 )
 
+// BackupsKey holds the key used to encrypt a backup archive: a
+// passphrase or an armored OpenPGP public key. Archives are only ever
+// decrypted by the client, so no private key is sent.
+type BackupsKey struct {
+	Passphrase string
+	PublicKey  string
+}
+
 // BackupsCreateArgs holds the args for the API Create method.
 type BackupsCreateArgs struct {
 	Notes string
+	// Key encrypts the new backup archive, if set.
+	Key *BackupsKey
+	// Incremental requests a backup of the changes since the last
+	// backup built on the latest full backup.
+	Incremental bool
 }
 
 // BackupsInfoArgs holds the args for the API Info method.
@@ -66,10 +79,51 @@ type BackupsMetadataResult struct {
 	Machine     string
 	Hostname    string
 	Version     version.Number
+
+	// KeyFingerprint identifies the key the archive is encrypted
+	// with, if any.
+	KeyFingerprint string
+	// Location is where the archive is stored, as given by the
+	// backups-storage environment setting when it was stored.
+	Location string
+
+	// Base is the ID of the full backup an incremental backup builds
+	// on. It is empty for full backups.
+	Base string
+	// OplogStart and OplogEnd are the MongoDB timestamps delimiting
+	// the span of the oplog the backup covers, if known.
+	OplogStart int64
+	OplogEnd   int64
+}
+
+// BackupsSchedule holds the schedule of automatic backups and its
+// retention policy, as used by the API Schedule and SetSchedule
+// methods.
+type BackupsSchedule struct {
+	// Interval is the time between scheduled backups. Zero disables
+	// scheduled backups.
+	Interval time.Duration
+	// KeepDaily is the number of days for which the last scheduled
+	// backup of the day is kept.
+	KeepDaily int
+	// KeepWeekly is the number of weeks for which the last scheduled
+	// backup of the week is kept.
+	KeepWeekly int
+	// PublicKey is the armored OpenPGP public key scheduled backups
+	// are encrypted with, if any.
+	PublicKey string
+	// KeyFingerprint identifies the public key. It is ignored by
+	// SetSchedule.
+	KeyFingerprint string
 }
 
 // RestoreArgs Holds the backup file or id
 type RestoreArgs struct {
 	// BackupId holds the id of the backup in server if any
 	BackupId string
+	// Until is the point in time up to which the changes recorded by
+	// the backup and the incremental backups built on it are restored.
+	// All the changes recorded by the backup are restored if it is
+	// zero.
+	Until time.Time
 }
@@ -3,6 +3,8 @@
 
 package params
 
+import "time"
+
 // Block describes a Juju block that protects environment from
 // corruption.
 type Block struct {
@@ -19,6 +21,9 @@ type Block struct {
 	// Message is a descriptive or an explanatory message
 	// that the block was created with.
 	Message string `json:"message,omitempty"`
+
+	// Expires, if set, is when the block switches itself off.
+	Expires *time.Time `json:"expires,omitempty"`
 }
 
 // BlockSwitchParams holds the parameters for switching
@@ -31,6 +36,15 @@ type BlockSwitchParams struct {
 	// Message is a descriptive or an explanatory message
 	// that accompanies the switch.
 	Message string `json:"message,omitempty"`
+
+	// Tag, if set, holds the tag of the service or machine
+	// the block is for, instead of the whole environment.
+	Tag string `json:"tag,omitempty"`
+
+	// TTL, if set, is how long the block stays on before
+	// it switches itself off. It is ignored when switching
+	// a block off.
+	TTL time.Duration `json:"ttl,omitempty"`
 }
 
 // BlockResult holds the result of an API call to retrieve details
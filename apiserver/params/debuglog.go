@@ -0,0 +1,44 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package params
+
+import "time"
+
+// DebugLogRecord describes a log message sent by the debug-log API
+// endpoint when JSON output is requested. Each message is sent as a
+// single line holding a JSON object. The field names are stable, so
+// that the messages may be fed straight into other log tooling.
+type DebugLogRecord struct {
+	// Entity is the tag of the agent that logged the message.
+	Entity string `json:"entity"`
+
+	// Timestamp is when the message was logged.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Level is the name of the level the message was logged at, such
+	// as "INFO".
+	Level string `json:"level"`
+
+	// Module is the name of the logging module the message was
+	// logged to.
+	Module string `json:"module"`
+
+	// Location is the source file and line the message was logged
+	// from.
+	Location string `json:"location"`
+
+	// Message is the text of the message.
+	Message string `json:"message"`
+}
+
+// DebugLogFields holds the names of the fields of a DebugLogRecord, any
+// of which may be selected to be sent, in the order they are sent.
+var DebugLogFields = []string{
+	"entity",
+	"timestamp",
+	"level",
+	"module",
+	"location",
+	"message",
+}
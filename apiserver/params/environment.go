@@ -42,9 +42,12 @@ const (
 )
 
 // ModifyEnvironUser stores the parameters used for a Client.ShareEnvironment call.
+// Access is the level of access ("read", "write" or "admin") given to a
+// user the environment is shared with; it defaults to "admin".
 type ModifyEnvironUser struct {
 	UserTag string        `json:"user-tag"`
 	Action  EnvironAction `json:"action"`
+	Access  string        `json:"access,omitempty"`
 }
 
 // SetEnvironAgentVersion contains the arguments for
@@ -60,6 +63,7 @@ type EnvUserInfo struct {
 	CreatedBy      string     `json:"createdby"`
 	DateCreated    time.Time  `json:"datecreated"`
 	LastConnection *time.Time `json:"lastconnection"`
+	Access         string     `json:"access,omitempty"`
 }
 
 // EnvUserInfoResult holds the result of an EnvUserInfo call.
@@ -72,3 +76,17 @@ type EnvUserInfoResult struct {
 type EnvUserInfoResults struct {
 	Results []EnvUserInfoResult `json:"results"`
 }
+
+// Orphan describes a provider resource of the environment that does
+// not back any machine or volume known to state.
+type Orphan struct {
+	Kind string `json:"kind"`
+	Id   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	Zone string `json:"zone,omitempty"`
+}
+
+// OrphansResults holds the orphaned resources of an environment.
+type OrphansResults struct {
+	Orphans []Orphan `json:"orphans"`
+}
@@ -163,15 +163,42 @@ type LegacyMachineStatus struct {
 	InstanceId string // Not type instance.Id just to match original api.
 }
 
-// TODO(ericsnow) Rename to StatusHistoryArgs.
-
-// StatusHistory holds the parameters to filter a status history query.
+// StatusHistory holds the parameters to filter a unit status history
+// query, as made by the UnitStatusHistory API call.
 type StatusHistory struct {
 	Kind HistoryKind
 	Size int
 	Name string
 }
 
+// StatusHistoryArgs holds the parameters of a StatusHistory API call.
+type StatusHistoryArgs struct {
+	// Tag identifies the entity whose status history is returned:
+	// a unit, machine, service, volume or filesystem.
+	Tag string
+
+	// Kind specifies which status history of a unit is returned:
+	// KindAgent, KindWorkload or, if empty, KindCombined. It must be
+	// empty for other entities.
+	Kind HistoryKind
+
+	// Size, if positive, limits the entries returned to the newest
+	// Size ones.
+	Size int
+
+	// FromDate, if not nil, excludes entries recorded before it.
+	FromDate *time.Time
+
+	// ToDate, if not nil, excludes entries recorded at or after it.
+	ToDate *time.Time
+}
+
+// StatusHistoryResult holds the result of a StatusHistory API call,
+// the status history entries of an entity, oldest first.
+type StatusHistoryResult struct {
+	Statuses []AgentStatus
+}
+
 // TODO(ericsnow) Rename to UnitStatusHistoryResult.
 
 // UnitStatusHistory holds a slice of statuses.
@@ -214,6 +241,11 @@ const (
 	KindCombined HistoryKind = "combined"
 	KindAgent    HistoryKind = "agent"
 	KindWorkload HistoryKind = "workload"
+
+	KindMachine    HistoryKind = "machine"
+	KindService    HistoryKind = "service"
+	KindVolume     HistoryKind = "volume"
+	KindFilesystem HistoryKind = "filesystem"
 )
 
 // Life describes the lifecycle state of an entity ("alive", "dying" or "dead").
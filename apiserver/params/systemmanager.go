@@ -3,6 +3,8 @@
 
 package params
 
+import "time"
+
 // DestroySystemArgs holds the arguments for destroying a system.
 type DestroySystemArgs struct {
 	// DestroyEnvironments specifies whether or not the hosted environments
@@ -36,3 +38,36 @@ type EnvironmentBlockInfoList struct {
 type RemoveBlocksArgs struct {
 	All bool `json:"all"`
 }
+
+// LogPruneCounts holds the numbers of log records removed by pruning
+// for each of the limits that logs are pruned by.
+type LogPruneCounts struct {
+	Age    int `json:"age"`
+	Level  int `json:"level"`
+	Entity int `json:"entity"`
+	Size   int `json:"size"`
+}
+
+// LogEntityPruneCount holds the number of log records of an entity
+// removed to keep them within the per-entity limit.
+type LogEntityPruneCount struct {
+	EnvUUID string `json:"env-uuid"`
+	Entity  string `json:"entity"`
+	Removed int    `json:"removed"`
+}
+
+// LogPruneStats describes the pruning of the log records stored in the
+// database of a system.
+type LogPruneStats struct {
+	Time         time.Time             `json:"time"`
+	CollectionMB int                   `json:"collection-mb"`
+	Removed      LogPruneCounts        `json:"removed"`
+	TotalRemoved LogPruneCounts        `json:"total-removed"`
+	Entities     []LogEntityPruneCount `json:"entities,omitempty"`
+}
+
+// LogPruneStatsResult holds the result of the LogPruneStats API call.
+// Stats is nil if the logs have not been pruned.
+type LogPruneStatsResult struct {
+	Stats *LogPruneStats `json:"stats,omitempty"`
+}
@@ -15,6 +15,12 @@ type UserInfo struct {
 	DateCreated    time.Time  `json:"date-created"`
 	LastConnection *time.Time `json:"last-connection,omitempty"`
 	Disabled       bool       `json:"disabled"`
+	// Access is the user's level of access to the environment, if
+	// they have any.
+	Access string `json:"access,omitempty"`
+	// IdentityProvider names what checks the user's password, if
+	// not juju.
+	IdentityProvider string `json:"identity-provider,omitempty"`
 }
 
 // UserInfoResult holds the result of a UserInfo call.
@@ -45,6 +51,9 @@ type AddUser struct {
 	Username    string `json:"username"`
 	DisplayName string `json:"display-name"`
 	Password    string `json:"password"`
+	// IdentityProvider, if set, names what checks the user's
+	// password, and Password is ignored.
+	IdentityProvider string `json:"identity-provider,omitempty"`
 }
 
 // AddUserResults holds the results of the bulk AddUser API call.
@@ -57,3 +66,61 @@ type AddUserResult struct {
 	Tag   string `json:"tag,omitempty"`
 	Error *Error `json:"error,omitempty"`
 }
+
+// APIToken holds information on an API token.
+type APIToken struct {
+	Id       string    `json:"id"`
+	User     string    `json:"user"`
+	Created  time.Time `json:"created"`
+	Expires  time.Time `json:"expires"`
+	ReadOnly bool      `json:"read-only,omitempty"`
+	Facades  []string  `json:"facades,omitempty"`
+}
+
+// AddAPITokens holds the parameters for adding API tokens.
+type AddAPITokens struct {
+	Tokens []AddAPIToken `json:"tokens"`
+}
+
+// AddAPIToken stores the parameters to add one API token.
+type AddAPIToken struct {
+	// User is the tag of the user the token lets log in, which must
+	// be the user adding it. It defaults to the user adding it.
+	User    string    `json:"user,omitempty"`
+	Expires time.Time `json:"expires"`
+	// ReadOnly limits the calls made with the token to those that do
+	// not change the environment.
+	ReadOnly bool `json:"read-only,omitempty"`
+	// Facades, if set, limits the calls made with the token to those
+	// on the named facades.
+	Facades []string `json:"facades,omitempty"`
+}
+
+// AddAPITokenResults holds the results of the bulk AddAPITokens API call.
+type AddAPITokenResults struct {
+	Results []AddAPITokenResult `json:"results"`
+}
+
+// AddAPITokenResult returns the new token and the credentials to log
+// in with it, or an error. The credentials cannot be had again.
+type AddAPITokenResult struct {
+	Token       *APIToken `json:"token,omitempty"`
+	Credentials string    `json:"credentials,omitempty"`
+	Error       *Error    `json:"error,omitempty"`
+}
+
+// APITokenIds holds the ids of API tokens.
+type APITokenIds struct {
+	Ids []string `json:"ids"`
+}
+
+// APITokensResult holds the result of an APITokens call.
+type APITokensResult struct {
+	Result []APIToken `json:"result,omitempty"`
+	Error  *Error     `json:"error,omitempty"`
+}
+
+// APITokensResults holds the results of the bulk APITokens API call.
+type APITokensResults struct {
+	Results []APITokensResult `json:"results"`
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/instance"
 	jjj "github.com/juju/juju/juju"
 	"github.com/juju/juju/state"
 	statestorage "github.com/juju/juju/state/storage"
@@ -68,6 +69,10 @@ func (api *API) SetMetricCredentials(args params.ServiceMetricCredentials) (para
 		return result, nil
 	}
 	for i, a := range args.Creds {
+		if err := api.check.ChangeAllowed(names.NewServiceTag(a.ServiceName)); err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
 		service, err := api.state.Service(a.ServiceName)
 		if err != nil {
 			result.Results[i].Error = common.ServerError(err)
@@ -97,12 +102,42 @@ func (api *API) ServicesDeployWithPlacement(args params.ServicesDeploy) (params.
 	}
 	owner := api.authorizer.GetAuthTag().String()
 	for i, arg := range args.Services {
+		if err := api.check.ChangeAllowed(PlacementMachines(arg)...); err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
 		err := DeployService(api.state, owner, arg)
 		result.Results[i].Error = common.ServerError(err)
 	}
 	return result, nil
 }
 
+// PlacementMachines returns the tags of the machines the units of the
+// service are to be deployed to, or to be deployed to containers in.
+func PlacementMachines(args params.ServiceDeploy) []names.Tag {
+	placements := args.Placement
+	if len(placements) == 0 {
+		if placement, err := instance.ParsePlacement(args.ToMachineSpec); err == nil && placement != nil {
+			placements = []*instance.Placement{placement}
+		}
+	}
+	var machines []names.Tag
+	for _, placement := range placements {
+		if placement == nil || !names.IsValidMachine(placement.Directive) {
+			continue
+		}
+		if placement.Scope == instance.MachineScope || isContainerType(placement.Scope) {
+			machines = append(machines, names.NewMachineTag(placement.Directive))
+		}
+	}
+	return machines
+}
+
+func isContainerType(scope string) bool {
+	_, err := instance.ParseContainerType(scope)
+	return err == nil
+}
+
 // DeployService fetches the charm from the charm store and deploys it.
 // The logic has been factored out into a common function which is called by
 // both the legacy API on the client facade, as well as the new service facade.
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"sync"
+	"time"
 
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
@@ -372,6 +373,80 @@ func (s *serviceSuite) TestClientServiceDeployWithInvalidPlacement(c *gc.C) {
 	c.Assert(results.Results[0].Error.Error(), gc.Matches, ".* invalid placement is invalid")
 }
 
+func (s *serviceSuite) TestEntityBlockServicesDeployToMachine(c *gc.C) {
+	curl, _ := s.UploadCharm(c, "precise/dummy-42", "dummy")
+	err := service.AddCharmWithAuthorization(s.State, params.AddCharmWithAuthorization{URL: curl.String()})
+	c.Assert(err, jc.ErrorIsNil)
+	machine, err := s.State.AddMachine("precise", state.JobHostUnits)
+	c.Assert(err, jc.ErrorIsNil)
+	err = s.State.SwitchEntityBlockOn(state.ChangeBlock, machine.Tag(), "TestEntityBlockServicesDeployToMachine", time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	results, err := s.serviceApi.ServicesDeploy(params.ServicesDeploy{
+		Services: []params.ServiceDeploy{{
+			ServiceName: "blocked",
+			CharmUrl:    curl.String(),
+			NumUnits:    1,
+			Placement:   []*instance.Placement{instance.MustParsePlacement(machine.Id())},
+		}, {
+			ServiceName:   "blocked-container",
+			CharmUrl:      curl.String(),
+			NumUnits:      1,
+			ToMachineSpec: "lxc:" + machine.Id(),
+		}, {
+			ServiceName: "allowed",
+			CharmUrl:    curl.String(),
+			NumUnits:    1,
+		}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 3)
+	s.AssertBlocked(c, results.Results[0].Error, "TestEntityBlockServicesDeployToMachine")
+	s.AssertBlocked(c, results.Results[1].Error, "TestEntityBlockServicesDeployToMachine")
+	c.Assert(results.Results[2].Error, gc.IsNil)
+
+	_, err = s.State.Service("blocked")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.State.Service("blocked-container")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.State.Service("allowed")
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *serviceSuite) TestEntityBlockSetMetricCredentials(c *gc.C) {
+	charm := s.Factory.MakeCharm(c, &factory.CharmParams{Name: "wordpress"})
+	wordpress := s.Factory.MakeService(c, &factory.ServiceParams{Charm: charm})
+	err := s.State.SwitchEntityBlockOn(state.ChangeBlock, wordpress.Tag(), "TestEntityBlockSetMetricCredentials", time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	results, err := s.serviceApi.SetMetricCredentials(params.ServiceMetricCredentials{
+		Creds: []params.ServiceMetricCredential{
+			{ServiceName: wordpress.Name(), MetricCredentials: []byte("creds 1234")},
+			{ServiceName: s.service.Name(), MetricCredentials: []byte("creds 4567")},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 2)
+	s.AssertBlocked(c, results.Results[0].Error, "TestEntityBlockSetMetricCredentials")
+	c.Assert(results.Results[1].Error, gc.IsNil)
+
+	err = wordpress.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(wordpress.MetricCredentials(), gc.HasLen, 0)
+}
+
+func (s *serviceSuite) TestBlockChangesSetMetricCredentials(c *gc.C) {
+	s.BlockAllChanges(c, "TestBlockChangesSetMetricCredentials")
+	results, err := s.serviceApi.SetMetricCredentials(params.ServiceMetricCredentials{
+		Creds: []params.ServiceMetricCredential{
+			{ServiceName: s.service.Name(), MetricCredentials: []byte("creds 1234")},
+		},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	s.AssertBlocked(c, results.Results[0].Error, "TestBlockChangesSetMetricCredentials")
+}
+
 // TODO(wallyworld) - the following charm tests have been moved from the apiserver/client
 // package in order to use the fake charm store testing infrastructure. They are legacy tests
 // written to use the api client instead of the apiserver logic. They need to be rewritten and
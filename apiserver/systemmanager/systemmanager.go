@@ -31,6 +31,7 @@ type SystemManager interface {
 	DestroySystem(args params.DestroySystemArgs) error
 	EnvironmentConfig() (params.EnvironmentConfigResults, error)
 	ListBlockedEnvironments() (params.EnvironmentBlockInfoList, error)
+	LogPruneStats() (params.LogPruneStatsResult, error)
 	RemoveBlocks(args params.RemoveBlocksArgs) error
 	WatchAllEnvs() (params.AllWatcherId, error)
 }
@@ -252,6 +253,41 @@ func (s *SystemManagerAPI) EnvironmentConfig() (params.EnvironmentConfigResults,
 	return result, nil
 }
 
+// LogPruneStats returns the stats of the last pruning of the log
+// records stored in the database of the system, and the totals since
+// the logs were first pruned.
+func (s *SystemManagerAPI) LogPruneStats() (params.LogPruneStatsResult, error) {
+	stats, err := state.GetLogPruneStats(s.state)
+	if errors.IsNotFound(err) {
+		return params.LogPruneStatsResult{}, nil
+	} else if err != nil {
+		return params.LogPruneStatsResult{}, errors.Trace(err)
+	}
+	result := &params.LogPruneStats{
+		Time:         stats.Time,
+		CollectionMB: stats.CollectionMB,
+		Removed:      logPruneCounts(stats.Removed),
+		TotalRemoved: logPruneCounts(stats.TotalRemoved),
+	}
+	for _, entity := range stats.Entities {
+		result.Entities = append(result.Entities, params.LogEntityPruneCount{
+			EnvUUID: entity.EnvUUID,
+			Entity:  entity.Entity,
+			Removed: entity.Removed,
+		})
+	}
+	return params.LogPruneStatsResult{Stats: result}, nil
+}
+
+func logPruneCounts(counts state.LogPruneCounts) params.LogPruneCounts {
+	return params.LogPruneCounts{
+		Age:    counts.Age,
+		Level:  counts.Level,
+		Entity: counts.Entity,
+		Size:   counts.Size,
+	}
+}
+
 // RemoveBlocks removes all the blocks in the system.
 func (s *SystemManagerAPI) RemoveBlocks(args params.RemoveBlocksArgs) error {
 	if !args.All {
@@ -83,7 +83,7 @@ func (s *systemManagerSuite) TestAllEnvironments(c *gc.C) {
 	st := s.Factory.MakeEnvironment(c, &factory.EnvParams{
 		Name: "user", Owner: remoteUserTag})
 	defer st.Close()
-	st.AddEnvironmentUser(admin.UserTag(), remoteUserTag, "Foo Bar")
+	st.AddEnvironmentUser(admin.UserTag(), remoteUserTag, "Foo Bar", state.EnvironmentAdminAccess)
 
 	s.Factory.MakeEnvironment(c, &factory.EnvParams{
 		Name: "no-access", Owner: remoteUserTag}).Close()
@@ -163,6 +163,33 @@ func (s *systemManagerSuite) TestEnvironmentConfigFromNonStateServer(c *gc.C) {
 	c.Assert(env.Config["name"], gc.Equals, "dummyenv")
 }
 
+func (s *systemManagerSuite) TestLogPruneStatsNotPruned(c *gc.C) {
+	result, err := s.systemManager.LogPruneStats()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Stats, gc.IsNil)
+}
+
+func (s *systemManagerSuite) TestLogPruneStats(c *gc.C) {
+	now := time.Now().Truncate(time.Millisecond)
+	dbLogger := state.NewDbLogger(s.State, names.NewMachineTag("0"))
+	defer dbLogger.Close()
+	err := dbLogger.Log(now.Add(-2*time.Hour), "module", "loc", loggo.INFO, "old")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = state.PruneLogsWithPolicy(s.State, now, state.LogPrunePolicy{
+		MaxAge:          time.Hour,
+		MaxCollectionMB: 100,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	result, err := s.systemManager.LogPruneStats()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Stats, gc.NotNil)
+	c.Assert(result.Stats.Time.Equal(now), jc.IsTrue)
+	c.Assert(result.Stats.Removed, gc.Equals, params.LogPruneCounts{Age: 1})
+	c.Assert(result.Stats.TotalRemoved, gc.Equals, params.LogPruneCounts{Age: 1})
+	c.Assert(result.Stats.Entities, gc.HasLen, 0)
+}
+
 func (s *systemManagerSuite) TestRemoveBlocks(c *gc.C) {
 	st := s.Factory.MakeEnvironment(c, &factory.EnvParams{
 		Name: "test"})
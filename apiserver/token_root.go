@@ -0,0 +1,82 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/set"
+
+	"github.com/juju/juju/apiserver/common"
+	"github.com/juju/juju/rpc"
+	"github.com/juju/juju/rpc/rpcreflect"
+	"github.com/juju/juju/state"
+)
+
+// tokenRoot restricts the API calls a user logged in with an API token
+// may make to those the token allows. The token is checked on every
+// call, so that calls fail as soon as it expires or is revoked.
+type tokenRoot struct {
+	rpc.MethodFinder
+	st      *state.State
+	tokenID string
+}
+
+// newTokenRoot returns a new tokenRoot for a user logged in with the
+// API token with the given id.
+func newTokenRoot(finder rpc.MethodFinder, st *state.State, tokenID string) *tokenRoot {
+	return &tokenRoot{
+		MethodFinder: finder,
+		st:           st,
+		tokenID:      tokenID,
+	}
+}
+
+// tokenDeniedCalls are the API calls that may not be made with an API
+// token, so that tokens cannot be used to get other credentials.
+var tokenDeniedCalls = set.NewStrings(
+	"UserManager.AddAPITokens",
+	"UserManager.SetPassword",
+)
+
+// FindMethod returns common.ErrPerm if the API token does not allow the
+// call, or no longer exists or has expired.
+func (r *tokenRoot) FindMethod(rootName string, version int, methodName string) (rpcreflect.MethodCaller, error) {
+	// The lookup of the name is done first to return a not found error if the
+	// user is looking for a method that we just don't have.
+	caller, err := r.MethodFinder.FindMethod(rootName, version, methodName)
+	if err != nil {
+		return nil, err
+	}
+	token, err := r.st.APIToken(r.tokenID)
+	if errors.IsNotFound(err) {
+		return nil, common.ErrPerm
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if token.Expired() || !isTokenCallAllowed(token, rootName, methodName) {
+		return nil, common.ErrPerm
+	}
+	return caller, nil
+}
+
+// isTokenCallAllowed reports whether the API token allows calls of the
+// facade method.
+func isTokenCallAllowed(token *state.APIToken, rootName, methodName string) bool {
+	fullName := rootName + "." + methodName
+	if tokenDeniedCalls.Contains(fullName) {
+		return false
+	}
+	if token.ReadOnly() && !readOnlyCalls.Contains(fullName) {
+		return false
+	}
+	facades := token.Facades()
+	// Connections must be kept alive, and watchers can only be
+	// reached through calls on the facades allowed.
+	if len(facades) == 0 || rootName == "Pinger" || strings.HasSuffix(rootName, "Watcher") {
+		return true
+	}
+	return set.NewStrings(facades...).Contains(rootName)
+}
@@ -0,0 +1,94 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package apiserver_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver"
+	"github.com/juju/juju/apiserver/common"
+	jujutesting "github.com/juju/juju/juju/testing"
+	"github.com/juju/juju/state"
+)
+
+type tokenRootSuite struct {
+	jujutesting.JujuConnSuite
+}
+
+var _ = gc.Suite(&tokenRootSuite{})
+
+func (s *tokenRootSuite) addToken(c *gc.C, readOnly bool, facades ...string) *state.APIToken {
+	token, _, err := s.State.AddAPIToken(state.APITokenParams{
+		User:     s.AdminUserTag(c),
+		Expires:  time.Now().Add(time.Hour),
+		ReadOnly: readOnly,
+		Facades:  facades,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return token
+}
+
+func (s *tokenRootSuite) assertMethodAllowed(c *gc.C, token *state.APIToken, rootName string, version int, method string) {
+	root := apiserver.TestingTokenRoot(s.State, token.ID())
+	caller, err := root.FindMethod(rootName, version, method)
+	c.Check(err, jc.ErrorIsNil)
+	c.Check(caller, gc.NotNil)
+}
+
+func (s *tokenRootSuite) assertMethodDenied(c *gc.C, token *state.APIToken, rootName string, version int, method string) {
+	root := apiserver.TestingTokenRoot(s.State, token.ID())
+	caller, err := root.FindMethod(rootName, version, method)
+	c.Check(err, gc.Equals, common.ErrPerm)
+	c.Check(caller, gc.IsNil)
+}
+
+func (s *tokenRootSuite) TestUnrestrictedToken(c *gc.C) {
+	token := s.addToken(c, false)
+	s.assertMethodAllowed(c, token, "Client", 0, "FullStatus")
+	s.assertMethodAllowed(c, token, "Client", 0, "ServiceDeploy")
+	s.assertMethodAllowed(c, token, "UserManager", 0, "RevokeAPITokens")
+
+	// Tokens cannot be used to get other credentials.
+	s.assertMethodDenied(c, token, "UserManager", 0, "AddAPITokens")
+	s.assertMethodDenied(c, token, "UserManager", 0, "SetPassword")
+}
+
+func (s *tokenRootSuite) TestReadOnlyToken(c *gc.C) {
+	token := s.addToken(c, true)
+	s.assertMethodAllowed(c, token, "Client", 0, "FullStatus")
+	s.assertMethodAllowed(c, token, "Pinger", 0, "Ping")
+
+	s.assertMethodDenied(c, token, "Client", 0, "ServiceDeploy")
+	s.assertMethodDenied(c, token, "UserManager", 0, "SetPassword")
+}
+
+func (s *tokenRootSuite) TestFacadesToken(c *gc.C) {
+	token := s.addToken(c, false, "Client")
+	s.assertMethodAllowed(c, token, "Client", 0, "FullStatus")
+	s.assertMethodAllowed(c, token, "Client", 0, "ServiceDeploy")
+	s.assertMethodAllowed(c, token, "Pinger", 0, "Ping")
+	s.assertMethodAllowed(c, token, "AllWatcher", 0, "Next")
+
+	s.assertMethodDenied(c, token, "Backups", 0, "Create")
+	s.assertMethodDenied(c, token, "UserManager", 0, "UserInfo")
+}
+
+func (s *tokenRootSuite) TestRevokedToken(c *gc.C) {
+	token := s.addToken(c, false)
+	err := s.State.RevokeAPIToken(token.ID())
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertMethodDenied(c, token, "Client", 0, "FullStatus")
+}
+
+func (s *tokenRootSuite) TestFindNonExistentMethod(c *gc.C) {
+	token := s.addToken(c, false)
+	root := apiserver.TestingTokenRoot(s.State, token.ID())
+	caller, err := root.FindMethod("Client", 0, "Bar")
+
+	c.Assert(err, gc.ErrorMatches, `no such request - method Client\(0\).Bar is not implemented`)
+	c.Assert(caller, gc.IsNil)
+}
@@ -13,6 +13,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/utils"
@@ -74,8 +75,11 @@ func (h *toolsUploadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := stateWrapper.authenticateUser(r); err != nil {
-		h.authError(w, h)
+	start := time.Now()
+	user, err := stateWrapper.authorizeUser(r, state.EnvironmentWriteAccess)
+	if err != nil {
+		stateWrapper.auditRequest(user, "Tools", r, start, err)
+		h.authorizeError(w, h, err)
 		return
 	}
 
@@ -83,6 +87,7 @@ func (h *toolsUploadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	case "POST":
 		// Add tools to storage.
 		agentTools, err := h.processPost(r, stateWrapper.state)
+		stateWrapper.auditRequest(user, "Tools", r, start, err)
 		if err != nil {
 			h.sendExistingError(w, http.StatusBadRequest, err)
 			return
@@ -23,6 +23,9 @@ func init() {
 
 // UserManager defines the methods on the usermanager API end point.
 type UserManager interface {
+	AddAPITokens(args params.AddAPITokens) (params.AddAPITokenResults, error)
+	APITokens(args params.Entities) (params.APITokensResults, error)
+	RevokeAPITokens(args params.APITokenIds) (params.ErrorResults, error)
 	AddUser(args params.AddUsers) (params.AddUserResults, error)
 	DisableUser(args params.Entities) (params.ErrorResults, error)
 	EnableUser(args params.Entities) (params.ErrorResults, error)
@@ -70,6 +73,19 @@ func (api *UserManagerAPI) permissionCheck(user names.UserTag) error {
 	return nil
 }
 
+// hasAdminAccess reports whether the user has admin access to the
+// environment.
+func (api *UserManagerAPI) hasAdminAccess(user names.UserTag) bool {
+	envUser, err := api.state.EnvironmentUser(user)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			logger.Debugf("error getting environment user: %v", err)
+		}
+		return false
+	}
+	return envUser.Access() == state.EnvironmentAdminAccess
+}
+
 // AddUser adds a user.
 func (api *UserManagerAPI) AddUser(args params.AddUsers) (params.AddUserResults, error) {
 	result := params.AddUserResults{
@@ -93,7 +109,12 @@ func (api *UserManagerAPI) AddUser(args params.AddUsers) (params.AddUserResults,
 		return result, errors.Trace(err)
 	}
 	for i, arg := range args.Users {
-		user, err := api.state.AddUser(arg.Username, arg.DisplayName, arg.Password, loggedInUser.Id())
+		var user *state.User
+		if arg.IdentityProvider != "" {
+			user, err = api.state.AddExternalUser(arg.Username, arg.DisplayName, arg.IdentityProvider, loggedInUser.Id())
+		} else {
+			user, err = api.state.AddUser(arg.Username, arg.DisplayName, arg.Password, loggedInUser.Id())
+		}
 		if err != nil {
 			err = errors.Annotate(err, "failed to create user")
 			result.Results[i].Error = common.ServerError(err)
@@ -179,14 +200,23 @@ func (api *UserManagerAPI) UserInfo(request params.UserInfoRequest) (params.User
 		} else {
 			lastLogin = &userLastLogin
 		}
+		var access string
+		envUser, err := api.state.EnvironmentUser(user.UserTag())
+		if err == nil {
+			access = string(envUser.Access())
+		} else if !errors.IsNotFound(err) {
+			logger.Debugf("error getting environment user: %v", err)
+		}
 		return params.UserInfoResult{
 			Result: &params.UserInfo{
-				Username:       user.Name(),
-				DisplayName:    user.DisplayName(),
-				CreatedBy:      user.CreatedBy(),
-				DateCreated:    user.DateCreated(),
-				LastConnection: lastLogin,
-				Disabled:       user.IsDisabled(),
+				Username:         user.Name(),
+				DisplayName:      user.DisplayName(),
+				CreatedBy:        user.CreatedBy(),
+				DateCreated:      user.DateCreated(),
+				LastConnection:   lastLogin,
+				Disabled:         user.IsDisabled(),
+				Access:           access,
+				IdentityProvider: user.IdentityProvider(),
 			},
 		}
 	}
@@ -250,7 +280,7 @@ func (api *UserManagerAPI) SetPassword(args params.EntityPasswords) (params.Erro
 		return result, common.ErrPerm
 	}
 	permErr := api.permissionCheck(loggedInUser)
-	adminUser := permErr == nil
+	adminUser := permErr == nil && api.hasAdminAccess(loggedInUser)
 	for i, arg := range args.Changes {
 		if err := api.setPassword(loggedInUser, arg, adminUser); err != nil {
 			result.Results[i].Error = common.ServerError(err)
@@ -259,6 +289,161 @@ func (api *UserManagerAPI) SetPassword(args params.EntityPasswords) (params.Erro
 	return result, nil
 }
 
+// canManageTokens returns an error unless the logged in user may
+// manage the API tokens of the given user: their own, or anyone's if
+// they are an administrator.
+func (api *UserManagerAPI) canManageTokens(loggedInUser, user names.UserTag) error {
+	if loggedInUser.Username() == user.Username() {
+		return nil
+	}
+	return api.permissionCheck(loggedInUser)
+}
+
+// AddAPITokens adds API tokens that let the logged in user log in to
+// the environment in place of their password.
+func (api *UserManagerAPI) AddAPITokens(args params.AddAPITokens) (params.AddAPITokenResults, error) {
+	result := params.AddAPITokenResults{
+		Results: make([]params.AddAPITokenResult, len(args.Tokens)),
+	}
+	if err := api.check.ChangeAllowed(); err != nil {
+		return result, errors.Trace(err)
+	}
+	if len(args.Tokens) == 0 {
+		return result, nil
+	}
+	loggedInUser, err := api.getLoggedInUser()
+	if err != nil {
+		return result, errors.Wrap(err, common.ErrPerm)
+	}
+	for i, arg := range args.Tokens {
+		token, credentials, err := api.addAPIToken(loggedInUser, arg)
+		if err != nil {
+			result.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		result.Results[i].Token = apiTokenInfo(token)
+		result.Results[i].Credentials = credentials
+	}
+	return result, nil
+}
+
+func (api *UserManagerAPI) addAPIToken(loggedInUser names.UserTag, arg params.AddAPIToken) (*state.APIToken, string, error) {
+	user := loggedInUser
+	if arg.User != "" {
+		var err error
+		if user, err = names.ParseUserTag(arg.User); err != nil {
+			return nil, "", errors.Trace(err)
+		}
+	}
+	// Tokens stand in for their users' passwords, so not even an
+	// administrator may add one for someone else.
+	if loggedInUser.Username() != user.Username() {
+		return nil, "", common.ErrPerm
+	}
+	token, credentials, err := api.state.AddAPIToken(state.APITokenParams{
+		User:     user,
+		Expires:  arg.Expires,
+		ReadOnly: arg.ReadOnly,
+		Facades:  arg.Facades,
+	})
+	if err != nil {
+		return nil, "", errors.Annotate(err, "failed to add API token")
+	}
+	return token, credentials, nil
+}
+
+// APITokens returns the API tokens of the given users, or of the
+// logged in user if none are given.
+func (api *UserManagerAPI) APITokens(args params.Entities) (params.APITokensResults, error) {
+	var results params.APITokensResults
+	loggedInUser, err := api.getLoggedInUser()
+	if err != nil {
+		return results, errors.Wrap(err, common.ErrPerm)
+	}
+	entities := args.Entities
+	if len(entities) == 0 {
+		entities = []params.Entity{{Tag: loggedInUser.String()}}
+	}
+	results.Results = make([]params.APITokensResult, len(entities))
+	for i, arg := range entities {
+		tokens, err := api.apiTokens(loggedInUser, arg.Tag)
+		if err != nil {
+			results.Results[i].Error = common.ServerError(err)
+			continue
+		}
+		results.Results[i].Result = tokens
+	}
+	return results, nil
+}
+
+func (api *UserManagerAPI) apiTokens(loggedInUser names.UserTag, tag string) ([]params.APIToken, error) {
+	user, err := names.ParseUserTag(tag)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err := api.canManageTokens(loggedInUser, user); err != nil {
+		return nil, errors.Trace(err)
+	}
+	tokens, err := api.state.APITokens(user)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	result := make([]params.APIToken, len(tokens))
+	for i, token := range tokens {
+		result[i] = *apiTokenInfo(token)
+	}
+	return result, nil
+}
+
+// RevokeAPITokens removes the API tokens with the given ids, so that
+// they are no longer accepted.
+func (api *UserManagerAPI) RevokeAPITokens(args params.APITokenIds) (params.ErrorResults, error) {
+	result := params.ErrorResults{
+		Results: make([]params.ErrorResult, len(args.Ids)),
+	}
+	if err := api.check.ChangeAllowed(); err != nil {
+		return result, errors.Trace(err)
+	}
+	if len(args.Ids) == 0 {
+		return result, nil
+	}
+	loggedInUser, err := api.getLoggedInUser()
+	if err != nil {
+		return result, errors.Wrap(err, common.ErrPerm)
+	}
+	for i, id := range args.Ids {
+		if err := api.revokeAPIToken(loggedInUser, id); err != nil {
+			result.Results[i].Error = common.ServerError(err)
+		}
+	}
+	return result, nil
+}
+
+func (api *UserManagerAPI) revokeAPIToken(loggedInUser names.UserTag, id string) error {
+	token, err := api.state.APIToken(id)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := api.canManageTokens(loggedInUser, token.User()); err != nil {
+		return errors.Trace(err)
+	}
+	if err := api.state.RevokeAPIToken(id); err != nil {
+		return errors.Annotate(err, "failed to revoke API token")
+	}
+	return nil
+}
+
+func apiTokenInfo(token *state.APIToken) *params.APIToken {
+	return &params.APIToken{
+		Id:       token.ID(),
+		User:     token.User().String(),
+		Created:  token.Created(),
+		Expires:  token.Expires(),
+		ReadOnly: token.ReadOnly(),
+		Facades:  token.Facades(),
+	}
+}
+
 func (api *UserManagerAPI) getLoggedInUser() (names.UserTag, error) {
 	switch tag := api.authorizer.GetAuthTag().(type) {
 	case names.UserTag:
@@ -79,6 +79,33 @@ func (s *userManagerSuite) TestAddUser(c *gc.C) {
 	c.Assert(user.DisplayName(), gc.Equals, "Foo Bar")
 }
 
+func (s *userManagerSuite) TestAddExternalUser(c *gc.C) {
+	args := params.AddUsers{
+		Users: []params.AddUser{{
+			Username:         "foobar",
+			DisplayName:      "Foo Bar",
+			IdentityProvider: "ldap",
+		}}}
+
+	result, err := s.usermanager.AddUser(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(result.Results, gc.HasLen, 1)
+	foobarTag := names.NewLocalUserTag("foobar")
+	c.Assert(result.Results[0], gc.DeepEquals, params.AddUserResult{
+		Tag: foobarTag.String()})
+	user, err := s.State.User(foobarTag)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.DisplayName(), gc.Equals, "Foo Bar")
+	c.Assert(user.IdentityProvider(), gc.Equals, "ldap")
+
+	infos, err := s.usermanager.UserInfo(params.UserInfoRequest{
+		Entities: []params.Entity{{Tag: foobarTag.String()}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(infos.Results, gc.HasLen, 1)
+	c.Assert(infos.Results[0].Result.IdentityProvider, gc.Equals, "ldap")
+}
+
 func (s *userManagerSuite) TestBlockAddUser(c *gc.C) {
 	args := params.AddUsers{
 		Users: []params.AddUser{{
@@ -350,6 +377,7 @@ func (s *userManagerSuite) TestUserInfo(c *gc.C) {
 			r.info.DateCreated = r.user.DateCreated()
 			r.info.LastConnection = lastLoginPointer(c, r.user)
 			r.info.CreatedBy = s.adminName
+			r.info.Access = "admin"
 		}
 		expected.Results = append(expected.Results, params.UserInfoResult{Result: r.info, Error: r.err})
 	}
@@ -393,6 +421,7 @@ func (s *userManagerSuite) TestUserInfoAll(c *gc.C) {
 		},
 	} {
 		r.info.CreatedBy = s.adminName
+		r.info.Access = "admin"
 		r.info.DateCreated = r.user.DateCreated()
 		r.info.LastConnection = lastLoginPointer(c, r.user)
 		expected.Results = append(expected.Results, params.UserInfoResult{Result: r.info})
@@ -406,6 +435,25 @@ func (s *userManagerSuite) TestUserInfoAll(c *gc.C) {
 	c.Assert(results, jc.DeepEquals, expected)
 }
 
+func (s *userManagerSuite) TestUserInfoAccess(c *gc.C) {
+	userFoo := s.Factory.MakeUser(c, &factory.UserParams{Name: "foobar", NoEnvUser: true})
+	s.Factory.MakeEnvUser(c, &factory.EnvUserParams{User: "foobar", Access: state.EnvironmentReadAccess})
+	userBar := s.Factory.MakeUser(c, &factory.UserParams{Name: "barfoo", NoEnvUser: true})
+
+	args := params.UserInfoRequest{
+		Entities: []params.Entity{{
+			Tag: userFoo.Tag().String(),
+		}, {
+			Tag: userBar.Tag().String(),
+		}}}
+	results, err := s.usermanager.UserInfo(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 2)
+	c.Assert(results.Results[0].Result.Access, gc.Equals, "read")
+	// Users who do not share the environment have no access to it.
+	c.Assert(results.Results[1].Result.Access, gc.Equals, "")
+}
+
 func lastLoginPointer(c *gc.C, user *state.User) *time.Time {
 	lastLogin, err := user.LastLogin()
 	if err != nil {
@@ -504,3 +552,179 @@ func (s *userManagerSuite) TestSetPasswordForOther(c *gc.C) {
 
 	c.Assert(barb.PasswordValid("new-password"), jc.IsFalse)
 }
+
+func (s *userManagerSuite) TestSetPasswordForOtherWithoutAdminAccess(c *gc.C) {
+	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex"})
+	envUser, err := s.State.EnvironmentUser(s.AdminUserTag(c))
+	c.Assert(err, jc.ErrorIsNil)
+	err = envUser.SetAccess(state.EnvironmentWriteAccess)
+	c.Assert(err, jc.ErrorIsNil)
+
+	args := params.EntityPasswords{
+		Changes: []params.EntityPassword{{
+			Tag:      alex.Tag().String(),
+			Password: "new-password",
+		}}}
+	results, err := s.usermanager.SetPassword(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.ErrorMatches, "permission denied")
+
+	err = alex.Refresh()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(alex.PasswordValid("new-password"), jc.IsFalse)
+}
+
+func (s *userManagerSuite) TestAddAPITokens(c *gc.C) {
+	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex"})
+	usermanager, err := usermanager.NewUserManagerAPI(
+		s.State, nil, apiservertesting.FakeAuthorizer{Tag: alex.Tag()})
+	c.Assert(err, jc.ErrorIsNil)
+
+	expires := time.Now().Add(time.Hour).Round(time.Second).UTC()
+	results, err := usermanager.AddAPITokens(params.AddAPITokens{
+		Tokens: []params.AddAPIToken{{
+			Expires:  expires,
+			ReadOnly: true,
+			Facades:  []string{"Client"},
+		}}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	result := results.Results[0]
+	c.Assert(result.Error, gc.IsNil)
+	c.Assert(result.Token.User, gc.Equals, "user-alex@local")
+	c.Assert(result.Token.Expires, gc.Equals, expires)
+	c.Assert(result.Token.ReadOnly, jc.IsTrue)
+	c.Assert(result.Token.Facades, jc.DeepEquals, []string{"Client"})
+
+	id, secret, ok := state.ParseAPITokenCredentials(result.Credentials)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(id, gc.Equals, result.Token.Id)
+	token, err := s.State.APIToken(id)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(token.SecretValid(secret), jc.IsTrue)
+}
+
+func (s *userManagerSuite) TestAddAPITokensForOther(c *gc.C) {
+	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex"})
+	barb := s.Factory.MakeUser(c, &factory.UserParams{Name: "barb"})
+	usermanager, err := usermanager.NewUserManagerAPI(
+		s.State, nil, apiservertesting.FakeAuthorizer{Tag: alex.Tag()})
+	c.Assert(err, jc.ErrorIsNil)
+
+	args := params.AddAPITokens{
+		Tokens: []params.AddAPIToken{{
+			User:    barb.Tag().String(),
+			Expires: time.Now().Add(time.Hour),
+		}}}
+	results, err := usermanager.AddAPITokens(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.DeepEquals, &params.Error{
+		Message: "permission denied",
+		Code:    params.CodeUnauthorized,
+	})
+
+	// Not even the administrator may add tokens for others.
+	results, err = s.usermanager.AddAPITokens(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.DeepEquals, &params.Error{
+		Message: "permission denied",
+		Code:    params.CodeUnauthorized,
+	})
+	tokens, err := s.State.APITokens(barb.UserTag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(tokens, gc.HasLen, 0)
+
+	// Users may name themselves.
+	args.Tokens[0].User = alex.Tag().String()
+	results, err = usermanager.AddAPITokens(args)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	c.Assert(results.Results[0].Token.User, gc.Equals, "user-alex@local")
+}
+
+func (s *userManagerSuite) TestAddAPITokensExpired(c *gc.C) {
+	results, err := s.usermanager.AddAPITokens(params.AddAPITokens{
+		Tokens: []params.AddAPIToken{{
+			Expires: time.Now().Add(-time.Hour),
+		}}})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.ErrorMatches, "failed to add API token: expiry time .* in the past not valid")
+}
+
+func (s *userManagerSuite) TestBlockAddAPITokens(c *gc.C) {
+	s.BlockAllChanges(c, "TestBlockAddAPITokens")
+	_, err := s.usermanager.AddAPITokens(params.AddAPITokens{
+		Tokens: []params.AddAPIToken{{
+			Expires: time.Now().Add(time.Hour),
+		}}})
+	s.AssertBlocked(c, err, "TestBlockAddAPITokens")
+}
+
+func (s *userManagerSuite) addAPIToken(c *gc.C, user names.UserTag) *state.APIToken {
+	token, _, err := s.State.AddAPIToken(state.APITokenParams{
+		User:    user,
+		Expires: time.Now().Add(time.Hour),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return token
+}
+
+func (s *userManagerSuite) TestAPITokens(c *gc.C) {
+	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex"})
+	barb := s.Factory.MakeUser(c, &factory.UserParams{Name: "barb"})
+	alexToken := s.addAPIToken(c, alex.UserTag())
+	s.addAPIToken(c, barb.UserTag())
+	usermanager, err := usermanager.NewUserManagerAPI(
+		s.State, nil, apiservertesting.FakeAuthorizer{Tag: alex.Tag()})
+	c.Assert(err, jc.ErrorIsNil)
+
+	results, err := usermanager.APITokens(params.Entities{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	c.Assert(results.Results[0].Result, gc.HasLen, 1)
+	c.Assert(results.Results[0].Result[0].Id, gc.Equals, alexToken.ID())
+
+	results, err = usermanager.APITokens(params.Entities{
+		Entities: []params.Entity{{Tag: barb.Tag().String()}},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 1)
+	c.Assert(results.Results[0].Error, gc.ErrorMatches, "permission denied")
+}
+
+func (s *userManagerSuite) TestRevokeAPITokens(c *gc.C) {
+	alex := s.Factory.MakeUser(c, &factory.UserParams{Name: "alex"})
+	barb := s.Factory.MakeUser(c, &factory.UserParams{Name: "barb"})
+	alexToken := s.addAPIToken(c, alex.UserTag())
+	barbToken := s.addAPIToken(c, barb.UserTag())
+	usermanager, err := usermanager.NewUserManagerAPI(
+		s.State, nil, apiservertesting.FakeAuthorizer{Tag: alex.Tag()})
+	c.Assert(err, jc.ErrorIsNil)
+
+	results, err := usermanager.RevokeAPITokens(params.APITokenIds{
+		Ids: []string{alexToken.ID(), barbToken.ID(), "missing"},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results, gc.HasLen, 3)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+	c.Assert(results.Results[1].Error, gc.ErrorMatches, "permission denied")
+	c.Assert(results.Results[2].Error, gc.ErrorMatches, `API token "missing" not found`)
+
+	_, err = s.State.APIToken(alexToken.ID())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	_, err = s.State.APIToken(barbToken.ID())
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The administrator may revoke anyone's tokens.
+	results, err = s.usermanager.RevokeAPITokens(params.APITokenIds{
+		Ids: []string{barbToken.ID()},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results.Results[0].Error, gc.IsNil)
+}
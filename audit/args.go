@@ -0,0 +1,235 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"github.com/juju/utils/set"
+)
+
+// Redacted replaces the values of secrets in audited arguments.
+const Redacted = "<redacted>"
+
+// secretKeyWords are the words that, if found in the name of a field,
+// mark its value as a secret.
+var secretKeyWords = []string{
+	"access-key",
+	"api-key",
+	"credential",
+	"passphrase",
+	"password",
+	"private",
+	"secret",
+	"ssh-key",
+	"token",
+}
+
+// Redact returns the arguments of an API call as JSON, with the values
+// of fields whose names suggest they hold secrets, such as passwords,
+// replaced by Redacted. The values of the fields named in secretFields
+// are redacted too, as are the values of the fields named in
+// settingsFields, which hold settings, such as those of charms, that
+// may be secrets whatever they are called; only the names of the
+// settings held in a map are kept. Field names are matched without
+// regard to case.
+func Redact(args interface{}, secretFields, settingsFields set.Strings) (string, error) {
+	value, err := toJSONValue(args)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	r := redactor{
+		secretFields:   lowerStrings(secretFields),
+		settingsFields: lowerStrings(settingsFields),
+	}
+	data, err := json.Marshal(r.redact(value))
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	return unescapeHTML(string(data)), nil
+}
+
+// htmlEscapes maps the escapes json.Marshal writes for the characters
+// HTML treats specially, such as those in Redacted, to the characters,
+// which need no escaping in JSON.
+var htmlEscapes = map[string]string{
+	`\u003c`: "<",
+	`\u003e`: ">",
+	`\u0026`: "&",
+}
+
+// unescapeHTML returns the JSON without the escapes json.Marshal
+// writes for the characters HTML treats specially.
+func unescapeHTML(data string) string {
+	var buf bytes.Buffer
+	for i := 0; i < len(data); i++ {
+		if data[i] != '\\' || i+1 == len(data) {
+			buf.WriteByte(data[i])
+			continue
+		}
+		// Copy the whole escape, so that an escaped backslash is
+		// not taken to start another escape.
+		if data[i+1] == 'u' && i+6 <= len(data) {
+			if char, ok := htmlEscapes[data[i:i+6]]; ok {
+				buf.WriteString(char)
+				i += 5
+				continue
+			}
+		}
+		buf.WriteString(data[i : i+2])
+		i++
+	}
+	return buf.String()
+}
+
+type redactor struct {
+	secretFields   set.Strings
+	settingsFields set.Strings
+}
+
+func (r redactor) redact(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, v := range value {
+			switch {
+			case r.isSecretKey(key):
+				value[key] = Redacted
+			case r.settingsFields.Contains(strings.ToLower(key)):
+				value[key] = redactSettings(v)
+			default:
+				value[key] = r.redact(v)
+			}
+		}
+	case []interface{}:
+		for i, v := range value {
+			value[i] = r.redact(v)
+		}
+	}
+	return value
+}
+
+// redactSettings returns the settings with the value of each setting
+// held in a map redacted, or redacted as a whole if they are not held
+// in a map, as when they are given as YAML.
+func redactSettings(value interface{}) interface{} {
+	switch value := value.(type) {
+	case nil:
+		return nil
+	case map[string]interface{}:
+		for key, v := range value {
+			if v != nil {
+				value[key] = Redacted
+			}
+		}
+		return value
+	case string:
+		if value == "" {
+			return value
+		}
+	}
+	return Redacted
+}
+
+func (r redactor) isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	if r.secretFields.Contains(key) {
+		return true
+	}
+	for _, word := range secretKeyWords {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
+
+func lowerStrings(values set.Strings) set.Strings {
+	result := make(set.Strings)
+	for _, value := range values.Values() {
+		result.Add(strings.ToLower(value))
+	}
+	return result
+}
+
+// entityKinds maps the names of the fields that hold bare entity names,
+// rather than tags, in API call arguments to the kinds of entity they
+// name.
+var entityKinds = map[string]string{
+	"machineid":    names.MachineTagKind,
+	"machinename":  names.MachineTagKind,
+	"machinenames": names.MachineTagKind,
+	"servicename":  names.ServiceTagKind,
+	"servicenames": names.ServiceTagKind,
+	"unitname":     names.UnitTagKind,
+	"unitnames":    names.UnitTagKind,
+}
+
+// Entities returns the tags of the entities the arguments of an API
+// call refer to, sorted. Both tags and the names held in fields such
+// as ServiceName are recognised.
+func Entities(args interface{}) ([]string, error) {
+	value, err := toJSONValue(args)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	entities := make(set.Strings)
+	addEntities(entities, "", value)
+	result := entities.Values()
+	sort.Strings(result)
+	return result, nil
+}
+
+func addEntities(entities set.Strings, kind string, value interface{}) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, v := range value {
+			addEntities(entities, entityKinds[strings.ToLower(key)], v)
+		}
+	case []interface{}:
+		for _, v := range value {
+			addEntities(entities, kind, v)
+		}
+	case string:
+		if tag, ok := entityTag(kind, value); ok {
+			entities.Add(tag.String())
+		}
+	}
+}
+
+// entityTag returns the tag of the named entity of the given kind, or
+// the tag the name is, if the kind is not known.
+func entityTag(kind, name string) (names.Tag, bool) {
+	switch {
+	case kind == names.MachineTagKind && names.IsValidMachine(name):
+		return names.NewMachineTag(name), true
+	case kind == names.ServiceTagKind && names.IsValidService(name):
+		return names.NewServiceTag(name), true
+	case kind == names.UnitTagKind && names.IsValidUnit(name):
+		return names.NewUnitTag(name), true
+	}
+	tag, err := names.ParseTag(name)
+	if err != nil {
+		return nil, false
+	}
+	return tag, true
+}
+
+// toJSONValue returns the arguments as the generic value decoding
+// their JSON encoding results in.
+func toJSONValue(args interface{}) (interface{}, error) {
+	data, err := json.Marshal(args)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot marshal arguments")
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, errors.Annotate(err, "cannot unmarshal arguments")
+	}
+	return value, nil
+}
@@ -0,0 +1,160 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package audit
+
+import (
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/set"
+	gc "gopkg.in/check.v1"
+)
+
+type argsSuite struct{}
+
+var _ = gc.Suite(&argsSuite{})
+
+type entity struct {
+	Tag string
+}
+
+type entityPassword struct {
+	Tag      string
+	Password string
+}
+
+func (*argsSuite) TestRedact(c *gc.C) {
+	for i, test := range []struct {
+		args     interface{}
+		redacted string
+	}{{
+		args:     nil,
+		redacted: `null`,
+	}, {
+		args:     struct{ ServiceName string }{"wordpress"},
+		redacted: `{"ServiceName":"wordpress"}`,
+	}, {
+		args: struct{ Changes []entityPassword }{
+			[]entityPassword{{"user-bob", "sekrit"}},
+		},
+		redacted: `{"Changes":[{"Password":"<redacted>","Tag":"user-bob"}]}`,
+	}, {
+		args: map[string]interface{}{
+			"Config": map[string]interface{}{
+				"admin-secret":   "sekrit",
+				"private-key":    "-----BEGIN",
+				"authorized-key": "ssh-rsa",
+				"api-token":      "abc",
+			},
+		},
+		redacted: `{"Config":{"admin-secret":"<redacted>","api-token":"<redacted>","authorized-key":"ssh-rsa","private-key":"<redacted>"}}`,
+	}, {
+		args: map[string]interface{}{
+			"Credentials": map[string]string{"password": "sekrit"},
+		},
+		redacted: `{"Credentials":"<redacted>"}`,
+	}, {
+		args: map[string]interface{}{
+			"Config": map[string]interface{}{
+				"access-key": "AKIA",
+				"ssh-key":    "-----BEGIN",
+			},
+		},
+		redacted: `{"Config":{"access-key":"<redacted>","ssh-key":"<redacted>"}}`,
+	}, {
+		args:     struct{ Note string }{`<b> & \u003c`},
+		redacted: `{"Note":"<b> & \\u003c"}`,
+	}} {
+		c.Logf("test %d", i)
+		redacted, err := Redact(test.args, nil, nil)
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(redacted, gc.Equals, test.redacted)
+	}
+}
+
+func (*argsSuite) TestRedactSecretFields(c *gc.C) {
+	args := map[string]interface{}{
+		"Config": map[string]interface{}{
+			"region":   "us-east-1",
+			"sdc-user": "bob",
+			"Manta":    "abc",
+		},
+	}
+	redacted, err := Redact(args, set.NewStrings("sdc-user", "manta"), nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(redacted, gc.Equals, `{"Config":{"Manta":"<redacted>","region":"us-east-1","sdc-user":"<redacted>"}}`)
+}
+
+func (*argsSuite) TestRedactSettingsFields(c *gc.C) {
+	for i, test := range []struct {
+		args     interface{}
+		redacted string
+	}{{
+		args: map[string]interface{}{
+			"ServiceName": "wordpress",
+			"Config":      map[string]string{"blog-title": "mine", "db-pass": "sekrit"},
+			"ConfigYAML":  "wordpress:\n  db-pass: sekrit\n",
+		},
+		redacted: `{"Config":{"blog-title":"<redacted>","db-pass":"<redacted>"},"ConfigYAML":"<redacted>","ServiceName":"wordpress"}`,
+	}, {
+		args: map[string]interface{}{
+			"Services": []interface{}{
+				map[string]interface{}{"Config": nil, "ConfigYAML": ""},
+			},
+		},
+		redacted: `{"Services":[{"Config":null,"ConfigYAML":""}]}`,
+	}} {
+		c.Logf("test %d", i)
+		redacted, err := Redact(test.args, nil, set.NewStrings("config", "ConfigYAML"))
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(redacted, gc.Equals, test.redacted)
+	}
+}
+
+func (*argsSuite) TestRedactError(c *gc.C) {
+	_, err := Redact(make(chan int), nil, nil)
+	c.Assert(err, gc.ErrorMatches, "cannot marshal arguments: .*")
+}
+
+func (*argsSuite) TestEntities(c *gc.C) {
+	for i, test := range []struct {
+		args     interface{}
+		entities []string
+	}{{
+		args: nil,
+	}, {
+		args:     struct{ ServiceName string }{"wordpress"},
+		entities: []string{"service-wordpress"},
+	}, {
+		args: struct{ Entities []entity }{
+			[]entity{{"unit-wordpress-0"}, {"machine-1"}, {"unit-wordpress-0"}},
+		},
+		entities: []string{"machine-1", "unit-wordpress-0"},
+	}, {
+		args: struct {
+			MachineNames []string
+			Force        bool
+		}{[]string{"0", "1/lxc/0"}, true},
+		entities: []string{"machine-0", "machine-1-lxc-0"},
+	}, {
+		args: struct {
+			UnitNames []string
+		}{[]string{"mysql/0", "not a unit"}},
+		entities: []string{"unit-mysql-0"},
+	}, {
+		args: struct {
+			ServiceName string
+			CharmURL    string
+			ConfigYAML  string
+		}{"wordpress", "cs:trusty/wordpress-1", "wordpress: {}"},
+		entities: []string{"service-wordpress"},
+	}} {
+		c.Logf("test %d", i)
+		entities, err := Entities(test.args)
+		c.Check(err, jc.ErrorIsNil)
+		if len(test.entities) == 0 {
+			c.Check(entities, gc.HasLen, 0)
+		} else {
+			c.Check(entities, jc.DeepEquals, test.entities)
+		}
+	}
+}
@@ -6,17 +6,21 @@ package backups
 import (
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"strings"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
 	"github.com/juju/utils/featureflag"
+	"gopkg.in/mgo.v2/bson"
 
 	"github.com/juju/juju/api/backups"
 	apiserverbackups "github.com/juju/juju/apiserver/backups"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
 	"github.com/juju/juju/feature"
+	"github.com/juju/juju/mongo"
 	statebackups "github.com/juju/juju/state/backups"
 )
 
@@ -62,6 +66,9 @@ func NewCommand() cmd.Command {
 	backupsCmd.Register(envcmd.Wrap(&UploadCommand{}))
 	backupsCmd.Register(envcmd.Wrap(&RemoveCommand{}))
 	backupsCmd.Register(envcmd.Wrap(&RestoreCommand{}))
+	backupsCmd.Register(envcmd.Wrap(&ScheduleCommand{}))
+	backupsCmd.Register(envcmd.Wrap(&SetScheduleCommand{}))
+	backupsCmd.Register(envcmd.Wrap(&VerifyCommand{}))
 	return &backupsCmd
 }
 
@@ -70,7 +77,10 @@ func NewCommand() cmd.Command {
 type APIClient interface {
 	io.Closer
 	// Create sends an RPC request to create a new backup.
-	Create(notes string) (*params.BackupsMetadataResult, error)
+	Create(notes string, key *params.BackupsKey) (*params.BackupsMetadataResult, error)
+	// CreateIncremental sends an RPC request to create a new
+	// incremental backup.
+	CreateIncremental(notes string, key *params.BackupsKey) (*params.BackupsMetadataResult, error)
 	// Info gets the backup's metadata.
 	Info(id string) (*params.BackupsMetadataResult, error)
 	// List gets all stored metadata.
@@ -85,6 +95,10 @@ type APIClient interface {
 	Restore(string, backups.ClientConnection) error
 	// Restore will restore a backup file into the state server.
 	RestoreReader(io.Reader, *params.BackupsMetadataResult, backups.ClientConnection) error
+	// Schedule gets the schedule of automatic backups.
+	Schedule() (*params.BackupsSchedule, error)
+	// SetSchedule replaces the schedule of automatic backups.
+	SetSchedule(params.BackupsSchedule) error
 }
 
 // CommandBase is the base type for backups sub-commands.
@@ -112,10 +126,23 @@ func (c *CommandBase) dumpMetadata(ctx *cmd.Context, result *params.BackupsMetad
 	fmt.Fprintf(ctx.Stdout, "checksum format: %q\n", result.ChecksumFormat)
 	fmt.Fprintf(ctx.Stdout, "size (B):        %d\n", result.Size)
 	fmt.Fprintf(ctx.Stdout, "stored:          %v\n", result.Stored)
+	if result.Location != "" {
+		fmt.Fprintf(ctx.Stdout, "location:        %q\n", result.Location)
+	}
 
 	fmt.Fprintf(ctx.Stdout, "started:         %v\n", result.Started)
 	fmt.Fprintf(ctx.Stdout, "finished:        %v\n", result.Finished)
 	fmt.Fprintf(ctx.Stdout, "notes:           %q\n", result.Notes)
+	if result.KeyFingerprint != "" {
+		fmt.Fprintf(ctx.Stdout, "key fingerprint: %q\n", result.KeyFingerprint)
+	}
+	if result.Base != "" {
+		fmt.Fprintf(ctx.Stdout, "base backup ID:  %q\n", result.Base)
+	}
+	if result.OplogEnd != 0 {
+		end := mongo.MongoTimestampTime(bson.MongoTimestamp(result.OplogEnd))
+		fmt.Fprintf(ctx.Stdout, "changes until:   %v\n", end)
+	}
 
 	fmt.Fprintf(ctx.Stdout, "environment ID:  %q\n", result.Environment)
 	fmt.Fprintf(ctx.Stdout, "machine ID:      %q\n", result.Machine)
@@ -123,7 +150,73 @@ func (c *CommandBase) dumpMetadata(ctx *cmd.Context, result *params.BackupsMetad
 	fmt.Fprintf(ctx.Stdout, "juju version:    %v\n", result.Version)
 }
 
-func getArchive(filename string) (rc io.ReadCloser, metaResult *params.BackupsMetadataResult, err error) {
+// readKey returns the key read from the given files, or nil if neither
+// is given. The key file holds an armored OpenPGP key, public to
+// encrypt an archive or private to decrypt one. Private keys are only
+// ever used on the client, and are never sent to the server.
+func readKey(ctx *cmd.Context, passphraseFile, keyFile string, private bool) (*statebackups.EncryptionKey, error) {
+	if passphraseFile == "" && keyFile == "" {
+		return nil, nil
+	}
+	var key statebackups.EncryptionKey
+	if passphraseFile != "" {
+		data, err := ioutil.ReadFile(ctx.AbsPath(passphraseFile))
+		if err != nil {
+			return nil, errors.Annotate(err, "cannot read passphrase")
+		}
+		key.Passphrase = strings.TrimRight(string(data), "\r\n")
+		if key.Passphrase == "" {
+			return nil, errors.Errorf("passphrase file %q is empty", passphraseFile)
+		}
+	}
+	if keyFile != "" {
+		data, err := ioutil.ReadFile(ctx.AbsPath(keyFile))
+		if err != nil {
+			return nil, errors.Annotate(err, "cannot read key")
+		}
+		if private {
+			key.PrivateKey = string(data)
+		} else {
+			key.PublicKey = string(data)
+		}
+	}
+	return &key, nil
+}
+
+// keyParams returns the params holding the key to encrypt new backup
+// archives with, or nil if there is no key.
+func keyParams(key *statebackups.EncryptionKey) *params.BackupsKey {
+	if key == nil {
+		return nil
+	}
+	return &params.BackupsKey{
+		Passphrase: key.Passphrase,
+		PublicKey:  key.PublicKey,
+	}
+}
+
+// writeTempArchive writes the plain archive to a new temporary file,
+// whose name is returned. Nothing is left behind if the archive fails
+// its integrity check.
+func writeTempArchive(plain io.Reader) (string, error) {
+	file, err := ioutil.TempFile("", "juju-backup-")
+	if err != nil {
+		return "", errors.Annotate(err, "cannot create decrypted archive file")
+	}
+	_, err = io.Copy(file, plain)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", errors.Annotate(err, "cannot write decrypted archive file")
+	}
+	return file.Name(), nil
+}
+
+// getArchive opens the backup archive file and extracts its metadata.
+// The key decrypts an encrypted archive, which is returned as it is.
+func getArchive(filename string, key *statebackups.EncryptionKey) (rc io.ReadCloser, metaResult *params.BackupsMetadataResult, err error) {
 	defer func() {
 		if err != nil && rc != nil {
 			rc.Close()
@@ -136,7 +229,13 @@ func getArchive(filename string) (rc io.ReadCloser, metaResult *params.BackupsMe
 	}
 
 	// Extract the metadata.
-	ad, err := statebackups.NewArchiveDataReader(archive)
+	var plain io.Reader = archive
+	if key != nil {
+		if plain, err = key.Decrypt(archive); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+	}
+	ad, err := statebackups.NewArchiveDataReader(plain)
 	if err != nil {
 		return nil, nil, errors.Trace(err)
 	}
@@ -173,6 +272,11 @@ func getArchive(filename string) (rc io.ReadCloser, metaResult *params.BackupsMe
 	if meta.Finished == nil || meta.Finished.IsZero() {
 		meta.Finished = fileMeta.Finished
 	}
+	if key != nil && meta.KeyFingerprint == "" {
+		if meta.KeyFingerprint, err = key.Fingerprint(); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+	}
 	_, err = archive.Seek(0, os.SEEK_SET)
 	if err != nil {
 		return nil, nil, errors.Trace(err)
@@ -20,7 +20,10 @@ var expectedSubCommmandNames = []string{
 	"list",
 	"remove",
 	"restore",
+	"schedule",
+	"set-schedule",
 	"upload",
+	"verify",
 }
 
 type backupsSuite struct {
@@ -13,6 +13,7 @@ import (
 	"github.com/juju/errors"
 	"launchpad.net/gnuflag"
 
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/state/backups"
 )
 
@@ -33,13 +34,29 @@ that case, the backup archive will be stored in the current working
 directory with a name matching juju-backup-<date>-<time>.tar.gz.
 
 WARNING: Remotely stored backups will be lost when the environment is
-destroyed.  Furthermore, the remotely backup is not guaranteed to be
-available.
+destroyed, unless the "backups-storage" environment setting stores them
+off the state server.  Furthermore, the remotely backup is not
+guaranteed to be available.
 
 Therefore, you should use the --download or --filename options, or use
 "juju backups download", to get a local copy of the backup archive.
 This local copy can then be used to restore an environment even if that
 environment was already destroyed or is otherwise unavailable.
+
+The backup archive holds secrets of the environment.  It may be
+encrypted with a passphrase, read from the file given with
+--passphrase-file, or with an OpenPGP public key, read in armored form
+from the file given with --public-key-file.  The archive is encrypted
+before it is stored, and downloaded encrypted.  The same passphrase, or
+the matching private key, is then needed to download it decrypted and
+to restore it.
+
+With --incremental, the backup only holds the changes to the database
+since the last backup built on the latest full backup.  It is much
+smaller and quicker to create than a full backup, but restoring it
+needs the full backup and each incremental backup in between.
+Incremental backups cannot be encrypted, and are only built on full
+backups that are not encrypted.
 `
 
 // CreateCommand is the sub-command for creating a new backup.
@@ -53,6 +70,13 @@ type CreateCommand struct {
 	Filename string
 	// Notes is the custom message to associated with the new backup.
 	Notes string
+	// PassphraseFile holds the passphrase to encrypt the backup with.
+	PassphraseFile string
+	// PublicKeyFile holds the public key to encrypt the backup with.
+	PublicKeyFile string
+	// Incremental means only the changes since the last backup are
+	// backed up.
+	Incremental bool
 }
 
 // Info implements Command.Info.
@@ -70,6 +94,9 @@ func (c *CreateCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.BoolVar(&c.Quiet, "quiet", false, "do not print the metadata")
 	f.BoolVar(&c.NoDownload, "no-download", false, "do not download the archive")
 	f.StringVar(&c.Filename, "filename", notset, "download to this file")
+	f.StringVar(&c.PassphraseFile, "passphrase-file", "", "encrypt the archive with the passphrase in this file")
+	f.StringVar(&c.PublicKeyFile, "public-key-file", "", "encrypt the archive with the OpenPGP public key in this file")
+	f.BoolVar(&c.Incremental, "incremental", false, "only back up the changes since the last backup")
 }
 
 // Init implements Command.Init.
@@ -86,19 +113,35 @@ func (c *CreateCommand) Init(args []string) error {
 	if c.Filename == "" {
 		return errors.Errorf("missing filename")
 	}
+	if c.PassphraseFile != "" && c.PublicKeyFile != "" {
+		return errors.Errorf("cannot mix --passphrase-file and --public-key-file")
+	}
+	if c.Incremental && (c.PassphraseFile != "" || c.PublicKeyFile != "") {
+		return errors.Errorf("cannot encrypt incremental backups")
+	}
 
 	return nil
 }
 
 // Run implements Command.Run.
 func (c *CreateCommand) Run(ctx *cmd.Context) error {
+	key, err := readKey(ctx, c.PassphraseFile, c.PublicKeyFile, false)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
 	client, err := c.NewAPIClient()
 	if err != nil {
 		return errors.Trace(err)
 	}
 	defer client.Close()
 
-	result, err := client.Create(c.Notes)
+	var result *params.BackupsMetadataResult
+	if c.Incremental {
+		result, err = client.CreateIncremental(c.Notes, keyParams(key))
+	} else {
+		result, err = client.Create(c.Notes, keyParams(key))
+	}
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -5,6 +5,8 @@ package backups_test
 
 import (
 	"bytes"
+	"io/ioutil"
+	"path/filepath"
 	"strings"
 
 	"github.com/juju/cmd"
@@ -13,6 +15,7 @@ import (
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/juju/backups"
 	"github.com/juju/juju/testing"
 )
@@ -154,6 +157,68 @@ func (s *createSuite) TestFilenameAndNoDownload(c *gc.C) {
 	c.Check(err, gc.ErrorMatches, "cannot mix --no-download and --filename")
 }
 
+func (s *createSuite) TestPassphraseFile(c *gc.C) {
+	client := s.setSuccess()
+	filename := filepath.Join(c.MkDir(), "passphrase")
+	err := ioutil.WriteFile(filename, []byte("secret\n"), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = testing.RunCommand(c, s.command, "create", "--no-download", "--passphrase-file", filename)
+	c.Assert(err, jc.ErrorIsNil)
+
+	client.Check(c, "", "", "Create")
+	c.Check(client.key, jc.DeepEquals, &params.BackupsKey{Passphrase: "secret"})
+}
+
+func (s *createSuite) TestPublicKeyFile(c *gc.C) {
+	client := s.setSuccess()
+	filename := filepath.Join(c.MkDir(), "key.asc")
+	err := ioutil.WriteFile(filename, []byte("<public key>"), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = testing.RunCommand(c, s.command, "create", "--no-download", "--public-key-file", filename)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(client.key, jc.DeepEquals, &params.BackupsKey{PublicKey: "<public key>"})
+}
+
+func (s *createSuite) TestEmptyPassphraseFile(c *gc.C) {
+	s.setSuccess()
+	filename := filepath.Join(c.MkDir(), "passphrase")
+	err := ioutil.WriteFile(filename, []byte("\n"), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = testing.RunCommand(c, s.command, "create", "--passphrase-file", filename)
+
+	c.Check(err, gc.ErrorMatches, `passphrase file ".*" is empty`)
+}
+
+func (s *createSuite) TestPassphraseAndPublicKey(c *gc.C) {
+	s.setSuccess()
+	_, err := testing.RunCommand(c, s.command, "create", "--passphrase-file", "a", "--public-key-file", "b")
+
+	c.Check(err, gc.ErrorMatches, "cannot mix --passphrase-file and --public-key-file")
+}
+
+func (s *createSuite) TestIncrementalEncrypted(c *gc.C) {
+	s.setSuccess()
+	_, err := testing.RunCommand(c, s.command, "create", "--incremental", "--passphrase-file", "a")
+
+	c.Check(err, gc.ErrorMatches, "cannot encrypt incremental backups")
+}
+
+func (s *createSuite) TestIncremental(c *gc.C) {
+	s.metaresult.Base = "eggs"
+	s.metaresult.OplogEnd = 6163845091342417923
+	client := s.setSuccess()
+	ctx, err := testing.RunCommand(c, s.command, "create", "--no-download", "--incremental")
+	c.Assert(err, jc.ErrorIsNil)
+
+	client.Check(c, "", "", "CreateIncremental")
+	out := strings.Replace(MetaResultString, "environment ID:", ""+
+		"base backup ID:  \"eggs\"\n"+
+		"changes until:   2015-06-24 07:47:00 +0000 UTC\n"+
+		"environment ID:", 1)
+	c.Check(testing.Stdout(ctx), gc.Equals, out+s.metaresult.ID+"\n")
+}
+
 func (s *createSuite) TestError(c *gc.C) {
 	s.setFailure("failed!")
 	ctx := cmdtesting.Context(c)
@@ -20,6 +20,14 @@ const downloadDoc = `
 
 If --filename is not used, the archive is downloaded to a temporary
 location and the filename is printed to stdout.
+
+An encrypted archive is downloaded as it is stored, unless the key to
+decrypt it is given: either the passphrase it was encrypted with, read
+from the file given with --passphrase-file, or the OpenPGP private key
+matching the public key it was encrypted with, read in armored form
+from the file given with --private-key-file.  The passphrase unlocks
+the private key, if both are given.  The integrity of the decrypted
+archive is checked as it is downloaded.
 `
 
 // DownloadCommand is the sub-command for downloading a backup archive.
@@ -29,6 +37,10 @@ type DownloadCommand struct {
 	Filename string
 	// ID is the backup ID to download.
 	ID string
+	// PassphraseFile holds the passphrase to decrypt the backup with.
+	PassphraseFile string
+	// PrivateKeyFile holds the private key to decrypt the backup with.
+	PrivateKeyFile string
 }
 
 // Info implements Command.Info.
@@ -44,6 +56,8 @@ func (c *DownloadCommand) Info() *cmd.Info {
 // SetFlags implements Command.SetFlags.
 func (c *DownloadCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.StringVar(&c.Filename, "filename", "", "download target")
+	f.StringVar(&c.PassphraseFile, "passphrase-file", "", "decrypt the archive with the passphrase in this file")
+	f.StringVar(&c.PrivateKeyFile, "private-key-file", "", "decrypt the archive with the OpenPGP private key in this file")
 }
 
 // Init implements Command.Init.
@@ -61,18 +75,37 @@ func (c *DownloadCommand) Init(args []string) error {
 
 // Run implements Command.Run.
 func (c *DownloadCommand) Run(ctx *cmd.Context) error {
+	key, err := readKey(ctx, c.PassphraseFile, c.PrivateKeyFile, true)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
 	client, err := c.NewAPIClient()
 	if err != nil {
 		return errors.Trace(err)
 	}
 	defer client.Close()
 
+	// The metadata tells which key the archive is encrypted with.
+	var fingerprint string
+	if key != nil {
+		meta, err := client.Info(c.ID)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		fingerprint = meta.KeyFingerprint
+	}
+
 	// Download the archive.
 	resultArchive, err := client.Download(c.ID)
 	if err != nil {
 		return errors.Trace(err)
 	}
 	defer resultArchive.Close()
+	plainArchive, err := backups.DecryptArchive(resultArchive, fingerprint, key)
+	if err != nil {
+		return errors.Annotatef(err, "cannot decrypt backup %q", c.ID)
+	}
 
 	// Prepare the local archive.
 	filename := c.ResolveFilename()
@@ -82,9 +115,12 @@ func (c *DownloadCommand) Run(ctx *cmd.Context) error {
 	}
 	defer archive.Close()
 
-	// Write out the archive.
-	_, err = io.Copy(archive, resultArchive)
+	// Write out the archive, leaving nothing behind if it fails the
+	// integrity check.
+	_, err = io.Copy(archive, plainArchive)
 	if err != nil {
+		archive.Close()
+		os.Remove(filename)
 		return errors.Annotate(err, "while creating local archive file")
 	}
 
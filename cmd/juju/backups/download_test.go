@@ -4,12 +4,19 @@
 package backups_test
 
 import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
 	"github.com/juju/cmd/cmdtesting"
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
+	"golang.org/x/crypto/openpgp"
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/cmd/juju/backups"
+	statebackups "github.com/juju/juju/state/backups"
 	"github.com/juju/juju/testing"
 )
 
@@ -89,3 +96,63 @@ func (s *downloadSuite) TestError(c *gc.C) {
 
 	c.Check(errors.Cause(err), gc.ErrorMatches, "failed!")
 }
+
+func (s *downloadSuite) setEncrypted(c *gc.C, passphrase string) *fakeAPIClient {
+	var encrypted bytes.Buffer
+	w, err := openpgp.SymmetricallyEncrypt(&encrypted, []byte(passphrase), nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = w.Write([]byte(s.data))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(w.Close(), jc.ErrorIsNil)
+
+	key := &statebackups.EncryptionKey{Passphrase: passphrase}
+	s.metaresult.KeyFingerprint, err = key.Fingerprint()
+	c.Assert(err, jc.ErrorIsNil)
+
+	client := s.setSuccess()
+	client.archive = ioutil.NopCloser(&encrypted)
+	return client
+}
+
+func (s *downloadSuite) writePassphrase(c *gc.C, passphrase string) {
+	s.subcommand.PassphraseFile = filepath.Join(c.MkDir(), "passphrase")
+	err := ioutil.WriteFile(s.subcommand.PassphraseFile, []byte(passphrase), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *downloadSuite) TestDecrypt(c *gc.C) {
+	client := s.setEncrypted(c, "secret")
+	s.writePassphrase(c, "secret")
+	ctx := cmdtesting.Context(c)
+	err := s.subcommand.Run(ctx)
+	c.Assert(err, jc.ErrorIsNil)
+
+	client.Check(c, s.metaresult.ID, "", "Info", "Download")
+	s.filename = "juju-backup-" + s.metaresult.ID + ".tar.gz"
+	s.checkStd(c, ctx, s.filename+"\n", "")
+	s.checkArchive(c)
+}
+
+func (s *downloadSuite) TestDecryptWrongKey(c *gc.C) {
+	s.setEncrypted(c, "secret")
+	s.writePassphrase(c, "guess")
+	ctx := cmdtesting.Context(c)
+	err := s.subcommand.Run(ctx)
+
+	c.Check(err, gc.ErrorMatches, `cannot decrypt backup "spam": cannot decrypt backup archive: wrong key`)
+	_, err = os.Stat(s.subcommand.ResolveFilename())
+	c.Check(err, jc.Satisfies, os.IsNotExist)
+}
+
+func (s *downloadSuite) TestNotDecrypted(c *gc.C) {
+	client := s.setEncrypted(c, "secret")
+	ctx := cmdtesting.Context(c)
+	err := s.subcommand.Run(ctx)
+	c.Assert(err, jc.ErrorIsNil)
+
+	client.Check(c, s.metaresult.ID, "", "Download")
+	s.filename = "juju-backup-" + s.metaresult.ID + ".tar.gz"
+	data, err := ioutil.ReadFile(s.filename)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), gc.Not(gc.Equals), s.data)
+}
@@ -10,4 +10,8 @@ const (
 
 var (
 	NewAPIClient = &newAPIClient
+
+	NewVerifiedArchiveData = &newVerifiedArchiveData
+	VerifyArchiveData      = &verifyArchiveData
+	RestoreDumpCounts      = &restoreDumpCounts
 )
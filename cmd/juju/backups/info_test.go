@@ -51,6 +51,18 @@ func (s *infoSuite) TestOkay(c *gc.C) {
 	s.checkStd(c, ctx, out, "")
 }
 
+func (s *infoSuite) TestEncrypted(c *gc.C) {
+	s.metaresult.KeyFingerprint = "passphrase"
+	s.setSuccess()
+	ctx := cmdtesting.Context(c)
+	err := s.subcommand.Run(ctx)
+	c.Check(err, jc.ErrorIsNil)
+
+	out := strings.Replace(MetaResultString, "notes:           \"\"\n",
+		"notes:           \"\"\nkey fingerprint: \"passphrase\"\n", 1)
+	s.checkStd(c, ctx, out, "")
+}
+
 func (s *infoSuite) TestError(c *gc.C) {
 	s.setFailure("failed!")
 	ctx := cmdtesting.Context(c)
@@ -12,7 +12,9 @@ import (
 )
 
 const listDoc = `
-"list" provides the metadata associated with all backups.
+"list" provides the metadata associated with all backups, including
+where each backup archive is stored.  See the "backups-storage"
+environment setting for where new archives are stored.
 `
 
 // ListCommand is the sub-command for listing all available backups.
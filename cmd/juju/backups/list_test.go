@@ -51,6 +51,18 @@ func (s *listSuite) TestOkay(c *gc.C) {
 	s.checkStd(c, ctx, out, "")
 }
 
+func (s *listSuite) TestLocation(c *gc.C) {
+	s.metaresult.Location = "ssh:ubuntu@10.0.0.1:/srv/backups"
+	s.setSuccess()
+	ctx := cmdtesting.Context(c)
+	err := s.subcommand.Run(ctx)
+	c.Check(err, jc.ErrorIsNil)
+
+	out := strings.Replace(MetaResultString, "stored:          0001-01-01 00:00:00 +0000 UTC\n",
+		"stored:          0001-01-01 00:00:00 +0000 UTC\nlocation:        \"ssh:ubuntu@10.0.0.1:/srv/backups\"\n", 1)
+	s.checkStd(c, ctx, out, "")
+}
+
 func (s *listSuite) TestBrief(c *gc.C) {
 	s.setSuccess()
 	s.subcommand.Brief = true
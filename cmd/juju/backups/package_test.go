@@ -124,6 +124,10 @@ type fakeAPIClient struct {
 	args  []string
 	idArg string
 	notes string
+	key   *params.BackupsKey
+
+	schedule    params.BackupsSchedule
+	newSchedule *params.BackupsSchedule
 }
 
 func (f *fakeAPIClient) Check(c *gc.C, id, notes string, calls ...string) {
@@ -132,10 +136,22 @@ func (f *fakeAPIClient) Check(c *gc.C, id, notes string, calls ...string) {
 	c.Check(f.notes, gc.Equals, notes)
 }
 
-func (c *fakeAPIClient) Create(notes string) (*params.BackupsMetadataResult, error) {
+func (c *fakeAPIClient) Create(notes string, key *params.BackupsKey) (*params.BackupsMetadataResult, error) {
 	c.calls = append(c.calls, "Create")
-	c.args = append(c.args, "notes")
+	c.args = append(c.args, "notes", "key")
+	c.notes = notes
+	c.key = key
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.metaresult, nil
+}
+
+func (c *fakeAPIClient) CreateIncremental(notes string, key *params.BackupsKey) (*params.BackupsMetadataResult, error) {
+	c.calls = append(c.calls, "CreateIncremental")
+	c.args = append(c.args, "notes", "key")
 	c.notes = notes
+	c.key = key
 	if c.err != nil {
 		return nil, c.err
 	}
@@ -201,3 +217,19 @@ func (c *fakeAPIClient) RestoreReader(io.Reader, *params.BackupsMetadataResult,
 func (c *fakeAPIClient) Restore(string, apibackups.ClientConnection) error {
 	return nil
 }
+
+func (c *fakeAPIClient) Schedule() (*params.BackupsSchedule, error) {
+	c.calls = append(c.calls, "Schedule")
+	if c.err != nil {
+		return nil, c.err
+	}
+	schedule := c.schedule
+	return &schedule, nil
+}
+
+func (c *fakeAPIClient) SetSchedule(schedule params.BackupsSchedule) error {
+	c.calls = append(c.calls, "SetSchedule")
+	c.args = append(c.args, "schedule")
+	c.newSchedule = &schedule
+	return c.err
+}
@@ -5,8 +5,11 @@ package backups
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
@@ -19,6 +22,7 @@ import (
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/bootstrap"
 	"github.com/juju/juju/environs/configstore"
+	statebackups "github.com/juju/juju/state/backups"
 )
 
 // RestoreCommand is a subcommand of backups that implement the restore behaior
@@ -29,6 +33,11 @@ type RestoreCommand struct {
 	filename    string
 	backupId    string
 	bootstrap   bool
+
+	passphraseFile string
+	privateKeyFile string
+	until          string
+	untilTime      time.Time
 }
 
 var restoreDoc = `
@@ -47,6 +56,21 @@ If the provided state cannot be restored, this command will fail with
 an appropriate message.  For instance, if the existing bootstrap
 instance is already running then the command will fail with a message
 to that effect.
+
+An encrypted backup is restored with the key to decrypt it, given with
+--passphrase-file or --private-key-file as for "juju backups download".
+The backup is decrypted locally and uploaded decrypted, so the key is
+never sent to the state server.  The restore fails if the key is not
+the one the backup was encrypted with, or if the backup fails its
+integrity check.  Incremental backups are never encrypted nor built on
+encrypted backups, so an encrypted backup is always restored as it is,
+without --until.
+
+Restoring an incremental backup restores the full backup it builds on
+and the changes recorded by each incremental backup in between.  With
+--until, given as a time such as 2015-06-24T07:47:00Z, the changes
+recorded by the backup and by the incremental backups built on it are
+only restored up to that point in time.  --until requires --id.
 `
 
 // Info returns the content for --help.
@@ -67,6 +91,9 @@ func (c *RestoreCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.BoolVar(&c.bootstrap, "b", false, "bootstrap a new state machine")
 	f.StringVar(&c.filename, "file", "", "provide a file to be used as the backup.")
 	f.StringVar(&c.backupId, "id", "", "provide the name of the backup to be restored.")
+	f.StringVar(&c.passphraseFile, "passphrase-file", "", "decrypt the backup with the passphrase in this file")
+	f.StringVar(&c.privateKeyFile, "private-key-file", "", "decrypt the backup with the OpenPGP private key in this file")
+	f.StringVar(&c.until, "until", "", "restore the changes up to this point in time")
 }
 
 // Init is where the preconditions for this commands can be checked.
@@ -81,6 +108,15 @@ func (c *RestoreCommand) Init(args []string) error {
 		return errors.Errorf("it is not possible to rebootstrap and restore from an id.")
 	}
 	var err error
+	if c.until != "" {
+		if c.backupId == "" {
+			return errors.Errorf("--until requires --id")
+		}
+		c.untilTime, err = time.Parse(time.RFC3339, c.until)
+		if err != nil {
+			return errors.Errorf("invalid --until time %q, expected a time such as 2015-06-24T07:47:00Z", c.until)
+		}
+	}
 	if c.filename != "" {
 		c.filename, err = filepath.Abs(c.filename)
 		if err != nil {
@@ -95,7 +131,7 @@ const restoreAPIIncompatibility = "server version not compatible for " +
 
 // runRestore will implement the actual calls to the different Client parts
 // of restore.
-func (c *RestoreCommand) runRestore(ctx *cmd.Context) error {
+func (c *RestoreCommand) runRestore(ctx *cmd.Context, key *statebackups.EncryptionKey) error {
 	client, closer, err := c.newClient()
 	if err != nil {
 		return errors.Trace(err)
@@ -105,16 +141,10 @@ func (c *RestoreCommand) runRestore(ctx *cmd.Context) error {
 	var rErr error
 	if c.filename != "" {
 		target = c.filename
-		archive, meta, err := getArchive(c.filename)
-		if err != nil {
-			return errors.Trace(err)
-		}
-		defer archive.Close()
-
-		rErr = client.RestoreReader(archive, meta, c.newClient)
+		rErr = c.restoreFile(client, key)
 	} else {
 		target = c.backupId
-		rErr = client.Restore(c.backupId, c.newClient)
+		rErr = c.restoreID(client, key)
 	}
 	if params.IsCodeNotImplemented(rErr) {
 		return errors.Errorf(restoreAPIIncompatibility)
@@ -127,6 +157,77 @@ func (c *RestoreCommand) runRestore(ctx *cmd.Context) error {
 	return nil
 }
 
+// restoreFile restores the backup archive file, decrypting it first
+// with the key, if given.
+func (c *RestoreCommand) restoreFile(client *backups.Client, key *statebackups.EncryptionKey) error {
+	if key == nil {
+		archive, meta, err := getArchive(c.filename, nil)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		defer archive.Close()
+		return client.RestoreReader(archive, meta, c.newClient)
+	}
+
+	archive, err := os.Open(c.filename)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer archive.Close()
+	plain, err := key.Decrypt(archive)
+	if err != nil {
+		return errors.Annotatef(err, "cannot decrypt %q", c.filename)
+	}
+	return c.restorePlain(client, plain)
+}
+
+// restoreID restores the stored backup, downloading it to decrypt it
+// with the key if it is encrypted.
+func (c *RestoreCommand) restoreID(client *backups.Client, key *statebackups.EncryptionKey) error {
+	result, err := client.Info(c.backupId)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if result.KeyFingerprint == "" {
+		return client.RestoreUntil(c.backupId, c.untilTime, c.newClient)
+	}
+	if key == nil {
+		return errors.Errorf("backup %q is encrypted; restoring it needs the key to decrypt it", c.backupId)
+	}
+	if result.Base != "" || !c.untilTime.IsZero() {
+		return errors.Errorf("cannot restore incremental changes from encrypted backup %q", c.backupId)
+	}
+
+	archive, err := client.Download(c.backupId)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer archive.Close()
+	plain, err := statebackups.DecryptArchive(archive, result.KeyFingerprint, key)
+	if err != nil {
+		return errors.Annotatef(err, "cannot decrypt backup %q", c.backupId)
+	}
+	return c.restorePlain(client, plain)
+}
+
+// restorePlain uploads the decrypted archive and restores it.
+func (c *RestoreCommand) restorePlain(client *backups.Client, plain io.Reader) error {
+	filename, err := writeTempArchive(plain)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer os.Remove(filename)
+	archive, meta, err := getArchive(filename, nil)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer archive.Close()
+	// The metadata in the archive still records the key it was
+	// encrypted with.
+	meta.KeyFingerprint = ""
+	return client.RestoreReader(archive, meta, c.newClient)
+}
+
 // rebootstrap will bootstrap a new server in safe-mode (not killing any other agent)
 // if there is no current server available to restore to.
 func (c *RestoreCommand) rebootstrap(ctx *cmd.Context) error {
@@ -187,10 +288,15 @@ func (c *RestoreCommand) newClient() (*backups.Client, func() error, error) {
 
 // Run is the entry point for this command.
 func (c *RestoreCommand) Run(ctx *cmd.Context) error {
+	// Read the key first, not to bootstrap in vain.
+	key, err := readKey(ctx, c.passphraseFile, c.privateKeyFile, true)
+	if err != nil {
+		return errors.Trace(err)
+	}
 	if c.bootstrap {
 		if err := c.rebootstrap(ctx); err != nil {
 			return errors.Trace(err)
 		}
 	}
-	return c.runRestore(ctx)
+	return c.runRestore(ctx, key)
 }
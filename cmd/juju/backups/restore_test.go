@@ -33,3 +33,11 @@ func (s *restoreSuite) TestRestoreArgs(c *gc.C) {
 	_, err = testing.RunCommand(c, s.command, "restore", "--id", "anid", "-b")
 	c.Assert(err, gc.ErrorMatches, "it is not possible to rebootstrap and restore from an id.")
 }
+
+func (s *restoreSuite) TestRestoreUntilArgs(c *gc.C) {
+	_, err := testing.RunCommand(c, s.command, "restore", "--file", "afile", "--until", "2015-06-24T07:47:00Z")
+	c.Assert(err, gc.ErrorMatches, "--until requires --id")
+
+	_, err = testing.RunCommand(c, s.command, "restore", "--id", "anid", "--until", "yesterday")
+	c.Assert(err, gc.ErrorMatches, `invalid --until time "yesterday", expected a time such as 2015-06-24T07:47:00Z`)
+}
@@ -0,0 +1,188 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/apiserver/params"
+)
+
+const scheduleDoc = `
+"schedule" shows when juju creates backups of its state automatically,
+and how many of the scheduled backups are kept.
+
+See "juju backups set-schedule" for how to change the schedule.
+`
+
+// ScheduleCommand is the sub-command for showing the backup schedule.
+type ScheduleCommand struct {
+	CommandBase
+}
+
+// Info implements Command.Info.
+func (c *ScheduleCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "schedule",
+		Purpose: "show the backup schedule",
+		Doc:     scheduleDoc,
+	}
+}
+
+// Init implements Command.Init.
+func (c *ScheduleCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+// Run implements Command.Run.
+func (c *ScheduleCommand) Run(ctx *cmd.Context) error {
+	client, err := c.NewAPIClient()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer client.Close()
+
+	schedule, err := client.Schedule()
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	if schedule.Interval == 0 {
+		fmt.Fprintf(ctx.Stdout, "interval:        disabled\n")
+	} else {
+		fmt.Fprintf(ctx.Stdout, "interval:        %v\n", schedule.Interval)
+	}
+	fmt.Fprintf(ctx.Stdout, "keep daily:      %d\n", schedule.KeepDaily)
+	fmt.Fprintf(ctx.Stdout, "keep weekly:     %d\n", schedule.KeepWeekly)
+	if schedule.KeyFingerprint == "" {
+		fmt.Fprintf(ctx.Stdout, "key fingerprint: none\n")
+	} else {
+		fmt.Fprintf(ctx.Stdout, "key fingerprint: %q\n", schedule.KeyFingerprint)
+	}
+	return nil
+}
+
+const setScheduleDoc = `
+"set-schedule" changes when juju creates backups of its state
+automatically, and how many of the scheduled backups are kept.  Only the
+parts of the schedule given as options are changed.
+
+A backup is created once the last scheduled backup is older than the
+interval given with --interval, e.g. "24h".  The interval must be at
+least one hour; an interval of 0 disables scheduled backups.
+
+Of the scheduled backups, the last one of each of the last --keep-daily
+days and of each of the last --keep-weekly weeks is kept, along with the
+last one overall; the others are removed.  If both are 0, no backup is
+removed.  Backups created with "juju backups create" are never removed
+automatically.
+
+Scheduled backups may be encrypted with an OpenPGP public key, read in
+armored form from the file given with --public-key-file.  Use
+--no-public-key to stop encrypting them.
+`
+
+// SetScheduleCommand is the sub-command for changing the backup
+// schedule.
+type SetScheduleCommand struct {
+	CommandBase
+	// Interval is the time between scheduled backups, if it changes.
+	Interval string
+	// KeepDaily is the number of daily backups to keep, or -1 if it
+	// does not change.
+	KeepDaily int
+	// KeepWeekly is the number of weekly backups to keep, or -1 if it
+	// does not change.
+	KeepWeekly int
+	// PublicKeyFile holds the public key to encrypt backups with.
+	PublicKeyFile string
+	// NoPublicKey means that backups should no longer be encrypted.
+	NoPublicKey bool
+
+	interval time.Duration
+}
+
+// Info implements Command.Info.
+func (c *SetScheduleCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "set-schedule",
+		Purpose: "change the backup schedule",
+		Doc:     setScheduleDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *SetScheduleCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.Interval, "interval", "", "time between scheduled backups (0 to disable)")
+	f.IntVar(&c.KeepDaily, "keep-daily", -1, "number of days to keep a backup for")
+	f.IntVar(&c.KeepWeekly, "keep-weekly", -1, "number of weeks to keep a backup for")
+	f.StringVar(&c.PublicKeyFile, "public-key-file", "", "encrypt backups with the OpenPGP public key in this file")
+	f.BoolVar(&c.NoPublicKey, "no-public-key", false, "do not encrypt backups")
+}
+
+// Init implements Command.Init.
+func (c *SetScheduleCommand) Init(args []string) error {
+	if err := cmd.CheckEmpty(args); err != nil {
+		return errors.Trace(err)
+	}
+	if c.Interval != "" {
+		interval, err := time.ParseDuration(c.Interval)
+		if err != nil {
+			return errors.Annotate(err, "invalid interval")
+		}
+		c.interval = interval
+	}
+	if c.PublicKeyFile != "" && c.NoPublicKey {
+		return errors.Errorf("cannot mix --public-key-file and --no-public-key")
+	}
+	if c.Interval == "" && c.KeepDaily < 0 && c.KeepWeekly < 0 && c.PublicKeyFile == "" && !c.NoPublicKey {
+		return errors.New("no schedule changes given")
+	}
+	return nil
+}
+
+// Run implements Command.Run.
+func (c *SetScheduleCommand) Run(ctx *cmd.Context) error {
+	key, err := readKey(ctx, "", c.PublicKeyFile, false)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	client, err := c.NewAPIClient()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer client.Close()
+
+	current, err := client.Schedule()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	schedule := params.BackupsSchedule{
+		Interval:   current.Interval,
+		KeepDaily:  current.KeepDaily,
+		KeepWeekly: current.KeepWeekly,
+		PublicKey:  current.PublicKey,
+	}
+	if c.Interval != "" {
+		schedule.Interval = c.interval
+	}
+	if c.KeepDaily >= 0 {
+		schedule.KeepDaily = c.KeepDaily
+	}
+	if c.KeepWeekly >= 0 {
+		schedule.KeepWeekly = c.KeepWeekly
+	}
+	if key != nil {
+		schedule.PublicKey = key.PublicKey
+	} else if c.NoPublicKey {
+		schedule.PublicKey = ""
+	}
+	return errors.Trace(client.SetSchedule(schedule))
+}
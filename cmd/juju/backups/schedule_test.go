@@ -0,0 +1,141 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/backups"
+	"github.com/juju/juju/testing"
+)
+
+type scheduleSuite struct {
+	BaseBackupsSuite
+}
+
+var _ = gc.Suite(&scheduleSuite{})
+
+func (s *scheduleSuite) TestSchedule(c *gc.C) {
+	client := s.setSuccess()
+	client.schedule = params.BackupsSchedule{
+		Interval:       24 * time.Hour,
+		KeepDaily:      7,
+		KeepWeekly:     4,
+		PublicKey:      "<public key>",
+		KeyFingerprint: "ABCD",
+	}
+	ctx, err := testing.RunCommand(c, s.command, "schedule")
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.checkStd(c, ctx, `
+interval:        24h0m0s
+keep daily:      7
+keep weekly:     4
+key fingerprint: "ABCD"
+`[1:], "")
+}
+
+func (s *scheduleSuite) TestScheduleDisabled(c *gc.C) {
+	s.setSuccess()
+	ctx, err := testing.RunCommand(c, s.command, "schedule")
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.checkStd(c, ctx, `
+interval:        disabled
+keep daily:      0
+keep weekly:     0
+key fingerprint: none
+`[1:], "")
+}
+
+func (s *scheduleSuite) TestScheduleError(c *gc.C) {
+	s.setFailure("failed!")
+	_, err := testing.RunCommand(c, s.command, "schedule")
+
+	c.Check(errors.Cause(err), gc.ErrorMatches, "failed!")
+}
+
+func (s *scheduleSuite) TestSetSchedule(c *gc.C) {
+	client := s.setSuccess()
+	client.schedule = params.BackupsSchedule{
+		Interval:       24 * time.Hour,
+		KeepDaily:      7,
+		KeepWeekly:     4,
+		PublicKey:      "<public key>",
+		KeyFingerprint: "ABCD",
+	}
+	_, err := testing.RunCommand(c, s.command, "set-schedule", "--interval", "12h", "--keep-weekly", "0")
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(client.calls, jc.DeepEquals, []string{"Schedule", "SetSchedule"})
+	c.Check(client.newSchedule, jc.DeepEquals, &params.BackupsSchedule{
+		Interval:   12 * time.Hour,
+		KeepDaily:  7,
+		KeepWeekly: 0,
+		PublicKey:  "<public key>",
+	})
+}
+
+func (s *scheduleSuite) TestSetSchedulePublicKey(c *gc.C) {
+	client := s.setSuccess()
+	client.schedule = params.BackupsSchedule{Interval: 24 * time.Hour}
+	filename := filepath.Join(c.MkDir(), "key.asc")
+	err := ioutil.WriteFile(filename, []byte("<public key>"), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = testing.RunCommand(c, s.command, "set-schedule", "--public-key-file", filename)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(client.newSchedule, jc.DeepEquals, &params.BackupsSchedule{
+		Interval:  24 * time.Hour,
+		PublicKey: "<public key>",
+	})
+}
+
+func (s *scheduleSuite) TestSetScheduleNoPublicKey(c *gc.C) {
+	client := s.setSuccess()
+	client.schedule = params.BackupsSchedule{Interval: 24 * time.Hour, PublicKey: "<public key>"}
+	_, err := testing.RunCommand(c, s.command, "set-schedule", "--no-public-key")
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(client.newSchedule, jc.DeepEquals, &params.BackupsSchedule{Interval: 24 * time.Hour})
+}
+
+func (s *scheduleSuite) TestSetScheduleInvalid(c *gc.C) {
+	s.setSuccess()
+	for i, test := range []struct {
+		args []string
+		err  string
+	}{{
+		args: nil,
+		err:  "no schedule changes given",
+	}, {
+		args: []string{"--interval", "daily"},
+		err:  "invalid interval: .*",
+	}, {
+		args: []string{"--public-key-file", "key.asc", "--no-public-key"},
+		err:  "cannot mix --public-key-file and --no-public-key",
+	}, {
+		args: []string{"--interval", "1h", "extra"},
+		err:  `unrecognized args: \["extra"\]`,
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		command := backups.NewCommand()
+		_, err := testing.RunCommand(c, command, append([]string{"set-schedule"}, test.args...)...)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
+func (s *scheduleSuite) TestSetScheduleError(c *gc.C) {
+	s.setFailure("failed!")
+	_, err := testing.RunCommand(c, s.command, "set-schedule", "--interval", "0")
+
+	c.Check(errors.Cause(err), gc.ErrorMatches, "failed!")
+}
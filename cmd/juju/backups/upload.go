@@ -15,6 +15,10 @@ import (
 
 const uploadDoc = `
 "upload" sends a backup archive file to remote storage.
+
+An encrypted archive is uploaded as it is, but its metadata can only be
+read with the key to decrypt it, given with --passphrase-file or
+--private-key-file as for "juju backups download".
 `
 
 // UploadCommand is the sub-command for uploading a backup archive.
@@ -26,12 +30,18 @@ type UploadCommand struct {
 	ShowMeta bool
 	// Quiet indicates that the new backup ID should not be printed.
 	Quiet bool
+	// PassphraseFile holds the passphrase to decrypt the backup with.
+	PassphraseFile string
+	// PrivateKeyFile holds the private key to decrypt the backup with.
+	PrivateKeyFile string
 }
 
 // SetFlags implements Command.SetFlags.
 func (c *UploadCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.BoolVar(&c.ShowMeta, "verbose", false, "show the uploaded metadata")
 	f.BoolVar(&c.Quiet, "quiet", false, "do not print the new backup ID")
+	f.StringVar(&c.PassphraseFile, "passphrase-file", "", "decrypt the archive with the passphrase in this file")
+	f.StringVar(&c.PrivateKeyFile, "private-key-file", "", "decrypt the archive with the OpenPGP private key in this file")
 }
 
 // Info implements Command.Info.
@@ -59,13 +69,18 @@ func (c *UploadCommand) Init(args []string) error {
 
 // Run implements Command.Run.
 func (c *UploadCommand) Run(ctx *cmd.Context) error {
+	key, err := readKey(ctx, c.PassphraseFile, c.PrivateKeyFile, true)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
 	client, err := c.NewAPIClient()
 	if err != nil {
 		return errors.Trace(err)
 	}
 	defer client.Close()
 
-	archive, meta, err := getArchive(c.Filename)
+	archive, meta, err := getArchive(c.Filename, key)
 	if err != nil {
 		return errors.Trace(err)
 	}
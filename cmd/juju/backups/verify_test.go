@@ -0,0 +1,174 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups_test
+
+import (
+	"io"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/juju/cmd/cmdtesting"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/cmd/juju/backups"
+	statebackups "github.com/juju/juju/state/backups"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/version"
+)
+
+type verifySuite struct {
+	BaseBackupsSuite
+	subcommand *backups.VerifyCommand
+
+	key      *statebackups.EncryptionKey
+	restored bool
+}
+
+var _ = gc.Suite(&verifySuite{})
+
+func (s *verifySuite) SetUpTest(c *gc.C) {
+	s.BaseBackupsSuite.SetUpTest(c)
+	s.subcommand = &backups.VerifyCommand{ID: "spam"}
+	s.key = nil
+	s.restored = false
+
+	ad := statebackups.NewArchiveData([]byte("<archive data>"))
+	s.PatchValue(backups.NewVerifiedArchiveData, func(archive io.Reader, meta *statebackups.Metadata, key *statebackups.EncryptionKey) (*statebackups.ArchiveData, error) {
+		data, err := ioutil.ReadAll(archive)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(string(data), gc.Equals, s.data)
+		c.Check(meta.ID(), gc.Equals, "spam")
+		s.key = key
+		return ad, nil
+	})
+	s.PatchValue(backups.VerifyArchiveData, func(got *statebackups.ArchiveData, meta *statebackups.Metadata) (*statebackups.VerifyResult, error) {
+		c.Check(got, gc.Equals, ad)
+		return &statebackups.VerifyResult{
+			Version:      version.MustParse("1.25.0"),
+			Databases:    []string{"admin", "juju"},
+			AgentConfigs: []string{"var/lib/juju/agents/machine-0/agent.conf"},
+		}, nil
+	})
+	s.PatchValue(backups.RestoreDumpCounts, func(got *statebackups.ArchiveData, ver version.Number) ([]statebackups.CollectionCount, error) {
+		c.Check(got, gc.Equals, ad)
+		c.Check(ver, gc.Equals, version.MustParse("1.25.0"))
+		s.restored = true
+		return []statebackups.CollectionCount{{
+			Database:   "juju",
+			Collection: "machines",
+			Count:      2,
+		}, {
+			Database:   "juju",
+			Collection: "units",
+			Count:      1,
+		}}, nil
+	})
+}
+
+const verifiedString = `
+backup ID:       "spam"
+juju version:    1.25.0
+databases:       admin, juju
+agent configs:   var/lib/juju/agents/machine-0/agent.conf
+`
+
+func (s *verifySuite) TestHelp(c *gc.C) {
+	ctx, err := testing.RunCommand(c, s.command, "verify", "--help")
+	c.Assert(err, jc.ErrorIsNil)
+
+	info := s.subcommand.Info()
+	expected := `(?sm)usage: juju backups verify \[options] ` + info.Args + `$.*`
+	c.Check(testing.Stdout(ctx), gc.Matches, expected)
+	expected = "(?sm).*^purpose: " + info.Purpose + "$.*"
+	c.Check(testing.Stdout(ctx), gc.Matches, expected)
+	expected = "(?sm).*^" + info.Doc + "$.*"
+	c.Check(testing.Stdout(ctx), gc.Matches, expected)
+}
+
+func (s *verifySuite) TestInit(c *gc.C) {
+	err := s.subcommand.Init(nil)
+	c.Check(err, gc.ErrorMatches, "missing ID")
+
+	err = s.subcommand.Init([]string{"spam", "eggs"})
+	c.Check(err, gc.ErrorMatches, `unrecognized args: \["eggs"\]`)
+}
+
+func (s *verifySuite) TestOkay(c *gc.C) {
+	client := s.setDownload()
+	ctx := cmdtesting.Context(c)
+	err := s.subcommand.Run(ctx)
+	c.Assert(err, jc.ErrorIsNil)
+
+	client.Check(c, "spam", "", "Info", "Download")
+	s.checkStd(c, ctx, verifiedString[1:], "")
+	c.Check(s.key, gc.IsNil)
+	c.Check(s.restored, jc.IsFalse)
+}
+
+func (s *verifySuite) TestRestore(c *gc.C) {
+	s.setDownload()
+	s.subcommand.Restore = true
+	ctx := cmdtesting.Context(c)
+	err := s.subcommand.Run(ctx)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(s.restored, jc.IsTrue)
+	s.checkStd(c, ctx, verifiedString[1:]+`
+collections:     2
+  juju.machines: 2 documents
+  juju.units: 1 documents
+`[1:], "")
+}
+
+func (s *verifySuite) TestRestoreIncremental(c *gc.C) {
+	s.metaresult.Base = "eggs"
+	client := s.setDownload()
+	s.subcommand.Restore = true
+	ctx := cmdtesting.Context(c)
+	err := s.subcommand.Run(ctx)
+
+	c.Check(err, gc.ErrorMatches, `cannot restore incremental backup "spam" on its own; verify full backup "eggs" instead`)
+	client.Check(c, "spam", "", "Info")
+}
+
+func (s *verifySuite) TestEncrypted(c *gc.C) {
+	key := &statebackups.EncryptionKey{Passphrase: "secret"}
+	fingerprint, err := key.Fingerprint()
+	c.Assert(err, jc.ErrorIsNil)
+	s.metaresult.KeyFingerprint = fingerprint
+	s.setDownload()
+
+	ctx := cmdtesting.Context(c)
+	err = s.subcommand.Run(ctx)
+	c.Check(err, gc.ErrorMatches, `backup "spam" is encrypted; verifying it needs the key to decrypt it`)
+
+	s.subcommand.PassphraseFile = filepath.Join(c.MkDir(), "passphrase")
+	err = ioutil.WriteFile(s.subcommand.PassphraseFile, []byte("secret\n"), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+	ctx = cmdtesting.Context(c)
+	err = s.subcommand.Run(ctx)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.key, jc.DeepEquals, key)
+}
+
+func (s *verifySuite) TestNotUsable(c *gc.C) {
+	s.setDownload()
+	s.PatchValue(backups.VerifyArchiveData, func(*statebackups.ArchiveData, *statebackups.Metadata) (*statebackups.VerifyResult, error) {
+		return nil, errors.New("DB dump is empty or missing")
+	})
+	ctx := cmdtesting.Context(c)
+	err := s.subcommand.Run(ctx)
+
+	c.Check(err, gc.ErrorMatches, `backup "spam" is not usable: DB dump is empty or missing`)
+}
+
+func (s *verifySuite) TestError(c *gc.C) {
+	s.setFailure("failed!")
+	ctx := cmdtesting.Context(c)
+	err := s.subcommand.Run(ctx)
+
+	c.Check(errors.Cause(err), gc.ErrorMatches, "failed!")
+}
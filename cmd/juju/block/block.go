@@ -4,6 +4,8 @@
 package block
 
 import (
+	"time"
+
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
 	"launchpad.net/gnuflag"
@@ -15,7 +17,10 @@ import (
 // commands that enable blocks.
 type BaseBlockCommand struct {
 	envcmd.EnvCommandBase
-	desc string
+	desc   string
+	ttl    time.Duration
+	target blockTarget
+	tag    string
 }
 
 // Init initializes the command.
@@ -28,7 +33,12 @@ func (c *BaseBlockCommand) Init(args []string) error {
 	if len(args) == 1 {
 		c.desc = args[0]
 	}
-	return nil
+	if c.ttl < 0 {
+		return errors.Errorf("invalid --ttl %v, expected a positive duration", c.ttl)
+	}
+	var err error
+	c.tag, err = c.target.entityTag()
+	return errors.Trace(err)
 }
 
 // internalRun blocks commands from running successfully.
@@ -39,18 +49,19 @@ func (c *BaseBlockCommand) internalRun(operation string) error {
 	}
 	defer client.Close()
 
-	return client.SwitchBlockOn(TypeFromOperation(operation), c.desc)
+	return client.SwitchEntityBlockOn(TypeFromOperation(operation), c.tag, c.desc, c.ttl)
 }
 
 // SetFlags implements Command.SetFlags.
 func (c *BaseBlockCommand) SetFlags(f *gnuflag.FlagSet) {
 	c.EnvCommandBase.SetFlags(f)
+	f.DurationVar(&c.ttl, "ttl", 0, "switch the block off again after this long, such as 2h")
 }
 
 // BlockClientAPI defines the client API methods that block command uses.
 type BlockClientAPI interface {
 	Close() error
-	SwitchBlockOn(blockType, msg string) error
+	SwitchEntityBlockOn(blockType, tag, msg string, ttl time.Duration) error
 }
 
 var getBlockClientAPI = func(p *BaseBlockCommand) (BlockClientAPI, error) {
@@ -68,6 +79,7 @@ This command allows to block environment destruction.
 
 To disable the block, run unblock command - see "juju help unblock". 
 To by-pass the block, run destroy-enviornment with --force option.
+The block may switch itself off after the time given with the --ttl option.
 
 "juju block destroy-environment" only blocks destroy-environment command.
    
@@ -105,6 +117,10 @@ from Juju environment.
 To disable the block, run unblock command - see "juju help unblock". 
 To by-pass the block, where available, run desired remove command with --force option.
 
+The block may be limited to a single service or machine with the --service
+or --machine option, and may switch itself off after the time given with
+the --ttl option.
+
 "juju block remove-object" blocks these commands:
     destroy-environment
     remove-machine
@@ -116,6 +132,12 @@ Examples:
    To prevent the machines, services, units and relations from being removed:
    juju block remove-object
 
+   To prevent the mysql service, its units and relations from being removed:
+   juju block remove-object --service mysql "keep the database"
+
+   To prevent machine 3 from being removed for the next two hours:
+   juju block remove-object --machine 3 --ttl 2h
+
 `
 
 // Info provides information about command.
@@ -128,6 +150,12 @@ func (c *RemoveCommand) Info() *cmd.Info {
 	}
 }
 
+// SetFlags implements Command.SetFlags.
+func (c *RemoveCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.BaseBlockCommand.SetFlags(f)
+	c.target.setFlags(f)
+}
+
 // Satisfying Command interface.
 func (c *RemoveCommand) Run(_ *cmd.Context) error {
 	return c.internalRun(c.Info().Name)
@@ -146,6 +174,11 @@ Juju environment.
 To disable the block, run unblock command - see "juju help unblock". 
 To by-pass the block, where available, run desired remove command with --force option.
 
+The block may be limited to a single service or machine with the --service
+or --machine option, in which case only the commands that change or remove
+that service or machine are blocked. The block may switch itself off after
+the time given with the --ttl option.
+
 "juju block all-changes" blocks these commands:
     add-machine
     add-relation
@@ -182,6 +215,9 @@ Examples:
    To prevent changes to the environment:
    juju block all-changes
 
+   To prevent changes to the mysql service for the next 30 minutes:
+   juju block all-changes --service mysql --ttl 30m
+
 `
 
 // Info provides information about command.
@@ -194,6 +230,12 @@ func (c *ChangeCommand) Info() *cmd.Info {
 	}
 }
 
+// SetFlags implements Command.SetFlags.
+func (c *ChangeCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.BaseBlockCommand.SetFlags(f)
+	c.target.setFlags(f)
+}
+
 // Satisfying Command interface.
 func (c *ChangeCommand) Run(_ *cmd.Context) error {
 	return c.internalRun(c.Info().Name)
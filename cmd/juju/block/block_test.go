@@ -5,6 +5,7 @@ package block_test
 
 import (
 	"strings"
+	"time"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
@@ -74,6 +75,65 @@ func (s *BlockCommandSuite) TestBlockChangeOperations(c *gc.C) {
 	s.assertBlock(c, command.Info().Name, "TestBlockChangeOperations")
 }
 
+func (s *BlockCommandSuite) TestBlockServiceWithTTL(c *gc.C) {
+	command := block.RemoveCommand{}
+	_, err := testing.RunCommand(c, envcmd.Wrap(&command), "--service", "mysql", "--ttl", "2h", "keep the database")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertBlock(c, command.Info().Name, "keep the database")
+	c.Assert(s.mockClient.Tag, gc.Equals, "service-mysql")
+	c.Assert(s.mockClient.TTL, gc.Equals, 2*time.Hour)
+}
+
+func (s *BlockCommandSuite) TestBlockMachine(c *gc.C) {
+	command := block.ChangeCommand{}
+	_, err := testing.RunCommand(c, envcmd.Wrap(&command), "--machine", "3")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertBlock(c, command.Info().Name, "")
+	c.Assert(s.mockClient.Tag, gc.Equals, "machine-3")
+	c.Assert(s.mockClient.TTL, gc.Equals, time.Duration(0))
+}
+
+func (s *BlockCommandSuite) TestBlockEnvironmentWithTTL(c *gc.C) {
+	command := block.DestroyCommand{}
+	_, err := testing.RunCommand(c, envcmd.Wrap(&command), "--ttl", "30m")
+	c.Assert(err, jc.ErrorIsNil)
+	s.assertBlock(c, command.Info().Name, "")
+	c.Assert(s.mockClient.Tag, gc.Equals, "")
+	c.Assert(s.mockClient.TTL, gc.Equals, 30*time.Minute)
+}
+
+func (s *BlockCommandSuite) TestBlockTargetErrors(c *gc.C) {
+	for i, test := range []struct {
+		command cmd.Command
+		args    []string
+		err     string
+	}{{
+		command: &block.RemoveCommand{},
+		args:    []string{"--service", "mysql", "--machine", "0"},
+		err:     "cannot specify both --service and --machine",
+	}, {
+		command: &block.RemoveCommand{},
+		args:    []string{"--service", "Bad_Name"},
+		err:     `invalid service name "Bad_Name"`,
+	}, {
+		command: &block.ChangeCommand{},
+		args:    []string{"--machine", "foo"},
+		err:     `invalid machine id "foo"`,
+	}, {
+		command: &block.ChangeCommand{},
+		args:    []string{"--ttl", "-1h"},
+		err:     `invalid --ttl -1h0m0s, expected a positive duration`,
+	}, {
+		command: &block.DestroyCommand{},
+		args:    []string{"--service", "mysql"},
+		err:     `flag provided but not defined: --service`,
+	}} {
+		c.Logf("test %d: %v", i, test.args)
+		_, err := testing.RunCommand(c, envcmd.Wrap(test.command), test.args...)
+		c.Check(err, gc.ErrorMatches, test.err)
+	}
+}
+
 func (s *BlockCommandSuite) processErrorTest(c *gc.C, tstError error, blockType block.Block, expectedError error, expectedWarning string) {
 	if tstError != nil {
 		c.Assert(errors.Cause(block.ProcessBlockedError(tstError, blockType)), gc.Equals, expectedError)
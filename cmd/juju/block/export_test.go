@@ -3,7 +3,11 @@
 
 package block
 
-import "github.com/juju/juju/apiserver/params"
+import (
+	"time"
+
+	"github.com/juju/juju/apiserver/params"
+)
 
 var (
 	BlockClient   = &getBlockClientAPI
@@ -14,6 +18,9 @@ var (
 type MockBlockClient struct {
 	BlockType string
 	Msg       string
+	Tag       string
+	TTL       time.Duration
+	Expires   *time.Time
 }
 
 func (c *MockBlockClient) Close() error {
@@ -21,14 +28,26 @@ func (c *MockBlockClient) Close() error {
 }
 
 func (c *MockBlockClient) SwitchBlockOn(blockType, msg string) error {
+	return c.SwitchEntityBlockOn(blockType, "", msg, 0)
+}
+
+func (c *MockBlockClient) SwitchEntityBlockOn(blockType, tag, msg string, ttl time.Duration) error {
 	c.BlockType = blockType
 	c.Msg = msg
+	c.Tag = tag
+	c.TTL = ttl
 	return nil
 }
 
 func (c *MockBlockClient) SwitchBlockOff(blockType string) error {
+	return c.SwitchEntityBlockOff(blockType, "")
+}
+
+func (c *MockBlockClient) SwitchEntityBlockOff(blockType, tag string) error {
 	c.BlockType = blockType
 	c.Msg = ""
+	c.Tag = tag
+	c.TTL = 0
 	return nil
 }
 
@@ -41,6 +60,8 @@ func (c *MockBlockClient) List() ([]params.Block, error) {
 		params.Block{
 			Type:    c.BlockType,
 			Message: c.Msg,
+			Tag:     c.Tag,
+			Expires: c.Expires,
 		},
 	}, nil
 }
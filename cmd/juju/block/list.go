@@ -7,9 +7,11 @@ import (
 	"bytes"
 	"fmt"
 	"text/tabwriter"
+	"time"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
+	"github.com/juju/names"
 	"launchpad.net/gnuflag"
 
 	"github.com/juju/juju/apiserver/params"
@@ -20,6 +22,8 @@ const listCommandDoc = `
 List blocks for Juju environment.
 This command shows if each block type is enabled. 
 For enabled blocks, block message is shown if it was specified.
+Blocks for single services or machines are shown after those for
+the environment, as are the times that blocks switch themselves off.
 `
 
 // ListCommand list blocks.
@@ -80,8 +84,10 @@ var getBlockListAPI = func(p *ListCommand) (BlockListAPI, error) {
 // BlockInfo defines the serialization behaviour of the block information.
 type BlockInfo struct {
 	Operation string  `yaml:"block" json:"block"`
+	Target    string  `yaml:"target,omitempty" json:"target,omitempty"`
 	Enabled   bool    `yaml:"enabled" json:"enabled"`
 	Message   *string `yaml:"message,omitempty" json:"message,omitempty"`
+	Expires   string  `yaml:"expires,omitempty" json:"expires,omitempty"`
 }
 
 // formatBlockInfo takes a set of Block and creates a
@@ -90,14 +96,24 @@ func formatBlockInfo(all []params.Block) []BlockInfo {
 	output := make([]BlockInfo, len(blockArgs))
 
 	info := make(map[string]BlockInfo, len(all))
+	var targeted []BlockInfo
 	// not all block types may be returned from client
 	for _, one := range all {
 		op := OperationFromType(one.Type)
+		message := one.Message
 		bi := BlockInfo{
 			Operation: op,
 			// If client returned it, it means that it is enabled
 			Enabled: true,
-			Message: &one.Message,
+			Message: &message,
+		}
+		if one.Expires != nil {
+			bi.Expires = one.Expires.UTC().Format(time.RFC3339)
+		}
+		if target := blockTargetName(one.Tag); target != "" {
+			bi.Target = target
+			targeted = append(targeted, bi)
+			continue
 		}
 		info[op] = bi
 	}
@@ -110,7 +126,18 @@ func formatBlockInfo(all []params.Block) []BlockInfo {
 		output[i] = BlockInfo{Operation: aType}
 	}
 
-	return output
+	return append(output, targeted...)
+}
+
+// blockTargetName returns the name of the service or machine
+// the block with the given tag is for, such as "service mysql",
+// or an empty string if it is for the whole environment.
+func blockTargetName(tagString string) string {
+	tag, err := names.ParseTag(tagString)
+	if err != nil || tag.Kind() == names.EnvironTagKind {
+		return ""
+	}
+	return names.ReadableString(tag)
 }
 
 // formatBlocks returns block list representation.
@@ -129,12 +156,19 @@ func formatBlocks(value interface{}) ([]byte, error) {
 		if ablock.Enabled {
 			switched = "on"
 		}
-		fmt.Fprintf(tw, "%v\t", ablock.Operation)
+		fmt.Fprintf(tw, "%v", ablock.Operation)
+		if ablock.Target != "" {
+			fmt.Fprintf(tw, " (%v)", ablock.Target)
+		}
+		fmt.Fprintf(tw, "\t")
 		if ablock.Message != nil {
 			fmt.Fprintf(tw, "\t=%v, %v", switched, *ablock.Message)
-			continue
+		} else {
+			fmt.Fprintf(tw, "\t=%v", switched)
+		}
+		if ablock.Expires != "" {
+			fmt.Fprintf(tw, " (until %v)", ablock.Expires)
 		}
-		fmt.Fprintf(tw, "\t=%v", switched)
 	}
 
 	tw.Flush()
@@ -4,6 +4,8 @@
 package block_test
 
 import (
+	"time"
+
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
@@ -71,3 +73,27 @@ func (s *listCommandSuite) TestListJson(c *gc.C) {
 	c.Assert(testing.Stdout(ctx), gc.Equals, `[{"block":"destroy-environment","enabled":false},{"block":"remove-object","enabled":true,"message":"Test this one"},{"block":"all-changes","enabled":false}]
 `)
 }
+
+func (s *listCommandSuite) TestListTargeted(c *gc.C) {
+	expires := time.Date(2015, 6, 24, 7, 47, 0, 0, time.UTC)
+	s.mockClient.SwitchEntityBlockOn(string(multiwatcher.BlockRemove), "service-mysql", "keep the database", time.Hour)
+	s.mockClient.Expires = &expires
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&block.ListCommand{}))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, `
+destroy-environment            =off
+remove-object                  =off
+all-changes                    =off
+remove-object (service mysql)  =on, keep the database (until 2015-06-24T07:47:00Z)
+`)
+}
+
+func (s *listCommandSuite) TestListTargetedJson(c *gc.C) {
+	expires := time.Date(2015, 6, 24, 7, 47, 0, 0, time.UTC)
+	s.mockClient.SwitchEntityBlockOn(string(multiwatcher.BlockChange), "machine-3", "", time.Hour)
+	s.mockClient.Expires = &expires
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&block.ListCommand{}), "--format", "json")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, `[{"block":"destroy-environment","enabled":false},{"block":"remove-object","enabled":false},{"block":"all-changes","enabled":false},{"block":"all-changes","target":"machine 3","enabled":true,"message":"","expires":"2015-06-24T07:47:00Z"}]
+`)
+}
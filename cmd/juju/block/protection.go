@@ -7,7 +7,10 @@ import (
 	"fmt"
 
 	"github.com/juju/cmd"
+	"github.com/juju/errors"
 	"github.com/juju/loggo"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
 
 	apiblock "github.com/juju/juju/api/block"
 	"github.com/juju/juju/apiserver/params"
@@ -48,6 +51,38 @@ var OperationFromType = func(blockType string) string {
 	return blockTypes[blockType]
 }
 
+// blockTarget holds the options that limit a block to a single
+// service or machine, rather than the whole environment.
+type blockTarget struct {
+	service string
+	machine string
+}
+
+func (t *blockTarget) setFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&t.service, "service", "", "only block operations on this service")
+	f.StringVar(&t.machine, "machine", "", "only block operations on this machine")
+}
+
+// entityTag returns the tag of the service or machine the block is
+// for, or an empty string if it is for the whole environment.
+func (t *blockTarget) entityTag() (string, error) {
+	switch {
+	case t.service != "" && t.machine != "":
+		return "", errors.New("cannot specify both --service and --machine")
+	case t.service != "":
+		if !names.IsValidService(t.service) {
+			return "", errors.Errorf("invalid service name %q", t.service)
+		}
+		return names.NewServiceTag(t.service).String(), nil
+	case t.machine != "":
+		if !names.IsValidMachine(t.machine) {
+			return "", errors.Errorf("invalid machine id %q", t.machine)
+		}
+		return names.NewMachineTag(t.machine).String(), nil
+	}
+	return "", nil
+}
+
 // getBlockAPI returns a block api for block manipulation.
 func getBlockAPI(c *envcmd.EnvCommandBase) (*apiblock.Client, error) {
 	root, err := c.NewAPIRoot()
@@ -100,6 +135,9 @@ To unblock removal, run
 
     juju unblock remove-object
 
+If the block is for a single service or machine, add the --service or
+--machine option shown by "juju block list".
+
 `
 var destroyMsg = `
 destroy-environment operation has been blocked for the current environment.
@@ -114,4 +152,7 @@ To unblock changes, run
 
     juju unblock all-changes
 
+If the block is for a single service or machine, add the --service or
+--machine option shown by "juju block list".
+
 `
@@ -18,6 +18,8 @@ import (
 type UnblockCommand struct {
 	envcmd.EnvCommandBase
 	operation string
+	target    blockTarget
+	tag       string
 }
 
 var (
@@ -31,6 +33,9 @@ must be manually unblocked to proceed.
 
 Some commands offer a --force option that can be used to bypass a block.
 
+Blocks for a single service or machine are unblocked by giving the same
+--service or --machine option they were switched on with.
+
 Commands that can be unblocked are grouped based on logical operations as follows:
 
 destroy-environment includes command:
@@ -85,6 +90,9 @@ Examples:
    To allow changes to the environment:
    juju unblock all-changes
 
+   To allow the mysql service to be removed:
+   juju unblock remove-object --service mysql
+
 See Also:
    juju help block
 `
@@ -133,12 +141,23 @@ func (c *UnblockCommand) Init(args []string) error {
 		return errors.Trace(errors.New("can only specify block type"))
 	}
 
-	return c.assignValidOperation("unblock", args)
+	if err := c.assignValidOperation("unblock", args); err != nil {
+		return err
+	}
+	var err error
+	if c.tag, err = c.target.entityTag(); err != nil {
+		return errors.Trace(err)
+	}
+	if c.tag != "" && c.operation == "destroy-environment" {
+		return errors.New("cannot unblock destroy-environment for a service or machine")
+	}
+	return nil
 }
 
 // SetFlags implements Command.SetFlags.
 func (c *UnblockCommand) SetFlags(f *gnuflag.FlagSet) {
 	c.EnvCommandBase.SetFlags(f)
+	c.target.setFlags(f)
 }
 
 // Run unblocks previously blocked commands.
@@ -150,13 +169,13 @@ func (c *UnblockCommand) Run(_ *cmd.Context) error {
 	}
 	defer client.Close()
 
-	return client.SwitchBlockOff(TypeFromOperation(c.operation))
+	return client.SwitchEntityBlockOff(TypeFromOperation(c.operation), c.tag)
 }
 
 // UnblockClientAPI defines the client API methods that unblock command uses.
 type UnblockClientAPI interface {
 	Close() error
-	SwitchBlockOff(blockType string) error
+	SwitchEntityBlockOff(blockType, tag string) error
 }
 
 var getUnblockClientAPI = func(p *UnblockCommand) (UnblockClientAPI, error) {
@@ -69,3 +69,30 @@ func (s *UnblockCommandSuite) TestUnblockCmdValidDestroyEnvOperationUpperCase(c
 func (s *UnblockCommandSuite) TestUnblockCmdValidDestroyEnvOperation(c *gc.C) {
 	s.assertRunUnblock(c, "destroy-environment")
 }
+
+func (s *UnblockCommandSuite) TestUnblockService(c *gc.C) {
+	err := runUnblockCommand(c, "remove-object", "--service", "mysql")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mockClient.BlockType, gc.Equals, block.TypeFromOperation("remove-object"))
+	c.Assert(s.mockClient.Tag, gc.Equals, "service-mysql")
+}
+
+func (s *UnblockCommandSuite) TestUnblockMachine(c *gc.C) {
+	err := runUnblockCommand(c, "all-changes", "--machine", "0/lxc/1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mockClient.BlockType, gc.Equals, block.TypeFromOperation("all-changes"))
+	c.Assert(s.mockClient.Tag, gc.Equals, "machine-0-lxc-1")
+}
+
+func (s *UnblockCommandSuite) TestUnblockEnvironment(c *gc.C) {
+	s.mockClient.Tag = "service-mysql"
+	s.assertRunUnblock(c, "remove-object")
+	c.Assert(s.mockClient.Tag, gc.Equals, "")
+}
+
+func (s *UnblockCommandSuite) TestUnblockTargetErrors(c *gc.C) {
+	s.assertErrorMatches(c, runUnblockCommand(c, "remove-object", "--service", "mysql", "--machine", "0"),
+		`cannot specify both --service and --machine`)
+	s.assertErrorMatches(c, runUnblockCommand(c, "destroy-environment", "--machine", "0"),
+		`cannot unblock destroy-environment for a service or machine`)
+}
@@ -0,0 +1,200 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/api/auditlog"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/common"
+)
+
+// defaultAuditRecordCount is the default number of the newest audit
+// records to display.
+const defaultAuditRecordCount = 20
+
+const auditLogDoc = `
+Show the audit log, which records the API calls that users have made to
+change the environment, oldest first. Any secrets in the arguments of
+the calls, such as passwords, are not shown.
+
+Records may be selected by the user that made the call, the entity the
+call refers to, given as a tag such as "unit-wordpress-0", and the time
+the call was made. Times are given in RFC3339 format, such as
+2015-06-24T07:47:00Z.
+
+Only environment administrators may see the audit log.
+
+Examples:
+    # Show the last 20 calls made.
+    juju audit-log
+
+    # Show the calls bob made yesterday to change wordpress.
+    juju audit-log --user bob --entity service-wordpress \
+        --from 2015-06-23T00:00:00Z --to 2015-06-24T00:00:00Z
+`
+
+// AuditLogCommand shows the records of the API calls users have made
+// to change the environment.
+type AuditLogCommand struct {
+	envcmd.EnvCommandBase
+	out     cmd.Output
+	user    string
+	entity  string
+	from    string
+	to      string
+	isoTime bool
+	filter  params.AuditRecordFilter
+}
+
+// Info implements Command.Info.
+func (c *AuditLogCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "audit-log",
+		Purpose: "show the API calls made to change the environment",
+		Doc:     auditLogDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *AuditLogCommand) SetFlags(f *gnuflag.FlagSet) {
+	f.StringVar(&c.user, "user", "", "only show calls made by this user")
+	f.StringVar(&c.entity, "entity", "", "only show calls that refer to the entity with this tag")
+	f.StringVar(&c.from, "from", "", "only show calls made at or after this time")
+	f.StringVar(&c.to, "to", "", "only show calls made before this time")
+	f.IntVar(&c.filter.Limit, "n", defaultAuditRecordCount, "show at most this many of the newest calls, or all of them if 0")
+	f.IntVar(&c.filter.Limit, "limit", defaultAuditRecordCount, "")
+	f.BoolVar(&c.isoTime, "utc", false, "display time as UTC in RFC3339 format")
+	c.out.AddFlags(f, "tabular", map[string]cmd.Formatter{
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+		"tabular": c.formatTabular,
+	})
+}
+
+// Init implements Command.Init.
+func (c *AuditLogCommand) Init(args []string) error {
+	if c.user != "" {
+		if !names.IsValidUser(c.user) {
+			return errors.Errorf("invalid user name %q", c.user)
+		}
+		c.filter.User = names.NewUserTag(c.user).String()
+	}
+	if c.entity != "" {
+		if _, err := names.ParseTag(c.entity); err != nil {
+			return errors.Errorf("invalid entity tag %q", c.entity)
+		}
+		c.filter.Entity = c.entity
+	}
+	var err error
+	if c.filter.From, err = parseAuditTime("--from", c.from); err != nil {
+		return err
+	}
+	if c.filter.To, err = parseAuditTime("--to", c.to); err != nil {
+		return err
+	}
+	if c.filter.Limit < 0 {
+		return errors.Errorf("invalid --limit %d, expected a number of calls", c.filter.Limit)
+	}
+	return cmd.CheckEmpty(args)
+}
+
+func parseAuditTime(flag, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errors.Errorf("invalid %s time %q, expected a time such as 2015-06-24T07:47:00Z", flag, value)
+	}
+	return t, nil
+}
+
+// AuditLogAPI defines the API methods that the audit-log command uses.
+type AuditLogAPI interface {
+	Records(filter params.AuditRecordFilter) ([]params.AuditRecord, error)
+	Close() error
+}
+
+var getAuditLogAPI = func(c *AuditLogCommand) (AuditLogAPI, error) {
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return auditlog.NewClient(root), nil
+}
+
+// AuditRecord defines the serialization behaviour of an audit record.
+type AuditRecord struct {
+	Time     string   `yaml:"time" json:"time"`
+	User     string   `yaml:"user" json:"user"`
+	Call     string   `yaml:"call" json:"call"`
+	Entities []string `yaml:"entities,omitempty" json:"entities,omitempty"`
+	Args     string   `yaml:"args,omitempty" json:"args,omitempty"`
+	Error    string   `yaml:"error,omitempty" json:"error,omitempty"`
+}
+
+// Run implements Command.Run.
+func (c *AuditLogCommand) Run(ctx *cmd.Context) error {
+	client, err := getAuditLogAPI(c)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	records, err := client.Records(c.filter)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	output := make([]AuditRecord, len(records))
+	for i, record := range records {
+		user := record.User
+		if tag, err := names.ParseUserTag(user); err == nil {
+			user = tag.Username()
+		}
+		output[i] = AuditRecord{
+			Time:     common.FormatTime(&record.Time, c.isoTime),
+			User:     user,
+			Call:     fmt.Sprintf("%s(%d).%s", record.Facade, record.Version, record.Method),
+			Entities: record.Entities,
+			Args:     record.Args,
+			Error:    record.Error,
+		}
+	}
+	return c.out.Write(ctx, output)
+}
+
+func (c *AuditLogCommand) formatTabular(value interface{}) ([]byte, error) {
+	records, ok := value.([]AuditRecord)
+	if !ok {
+		return nil, errors.Errorf("expected value of type %T, got %T", records, value)
+	}
+	var out bytes.Buffer
+	const (
+		// To format things into columns.
+		minwidth = 0
+		tabwidth = 1
+		padding  = 2
+		padchar  = ' '
+		flags    = 0
+	)
+	tw := tabwriter.NewWriter(&out, minwidth, tabwidth, padding, padchar, flags)
+	fmt.Fprintf(tw, "TIME\tUSER\tCALL\tENTITIES\tERROR\n")
+	for _, record := range records {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n",
+			record.Time, record.User, record.Call, strings.Join(record.Entities, ","), record.Error)
+	}
+	tw.Flush()
+	return out.Bytes(), nil
+}
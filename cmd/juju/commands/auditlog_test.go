@@ -0,0 +1,162 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package commands
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/testing"
+)
+
+type AuditLogSuite struct {
+	testing.FakeJujuHomeSuite
+}
+
+var _ = gc.Suite(&AuditLogSuite{})
+
+func (s *AuditLogSuite) TestArgParsing(c *gc.C) {
+	from := time.Date(2015, 6, 23, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2015, 6, 24, 0, 0, 0, 0, time.UTC)
+	for i, test := range []struct {
+		args     []string
+		expected params.AuditRecordFilter
+		errMatch string
+	}{{
+		expected: params.AuditRecordFilter{Limit: 20},
+	}, {
+		args:     []string{"--user", "bob"},
+		expected: params.AuditRecordFilter{User: "user-bob", Limit: 20},
+	}, {
+		args:     []string{"--user", "bob@ldap"},
+		expected: params.AuditRecordFilter{User: "user-bob@ldap", Limit: 20},
+	}, {
+		args:     []string{"--user", "not a user"},
+		errMatch: `invalid user name "not a user"`,
+	}, {
+		args:     []string{"--entity", "unit-wordpress-0"},
+		expected: params.AuditRecordFilter{Entity: "unit-wordpress-0", Limit: 20},
+	}, {
+		args:     []string{"--entity", "wordpress/0"},
+		errMatch: `invalid entity tag "wordpress/0"`,
+	}, {
+		args:     []string{"--from", "2015-06-23T00:00:00Z", "--to", "2015-06-24T00:00:00Z"},
+		expected: params.AuditRecordFilter{From: from, To: to, Limit: 20},
+	}, {
+		args:     []string{"--from", "yesterday"},
+		errMatch: `invalid --from time "yesterday", expected a time such as 2015-06-24T07:47:00Z`,
+	}, {
+		args:     []string{"-n", "0"},
+		expected: params.AuditRecordFilter{},
+	}, {
+		args:     []string{"--limit", "-1"},
+		errMatch: `invalid --limit -1, expected a number of calls`,
+	}, {
+		args:     []string{"extra"},
+		errMatch: `unrecognized args: \["extra"\]`,
+	}} {
+		c.Logf("test %d", i)
+		command := &AuditLogCommand{}
+		err := testing.InitCommand(envcmd.Wrap(command), test.args)
+		if test.errMatch == "" {
+			c.Check(err, jc.ErrorIsNil)
+			c.Check(command.filter, jc.DeepEquals, test.expected)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.errMatch)
+		}
+	}
+}
+
+func (s *AuditLogSuite) patchAPI(fake *fakeAuditLogAPI) {
+	s.PatchValue(&getAuditLogAPI, func(_ *AuditLogCommand) (AuditLogAPI, error) {
+		return fake, nil
+	})
+}
+
+var auditRecords = []params.AuditRecord{{
+	Time:     time.Date(2015, 6, 23, 7, 47, 0, 0, time.UTC),
+	User:     "user-bob@local",
+	Facade:   "Client",
+	Version:  1,
+	Method:   "ServiceDestroy",
+	Entities: []string{"service-wordpress"},
+	Args:     `{"ServiceName":"wordpress"}`,
+}, {
+	Time:     time.Date(2015, 6, 23, 7, 48, 0, 0, time.UTC),
+	User:     "user-alice@ldap",
+	Facade:   "Client",
+	Version:  1,
+	Method:   "DestroyMachines",
+	Entities: []string{"machine-1", "machine-2"},
+	Args:     `{"MachineNames":["1","2"]}`,
+	Error:    "machine 1 has unit wordpress/0 assigned",
+}}
+
+func (s *AuditLogSuite) TestFilterPassed(c *gc.C) {
+	fake := &fakeAuditLogAPI{}
+	s.patchAPI(fake)
+	_, err := testing.RunCommand(c, envcmd.Wrap(&AuditLogCommand{}),
+		"--user", "bob", "--entity", "service-wordpress", "--limit", "5",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(fake.filter, jc.DeepEquals, params.AuditRecordFilter{
+		User:   "user-bob",
+		Entity: "service-wordpress",
+		Limit:  5,
+	})
+}
+
+func (s *AuditLogSuite) TestTabularOutput(c *gc.C) {
+	s.patchAPI(&fakeAuditLogAPI{records: auditRecords})
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&AuditLogCommand{}), "--utc")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, ""+
+		"TIME                  USER        CALL                       ENTITIES             ERROR\n"+
+		"2015-06-23 07:47:00Z  bob@local   Client(1).ServiceDestroy   service-wordpress    \n"+
+		"2015-06-23 07:48:00Z  alice@ldap  Client(1).DestroyMachines  machine-1,machine-2  machine 1 has unit wordpress/0 assigned\n",
+	)
+}
+
+func (s *AuditLogSuite) TestYAMLOutput(c *gc.C) {
+	s.patchAPI(&fakeAuditLogAPI{records: auditRecords[:1]})
+	ctx, err := testing.RunCommand(c, envcmd.Wrap(&AuditLogCommand{}), "--utc", "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, ""+
+		"- time: 2015-06-23 07:47:00Z\n"+
+		"  user: bob@local\n"+
+		"  call: Client(1).ServiceDestroy\n"+
+		"  entities:\n"+
+		"  - service-wordpress\n"+
+		"  args: '{\"ServiceName\":\"wordpress\"}'\n",
+	)
+}
+
+func (s *AuditLogSuite) TestAPIError(c *gc.C) {
+	s.patchAPI(&fakeAuditLogAPI{err: errors.New("permission denied")})
+	_, err := testing.RunCommand(c, envcmd.Wrap(&AuditLogCommand{}))
+	c.Assert(err, gc.ErrorMatches, "permission denied")
+}
+
+type fakeAuditLogAPI struct {
+	records []params.AuditRecord
+	filter  params.AuditRecordFilter
+	err     error
+}
+
+func (fake *fakeAuditLogAPI) Records(filter params.AuditRecordFilter) ([]params.AuditRecord, error) {
+	if fake.err != nil {
+		return nil, fake.err
+	}
+	fake.filter = filter
+	return fake.records, nil
+}
+
+func (fake *fakeAuditLogAPI) Close() error {
+	return nil
+}
@@ -6,19 +6,26 @@ package commands
 import (
 	"fmt"
 	"io"
+	"regexp"
+	"time"
 
 	"github.com/juju/cmd"
 	"github.com/juju/loggo"
+	"github.com/juju/utils/set"
 	"launchpad.net/gnuflag"
 
 	"github.com/juju/juju/api"
+	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/cmd/envcmd"
+	"github.com/juju/juju/cmd/juju/common"
 )
 
 type DebugLogCommand struct {
 	envcmd.EnvCommandBase
 
 	level  string
+	since  string
+	until  string
 	params api.DebugLogParams
 }
 
@@ -31,6 +38,27 @@ const defaultLineCount = 10
 const debuglogDoc = `
 Stream the consolidated debug log file. This file contains the log messages
 from all nodes in the environment.
+
+The messages may be limited to those logged within a time range with
+--since and --until, which take either a time in RFC3339 format, such as
+2015-06-24T07:47:00Z, or a duration before now, such as 90m. With
+--no-tail, the command exits once the messages already logged have been
+shown, rather than waiting for more. --message only shows the messages
+matching a regular expression.
+
+With --format=json, each message is shown as a line holding a JSON
+object with the fields entity, timestamp, level, module, location and
+message. --fields limits the objects to the fields given.
+
+The time range, --message, --no-tail and --format=json need the
+environment to store its logs in the database.
+
+Examples:
+    # Show the errors logged by hooks in the last hour, then exit.
+    juju debug-log --since 1h --message 'hook .* failed' --no-tail
+
+    # Stream the messages of machine 0 as JSON objects.
+    juju debug-log -i machine-0 --format json --fields timestamp,level,message
 `
 
 func (c *DebugLogCommand) Info() *cmd.Info {
@@ -56,6 +84,14 @@ func (c *DebugLogCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.UintVar(&c.params.Backlog, "lines", defaultLineCount, "")
 	f.UintVar(&c.params.Limit, "limit", 0, "show at most this many lines")
 	f.BoolVar(&c.params.Replay, "replay", false, "start filtering from the start")
+
+	f.StringVar(&c.since, "since", "", "only show log messages logged at or after this time, or this long ago")
+	f.StringVar(&c.until, "until", "", "only show log messages logged before this time, or this long ago")
+	f.StringVar(&c.params.MessageRegexp, "message", "", "only show log messages matching this regular expression")
+	f.BoolVar(&c.params.NoTail, "no-tail", false, "exit once the messages already logged have been shown")
+
+	f.StringVar(&c.params.Format, "format", "text", "show log messages in this format, one of [text, json]")
+	f.Var(cmd.NewStringsValue(nil, &c.params.Fields), "fields", "only show these fields of the JSON log messages")
 }
 
 func (c *DebugLogCommand) Init(args []string) error {
@@ -67,9 +103,52 @@ func (c *DebugLogCommand) Init(args []string) error {
 		}
 		c.params.Level = level
 	}
+	now := time.Now()
+	var err error
+	if c.params.StartTime, err = common.ParseTime("--since", c.since, now); err != nil {
+		return err
+	}
+	if c.params.EndTime, err = common.ParseTime("--until", c.until, now); err != nil {
+		return err
+	}
+	if c.since != "" && c.until != "" && !c.params.EndTime.After(c.params.StartTime) {
+		return fmt.Errorf("--until %s is not after --since %s", c.until, c.since)
+	}
+	if c.params.MessageRegexp != "" {
+		if _, err := regexp.Compile(c.params.MessageRegexp); err != nil {
+			return fmt.Errorf("--message value %q is not a valid regular expression: %v", c.params.MessageRegexp, err)
+		}
+	}
+	if err := c.checkFormat(); err != nil {
+		return err
+	}
 	return cmd.CheckEmpty(args)
 }
 
+// checkFormat checks the requested output format and the fields
+// selected from it.
+func (c *DebugLogCommand) checkFormat() error {
+	switch c.params.Format {
+	case "text":
+		// Text is the server's default format, so it is not sent, for
+		// the sake of servers that store their logs in files.
+		c.params.Format = ""
+		if len(c.params.Fields) > 0 {
+			return fmt.Errorf("--fields is only supported with --format=json")
+		}
+	case "json":
+		validFields := set.NewStrings(params.DebugLogFields...)
+		for _, field := range c.params.Fields {
+			if !validFields.Contains(field) {
+				return fmt.Errorf("--fields value %q is not one of %q", field, params.DebugLogFields)
+			}
+		}
+	default:
+		return fmt.Errorf("--format value %q is not one of %q, %q", c.params.Format, "text", "json")
+	}
+	return nil
+}
+
 type DebugLogAPI interface {
 	WatchDebugLog(params api.DebugLogParams) (io.ReadCloser, error)
 	Close() error
@@ -7,6 +7,7 @@ import (
 	"io"
 	"io/ioutil"
 	"strings"
+	"time"
 
 	"github.com/juju/loggo"
 	jc "github.com/juju/testing/checkers"
@@ -85,6 +86,59 @@ func (s *DebugLogSuite) TestArgParsing(c *gc.C) {
 				Backlog: 10,
 				Limit:   100,
 			},
+		}, {
+			args: []string{"--since", "2015-06-24T07:47:00Z", "--until", "2015-06-24T08:47:00Z"},
+			expected: api.DebugLogParams{
+				Backlog:   10,
+				StartTime: time.Date(2015, 6, 24, 7, 47, 0, 0, time.UTC),
+				EndTime:   time.Date(2015, 6, 24, 8, 47, 0, 0, time.UTC),
+			},
+		}, {
+			args:     []string{"--since", "yesterday"},
+			errMatch: `--since value "yesterday" is not a time such as 2015-06-24T07:47:00Z or a duration such as 90m`,
+		}, {
+			args:     []string{"--until", "-1h"},
+			errMatch: `--until value "-1h" is not a time such as 2015-06-24T07:47:00Z or a duration such as 90m`,
+		}, {
+			args:     []string{"--since", "1h", "--until", "2h"},
+			errMatch: `--until 2h is not after --since 1h`,
+		}, {
+			args: []string{"--message", "hook .* failed", "--no-tail"},
+			expected: api.DebugLogParams{
+				Backlog:       10,
+				MessageRegexp: "hook .* failed",
+				NoTail:        true,
+			},
+		}, {
+			args:     []string{"--message", "(unclosed"},
+			errMatch: `--message value "\(unclosed" is not a valid regular expression: .*`,
+		}, {
+			args: []string{"--format", "text"},
+			expected: api.DebugLogParams{
+				Backlog: 10,
+			},
+		}, {
+			args: []string{"--format", "json"},
+			expected: api.DebugLogParams{
+				Backlog: 10,
+				Format:  "json",
+			},
+		}, {
+			args: []string{"--format", "json", "--fields", "timestamp,message"},
+			expected: api.DebugLogParams{
+				Backlog: 10,
+				Format:  "json",
+				Fields:  []string{"timestamp", "message"},
+			},
+		}, {
+			args:     []string{"--format", "yaml"},
+			errMatch: `--format value "yaml" is not one of "text", "json"`,
+		}, {
+			args:     []string{"--fields", "message"},
+			errMatch: `--fields is only supported with --format=json`,
+		}, {
+			args:     []string{"--format", "json", "--fields", "message,host"},
+			errMatch: `--fields value "host" is not one of \[.*\]`,
 		},
 	} {
 		c.Logf("test %v", i)
@@ -99,6 +153,18 @@ func (s *DebugLogSuite) TestArgParsing(c *gc.C) {
 	}
 }
 
+func (s *DebugLogSuite) TestDurationArgs(c *gc.C) {
+	command := &DebugLogCommand{}
+	before := time.Now()
+	err := testing.InitCommand(envcmd.Wrap(command), []string{"--since", "90m", "--until", "30m"})
+	c.Assert(err, jc.ErrorIsNil)
+	after := time.Now()
+	startTime, endTime := command.params.StartTime, command.params.EndTime
+	c.Assert(startTime.Before(before.Add(-90*time.Minute)), jc.IsFalse)
+	c.Assert(startTime.After(after.Add(-90*time.Minute)), jc.IsFalse)
+	c.Assert(endTime.Sub(startTime), gc.Equals, time.Hour)
+}
+
 func (s *DebugLogSuite) TestParamsPassed(c *gc.C) {
 	fake := &fakeDebugLogAPI{}
 	s.PatchValue(&getDebugLogAPI, func(_ *DebugLogCommand) (DebugLogAPI, error) {
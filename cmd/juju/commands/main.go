@@ -143,6 +143,7 @@ func registerCommands(r commandRegistry, ctx *cmd.Context) {
 	r.Register(wrapEnvCommand(&SSHCommand{}))
 	r.Register(wrapEnvCommand(&ResolvedCommand{}))
 	r.Register(wrapEnvCommand(&DebugLogCommand{}))
+	r.Register(wrapEnvCommand(&AuditLogCommand{}))
 	r.Register(wrapEnvCommand(&DebugHooksCommand{}))
 
 	// Configuration commands.
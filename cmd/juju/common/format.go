@@ -3,7 +3,10 @@
 
 package common
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // FormatTime returns a string with the local time formatted
 // in an arbitrary format used for status or and localized tz
@@ -21,3 +24,19 @@ func FormatTime(t *time.Time, formatISO bool) string {
 	// Otherwise use local time.
 	return t.Local().Format("02 Jan 2006 15:04:05Z07:00")
 }
+
+// ParseTime parses the value of a time flag, which is either a time in
+// RFC3339 format or a duration before now. An empty value gives the
+// zero time.
+func ParseTime(flag, value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("%s value %q is not a time such as 2015-06-24T07:47:00Z or a duration such as 90m", flag, value)
+}
@@ -34,6 +34,7 @@ func NewSuperCommand() cmd.Command {
 	environmentCmd.Register(envcmd.Wrap(&RetryProvisioningCommand{}))
 	environmentCmd.Register(envcmd.Wrap(&EnvSetConstraintsCommand{}))
 	environmentCmd.Register(envcmd.Wrap(&EnvGetConstraintsCommand{}))
+	environmentCmd.Register(envcmd.Wrap(&OrphansCommand{}))
 
 	if featureflag.Enabled(feature.JES) {
 		environmentCmd.Register(envcmd.Wrap(&ShareCommand{}))
@@ -29,6 +29,7 @@ var expectedCommmandNames = []string{
 	"get-constraints",
 	"help",
 	"jenv",
+	"orphans",
 	"retry-provisioning",
 	"set",
 	"set-constraints",
@@ -58,3 +58,10 @@ func NewDestroyCommand(api DestroyEnvironmentAPI) *DestroyCommand {
 		api: api,
 	}
 }
+
+// NewOrphansCommand returns an OrphansCommand with the api provided as specified.
+func NewOrphansCommand(api OrphansAPI) *OrphansCommand {
+	return &OrphansCommand{
+		api: api,
+	}
+}
@@ -31,6 +31,7 @@ type fakeEnvAPI struct {
 	err         error
 	keys        []string
 	addUsers    []names.UserTag
+	access      string
 	removeUsers []names.UserTag
 }
 
@@ -52,8 +53,9 @@ func (f *fakeEnvAPI) EnvironmentUnset(keys ...string) error {
 	return f.err
 }
 
-func (f *fakeEnvAPI) ShareEnvironment(users ...names.UserTag) error {
+func (f *fakeEnvAPI) ShareEnvironmentWithAccess(access string, users ...names.UserTag) error {
 	f.addUsers = users
+	f.access = access
 	return f.err
 }
 
@@ -0,0 +1,132 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environment
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/api/orphans"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+const orphansCommandDoc = `
+List the provider resources of the environment, such as servers and
+drives, that no longer back any machine or volume. They are usually
+left behind by failed removals.
+
+The state server looks for orphaned resources periodically. When the
+environment's provisioner-harvest-mode is "unknown" or "all" it removes
+them; otherwise it only logs them. This command previews what would be
+removed.
+`
+
+// OrphansCommand lists the orphaned provider resources of the environment.
+type OrphansCommand struct {
+	envcmd.EnvCommandBase
+	out cmd.Output
+	api OrphansAPI
+}
+
+// OrphansAPI defines the methods on the orphans API that the orphans
+// command calls.
+type OrphansAPI interface {
+	Close() error
+	List() ([]params.Orphan, error)
+}
+
+// OrphanInfo defines the serialization behaviour of an orphaned resource.
+type OrphanInfo struct {
+	Kind string `yaml:"kind" json:"kind"`
+	Id   string `yaml:"id" json:"id"`
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+	Zone string `yaml:"zone,omitempty" json:"zone,omitempty"`
+}
+
+func (c *OrphansCommand) getAPI() (OrphansAPI, error) {
+	if c.api != nil {
+		return c.api, nil
+	}
+	root, err := c.NewAPIRoot()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return orphans.NewClient(root), nil
+}
+
+// Info implements Command.Info.
+func (c *OrphansCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "orphans",
+		Purpose: "lists provider resources no longer used by the environment",
+		Doc:     orphansCommandDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *OrphansCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "tabular", map[string]cmd.Formatter{
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+		"tabular": c.formatTabular,
+	})
+}
+
+// Init implements Command.Init.
+func (c *OrphansCommand) Init(args []string) error {
+	return cmd.CheckEmpty(args)
+}
+
+// Run implements Command.Run.
+func (c *OrphansCommand) Run(ctx *cmd.Context) error {
+	client, err := c.getAPI()
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	result, err := client.List()
+	if err != nil {
+		return err
+	}
+	if len(result) == 0 {
+		fmt.Fprintf(ctx.Stderr, "no orphaned resources found\n")
+		return nil
+	}
+	output := make([]OrphanInfo, len(result))
+	for i, o := range result {
+		output[i] = OrphanInfo{Kind: o.Kind, Id: o.Id, Name: o.Name, Zone: o.Zone}
+	}
+	return c.out.Write(ctx, output)
+}
+
+// formatTabular takes an interface{} to adhere to the cmd.Formatter interface
+func (c *OrphansCommand) formatTabular(value interface{}) ([]byte, error) {
+	orphans, ok := value.([]OrphanInfo)
+	if !ok {
+		return nil, errors.Errorf("expected value of type %T, got %T", orphans, value)
+	}
+	var out bytes.Buffer
+	const (
+		// To format things into columns.
+		minwidth = 0
+		tabwidth = 1
+		padding  = 2
+		padchar  = ' '
+		flags    = 0
+	)
+	tw := tabwriter.NewWriter(&out, minwidth, tabwidth, padding, padchar, flags)
+	fmt.Fprintf(tw, "KIND\tID\tNAME\tZONE\n")
+	for _, o := range orphans {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", o.Kind, o.Id, o.Name, o.Zone)
+	}
+	tw.Flush()
+	return out.Bytes(), nil
+}
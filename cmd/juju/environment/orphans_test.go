@@ -0,0 +1,77 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environment_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/environment"
+	"github.com/juju/juju/testing"
+)
+
+type OrphansCommandSuite struct {
+	testing.FakeJujuHomeSuite
+	fake *fakeOrphansClient
+}
+
+var _ = gc.Suite(&OrphansCommandSuite{})
+
+type fakeOrphansClient struct {
+	orphans []params.Orphan
+}
+
+func (f *fakeOrphansClient) Close() error {
+	return nil
+}
+
+func (f *fakeOrphansClient) List() ([]params.Orphan, error) {
+	return f.orphans, nil
+}
+
+func (s *OrphansCommandSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.fake = &fakeOrphansClient{orphans: []params.Orphan{
+		{Kind: "server", Id: "2f9b8e5c", Name: "juju-env-2", Zone: "zrh"},
+		{Kind: "drive", Id: "6c1c4e0a", Name: "juju-env-3", Zone: "lvs"},
+	}}
+}
+
+func (s *OrphansCommandSuite) TestOrphans(c *gc.C) {
+	context, err := testing.RunCommand(c, environment.NewOrphansCommand(s.fake))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(context), gc.Equals, ""+
+		"KIND    ID        NAME        ZONE\n"+
+		"server  2f9b8e5c  juju-env-2  zrh\n"+
+		"drive   6c1c4e0a  juju-env-3  lvs\n"+
+		"\n")
+}
+
+func (s *OrphansCommandSuite) TestOrphansFormatYaml(c *gc.C) {
+	context, err := testing.RunCommand(c, environment.NewOrphansCommand(s.fake), "--format", "yaml")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(context), gc.Equals, ""+
+		"- kind: server\n"+
+		"  id: 2f9b8e5c\n"+
+		"  name: juju-env-2\n"+
+		"  zone: zrh\n"+
+		"- kind: drive\n"+
+		"  id: 6c1c4e0a\n"+
+		"  name: juju-env-3\n"+
+		"  zone: lvs\n")
+}
+
+func (s *OrphansCommandSuite) TestNoOrphans(c *gc.C) {
+	s.fake.orphans = nil
+	context, err := testing.RunCommand(c, environment.NewOrphansCommand(s.fake))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(context), gc.Equals, "")
+	c.Assert(testing.Stderr(context), gc.Equals, "no orphaned resources found\n")
+}
+
+func (s *OrphansCommandSuite) TestUnrecognizedArg(c *gc.C) {
+	_, err := testing.RunCommand(c, environment.NewOrphansCommand(s.fake), "whoops")
+	c.Assert(err, gc.ErrorMatches, `unrecognized args: \["whoops"\]`)
+}
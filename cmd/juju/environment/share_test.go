@@ -40,6 +40,15 @@ func (s *shareSuite) TestInit(c *gc.C) {
 
 	err = testing.InitCommand(shareCmd, []string{"not valid/0"})
 	c.Assert(err, gc.ErrorMatches, `invalid username: "not valid/0"`)
+
+	shareCmd = &environment.ShareCommand{}
+	err = testing.InitCommand(shareCmd, []string{"--access", "read", "sam"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(shareCmd.Access, gc.Equals, "read")
+
+	shareCmd = &environment.ShareCommand{}
+	err = testing.InitCommand(shareCmd, []string{"--access", "superuser", "sam"})
+	c.Assert(err, gc.ErrorMatches, `invalid access "superuser", expected one of "read", "write" or "admin"`)
 }
 
 func (s *shareSuite) TestPassesValues(c *gc.C) {
@@ -49,6 +58,14 @@ func (s *shareSuite) TestPassesValues(c *gc.C) {
 	_, err := s.run(c, "sam", "ralph")
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(s.fake.addUsers, jc.DeepEquals, []names.UserTag{sam, ralph})
+	c.Assert(s.fake.access, gc.Equals, "")
+}
+
+func (s *shareSuite) TestPassesAccess(c *gc.C) {
+	_, err := s.run(c, "--access", "write", "sam")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.fake.addUsers, jc.DeepEquals, []names.UserTag{names.NewUserTag("sam")})
+	c.Assert(s.fake.access, gc.Equals, "write")
 }
 
 func (s *shareSuite) TestBlockShare(c *gc.C) {
@@ -5,11 +5,14 @@ package status
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
+	"github.com/juju/names"
 	"launchpad.net/gnuflag"
 
 	"github.com/juju/juju/apiserver/params"
@@ -24,43 +27,78 @@ type StatusHistoryCommand struct {
 	outputContent string
 	backlogSize   int
 	isoTime       bool
-	unitName      string
+	entityName    string
+	since         string
+	until         string
+	follow        bool
+
+	entityTag names.Tag
+	kind      params.HistoryKind
+	fromDate  time.Time
+	toDate    time.Time
 }
 
 var statusHistoryDoc = `
 This command will report the history of status changes for
-a given unit.
-The statuses for the unit workload and/or agent are available.
+a given unit, machine, service, volume or filesystem.
+
+A unit is given by name, a machine by id and a service by name.
+For volumes and filesystems, -type must be given with their id.
 -type supports:
     agent: will show statuses for the unit's agent
     workload: will show statuses for the unit's workload
     combined: will show agent and workload statuses combined
  and sorted by time of occurrence.
+    machine: will show statuses for the machine
+    service: will show statuses for the service
+    volume: will show statuses for the volume
+    filesystem: will show statuses for the filesystem
+Machines and services are recognised without -type.
+
+-since and -until take a time such as 2015-06-24T07:47:00Z or
+a duration before now such as 90m. With -follow, status changes
+are shown as they happen until interrupted.
+
+Examples:
+    # Show the last 20 statuses of a unit.
+    juju status-history mysql/0
+
+    # Show the statuses of a machine over the last day.
+    juju status-history -n 0 -since 24h 0
+
+    # Follow the statuses of a service.
+    juju status-history -follow mysql
+
+    # Show the statuses of a volume.
+    juju status-history -type volume 0/1
 `
 
 func (c *StatusHistoryCommand) Info() *cmd.Info {
 	return &cmd.Info{
 		Name:    "status-history",
-		Args:    "[-n N] <unit>",
-		Purpose: "output past statuses for a unit",
+		Args:    "[-n N] <unit|machine|service|volume|filesystem>",
+		Purpose: "output past statuses for a unit, machine, service, volume or filesystem",
 		Doc:     statusHistoryDoc,
 	}
 }
 
 func (c *StatusHistoryCommand) SetFlags(f *gnuflag.FlagSet) {
-	f.StringVar(&c.outputContent, "type", "combined", "type of statuses to be displayed [agent|workload|combined].")
-	f.IntVar(&c.backlogSize, "n", 20, "size of logs backlog.")
+	f.StringVar(&c.outputContent, "type", "combined", "type of statuses to be displayed [agent|workload|combined|machine|service|volume|filesystem].")
+	f.IntVar(&c.backlogSize, "n", 20, "size of logs backlog, or 0 for all.")
 	f.BoolVar(&c.isoTime, "utc", false, "display time as UTC in RFC3339 format")
+	f.StringVar(&c.since, "since", "", "only show statuses recorded at or after this time, or this long ago")
+	f.StringVar(&c.until, "until", "", "only show statuses recorded before this time, or this long ago")
+	f.BoolVar(&c.follow, "follow", false, "keep showing statuses as they are recorded")
 }
 
 func (c *StatusHistoryCommand) Init(args []string) error {
 	switch {
 	case len(args) > 1:
-		return errors.Errorf("unexpected arguments after unit name.")
+		return errors.Errorf("unexpected arguments after entity name.")
 	case len(args) == 0:
-		return errors.Errorf("unit name is missing.")
+		return errors.Errorf("entity name is missing.")
 	default:
-		c.unitName = args[0]
+		c.entityName = args[0]
 	}
 	// If use of ISO time not specified on command line,
 	// check env var.
@@ -73,47 +111,208 @@ func (c *StatusHistoryCommand) Init(args []string) error {
 			}
 		}
 	}
+	if c.backlogSize < 0 {
+		return errors.Errorf("invalid history size: %d", c.backlogSize)
+	}
+	now := time.Now()
+	var err error
+	if c.fromDate, err = common.ParseTime("--since", c.since, now); err != nil {
+		return err
+	}
+	if c.toDate, err = common.ParseTime("--until", c.until, now); err != nil {
+		return err
+	}
+	if c.since != "" && c.until != "" && !c.toDate.After(c.fromDate) {
+		return errors.Errorf("--until %s is not after --since %s", c.until, c.since)
+	}
+	if c.follow && c.until != "" {
+		return errors.Errorf("--follow cannot be used with --until")
+	}
+	return c.parseEntity()
+}
+
+// parseEntity works out the entity whose status history is shown, and
+// the kind of the statuses shown.
+func (c *StatusHistoryCommand) parseEntity() error {
 	kind := params.HistoryKind(c.outputContent)
+	name := c.entityName
 	switch kind {
 	case params.KindCombined, params.KindAgent, params.KindWorkload:
+		switch {
+		case names.IsValidUnit(name):
+			c.entityTag = names.NewUnitTag(name)
+			c.kind = kind
+			return nil
+		case kind != params.KindCombined:
+			return errors.Errorf("status type %q is only valid for units, not %q", kind, name)
+		case names.IsValidMachine(name):
+			c.entityTag = names.NewMachineTag(name)
+		case names.IsValidService(name):
+			c.entityTag = names.NewServiceTag(name)
+		default:
+			return errors.Errorf("%q is not a valid unit, machine or service", name)
+		}
 		return nil
-
+	case params.KindMachine:
+		if names.IsValidMachine(name) {
+			c.entityTag = names.NewMachineTag(name)
+			return nil
+		}
+	case params.KindService:
+		if names.IsValidService(name) {
+			c.entityTag = names.NewServiceTag(name)
+			return nil
+		}
+	case params.KindVolume:
+		if names.IsValidVolume(name) {
+			c.entityTag = names.NewVolumeTag(name)
+			return nil
+		}
+	case params.KindFilesystem:
+		if names.IsValidFilesystem(name) {
+			c.entityTag = names.NewFilesystemTag(name)
+			return nil
+		}
+	default:
+		return errors.Errorf("unexpected status type %q", c.outputContent)
 	}
-	return errors.Errorf("unexpected status type %q", c.outputContent)
+	return errors.Errorf("%q is not a valid %s", name, kind)
+}
+
+type statusHistoryAPI interface {
+	StatusHistory(args params.StatusHistoryArgs) (*params.StatusHistoryResult, error)
+	UnitStatusHistory(kind params.HistoryKind, unitName string, size int) (*params.UnitStatusHistory, error)
+	Close() error
 }
 
+var newAPIClientForStatusHistory = func(c *StatusHistoryCommand) (statusHistoryAPI, error) {
+	return c.NewAPIClient()
+}
+
+// statusHistoryPollInterval is how often the status history is polled
+// for new statuses when following it.
+var statusHistoryPollInterval = 5 * time.Second
+
 func (c *StatusHistoryCommand) Run(ctx *cmd.Context) error {
-	apiclient, err := c.NewAPIClient()
+	apiclient, err := newAPIClientForStatusHistory(c)
 	if err != nil {
 		return fmt.Errorf(connectionError, c.ConnectionName(), err)
 	}
 	defer apiclient.Close()
-	var statuses *params.UnitStatusHistory
-	kind := params.HistoryKind(c.outputContent)
-	statuses, err = apiclient.UnitStatusHistory(kind, c.unitName, c.backlogSize)
+
+	args := params.StatusHistoryArgs{
+		Tag:  c.entityTag.String(),
+		Kind: c.kind,
+		Size: c.backlogSize,
+	}
+	if !c.fromDate.IsZero() {
+		args.FromDate = &c.fromDate
+	}
+	if !c.toDate.IsZero() {
+		args.ToDate = &c.toDate
+	}
+	statuses, err := c.statusHistory(apiclient, args)
 	if err != nil {
-		if len(statuses.Statuses) == 0 {
+		return errors.Trace(err)
+	}
+	if len(statuses) == 0 && !c.follow {
+		return errors.Errorf("no status history available")
+	}
+	printer := newStatusHistoryPrinter(ctx.Stdout, c.isoTime)
+	printer.print(statuses)
+	if !c.follow {
+		return nil
+	}
+
+	interrupted := make(chan os.Signal, 1)
+	ctx.InterruptNotify(interrupted)
+	defer ctx.StopInterruptNotify(interrupted)
+	args.Size = 0
+	for {
+		if len(statuses) > 0 {
+			// Statuses are recorded with nanosecond precision.
+			from := statuses[len(statuses)-1].Since.Add(time.Nanosecond)
+			args.FromDate = &from
+		}
+		select {
+		case <-interrupted:
+			return nil
+		case <-time.After(statusHistoryPollInterval):
+		}
+		newStatuses, err := c.statusHistory(apiclient, args)
+		if err != nil {
 			return errors.Trace(err)
 		}
-		// Display any error, but continue to print status if some was returned
-		fmt.Fprintf(ctx.Stderr, "%v\n", err)
-	} else if len(statuses.Statuses) == 0 {
-		return errors.Errorf("no status history available")
+		if len(newStatuses) > 0 {
+			printer.print(newStatuses)
+			statuses = newStatuses
+		}
+	}
+}
+
+// statusHistory returns the statuses args select, falling back to the
+// UnitStatusHistory API call for servers that do not support the
+// StatusHistory call when it can give the same statuses.
+func (c *StatusHistoryCommand) statusHistory(apiclient statusHistoryAPI, args params.StatusHistoryArgs) ([]params.AgentStatus, error) {
+	result, err := apiclient.StatusHistory(args)
+	if err == nil {
+		return result.Statuses, nil
+	}
+	if !errors.IsNotImplemented(err) {
+		return nil, err
+	}
+	// The server only knows the UnitStatusHistory call.
+	if _, isUnit := c.entityTag.(names.UnitTag); !isUnit {
+		return nil, errors.Errorf("status history of %s not supported by the server", names.ReadableString(c.entityTag))
+	}
+	if c.follow || args.FromDate != nil || args.ToDate != nil {
+		return nil, errors.Errorf("--since, --until and --follow not supported by the server")
+	}
+	if args.Size == 0 {
+		return nil, errors.Errorf("-n 0 not supported by the server")
+	}
+	unitResult, err := apiclient.UnitStatusHistory(c.kind, c.entityTag.Id(), args.Size)
+	if err != nil {
+		return nil, err
+	}
+	return unitResult.Statuses, nil
+}
+
+// statusHistoryPrinter prints statuses as a table, widening its
+// columns as needed when more statuses are printed.
+type statusHistoryPrinter struct {
+	out           io.Writer
+	isoTime       bool
+	lengths       []int
+	headerPrinted bool
+}
+
+func newStatusHistoryPrinter(out io.Writer, isoTime bool) *statusHistoryPrinter {
+	return &statusHistoryPrinter{
+		out:     out,
+		isoTime: isoTime,
+		lengths: []int{1, 1, 1, 1},
+	}
+}
+
+func (p *statusHistoryPrinter) print(statuses []params.AgentStatus) {
+	var table [][]string
+	if !p.headerPrinted {
+		table = append(table, []string{"TIME", "TYPE", "STATUS", "MESSAGE"})
+		p.headerPrinted = true
+	}
+	for _, v := range statuses {
+		table = append(table, []string{common.FormatTime(v.Since, p.isoTime), string(v.Kind), string(v.Status), v.Info})
 	}
-	table := [][]string{{"TIME", "TYPE", "STATUS", "MESSAGE"}}
-	lengths := []int{1, 1, 1, 1}
-	for _, v := range statuses.Statuses {
-		fields := []string{common.FormatTime(v.Since, c.isoTime), string(v.Kind), string(v.Status), v.Info}
+	for _, fields := range table {
 		for k, v := range fields {
-			if len(v) > lengths[k] {
-				lengths[k] = len(v)
+			if len(v) > p.lengths[k] {
+				p.lengths[k] = len(v)
 			}
 		}
-		table = append(table, fields)
 	}
-	f := fmt.Sprintf("%%-%ds\t%%-%ds\t%%-%ds\t%%-%ds\n", lengths[0], lengths[1], lengths[2], lengths[3])
+	f := fmt.Sprintf("%%-%ds\t%%-%ds\t%%-%ds\t%%-%ds\n", p.lengths[0], p.lengths[1], p.lengths[2], p.lengths[3])
 	for _, v := range table {
-		fmt.Printf(f, v[0], v[1], v[2], v[3])
+		fmt.Fprintf(p.out, f, v[0], v[1], v[2], v[3])
 	}
-	return nil
 }
@@ -0,0 +1,263 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package status
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type StatusHistorySuite struct {
+	coretesting.FakeJujuHomeSuite
+	api *fakeStatusHistoryAPI
+}
+
+var _ = gc.Suite(&StatusHistorySuite{})
+
+func (s *StatusHistorySuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.api = &fakeStatusHistoryAPI{}
+	s.PatchValue(&newAPIClientForStatusHistory, func(*StatusHistoryCommand) (statusHistoryAPI, error) {
+		return s.api, nil
+	})
+	s.PatchValue(&statusHistoryPollInterval, time.Millisecond)
+}
+
+func (s *StatusHistorySuite) TestInit(c *gc.C) {
+	for i, test := range []struct {
+		args []string
+		tag  names.Tag
+		kind params.HistoryKind
+		err  string
+	}{{
+		args: []string{"mysql/0"},
+		tag:  names.NewUnitTag("mysql/0"),
+		kind: params.KindCombined,
+	}, {
+		args: []string{"--type", "agent", "mysql/0"},
+		tag:  names.NewUnitTag("mysql/0"),
+		kind: params.KindAgent,
+	}, {
+		args: []string{"0/lxc/1"},
+		tag:  names.NewMachineTag("0/lxc/1"),
+	}, {
+		args: []string{"mysql"},
+		tag:  names.NewServiceTag("mysql"),
+	}, {
+		args: []string{"--type", "machine", "0"},
+		tag:  names.NewMachineTag("0"),
+	}, {
+		args: []string{"--type", "volume", "0/1"},
+		tag:  names.NewVolumeTag("0/1"),
+	}, {
+		args: []string{"--type", "filesystem", "2"},
+		tag:  names.NewFilesystemTag("2"),
+	}, {
+		args: []string{},
+		err:  "entity name is missing.",
+	}, {
+		args: []string{"mysql/0", "mysql/1"},
+		err:  "unexpected arguments after entity name.",
+	}, {
+		args: []string{"--type", "agent", "0"},
+		err:  `status type "agent" is only valid for units, not "0"`,
+	}, {
+		args: []string{"--type", "volume", "mysql"},
+		err:  `"mysql" is not a valid volume`,
+	}, {
+		args: []string{"--type", "foo", "mysql/0"},
+		err:  `unexpected status type "foo"`,
+	}, {
+		args: []string{"mysql-0"},
+		err:  `"mysql-0" is not a valid unit, machine or service`,
+	}, {
+		args: []string{"-n", "-1", "mysql/0"},
+		err:  "invalid history size: -1",
+	}, {
+		args: []string{"--since", "yesterday", "mysql/0"},
+		err:  `--since value "yesterday" is not a time such as .* or a duration such as 90m`,
+	}, {
+		args: []string{"--since", "1h", "--until", "2h", "mysql/0"},
+		err:  "--until 2h is not after --since 1h",
+	}, {
+		args: []string{"--follow", "--until", "1h", "mysql/0"},
+		err:  "--follow cannot be used with --until",
+	}} {
+		c.Logf("test %d: %q", i, test.args)
+		command := &StatusHistoryCommand{}
+		err := coretesting.InitCommand(envcmd.Wrap(command), test.args)
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(command.entityTag, gc.Equals, test.tag)
+		c.Check(command.kind, gc.Equals, test.kind)
+	}
+}
+
+func (s *StatusHistorySuite) TestTimeRange(c *gc.C) {
+	command := &StatusHistoryCommand{}
+	err := coretesting.InitCommand(envcmd.Wrap(command), []string{
+		"--since", "2015-06-19T15:00:00Z", "--until", "2015-06-20T15:00:00Z", "mysql/0",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(command.fromDate, gc.Equals, time.Date(2015, 6, 19, 15, 0, 0, 0, time.UTC))
+	c.Check(command.toDate, gc.Equals, time.Date(2015, 6, 20, 15, 0, 0, 0, time.UTC))
+
+	before := time.Now()
+	err = coretesting.InitCommand(envcmd.Wrap(command), []string{"--since", "1h", "mysql/0"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(command.fromDate.After(before.Add(-time.Hour-time.Second)), jc.IsTrue)
+	c.Check(command.fromDate.Before(time.Now().Add(-time.Hour+time.Second)), jc.IsTrue)
+}
+
+func (s *StatusHistorySuite) TestRun(c *gc.C) {
+	t0 := time.Date(2015, 6, 19, 15, 0, 0, 0, time.UTC)
+	s.api.results = [][]params.AgentStatus{{
+		agentStatus(t0, params.KindMachine, params.StatusPending, ""),
+		agentStatus(t0.Add(time.Minute), params.KindMachine, params.StatusStarted, "up"),
+	}}
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&StatusHistoryCommand{}),
+		"--utc", "-n", "5", "--since", "2015-06-19T14:00:00Z", "0")
+	c.Assert(err, jc.ErrorIsNil)
+	since := t0.Add(-time.Hour)
+	c.Check(s.api.calls, jc.DeepEquals, []params.StatusHistoryArgs{{
+		Tag:      "machine-0",
+		Size:     5,
+		FromDate: &since,
+	}})
+	c.Check(coretesting.Stdout(ctx), gc.Equals, ""+
+		"TIME                \tTYPE   \tSTATUS \tMESSAGE\n"+
+		"2015-06-19 15:00:00Z\tmachine\tpending\t       \n"+
+		"2015-06-19 15:01:00Z\tmachine\tstarted\tup     \n")
+}
+
+func (s *StatusHistorySuite) TestRunNoHistory(c *gc.C) {
+	s.api.results = [][]params.AgentStatus{nil}
+	_, err := coretesting.RunCommand(c, envcmd.Wrap(&StatusHistoryCommand{}), "mysql")
+	c.Assert(err, gc.ErrorMatches, "no status history available")
+}
+
+func (s *StatusHistorySuite) TestFollow(c *gc.C) {
+	t0 := time.Date(2015, 6, 19, 15, 0, 0, 0, time.UTC)
+	s.api.results = [][]params.AgentStatus{{
+		agentStatus(t0, params.KindWorkload, params.StatusMaintenance, "installing"),
+	}, nil, {
+		agentStatus(t0.Add(time.Minute), params.KindAgent, params.StatusIdle, ""),
+		agentStatus(t0.Add(2*time.Minute), params.KindWorkload, params.StatusActive, "ready"),
+	}}
+	s.api.err = errors.New("connection lost")
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&StatusHistoryCommand{}), "--utc", "--follow", "mysql/0")
+	c.Assert(err, gc.ErrorMatches, "connection lost")
+
+	c.Assert(s.api.calls, gc.HasLen, 4)
+	c.Check(s.api.calls[0], jc.DeepEquals, params.StatusHistoryArgs{
+		Tag:  "unit-mysql-0",
+		Kind: params.KindCombined,
+		Size: 20,
+	})
+	// Following asks for the statuses after the last one shown.
+	from := t0.Add(time.Nanosecond)
+	c.Check(s.api.calls[1], jc.DeepEquals, params.StatusHistoryArgs{
+		Tag:      "unit-mysql-0",
+		Kind:     params.KindCombined,
+		FromDate: &from,
+	})
+	c.Check(s.api.calls[2], jc.DeepEquals, s.api.calls[1])
+	from = t0.Add(2*time.Minute + time.Nanosecond)
+	c.Check(s.api.calls[3].FromDate, jc.DeepEquals, &from)
+
+	c.Check(coretesting.Stdout(ctx), gc.Equals, ""+
+		"TIME                \tTYPE    \tSTATUS     \tMESSAGE   \n"+
+		"2015-06-19 15:00:00Z\tworkload\tmaintenance\tinstalling\n"+
+		"2015-06-19 15:01:00Z\tagent   \tidle       \t          \n"+
+		"2015-06-19 15:02:00Z\tworkload\tactive     \tready     \n")
+}
+
+func (s *StatusHistorySuite) TestFallBackToUnitStatusHistory(c *gc.C) {
+	t0 := time.Date(2015, 6, 19, 15, 0, 0, 0, time.UTC)
+	s.api.err = errors.NotImplementedf("StatusHistory")
+	s.api.unitResult = []params.AgentStatus{
+		agentStatus(t0, params.KindAgent, params.StatusIdle, ""),
+	}
+	ctx, err := coretesting.RunCommand(c, envcmd.Wrap(&StatusHistoryCommand{}), "--utc", "--type", "agent", "mysql/0")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.api.unitCalls, jc.DeepEquals, []string{"agent mysql/0 20"})
+	c.Check(coretesting.Stdout(ctx), gc.Equals, ""+
+		"TIME                \tTYPE \tSTATUS\tMESSAGE\n"+
+		"2015-06-19 15:00:00Z\tagent\tidle  \t       \n")
+}
+
+func (s *StatusHistorySuite) TestNoFallBackForTimeRange(c *gc.C) {
+	s.api.err = errors.NotImplementedf("StatusHistory")
+	_, err := coretesting.RunCommand(c, envcmd.Wrap(&StatusHistoryCommand{}), "--since", "1h", "mysql/0")
+	c.Assert(err, gc.ErrorMatches, "--since, --until and --follow not supported by the server")
+	c.Check(s.api.unitCalls, gc.HasLen, 0)
+}
+
+func (s *StatusHistorySuite) TestNoFallBackForFollow(c *gc.C) {
+	s.api.err = errors.NotImplementedf("StatusHistory")
+	_, err := coretesting.RunCommand(c, envcmd.Wrap(&StatusHistoryCommand{}), "--follow", "mysql/0")
+	c.Assert(err, gc.ErrorMatches, "--since, --until and --follow not supported by the server")
+	c.Check(s.api.unitCalls, gc.HasLen, 0)
+}
+
+func (s *StatusHistorySuite) TestNoFallBackForMachine(c *gc.C) {
+	s.api.err = errors.NotImplementedf("StatusHistory")
+	_, err := coretesting.RunCommand(c, envcmd.Wrap(&StatusHistoryCommand{}), "0")
+	c.Assert(err, gc.ErrorMatches, "status history of machine 0 not supported by the server")
+	c.Check(s.api.unitCalls, gc.HasLen, 0)
+}
+
+func agentStatus(since time.Time, kind params.HistoryKind, status params.Status, info string) params.AgentStatus {
+	return params.AgentStatus{
+		Since:  &since,
+		Kind:   kind,
+		Status: status,
+		Info:   info,
+	}
+}
+
+// fakeStatusHistoryAPI returns each of its results in turn from
+// StatusHistory, and then its error.
+type fakeStatusHistoryAPI struct {
+	calls      []params.StatusHistoryArgs
+	results    [][]params.AgentStatus
+	err        error
+	unitCalls  []string
+	unitResult []params.AgentStatus
+}
+
+func (f *fakeStatusHistoryAPI) StatusHistory(args params.StatusHistoryArgs) (*params.StatusHistoryResult, error) {
+	if args.FromDate != nil {
+		from := *args.FromDate
+		args.FromDate = &from
+	}
+	f.calls = append(f.calls, args)
+	if len(f.results) == 0 {
+		return &params.StatusHistoryResult{}, f.err
+	}
+	result := &params.StatusHistoryResult{Statuses: f.results[0]}
+	f.results = f.results[1:]
+	return result, nil
+}
+
+func (f *fakeStatusHistoryAPI) UnitStatusHistory(kind params.HistoryKind, unitName string, size int) (*params.UnitStatusHistory, error) {
+	f.unitCalls = append(f.unitCalls, fmt.Sprintf("%s %s %d", kind, unitName, size))
+	return &params.UnitStatusHistory{Statuses: f.unitResult}, nil
+}
+
+func (f *fakeStatusHistoryAPI) Close() error {
+	return nil
+}
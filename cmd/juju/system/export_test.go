@@ -13,6 +13,7 @@ import (
 var (
 	SetConfigSpecialCaseDefaults = setConfigSpecialCaseDefaults
 	UserCurrent                  = &userCurrent
+	ReadPassword                 = &readPassword
 )
 
 // NewListCommand returns a ListCommand with the configstore provided as specified.
@@ -119,3 +120,12 @@ func NewListBlocksCommand(api listBlocksAPI, apierr error) *ListBlocksCommand {
 		apierr: apierr,
 	}
 }
+
+// NewLogStatsCommand returns a LogStatsCommand with the systemmanager
+// endpoint mocked out.
+func NewLogStatsCommand(api logStatsAPI, apierr error) *LogStatsCommand {
+	return &LogStatsCommand{
+		api:    api,
+		apierr: apierr,
+	}
+}
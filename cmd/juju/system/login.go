@@ -4,11 +4,14 @@
 package system
 
 import (
+	"fmt"
+
 	"github.com/juju/cmd"
 	"github.com/juju/errors"
 	"github.com/juju/juju/api/usermanager"
 	"github.com/juju/names"
 	"github.com/juju/utils"
+	"github.com/juju/utils/readpass"
 	goyaml "gopkg.in/yaml.v1"
 	"launchpad.net/gnuflag"
 
@@ -62,6 +65,10 @@ credentials for a system, you should use the --keep-password option as it will
 mean that you will still be able to connect to the api server from the
 computer where you ran api-info.
 
+Users whose passwords are checked against an LDAP directory are sent server
+files without passwords. They are asked for their directory password when
+logging in, and it is never replaced with a random one.
+
 See Also:
     juju help system environments
     juju help system use-environment
@@ -134,6 +141,16 @@ func (c *LoginCommand) Run(ctx *cmd.Context) error {
 		// so we never attempt to change them.
 		c.KeepPassword = true
 	}
+	if serverDetails.Password == "" {
+		// Neither do users authenticated by an identity provider, who
+		// are sent server files without passwords.
+		password, err := readServerPassword(ctx, serverDetails.Username)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		serverDetails.Password = password
+		c.KeepPassword = true
+	}
 
 	info := api.Info{
 		Addrs:    serverDetails.Addresses,
@@ -226,7 +243,7 @@ func (c *LoginCommand) updatePassword(ctx *cmd.Context, conn api.Connection, use
 		return errors.Trace(err)
 	}
 	if err := userManager.SetPassword(userTag.Name(), password); err != nil {
-		errors.Trace(err)
+		return errors.Trace(err)
 	}
 	ctx.Infof("password updated\n")
 	creds := serverInfo.APICredentials()
@@ -238,6 +255,24 @@ func (c *LoginCommand) updatePassword(ctx *cmd.Context, conn api.Connection, use
 	return nil
 }
 
+var readPassword = readpass.ReadPassword
+
+// readServerPassword asks the user for the password to log in with.
+func readServerPassword(ctx *cmd.Context, username string) (string, error) {
+	// Add the carriage return directly after readPassword, so any
+	// errors are output on their own line.
+	fmt.Fprintf(ctx.Stdout, "password for %s: ", username)
+	password, err := readPassword()
+	fmt.Fprint(ctx.Stdout, "\n")
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if password == "" {
+		return "", errors.New("no password given")
+	}
+	return password, nil
+}
+
 func apiOpen(info *api.Info, opts api.DialOpts) (api.Connection, error) {
 	return api.Open(info, opts)
 }
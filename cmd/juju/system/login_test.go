@@ -182,6 +182,61 @@ func (s *LoginSuite) TestRemoteUsersKeepPassword(c *gc.C) {
 	c.Assert(creds.Password, gc.Equals, "sekrit")
 }
 
+func (s *LoginSuite) TestPasswordlessServerFile(c *gc.C) {
+	s.PatchValue(system.ReadPassword, func() (string, error) {
+		return "directory-password", nil
+	})
+	serverFilePath := filepath.Join(c.MkDir(), "server.yaml")
+	content := `
+addresses: ["192.168.2.1:1234"]
+username: ldap-user
+password: ""
+`
+	err := ioutil.WriteFile(serverFilePath, []byte(content), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	ctx, err := s.run(c, "foo", "--server", serverFilePath)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(ctx), gc.Equals, "password for ldap-user: \n")
+	c.Assert(s.apiConnection.info.Password, gc.Equals, "directory-password")
+
+	// The password is not replaced, as juju does not hold it.
+	info, err := s.store.ReadInfo("foo")
+	c.Assert(err, jc.ErrorIsNil)
+	creds := info.APICredentials()
+	c.Assert(creds.User, gc.Equals, "ldap-user")
+	c.Assert(creds.Password, gc.Equals, "directory-password")
+	c.Assert(s.apiConnection.password, gc.Equals, "")
+}
+
+func (s *LoginSuite) TestPasswordlessServerFileNoPassword(c *gc.C) {
+	s.PatchValue(system.ReadPassword, func() (string, error) {
+		return "", nil
+	})
+	serverFilePath := filepath.Join(c.MkDir(), "server.yaml")
+	content := `
+addresses: ["192.168.2.1:1234"]
+username: ldap-user
+`
+	err := ioutil.WriteFile(serverFilePath, []byte(content), 0644)
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = s.run(c, "foo", "--server", serverFilePath)
+	c.Assert(err, gc.ErrorMatches, "no password given")
+	c.Assert(s.apiConnection.info, gc.IsNil)
+}
+
+func (s *LoginSuite) TestSetPasswordError(c *gc.C) {
+	s.apiConnection.setPasswordError = errors.New("boom")
+	_, err := s.runServerFile(c)
+	c.Assert(err, gc.ErrorMatches, "boom")
+
+	// The password given in the server file is still cached.
+	info, err := s.store.ReadInfo("foo")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.APICredentials().Password, gc.Equals, "sekrit")
+}
+
 func (s *LoginSuite) TestConnectsUsingServerFileInfo(c *gc.C) {
 	s.username = "valid-user@local"
 	_, err := s.runServerFile(c)
@@ -212,6 +267,8 @@ type mockAPIConnection struct {
 	serverTag    names.EnvironTag
 	username     string
 	password     string
+
+	setPasswordError error
 }
 
 func (*mockAPIConnection) Close() error {
@@ -234,6 +291,9 @@ func (m *mockAPIConnection) ServerTag() (names.EnvironTag, error) {
 }
 
 func (m *mockAPIConnection) SetPassword(username, password string) error {
+	if m.setPasswordError != nil {
+		return m.setPasswordError
+	}
 	m.username = username
 	m.password = password
 	return nil
@@ -0,0 +1,189 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package system
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	"launchpad.net/gnuflag"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/envcmd"
+)
+
+// LogStatsCommand shows the stats of the pruning of the logs stored in
+// the database of the system.
+type LogStatsCommand struct {
+	envcmd.SysCommandBase
+	out    cmd.Output
+	api    logStatsAPI
+	apierr error
+}
+
+var logStatsDoc = `
+Show how the log records stored in the database of the system were last
+pruned, and how many have been pruned in total, by the limit that caused
+their removal:
+
+    age     the log-max-age setting
+    level   the log-level-max-ages setting
+    entity  the log-max-entity-mb setting
+    size    the log-max-collection-mb setting
+
+The entities whose records were pruned to keep them within the
+log-max-entity-mb limit are listed too. The settings are read from the
+system environment.
+
+Examples:
+    # Show the stats in a table.
+    juju system log-stats
+
+    # Show the stats as YAML.
+    juju system log-stats --format yaml
+`
+
+// logStatsAPI defines the methods on the system manager API endpoint
+// that the log-stats command calls.
+type logStatsAPI interface {
+	Close() error
+	LogPruneStats() (*params.LogPruneStats, error)
+}
+
+// Info implements Command.Info.
+func (c *LogStatsCommand) Info() *cmd.Info {
+	return &cmd.Info{
+		Name:    "log-stats",
+		Purpose: "show the stats of the pruning of the logs of the system",
+		Doc:     logStatsDoc,
+	}
+}
+
+// SetFlags implements Command.SetFlags.
+func (c *LogStatsCommand) SetFlags(f *gnuflag.FlagSet) {
+	c.out.AddFlags(f, "tabular", map[string]cmd.Formatter{
+		"yaml":    cmd.FormatYaml,
+		"json":    cmd.FormatJson,
+		"tabular": formatTabularLogStats,
+	})
+}
+
+func (c *LogStatsCommand) getAPI() (logStatsAPI, error) {
+	if c.api != nil {
+		return c.api, c.apierr
+	}
+	return c.NewSystemManagerAPIClient()
+}
+
+// Run implements Command.Run
+func (c *LogStatsCommand) Run(ctx *cmd.Context) error {
+	api, err := c.getAPI()
+	if err != nil {
+		return errors.Annotate(err, "cannot connect to the API")
+	}
+	defer api.Close()
+
+	stats, err := api.LogPruneStats()
+	if err != nil {
+		return errors.Annotate(err, "cannot get log pruning stats")
+	}
+	if stats == nil {
+		ctx.Infof("The logs of the system have not been pruned.")
+		return nil
+	}
+	return c.out.Write(ctx, newLogStats(stats))
+}
+
+// logStats holds the log pruning stats as they are output.
+type logStats struct {
+	LastPruned   string           `yaml:"last-pruned" json:"last-pruned"`
+	CollectionMB int              `yaml:"collection-mb" json:"collection-mb"`
+	Removed      logRemovedCounts `yaml:"removed" json:"removed"`
+	TotalRemoved logRemovedCounts `yaml:"total-removed" json:"total-removed"`
+	Entities     []logEntityStats `yaml:"entities,omitempty" json:"entities,omitempty"`
+}
+
+type logRemovedCounts struct {
+	Age    int `yaml:"age" json:"age"`
+	Level  int `yaml:"level" json:"level"`
+	Entity int `yaml:"entity" json:"entity"`
+	Size   int `yaml:"size" json:"size"`
+}
+
+type logEntityStats struct {
+	EnvUUID string `yaml:"env-uuid" json:"env-uuid"`
+	Entity  string `yaml:"entity" json:"entity"`
+	Removed int    `yaml:"removed" json:"removed"`
+}
+
+func newLogStats(stats *params.LogPruneStats) logStats {
+	result := logStats{
+		LastPruned:   stats.Time.UTC().Format(time.RFC3339),
+		CollectionMB: stats.CollectionMB,
+		Removed:      newLogRemovedCounts(stats.Removed),
+		TotalRemoved: newLogRemovedCounts(stats.TotalRemoved),
+	}
+	for _, entity := range stats.Entities {
+		result.Entities = append(result.Entities, logEntityStats{
+			EnvUUID: entity.EnvUUID,
+			Entity:  entity.Entity,
+			Removed: entity.Removed,
+		})
+	}
+	return result
+}
+
+func newLogRemovedCounts(counts params.LogPruneCounts) logRemovedCounts {
+	return logRemovedCounts{
+		Age:    counts.Age,
+		Level:  counts.Level,
+		Entity: counts.Entity,
+		Size:   counts.Size,
+	}
+}
+
+func formatTabularLogStats(value interface{}) ([]byte, error) {
+	stats, ok := value.(logStats)
+	if !ok {
+		return nil, errors.Errorf("expected value of type %T, got %T", stats, value)
+	}
+
+	var out bytes.Buffer
+	const (
+		// To format things into columns.
+		minwidth = 0
+		tabwidth = 1
+		padding  = 2
+		padchar  = ' '
+		flags    = 0
+	)
+	tw := tabwriter.NewWriter(&out, minwidth, tabwidth, padding, padchar, flags)
+	fmt.Fprintf(tw, "LAST PRUNED\t%s\n", stats.LastPruned)
+	fmt.Fprintf(tw, "COLLECTION SIZE\t%dMB\n", stats.CollectionMB)
+	tw.Flush()
+
+	fmt.Fprintf(&out, "\n")
+	tw = tabwriter.NewWriter(&out, minwidth, tabwidth, padding, padchar, flags)
+	fmt.Fprintf(tw, "LIMIT\tREMOVED\tTOTAL REMOVED\n")
+	fmt.Fprintf(tw, "age\t%d\t%d\n", stats.Removed.Age, stats.TotalRemoved.Age)
+	fmt.Fprintf(tw, "level\t%d\t%d\n", stats.Removed.Level, stats.TotalRemoved.Level)
+	fmt.Fprintf(tw, "entity\t%d\t%d\n", stats.Removed.Entity, stats.TotalRemoved.Entity)
+	fmt.Fprintf(tw, "size\t%d\t%d\n", stats.Removed.Size, stats.TotalRemoved.Size)
+	tw.Flush()
+
+	if len(stats.Entities) > 0 {
+		fmt.Fprintf(&out, "\n")
+		tw = tabwriter.NewWriter(&out, minwidth, tabwidth, padding, padchar, flags)
+		fmt.Fprintf(tw, "ENVIRONMENT UUID\tENTITY\tREMOVED\n")
+		for _, entity := range stats.Entities {
+			fmt.Fprintf(tw, "%s\t%s\t%d\n", entity.EnvUUID, entity.Entity, entity.Removed)
+		}
+		tw.Flush()
+	}
+	return out.Bytes(), nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package system_test
+
+import (
+	"time"
+
+	"github.com/juju/cmd"
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	goyaml "gopkg.in/yaml.v1"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/cmd/juju/system"
+	_ "github.com/juju/juju/provider/dummy"
+	"github.com/juju/juju/testing"
+)
+
+type LogStatsSuite struct {
+	testing.FakeJujuHomeSuite
+	api      *fakeLogStatsAPI
+	apierror error
+}
+
+var _ = gc.Suite(&LogStatsSuite{})
+
+// fakeLogStatsAPI mocks out the systemmanager API
+type fakeLogStatsAPI struct {
+	err   error
+	stats *params.LogPruneStats
+}
+
+func (f *fakeLogStatsAPI) Close() error { return nil }
+
+func (f *fakeLogStatsAPI) LogPruneStats() (*params.LogPruneStats, error) {
+	return f.stats, f.err
+}
+
+func (s *LogStatsSuite) SetUpTest(c *gc.C) {
+	s.FakeJujuHomeSuite.SetUpTest(c)
+	s.apierror = nil
+	s.api = &fakeLogStatsAPI{
+		stats: &params.LogPruneStats{
+			Time:         time.Date(2015, 6, 19, 15, 0, 0, 0, time.UTC),
+			CollectionMB: 1024,
+			Removed:      params.LogPruneCounts{Age: 10, Level: 5, Entity: 3},
+			TotalRemoved: params.LogPruneCounts{Age: 100, Level: 50, Entity: 3, Size: 2},
+			Entities: []params.LogEntityPruneCount{{
+				EnvUUID: "env-uuid",
+				Entity:  "unit-mysql-0",
+				Removed: 3,
+			}},
+		},
+	}
+}
+
+func (s *LogStatsSuite) runLogStatsCommand(c *gc.C, args ...string) (*cmd.Context, error) {
+	cmd := system.NewLogStatsCommand(s.api, s.apierror)
+	return testing.RunCommand(c, cmd, args...)
+}
+
+func (s *LogStatsSuite) TestLogStatsCannotConnectToAPI(c *gc.C) {
+	s.apierror = errors.New("connection refused")
+	_, err := s.runLogStatsCommand(c)
+	c.Assert(err, gc.ErrorMatches, "cannot connect to the API: connection refused")
+}
+
+func (s *LogStatsSuite) TestLogStatsError(c *gc.C) {
+	s.api.err = errors.New("unexpected api error")
+	_, err := s.runLogStatsCommand(c)
+	c.Assert(err, gc.ErrorMatches, "cannot get log pruning stats: unexpected api error")
+}
+
+func (s *LogStatsSuite) TestLogStatsNotPruned(c *gc.C) {
+	s.api.stats = nil
+	ctx, err := s.runLogStatsCommand(c)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(testing.Stdout(ctx), gc.Equals, "")
+	c.Check(testing.Stderr(ctx), gc.Equals, "The logs of the system have not been pruned.\n")
+}
+
+func (s *LogStatsSuite) TestLogStatsTabular(c *gc.C) {
+	ctx, err := s.runLogStatsCommand(c)
+	c.Check(err, jc.ErrorIsNil)
+	c.Check(testing.Stdout(ctx), gc.Equals, ""+
+		"LAST PRUNED      2015-06-19T15:00:00Z\n"+
+		"COLLECTION SIZE  1024MB\n"+
+		"\n"+
+		"LIMIT   REMOVED  TOTAL REMOVED\n"+
+		"age     10       100\n"+
+		"level   5        50\n"+
+		"entity  3        3\n"+
+		"size    0        2\n"+
+		"\n"+
+		"ENVIRONMENT UUID  ENTITY        REMOVED\n"+
+		"env-uuid          unit-mysql-0  3\n"+
+		"\n")
+}
+
+func (s *LogStatsSuite) TestLogStatsJSON(c *gc.C) {
+	ctx, err := s.runLogStatsCommand(c, "--format", "json")
+	c.Check(err, jc.ErrorIsNil)
+	c.Check(testing.Stdout(ctx), gc.Equals, ""+
+		`{"last-pruned":"2015-06-19T15:00:00Z","collection-mb":1024,`+
+		`"removed":{"age":10,"level":5,"entity":3,"size":0},`+
+		`"total-removed":{"age":100,"level":50,"entity":3,"size":2},`+
+		`"entities":[{"env-uuid":"env-uuid","entity":"unit-mysql-0","removed":3}]}`+
+		"\n")
+}
+
+func (s *LogStatsSuite) TestLogStatsYAML(c *gc.C) {
+	ctx, err := s.runLogStatsCommand(c, "--format", "yaml")
+	c.Check(err, jc.ErrorIsNil)
+	var stats map[string]interface{}
+	err = goyaml.Unmarshal([]byte(testing.Stdout(ctx)), &stats)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(stats, jc.DeepEquals, map[string]interface{}{
+		"last-pruned":   "2015-06-19T15:00:00Z",
+		"collection-mb": 1024,
+		"removed": map[interface{}]interface{}{
+			"age": 10, "level": 5, "entity": 3, "size": 0,
+		},
+		"total-removed": map[interface{}]interface{}{
+			"age": 100, "level": 50, "entity": 3, "size": 2,
+		},
+		"entities": []interface{}{
+			map[interface{}]interface{}{
+				"env-uuid": "env-uuid",
+				"entity":   "unit-mysql-0",
+				"removed":  3,
+			},
+		},
+	})
+}
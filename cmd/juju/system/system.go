@@ -45,6 +45,7 @@ func NewSuperCommand() cmd.Command {
 	systemCmd.Register(&DestroyCommand{})
 	systemCmd.Register(&KillCommand{apiDialerFunc: juju.NewAPIFromName})
 	systemCmd.Register(envcmd.WrapSystem(&ListBlocksCommand{}))
+	systemCmd.Register(envcmd.WrapSystem(&LogStatsCommand{}))
 	systemCmd.Register(envcmd.WrapSystem(&EnvironmentsCommand{}))
 	systemCmd.Register(envcmd.WrapSystem(&CreateEnvironmentCommand{}))
 	systemCmd.Register(envcmd.WrapSystem(&RemoveBlocksCommand{}))
@@ -29,6 +29,7 @@ var expectedCommmandNames = []string{
 	"kill",
 	"list",
 	"list-blocks",
+	"log-stats",
 	"login",
 	"remove-blocks",
 	"use-env", // alias for use-environment
@@ -23,18 +23,31 @@ lost when the environent is destroyed.  A server file will be written out in
 the current directory.  You can control the name and location of this file
 using the --output option.
 
+Users whose passwords are checked against the LDAP directory set up in the
+state server's configuration need not be added, as they are added when they
+first log in. They can be added beforehand with the --ldap option, in which
+case no password is generated, and the server file has none.
+
 Examples:
     # Add user "foobar" with a strong random password is generated.
     juju user add foobar
 
+    # Add user "foobar" whose password is checked by the LDAP directory.
+    juju user add --ldap foobar
+
 
 See Also:
     juju help user change-password
 `
 
+// ldapIdentityProvider is the identity provider of users whose
+// passwords are checked against an LDAP directory.
+const ldapIdentityProvider = "ldap"
+
 // AddUserAPI defines the usermanager API methods that the add command uses.
 type AddUserAPI interface {
 	AddUser(username, displayName, password string) (names.UserTag, error)
+	AddExternalUser(username, displayName, identityProvider string) (names.UserTag, error)
 	Close() error
 }
 
@@ -45,6 +58,7 @@ type AddCommand struct {
 	User        string
 	DisplayName string
 	OutPath     string
+	LDAP        bool
 }
 
 // Info implements Command.Info.
@@ -61,6 +75,7 @@ func (c *AddCommand) Info() *cmd.Info {
 func (c *AddCommand) SetFlags(f *gnuflag.FlagSet) {
 	f.StringVar(&c.OutPath, "o", "", "specify the environment file for new user")
 	f.StringVar(&c.OutPath, "output", "", "")
+	f.BoolVar(&c.LDAP, "ldap", false, "check the user's password against the LDAP directory")
 }
 
 // Init implements Command.Init.
@@ -89,14 +104,22 @@ func (c *AddCommand) Run(ctx *cmd.Context) error {
 		defer c.api.Close()
 	}
 
-	password, err := utils.RandomPassword()
-	if err != nil {
-		return errors.Annotate(err, "failed to generate random password")
-	}
-	randomPasswordNotify(password)
+	var password string
+	if c.LDAP {
+		if _, err := c.api.AddExternalUser(c.User, c.DisplayName, ldapIdentityProvider); err != nil {
+			return block.ProcessBlockedError(err, block.BlockChange)
+		}
+	} else {
+		var err error
+		password, err = utils.RandomPassword()
+		if err != nil {
+			return errors.Annotate(err, "failed to generate random password")
+		}
+		randomPasswordNotify(password)
 
-	if _, err := c.api.AddUser(c.User, c.DisplayName, password); err != nil {
-		return block.ProcessBlockedError(err, block.BlockChange)
+		if _, err := c.api.AddUser(c.User, c.DisplayName, password); err != nil {
+			return block.ProcessBlockedError(err, block.BlockChange)
+		}
 	}
 
 	displayName := c.User
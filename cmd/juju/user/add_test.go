@@ -121,6 +121,18 @@ func (s *UserAddCommandSuite) TestUsernameAndDisplayname(c *gc.C) {
 	s.assertServerFileMatches(c, s.serverFilename, "foobar", s.randomPassword)
 }
 
+func (s *UserAddCommandSuite) TestLDAPUser(c *gc.C) {
+	context, err := s.run(c, "--ldap", "foobar", "Foo Bar")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(s.mockAPI.username, gc.Equals, "foobar")
+	c.Assert(s.mockAPI.displayname, gc.Equals, "Foo Bar")
+	c.Assert(s.mockAPI.identityProvider, gc.Equals, "ldap")
+	c.Assert(s.randomPassword, gc.Equals, "")
+	expected := `user "Foo Bar (foobar)" added`
+	c.Assert(testing.Stderr(context), jc.Contains, expected)
+	s.assertServerFileMatches(c, s.serverFilename, "foobar", "")
+}
+
 func (s *UserAddCommandSuite) TestBlockAddUser(c *gc.C) {
 	// Block operation
 	s.mockAPI.blocked = true
@@ -143,6 +155,8 @@ type mockAddUserAPI struct {
 	displayname string
 	password    string
 
+	identityProvider string
+
 	shareFailMsg string
 	sharedUsers  []names.UserTag
 	blocked      bool
@@ -162,6 +176,16 @@ func (m *mockAddUserAPI) AddUser(username, displayname, password string) (names.
 	return names.UserTag{}, errors.New(m.failMessage)
 }
 
+func (m *mockAddUserAPI) AddExternalUser(username, displayname, identityProvider string) (names.UserTag, error) {
+	m.username = username
+	m.displayname = displayname
+	m.identityProvider = identityProvider
+	if m.failMessage == "" {
+		return names.NewLocalUserTag(username), nil
+	}
+	return names.UserTag{}, errors.New(m.failMessage)
+}
+
 func (*mockAddUserAPI) Close() error {
 	return nil
 }
@@ -18,6 +18,10 @@ import (
 const InfoCommandDoc = `
 Display infomation on a user.
 
+The user's access to the current environment ("read", "write" or
+"admin") is shown when they share it, and the identity provider that
+checks their password when it is not juju, such as "ldap".
+
 Examples:
   	# Show information on the current user
   	$ juju user info  
@@ -25,6 +29,7 @@ Examples:
   	display-name: Foo Bar
   	date-created : 1981-02-27 16:10:05 +0000 UTC
 	last-connection: 2014-01-01 00:00:00 +0000 UTC
+	access: admin
 
   	# Show information on a user with the given username
   	$ juju user info jsmith
@@ -32,13 +37,15 @@ Examples:
   	display-name: John Smith
   	date-created : 1981-02-27 16:10:05 +0000 UTC
 	last-connection: 2014-01-01 00:00:00 +0000 UTC
+	access: admin
 
   	# Show information on the current user in JSON format
   	$ juju user info --format json
   	{"user-name":"foobar",
   	"display-name":"Foo Bar",
 	"date-created": "1981-02-27 16:10:05 +0000 UTC",
-	"last-connection": "2014-01-01 00:00:00 +0000 UTC"}
+	"last-connection": "2014-01-01 00:00:00 +0000 UTC",
+	"access": "admin"}
 
   	# Show information on the current user in YAML format
   	$ juju user info --format yaml
@@ -46,6 +53,7 @@ Examples:
  	display-name: Foo Bar
  	date-created : 1981-02-27 16:10:05 +0000 UTC
 	last-connection: 2014-01-01 00:00:00 +0000 UTC
+	access: admin
 `
 
 // UserInfoAPI defines the API methods that the info command uses.
@@ -74,11 +82,13 @@ type InfoCommand struct {
 
 // UserInfo defines the serialization behaviour of the user information.
 type UserInfo struct {
-	Username       string `yaml:"user-name" json:"user-name"`
-	DisplayName    string `yaml:"display-name" json:"display-name"`
-	DateCreated    string `yaml:"date-created" json:"date-created"`
-	LastConnection string `yaml:"last-connection" json:"last-connection"`
-	Disabled       bool   `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+	Username         string `yaml:"user-name" json:"user-name"`
+	DisplayName      string `yaml:"display-name" json:"display-name"`
+	DateCreated      string `yaml:"date-created" json:"date-created"`
+	LastConnection   string `yaml:"last-connection" json:"last-connection"`
+	Disabled         bool   `yaml:"disabled,omitempty" json:"disabled,omitempty"`
+	Access           string `yaml:"access,omitempty" json:"access,omitempty"`
+	IdentityProvider string `yaml:"identity-provider,omitempty" json:"identity-provider,omitempty"`
 }
 
 // Info implements Command.Info.
@@ -144,10 +154,12 @@ func (c *InfoCommandBase) apiUsersToUserInfoSlice(users []params.UserInfo) []Use
 	var now = time.Now()
 	for _, info := range users {
 		outInfo := UserInfo{
-			Username:       info.Username,
-			DisplayName:    info.DisplayName,
-			Disabled:       info.Disabled,
-			LastConnection: LastConnection(info.LastConnection, now, c.exactTime),
+			Username:         info.Username,
+			DisplayName:      info.DisplayName,
+			Disabled:         info.Disabled,
+			LastConnection:   LastConnection(info.LastConnection, now, c.exactTime),
+			Access:           info.Access,
+			IdentityProvider: info.IdentityProvider,
 		}
 		if c.exactTime {
 			outInfo.DateCreated = info.DateCreated.String()
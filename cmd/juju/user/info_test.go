@@ -57,6 +57,12 @@ func (*fakeUserInfoAPI) UserInfo(usernames []string, all usermanager.IncludeDisa
 	case "foobar":
 		info.Username = "foobar"
 		info.DisplayName = "Foo Bar"
+	case "reader":
+		info.Username = "reader"
+		info.Access = "read"
+	case "ldapuser":
+		info.Username = "ldapuser"
+		info.IdentityProvider = "ldap"
 	default:
 		return nil, common.ErrPerm
 	}
@@ -93,6 +99,28 @@ last-connection: 2014-01-01
 `)
 }
 
+func (s *UserInfoCommandSuite) TestUserInfoWithAccess(c *gc.C) {
+	context, err := testing.RunCommand(c, newUserInfoCommand(), "reader")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(context), gc.Equals, `user-name: reader
+display-name: ""
+date-created: 1981-02-27
+last-connection: 2014-01-01
+access: read
+`)
+}
+
+func (s *UserInfoCommandSuite) TestUserInfoWithIdentityProvider(c *gc.C) {
+	context, err := testing.RunCommand(c, newUserInfoCommand(), "ldapuser")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(testing.Stdout(context), gc.Equals, `user-name: ldapuser
+display-name: ""
+date-created: 1981-02-27
+last-connection: 2014-01-01
+identity-provider: ldap
+`)
+}
+
 func (*UserInfoCommandSuite) TestUserInfoUserDoesNotExist(c *gc.C) {
 	_, err := testing.RunCommand(c, newUserInfoCommand(), "barfoo")
 	c.Assert(err, gc.ErrorMatches, "permission denied")
@@ -59,6 +59,7 @@ import (
 	"github.com/juju/juju/service"
 	"github.com/juju/juju/service/common"
 	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/backups"
 	"github.com/juju/juju/state/multiwatcher"
 	statestorage "github.com/juju/juju/state/storage"
 	"github.com/juju/juju/storage/looputil"
@@ -67,7 +68,9 @@ import (
 	"github.com/juju/juju/worker/addresser"
 	"github.com/juju/juju/worker/apiaddressupdater"
 	"github.com/juju/juju/worker/apicaller"
+	"github.com/juju/juju/worker/auditlogpruner"
 	"github.com/juju/juju/worker/authenticationworker"
+	"github.com/juju/juju/worker/backupscheduler"
 	"github.com/juju/juju/worker/certupdater"
 	"github.com/juju/juju/worker/charmrevisionworker"
 	"github.com/juju/juju/worker/cleaner"
@@ -81,12 +84,14 @@ import (
 	"github.com/juju/juju/worker/imagemetadataworker"
 	"github.com/juju/juju/worker/instancepoller"
 	"github.com/juju/juju/worker/localstorage"
+	"github.com/juju/juju/worker/logforwarder"
 	workerlogger "github.com/juju/juju/worker/logger"
 	"github.com/juju/juju/worker/logsender"
 	"github.com/juju/juju/worker/machiner"
 	"github.com/juju/juju/worker/metricworker"
 	"github.com/juju/juju/worker/minunitsworker"
 	"github.com/juju/juju/worker/networker"
+	"github.com/juju/juju/worker/orphancollector"
 	"github.com/juju/juju/worker/peergrouper"
 	"github.com/juju/juju/worker/provisioner"
 	"github.com/juju/juju/worker/proxyupdater"
@@ -1074,9 +1079,20 @@ func (a *MachineAgent) StateWorker() (worker.Worker, error) {
 				return statushistorypruner.New(st, statushistorypruner.NewHistoryPrunerParams()), nil
 			})
 
+			a.startWorkerAfterUpgrade(singularRunner, "auditlogpruner", func() (worker.Worker, error) {
+				return auditlogpruner.New(st, auditlogpruner.NewAuditLogPrunerParams()), nil
+			})
+
 			a.startWorkerAfterUpgrade(singularRunner, "txnpruner", func() (worker.Worker, error) {
 				return txnpruner.New(st, time.Hour*2), nil
 			})
+			a.startWorkerAfterUpgrade(singularRunner, "backupscheduler", func() (worker.Worker, error) {
+				backupPaths := backups.Paths{
+					DataDir: agentConfig.DataDir(),
+					LogsDir: agentConfig.LogDir(),
+				}
+				return backupscheduler.New(st, backupPaths, m.Id(), backupscheduler.DefaultInterval), nil
+			})
 
 		case state.JobManageStateDeprecated:
 			// Legacy environments may set this, but we ignore it.
@@ -1147,6 +1163,14 @@ func (a *MachineAgent) startEnvWorkers(
 	singularRunner.StartWorker("minunitsworker", func() (worker.Worker, error) {
 		return minunitsworker.NewMinUnitsWorker(st), nil
 	})
+	singularRunner.StartWorker("orphancollector", func() (worker.Worker, error) {
+		return orphancollector.New(st, orphancollector.DefaultInterval), nil
+	})
+	if feature.IsDbLogEnabled() {
+		singularRunner.StartWorker("logforwarder", func() (worker.Worker, error) {
+			return logforwarder.New(st), nil
+		})
+	}
 
 	// Start workers that use an API connection.
 	singularRunner.StartWorker("environ-provisioner", func() (worker.Worker, error) {
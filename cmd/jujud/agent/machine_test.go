@@ -264,6 +264,7 @@ type MachineSuite struct {
 var perEnvSingularWorkers = []string{
 	"cleaner",
 	"minunitsworker",
+	"orphancollector",
 	"addresserworker",
 	"environ-provisioner",
 	"charm-revision-updater",
@@ -811,6 +812,21 @@ func (s *MachineSuite) TestManageEnvironDoesntRunDbLogPrunerByDefault(c *gc.C) {
 	c.Assert(started.Contains("dblogpruner"), jc.IsFalse)
 }
 
+func (s *MachineSuite) TestManageEnvironRunsLogForwarderIfFeatureFlagEnabled(c *gc.C) {
+	s.SetFeatureFlags("db-log")
+
+	m, _, _ := s.primeAgent(c, version.Current, state.JobManageEnviron)
+	a := s.newAgent(c, m)
+	defer func() { c.Check(a.Stop(), jc.ErrorIsNil) }()
+	go func() { c.Check(a.Run(nil), jc.ErrorIsNil) }()
+
+	// The log forwarder runs for each environment.
+	runner := s.singularRecord.nextRunner(c)
+	runner.waitForWorker(c, "txnpruner")
+	runner = s.singularRecord.nextRunner(c)
+	runner.waitForWorker(c, "logforwarder")
+}
+
 func (s *MachineSuite) TestManageEnvironRunsStatusHistoryPruner(c *gc.C) {
 	m, _, _ := s.primeAgent(c, version.Current, state.JobManageEnviron)
 	a := s.newAgent(c, m)
@@ -821,6 +837,26 @@ func (s *MachineSuite) TestManageEnvironRunsStatusHistoryPruner(c *gc.C) {
 	runner.waitForWorker(c, "statushistorypruner")
 }
 
+func (s *MachineSuite) TestManageEnvironRunsAuditLogPruner(c *gc.C) {
+	m, _, _ := s.primeAgent(c, version.Current, state.JobManageEnviron)
+	a := s.newAgent(c, m)
+	defer func() { c.Check(a.Stop(), jc.ErrorIsNil) }()
+	go func() { c.Check(a.Run(nil), jc.ErrorIsNil) }()
+
+	runner := s.singularRecord.nextRunner(c)
+	runner.waitForWorker(c, "auditlogpruner")
+}
+
+func (s *MachineSuite) TestManageEnvironRunsBackupScheduler(c *gc.C) {
+	m, _, _ := s.primeAgent(c, version.Current, state.JobManageEnviron)
+	a := s.newAgent(c, m)
+	defer func() { c.Check(a.Stop(), jc.ErrorIsNil) }()
+	go func() { c.Check(a.Run(nil), jc.ErrorIsNil) }()
+
+	runner := s.singularRecord.nextRunner(c)
+	runner.waitForWorker(c, "backupscheduler")
+}
+
 func (s *MachineSuite) TestManageEnvironCallsUseMultipleCPUs(c *gc.C) {
 	// If it has been enabled, the JobManageEnviron agent should call utils.UseMultipleCPUs
 	usefulVersion := version.Current
@@ -55,6 +55,11 @@ type StartInstanceParams struct {
 	// in. It is only populated when valid positive spaces constraints
 	// are present.
 	SubnetsToZones map[network.Id][]string
+
+	// StatusCallback is an optional function the InstanceBroker may
+	// call to report the progress of starting the instance, while the
+	// machine is still pending. The data may be nil.
+	StatusCallback func(info string, data map[string]interface{}) error
 }
 
 // StartInstanceResult holds the result of an
@@ -6,6 +6,7 @@ package config
 import (
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -171,6 +172,65 @@ const (
 	// interfaces created for LXC containers. See also bug #1442257.
 	LXCDefaultMTU = "lxc-default-mtu"
 
+	// BackupsStorageKey stores where the archives of state backups
+	// are kept. See ParseBackupsStorage.
+	BackupsStorageKey = "backups-storage"
+
+	// LDAPURLKey stores the ldap:// or ldaps:// URL of the LDAP
+	// directory that checks the passwords of LDAP users. The LDAP
+	// settings are given at bootstrap; the API refuses to change them.
+	LDAPURLKey = "ldap-url"
+
+	// LDAPUserDNKey stores the template of the distinguished names
+	// of LDAP users, in which "{username}" stands for the juju user
+	// name.
+	LDAPUserDNKey = "ldap-user-dn"
+
+	// LDAPDisplayNameAttributeKey stores the LDAP attribute that
+	// holds the display names of LDAP users.
+	LDAPDisplayNameAttributeKey = "ldap-display-name-attribute"
+
+	// LDAPCACertKey stores the PEM-encoded CA certificate that the
+	// TLS certificate of an ldaps:// directory is checked with, in
+	// place of the system's.
+	LDAPCACertKey = "ldap-ca-cert"
+
+	// LDAPAllowInsecureKey stores whether an ldap:// URL may be used,
+	// over which passwords are sent unencrypted.
+	LDAPAllowInsecureKey = "ldap-allow-insecure"
+
+	// LogForwardURLKey stores the URL of the syslog or HTTP endpoint
+	// that the environment's logs are forwarded to. See
+	// validateLogForward for the schemes accepted.
+	LogForwardURLKey = "log-forward-url"
+
+	// LogForwardCACertKey stores the PEM-encoded CA certificate that
+	// the TLS certificate of the log forwarding endpoint is checked
+	// with, in place of the system's.
+	LogForwardCACertKey = "log-forward-ca-cert"
+
+	// LogMaxAgeKey stores how long log records stored in the database
+	// are kept, as a duration such as "72h". Like the other log
+	// pruning settings, it applies to the logs of all environments,
+	// and so may only be set in the state server environment.
+	LogMaxAgeKey = "log-max-age"
+
+	// LogLevelMaxAgesKey stores how long log records logged at
+	// particular levels are kept, in place of the log-max-age setting,
+	// as a comma-separated list of level=duration pairs such as
+	// "DEBUG=1h,INFO=24h".
+	LogLevelMaxAgesKey = "log-level-max-ages"
+
+	// LogMaxEntityMBKey stores the size, in MB, that the log records
+	// of each entity in an environment are pruned to, with 0 meaning
+	// no limit.
+	LogMaxEntityMBKey = "log-max-entity-mb"
+
+	// LogMaxCollectionMBKey stores the size, in MB, that the database
+	// collection holding the log records of all environments is
+	// pruned to.
+	LogMaxCollectionMBKey = "log-max-collection-mb"
+
 	//
 	// Deprecated Settings Attributes
 	//
@@ -271,6 +331,192 @@ func (method HarvestMode) HarvestUnknown() bool {
 	return method&HarvestUnknown != 0
 }
 
+const (
+	// BackupsStorageState keeps backup archives in the environment's
+	// blobstore.
+	BackupsStorageState = "state"
+	// BackupsStorageLocal keeps backup archives in a directory of the
+	// state server.
+	BackupsStorageLocal = "local"
+	// BackupsStorageSSH keeps backup archives in a directory of a host
+	// reached over SSH from the state server.
+	BackupsStorageSSH = "ssh"
+	// BackupsStorageProvider keeps backup archives in the storage of
+	// the provider.
+	BackupsStorageProvider = "provider"
+)
+
+// BackupsStorage describes where backup archives are kept.
+type BackupsStorage struct {
+	// Type is one of BackupsStorageState, BackupsStorageLocal,
+	// BackupsStorageSSH and BackupsStorageProvider.
+	Type string
+	// Host is the [user@]hostname of the SSH host.
+	Host string
+	// Dir is the absolute path of the directory archives are kept in,
+	// locally or on the SSH host.
+	Dir string
+}
+
+// ParseBackupsStorage parses the description of where backup archives
+// are kept, which is one of "state", "provider", "local:<dir>" and
+// "ssh:[<user>@]<host>:<dir>". An empty description means "state".
+func ParseBackupsStorage(description string) (BackupsStorage, error) {
+	switch description {
+	case "", BackupsStorageState:
+		return BackupsStorage{Type: BackupsStorageState}, nil
+	case BackupsStorageProvider:
+		return BackupsStorage{Type: BackupsStorageProvider}, nil
+	}
+	parts := strings.SplitN(description, ":", 2)
+	if len(parts) == 2 {
+		switch parts[0] {
+		case BackupsStorageLocal:
+			if filepath.IsAbs(parts[1]) {
+				return BackupsStorage{Type: BackupsStorageLocal, Dir: parts[1]}, nil
+			}
+		case BackupsStorageSSH:
+			hostDir := strings.SplitN(parts[1], ":", 2)
+			if len(hostDir) == 2 && hostDir[0] != "" && strings.HasPrefix(hostDir[1], "/") {
+				return BackupsStorage{Type: BackupsStorageSSH, Host: hostDir[0], Dir: hostDir[1]}, nil
+			}
+		}
+	}
+	return BackupsStorage{}, errors.NotValidf("backups storage %q", description)
+}
+
+// String returns the description of where backup archives are kept.
+func (s BackupsStorage) String() string {
+	switch s.Type {
+	case BackupsStorageLocal:
+		return s.Type + ":" + s.Dir
+	case BackupsStorageSSH:
+		return s.Type + ":" + s.Host + ":" + s.Dir
+	}
+	return s.Type
+}
+
+const (
+	// ldapUsernamePlaceholder stands for the user name in the
+	// template of LDAP users' distinguished names.
+	ldapUsernamePlaceholder = "{username}"
+
+	// DefaultLDAPDisplayNameAttribute is the LDAP attribute that
+	// holds the display names of LDAP users when none is configured.
+	DefaultLDAPDisplayNameAttribute = "displayName"
+)
+
+// validateLDAP checks the settings of the LDAP directory that checks
+// the passwords of LDAP users, if there is one.
+func validateLDAP(cfg *Config) error {
+	rawurl := cfg.asString(LDAPURLKey)
+	if rawurl == "" {
+		return nil
+	}
+	u, err := url.Parse(rawurl)
+	if err != nil || (u.Scheme != "ldap" && u.Scheme != "ldaps") || u.Host == "" {
+		return errors.Errorf("%s: expected ldap:// or ldaps:// URL, got %q", LDAPURLKey, rawurl)
+	}
+	if u.Scheme == "ldap" && !cfg.LDAPAllowInsecure() {
+		return errors.Errorf("%s: ldap:// sends passwords unencrypted; use ldaps:// or set %s", LDAPURLKey, LDAPAllowInsecureKey)
+	}
+	if caCert := cfg.asString(LDAPCACertKey); caCert != "" {
+		if _, err := cert.ParseCert(caCert); err != nil {
+			return errors.Annotatef(err, "%s: bad CA certificate", LDAPCACertKey)
+		}
+	}
+	if !strings.Contains(cfg.asString(LDAPUserDNKey), ldapUsernamePlaceholder) {
+		return errors.Errorf("%s: expected distinguished name containing %s, got %q", LDAPUserDNKey, ldapUsernamePlaceholder, cfg.asString(LDAPUserDNKey))
+	}
+	return nil
+}
+
+// Log forwarding URL schemes.
+const (
+	// LogForwardSyslogTCP forwards logs as RFC5424 syslog messages
+	// over TCP.
+	LogForwardSyslogTCP = "syslog+tcp"
+
+	// LogForwardSyslogTLS forwards logs as RFC5424 syslog messages
+	// over TLS.
+	LogForwardSyslogTLS = "syslog+tls"
+
+	// LogForwardHTTP and LogForwardHTTPS forward logs as JSON objects
+	// POSTed to an HTTP endpoint.
+	LogForwardHTTP  = "http"
+	LogForwardHTTPS = "https"
+)
+
+// validateLogForward checks the settings of the endpoint that logs are
+// forwarded to, if there is one.
+func validateLogForward(cfg *Config) error {
+	if caCert := cfg.asString(LogForwardCACertKey); caCert != "" {
+		if _, err := cert.ParseCert(caCert); err != nil {
+			return errors.Annotatef(err, "%s: bad CA certificate", LogForwardCACertKey)
+		}
+	}
+	rawurl := cfg.asString(LogForwardURLKey)
+	if rawurl == "" {
+		return nil
+	}
+	u, err := url.Parse(rawurl)
+	if err == nil && u.Host != "" {
+		switch u.Scheme {
+		case LogForwardSyslogTCP, LogForwardSyslogTLS, LogForwardHTTP, LogForwardHTTPS:
+			return nil
+		}
+	}
+	return errors.Errorf("%s: expected %s://, %s://, %s:// or %s:// URL, got %q", LogForwardURLKey,
+		LogForwardSyslogTCP, LogForwardSyslogTLS, LogForwardHTTP, LogForwardHTTPS, rawurl)
+}
+
+// ParseLogLevelMaxAges parses the value of the log-level-max-ages
+// setting, a comma-separated list of level=duration pairs such as
+// "DEBUG=1h,INFO=24h".
+func ParseLogLevelMaxAges(value string) (map[loggo.Level]time.Duration, error) {
+	ages := make(map[loggo.Level]time.Duration)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.Errorf("expected level=duration, got %q", pair)
+		}
+		level, ok := loggo.ParseLevel(strings.TrimSpace(parts[0]))
+		if !ok || level == loggo.UNSPECIFIED {
+			return nil, errors.Errorf("unknown log level %q", parts[0])
+		}
+		age, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil || age <= 0 {
+			return nil, errors.Errorf("expected positive duration for %s, got %q", level, parts[1])
+		}
+		ages[level] = age
+	}
+	return ages, nil
+}
+
+// validateLogPruning checks the settings that control the pruning of
+// log records stored in the database.
+func validateLogPruning(cfg *Config) error {
+	if v := cfg.asString(LogMaxAgeKey); v != "" {
+		if age, err := time.ParseDuration(v); err != nil || age <= 0 {
+			return errors.Errorf("%s: expected positive duration, got %q", LogMaxAgeKey, v)
+		}
+	}
+	if _, err := ParseLogLevelMaxAges(cfg.asString(LogLevelMaxAgesKey)); err != nil {
+		return errors.Annotate(err, LogLevelMaxAgesKey)
+	}
+	if v, ok := cfg.defined[LogMaxEntityMBKey].(int); ok && v < 0 {
+		return errors.Errorf("%s: expected non-negative integer, got %v", LogMaxEntityMBKey, v)
+	}
+	if v, ok := cfg.defined[LogMaxCollectionMBKey].(int); ok && v <= 0 {
+		return errors.Errorf("%s: expected positive integer, got %v", LogMaxCollectionMBKey, v)
+	}
+	return nil
+}
+
 var latestLtsSeries string
 
 type HasDefaultSeries interface {
@@ -634,6 +880,24 @@ func Validate(cfg, old *Config) error {
 		}
 	}
 
+	if v, ok := cfg.defined[BackupsStorageKey].(string); ok {
+		if _, err := ParseBackupsStorage(v); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	if err := validateLDAP(cfg); err != nil {
+		return errors.Trace(err)
+	}
+
+	if err := validateLogForward(cfg); err != nil {
+		return errors.Trace(err)
+	}
+
+	if err := validateLogPruning(cfg); err != nil {
+		return errors.Trace(err)
+	}
+
 	// Check LXCDefaultMTU is a positive integer, when set.
 	if lxcDefaultMTU, ok := cfg.LXCDefaultMTU(); ok && lxcDefaultMTU < 0 {
 		return errors.Errorf("%s: expected positive integer, got %v", LXCDefaultMTU, lxcDefaultMTU)
@@ -1135,6 +1399,95 @@ func (c *Config) LXCDefaultMTU() (int, bool) {
 	return v, ok
 }
 
+// BackupsStorage returns where the archives of state backups are kept.
+func (c *Config) BackupsStorage() BackupsStorage {
+	// Validate ensures the description parses.
+	s, _ := ParseBackupsStorage(c.asString(BackupsStorageKey))
+	return s
+}
+
+// LDAPURL returns the URL of the LDAP directory that checks the
+// passwords of LDAP users, or "" if there is none.
+func (c *Config) LDAPURL() string {
+	return c.asString(LDAPURLKey)
+}
+
+// LDAPUserDN returns the distinguished name of the LDAP entry of the
+// named user. The name is used as given, so characters special in
+// distinguished names must already be escaped.
+func (c *Config) LDAPUserDN(username string) string {
+	return strings.Replace(c.asString(LDAPUserDNKey), ldapUsernamePlaceholder, username, -1)
+}
+
+// LDAPDisplayNameAttribute returns the LDAP attribute that holds the
+// display names of LDAP users.
+func (c *Config) LDAPDisplayNameAttribute() string {
+	if v := c.asString(LDAPDisplayNameAttributeKey); v != "" {
+		return v
+	}
+	return DefaultLDAPDisplayNameAttribute
+}
+
+// LDAPCACert returns the PEM-encoded CA certificate that the TLS
+// certificate of an ldaps:// directory is checked with, or "" if the
+// system's CA certificates are used.
+func (c *Config) LDAPCACert() string {
+	return c.asString(LDAPCACertKey)
+}
+
+// LDAPAllowInsecure reports whether an ldap:// URL may be used, over
+// which passwords are sent unencrypted.
+func (c *Config) LDAPAllowInsecure() bool {
+	v, _ := c.defined[LDAPAllowInsecureKey].(bool)
+	return v
+}
+
+// LogForwardURL returns the URL of the endpoint that the environment's
+// logs are forwarded to, or "" if they are not forwarded.
+func (c *Config) LogForwardURL() string {
+	return c.asString(LogForwardURLKey)
+}
+
+// LogMaxAge returns how long log records stored in the database are
+// kept, and whether it is set.
+func (c *Config) LogMaxAge() (time.Duration, bool) {
+	// Validate ensures the duration parses.
+	age, err := time.ParseDuration(c.asString(LogMaxAgeKey))
+	if err != nil {
+		return 0, false
+	}
+	return age, true
+}
+
+// LogLevelMaxAges returns how long log records logged at particular
+// levels are kept, in place of LogMaxAge.
+func (c *Config) LogLevelMaxAges() map[loggo.Level]time.Duration {
+	// Validate ensures the setting parses.
+	ages, _ := ParseLogLevelMaxAges(c.asString(LogLevelMaxAgesKey))
+	return ages
+}
+
+// LogMaxEntityMB returns the size, in MB, that the log records of each
+// entity in an environment are pruned to, or 0 if there is no limit.
+func (c *Config) LogMaxEntityMB() int {
+	v, _ := c.defined[LogMaxEntityMBKey].(int)
+	return v
+}
+
+// LogMaxCollectionMB returns the size, in MB, that the database
+// collection holding log records is pruned to, and whether it is set.
+func (c *Config) LogMaxCollectionMB() (int, bool) {
+	v, ok := c.defined[LogMaxCollectionMBKey].(int)
+	return v, ok
+}
+
+// LogForwardCACert returns the PEM-encoded CA certificate that the TLS
+// certificate of the log forwarding endpoint is checked with, or "" if
+// the system's are used.
+func (c *Config) LogForwardCACert() string {
+	return c.asString(LogForwardCACertKey)
+}
+
 // DisableNetworkManagement reports whether Juju is allowed to
 // configure and manage networking inside the environment.
 func (c *Config) DisableNetworkManagement() (bool, bool) {
@@ -1274,6 +1627,18 @@ var alwaysOptional = schema.Defaults{
 	SetNumaControlPolicyKey:      DefaultNumaControlPolicy,
 	AllowLXCLoopMounts:           false,
 	ResourceTagsKey:              schema.Omit,
+	BackupsStorageKey:            schema.Omit,
+	LDAPURLKey:                   schema.Omit,
+	LDAPUserDNKey:                schema.Omit,
+	LDAPDisplayNameAttributeKey:  schema.Omit,
+	LDAPCACertKey:                schema.Omit,
+	LDAPAllowInsecureKey:         schema.Omit,
+	LogForwardURLKey:             schema.Omit,
+	LogForwardCACertKey:          schema.Omit,
+	LogMaxAgeKey:                 schema.Omit,
+	LogLevelMaxAgesKey:           schema.Omit,
+	LogMaxEntityMBKey:            schema.Omit,
+	LogMaxCollectionMBKey:        schema.Omit,
 
 	// Storage related config.
 	// Environ providers will specify their own defaults.
@@ -1585,6 +1950,71 @@ var configSchema = environschema.Fields{
 		Description: "Path to file containing SSH authorized keys",
 		Type:        environschema.Tstring,
 	},
+	BackupsStorageKey: {
+		// default: state
+		Description: `Where state backup archives are kept: "state", "provider", "local:<dir>" or "ssh:[<user>@]<host>:<dir>" (default state)`,
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	LDAPURLKey: {
+		Description: `The ldap:// or ldaps:// URL of the LDAP directory that checks the passwords of LDAP users`,
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	LDAPUserDNKey: {
+		Description: `The distinguished name of LDAP users' entries, with {username} standing for the user name, e.g. uid={username},ou=people,dc=example,dc=com`,
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	LDAPDisplayNameAttributeKey: {
+		// default: displayName
+		Description: `The LDAP attribute that holds the display names of LDAP users (default displayName)`,
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	LDAPCACertKey: {
+		Description: `The PEM-encoded CA certificate that the TLS certificate of an ldaps:// LDAP directory is checked with, in place of the system's`,
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	LDAPAllowInsecureKey: {
+		Description: `Whether an ldap:// URL may be used for the LDAP directory, over which passwords are sent unencrypted`,
+		Type:        environschema.Tbool,
+		Group:       environschema.EnvironGroup,
+	},
+	LogForwardURLKey: {
+		Description: `The URL of the endpoint the environment's logs are forwarded to: syslog+tcp://<host>[:<port>] or syslog+tls://<host>[:<port>] for an RFC5424 syslog server, or an http:// or https:// URL that log records are POSTed to as JSON`,
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	LogForwardCACertKey: {
+		Description: `The PEM-encoded CA certificate that the TLS certificate of the log forwarding endpoint is checked with, in place of the system's`,
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	LogMaxAgeKey: {
+		// default: 72h
+		Description: `How long log records are kept, such as 72h; it applies to the logs of all environments, and may only be set in the state server environment (default 72h)`,
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	LogLevelMaxAgesKey: {
+		Description: `How long log records logged at particular levels are kept, in place of log-max-age, such as DEBUG=1h,INFO=24h; may only be set in the state server environment`,
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	LogMaxEntityMBKey: {
+		// default: 0
+		Description: `The size in MB that the log records of each entity in an environment are pruned to, or 0 for no limit; may only be set in the state server environment (default 0)`,
+		Type:        environschema.Tint,
+		Group:       environschema.EnvironGroup,
+	},
+	LogMaxCollectionMBKey: {
+		// default: 4096
+		Description: `The size in MB that the log records of all environments are pruned to; may only be set in the state server environment (default 4096)`,
+		Type:        environschema.Tint,
+		Group:       environschema.EnvironGroup,
+	},
 	PreventAllChangesKey: {
 		Description: `Whether all changes to the environment will be prevented`,
 		Type:        environschema.Tbool,
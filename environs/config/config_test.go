@@ -257,6 +257,192 @@ var configTests = []configTest{
 			"lxc-default-mtu": -42,
 		},
 		err: `lxc-default-mtu: expected positive integer, got -42`,
+	}, {
+		about:       "Backups storage",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":            "my-type",
+			"name":            "my-name",
+			"backups-storage": "ssh:ubuntu@backups.example.com:/srv/backups",
+		},
+	}, {
+		about:       "Backups storage invalid",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":            "my-type",
+			"name":            "my-name",
+			"backups-storage": "local:relative/dir",
+		},
+		err: `backups storage "local:relative/dir" not valid`,
+	}, {
+		about:       "LDAP directory",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":         "my-type",
+			"name":         "my-name",
+			"ldap-url":     "ldaps://ldap.example.com",
+			"ldap-user-dn": "uid={username},ou=people,dc=example,dc=com",
+		},
+	}, {
+		about:       "LDAP URL invalid",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":         "my-type",
+			"name":         "my-name",
+			"ldap-url":     "https://ldap.example.com",
+			"ldap-user-dn": "uid={username},ou=people,dc=example,dc=com",
+		},
+		err: `ldap-url: expected ldap:// or ldaps:// URL, got "https://ldap.example.com"`,
+	}, {
+		about:       "LDAP user DN missing user name",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":     "my-type",
+			"name":     "my-name",
+			"ldap-url": "ldaps://ldap.example.com",
+		},
+		err: `ldap-user-dn: expected distinguished name containing {username}, got ""`,
+	}, {
+		about:       "LDAP URL insecure",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":         "my-type",
+			"name":         "my-name",
+			"ldap-url":     "ldap://ldap.example.com",
+			"ldap-user-dn": "uid={username},ou=people,dc=example,dc=com",
+		},
+		err: `ldap-url: ldap:// sends passwords unencrypted; use ldaps:// or set ldap-allow-insecure`,
+	}, {
+		about:       "LDAP URL insecure allowed",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                "my-type",
+			"name":                "my-name",
+			"ldap-url":            "ldap://ldap.example.com",
+			"ldap-user-dn":        "uid={username},ou=people,dc=example,dc=com",
+			"ldap-allow-insecure": true,
+		},
+	}, {
+		about:       "LDAP CA certificate invalid",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":         "my-type",
+			"name":         "my-name",
+			"ldap-url":     "ldaps://ldap.example.com",
+			"ldap-user-dn": "uid={username},ou=people,dc=example,dc=com",
+			"ldap-ca-cert": "bad",
+		},
+		err: `ldap-ca-cert: bad CA certificate: .*`,
+	}, {
+		about:       "Log forwarding to syslog",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":            "my-type",
+			"name":            "my-name",
+			"log-forward-url": "syslog+tls://logs.example.com:6514",
+		},
+	}, {
+		about:       "Log forwarding to HTTP",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                "my-type",
+			"name":                "my-name",
+			"log-forward-url":     "https://logs.example.com/ingest",
+			"log-forward-ca-cert": testing.CACert,
+		},
+	}, {
+		about:       "Log forwarding URL invalid",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":            "my-type",
+			"name":            "my-name",
+			"log-forward-url": "udp://logs.example.com",
+		},
+		err: `log-forward-url: expected syslog\+tcp://, syslog\+tls://, http:// or https:// URL, got "udp://logs.example.com"`,
+	}, {
+		about:       "Log forwarding URL without host",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":            "my-type",
+			"name":            "my-name",
+			"log-forward-url": "syslog+tcp:///",
+		},
+		err: `log-forward-url: expected .* URL, got "syslog\+tcp:///"`,
+	}, {
+		about:       "Log forwarding CA certificate invalid",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                "my-type",
+			"name":                "my-name",
+			"log-forward-url":     "syslog+tls://logs.example.com",
+			"log-forward-ca-cert": "rubbish",
+		},
+		err: `log-forward-ca-cert: bad CA certificate: .*`,
+	}, {
+		about:       "Log pruning policy",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                  "my-type",
+			"name":                  "my-name",
+			"log-max-age":           "168h",
+			"log-level-max-ages":    "DEBUG=1h, INFO=24h",
+			"log-max-entity-mb":     100,
+			"log-max-collection-mb": 1024,
+		},
+	}, {
+		about:       "Log max age invalid",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":        "my-type",
+			"name":        "my-name",
+			"log-max-age": "3 days",
+		},
+		err: `log-max-age: expected positive duration, got "3 days"`,
+	}, {
+		about:       "Log level max ages unknown level",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":               "my-type",
+			"name":               "my-name",
+			"log-level-max-ages": "CHATTY=1h",
+		},
+		err: `log-level-max-ages: unknown log level "CHATTY"`,
+	}, {
+		about:       "Log level max ages invalid duration",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":               "my-type",
+			"name":               "my-name",
+			"log-level-max-ages": "DEBUG=-1h",
+		},
+		err: `log-level-max-ages: expected positive duration for DEBUG, got "-1h"`,
+	}, {
+		about:       "Log level max ages missing duration",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":               "my-type",
+			"name":               "my-name",
+			"log-level-max-ages": "DEBUG",
+		},
+		err: `log-level-max-ages: expected level=duration, got "DEBUG"`,
+	}, {
+		about:       "Log max entity MB negative",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":              "my-type",
+			"name":              "my-name",
+			"log-max-entity-mb": -1,
+		},
+		err: `log-max-entity-mb: expected non-negative integer, got -1`,
+	}, {
+		about:       "Log max collection MB zero",
+		useDefaults: config.UseDefaults,
+		attrs: testing.Attrs{
+			"type":                  "my-type",
+			"name":                  "my-name",
+			"log-max-collection-mb": 0,
+		},
+		err: `log-max-collection-mb: expected positive integer, got 0`,
 	}, {
 		about:       "CA cert & key from path",
 		useDefaults: config.UseDefaults,
@@ -1283,6 +1469,12 @@ func (test configTest) check(c *gc.C, home *gitjujutesting.FakeHome) {
 	} else {
 		c.Assert(cfg.ProvisionerHarvestMode(), gc.Equals, config.HarvestDestroyed)
 	}
+
+	if v, ok := test.attrs["backups-storage"]; ok {
+		c.Assert(cfg.BackupsStorage().String(), gc.Equals, v)
+	} else {
+		c.Assert(cfg.BackupsStorage(), gc.Equals, config.BackupsStorage{Type: config.BackupsStorageState})
+	}
 	sshOpts := cfg.BootstrapSSHOpts()
 	test.assertDuration(
 		c,
@@ -1701,6 +1893,120 @@ func (s *ConfigSuite) TestLoggingConfigFromEnvironment(c *gc.C) {
 	c.Assert(config.LoggingConfig(), gc.Equals, "<root>=INFO;unit=DEBUG")
 }
 
+func (s *ConfigSuite) TestParseBackupsStorage(c *gc.C) {
+	for i, test := range []struct {
+		description string
+		expected    config.BackupsStorage
+		err         string
+	}{{
+		description: "",
+		expected:    config.BackupsStorage{Type: "state"},
+	}, {
+		description: "state",
+		expected:    config.BackupsStorage{Type: "state"},
+	}, {
+		description: "provider",
+		expected:    config.BackupsStorage{Type: "provider"},
+	}, {
+		description: "local:/var/backups/juju",
+		expected:    config.BackupsStorage{Type: "local", Dir: "/var/backups/juju"},
+	}, {
+		description: "ssh:ubuntu@10.0.0.1:/srv/backups",
+		expected:    config.BackupsStorage{Type: "ssh", Host: "ubuntu@10.0.0.1", Dir: "/srv/backups"},
+	}, {
+		description: "ssh:/srv/backups",
+		err:         `backups storage "ssh:/srv/backups" not valid`,
+	}, {
+		description: "local:",
+		err:         `backups storage "local:" not valid`,
+	}, {
+		description: "s3",
+		err:         `backups storage "s3" not valid`,
+	}} {
+		c.Logf("test %d: %q", i, test.description)
+		stor, err := config.ParseBackupsStorage(test.description)
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(stor, gc.Equals, test.expected)
+		if test.description != "" {
+			c.Check(stor.String(), gc.Equals, test.description)
+		}
+	}
+}
+
+func (s *ConfigSuite) TestLDAPSettings(c *gc.C) {
+	s.addJujuFiles(c)
+	cfg := newTestConfig(c, testing.Attrs{
+		"ldap-url":     "ldaps://ldap.example.com:636",
+		"ldap-user-dn": "uid={username},ou=people,dc=example,dc=com",
+	})
+	c.Assert(cfg.LDAPURL(), gc.Equals, "ldaps://ldap.example.com:636")
+	c.Assert(cfg.LDAPUserDN("bob"), gc.Equals, "uid=bob,ou=people,dc=example,dc=com")
+	c.Assert(cfg.LDAPDisplayNameAttribute(), gc.Equals, "displayName")
+	c.Assert(cfg.LDAPCACert(), gc.Equals, "")
+	c.Assert(cfg.LDAPAllowInsecure(), jc.IsFalse)
+
+	cfg = newTestConfig(c, testing.Attrs{
+		"ldap-url":                    "ldap://ldap.example.com:389",
+		"ldap-user-dn":                "uid={username},ou=people,dc=example,dc=com",
+		"ldap-display-name-attribute": "cn",
+		"ldap-ca-cert":                testing.CACert,
+		"ldap-allow-insecure":         true,
+	})
+	c.Assert(cfg.LDAPDisplayNameAttribute(), gc.Equals, "cn")
+	c.Assert(cfg.LDAPCACert(), gc.Equals, testing.CACert)
+	c.Assert(cfg.LDAPAllowInsecure(), jc.IsTrue)
+
+	cfg = newTestConfig(c, nil)
+	c.Assert(cfg.LDAPURL(), gc.Equals, "")
+}
+
+func (s *ConfigSuite) TestLogForwardSettings(c *gc.C) {
+	s.addJujuFiles(c)
+	cfg := newTestConfig(c, testing.Attrs{
+		"log-forward-url":     "syslog+tls://logs.example.com:6514",
+		"log-forward-ca-cert": testing.CACert,
+	})
+	c.Assert(cfg.LogForwardURL(), gc.Equals, "syslog+tls://logs.example.com:6514")
+	c.Assert(cfg.LogForwardCACert(), gc.Equals, testing.CACert)
+
+	cfg = newTestConfig(c, nil)
+	c.Assert(cfg.LogForwardURL(), gc.Equals, "")
+	c.Assert(cfg.LogForwardCACert(), gc.Equals, "")
+}
+
+func (s *ConfigSuite) TestLogPruningSettings(c *gc.C) {
+	s.addJujuFiles(c)
+	cfg := newTestConfig(c, testing.Attrs{
+		"log-max-age":           "168h",
+		"log-level-max-ages":    "DEBUG=1h,INFO=24h",
+		"log-max-entity-mb":     100,
+		"log-max-collection-mb": 1024,
+	})
+	maxAge, ok := cfg.LogMaxAge()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(maxAge, gc.Equals, 168*time.Hour)
+	c.Assert(cfg.LogLevelMaxAges(), jc.DeepEquals, map[loggo.Level]time.Duration{
+		loggo.DEBUG: time.Hour,
+		loggo.INFO:  24 * time.Hour,
+	})
+	c.Assert(cfg.LogMaxEntityMB(), gc.Equals, 100)
+	maxMB, ok := cfg.LogMaxCollectionMB()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(maxMB, gc.Equals, 1024)
+
+	cfg = newTestConfig(c, nil)
+	_, ok = cfg.LogMaxAge()
+	c.Assert(ok, jc.IsFalse)
+	c.Assert(cfg.LogLevelMaxAges(), gc.HasLen, 0)
+	c.Assert(cfg.LogMaxEntityMB(), gc.Equals, 0)
+	_, ok = cfg.LogMaxCollectionMB()
+	c.Assert(ok, jc.IsFalse)
+}
+
 func (s *ConfigSuite) TestProxyValuesWithFallback(c *gc.C) {
 	s.addJujuFiles(c)
 
@@ -0,0 +1,105 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
+
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/state"
+)
+
+// Orphan is a provider resource of an environment, such as a server or
+// a drive, that does not back any machine or volume known to state.
+// Orphans are typically left behind by failed removals.
+type Orphan struct {
+	// Kind is the kind of the resource, e.g. "server" or "drive".
+	Kind string
+	// Id is the provider id of the resource.
+	Id string
+	// Name is the provider name of the resource, if any.
+	Name string
+	// Zone is the availability zone the resource lives in, if any.
+	Zone string
+}
+
+// OrphanParams holds what state knows of an environment, against
+// which its provider resources are reconciled.
+type OrphanParams struct {
+	// Instances holds the instance ids of all provisioned machines.
+	Instances []instance.Id
+	// PendingMachines holds the ids of the machines not provisioned
+	// yet, whose resources may be in the middle of being created.
+	PendingMachines []string
+	// Volumes holds the tags of all volumes, provisioned or not.
+	Volumes []names.VolumeTag
+}
+
+// OrphanCollector is implemented by environments that can find the
+// resources they have left behind, and remove them.
+type OrphanCollector interface {
+	// Orphans returns the resources of the environment that back
+	// nothing described by params.
+	Orphans(params OrphanParams) ([]Orphan, error)
+
+	// RemoveOrphans removes the given resources, as returned by
+	// Orphans.
+	RemoveOrphans(orphans []Orphan) error
+}
+
+// StateOrphanParams returns the OrphanParams describing the machines
+// and volumes in state.
+func StateOrphanParams(st *state.State) (OrphanParams, error) {
+	var params OrphanParams
+	machines, err := st.AllMachines()
+	if err != nil {
+		return params, errors.Annotate(err, "cannot get machines")
+	}
+	for _, m := range machines {
+		id, err := m.InstanceId()
+		if errors.IsNotProvisioned(err) {
+			params.PendingMachines = append(params.PendingMachines, m.Id())
+			continue
+		} else if err != nil {
+			return params, errors.Annotatef(err, "cannot get instance id of machine %q", m.Id())
+		}
+		params.Instances = append(params.Instances, id)
+	}
+	volumes, err := st.AllVolumes()
+	if err != nil {
+		return params, errors.Annotate(err, "cannot get volumes")
+	}
+	for _, v := range volumes {
+		params.Volumes = append(params.Volumes, v.VolumeTag())
+	}
+	return params, nil
+}
+
+// FindOrphans returns the orphaned resources of the environment of st,
+// along with the collector that found them. If the environment cannot
+// collect orphans, the error satisfies errors.IsNotSupported.
+func FindOrphans(st *state.State) (OrphanCollector, []Orphan, error) {
+	envConfig, err := st.EnvironConfig()
+	if err != nil {
+		return nil, nil, errors.Annotate(err, "cannot get environment config")
+	}
+	env, err := New(envConfig)
+	if err != nil {
+		return nil, nil, errors.Annotate(err, "cannot access environment")
+	}
+	collector, ok := env.(OrphanCollector)
+	if !ok {
+		return nil, nil, errors.NotSupportedf("%s provider orphan collection", envConfig.Type())
+	}
+	params, err := StateOrphanParams(st)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	orphans, err := collector.Orphans(params)
+	if err != nil {
+		return nil, nil, errors.Annotate(err, "cannot find orphans")
+	}
+	return collector, orphans, nil
+}
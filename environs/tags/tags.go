@@ -17,6 +17,10 @@ const (
 	// whether a machine instance is a state server or not.
 	JujuStateServer = JujuTagPrefix + "is-state"
 
+	// JujuMachine is the tag name used for identifying the
+	// Juju machine a machine instance was provisioned for.
+	JujuMachine = JujuTagPrefix + "machine-id"
+
 	// JujuUnitsDeployed is the tag name used for identifying
 	// the units deployed to a machine instance.
 	JujuUnitsDeployed = JujuTagPrefix + "units-deployed"
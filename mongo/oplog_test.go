@@ -216,6 +216,13 @@ func (s *oplogSuite) TestNewMongoTimestampBeforeUnixEpoch(c *gc.C) {
 	c.Assert(mongo.NewMongoTimestamp(time.Time{}), gc.Equals, bson.MongoTimestamp(0))
 }
 
+func (s *oplogSuite) TestMongoTimestampTime(c *gc.C) {
+	t := time.Date(2015, 6, 24, 7, 47, 0, 0, time.UTC)
+
+	c.Assert(mongo.MongoTimestampTime(mongo.NewMongoTimestamp(t)), gc.Equals, t)
+	c.Assert(mongo.MongoTimestampTime(mongo.NewMongoTimestamp(t)+3), gc.Equals, t)
+}
+
 func (s *oplogSuite) startMongoWithReplicaset(c *gc.C) (*jujutesting.MgoInstance, *mgo.Session) {
 	inst := &jujutesting.MgoInstance{
 		Params: []string{
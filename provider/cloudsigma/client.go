@@ -12,7 +12,9 @@ import (
 	"github.com/juju/utils"
 
 	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/environs/imagemetadata"
+	jujutags "github.com/juju/juju/environs/tags"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/juju/arch"
 	"github.com/juju/juju/state/multiwatcher"
@@ -20,6 +22,7 @@ import (
 
 type environClient struct {
 	conn   *gosigma.Client
+	rest   *restClient
 	uuid   string
 	config *environConfig
 }
@@ -52,6 +55,7 @@ var newClient = func(cfg *environConfig) (client *environClient, err error) {
 
 	client = &environClient{
 		conn:   conn,
+		rest:   newRestClient(gosigma.ResolveEndpoint(cfg.region()), cfg.username(), cfg.password()),
 		uuid:   uuid,
 		config: cfg,
 	}
@@ -149,22 +153,60 @@ func (c *environClient) stopInstance(id instance.Id) error {
 	err = s.StopWait()
 	logger.Tracef("environClient.StopInstance - stop server, %q = %v", uuid, err)
 
+	var policies []string
+	if c.config.FirewallMode() == config.FwInstance {
+		if policies, err = c.serverFirewallPolicies(uuid); err != nil {
+			logger.Warningf("cannot get firewall policies of server %q: %v", uuid, err)
+		}
+	}
+
+	// A drive given through placement belongs to the user, so it is
+	// detached rather than removed with the server.
+	if drive, ok := s.Get(jujuMetaPlacedDrive); ok {
+		if err := c.detachDrive(uuid, drive); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	// The drives of volumes are persistent, so they are detached
+	// rather than removed with the server.
+	if err := c.detachVolumeDrives(uuid); err != nil {
+		return errors.Trace(err)
+	}
+
+	// A server that cannot be removed would leak its drives, so the
+	// failure is reported; the orphan collector cleans up after it.
 	err = s.Remove(gosigma.RecurseAllDrives)
 	logger.Tracef("environClient.StopInstance - remove server, %q = %v", uuid, err)
+	if err != nil {
+		return errors.Annotatef(err, "cannot remove server %q", uuid)
+	}
+
+	for _, policy := range policies {
+		err = c.rest.remove(fwPolicies, policy)
+		logger.Tracef("environClient.StopInstance - remove firewall policy, %q = %v", policy, err)
+	}
 
 	return nil
 }
 
-//newInstance creates and starts new instance.
-func (c *environClient) newInstance(args environs.StartInstanceParams, img *imagemetadata.ImageMetadata, userData []byte) (srv gosigma.Server, drv gosigma.Drive, ar string, err error) {
+//newInstance creates and starts new instance. If resumable is set, a
+// root drive still being cloned or resized when the start fails is left
+// for the next attempt in this zone to adopt.
+func (c *environClient) newInstance(args environs.StartInstanceParams, img *imagemetadata.ImageMetadata, userData []byte, resumable bool) (srv gosigma.Server, drv gosigma.Drive, ar string, err error) {
 
+	// A drive given through placement belongs to the user
+	// until the instance is started successfully.
+	var placedDrive, keepDrive bool
 	defer func() {
 		if err == nil {
 			return
 		}
-		if srv != nil {
+		if srv != nil && (placedDrive || keepDrive) {
+			srv.Remove(gosigma.RecurseNothing)
+		} else if srv != nil {
 			srv.Remove(gosigma.RecurseAllDrives)
-		} else if drv != nil {
+		} else if drv != nil && !placedDrive && !keepDrive {
 			drv.Remove()
 		}
 		srv = nil
@@ -176,6 +218,11 @@ func (c *environClient) newInstance(args environs.StartInstanceParams, img *imag
 		return nil, nil, "", err
 	}
 
+	placement, err := parsePlacement(args.Placement)
+	if err != nil {
+		return nil, nil, "", errors.Trace(err)
+	}
+
 	logger.Debugf("Tools: %v", args.Tools.URLs())
 	logger.Debugf("Juju Constraints:" + args.Constraints.String())
 	logger.Debugf("InstanceConfig: %#v", args.InstanceConfig)
@@ -183,27 +230,79 @@ func (c *environClient) newInstance(args environs.StartInstanceParams, img *imag
 	constraints := newConstraints(args.InstanceConfig.Bootstrap, args.Constraints, img)
 	logger.Debugf("CloudSigma Constraints: %v", constraints)
 
-	originalDrive, err := c.conn.Drive(constraints.driveTemplate, gosigma.LibraryMedia)
-	if err != nil {
-		err = errors.Annotatef(err, "Failed to query drive template")
-		return nil, nil, "", err
-	}
-
 	baseName := "juju-" + c.uuid + "-" + args.InstanceConfig.MachineId
+	report := progressReporter(args)
 
-	cloneParams := gosigma.CloneParams{Name: baseName}
-	if drv, err = originalDrive.CloneWait(cloneParams, nil); err != nil {
-		err = errors.Errorf("error cloning drive: %v", err)
-		return nil, nil, "", err
+	if err = c.removeStaleServers(baseName, placement.drive != ""); err != nil {
+		return nil, nil, "", errors.Trace(err)
 	}
 
-	if drv.Size() < constraints.driveSize {
-		if err = drv.ResizeWait(constraints.driveSize); err != nil {
-			err = errors.Errorf("error resizing drive: %v", err)
+	if placement.drive != "" {
+		if drv, err = c.conn.Drive(placement.drive, gosigma.LibraryAccount); err != nil {
+			err = errors.Annotatef(err, "Failed to query drive %q", placement.drive)
+			return nil, nil, "", err
+		}
+		placedDrive = true
+		if ar, err = placedDriveArch(drv, img.Arch); err != nil {
+			return nil, nil, "", errors.Trace(err)
+		}
+	} else {
+		// The template is either a library image or a private image
+		// of the account, e.g. from "juju metadata generate-image".
+		var originalDrive gosigma.Drive
+		if originalDrive, err = c.conn.Drive(constraints.driveTemplate, gosigma.LibraryMedia); err != nil {
+			var accountErr error
+			if originalDrive, accountErr = c.conn.Drive(constraints.driveTemplate, gosigma.LibraryAccount); accountErr != nil {
+				err = errors.Annotatef(err, "Failed to query drive template")
+				return nil, nil, "", err
+			}
+			err = nil
+		}
+
+		if drv, err = c.cloneRootDrive(originalDrive, baseName, report); err != nil {
+			keepDrive = resumable && drv != nil
+			return nil, nil, "", errors.Trace(err)
+		}
+
+		// populate root drive hardware characteristics
+		switch originalDrive.Arch() {
+		case "64":
+			ar = arch.AMD64
+		case "32":
+			ar = arch.I386
+		case "":
+			// Private images do not carry an architecture, which is
+			// then known from the image metadata.
+			if ar = img.Arch; ar == "" {
+				err = errors.Errorf("unknown arch of drive template %q", constraints.driveTemplate)
+				return nil, nil, "", err
+			}
+		default:
+			err = errors.Errorf("unknown arch: %v", originalDrive.Arch())
 			return nil, nil, "", err
 		}
 	}
 
+	// A drive given through placement is only resized when a root
+	// disk size is asked for.
+	if drv.Size() < constraints.driveSize && (!placedDrive || args.Constraints.RootDisk != nil) {
+		if err = c.resizeRootDrive(drv, constraints.driveSize, report); err != nil {
+			keepDrive = resumable && !placedDrive
+			return nil, nil, "", errors.Trace(err)
+		}
+	}
+
+	// A drive given through placement keeps the meta of its owner.
+	if !placedDrive {
+		meta := instanceResourceTags(args)
+		meta[jujuMetaEnvironment] = c.uuid
+		if err = c.setRootDriveMeta(drv, meta); err != nil {
+			return nil, nil, "", errors.Trace(err)
+		}
+	}
+
+	report("creating server")
+
 	cc, err := c.generateSigmaComponents(baseName, constraints, args, drv, userData)
 	if err != nil {
 		return nil, nil, "", errors.Trace(err)
@@ -214,19 +313,25 @@ func (c *environClient) newInstance(args environs.StartInstanceParams, img *imag
 		return nil, nil, "", err
 	}
 
-	if err = srv.Start(); err != nil {
-		err = errors.Annotatef(err, "error booting new instance")
-		return nil, nil, "", err
+	if len(args.Constraints.Tags) > 0 {
+		if err = c.tagResource(srv.UUID(), args.Constraints.Tags); err != nil {
+			return nil, nil, "", errors.Trace(err)
+		}
+	}
+
+	if placement.pool != "" {
+		if err = c.setServerPool(srv.UUID(), placement.pool); err != nil {
+			return nil, nil, "", errors.Trace(err)
+		}
 	}
 
-	// populate root drive hardware characteristics
-	switch originalDrive.Arch() {
-	case "64":
-		ar = arch.AMD64
-	case "32":
-		ar = arch.I386
-	default:
-		err = errors.Errorf("unknown arch: %v", ar)
+	if err = c.setupFirewall(srv.UUID(), args.InstanceConfig.MachineId); err != nil {
+		return nil, nil, "", errors.Trace(err)
+	}
+
+	report("starting server")
+	if err = srv.Start(); err != nil {
+		err = errors.Annotatef(err, "error booting new instance")
 		return nil, nil, "", err
 	}
 
@@ -249,8 +354,30 @@ func (c *environClient) generateSigmaComponents(baseName string, constraints *si
 	logger.Debugf("Setting ssh key: %s end", c.config.AuthorizedKeys())
 	cc.SetSSHPublicKey(c.config.AuthorizedKeys())
 	cc.AttachDrive(1, "0:0", "virtio", drv.UUID())
-	cc.NetworkDHCP4(gosigma.ModelVirtio)
 
+	// The public NIC comes first, followed by the private networks of
+	// the spaces the instance is constrained to.
+	placement, err := parsePlacement(args.Placement)
+	if err != nil {
+		return cc, errors.Trace(err)
+	}
+	if placement.ip != "" {
+		cc.NetworkStatic4(gosigma.ModelVirtio, placement.ip)
+	} else {
+		cc.NetworkDHCP4(gosigma.ModelVirtio)
+	}
+	for _, uuid := range startVLANs(c.config.region(), args.SubnetsToZones) {
+		cc.NetworkVLan(gosigma.ModelVirtio, uuid)
+	}
+
+	// Resource tags are set first, so that they cannot override the
+	// meta juju relies on.
+	for k, v := range instanceResourceTags(args) {
+		cc.SetMeta(k, v)
+	}
+	if placement.drive != "" {
+		cc.SetMeta(jujuMetaPlacedDrive, placement.drive)
+	}
 	if multiwatcher.AnyJobNeedsState(args.InstanceConfig.Jobs...) {
 		cc.SetMeta(jujuMetaInstance, jujuMetaInstanceStateServer)
 	} else {
@@ -267,3 +394,15 @@ func (c *environClient) generateSigmaComponents(baseName string, constraints *si
 
 	return cc, nil
 }
+
+// instanceResourceTags returns the resource tags of the instance
+// started with args: those of its instance config, which identify the
+// environment and carry the user's resource-tags, and its machine id.
+func instanceResourceTags(args environs.StartInstanceParams) map[string]string {
+	result := make(map[string]string, len(args.InstanceConfig.Tags)+1)
+	for k, v := range args.InstanceConfig.Tags {
+		result[k] = v
+	}
+	result[jujutags.JujuMachine] = args.InstanceConfig.MachineId
+	return result
+}
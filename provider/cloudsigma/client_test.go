@@ -47,6 +47,7 @@ func (s *clientSuite) SetUpTest(c *gc.C) {
 	ll := logger.LogLevel()
 	logger.SetLogLevel(loggo.TRACE)
 	s.AddCleanup(func(*gc.C) { logger.SetLogLevel(ll) })
+	s.PatchValue(&rootDriveAttempt, utils.AttemptStrategy{Total: 5 * time.Second, Delay: 10 * time.Millisecond})
 
 	mock.Reset()
 }
@@ -166,7 +167,7 @@ func (s *clientSuite) TestClientNewInstanceInvalidParams(c *gc.C) {
 	img := &imagemetadata.ImageMetadata{
 		Id: validImageId,
 	}
-	server, drive, arch, err := cli.newInstance(params, img, nil)
+	server, drive, arch, err := cli.newInstance(params, img, nil, true)
 	c.Check(server, gc.IsNil)
 	c.Check(arch, gc.Equals, "")
 	c.Check(drive, gc.IsNil)
@@ -191,7 +192,7 @@ func (s *clientSuite) TestClientNewInstanceInvalidTemplate(c *gc.C) {
 	img := &imagemetadata.ImageMetadata{
 		Id: "invalid-id",
 	}
-	server, drive, arch, err := cli.newInstance(params, img, nil)
+	server, drive, arch, err := cli.newInstance(params, img, nil, true)
 	c.Check(server, gc.IsNil)
 	c.Check(arch, gc.Equals, "")
 	c.Check(drive, gc.IsNil)
@@ -204,6 +205,10 @@ func (s *clientSuite) TestClientNewInstance(c *gc.C) {
 
 	cli.conn.OperationTimeout(1 * time.Second)
 
+	api := newFakeSigmaAPI()
+	defer api.Close()
+	cli.rest = api.restClient()
+
 	params := environs.StartInstanceParams{
 		Constraints: constraints.Value{},
 		InstanceConfig: &instancecfg.InstanceConfig{
@@ -236,10 +241,115 @@ func (s *clientSuite) TestClientNewInstance(c *gc.C) {
 	mock.ResetDrives()
 	mock.LibDrives.Add(templateDrive)
 
-	server, drive, arch, err := cli.newInstance(params, img, utils.Gzip([]byte{}))
+	server, drive, arch, err := cli.newInstance(params, img, utils.Gzip([]byte{}), true)
 	c.Check(server, gc.NotNil)
 	c.Check(drive, gc.NotNil)
 	c.Check(arch, gc.NotNil)
 	fmt.Printf("%v", err)
 	c.Check(err, gc.IsNil)
 }
+
+func (s *clientSuite) TestClientNewInstanceResourceTags(c *gc.C) {
+	cli, err := testNewClient(c, mock.Endpoint(""), mock.TestUser, mock.TestPassword)
+	c.Assert(err, gc.IsNil)
+	cli.conn.OperationTimeout(1 * time.Second)
+
+	api := newFakeSigmaAPI()
+	defer api.Close()
+	cli.rest = api.restClient()
+
+	mock.ResetDrives()
+	mock.LibDrives.Add(&data.Drive{
+		Resource:     data.Resource{URI: "uri", UUID: validImageId},
+		LibraryDrive: data.LibraryDrive{Arch: "64", ImageType: "image-type", OS: "os"},
+		Size:         2200 * gosigma.Megabyte,
+		Status:       "unmounted",
+	})
+
+	params := environs.StartInstanceParams{
+		InstanceConfig: &instancecfg.InstanceConfig{
+			MachineId: "1",
+			Tags: map[string]string{
+				"juju-env-uuid":     testEnvUUID,
+				"billing":           "team-a",
+				jujuMetaEnvironment: "spoofed",
+			},
+			Tools: &tools.Tools{
+				Version: version.Binary{Series: "trusty"},
+			},
+		},
+	}
+	img := &imagemetadata.ImageMetadata{Id: validImageId}
+	server, drive, _, err := cli.newInstance(params, img, utils.Gzip([]byte{}), true)
+	c.Assert(err, gc.IsNil)
+
+	for k, expect := range map[string]string{
+		"juju-env-uuid":     testEnvUUID,
+		"juju-machine-id":   "1",
+		"billing":           "team-a",
+		jujuMetaEnvironment: testEnvUUID,
+	} {
+		v, _ := server.Get(k)
+		c.Check(v, gc.Equals, expect, gc.Commentf("server meta %q", k))
+	}
+	c.Check(api.driveMeta(drive.UUID()), gc.DeepEquals, map[string]string{
+		"juju-env-uuid":     testEnvUUID,
+		"juju-machine-id":   "1",
+		"billing":           "team-a",
+		jujuMetaEnvironment: testEnvUUID,
+	})
+}
+
+func (s *clientSuite) newPlacedDriveClient(c *gc.C, arch string) *environClient {
+	cli, err := testNewClient(c, mock.Endpoint(""), mock.TestUser, mock.TestPassword)
+	c.Assert(err, gc.IsNil)
+	cli.conn.OperationTimeout(1 * time.Second)
+
+	api := newFakeSigmaAPI()
+	s.AddCleanup(func(*gc.C) { api.Close() })
+	cli.rest = api.restClient()
+
+	mock.ResetDrives()
+	mock.Drives.Add(&data.Drive{
+		Resource:     data.Resource{URI: "uri", UUID: "placed"},
+		LibraryDrive: data.LibraryDrive{Arch: arch},
+		Media:        "disk",
+		Size:         1024 * gosigma.Megabyte,
+		Status:       "unmounted",
+	})
+	return cli
+}
+
+func (s *clientSuite) placedDriveParams() environs.StartInstanceParams {
+	return environs.StartInstanceParams{
+		Placement: "drive=placed",
+		InstanceConfig: &instancecfg.InstanceConfig{
+			MachineId: "1",
+			Tools: &tools.Tools{
+				Version: version.Binary{Series: "trusty"},
+			},
+		},
+	}
+}
+
+func (s *clientSuite) TestClientNewInstancePlacedDrive(c *gc.C) {
+	cli := s.newPlacedDriveClient(c, "64")
+
+	img := &imagemetadata.ImageMetadata{Id: validImageId, Arch: "amd64"}
+	server, drive, arch, err := cli.newInstance(s.placedDriveParams(), img, utils.Gzip([]byte{}), true)
+	c.Assert(err, gc.IsNil)
+	c.Check(arch, gc.Equals, "amd64")
+	// The drive is not resized unless a root disk size is asked for.
+	c.Check(drive.UUID(), gc.Equals, "placed")
+	c.Check(drive.Size(), gc.Equals, 1024*gosigma.Megabyte)
+	placed, _ := server.Get(jujuMetaPlacedDrive)
+	c.Check(placed, gc.Equals, "placed")
+}
+
+func (s *clientSuite) TestClientNewInstancePlacedDriveArchMismatch(c *gc.C) {
+	cli := s.newPlacedDriveClient(c, "32")
+
+	img := &imagemetadata.ImageMetadata{Id: validImageId, Arch: "amd64"}
+	_, _, _, err := cli.newInstance(s.placedDriveParams(), img, utils.Gzip([]byte{}), true)
+	c.Assert(err, gc.ErrorMatches, `drive "placed" has architecture "i386", not "amd64"`)
+}
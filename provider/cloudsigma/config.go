@@ -4,6 +4,8 @@
 package cloudsigma
 
 import (
+	"strings"
+
 	"github.com/altoros/gosigma"
 	"github.com/juju/errors"
 	"github.com/juju/schema"
@@ -26,6 +28,27 @@ cloudsigma:
     #
     # username: <your username>
     # password: <secret>
+
+    # zones holds a comma separated list of additional CloudSigma
+    # locations, reachable with the same credentials, to spread
+    # machines across. Each location is an availability zone.
+    #
+    # zones: <other region>, ...
+
+    # instance-types holds additional instance types, or overrides of the
+    # built-in ones (micro, small, medium, large, xlarge and highmem),
+    # separated by semicolons. Each type is given a name and the
+    # cpu-cores, mem and optionally cpu-power and root-disk it provides.
+    #
+    # instance-types: big: cpu-cores=4 mem=8G; fast: cpu-cores=2 cpu-power=6000 mem=4G
+
+    # image holds a private image to clone root drives from, instead of
+    # the library images found through simplestreams. It is the UUID of
+    # a drive of the account, or tag:<name> for the drive with that tag.
+    # The drive's meta must hold its juju-arch (e.g. amd64) and the
+    # comma separated juju-series (e.g. trusty) it can run.
+    #
+    # image: tag:golden
 `
 
 const (
@@ -33,15 +56,27 @@ const (
 )
 
 var configFields = schema.Fields{
-	"username": schema.String(),
-	"password": schema.String(),
-	"region":   schema.String(),
+	"username":       schema.String(),
+	"password":       schema.String(),
+	"region":         schema.String(),
+	"zones":          schema.String(),
+	"instance-types": schema.String(),
+	"image":          schema.String(),
 }
 
 var configDefaultFields = schema.Defaults{
-	"username": "",
-	"password": "",
-	"region":   gosigma.DefaultRegion,
+	"username":       "",
+	"password":       "",
+	"region":         gosigma.DefaultRegion,
+	"zones":          "",
+	"instance-types": "",
+	"image":          "",
+}
+
+var configRequiredFields = []string{
+	"username",
+	"password",
+	"region",
 }
 
 var configSecretFields = []string{
@@ -92,11 +127,17 @@ func validateConfig(cfg *config.Config, old *environConfig) (*environConfig, err
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	for field := range configFields {
+	for _, field := range configRequiredFields {
 		if newAttrs[field] == "" {
 			return nil, errors.Errorf("%s: must not be empty", field)
 		}
 	}
+	if _, err := parseInstanceTypes(newAttrs["instance-types"].(string)); err != nil {
+		return nil, errors.Annotate(err, "instance-types")
+	}
+	if err := validateImageRef(newAttrs["image"].(string)); err != nil {
+		return nil, errors.Annotate(err, "image")
+	}
 
 	// If an old config was supplied, check any immutable fields have not changed.
 	if old != nil {
@@ -128,7 +169,7 @@ func validateConfig(cfg *config.Config, old *environConfig) (*environConfig, err
 func (c environConfig) clientConfigChanged(newConfig *environConfig) bool {
 	// compare
 	if newConfig.region() != c.region() || newConfig.username() != c.username() ||
-		newConfig.password() != c.password() {
+		newConfig.password() != c.password() || newConfig.attrs["zones"] != c.attrs["zones"] {
 		return true
 	}
 
@@ -144,6 +185,46 @@ func (c environConfig) region() string {
 	return c.attrs["region"].(string)
 }
 
+// zones returns the CloudSigma locations used as availability zones,
+// starting with the environment's region.
+func (c environConfig) zones() []string {
+	zones := []string{c.region()}
+	extra, _ := c.attrs["zones"].(string)
+	for _, zone := range strings.Split(extra, ",") {
+		zone = strings.TrimSpace(zone)
+		if zone == "" {
+			continue
+		}
+		found := false
+		for _, z := range zones {
+			found = found || z == zone
+		}
+		if !found {
+			zones = append(zones, zone)
+		}
+	}
+	return zones
+}
+
+// withRegion returns a copy of the configuration using the given
+// CloudSigma location as region. It is used to create the clients
+// of additional availability zones.
+func (c environConfig) withRegion(region string) *environConfig {
+	attrs := make(map[string]interface{}, len(c.attrs))
+	for k, v := range c.attrs {
+		attrs[k] = v
+	}
+	attrs["region"] = region
+	return &environConfig{Config: c.Config, attrs: attrs}
+}
+
+// image returns the reference of the private image to clone root
+// drives from, if any.
+func (c environConfig) image() string {
+	image, _ := c.attrs["image"].(string)
+	return image
+}
+
 func (c environConfig) username() string {
 	return c.attrs["username"].(string)
 }
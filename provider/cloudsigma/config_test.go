@@ -84,6 +84,34 @@ func (s *configSuite) TestNewEnvironConfig(c *gc.C) {
 		info:   "region must not be empty",
 		insert: testing.Attrs{"region": ""},
 		err:    "region: must not be empty",
+	}, {
+		info:   "zones are inserted if missing",
+		remove: []string{"zones"},
+		expect: testing.Attrs{"zones": ""},
+	}, {
+		info:   "zones are kept",
+		insert: testing.Attrs{"zones": "lvs,sjc"},
+		expect: testing.Attrs{"zones": "lvs,sjc"},
+	}, {
+		info:   "instance-types are inserted if missing",
+		remove: []string{"instance-types"},
+		expect: testing.Attrs{"instance-types": ""},
+	}, {
+		info:   "instance-types are kept",
+		insert: testing.Attrs{"instance-types": "big: cpu-cores=4 mem=8G"},
+		expect: testing.Attrs{"instance-types": "big: cpu-cores=4 mem=8G"},
+	}, {
+		info:   "instance-types must be valid",
+		insert: testing.Attrs{"instance-types": "big: cpu-cores=4"},
+		err:    `instance-types: instance type "big": cpu-cores and mem must be specified`,
+	}, {
+		info:   "image is kept",
+		insert: testing.Attrs{"image": "tag:golden"},
+		expect: testing.Attrs{"image": "tag:golden"},
+	}, {
+		info:   "image must name a tag",
+		insert: testing.Attrs{"image": "tag:"},
+		err:    `image: image "tag:" without tag name not valid`,
 	}}
 
 	for i, test := range newConfigTests {
@@ -133,6 +161,10 @@ var changeConfigTests = []struct {
 	info:   "can not change password to empty",
 	insert: testing.Attrs{"password": ""},
 	err:    "password: must not be empty",
+}, {
+	info:   "can change zones",
+	insert: testing.Attrs{"zones": "lvs"},
+	expect: testing.Attrs{"zones": "lvs"},
 }, {
 	info:   "can change region",
 	insert: testing.Attrs{"region": "lvs"},
@@ -318,4 +350,20 @@ func (s *configSuite) TestClientConfigChanged(c *gc.C) {
 	ecfg.attrs["password"] = "password1"
 	rc = oldConfig.clientConfigChanged(ecfg)
 	c.Check(rc, gc.Equals, true)
+
+	ecfg.attrs["password"] = "password"
+	ecfg.attrs["zones"] = "lvs"
+	rc = oldConfig.clientConfigChanged(ecfg)
+	c.Check(rc, gc.Equals, true)
+}
+
+func (s *configSuite) TestZones(c *gc.C) {
+	ecfg, err := validateConfig(newConfig(c, validAttrs().Merge(testing.Attrs{"zones": " lvs,zrh,, sjc"})), nil)
+	c.Assert(err, gc.IsNil)
+	c.Check(ecfg.zones(), gc.DeepEquals, []string{"zrh", "lvs", "sjc"})
+
+	zcfg := ecfg.withRegion("lvs")
+	c.Check(zcfg.region(), gc.Equals, "lvs")
+	c.Check(zcfg.username(), gc.Equals, "user")
+	c.Check(ecfg.region(), gc.Equals, "zrh")
 }
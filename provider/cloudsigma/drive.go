@@ -0,0 +1,268 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cloudsigma
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+)
+
+const (
+	driveMediaDisk = "disk"
+
+	driveStatusCreating  = "creating"
+	driveStatusResizing  = "resizing"
+	driveStatusMounted   = "mounted"
+	driveStatusUnmounted = "unmounted"
+
+	// driveDevice is the emulated device used for attached drives.
+	driveDevice = "virtio"
+
+	// jujuMetaVolume holds the tag of the juju volume a drive was
+	// created for.
+	jujuMetaVolume = "juju-volume"
+)
+
+// driveAttempt is used to wait for drives to finish pending operations.
+var driveAttempt = utils.AttemptStrategy{
+	Total: 5 * time.Minute,
+	Delay: 5 * time.Second,
+}
+
+// sigmaDrive is a CloudSigma drive, as far as storage is concerned.
+type sigmaDrive struct {
+	UUID        string            `json:"uuid,omitempty"`
+	Name        string            `json:"name"`
+	Size        uint64            `json:"size"`
+	Media       string            `json:"media"`
+	StorageType string            `json:"storage_type,omitempty"`
+	Status      string            `json:"status,omitempty"`
+	Meta        map[string]string `json:"meta,omitempty"`
+	MountedOn   []struct {
+		UUID string `json:"uuid"`
+	} `json:"mounted_on,omitempty"`
+}
+
+// busy reports whether the drive has an operation in progress.
+func (d *sigmaDrive) busy() bool {
+	return d.Status == driveStatusCreating || d.Status == driveStatusResizing
+}
+
+// sigmaServerDrive is a drive attached to a server.
+type sigmaServerDrive struct {
+	BootOrder  *int            `json:"boot_order"`
+	DevChannel string          `json:"dev_channel"`
+	Device     string          `json:"device"`
+	Drive      json.RawMessage `json:"drive"`
+}
+
+// driveUUID returns the UUID of the attached drive, which is
+// either a bare UUID or a resource reference.
+func (d *sigmaServerDrive) driveUUID() string {
+	var uuid string
+	if err := json.Unmarshal(d.Drive, &uuid); err == nil {
+		return uuid
+	}
+	var ref struct {
+		UUID string `json:"uuid"`
+	}
+	if err := json.Unmarshal(d.Drive, &ref); err == nil {
+		return ref.UUID
+	}
+	return ""
+}
+
+// environDrives returns the drives created for volumes of this
+// environment.
+func (c *environClient) environDrives() ([]sigmaDrive, error) {
+	var all []sigmaDrive
+	if err := c.rest.list(drives, &all); err != nil {
+		return nil, errors.Annotate(err, "cannot list drives")
+	}
+	var result []sigmaDrive
+	for _, drive := range all {
+		if _, ok := drive.Meta[jujuMetaVolume]; ok && drive.Meta[jujuMetaEnvironment] == c.uuid {
+			result = append(result, drive)
+		}
+	}
+	return result, nil
+}
+
+// drive returns the drive with the given UUID.
+func (c *environClient) drive(uuid string) (*sigmaDrive, error) {
+	var drive sigmaDrive
+	if err := c.rest.get(drives, uuid, &drive); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &drive, nil
+}
+
+// waitDrive waits until the drive with the given UUID has no
+// operation in progress, and returns it.
+func (c *environClient) waitDrive(uuid string) (*sigmaDrive, error) {
+	for a := driveAttempt.Start(); a.Next(); {
+		drive, err := c.drive(uuid)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if !drive.busy() {
+			return drive, nil
+		}
+	}
+	return nil, errors.Errorf("timed out waiting for drive %q", uuid)
+}
+
+// createDrive creates a new drive and waits for it to be ready.
+func (c *environClient) createDrive(drive *sigmaDrive) (*sigmaDrive, error) {
+	var created sigmaDrive
+	if err := c.rest.create(drives, drive, &created); err != nil {
+		return nil, errors.Annotatef(err, "cannot create drive %q", drive.Name)
+	}
+	return c.waitDrive(created.UUID)
+}
+
+// resizeDrive grows the drive with the given UUID to size bytes and
+// waits for the resize to complete.
+func (c *environClient) resizeDrive(drive *sigmaDrive, size uint64) (*sigmaDrive, error) {
+	resized := *drive
+	resized.Size = size
+	if err := c.rest.action(drives, drive.UUID, "resize", &resized, nil); err != nil {
+		return nil, errors.Annotatef(err, "cannot resize drive %q", drive.UUID)
+	}
+	return c.waitDrive(drive.UUID)
+}
+
+// removeDrive deletes the drive with the given UUID. A drive that
+// does not exist is not an error.
+func (c *environClient) removeDrive(uuid string) error {
+	err := c.rest.remove(drives, uuid)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return errors.Annotatef(err, "cannot remove drive %q", uuid)
+}
+
+// serverDrives returns the server definition and its attached drives.
+// The definition is kept as a map, since the API requires the whole
+// definition on update.
+func (c *environClient) serverDrives(serverUUID string) (map[string]interface{}, []sigmaServerDrive, error) {
+	var server map[string]interface{}
+	if err := c.rest.get(servers, serverUUID, &server); err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	var attached []sigmaServerDrive
+	if raw, ok := server["drives"]; ok && raw != nil {
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		if err := json.Unmarshal(data, &attached); err != nil {
+			return nil, nil, errors.Annotatef(err, "bad drives of server %q", serverUUID)
+		}
+	}
+	return server, attached, nil
+}
+
+// attachDrive attaches the drive to the server, if it is not attached
+// already, and returns the device channel it is attached at.
+func (c *environClient) attachDrive(serverUUID, driveUUID string) (string, error) {
+	server, attached, err := c.serverDrives(serverUUID)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	used := make(map[string]bool)
+	for _, d := range attached {
+		if d.driveUUID() == driveUUID {
+			return d.DevChannel, nil
+		}
+		used[d.DevChannel] = true
+	}
+
+	// The root drive is attached at 0:0.
+	channel := ""
+	for unit := 1; channel == ""; unit++ {
+		if candidate := fmt.Sprintf("0:%d", unit); !used[candidate] {
+			channel = candidate
+		}
+	}
+	ref, err := json.Marshal(driveUUID)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	attached = append(attached, sigmaServerDrive{
+		DevChannel: channel,
+		Device:     driveDevice,
+		Drive:      ref,
+	})
+	server["drives"] = attached
+	if err := c.rest.update(servers, serverUUID, server, nil); err != nil {
+		return "", errors.Annotatef(err, "cannot attach drive %q to server %q", driveUUID, serverUUID)
+	}
+	return channel, nil
+}
+
+// detachDrive detaches the drive from the server. A drive that is not
+// attached is not an error.
+func (c *environClient) detachDrive(serverUUID, driveUUID string) error {
+	server, attached, err := c.serverDrives(serverUUID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	remaining := make([]sigmaServerDrive, 0, len(attached))
+	for _, d := range attached {
+		if d.driveUUID() != driveUUID {
+			remaining = append(remaining, d)
+		}
+	}
+	if len(remaining) == len(attached) {
+		return nil
+	}
+	server["drives"] = remaining
+	if err := c.rest.update(servers, serverUUID, server, nil); err != nil {
+		return errors.Annotatef(err, "cannot detach drive %q from server %q", driveUUID, serverUUID)
+	}
+	return nil
+}
+
+// detachVolumeDrives detaches the drives of volumes from the server
+// with the given UUID, so that they are kept when it is removed.
+func (c *environClient) detachVolumeDrives(serverUUID string) error {
+	_, attached, err := c.serverDrives(serverUUID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, d := range attached {
+		driveUUID := d.driveUUID()
+		drive, err := c.drive(driveUUID)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return errors.Trace(err)
+		}
+		if _, ok := drive.Meta[jujuMetaVolume]; !ok {
+			continue
+		}
+		if err := c.detachDrive(serverUUID, driveUUID); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// deviceName returns the name of the virtio device a drive attached
+// at the given channel appears as on the server, e.g. "vdb" for "0:1".
+func deviceName(channel string) (string, error) {
+	var controller, unit int
+	if _, err := fmt.Sscanf(channel, "%d:%d", &controller, &unit); err != nil {
+		return "", errors.Annotatef(err, "bad device channel %q", channel)
+	}
+	if controller != 0 || unit < 0 || unit >= 26 {
+		return "", errors.Errorf("unsupported device channel %q", channel)
+	}
+	return fmt.Sprintf("vd%c", 'a'+unit), nil
+}
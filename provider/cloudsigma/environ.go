@@ -32,7 +32,9 @@ type environ struct {
 
 	ecfg                   *environConfig
 	client                 *environClient
+	zoneClients            map[string]*environClient
 	supportedArchitectures []string
+	peers                  peerRefresher
 }
 
 // Name returns the Environ's name.
@@ -64,7 +66,15 @@ func (env *environ) SetConfig(cfg *config.Config) error {
 			return errors.Trace(err)
 		}
 
+		zoneClients := map[string]*environClient{ecfg.region(): client}
+		for _, zone := range ecfg.zones()[1:] {
+			if zoneClients[zone], err = newClient(ecfg.withRegion(zone)); err != nil {
+				return errors.Annotatef(err, "zone %q", zone)
+			}
+		}
+
 		env.client = client
+		env.zoneClients = zoneClients
 	}
 
 	env.ecfg = ecfg
@@ -98,7 +108,20 @@ func (env *environ) Bootstrap(ctx environs.BootstrapContext, params environs.Boo
 }
 
 func (e *environ) StateServerInstances() ([]instance.Id, error) {
-	return e.client.getStateServerIds()
+	var ids []instance.Id
+	for _, client := range e.clients() {
+		zoneIds, err := client.getStateServerIds()
+		if err == environs.ErrNotBootstrapped {
+			continue
+		} else if err != nil {
+			return []instance.Id{}, errors.Trace(err)
+		}
+		ids = append(ids, zoneIds...)
+	}
+	if len(ids) == 0 {
+		return []instance.Id{}, environs.ErrNotBootstrapped
+	}
+	return ids, nil
 }
 
 // Destroy shuts down all known machines and destroys the
@@ -109,8 +132,18 @@ func (e *environ) StateServerInstances() ([]instance.Id, error) {
 // When Destroy has been called, any Environ referring to the
 // same remote environment may become invalid
 func (env *environ) Destroy() error {
-	// You can probably ignore this method; the common implementation should work.
-	return common.Destroy(env)
+	if err := common.Destroy(env); err != nil {
+		return errors.Trace(err)
+	}
+	if env.Config().FirewallMode() != config.FwGlobal {
+		return nil
+	}
+	for _, client := range env.clients() {
+		if err := client.removeFirewallPolicy(client.globalFirewallName()); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
 }
 
 // PrecheckInstance performs a preflight check on the specified
@@ -122,6 +155,11 @@ func (env *environ) Destroy() error {
 // guaranteed that the constraints are valid; if a non-nil error is
 // returned, then the constraints are definitely invalid.
 func (env *environ) PrecheckInstance(series string, cons constraints.Value, placement string) error {
+	if placement != "" {
+		if _, err := env.checkPlacement(placement); err != nil {
+			return errors.Trace(err)
+		}
+	}
 	return nil
 }
 
@@ -74,12 +74,12 @@ func (s *environSuite) TestBase(c *gc.C) {
 	c.Check(validator, gc.NotNil)
 	c.Check(err, gc.IsNil)
 
-	c.Check(env.SupportsUnitPlacement(), gc.ErrorMatches, "SupportsUnitPlacement not implemented")
+	c.Check(env.SupportsUnitPlacement(), gc.IsNil)
 
-	c.Check(env.OpenPorts(nil), gc.IsNil)
-	c.Check(env.ClosePorts(nil), gc.IsNil)
+	c.Check(env.OpenPorts(nil), gc.ErrorMatches, `invalid firewall mode "instance" for opening ports on environment`)
+	c.Check(env.ClosePorts(nil), gc.ErrorMatches, `invalid firewall mode "instance" for closing ports on environment`)
 
 	ports, err := env.Ports()
 	c.Check(ports, gc.IsNil)
-	c.Check(err, gc.IsNil)
+	c.Check(err, gc.ErrorMatches, `invalid firewall mode "instance" for retrieving ports from environment`)
 }
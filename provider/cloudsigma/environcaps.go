@@ -4,8 +4,6 @@
 package cloudsigma
 
 import (
-	"github.com/juju/errors"
-
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs/imagemetadata"
 	"github.com/juju/juju/environs/simplestreams"
@@ -34,8 +32,6 @@ func (env *environ) SupportedArchitectures() ([]string, error) {
 
 var unsupportedConstraints = []string{
 	constraints.Container,
-	constraints.InstanceType,
-	constraints.Tags,
 }
 
 // ConstraintsValidator returns a Validator instance which
@@ -48,6 +44,21 @@ func (env *environ) ConstraintsValidator() (constraints.Validator, error) {
 		return nil, err
 	}
 	validator.RegisterVocabulary(constraints.Arch, supportedArches)
+
+	// An instance type determines the CPU and RAM of the instance.
+	validator.RegisterConflicts(
+		[]string{constraints.InstanceType},
+		[]string{constraints.Mem, constraints.CpuCores, constraints.CpuPower},
+	)
+	itypes, err := env.ecfg.instanceTypes()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(itypes))
+	for i, itype := range itypes {
+		names[i] = itype.Name
+	}
+	validator.RegisterVocabulary(constraints.InstanceType, names)
 	return validator, nil
 }
 
@@ -56,11 +67,3 @@ func (env *environ) ConstraintsValidator() (constraints.Validator, error) {
 func (env *environ) SupportNetworks() bool {
 	return false
 }
-
-// SupportsUnitAssignment returns an error which, if non-nil, indicates
-// that the environment does not support unit placement. If the environment
-// does not support unit placement, then machines may not be created
-// without units, and units cannot be placed explcitly.
-func (env *environ) SupportsUnitPlacement() error {
-	return errors.NotImplementedf("SupportsUnitPlacement")
-}
@@ -3,28 +3,157 @@
 
 package cloudsigma
 
-import "github.com/juju/juju/network"
+import (
+	"sync"
+	"time"
 
-// Implementing the methods below (to do something other than return nil) will
-// cause `juju expose` to work when the firewall-mode is "global". If you
-// implement one of them, you should implement them all.
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+	"github.com/juju/utils/set"
+
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/network"
+)
+
+// peerAddressAttempt is how long the instances of the environment are
+// waited for to get their addresses, so that the firewall policies of
+// the other machines of the environment can accept traffic from them.
+var peerAddressAttempt = utils.AttemptStrategy{
+	Total: 2 * time.Minute,
+	Delay: 5 * time.Second,
+}
+
+// peerRefresher tracks the background updates of the firewall peers of
+// an environment. Updates requested while one is under way are made
+// together by a single further update.
+type peerRefresher struct {
+	mu      sync.Mutex
+	running bool
+	pending bool
+}
 
 // OpenPorts opens the given ports for the whole environment.
 // Must only be used if the environment was setup with the FwGlobal firewall mode.
 func (env *environ) OpenPorts(ports []network.PortRange) error {
-	logger.Warningf("pretending to open ports %v for all instances", ports)
+	if mode := env.Config().FirewallMode(); mode != config.FwGlobal {
+		return errors.Errorf("invalid firewall mode %q for opening ports on environment", mode)
+	}
+	// Policies are kept up to date in every availability zone, so that
+	// instances started in any zone get the currently open ports.
+	for _, client := range env.clients() {
+		if err := client.changeFirewallPorts(client.globalFirewallName(), ports, nil); err != nil {
+			return errors.Trace(err)
+		}
+	}
 	return nil
 }
 
 // ClosePorts closes the given ports for the whole environment.
 // Must only be used if the environment was setup with the FwGlobal firewall mode.
 func (env *environ) ClosePorts(ports []network.PortRange) error {
-	logger.Warningf("pretending to close ports %v for all instances", ports)
+	if mode := env.Config().FirewallMode(); mode != config.FwGlobal {
+		return errors.Errorf("invalid firewall mode %q for closing ports on environment", mode)
+	}
+	for _, client := range env.clients() {
+		if err := client.changeFirewallPorts(client.globalFirewallName(), nil, ports); err != nil {
+			return errors.Trace(err)
+		}
+	}
 	return nil
 }
 
 // Ports returns the ports opened for the whole environment.
 // Must only be used if the environment was setup with the FwGlobal firewall mode.
 func (env *environ) Ports() ([]network.PortRange, error) {
-	return nil, nil
+	if mode := env.Config().FirewallMode(); mode != config.FwGlobal {
+		return nil, errors.Errorf("invalid firewall mode %q for retrieving ports from environment", mode)
+	}
+	ports, err := env.client.firewallPorts(env.client.globalFirewallName())
+	return ports, errors.Trace(err)
+}
+
+// updateFirewallPeers makes the firewall policies of every availability
+// zone accept all traffic from the addresses of the machines of the
+// environment, so that units can reach each other on ports that are
+// not opened, e.g. for relations.
+func (env *environ) updateFirewallPeers() error {
+	if env.Config().FirewallMode() == config.FwNone {
+		return nil
+	}
+	m, err := env.instanceMap()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	peers := set.NewStrings()
+	for _, inst := range m {
+		for _, addr := range inst.server.IPv4() {
+			peers.Add(addr)
+		}
+	}
+	for _, client := range env.clients() {
+		if err := client.setFirewallPeers(peers.SortedValues()); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// refreshFirewallPeers updates the firewall peers of the environment in
+// the background, once its instances have their addresses, so that
+// starting instances does not wait for them.
+func (env *environ) refreshFirewallPeers() {
+	if env.Config().FirewallMode() == config.FwNone {
+		return
+	}
+	r := &env.peers
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.pending = true
+	if !r.running {
+		r.running = true
+		go env.peerRefreshLoop()
+	}
+}
+
+// peerRefreshLoop updates the firewall peers of the environment until
+// no more updates are requested.
+func (env *environ) peerRefreshLoop() {
+	r := &env.peers
+	for {
+		r.mu.Lock()
+		if !r.pending {
+			r.running = false
+			r.mu.Unlock()
+			return
+		}
+		r.pending = false
+		r.mu.Unlock()
+		env.waitPeerAddresses()
+	}
+}
+
+// waitPeerAddresses waits for the instances of the environment to get
+// their addresses, and then updates the firewall peers of the
+// environment. Failures are only logged, since the peers are updated
+// again whenever an instance is started or stopped.
+func (env *environ) waitPeerAddresses() {
+	for a := peerAddressAttempt.Start(); a.Next(); {
+		m, err := env.instanceMap()
+		if err != nil {
+			continue
+		}
+		addressed := true
+		for _, inst := range m {
+			if inst.findIPv4() == "" {
+				addressed = false
+				break
+			}
+		}
+		if addressed {
+			break
+		}
+	}
+	if err := env.updateFirewallPeers(); err != nil {
+		logger.Warningf("cannot update firewall peers: %v", err)
+	}
 }
@@ -63,15 +63,67 @@ func (env *environ) StartInstance(args environs.StartInstanceParams) (*environs.
 		return nil, errors.New("tools not found")
 	}
 
-	region, _ := env.Region()
-	img, err := findInstanceImage(env, imagemetadata.NewImageConstraint(simplestreams.LookupParams{
-		CloudSpec: region,
-		Series:    args.Tools.AllSeries(),
-		Arches:    args.Tools.Arches(),
-		Stream:    env.Config().ImageStream(),
-	}))
+	zones, err := env.startZones(args)
 	if err != nil {
-		return nil, err
+		return nil, errors.Trace(err)
+	}
+	// The zones are tried in turn, so that an instance is started even
+	// when a location is out of capacity. Only the last zone tried keeps
+	// a root drive for the next attempt to resume.
+	for i, zone := range zones {
+		var result *environs.StartInstanceResult
+		if result, err = env.startZoneInstance(zone, args, i == len(zones)-1); err == nil {
+			return result, nil
+		}
+		if i < len(zones)-1 {
+			logger.Infof("cannot start instance in availability zone %q, trying another: %v", zone, err)
+		}
+	}
+	return nil, err
+}
+
+// startZoneInstance starts a new instance in the given availability zone.
+// If resumable is set, a root drive left by a failed start is kept for
+// the next attempt.
+func (env *environ) startZoneInstance(zone string, args environs.StartInstanceParams, resumable bool) (*environs.StartInstanceResult, error) {
+	client, err := env.zoneClient(zone)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	logger.Debugf("starting instance in availability zone %q", zone)
+
+	itypes, err := env.ecfg.instanceTypes()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if args.Constraints, err = instanceTypeConstraints(itypes, zone, args.Constraints); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	placement, err := parsePlacement(args.Placement)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var img *imagemetadata.ImageMetadata
+	if image := env.ecfg.image(); image != "" || placement.image != "" {
+		if placement.image != "" {
+			image = placement.image
+		}
+		img, err = client.privateImage(image, args.InstanceConfig.Series, args.Tools.Arches())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	} else {
+		region, _ := env.cloudSpec(zone)
+		img, err = findInstanceImage(env, imagemetadata.NewImageConstraint(simplestreams.LookupParams{
+			CloudSpec: region,
+			Series:    args.Tools.AllSeries(),
+			Arches:    args.Tools.Arches(),
+			Stream:    env.Config().ImageStream(),
+		}))
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	tools, err := args.Tools.Match(tools.Filter{Arch: img.Arch})
@@ -90,13 +142,13 @@ func (env *environ) StartInstance(args environs.StartInstanceParams) (*environs.
 
 	logger.Debugf("cloudsigma user data; %d bytes", len(userData))
 
-	client := env.client
-	server, rootdrive, arch, err := client.newInstance(args, img, userData)
+	server, rootdrive, arch, err := client.newInstance(args, img, userData, resumable)
 	if err != nil {
 		return nil, errors.Errorf("failed start instance: %v", err)
 	}
 
-	inst := &sigmaInstance{server: server}
+	inst := &sigmaInstance{server: server, client: client}
+	env.refreshFirewallPeers()
 
 	// prepare hardware characteristics
 	hwch, err := inst.hardware(arch, rootdrive.Size())
@@ -119,16 +171,21 @@ func (env *environ) AllInstances() ([]instance.Instance, error) {
 
 	logger.Tracef("environ.AllInstances...")
 
-	servers, err := env.client.instances()
-	if err != nil {
-		logger.Tracef("environ.AllInstances failed: %v", err)
-		return nil, err
-	}
+	var instances []instance.Instance
+	for _, client := range env.clients() {
+		servers, err := client.instances()
+		if err != nil {
+			logger.Tracef("environ.AllInstances failed: %v", err)
+			return nil, err
+		}
 
-	instances := make([]instance.Instance, 0, len(servers))
-	for _, server := range servers {
-		instance := sigmaInstance{server: server}
-		instances = append(instances, instance)
+		for _, server := range servers {
+			instance := sigmaInstance{server: server, client: client}
+			instances = append(instances, instance)
+		}
+	}
+	if instances == nil {
+		instances = []instance.Instance{}
 	}
 
 	if logger.LogLevel() <= loggo.TRACE {
@@ -156,7 +213,7 @@ func (env *environ) Instances(ids []instance.Id) ([]instance.Instance, error) {
 	// real instance that's not part of the environment -- the Environ should
 	// treat that no differently to a request for one that does not exist.
 
-	m, err := env.client.instanceMap()
+	m, err := env.instanceMap()
 	if err != nil {
 		logger.Warningf("environ.Instances failed: %v", err)
 		return nil, err
@@ -165,8 +222,8 @@ func (env *environ) Instances(ids []instance.Id) ([]instance.Instance, error) {
 	var found int
 	r := make([]instance.Instance, len(ids))
 	for i, id := range ids {
-		if s, ok := m[string(id)]; ok {
-			r[i] = sigmaInstance{server: s}
+		if inst, ok := m[string(id)]; ok {
+			r[i] = inst
 			found++
 		}
 	}
@@ -184,13 +241,40 @@ func (env *environ) Instances(ids []instance.Id) ([]instance.Instance, error) {
 func (env *environ) StopInstances(instances ...instance.Id) error {
 	logger.Debugf("stop instances %+v", instances)
 
-	var err error
+	m, err := env.instanceMap()
+	if err != nil {
+		return errors.Trace(err)
+	}
 
 	for _, instance := range instances {
-		if e := env.client.stopInstance(instance); e != nil {
+		// Unknown instances are left to the client of the
+		// environment's region to report.
+		client := env.client
+		if inst, ok := m[string(instance)]; ok {
+			client = inst.client
+		}
+		if e := client.stopInstance(instance); e != nil {
 			err = e
 		}
 	}
 
+	if e := env.updateFirewallPeers(); e != nil {
+		logger.Warningf("cannot update firewall peers: %v", e)
+	}
 	return err
 }
+
+// instanceMap returns the instances of all availability zones by id.
+func (env *environ) instanceMap() (map[string]sigmaInstance, error) {
+	m := make(map[string]sigmaInstance)
+	for _, client := range env.clients() {
+		servers, err := client.instanceMap()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for id, server := range servers {
+			m[id] = sigmaInstance{server: server, client: client}
+		}
+	}
+	return m, nil
+}
@@ -0,0 +1,182 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cloudsigma
+
+import (
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/provider/common"
+)
+
+// Each CloudSigma location the environment uses is exposed as an
+// availability zone. The environment's region is always the first zone.
+
+var _ common.ZonedEnviron = (*environ)(nil)
+
+type sigmaAvailabilityZone struct {
+	name string
+}
+
+// Name returns the name of the availability zone.
+func (z sigmaAvailabilityZone) Name() string {
+	return z.name
+}
+
+// Available reports whether the availability zone is currently available.
+func (z sigmaAvailabilityZone) Available() bool {
+	return true
+}
+
+// clients returns the clients of all availability zones, in zone order.
+func (env *environ) clients() []*environClient {
+	zones := env.ecfg.zones()
+	clients := make([]*environClient, 0, len(zones))
+	for _, zone := range zones {
+		if client, ok := env.zoneClients[zone]; ok {
+			clients = append(clients, client)
+		}
+	}
+	return clients
+}
+
+// zoneClient returns the client of the given availability zone.
+func (env *environ) zoneClient(zone string) (*environClient, error) {
+	client, ok := env.zoneClients[zone]
+	if !ok {
+		return nil, errors.NotFoundf("availability zone %q", zone)
+	}
+	return client, nil
+}
+
+// AvailabilityZones returns all availability zones in the environment.
+func (env *environ) AvailabilityZones() ([]common.AvailabilityZone, error) {
+	zones := env.ecfg.zones()
+	result := make([]common.AvailabilityZone, len(zones))
+	for i, zone := range zones {
+		result[i] = sigmaAvailabilityZone{zone}
+	}
+	return result, nil
+}
+
+// InstanceAvailabilityZoneNames returns the names of the availability
+// zones for the specified instances. The error returned follows the same
+// rules as Environ.Instances.
+func (env *environ) InstanceAvailabilityZoneNames(ids []instance.Id) ([]string, error) {
+	instances, err := env.Instances(ids)
+	if err != nil && err != environs.ErrPartialInstances {
+		return nil, err
+	}
+	zones := make([]string, len(instances))
+	for i, inst := range instances {
+		if inst == nil {
+			continue
+		}
+		zones[i] = inst.(sigmaInstance).zone()
+	}
+	return zones, err
+}
+
+var availabilityZoneAllocations = common.AvailabilityZoneAllocations
+
+// startZones returns the availability zones that should be tried, in
+// order, for a new instance. If a zone was given or implied through
+// placement, only that zone is returned. Otherwise the zones are
+// ordered so that instances of the distribution group are spread
+// evenly across them. If the instance is constrained to spaces, only
+// zones with subnets of the spaces are returned. If the environment
+// names a private image, only zones holding the image are returned.
+func (env *environ) startZones(args environs.StartInstanceParams) ([]string, error) {
+	zones, err := env.allocationZones(args)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if zones, err = env.imageZones(args.Placement, zones); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(args.SubnetsToZones) == 0 {
+		return zones, nil
+	}
+	var withSubnets []string
+	for _, zone := range zones {
+		if len(startVLANs(zone, args.SubnetsToZones)) > 0 {
+			withSubnets = append(withSubnets, zone)
+		}
+	}
+	if len(withSubnets) == 0 {
+		return nil, errors.Errorf("no subnets of the constrained spaces in availability zones %v", zones)
+	}
+	return withSubnets, nil
+}
+
+// imageZones returns the given availability zones that hold the
+// private image of the environment. Drives and tags are per location,
+// so the image is resolved in each zone. An image given through
+// placement has already pinned the zone.
+func (env *environ) imageZones(placementArg string, zones []string) ([]string, error) {
+	ref := env.ecfg.image()
+	if ref == "" {
+		return zones, nil
+	}
+	placement, err := parsePlacement(placementArg)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if placement.image != "" {
+		return zones, nil
+	}
+	var withImage []string
+	for _, zone := range zones {
+		client, err := env.zoneClient(zone)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if _, err := client.imageDrive(ref); errors.IsNotFound(err) {
+			logger.Debugf("image %q not found in availability zone %q", ref, zone)
+			continue
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		withImage = append(withImage, zone)
+	}
+	if len(withImage) == 0 {
+		return nil, errors.NotFoundf("image %q in availability zones %v", ref, zones)
+	}
+	return withImage, nil
+}
+
+// allocationZones returns the availability zones for a new instance
+// given its placement and distribution group.
+func (env *environ) allocationZones(args environs.StartInstanceParams) ([]string, error) {
+	if args.Placement != "" {
+		placement, err := env.checkPlacement(args.Placement)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if placement.zone != "" {
+			return []string{placement.zone}, nil
+		}
+	}
+
+	var group []instance.Id
+	if args.DistributionGroup != nil {
+		var err error
+		if group, err = args.DistributionGroup(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	zoneInstances, err := availabilityZoneAllocations(env, group)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	zones := make([]string, len(zoneInstances))
+	for i, z := range zoneInstances {
+		zones[i] = z.ZoneName
+	}
+	if len(zones) == 0 {
+		return nil, errors.NotFoundf("availability zones")
+	}
+	return zones, nil
+}
@@ -0,0 +1,193 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cloudsigma
+
+import (
+	"github.com/altoros/gosigma/mock"
+	"github.com/juju/errors"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/cloudconfig/instancecfg"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/imagemetadata"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/provider/common"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/tools"
+	"github.com/juju/juju/version"
+)
+
+type environZonesSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&environZonesSuite{})
+
+func (s *environZonesSuite) SetUpSuite(c *gc.C) {
+	s.BaseSuite.SetUpSuite(c)
+	mock.Start()
+}
+
+func (s *environZonesSuite) TearDownSuite(c *gc.C) {
+	mock.Stop()
+	s.BaseSuite.TearDownSuite(c)
+}
+
+func (s *environZonesSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	mock.Reset()
+}
+
+func (s *environZonesSuite) newEnviron(c *gc.C, attrs testing.Attrs) *environ {
+	s.PatchValue(&newClient, func(cfg *environConfig) (*environClient, error) {
+		return &environClient{uuid: "f54aac3a-9dcd-4a0c-86b5-24091478478c", config: cfg}, nil
+	})
+	env, err := environs.New(newConfig(c, validAttrs().Merge(attrs)))
+	c.Assert(err, gc.IsNil)
+	return env.(*environ)
+}
+
+func zoneNames(zones []common.AvailabilityZone) []string {
+	names := make([]string, len(zones))
+	for i, zone := range zones {
+		names[i] = zone.Name()
+	}
+	return names
+}
+
+func (s *environZonesSuite) TestAvailabilityZones(c *gc.C) {
+	env := s.newEnviron(c, testing.Attrs{"zones": "lvs, sjc,zrh,,lvs"})
+
+	zones, err := env.AvailabilityZones()
+	c.Assert(err, gc.IsNil)
+	c.Assert(zoneNames(zones), gc.DeepEquals, []string{"zrh", "lvs", "sjc"})
+	for _, zone := range zones {
+		c.Check(zone.Available(), gc.Equals, true)
+	}
+
+	clients := env.clients()
+	c.Assert(clients, gc.HasLen, 3)
+	c.Check(clients[0], gc.Equals, env.client)
+	c.Check(clients[1].config.region(), gc.Equals, "lvs")
+	c.Check(clients[2].config.region(), gc.Equals, "sjc")
+}
+
+func (s *environZonesSuite) TestAvailabilityZonesDefault(c *gc.C) {
+	env := s.newEnviron(c, nil)
+
+	zones, err := env.AvailabilityZones()
+	c.Assert(err, gc.IsNil)
+	c.Assert(zoneNames(zones), gc.DeepEquals, []string{"zrh"})
+}
+
+func (s *environZonesSuite) TestStartZonesInvalidPlacement(c *gc.C) {
+	env := s.newEnviron(c, testing.Attrs{"zones": "lvs"})
+
+	for placement, expect := range map[string]string{
+		"zone=wdc": `invalid availability zone "wdc"`,
+		"lvs":      "unknown placement directive: lvs",
+		"host=lvs": "unknown placement directive: host=lvs",
+	} {
+		_, err := env.startZones(environs.StartInstanceParams{Placement: placement})
+		c.Check(err, gc.ErrorMatches, expect)
+	}
+}
+
+func (s *environZonesSuite) TestStartZonesPlacement(c *gc.C) {
+	env := s.newEnviron(c, testing.Attrs{"zones": "lvs"})
+
+	zones, err := env.startZones(environs.StartInstanceParams{Placement: "zone=lvs"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(zones, gc.DeepEquals, []string{"lvs"})
+}
+
+func (s *environZonesSuite) TestStartZonesDistribution(c *gc.C) {
+	env := s.newEnviron(c, testing.Attrs{"zones": "lvs"})
+
+	group := []instance.Id{"inst-0"}
+	s.PatchValue(&availabilityZoneAllocations, func(e common.ZonedEnviron, ids []instance.Id) ([]common.AvailabilityZoneInstances, error) {
+		c.Check(e, gc.Equals, env)
+		c.Check(ids, gc.DeepEquals, group)
+		return []common.AvailabilityZoneInstances{
+			{ZoneName: "lvs"},
+			{ZoneName: "zrh", Instances: group},
+		}, nil
+	})
+
+	zones, err := env.startZones(environs.StartInstanceParams{
+		DistributionGroup: func() ([]instance.Id, error) { return group, nil },
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(zones, gc.DeepEquals, []string{"lvs", "zrh"})
+}
+
+func (s *environZonesSuite) TestStartZonesImage(c *gc.C) {
+	// The private image is only tagged in the lvs zone.
+	zrh, lvs := newFakeSigmaAPI(), newFakeSigmaAPI()
+	s.AddCleanup(func(*gc.C) { zrh.Close(); lvs.Close() })
+	s.PatchValue(&newClient, func(cfg *environConfig) (*environClient, error) {
+		api := zrh
+		if cfg.region() == "lvs" {
+			api = lvs
+		}
+		return &environClient{rest: api.restClient(), config: cfg}, nil
+	})
+	env, err := environs.New(newConfig(c, validAttrs().Merge(testing.Attrs{
+		"zones": "lvs",
+		"image": "tag:golden",
+	})))
+	c.Assert(err, gc.IsNil)
+	s.PatchValue(&availabilityZoneAllocations, func(common.ZonedEnviron, []instance.Id) ([]common.AvailabilityZoneInstances, error) {
+		return []common.AvailabilityZoneInstances{{ZoneName: "zrh"}, {ZoneName: "lvs"}}, nil
+	})
+
+	_, err = env.(*environ).startZones(environs.StartInstanceParams{})
+	c.Assert(err, gc.ErrorMatches, `image "tag:golden" in availability zones \[zrh lvs\] not found`)
+
+	client := &environClient{rest: lvs.restClient()}
+	var created sigmaDrive
+	err = client.rest.create(drives, &sigmaDrive{Name: "golden", Size: 1, Media: driveMediaDisk}, &created)
+	c.Assert(err, gc.IsNil)
+	err = client.tagResource(created.UUID, []string{"golden"})
+	c.Assert(err, gc.IsNil)
+
+	zones, err := env.(*environ).startZones(environs.StartInstanceParams{})
+	c.Assert(err, gc.IsNil)
+	c.Assert(zones, gc.DeepEquals, []string{"lvs"})
+}
+
+func (s *environZonesSuite) TestStartInstanceTriesZones(c *gc.C) {
+	env := s.newEnviron(c, testing.Attrs{"zones": "lvs"})
+
+	s.PatchValue(&availabilityZoneAllocations, func(common.ZonedEnviron, []instance.Id) ([]common.AvailabilityZoneInstances, error) {
+		return []common.AvailabilityZoneInstances{{ZoneName: "lvs"}, {ZoneName: "zrh"}}, nil
+	})
+	var regions []string
+	s.PatchValue(&findInstanceImage, func(env *environ, ic *imagemetadata.ImageConstraint) (*imagemetadata.ImageMetadata, error) {
+		regions = append(regions, ic.Region)
+		return nil, errors.Errorf("no images in %q", ic.Region)
+	})
+
+	_, err := env.StartInstance(environs.StartInstanceParams{
+		Tools:          tools.List{{Version: version.MustParseBinary("1.2.3-trusty-amd64")}},
+		InstanceConfig: &instancecfg.InstanceConfig{},
+	})
+	c.Assert(err, gc.ErrorMatches, `no images in "zrh"`)
+	c.Assert(regions, gc.DeepEquals, []string{"lvs", "zrh"})
+}
+
+func (s *environZonesSuite) TestInstanceAvailabilityZoneNames(c *gc.C) {
+	uuid := addTestClientServer(c, jujuMetaInstanceServer, "f54aac3a-9dcd-4a0c-86b5-24091478478c")
+
+	env, err := environs.New(newConfig(c, validAttrs().Merge(testing.Attrs{
+		"region":   mock.Endpoint(""),
+		"username": mock.TestUser,
+		"password": mock.TestPassword,
+	})))
+	c.Assert(err, gc.IsNil)
+
+	zones, err := env.(common.ZonedEnviron).InstanceAvailabilityZoneNames([]instance.Id{instance.Id(uuid), "missing"})
+	c.Assert(err, gc.Equals, environs.ErrPartialInstances)
+	c.Assert(zones, gc.DeepEquals, []string{mock.Endpoint(""), ""})
+}
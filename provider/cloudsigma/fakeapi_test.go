@@ -0,0 +1,431 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cloudsigma
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// fakeSigmaAPI is a local fake of the parts of the CloudSigma API
+// accessed through restClient: firewall policies, drives, tags, VLANs,
+// static IPs and server updates. Servers are created on first access,
+// drives cloned in the gosigma mock on first update.
+type fakeSigmaAPI struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	nextID   int
+	policies map[string]*sigmaFirewallPolicy
+	drives   map[string]*sigmaDrive
+	tags     map[string]*sigmaTag
+	vlans    map[string]*sigmaVLAN
+	ips      map[string]map[string]interface{}
+	servers  map[string]map[string]interface{}
+
+	// knownServersOnly, if set, makes servers not created on
+	// first access, as in a location they are not in.
+	knownServersOnly bool
+}
+
+func newFakeSigmaAPI() *fakeSigmaAPI {
+	f := &fakeSigmaAPI{
+		policies: make(map[string]*sigmaFirewallPolicy),
+		drives:   make(map[string]*sigmaDrive),
+		tags:     make(map[string]*sigmaTag),
+		vlans:    make(map[string]*sigmaVLAN),
+		ips:      make(map[string]map[string]interface{}),
+		servers:  make(map[string]map[string]interface{}),
+	}
+	f.Server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	return f
+}
+
+// restClient returns a restClient talking to the fake.
+func (f *fakeSigmaAPI) restClient() *restClient {
+	return newRestClient(f.URL, "user", "password")
+}
+
+// serverPolicy returns the firewall policy attached to the server's
+// first NIC.
+func (f *fakeSigmaAPI) serverPolicy(uuid string) interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	server, ok := f.servers[uuid]
+	if !ok {
+		return nil
+	}
+	nics := server["nics"].([]interface{})
+	return nics[0].(map[string]interface{})["firewall_policy"]
+}
+
+func (f *fakeSigmaAPI) policyNames() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var names []string
+	for _, p := range f.policies {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// serverDrives returns the UUIDs of the drives attached to the server.
+func (f *fakeSigmaAPI) serverDrives(uuid string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.attachedDrives(uuid)
+}
+
+func (f *fakeSigmaAPI) attachedDrives(uuid string) []string {
+	server, ok := f.servers[uuid]
+	if !ok {
+		return nil
+	}
+	var uuids []string
+	attached, _ := server["drives"].([]interface{})
+	for _, d := range attached {
+		drive := d.(map[string]interface{})["drive"]
+		if ref, ok := drive.(map[string]interface{}); ok {
+			drive = ref["uuid"]
+		}
+		uuids = append(uuids, drive.(string))
+	}
+	return uuids
+}
+
+func (f *fakeSigmaAPI) driveNames() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var names []string
+	for _, d := range f.drives {
+		names = append(names, d.Name)
+	}
+	return names
+}
+
+// driveMeta returns the meta of the drive.
+func (f *fakeSigmaAPI) driveMeta(uuid string) map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if d, ok := f.drives[uuid]; ok {
+		return d.Meta
+	}
+	return nil
+}
+
+// tagResources returns the resources of each tag by name.
+func (f *fakeSigmaAPI) tagResources() map[string][]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	resources := make(map[string][]string)
+	for _, t := range f.tags {
+		resources[t.Name] = t.Resources
+	}
+	return resources
+}
+
+// addVLAN adds a VLAN with the given meta.
+func (f *fakeSigmaAPI) addVLAN(uuid string, meta map[string]string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.vlans[uuid] = &sigmaVLAN{UUID: uuid, Meta: meta}
+}
+
+// vlanMeta returns the meta of the VLAN.
+func (f *fakeSigmaAPI) vlanMeta(uuid string) map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.vlans[uuid].Meta
+}
+
+// addIP adds a static IP, assigned to the given server unless empty.
+func (f *fakeSigmaAPI) addIP(addr, serverUUID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ip := map[string]interface{}{"uuid": addr, "netmask": 24, "gateway": "10.0.0.1", "server": nil}
+	if serverUUID != "" {
+		ip["server"] = map[string]interface{}{"uuid": serverUUID}
+	}
+	f.ips[addr] = ip
+}
+
+// setServerNICs replaces the NICs of the server.
+func (f *fakeSigmaAPI) setServerNICs(uuid string, nics ...map[string]interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	list := make([]interface{}, len(nics))
+	for i, nic := range nics {
+		list[i] = nic
+	}
+	f.servers[uuid] = map[string]interface{}{"uuid": uuid, "nics": list}
+}
+
+func (f *fakeSigmaAPI) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case parts[0] == fwPolicies:
+		f.servePolicies(w, r, parts[1:])
+	case parts[0] == drives:
+		f.serveDrives(w, r, parts[1:])
+	case parts[0] == tags:
+		f.serveTags(w, r, parts[1:])
+	case parts[0] == vlans:
+		f.serveVLANs(w, r, parts[1:])
+	case parts[0] == ips && len(parts) == 2 && r.Method == "GET" && f.ips[parts[1]] != nil:
+		writeJSON(w, f.ips[parts[1]])
+	case parts[0] == servers && len(parts) == 2:
+		f.serveServer(w, r, parts[1])
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeSigmaAPI) servePolicies(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case r.Method == "GET" && len(parts) == 1 && parts[0] == "detail":
+		objects := []*sigmaFirewallPolicy{}
+		for _, p := range f.policies {
+			objects = append(objects, p)
+		}
+		writeJSON(w, map[string]interface{}{"objects": objects})
+	case r.Method == "POST" && len(parts) == 0:
+		var req struct {
+			Objects []*sigmaFirewallPolicy `json:"objects"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, p := range req.Objects {
+			f.nextID++
+			p.UUID = fmt.Sprintf("fwpolicy-%d", f.nextID)
+			f.policies[p.UUID] = p
+		}
+		writeJSON(w, req)
+	case r.Method == "PUT" && len(parts) == 1:
+		if _, ok := f.policies[parts[0]]; !ok {
+			http.NotFound(w, r)
+			return
+		}
+		var p sigmaFirewallPolicy
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		p.UUID = parts[0]
+		f.policies[p.UUID] = &p
+		writeJSON(w, p)
+	case r.Method == "DELETE" && len(parts) == 1:
+		if _, ok := f.policies[parts[0]]; !ok {
+			http.NotFound(w, r)
+			return
+		}
+		delete(f.policies, parts[0])
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeSigmaAPI) serveDrives(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case r.Method == "GET" && len(parts) == 1 && parts[0] == "detail":
+		objects := []*sigmaDrive{}
+		for _, d := range f.drives {
+			objects = append(objects, f.mountedDrive(d))
+		}
+		writeJSON(w, map[string]interface{}{"objects": objects})
+	case r.Method == "POST" && len(parts) == 0:
+		var req struct {
+			Objects []*sigmaDrive `json:"objects"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, d := range req.Objects {
+			f.nextID++
+			d.UUID = fmt.Sprintf("drive-%d", f.nextID)
+			d.Status = driveStatusUnmounted
+			f.drives[d.UUID] = d
+		}
+		writeJSON(w, req)
+	case r.Method == "PUT" && len(parts) == 1:
+		// Root drives are cloned in the gosigma mock, so they are
+		// recorded here on their first update.
+		var d sigmaDrive
+		if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		d.UUID = parts[0]
+		f.drives[d.UUID] = &d
+		writeJSON(w, f.mountedDrive(&d))
+	case len(parts) == 0 || f.drives[parts[0]] == nil:
+		http.NotFound(w, r)
+	case r.Method == "GET" && len(parts) == 1:
+		writeJSON(w, f.mountedDrive(f.drives[parts[0]]))
+	case r.Method == "POST" && len(parts) == 2 && parts[1] == "action" && r.URL.Query().Get("do") == "resize":
+		var d sigmaDrive
+		if err := json.NewDecoder(r.Body).Decode(&d); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.drives[parts[0]].Size = d.Size
+		writeJSON(w, f.drives[parts[0]])
+	case r.Method == "DELETE" && len(parts) == 1:
+		if len(f.mountedDrive(f.drives[parts[0]]).MountedOn) > 0 {
+			http.Error(w, "drive is mounted", http.StatusForbidden)
+			return
+		}
+		delete(f.drives, parts[0])
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeSigmaAPI) serveTags(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case r.Method == "GET" && len(parts) == 1 && parts[0] == "detail":
+		// Resources are reported as objects, like the real API does.
+		objects := []interface{}{}
+		for _, t := range f.tags {
+			resources := []interface{}{}
+			for _, uuid := range t.Resources {
+				resources = append(resources, map[string]interface{}{"uuid": uuid, "res_type": "servers"})
+			}
+			objects = append(objects, map[string]interface{}{
+				"uuid":      t.UUID,
+				"name":      t.Name,
+				"resources": resources,
+			})
+		}
+		writeJSON(w, map[string]interface{}{"objects": objects})
+	case r.Method == "POST" && len(parts) == 0:
+		var req struct {
+			Objects []*sigmaTag `json:"objects"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, t := range req.Objects {
+			f.nextID++
+			t.UUID = fmt.Sprintf("tag-%d", f.nextID)
+			f.tags[t.UUID] = t
+		}
+		writeJSON(w, req)
+	case r.Method == "PUT" && len(parts) == 1:
+		if _, ok := f.tags[parts[0]]; !ok {
+			http.NotFound(w, r)
+			return
+		}
+		var t sigmaTag
+		if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		t.UUID = parts[0]
+		f.tags[t.UUID] = &t
+		writeJSON(w, t)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeSigmaAPI) serveVLANs(w http.ResponseWriter, r *http.Request, parts []string) {
+	switch {
+	case r.Method == "GET" && len(parts) == 1 && parts[0] == "detail":
+		objects := []*sigmaVLAN{}
+		for _, v := range f.vlans {
+			objects = append(objects, v)
+		}
+		writeJSON(w, map[string]interface{}{"objects": objects})
+	case len(parts) != 1 || f.vlans[parts[0]] == nil:
+		http.NotFound(w, r)
+	case r.Method == "GET":
+		writeJSON(w, f.vlans[parts[0]])
+	case r.Method == "PUT":
+		var v sigmaVLAN
+		if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		v.UUID = parts[0]
+		f.vlans[v.UUID] = &v
+		writeJSON(w, v)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// mountedDrive returns a copy of the drive with its status and the
+// servers it is attached to filled in.
+func (f *fakeSigmaAPI) mountedDrive(d *sigmaDrive) *sigmaDrive {
+	mounted := *d
+	mounted.Status = driveStatusUnmounted
+	mounted.MountedOn = nil
+	for uuid := range f.servers {
+		for _, driveUUID := range f.attachedDrives(uuid) {
+			if driveUUID == d.UUID {
+				mounted.Status = driveStatusMounted
+				mounted.MountedOn = append(mounted.MountedOn, struct {
+					UUID string `json:"uuid"`
+				}{uuid})
+			}
+		}
+	}
+	return &mounted
+}
+
+// serveServer serves the definition of any server as having a single
+// NIC and a root drive, since servers themselves live in the gosigma
+// mock.
+func (f *fakeSigmaAPI) serveServer(w http.ResponseWriter, r *http.Request, uuid string) {
+	server, ok := f.servers[uuid]
+	if !ok && f.knownServersOnly {
+		http.NotFound(w, r)
+		return
+	}
+	if !ok {
+		server = map[string]interface{}{
+			"uuid": uuid,
+			"nics": []interface{}{map[string]interface{}{"model": "virtio", "firewall_policy": nil}},
+			"drives": []interface{}{map[string]interface{}{
+				"boot_order":  1,
+				"dev_channel": "0:0",
+				"device":      "virtio",
+				"drive":       map[string]interface{}{"uuid": "root-" + uuid},
+			}},
+		}
+		f.servers[uuid] = server
+	}
+	switch r.Method {
+	case "GET":
+		writeJSON(w, server)
+	case "PUT":
+		var updated map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&updated); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.servers[uuid] = updated
+		writeJSON(w, updated)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,348 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cloudsigma
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/network"
+)
+
+const (
+	fwActionAccept = "accept"
+	fwActionDrop   = "drop"
+	fwDirectionIn  = "in"
+
+	// fwCommentBase marks the rules juju always adds to a policy, so
+	// that they are not reported as ports opened by the firewaller.
+	fwCommentBase = "juju-base"
+	// fwCommentPort marks the rules added for opened port ranges.
+	fwCommentPort = "juju-port"
+	// fwCommentPeer marks the rules accepting all traffic from the
+	// machines of the environment, so that units can reach each other
+	// on ports that are not opened.
+	fwCommentPeer = "juju-peer"
+)
+
+// sigmaFirewallRule is a single rule of a CloudSigma firewall policy.
+type sigmaFirewallRule struct {
+	Action    string `json:"action"`
+	Direction string `json:"direction"`
+	IPProto   string `json:"ip_proto,omitempty"`
+	DstPort   string `json:"dst_port,omitempty"`
+	SrcIP     string `json:"src_ip,omitempty"`
+	Comment   string `json:"comment,omitempty"`
+}
+
+// sigmaFirewallPolicy is a CloudSigma firewall policy. Policies are
+// attached to server NICs and rules are evaluated in order.
+type sigmaFirewallPolicy struct {
+	UUID  string              `json:"uuid,omitempty"`
+	Name  string              `json:"name"`
+	Rules []sigmaFirewallRule `json:"rules"`
+	Meta  map[string]string   `json:"meta,omitempty"`
+}
+
+// ports returns the port ranges opened by juju in the policy.
+func (p *sigmaFirewallPolicy) ports() ([]network.PortRange, error) {
+	var ports []network.PortRange
+	for _, rule := range p.Rules {
+		if rule.Comment != fwCommentPort || rule.Action != fwActionAccept {
+			continue
+		}
+		portRange, err := network.ParsePortRange(strings.Replace(rule.DstPort, ":", "-", 1))
+		if err != nil {
+			return nil, errors.Annotatef(err, "bad rule in firewall policy %q", p.Name)
+		}
+		portRange.Protocol = rule.IPProto
+		ports = append(ports, portRange)
+	}
+	network.SortPortRanges(ports)
+	return ports, nil
+}
+
+// peers returns the addresses of the machines of the environment that
+// the policy accepts all traffic from.
+func (p *sigmaFirewallPolicy) peers() []string {
+	var peers []string
+	for _, rule := range p.Rules {
+		if rule.Comment == fwCommentPeer && rule.Action == fwActionAccept {
+			peers = append(peers, rule.SrcIP)
+		}
+	}
+	sort.Strings(peers)
+	return peers
+}
+
+// firewallRules returns the full rule set for a policy opening the
+// given ports. The base rules keep SSH and the juju API reachable, the
+// peer rules let the machines of the environment reach each other, and
+// the trailing drop rules reject any other inbound TCP and UDP traffic.
+// CloudSigma policies are stateful, so replies to outbound connections
+// are not affected.
+func firewallRules(apiPort, statePort int, peers []string, ports network.PortSet) []sigmaFirewallRule {
+	rules := []sigmaFirewallRule{
+		acceptRule("tcp", "22", fwCommentBase),
+		acceptRule("tcp", strconv.Itoa(apiPort), fwCommentBase),
+		acceptRule("tcp", strconv.Itoa(statePort), fwCommentBase),
+	}
+	for _, peer := range peers {
+		rules = append(rules, sigmaFirewallRule{
+			Action:    fwActionAccept,
+			Direction: fwDirectionIn,
+			SrcIP:     peer,
+			Comment:   fwCommentPeer,
+		})
+	}
+	portRanges := ports.PortRanges()
+	network.SortPortRanges(portRanges)
+	for _, portRange := range portRanges {
+		dstPort := strconv.Itoa(portRange.FromPort)
+		if portRange.ToPort != portRange.FromPort {
+			dstPort = fmt.Sprintf("%d:%d", portRange.FromPort, portRange.ToPort)
+		}
+		rules = append(rules, acceptRule(portRange.Protocol, dstPort, fwCommentPort))
+	}
+	return append(rules,
+		sigmaFirewallRule{Action: fwActionDrop, Direction: fwDirectionIn, IPProto: "tcp", Comment: fwCommentBase},
+		sigmaFirewallRule{Action: fwActionDrop, Direction: fwDirectionIn, IPProto: "udp", Comment: fwCommentBase},
+	)
+}
+
+func acceptRule(protocol, dstPort, comment string) sigmaFirewallRule {
+	return sigmaFirewallRule{
+		Action:    fwActionAccept,
+		Direction: fwDirectionIn,
+		IPProto:   protocol,
+		DstPort:   dstPort,
+		Comment:   comment,
+	}
+}
+
+// globalFirewallName returns the name of the policy shared by all
+// instances when the firewall mode is FwGlobal.
+func (c *environClient) globalFirewallName() string {
+	return fmt.Sprintf("juju-%s", c.uuid)
+}
+
+// machineFirewallName returns the name of the policy of the given
+// machine when the firewall mode is FwInstance.
+func (c *environClient) machineFirewallName(machineID string) string {
+	return fmt.Sprintf("juju-%s-machine-%s", c.uuid, machineID)
+}
+
+// setupFirewall attaches the firewall policy appropriate for the
+// environment's firewall mode to a newly created server.
+func (c *environClient) setupFirewall(serverUUID, machineID string) error {
+	var name string
+	switch c.config.FirewallMode() {
+	case config.FwGlobal:
+		name = c.globalFirewallName()
+	case config.FwInstance:
+		name = c.machineFirewallName(machineID)
+	default:
+		return nil
+	}
+	policy, err := c.ensureFirewallPolicy(name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(c.attachFirewallPolicy(serverUUID, policy.UUID))
+}
+
+// firewallPolicy returns the firewall policy of this environment with
+// the given name. A NotFound error is returned if there is none.
+func (c *environClient) firewallPolicy(name string) (*sigmaFirewallPolicy, error) {
+	policies, err := c.firewallPolicies()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, policy := range policies {
+		if policy.Name == name {
+			policy := policy
+			return &policy, nil
+		}
+	}
+	return nil, errors.NotFoundf("firewall policy %q", name)
+}
+
+// firewallPolicies returns all firewall policies of this environment.
+func (c *environClient) firewallPolicies() ([]sigmaFirewallPolicy, error) {
+	var policies []sigmaFirewallPolicy
+	if err := c.rest.list(fwPolicies, &policies); err != nil {
+		return nil, errors.Annotate(err, "cannot list firewall policies")
+	}
+	var result []sigmaFirewallPolicy
+	for _, policy := range policies {
+		if policy.Meta[jujuMetaEnvironment] == c.uuid {
+			result = append(result, policy)
+		}
+	}
+	return result, nil
+}
+
+// ensureFirewallPolicy returns the named firewall policy, creating it
+// with no opened ports if it does not exist yet. A new policy accepts
+// traffic from the same peers as the other policies of the environment.
+func (c *environClient) ensureFirewallPolicy(name string) (*sigmaFirewallPolicy, error) {
+	policy, err := c.firewallPolicy(name)
+	if !errors.IsNotFound(err) {
+		return policy, errors.Trace(err)
+	}
+	policies, err := c.firewallPolicies()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var peers []string
+	if len(policies) > 0 {
+		peers = policies[0].peers()
+	}
+	policy = &sigmaFirewallPolicy{
+		Name:  name,
+		Rules: firewallRules(c.config.APIPort(), c.config.StatePort(), peers, network.NewPortSet()),
+		Meta:  map[string]string{jujuMetaEnvironment: c.uuid},
+	}
+	var created sigmaFirewallPolicy
+	if err := c.rest.create(fwPolicies, policy, &created); err != nil {
+		return nil, errors.Annotatef(err, "cannot create firewall policy %q", name)
+	}
+	return &created, nil
+}
+
+// firewallPorts returns the port ranges opened in the named policy.
+// If the policy does not exist, no ports are open.
+func (c *environClient) firewallPorts(name string) ([]network.PortRange, error) {
+	policy, err := c.firewallPolicy(name)
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return policy.ports()
+}
+
+// changeFirewallPorts opens and closes the given port ranges in the
+// named policy, creating the policy if needed.
+func (c *environClient) changeFirewallPorts(name string, open, close []network.PortRange) error {
+	policy, err := c.ensureFirewallPolicy(name)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	current, err := policy.ports()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ports := network.NewPortSet(current...)
+	ports = ports.Union(network.NewPortSet(open...))
+	ports = ports.Difference(network.NewPortSet(close...))
+
+	policy.Rules = firewallRules(c.config.APIPort(), c.config.StatePort(), policy.peers(), ports)
+	if err := c.rest.update(fwPolicies, policy.UUID, policy, nil); err != nil {
+		return errors.Annotatef(err, "cannot update firewall policy %q", name)
+	}
+	return nil
+}
+
+// setFirewallPeers makes every firewall policy of this environment
+// accept all traffic from the given addresses, and only those, on top
+// of the opened ports.
+func (c *environClient) setFirewallPeers(peers []string) error {
+	policies, err := c.firewallPolicies()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	peers = append([]string(nil), peers...)
+	sort.Strings(peers)
+	for _, policy := range policies {
+		if stringsEqual(policy.peers(), peers) {
+			continue
+		}
+		ports, err := policy.ports()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		policy.Rules = firewallRules(c.config.APIPort(), c.config.StatePort(), peers, network.NewPortSet(ports...))
+		if err := c.rest.update(fwPolicies, policy.UUID, &policy, nil); err != nil {
+			return errors.Annotatef(err, "cannot update firewall policy %q", policy.Name)
+		}
+	}
+	return nil
+}
+
+func stringsEqual(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// removeFirewallPolicy deletes the named policy, if it exists.
+func (c *environClient) removeFirewallPolicy(name string) error {
+	policy, err := c.firewallPolicy(name)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Annotatef(c.rest.remove(fwPolicies, policy.UUID), "cannot remove firewall policy %q", name)
+}
+
+// attachFirewallPolicy sets the firewall policy of all NICs of the
+// given server. The server definition is round-tripped untouched
+// otherwise, since the API requires the whole definition on update.
+func (c *environClient) attachFirewallPolicy(serverUUID, policyUUID string) error {
+	var server map[string]interface{}
+	if err := c.rest.get(servers, serverUUID, &server); err != nil {
+		return errors.Trace(err)
+	}
+	nics, _ := server["nics"].([]interface{})
+	for _, nic := range nics {
+		if m, ok := nic.(map[string]interface{}); ok {
+			m["firewall_policy"] = policyUUID
+		}
+	}
+	if err := c.rest.update(servers, serverUUID, server, nil); err != nil {
+		return errors.Annotatef(err, "cannot attach firewall policy to server %q", serverUUID)
+	}
+	return nil
+}
+
+// serverFirewallPolicies returns the UUIDs of the firewall policies
+// attached to the NICs of the given server.
+func (c *environClient) serverFirewallPolicies(serverUUID string) ([]string, error) {
+	var server struct {
+		NICs []struct {
+			FirewallPolicy json.RawMessage `json:"firewall_policy"`
+		} `json:"nics"`
+	}
+	if err := c.rest.get(servers, serverUUID, &server); err != nil {
+		return nil, errors.Trace(err)
+	}
+	var uuids []string
+	for _, nic := range server.NICs {
+		// The policy is either a bare UUID or a resource reference.
+		var ref struct {
+			UUID string `json:"uuid"`
+		}
+		var uuid string
+		if err := json.Unmarshal(nic.FirewallPolicy, &uuid); err == nil && uuid != "" {
+			uuids = append(uuids, uuid)
+		} else if err := json.Unmarshal(nic.FirewallPolicy, &ref); err == nil && ref.UUID != "" {
+			uuids = append(uuids, ref.UUID)
+		}
+	}
+	return uuids, nil
+}
@@ -0,0 +1,219 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cloudsigma
+
+import (
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/testing"
+)
+
+type firewallSuite struct {
+	testing.BaseSuite
+	api *fakeSigmaAPI
+}
+
+var _ = gc.Suite(&firewallSuite{})
+
+func (s *firewallSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.api = newFakeSigmaAPI()
+	s.AddCleanup(func(*gc.C) { s.api.Close() })
+}
+
+func (s *firewallSuite) newClient(c *gc.C, mode string) *environClient {
+	ecfg := &environConfig{
+		Config: newConfig(c, validAttrs().Merge(testing.Attrs{"firewall-mode": mode})),
+	}
+	return &environClient{
+		rest:   s.api.restClient(),
+		uuid:   "f54aac3a-9dcd-4a0c-86b5-24091478478c",
+		config: ecfg,
+	}
+}
+
+func (s *firewallSuite) TestFirewallNames(c *gc.C) {
+	cli := s.newClient(c, "global")
+	c.Check(cli.globalFirewallName(), gc.Equals, "juju-f54aac3a-9dcd-4a0c-86b5-24091478478c")
+	c.Check(cli.machineFirewallName("1"), gc.Equals, "juju-f54aac3a-9dcd-4a0c-86b5-24091478478c-machine-1")
+}
+
+func (s *firewallSuite) TestFirewallRules(c *gc.C) {
+	ports := network.NewPortSet(
+		network.PortRange{FromPort: 80, ToPort: 80, Protocol: "tcp"},
+		network.PortRange{FromPort: 8000, ToPort: 8100, Protocol: "udp"},
+	)
+	rules := firewallRules(17070, 37017, []string{"10.0.0.1", "10.0.0.2"}, ports)
+	c.Assert(rules, gc.DeepEquals, []sigmaFirewallRule{
+		{Action: "accept", Direction: "in", IPProto: "tcp", DstPort: "22", Comment: "juju-base"},
+		{Action: "accept", Direction: "in", IPProto: "tcp", DstPort: "17070", Comment: "juju-base"},
+		{Action: "accept", Direction: "in", IPProto: "tcp", DstPort: "37017", Comment: "juju-base"},
+		{Action: "accept", Direction: "in", SrcIP: "10.0.0.1", Comment: "juju-peer"},
+		{Action: "accept", Direction: "in", SrcIP: "10.0.0.2", Comment: "juju-peer"},
+		{Action: "accept", Direction: "in", IPProto: "tcp", DstPort: "80", Comment: "juju-port"},
+		{Action: "accept", Direction: "in", IPProto: "udp", DstPort: "8000:8100", Comment: "juju-port"},
+		{Action: "drop", Direction: "in", IPProto: "tcp", Comment: "juju-base"},
+		{Action: "drop", Direction: "in", IPProto: "udp", Comment: "juju-base"},
+	})
+
+	policy := &sigmaFirewallPolicy{Name: "test", Rules: rules}
+	parsed, err := policy.ports()
+	c.Assert(err, gc.IsNil)
+	c.Assert(parsed, gc.DeepEquals, []network.PortRange{
+		{FromPort: 80, ToPort: 80, Protocol: "tcp"},
+		{FromPort: 8000, ToPort: 8100, Protocol: "udp"},
+	})
+	c.Assert(policy.peers(), gc.DeepEquals, []string{"10.0.0.1", "10.0.0.2"})
+}
+
+func (s *firewallSuite) TestChangeFirewallPorts(c *gc.C) {
+	cli := s.newClient(c, "global")
+	name := cli.globalFirewallName()
+
+	ports, err := cli.firewallPorts(name)
+	c.Assert(err, gc.IsNil)
+	c.Assert(ports, gc.HasLen, 0)
+
+	open := []network.PortRange{
+		{FromPort: 80, ToPort: 80, Protocol: "tcp"},
+		{FromPort: 443, ToPort: 443, Protocol: "tcp"},
+	}
+	err = cli.changeFirewallPorts(name, open, nil)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.api.policyNames(), gc.DeepEquals, []string{name})
+
+	ports, err = cli.firewallPorts(name)
+	c.Assert(err, gc.IsNil)
+	c.Assert(ports, gc.DeepEquals, open)
+
+	err = cli.changeFirewallPorts(name, nil, open[:1])
+	c.Assert(err, gc.IsNil)
+
+	ports, err = cli.firewallPorts(name)
+	c.Assert(err, gc.IsNil)
+	c.Assert(ports, gc.DeepEquals, open[1:])
+
+	err = cli.removeFirewallPolicy(name)
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.api.policyNames(), gc.HasLen, 0)
+}
+
+func (s *firewallSuite) TestSetFirewallPeers(c *gc.C) {
+	cli := s.newClient(c, "instance")
+	open := []network.PortRange{{FromPort: 80, ToPort: 80, Protocol: "tcp"}}
+	err := cli.changeFirewallPorts(cli.machineFirewallName("0"), open, nil)
+	c.Assert(err, gc.IsNil)
+
+	err = cli.setFirewallPeers([]string{"10.0.0.2", "10.0.0.1"})
+	c.Assert(err, gc.IsNil)
+	policy0, err := cli.firewallPolicy(cli.machineFirewallName("0"))
+	c.Assert(err, gc.IsNil)
+	c.Check(policy0.peers(), gc.DeepEquals, []string{"10.0.0.1", "10.0.0.2"})
+	ports, err := policy0.ports()
+	c.Assert(err, gc.IsNil)
+	c.Check(ports, gc.DeepEquals, open)
+
+	// New policies accept the peers of the environment, which are
+	// kept when ports are changed.
+	err = cli.changeFirewallPorts(cli.machineFirewallName("1"), open, nil)
+	c.Assert(err, gc.IsNil)
+	policy1, err := cli.firewallPolicy(cli.machineFirewallName("1"))
+	c.Assert(err, gc.IsNil)
+	c.Check(policy1.peers(), gc.DeepEquals, []string{"10.0.0.1", "10.0.0.2"})
+
+	err = cli.setFirewallPeers([]string{"10.0.0.1"})
+	c.Assert(err, gc.IsNil)
+	policy1, err = cli.firewallPolicy(cli.machineFirewallName("1"))
+	c.Assert(err, gc.IsNil)
+	c.Check(policy1.peers(), gc.DeepEquals, []string{"10.0.0.1"})
+	c.Check(policy1.Rules[len(policy1.Rules)-1].Action, gc.Equals, "drop")
+}
+
+func (s *firewallSuite) TestFirewallPolicyOtherEnvironment(c *gc.C) {
+	cli := s.newClient(c, "global")
+	other := s.newClient(c, "global")
+	other.uuid = "other-env"
+
+	name := cli.globalFirewallName()
+	err := other.rest.create(fwPolicies, &sigmaFirewallPolicy{
+		Name: name,
+		Meta: map[string]string{jujuMetaEnvironment: other.uuid},
+	}, nil)
+	c.Assert(err, gc.IsNil)
+
+	_, err = cli.firewallPolicy(name)
+	c.Assert(err, gc.ErrorMatches, `firewall policy ".*" not found`)
+}
+
+func (s *firewallSuite) TestSetupFirewallGlobal(c *gc.C) {
+	cli := s.newClient(c, "global")
+
+	err := cli.setupFirewall("server-0", "0")
+	c.Assert(err, gc.IsNil)
+	err = cli.setupFirewall("server-1", "1")
+	c.Assert(err, gc.IsNil)
+
+	policy, err := cli.firewallPolicy(cli.globalFirewallName())
+	c.Assert(err, gc.IsNil)
+	c.Check(s.api.serverPolicy("server-0"), gc.Equals, policy.UUID)
+	c.Check(s.api.serverPolicy("server-1"), gc.Equals, policy.UUID)
+}
+
+func (s *firewallSuite) TestSetupFirewallInstance(c *gc.C) {
+	cli := s.newClient(c, "instance")
+
+	err := cli.setupFirewall("server-0", "0")
+	c.Assert(err, gc.IsNil)
+	err = cli.setupFirewall("server-1", "1")
+	c.Assert(err, gc.IsNil)
+
+	policy0, err := cli.firewallPolicy(cli.machineFirewallName("0"))
+	c.Assert(err, gc.IsNil)
+	policy1, err := cli.firewallPolicy(cli.machineFirewallName("1"))
+	c.Assert(err, gc.IsNil)
+	c.Check(s.api.serverPolicy("server-0"), gc.Equals, policy0.UUID)
+	c.Check(s.api.serverPolicy("server-1"), gc.Equals, policy1.UUID)
+
+	uuids, err := cli.serverFirewallPolicies("server-1")
+	c.Assert(err, gc.IsNil)
+	c.Assert(uuids, gc.DeepEquals, []string{policy1.UUID})
+}
+
+func (s *firewallSuite) TestSetupFirewallNone(c *gc.C) {
+	cli := s.newClient(c, "none")
+
+	err := cli.setupFirewall("server-0", "0")
+	c.Assert(err, gc.IsNil)
+	c.Check(s.api.policyNames(), gc.HasLen, 0)
+	c.Check(s.api.serverPolicy("server-0"), gc.IsNil)
+}
+
+func (s *firewallSuite) TestInstancePorts(c *gc.C) {
+	inst := sigmaInstance{client: s.newClient(c, "instance")}
+
+	ports := []network.PortRange{{FromPort: 8080, ToPort: 8080, Protocol: "tcp"}}
+	err := inst.OpenPorts("2", ports)
+	c.Assert(err, gc.IsNil)
+
+	opened, err := inst.Ports("2")
+	c.Assert(err, gc.IsNil)
+	c.Assert(opened, gc.DeepEquals, ports)
+
+	err = inst.ClosePorts("2", ports)
+	c.Assert(err, gc.IsNil)
+
+	opened, err = inst.Ports("2")
+	c.Assert(err, gc.IsNil)
+	c.Assert(opened, gc.HasLen, 0)
+}
+
+func (s *firewallSuite) TestInstancePortsWrongMode(c *gc.C) {
+	inst := sigmaInstance{client: s.newClient(c, "global")}
+
+	err := inst.OpenPorts("2", nil)
+	c.Assert(err, gc.ErrorMatches, `invalid firewall mode "global" for opening ports on instance`)
+	_, err = inst.Ports("2")
+	c.Assert(err, gc.ErrorMatches, `invalid firewall mode "global" for retrieving ports from instance`)
+}
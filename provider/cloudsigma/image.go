@@ -0,0 +1,119 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cloudsigma
+
+import (
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/environs/imagemetadata"
+)
+
+// Besides the library images found through simplestreams, root drives
+// can be cloned from a private image: a drive of the account, named by
+// its UUID or by a tag with the "tag:" prefix. A private image declares
+// the architecture and series it holds in its meta.
+
+const (
+	// imageTagPrefix marks a private image named by tag.
+	imageTagPrefix = "tag:"
+
+	// jujuMetaImageArch is the meta key of a private image holding
+	// its architecture, e.g. "amd64".
+	jujuMetaImageArch = "juju-arch"
+	// jujuMetaImageSeries is the meta key of a private image holding
+	// the comma separated series it can run, e.g. "trusty".
+	jujuMetaImageSeries = "juju-series"
+)
+
+// validateImageRef checks the syntax of a private image reference.
+func validateImageRef(ref string) error {
+	if strings.HasPrefix(ref, imageTagPrefix) && strings.TrimPrefix(ref, imageTagPrefix) == "" {
+		return errors.NotValidf("image %q without tag name", ref)
+	}
+	return nil
+}
+
+// imageDrive returns the drive of the private image with the given
+// reference.
+func (c *environClient) imageDrive(ref string) (*sigmaDrive, error) {
+	if !strings.HasPrefix(ref, imageTagPrefix) {
+		drive, err := c.drive(ref)
+		if errors.IsNotFound(err) {
+			return nil, errors.NotFoundf("image %q", ref)
+		} else if err != nil {
+			return nil, errors.Annotatef(err, "cannot get image %q", ref)
+		}
+		return drive, nil
+	}
+
+	name := strings.TrimPrefix(ref, imageTagPrefix)
+	var all []sigmaTag
+	if err := c.rest.list(tags, &all); err != nil {
+		return nil, errors.Annotate(err, "cannot list tags")
+	}
+	var found []*sigmaDrive
+	for _, tag := range all {
+		if tag.Name != name {
+			continue
+		}
+		// Tags may label other kinds of resources too.
+		for _, uuid := range tag.Resources {
+			drive, err := c.drive(uuid)
+			if errors.IsNotFound(err) {
+				continue
+			} else if err != nil {
+				return nil, errors.Annotatef(err, "cannot get image %q", ref)
+			}
+			found = append(found, drive)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, errors.NotFoundf("image %q", ref)
+	case 1:
+		return found[0], nil
+	}
+	return nil, errors.Errorf("image %q is ambiguous: tag %q labels %d drives", ref, name, len(found))
+}
+
+// privateImage returns the metadata of the private image with the
+// given reference, after checking that it can run the given series
+// and one of the given architectures.
+func (c *environClient) privateImage(ref, series string, arches []string) (*imagemetadata.ImageMetadata, error) {
+	drive, err := c.imageDrive(ref)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if drive.Media != driveMediaDisk {
+		return nil, errors.Errorf("image %q is not a disk", ref)
+	}
+
+	arch := drive.Meta[jujuMetaImageArch]
+	if arch == "" {
+		return nil, errors.Errorf("image %q has no %q meta", ref, jujuMetaImageArch)
+	}
+	supported := false
+	for _, a := range arches {
+		supported = supported || a == arch
+	}
+	if !supported {
+		return nil, errors.Errorf("image %q has architecture %q, need one of %v", ref, arch, arches)
+	}
+
+	supported = false
+	for _, s := range strings.Split(drive.Meta[jujuMetaImageSeries], ",") {
+		supported = supported || strings.TrimSpace(s) == series
+	}
+	if !supported {
+		return nil, errors.Errorf("image %q does not support series %q", ref, series)
+	}
+
+	return &imagemetadata.ImageMetadata{
+		Id:     drive.UUID,
+		Arch:   arch,
+		Region: c.config.region(),
+	}, nil
+}
@@ -0,0 +1,88 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cloudsigma
+
+import (
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs/imagemetadata"
+	"github.com/juju/juju/juju/arch"
+	"github.com/juju/juju/testing"
+)
+
+type imageSuite struct {
+	testing.BaseSuite
+	api    *fakeSigmaAPI
+	client *environClient
+}
+
+var _ = gc.Suite(&imageSuite{})
+
+func (s *imageSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.api = newFakeSigmaAPI()
+	s.AddCleanup(func(*gc.C) { s.api.Close() })
+	s.client = &environClient{
+		rest:   s.api.restClient(),
+		config: &environConfig{attrs: map[string]interface{}{"region": "zrh"}},
+	}
+}
+
+func (s *imageSuite) addImage(c *gc.C, name string, meta map[string]string) string {
+	var created sigmaDrive
+	err := s.client.rest.create(drives, &sigmaDrive{Name: name, Size: 1, Media: driveMediaDisk, Meta: meta}, &created)
+	c.Assert(err, gc.IsNil)
+	return created.UUID
+}
+
+func (s *imageSuite) TestPrivateImage(c *gc.C) {
+	uuid := s.addImage(c, "golden", map[string]string{
+		jujuMetaImageArch:   arch.AMD64,
+		jujuMetaImageSeries: "precise, trusty",
+	})
+	err := s.client.tagResource(uuid, []string{"golden"})
+	c.Assert(err, gc.IsNil)
+
+	expect := &imagemetadata.ImageMetadata{Id: uuid, Arch: arch.AMD64, Region: "zrh"}
+	img, err := s.client.privateImage(uuid, "trusty", []string{arch.AMD64, arch.I386})
+	c.Assert(err, gc.IsNil)
+	c.Check(img, gc.DeepEquals, expect)
+
+	img, err = s.client.privateImage("tag:golden", "precise", []string{arch.AMD64})
+	c.Assert(err, gc.IsNil)
+	c.Check(img, gc.DeepEquals, expect)
+}
+
+func (s *imageSuite) TestPrivateImageInvalid(c *gc.C) {
+	uuid := s.addImage(c, "golden", map[string]string{
+		jujuMetaImageArch:   arch.AMD64,
+		jujuMetaImageSeries: "trusty",
+	})
+	bare := s.addImage(c, "bare", nil)
+
+	_, err := s.client.privateImage(uuid, "vivid", []string{arch.AMD64})
+	c.Check(err, gc.ErrorMatches, `image ".*" does not support series "vivid"`)
+	_, err = s.client.privateImage(uuid, "trusty", []string{arch.I386})
+	c.Check(err, gc.ErrorMatches, `image ".*" has architecture "amd64", need one of \[i386\]`)
+	_, err = s.client.privateImage(bare, "trusty", []string{arch.AMD64})
+	c.Check(err, gc.ErrorMatches, `image ".*" has no "juju-arch" meta`)
+	_, err = s.client.privateImage("missing", "trusty", []string{arch.AMD64})
+	c.Check(err, gc.ErrorMatches, `image "missing" not found`)
+	_, err = s.client.privateImage("tag:missing", "trusty", []string{arch.AMD64})
+	c.Check(err, gc.ErrorMatches, `image "tag:missing" not found`)
+
+	err = s.client.tagResource(uuid, []string{"golden"})
+	c.Assert(err, gc.IsNil)
+	err = s.client.tagResource(bare, []string{"golden"})
+	c.Assert(err, gc.IsNil)
+	_, err = s.client.privateImage("tag:golden", "trusty", []string{arch.AMD64})
+	c.Check(err, gc.ErrorMatches, `image "tag:golden" is ambiguous: tag "golden" labels 2 drives`)
+}
+
+func (s *imageSuite) TestValidateImageRef(c *gc.C) {
+	c.Check(validateImageRef(""), gc.IsNil)
+	c.Check(validateImageRef("f54aac3a-9dcd-4a0c-86b5-24091478478c"), gc.IsNil)
+	c.Check(validateImageRef("tag:golden"), gc.IsNil)
+	c.Check(validateImageRef("tag:"), gc.ErrorMatches, `image "tag:" without tag name not valid`)
+}
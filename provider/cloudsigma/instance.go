@@ -7,6 +7,7 @@ import (
 	"github.com/altoros/gosigma"
 	"github.com/juju/errors"
 
+	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/network"
 )
@@ -15,6 +16,7 @@ var _ instance.Instance = (*sigmaInstance)(nil)
 
 type sigmaInstance struct {
 	server gosigma.Server
+	client *environClient
 }
 
 var ErrNoDNSName = errors.New("IPv4 address not found")
@@ -66,20 +68,50 @@ func (i sigmaInstance) Addresses() ([]network.Address, error) {
 // OpenPorts opens the given ports on the instance, which
 // should have been started with the given machine id.
 func (i sigmaInstance) OpenPorts(machineID string, ports []network.PortRange) error {
-	return errors.NotImplementedf("OpenPorts")
+	if err := i.checkFirewallMode("opening ports on"); err != nil {
+		return errors.Trace(err)
+	}
+	err := i.client.changeFirewallPorts(i.client.machineFirewallName(machineID), ports, nil)
+	return errors.Trace(err)
 }
 
 // ClosePorts closes the given ports on the instance, which
 // should have been started with the given machine id.
 func (i sigmaInstance) ClosePorts(machineID string, ports []network.PortRange) error {
-	return errors.NotImplementedf("ClosePorts")
+	if err := i.checkFirewallMode("closing ports on"); err != nil {
+		return errors.Trace(err)
+	}
+	err := i.client.changeFirewallPorts(i.client.machineFirewallName(machineID), nil, ports)
+	return errors.Trace(err)
 }
 
 // Ports returns the set of ports open on the instance, which
 // should have been started with the given machine id.
 // The ports are returned as sorted by SortPorts.
 func (i sigmaInstance) Ports(machineID string) ([]network.PortRange, error) {
-	return nil, errors.NotImplementedf("Ports")
+	if err := i.checkFirewallMode("retrieving ports from"); err != nil {
+		return nil, errors.Trace(err)
+	}
+	ports, err := i.client.firewallPorts(i.client.machineFirewallName(machineID))
+	return ports, errors.Trace(err)
+}
+
+func (i sigmaInstance) checkFirewallMode(action string) error {
+	if i.client == nil {
+		return errors.New("invalid instance")
+	}
+	if mode := i.client.config.FirewallMode(); mode != config.FwInstance {
+		return errors.Errorf("invalid firewall mode %q for %s instance", mode, action)
+	}
+	return nil
+}
+
+// zone returns the availability zone of the instance.
+func (i sigmaInstance) zone() string {
+	if i.client == nil {
+		return ""
+	}
+	return i.client.config.region()
 }
 
 func (i sigmaInstance) findIPv4() string {
@@ -101,6 +133,10 @@ func (i *sigmaInstance) hardware(arch string, driveSize uint64) (*instance.Hardw
 		Arch:     &arch,
 	}
 
+	if zone := i.zone(); zone != "" {
+		hw.AvailabilityZone = &zone
+	}
+
 	diskSpace := driveSize / gosigma.Megabyte
 	if diskSpace > 0 {
 		hw.RootDisk = &diskSpace
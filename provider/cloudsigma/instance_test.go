@@ -53,12 +53,12 @@ func (s *instanceSuite) SetUpTest(c *gc.C) {
 	server, err := cli.Server("f4ec5097-121e-44a7-a207-75bc02163260")
 	c.Assert(err, gc.IsNil)
 	c.Assert(server, gc.NotNil)
-	s.inst = &sigmaInstance{server}
+	s.inst = &sigmaInstance{server: server}
 
 	server, err = cli.Server("uuid-no-ip")
 	c.Assert(err, gc.IsNil)
 	c.Assert(server, gc.NotNil)
-	s.instWithoutIP = &sigmaInstance{server}
+	s.instWithoutIP = &sigmaInstance{server: server}
 }
 
 func (s *instanceSuite) TearDownTest(c *gc.C) {
@@ -101,11 +101,11 @@ func (s *instanceSuite) TestInstanceAddresses(c *gc.C) {
 }
 
 func (s *instanceSuite) TestInstancePorts(c *gc.C) {
-	c.Check(s.inst.OpenPorts("", nil), gc.ErrorMatches, "OpenPorts not implemented")
-	c.Check(s.inst.ClosePorts("", nil), gc.ErrorMatches, "ClosePorts not implemented")
+	c.Check(s.inst.OpenPorts("", nil), gc.ErrorMatches, "invalid instance")
+	c.Check(s.inst.ClosePorts("", nil), gc.ErrorMatches, "invalid instance")
 
 	_, err := s.inst.Ports("")
-	c.Check(err, gc.ErrorMatches, "Ports not implemented")
+	c.Check(err, gc.ErrorMatches, "invalid instance")
 }
 
 func (s *instanceSuite) TestInstanceHardware(c *gc.C) {
@@ -0,0 +1,196 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cloudsigma
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/environs/instances"
+	"github.com/juju/juju/juju/arch"
+)
+
+// CloudSigma servers are not bound to fixed sizes, so the instance types
+// below are just named profiles of CPU, RAM and disk. They can be
+// extended and overridden through the "instance-types" config attribute.
+
+// all instance types can run both amd64 and i386 images.
+var both = []string{arch.AMD64, arch.I386}
+
+// instanceTypeCost returns the relative cost of an instance type with
+// the given CPU power in MHz and RAM in MB, which is only used to pick
+// the cheapest matching type. Roughly, 1GHz of CPU costs as much as
+// 1GB of RAM.
+func instanceTypeCost(power, mem uint64) uint64 {
+	return power/20 + mem/20
+}
+
+// defaultInstanceTypes holds the built-in instance types.
+var defaultInstanceTypes = []instances.InstanceType{
+	{
+		Name:     "micro",
+		Arches:   both,
+		CpuCores: 1,
+		CpuPower: instances.CpuPower(1000),
+		Mem:      512,
+		Cost:     instanceTypeCost(1000, 512),
+	}, {
+		Name:     "small",
+		Arches:   both,
+		CpuCores: 1,
+		CpuPower: instances.CpuPower(defaultCPUPower),
+		Mem:      defaultMemoryGB * 1024,
+		Cost:     instanceTypeCost(defaultCPUPower, defaultMemoryGB*1024),
+	}, {
+		Name:     "medium",
+		Arches:   both,
+		CpuCores: 2,
+		CpuPower: instances.CpuPower(2 * defaultCPUPower),
+		Mem:      4096,
+		Cost:     instanceTypeCost(2*defaultCPUPower, 4096),
+	}, {
+		Name:     "large",
+		Arches:   both,
+		CpuCores: 4,
+		CpuPower: instances.CpuPower(4 * defaultCPUPower),
+		Mem:      8192,
+		Cost:     instanceTypeCost(4*defaultCPUPower, 8192),
+	}, {
+		Name:     "xlarge",
+		Arches:   both,
+		CpuCores: 8,
+		CpuPower: instances.CpuPower(8 * defaultCPUPower),
+		Mem:      16384,
+		Cost:     instanceTypeCost(8*defaultCPUPower, 16384),
+	}, {
+		Name:     "highmem",
+		Arches:   both,
+		CpuCores: 4,
+		CpuPower: instances.CpuPower(4 * defaultCPUPower),
+		Mem:      32768,
+		Cost:     instanceTypeCost(4*defaultCPUPower, 32768),
+	},
+}
+
+// parseInstanceTypes parses instance type definitions of the form
+//
+//	name: constraints; name: constraints; ...
+//
+// where the constraints are given in the syntax of juju constraints and
+// may set cpu-cores, cpu-power, mem and root-disk, e.g.
+//
+//	big: cpu-cores=4 cpu-power=8000 mem=8G root-disk=40G
+//
+// The cost of a type defined this way is derived from its CPU and RAM.
+func parseInstanceTypes(defs string) ([]instances.InstanceType, error) {
+	var itypes []instances.InstanceType
+	for _, def := range strings.Split(defs, ";") {
+		if strings.TrimSpace(def) == "" {
+			continue
+		}
+		pos := strings.IndexRune(def, ':')
+		if pos == -1 {
+			return nil, errors.Errorf("instance type %q: expected name: constraints", strings.TrimSpace(def))
+		}
+		name := strings.TrimSpace(def[:pos])
+		if name == "" {
+			return nil, errors.Errorf("instance type %q: name must not be empty", strings.TrimSpace(def))
+		}
+		cons, err := constraints.Parse(def[pos+1:])
+		if err != nil {
+			return nil, errors.Annotatef(err, "instance type %q", name)
+		}
+		if cons.CpuCores == nil || cons.Mem == nil {
+			return nil, errors.Errorf("instance type %q: cpu-cores and mem must be specified", name)
+		}
+		if cons.Arch != nil || cons.Container != nil || cons.HasInstanceType() ||
+			cons.Tags != nil || cons.Spaces != nil || cons.Networks != nil {
+			return nil, errors.Errorf("instance type %q: only cpu-cores, cpu-power, mem and root-disk may be specified", name)
+		}
+		itype := instances.InstanceType{
+			Name:     name,
+			Arches:   both,
+			CpuCores: *cons.CpuCores,
+			Mem:      *cons.Mem,
+		}
+		power := *cons.CpuCores * defaultCPUPower
+		if cons.CpuPower != nil {
+			power = *cons.CpuPower
+		}
+		itype.CpuPower = instances.CpuPower(power)
+		if cons.RootDisk != nil {
+			itype.RootDisk = *cons.RootDisk
+		}
+		itype.Cost = instanceTypeCost(power, itype.Mem)
+		itypes = append(itypes, itype)
+	}
+	return itypes, nil
+}
+
+// instanceTypes returns the built-in instance types, extended and
+// overridden by the ones configured for the environment.
+func (c environConfig) instanceTypes() ([]instances.InstanceType, error) {
+	defs, _ := c.attrs["instance-types"].(string)
+	configured, err := parseInstanceTypes(defs)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	named := make(map[string]instances.InstanceType)
+	for _, itype := range defaultInstanceTypes {
+		named[itype.Name] = itype
+	}
+	for _, itype := range configured {
+		named[itype.Name] = itype
+	}
+	itypes := make([]instances.InstanceType, 0, len(named))
+	for _, itype := range named {
+		itypes = append(itypes, itype)
+	}
+	sort.Sort(byName(itypes))
+	return itypes, nil
+}
+
+type byName []instances.InstanceType
+
+func (b byName) Len() int           { return len(b) }
+func (b byName) Less(i, j int) bool { return b[i].Name < b[j].Name }
+func (b byName) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+
+// instanceTypeConstraints returns the constraints with the unset CPU,
+// RAM and disk values filled in from the instance type they name, if
+// any. Constraints without an instance type are passed through, since
+// servers can be sized freely and the unset values get the defaults.
+// Tags are not considered for matching, since they are applied to
+// servers rather than selecting them.
+func instanceTypeConstraints(itypes []instances.InstanceType, region string, cons constraints.Value) (constraints.Value, error) {
+	if !cons.HasInstanceType() {
+		return cons, nil
+	}
+	matchCons := cons
+	matchCons.Tags = nil
+	matching, err := instances.MatchingInstanceTypes(itypes, region, matchCons)
+	if err != nil {
+		return cons, errors.Trace(err)
+	}
+	itype := matching[0]
+	logger.Debugf("using instance type %q for constraints %q", itype.Name, cons)
+
+	if cons.CpuCores == nil {
+		cons.CpuCores = &itype.CpuCores
+	}
+	if cons.CpuPower == nil && itype.CpuPower != nil {
+		power := *itype.CpuPower
+		cons.CpuPower = &power
+	}
+	if cons.Mem == nil {
+		cons.Mem = &itype.Mem
+	}
+	if cons.RootDisk == nil && itype.RootDisk > 0 {
+		cons.RootDisk = &itype.RootDisk
+	}
+	return cons, nil
+}
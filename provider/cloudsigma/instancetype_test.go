@@ -0,0 +1,153 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cloudsigma
+
+import (
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/instances"
+	"github.com/juju/juju/juju/arch"
+	"github.com/juju/juju/testing"
+)
+
+type instanceTypeSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&instanceTypeSuite{})
+
+var parseInstanceTypesTests = []struct {
+	defs   string
+	expect []instances.InstanceType
+	err    string
+}{{
+	defs: "",
+}, {
+	defs: "big: cpu-cores=4 mem=8G",
+	expect: []instances.InstanceType{{
+		Name: "big", Arches: both, CpuCores: 4, CpuPower: instances.CpuPower(8000), Mem: 8192, Cost: 809,
+	}},
+}, {
+	defs: " fast: cpu-cores=2 cpu-power=6000 mem=4G root-disk=20G ; tiny:cpu-cores=1 mem=256M;",
+	expect: []instances.InstanceType{{
+		Name: "fast", Arches: both, CpuCores: 2, CpuPower: instances.CpuPower(6000), Mem: 4096, RootDisk: 20480, Cost: 504,
+	}, {
+		Name: "tiny", Arches: both, CpuCores: 1, CpuPower: instances.CpuPower(2000), Mem: 256, Cost: 112,
+	}},
+}, {
+	defs: "big",
+	err:  `instance type "big": expected name: constraints`,
+}, {
+	defs: ": cpu-cores=4 mem=8G",
+	err:  `instance type ": cpu-cores=4 mem=8G": name must not be empty`,
+}, {
+	defs: "big: mem=8G",
+	err:  `instance type "big": cpu-cores and mem must be specified`,
+}, {
+	defs: "big: cpu-cores=4 mem=8G arch=amd64",
+	err:  `instance type "big": only cpu-cores, cpu-power, mem and root-disk may be specified`,
+}, {
+	defs: "big: cpu-cores=many mem=8G",
+	err:  `instance type "big": bad "cpu-cores" constraint: .*`,
+}}
+
+func (s *instanceTypeSuite) TestParseInstanceTypes(c *gc.C) {
+	for i, test := range parseInstanceTypesTests {
+		c.Logf("test %d: %q", i, test.defs)
+		itypes, err := parseInstanceTypes(test.defs)
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Check(err, gc.IsNil)
+		c.Check(itypes, gc.DeepEquals, test.expect)
+	}
+}
+
+func (s *instanceTypeSuite) TestInstanceTypes(c *gc.C) {
+	ecfg := &environConfig{attrs: map[string]interface{}{
+		"instance-types": "small: cpu-cores=1 mem=1G; big: cpu-cores=16 mem=64G",
+	}}
+	itypes, err := ecfg.instanceTypes()
+	c.Assert(err, gc.IsNil)
+
+	var names []string
+	for _, itype := range itypes {
+		names = append(names, itype.Name)
+		if itype.Name == "small" {
+			c.Check(itype.Mem, gc.Equals, uint64(1024))
+		}
+	}
+	c.Assert(names, gc.DeepEquals, []string{"big", "highmem", "large", "medium", "micro", "small", "xlarge"})
+}
+
+var instanceTypeConstraintsTests = []struct {
+	cons   string
+	expect string
+	err    string
+}{{
+	cons:   "",
+	expect: "",
+}, {
+	cons:   "instance-type=large",
+	expect: "instance-type=large cpu-cores=4 cpu-power=8000 mem=8192M",
+}, {
+	cons:   "instance-type=large root-disk=40G",
+	expect: "instance-type=large cpu-cores=4 cpu-power=8000 mem=8192M root-disk=40960M",
+}, {
+	cons:   "mem=20G",
+	expect: "mem=20480M",
+}, {
+	cons:   "cpu-cores=2 root-disk=10G",
+	expect: "cpu-cores=2 root-disk=10240M",
+}, {
+	cons:   "tags=web,db",
+	expect: "tags=web,db",
+}, {
+	cons: "instance-type=huge",
+	err:  `no instance types in zrh matching constraints "instance-type=huge"`,
+}}
+
+func (s *instanceTypeSuite) TestInstanceTypeConstraints(c *gc.C) {
+	for i, test := range instanceTypeConstraintsTests {
+		c.Logf("test %d: %q", i, test.cons)
+		cons, err := instanceTypeConstraints(defaultInstanceTypes, "zrh", constraints.MustParse(test.cons))
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Check(err, gc.IsNil)
+		c.Check(cons, gc.DeepEquals, constraints.MustParse(test.expect))
+	}
+}
+
+func (s *instanceTypeSuite) TestConstraintsValidator(c *gc.C) {
+	s.PatchValue(&newClient, func(*environConfig) (*environClient, error) {
+		return nil, nil
+	})
+	env, err := environs.New(newConfig(c, validAttrs().Merge(testing.Attrs{
+		"instance-types": "big: cpu-cores=16 mem=64G",
+	})))
+	c.Assert(err, gc.IsNil)
+	env.(*environ).supportedArchitectures = []string{arch.AMD64}
+
+	validator, err := env.ConstraintsValidator()
+	c.Assert(err, gc.IsNil)
+
+	unsupported, err := validator.Validate(constraints.MustParse("instance-type=big tags=web"))
+	c.Check(err, gc.IsNil)
+	c.Check(unsupported, gc.HasLen, 0)
+
+	_, err = validator.Validate(constraints.MustParse("instance-type=huge"))
+	c.Check(err, gc.ErrorMatches, `invalid constraint value: instance-type=huge\nvalid values are: .*`)
+
+	_, err = validator.Validate(constraints.MustParse("instance-type=big mem=8G"))
+	c.Check(err, gc.ErrorMatches, `ambiguous constraints: "instance-type" overlaps with "mem"`)
+
+	unsupported, err = validator.Validate(constraints.MustParse("container=lxc"))
+	c.Check(err, gc.IsNil)
+	c.Check(unsupported, gc.DeepEquals, []string{"container"})
+}
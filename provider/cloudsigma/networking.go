@@ -0,0 +1,423 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cloudsigma
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/network"
+)
+
+// The private networks of a CloudSigma account are VLANs, which are
+// exposed as subnets. CloudSigma does not manage the addresses used on
+// a VLAN, so the CIDR of a VLAN and the range of addresses juju may
+// allocate to containers on it are taken from the VLAN's meta, which
+// can be edited in the CloudSigma web UI.
+
+var _ environs.NetworkingEnviron = (*environ)(nil)
+
+const (
+	// jujuMetaCIDR is the meta key of a VLAN holding its CIDR.
+	jujuMetaCIDR = "juju-cidr"
+	// jujuMetaIPRange is the meta key of a VLAN holding the range of
+	// addresses juju may allocate, in the form "<low>-<high>".
+	jujuMetaIPRange = "juju-ip-range"
+	// jujuMetaAddress prefixes the meta keys of a VLAN recording the
+	// addresses allocated by juju. The value is the hostname.
+	jujuMetaAddress = "juju-address-"
+
+	nicConfDHCP   = "dhcp"
+	nicConfStatic = "static"
+	nicConfManual = "manual"
+)
+
+// sigmaVLAN is a CloudSigma VLAN, i.e. a private network.
+type sigmaVLAN struct {
+	UUID string            `json:"uuid"`
+	Meta map[string]string `json:"meta"`
+}
+
+// ipRange returns the range of addresses that juju may allocate on
+// the VLAN, or nils if none is set.
+func (v *sigmaVLAN) ipRange() (low, high net.IP, err error) {
+	value := v.Meta[jujuMetaIPRange]
+	if value == "" {
+		return nil, nil, nil
+	}
+	parts := strings.Split(value, "-")
+	if len(parts) == 2 {
+		low, high = net.ParseIP(strings.TrimSpace(parts[0])), net.ParseIP(strings.TrimSpace(parts[1]))
+	}
+	if low == nil || high == nil || bytes.Compare(low.To16(), high.To16()) > 0 {
+		return nil, nil, errors.NotValidf("IP range %q of VLAN %q", value, v.UUID)
+	}
+	return low, high, nil
+}
+
+// subnetInfo returns the VLAN as a subnet of the given zone. If the
+// meta of the VLAN is invalid, the subnet is returned along with the
+// error, without the CIDR or IP range found invalid.
+func (v *sigmaVLAN) subnetInfo(zone string) (network.SubnetInfo, error) {
+	info := network.SubnetInfo{
+		ProviderId:        network.Id(v.UUID),
+		CIDR:              v.Meta[jujuMetaCIDR],
+		AvailabilityZones: []string{zone},
+	}
+	if info.CIDR == "" {
+		return info, nil
+	}
+	_, ipNet, err := net.ParseCIDR(info.CIDR)
+	if err != nil {
+		cidr := info.CIDR
+		info.CIDR = ""
+		return info, errors.NotValidf("CIDR %q of VLAN %q", cidr, v.UUID)
+	}
+	low, high, err := v.ipRange()
+	if err != nil {
+		return info, errors.Trace(err)
+	}
+	if low != nil && !(ipNet.Contains(low) && ipNet.Contains(high)) {
+		return info, errors.NotValidf("IP range %q of VLAN %q outside of %s", v.Meta[jujuMetaIPRange], v.UUID, info.CIDR)
+	}
+	info.AllocatableIPLow, info.AllocatableIPHigh = low, high
+	return info, nil
+}
+
+// sigmaIP is a static IP subscribed to by the account. The UUID of
+// the IP is the address itself.
+type sigmaIP struct {
+	UUID    string          `json:"uuid"`
+	Netmask int             `json:"netmask"`
+	Gateway string          `json:"gateway"`
+	Server  json.RawMessage `json:"server"`
+}
+
+// sigmaServerNIC is a NIC of a server. References to resources are
+// either bare UUIDs or resource references.
+type sigmaServerNIC struct {
+	IPv4Conf *struct {
+		Conf string          `json:"conf"`
+		IP   json.RawMessage `json:"ip"`
+	} `json:"ip_v4_conf"`
+	MAC     string          `json:"mac"`
+	VLAN    json.RawMessage `json:"vlan"`
+	Runtime *struct {
+		IPv4 json.RawMessage `json:"ip_v4"`
+	} `json:"runtime"`
+}
+
+// resourceUUID returns the UUID of a resource given either as a bare
+// UUID or as a resource reference.
+func resourceUUID(raw json.RawMessage) string {
+	var uuid string
+	if err := json.Unmarshal(raw, &uuid); err == nil {
+		return uuid
+	}
+	var ref struct {
+		UUID string `json:"uuid"`
+	}
+	if err := json.Unmarshal(raw, &ref); err == nil {
+		return ref.UUID
+	}
+	return ""
+}
+
+// vlans returns all VLANs of the account.
+func (c *environClient) vlans() ([]sigmaVLAN, error) {
+	var all []sigmaVLAN
+	if err := c.rest.list(vlans, &all); err != nil {
+		return nil, errors.Annotate(err, "cannot list VLANs")
+	}
+	return all, nil
+}
+
+// vlan returns the VLAN with the given UUID.
+func (c *environClient) vlan(uuid string) (*sigmaVLAN, error) {
+	var vlan sigmaVLAN
+	if err := c.rest.get(vlans, uuid, &vlan); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &vlan, nil
+}
+
+// staticIP returns the static IP with the given address.
+func (c *environClient) staticIP(addr string) (*sigmaIP, error) {
+	var ip sigmaIP
+	if err := c.rest.get(ips, addr, &ip); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &ip, nil
+}
+
+// serverNICs returns the NICs of the given server.
+func (c *environClient) serverNICs(serverUUID string) ([]sigmaServerNIC, error) {
+	var server struct {
+		NICs []sigmaServerNIC `json:"nics"`
+	}
+	if err := c.rest.get(servers, serverUUID, &server); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return server.NICs, nil
+}
+
+// Subnets is specified on environs.Networking.
+func (env *environ) Subnets(instId instance.Id, subnetIds []network.Id) ([]network.SubnetInfo, error) {
+	var onInstance map[string]bool
+	clients := env.clients()
+	if instId != instance.UnknownId {
+		inst, err := env.instance(instId)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		nics, err := inst.client.serverNICs(string(instId))
+		if err != nil {
+			return nil, errors.Annotatef(err, "cannot get NICs of instance %q", instId)
+		}
+		onInstance = make(map[string]bool)
+		for _, nic := range nics {
+			onInstance[resourceUUID(nic.VLAN)] = true
+		}
+		clients = []*environClient{inst.client}
+	}
+
+	wanted := make(map[network.Id]bool)
+	for _, id := range subnetIds {
+		wanted[id] = false
+	}
+
+	var results []network.SubnetInfo
+	for _, client := range clients {
+		sigmaVLANs, err := client.vlans()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for _, vlan := range sigmaVLANs {
+			id := network.Id(vlan.UUID)
+			if _, ok := wanted[id]; len(subnetIds) > 0 && !ok {
+				continue
+			}
+			if onInstance != nil && !onInstance[vlan.UUID] {
+				continue
+			}
+			wanted[id] = true
+			info, err := vlan.subnetInfo(client.config.region())
+			if err != nil {
+				logger.Warningf("ignoring %v", err)
+			}
+			results = append(results, info)
+		}
+	}
+
+	var missing []string
+	for _, id := range subnetIds {
+		if !wanted[id] {
+			missing = append(missing, string(id))
+		}
+	}
+	if len(missing) > 0 {
+		return nil, errors.NotFoundf("subnets %v", missing)
+	}
+	return results, nil
+}
+
+// NetworkInterfaces is specified on environs.Networking.
+func (env *environ) NetworkInterfaces(instId instance.Id) ([]network.InterfaceInfo, error) {
+	inst, err := env.instance(instId)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	nics, err := inst.client.serverNICs(string(instId))
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot get NICs of instance %q", instId)
+	}
+
+	result := make([]network.InterfaceInfo, len(nics))
+	for i, nic := range nics {
+		info := network.InterfaceInfo{
+			DeviceIndex:   i,
+			MACAddress:    nic.MAC,
+			InterfaceName: fmt.Sprintf("eth%d", i),
+			ProviderId:    network.Id(fmt.Sprintf("%s-nic-%d", instId, i)),
+		}
+		if uuid := resourceUUID(nic.VLAN); uuid != "" {
+			info.ConfigType = network.ConfigManual
+			info.ProviderSubnetId = network.Id(uuid)
+			info.NetworkName = uuid
+			if vlan, err := inst.client.vlan(uuid); err != nil {
+				logger.Warningf("cannot get VLAN %q: %v", uuid, err)
+			} else {
+				info.CIDR = vlan.Meta[jujuMetaCIDR]
+			}
+		}
+		if nic.IPv4Conf != nil {
+			switch nic.IPv4Conf.Conf {
+			case nicConfDHCP:
+				info.ConfigType = network.ConfigDHCP
+			case nicConfStatic:
+				info.ConfigType = network.ConfigStatic
+				info.Address = network.NewScopedAddress(resourceUUID(nic.IPv4Conf.IP), network.ScopePublic)
+			case nicConfManual:
+				info.ConfigType = network.ConfigManual
+			}
+		}
+		if info.Address.Value == "" && nic.Runtime != nil {
+			if ip := resourceUUID(nic.Runtime.IPv4); ip != "" {
+				info.Address = network.NewAddress(ip)
+			}
+		}
+		result[i] = info
+	}
+	return result, nil
+}
+
+// SupportsAddressAllocation is specified on environs.Networking.
+// Addresses can only be allocated on VLANs with an IP range set.
+func (env *environ) SupportsAddressAllocation(subnetId network.Id) (bool, error) {
+	if !environs.AddressAllocationEnabled() {
+		return false, errors.NotSupportedf("address allocation")
+	}
+	var ids []network.Id
+	if subnetId != network.AnySubnet {
+		ids = []network.Id{subnetId}
+	}
+	subnets, err := env.Subnets(instance.UnknownId, ids)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	for _, subnet := range subnets {
+		if subnet.AllocatableIPHigh != nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SupportsSpaces is specified on environs.Networking.
+func (env *environ) SupportsSpaces() (bool, error) {
+	return true, nil
+}
+
+// AllocateAddress is specified on environs.Networking. The address is
+// recorded in the VLAN's meta, so that it is not handed out twice to
+// environments sharing the VLAN.
+func (env *environ) AllocateAddress(instId instance.Id, subnetId network.Id, addr network.Address, _, hostname string) (err error) {
+	if !environs.AddressAllocationEnabled() {
+		return errors.NotSupportedf("address allocation")
+	}
+	defer errors.DeferredAnnotatef(&err, "failed to allocate address %q for instance %q", addr, instId)
+
+	client, vlan, err := env.instanceVLAN(instId, subnetId)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	low, high, err := vlan.ipRange()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	ip := net.ParseIP(addr.Value)
+	if low == nil || ip == nil ||
+		bytes.Compare(ip.To16(), low.To16()) < 0 || bytes.Compare(ip.To16(), high.To16()) > 0 {
+		return environs.ErrIPAddressUnavailable
+	}
+	key := jujuMetaAddress + addr.Value
+	if holder, ok := vlan.Meta[key]; ok && holder != hostname {
+		return environs.ErrIPAddressUnavailable
+	}
+	if vlan.Meta == nil {
+		vlan.Meta = make(map[string]string)
+	}
+	vlan.Meta[key] = hostname
+	return errors.Trace(client.rest.update(vlans, vlan.UUID, vlan, nil))
+}
+
+// ReleaseAddress is specified on environs.Networking.
+func (env *environ) ReleaseAddress(instId instance.Id, subnetId network.Id, addr network.Address, _ string) (err error) {
+	if !environs.AddressAllocationEnabled() {
+		return errors.NotSupportedf("address allocation")
+	}
+	defer errors.DeferredAnnotatef(&err, "failed to release address %q from instance %q", addr, instId)
+
+	// The instance may be gone already, so look for the VLAN in all
+	// availability zones.
+	for _, client := range env.clients() {
+		vlan, err := client.vlan(string(subnetId))
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return errors.Trace(err)
+		}
+		key := jujuMetaAddress + addr.Value
+		if _, ok := vlan.Meta[key]; !ok {
+			return nil
+		}
+		delete(vlan.Meta, key)
+		return errors.Trace(client.rest.update(vlans, vlan.UUID, vlan, nil))
+	}
+	return errors.NotFoundf("subnet %q", subnetId)
+}
+
+// instance returns the instance with the given id, from any of the
+// availability zones.
+func (env *environ) instance(id instance.Id) (*sigmaInstance, error) {
+	m, err := env.instanceMap()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	inst, ok := m[string(id)]
+	if !ok {
+		return nil, errors.NotFoundf("instance %q", id)
+	}
+	return &inst, nil
+}
+
+// instanceVLAN returns the VLAN with the given UUID, along with the
+// client of its availability zone, checking that the instance has a
+// NIC on it.
+func (env *environ) instanceVLAN(instId instance.Id, subnetId network.Id) (*environClient, *sigmaVLAN, error) {
+	inst, err := env.instance(instId)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	nics, err := inst.client.serverNICs(string(instId))
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	for _, nic := range nics {
+		if resourceUUID(nic.VLAN) != string(subnetId) {
+			continue
+		}
+		vlan, err := inst.client.vlan(string(subnetId))
+		if err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+		return inst.client, vlan, nil
+	}
+	return nil, nil, errors.NotFoundf("NIC on subnet %q", subnetId)
+}
+
+// startVLANs returns the VLANs of the given zone that a new instance
+// should be connected to, i.e. the subnets of the spaces it is
+// constrained to.
+func startVLANs(zone string, subnetsToZones map[network.Id][]string) []string {
+	var uuids []string
+	for id, zones := range subnetsToZones {
+		for _, z := range zones {
+			if z == zone {
+				uuids = append(uuids, string(id))
+				break
+			}
+		}
+	}
+	sort.Strings(uuids)
+	return uuids
+}
@@ -0,0 +1,228 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cloudsigma
+
+import (
+	"net"
+
+	"github.com/altoros/gosigma"
+	"github.com/altoros/gosigma/mock"
+	"github.com/juju/errors"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/feature"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/testing"
+)
+
+const testEnvUUID = "f54aac3a-9dcd-4a0c-86b5-24091478478c"
+
+type networkingSuite struct {
+	testing.BaseSuite
+	api    *fakeSigmaAPI
+	env    *environ
+	instId instance.Id
+}
+
+var _ = gc.Suite(&networkingSuite{})
+
+func (s *networkingSuite) SetUpSuite(c *gc.C) {
+	s.BaseSuite.SetUpSuite(c)
+	mock.Start()
+}
+
+func (s *networkingSuite) TearDownSuite(c *gc.C) {
+	mock.Stop()
+	s.BaseSuite.TearDownSuite(c)
+}
+
+func (s *networkingSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.SetFeatureFlags(feature.AddressAllocation)
+	mock.Reset()
+	s.api = newFakeSigmaAPI()
+	s.AddCleanup(func(*gc.C) { s.api.Close() })
+
+	// Servers live in the gosigma mock, their NICs in the fake.
+	s.PatchValue(&newClient, func(cfg *environConfig) (*environClient, error) {
+		conn, err := gosigma.NewClient(mock.Endpoint(""), mock.TestUser, mock.TestPassword, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &environClient{conn: conn, rest: s.api.restClient(), uuid: testEnvUUID, config: cfg}, nil
+	})
+	env, err := environs.New(newConfig(c, validAttrs()))
+	c.Assert(err, gc.IsNil)
+	s.env = env.(*environ)
+
+	s.api.addVLAN("vlan-0", map[string]string{
+		jujuMetaCIDR:    "10.0.0.0/24",
+		jujuMetaIPRange: "10.0.0.100-10.0.0.200",
+	})
+	s.api.addVLAN("vlan-1", map[string]string{"name": "unmanaged"})
+	s.instId = instance.Id(addTestClientServer(c, jujuMetaInstanceServer, testEnvUUID))
+	s.api.setServerNICs(string(s.instId), map[string]interface{}{
+		"ip_v4_conf": map[string]interface{}{"conf": "dhcp", "ip": nil},
+		"mac":        "22:40:85:4c:d7:01",
+		"runtime":    map[string]interface{}{"ip_v4": map[string]interface{}{"uuid": "178.22.70.33"}},
+	}, map[string]interface{}{
+		"ip_v4_conf": nil,
+		"mac":        "22:40:85:4c:d7:02",
+		"vlan":       map[string]interface{}{"uuid": "vlan-0"},
+	})
+}
+
+func (s *networkingSuite) TestSubnets(c *gc.C) {
+	subnets, err := s.env.Subnets(instance.UnknownId, []network.Id{"vlan-0", "vlan-1"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(subnets, gc.HasLen, 2)
+	if subnets[0].ProviderId != "vlan-0" {
+		subnets[0], subnets[1] = subnets[1], subnets[0]
+	}
+	c.Check(subnets[0], gc.DeepEquals, network.SubnetInfo{
+		ProviderId:        "vlan-0",
+		CIDR:              "10.0.0.0/24",
+		AllocatableIPLow:  net.ParseIP("10.0.0.100"),
+		AllocatableIPHigh: net.ParseIP("10.0.0.200"),
+		AvailabilityZones: []string{"zrh"},
+	})
+	c.Check(subnets[1], gc.DeepEquals, network.SubnetInfo{
+		ProviderId:        "vlan-1",
+		AvailabilityZones: []string{"zrh"},
+	})
+
+	_, err = s.env.Subnets(instance.UnknownId, []network.Id{"vlan-0", "vlan-9"})
+	c.Assert(err, gc.ErrorMatches, `subnets \[vlan-9\] not found`)
+}
+
+func (s *networkingSuite) TestSubnetsInvalidMeta(c *gc.C) {
+	s.api.addVLAN("vlan-2", map[string]string{
+		jujuMetaCIDR:    "10.0.2.0/24",
+		jujuMetaIPRange: "10.0.3.100-10.0.3.200",
+	})
+	s.api.addVLAN("vlan-3", map[string]string{jujuMetaCIDR: "10.0.3.0"})
+
+	subnets, err := s.env.Subnets(instance.UnknownId, []network.Id{"vlan-2", "vlan-3"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(subnets, gc.HasLen, 2)
+	if subnets[0].ProviderId != "vlan-2" {
+		subnets[0], subnets[1] = subnets[1], subnets[0]
+	}
+	c.Check(subnets[0], gc.DeepEquals, network.SubnetInfo{
+		ProviderId:        "vlan-2",
+		CIDR:              "10.0.2.0/24",
+		AvailabilityZones: []string{"zrh"},
+	})
+	c.Check(subnets[1], gc.DeepEquals, network.SubnetInfo{
+		ProviderId:        "vlan-3",
+		AvailabilityZones: []string{"zrh"},
+	})
+}
+
+func (s *networkingSuite) TestSubnetsOfInstance(c *gc.C) {
+	subnets, err := s.env.Subnets(s.instId, nil)
+	c.Assert(err, gc.IsNil)
+	c.Assert(subnets, gc.HasLen, 1)
+	c.Check(subnets[0].ProviderId, gc.Equals, network.Id("vlan-0"))
+}
+
+func (s *networkingSuite) TestNetworkInterfaces(c *gc.C) {
+	ifaces, err := s.env.NetworkInterfaces(s.instId)
+	c.Assert(err, gc.IsNil)
+	c.Assert(ifaces, gc.DeepEquals, []network.InterfaceInfo{{
+		DeviceIndex:   0,
+		MACAddress:    "22:40:85:4c:d7:01",
+		InterfaceName: "eth0",
+		ProviderId:    network.Id(s.instId + "-nic-0"),
+		ConfigType:    network.ConfigDHCP,
+		Address:       network.NewAddress("178.22.70.33"),
+	}, {
+		DeviceIndex:      1,
+		MACAddress:       "22:40:85:4c:d7:02",
+		InterfaceName:    "eth1",
+		ProviderId:       network.Id(s.instId + "-nic-1"),
+		ProviderSubnetId: "vlan-0",
+		NetworkName:      "vlan-0",
+		CIDR:             "10.0.0.0/24",
+		ConfigType:       network.ConfigManual,
+	}})
+
+	_, err = s.env.NetworkInterfaces("missing")
+	c.Assert(err, gc.ErrorMatches, `instance "missing" not found`)
+}
+
+func (s *networkingSuite) TestSupportsAddressAllocation(c *gc.C) {
+	ok, err := s.env.SupportsAddressAllocation(network.AnySubnet)
+	c.Check(err, gc.IsNil)
+	c.Check(ok, gc.Equals, true)
+
+	ok, err = s.env.SupportsAddressAllocation("vlan-1")
+	c.Check(err, gc.IsNil)
+	c.Check(ok, gc.Equals, false)
+
+	s.SetFeatureFlags()
+	_, err = s.env.SupportsAddressAllocation(network.AnySubnet)
+	c.Check(err, gc.ErrorMatches, "address allocation not supported")
+}
+
+func (s *networkingSuite) TestAllocateReleaseAddress(c *gc.C) {
+	addr := network.NewAddress("10.0.0.150")
+	err := s.env.AllocateAddress(s.instId, "vlan-0", addr, "", "machine-0-lxc-0")
+	c.Assert(err, gc.IsNil)
+	c.Check(s.api.vlanMeta("vlan-0")[jujuMetaAddress+"10.0.0.150"], gc.Equals, "machine-0-lxc-0")
+
+	err = s.env.AllocateAddress(s.instId, "vlan-0", addr, "", "machine-0-lxc-1")
+	c.Check(errors.Cause(err), gc.Equals, environs.ErrIPAddressUnavailable)
+
+	err = s.env.AllocateAddress(s.instId, "vlan-0", network.NewAddress("10.0.0.10"), "", "machine-0-lxc-1")
+	c.Check(errors.Cause(err), gc.Equals, environs.ErrIPAddressUnavailable)
+
+	err = s.env.AllocateAddress(s.instId, "vlan-1", addr, "", "machine-0-lxc-1")
+	c.Check(err, gc.ErrorMatches, `.*NIC on subnet "vlan-1" not found`)
+
+	err = s.env.ReleaseAddress(s.instId, "vlan-0", addr, "")
+	c.Assert(err, gc.IsNil)
+	_, ok := s.api.vlanMeta("vlan-0")[jujuMetaAddress+"10.0.0.150"]
+	c.Check(ok, gc.Equals, false)
+}
+
+func (s *networkingSuite) TestCheckPlacementIP(c *gc.C) {
+	s.api.addIP("178.22.70.40", "")
+	s.api.addIP("178.22.70.41", string(s.instId))
+
+	p, err := s.env.checkPlacement("ip=178.22.70.40")
+	c.Assert(err, gc.IsNil)
+	c.Check(*p, gc.Equals, sigmaPlacement{zone: "zrh", ip: "178.22.70.40"})
+
+	_, err = s.env.checkPlacement("ip=178.22.70.41")
+	c.Check(err, gc.ErrorMatches, `IP "178.22.70.41" is in use by server ".*"`)
+
+	_, err = s.env.checkPlacement("ip=178.22.70.42")
+	c.Check(err, gc.ErrorMatches, `IP "178.22.70.42" not found`)
+}
+
+func (s *networkingSuite) TestStartZonesSpaces(c *gc.C) {
+	zones, err := s.env.startZones(environs.StartInstanceParams{
+		SubnetsToZones: map[network.Id][]string{"vlan-0": {"zrh"}},
+	})
+	c.Assert(err, gc.IsNil)
+	c.Check(zones, gc.DeepEquals, []string{"zrh"})
+
+	_, err = s.env.startZones(environs.StartInstanceParams{
+		SubnetsToZones: map[network.Id][]string{"vlan-9": {"lvs"}},
+	})
+	c.Check(err, gc.ErrorMatches, `no subnets of the constrained spaces in availability zones \[zrh\]`)
+}
+
+func (s *networkingSuite) TestStartVLANs(c *gc.C) {
+	subnetsToZones := map[network.Id][]string{
+		"vlan-2": {"zrh"},
+		"vlan-0": {"lvs", "zrh"},
+		"vlan-1": {"lvs"},
+	}
+	c.Check(startVLANs("zrh", subnetsToZones), gc.DeepEquals, []string{"vlan-0", "vlan-2"})
+	c.Check(startVLANs("wdc", subnetsToZones), gc.HasLen, 0)
+}
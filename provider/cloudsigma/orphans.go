@@ -0,0 +1,144 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cloudsigma
+
+import (
+	"strings"
+
+	"github.com/altoros/gosigma"
+	"github.com/juju/errors"
+	"github.com/juju/utils/set"
+
+	"github.com/juju/juju/environs"
+)
+
+// Servers and root drives are named after the environment and the
+// machine they were created for, and volume drives carry the
+// environment and volume in their meta. Any of them no longer backing
+// a machine or volume in state is an orphan. Resources of machines not
+// provisioned yet are left alone, since an interrupted start resumes
+// from them.
+
+var _ environs.OrphanCollector = (*environ)(nil)
+
+const (
+	orphanServer = "server"
+	orphanDrive  = "drive"
+)
+
+// nameMachineId returns the id of the machine the resource with the
+// given name was created for, if it was created for one.
+func (c *environClient) nameMachineId(name string) (string, bool) {
+	prefix := "juju-" + c.uuid + "-"
+	if !strings.HasPrefix(name, prefix) || name == prefix {
+		return "", false
+	}
+	return strings.TrimPrefix(name, prefix), true
+}
+
+// orphans returns the orphaned servers and drives of the environment in
+// the zone of the client.
+func (c *environClient) orphans(instances, pending, volumes set.Strings) ([]environs.Orphan, error) {
+	zone := c.config.region()
+	var orphans []environs.Orphan
+
+	servers, err := c.instances()
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot list servers")
+	}
+	for _, s := range servers {
+		if instances.Contains(s.UUID()) {
+			continue
+		}
+		if id, ok := c.nameMachineId(s.Name()); ok && pending.Contains(id) {
+			continue
+		}
+		orphans = append(orphans, environs.Orphan{Kind: orphanServer, Id: s.UUID(), Name: s.Name(), Zone: zone})
+	}
+
+	var all []sigmaDrive
+	if err := c.rest.list(drives, &all); err != nil {
+		return nil, errors.Annotate(err, "cannot list drives")
+	}
+	for _, d := range all {
+		// Mounted drives go along with their server.
+		if len(d.MountedOn) > 0 || d.Status == driveStatusMounted || d.busy() {
+			continue
+		}
+		if tag, ok := d.Meta[jujuMetaVolume]; ok {
+			if d.Meta[jujuMetaEnvironment] != c.uuid || volumes.Contains(tag) {
+				continue
+			}
+		} else if id, ok := c.nameMachineId(d.Name); !ok || pending.Contains(id) {
+			continue
+		}
+		orphans = append(orphans, environs.Orphan{Kind: orphanDrive, Id: d.UUID, Name: d.Name, Zone: zone})
+	}
+	return orphans, nil
+}
+
+// removeOrphan removes an orphaned server or drive. The drives of a
+// server are kept, since they may hold volumes; they become orphans
+// themselves once the server is gone.
+func (c *environClient) removeOrphan(orphan environs.Orphan) error {
+	switch orphan.Kind {
+	case orphanServer:
+		s, err := c.conn.Server(orphan.Id)
+		if err != nil {
+			return errors.Annotatef(err, "cannot get server %q", orphan.Id)
+		}
+		if s.Status() != gosigma.ServerStopped {
+			if err := s.StopWait(); err != nil {
+				return errors.Annotatef(err, "cannot stop server %q", orphan.Id)
+			}
+		}
+		if err := s.Remove(gosigma.RecurseNothing); err != nil {
+			return errors.Annotatef(err, "cannot remove server %q", orphan.Id)
+		}
+		return nil
+	case orphanDrive:
+		return c.removeDrive(orphan.Id)
+	}
+	return errors.NotValidf("orphan kind %q", orphan.Kind)
+}
+
+// Orphans is specified in the environs.OrphanCollector interface.
+func (env *environ) Orphans(params environs.OrphanParams) ([]environs.Orphan, error) {
+	instances := set.NewStrings()
+	for _, id := range params.Instances {
+		instances.Add(string(id))
+	}
+	volumes := set.NewStrings()
+	for _, tag := range params.Volumes {
+		volumes.Add(tag.String())
+	}
+	pending := set.NewStrings(params.PendingMachines...)
+
+	var orphans []environs.Orphan
+	for _, client := range env.clients() {
+		found, err := client.orphans(instances, pending, volumes)
+		if err != nil {
+			return nil, errors.Annotatef(err, "availability zone %q", client.config.region())
+		}
+		orphans = append(orphans, found...)
+	}
+	return orphans, nil
+}
+
+// RemoveOrphans is specified in the environs.OrphanCollector interface.
+func (env *environ) RemoveOrphans(orphans []environs.Orphan) error {
+	var err error
+	for _, orphan := range orphans {
+		client, e := env.zoneClient(orphan.Zone)
+		if e == nil {
+			logger.Infof("removing orphaned %s %q (%s)", orphan.Kind, orphan.Id, orphan.Name)
+			e = client.removeOrphan(orphan)
+		}
+		if e != nil {
+			logger.Warningf("cannot remove orphaned %s %q: %v", orphan.Kind, orphan.Id, e)
+			err = e
+		}
+	}
+	return err
+}
@@ -0,0 +1,114 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cloudsigma
+
+import (
+	"sort"
+
+	"github.com/altoros/gosigma"
+	"github.com/altoros/gosigma/mock"
+	"github.com/juju/names"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/testing"
+)
+
+type orphansSuite struct {
+	testing.BaseSuite
+	api *fakeSigmaAPI
+	env *environ
+}
+
+var _ = gc.Suite(&orphansSuite{})
+
+func (s *orphansSuite) SetUpSuite(c *gc.C) {
+	s.BaseSuite.SetUpSuite(c)
+	mock.Start()
+}
+
+func (s *orphansSuite) TearDownSuite(c *gc.C) {
+	mock.Stop()
+	s.BaseSuite.TearDownSuite(c)
+}
+
+func (s *orphansSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	mock.Reset()
+	s.api = newFakeSigmaAPI()
+	s.AddCleanup(func(*gc.C) { s.api.Close() })
+
+	// Servers live in the gosigma mock, drives in the fake.
+	s.PatchValue(&newClient, func(cfg *environConfig) (*environClient, error) {
+		conn, err := gosigma.NewClient(mock.Endpoint(""), mock.TestUser, mock.TestPassword, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &environClient{conn: conn, rest: s.api.restClient(), uuid: testEnvUUID, config: cfg}, nil
+	})
+	env, err := environs.New(newConfig(c, validAttrs()))
+	c.Assert(err, gc.IsNil)
+	s.env = env.(*environ)
+}
+
+func (s *orphansSuite) addDrive(c *gc.C, name string, meta map[string]string) string {
+	var created sigmaDrive
+	err := s.api.restClient().create(drives, &sigmaDrive{Name: name, Size: 1, Media: "disk", Meta: meta}, &created)
+	c.Assert(err, gc.IsNil)
+	return created.UUID
+}
+
+func (s *orphansSuite) TestOrphans(c *gc.C) {
+	prefix := "juju-" + testEnvUUID + "-"
+	known := addNamedTestServer(c, prefix+"1", testEnvUUID, "running")
+	orphan := addNamedTestServer(c, prefix+"2", testEnvUUID, "stopped")
+	addNamedTestServer(c, prefix+"3", testEnvUUID, "stopped")
+	addNamedTestServer(c, prefix+"2", "alien", "stopped")
+
+	root := s.addDrive(c, prefix+"1", nil)
+	_, err := s.env.client.attachDrive(known, root)
+	c.Assert(err, gc.IsNil)
+	s.addDrive(c, prefix+"2", nil)
+	s.addDrive(c, prefix+"3", nil)
+	s.addDrive(c, "golden", nil)
+	s.addDrive(c, prefix+"volume-0", map[string]string{jujuMetaEnvironment: testEnvUUID, jujuMetaVolume: "volume-0"})
+	s.addDrive(c, prefix+"volume-1", map[string]string{jujuMetaEnvironment: testEnvUUID, jujuMetaVolume: "volume-1"})
+	s.addDrive(c, "alien-volume-1", map[string]string{jujuMetaEnvironment: "alien", jujuMetaVolume: "volume-1"})
+
+	orphans, err := s.env.Orphans(environs.OrphanParams{
+		Instances:       []instance.Id{instance.Id(known)},
+		PendingMachines: []string{"3"},
+		Volumes:         []names.VolumeTag{names.NewVolumeTag("0")},
+	})
+	c.Assert(err, gc.IsNil)
+	var found []string
+	for _, o := range orphans {
+		c.Check(o.Zone, gc.Equals, "zrh")
+		if o.Kind == orphanServer {
+			c.Check(o.Id, gc.Equals, orphan)
+		}
+		found = append(found, o.Kind+" "+o.Name)
+	}
+	sort.Strings(found)
+	c.Check(found, gc.DeepEquals, []string{
+		"drive " + prefix + "2",
+		"drive " + prefix + "volume-1",
+		"server " + prefix + "2",
+	})
+
+	err = s.env.RemoveOrphans(orphans)
+	c.Assert(err, gc.IsNil)
+	servers, err := s.env.client.instances()
+	c.Assert(err, gc.IsNil)
+	c.Check(servers, gc.HasLen, 2)
+	left := s.api.driveNames()
+	sort.Strings(left)
+	c.Check(left, gc.DeepEquals, []string{"alien-volume-1", "golden", prefix + "1", prefix + "3", prefix + "volume-0"})
+}
+
+func (s *orphansSuite) TestRemoveOrphansUnknownZone(c *gc.C) {
+	err := s.env.RemoveOrphans([]environs.Orphan{{Kind: orphanDrive, Id: "drive-1", Zone: "sjc"}})
+	c.Check(err, gc.ErrorMatches, `availability zone "sjc" not found`)
+}
@@ -0,0 +1,222 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cloudsigma
+
+import (
+	"strings"
+
+	"github.com/altoros/gosigma"
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/juju/arch"
+)
+
+const (
+	// placementZone places the instance in the given availability zone.
+	placementZone = "zone"
+	// placementPool places the instance on hosts of the given CloudSigma
+	// allocation pool.
+	placementPool = "pool"
+	// placementDrive boots the instance from the given existing drive of
+	// the account, rather than from a clone of an image.
+	placementDrive = "drive"
+	// placementIP configures the public NIC of the instance with the
+	// given static IP subscribed to by the account, rather than DHCP.
+	placementIP = "ip"
+	// placementImage clones the root drive of the instance from the
+	// given private image, overriding the "image" config attribute.
+	placementImage = "image"
+
+	// serverAllocationPool is the field of the server definition holding
+	// the allocation pool.
+	serverAllocationPool = "allocation_pool"
+
+	// jujuMetaPlacedDrive is the meta key of a server booted from a
+	// drive given through placement, holding the UUID of the drive.
+	jujuMetaPlacedDrive = "juju-placed-drive"
+)
+
+// sigmaPlacement holds the placement directives of an instance.
+type sigmaPlacement struct {
+	zone  string
+	pool  string
+	drive string
+	ip    string
+	image string
+}
+
+// parsePlacement parses a comma separated list of placement directives
+// of the form key=value, e.g. "zone=zrh,drive=<uuid>". It does not check
+// that the zone, the drive or the IP exist.
+func parsePlacement(placement string) (*sigmaPlacement, error) {
+	var p sigmaPlacement
+	if placement == "" {
+		return &p, nil
+	}
+	for _, directive := range strings.Split(placement, ",") {
+		pos := strings.IndexRune(directive, '=')
+		if pos == -1 {
+			return nil, errors.Errorf("unknown placement directive: %v", directive)
+		}
+		key, value := directive[:pos], directive[pos+1:]
+		if value == "" {
+			return nil, errors.Errorf("placement directive %q: value must not be empty", key)
+		}
+		var target *string
+		switch key {
+		case placementZone:
+			target = &p.zone
+		case placementPool:
+			target = &p.pool
+		case placementDrive:
+			target = &p.drive
+		case placementIP:
+			target = &p.ip
+		case placementImage:
+			target = &p.image
+		default:
+			return nil, errors.Errorf("unknown placement directive: %v", directive)
+		}
+		if *target != "" {
+			return nil, errors.Errorf("placement directive %q specified more than once", key)
+		}
+		*target = value
+	}
+	if p.drive != "" && p.image != "" {
+		return nil, errors.Errorf("placement directives %q and %q cannot be combined", placementDrive, placementImage)
+	}
+	if err := validateImageRef(p.image); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &p, nil
+}
+
+// checkPlacement parses the placement directives and checks them
+// against the environment. The zone of a placement naming only a
+// drive, an IP or an image is set to the zone it is in.
+func (env *environ) checkPlacement(placement string) (*sigmaPlacement, error) {
+	p, err := parsePlacement(placement)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if p.zone != "" {
+		if _, err := env.zoneClient(p.zone); err != nil {
+			return nil, errors.Errorf("invalid availability zone %q", p.zone)
+		}
+	}
+	if p.drive != "" {
+		if err := env.checkPlacementDrive(p); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if p.ip != "" {
+		if err := env.checkPlacementIP(p); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if p.image != "" {
+		if err := env.checkPlacementImage(p); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return p, nil
+}
+
+// placementClients returns the clients of the zones a resource named
+// in the placement may be in.
+func (env *environ) placementClients(p *sigmaPlacement) []*environClient {
+	if p.zone == "" {
+		return env.clients()
+	}
+	client, _ := env.zoneClient(p.zone)
+	return []*environClient{client}
+}
+
+func (env *environ) checkPlacementDrive(p *sigmaPlacement) error {
+	for _, client := range env.placementClients(p) {
+		drive, err := client.drive(p.drive)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return errors.Annotatef(err, "cannot check drive %q", p.drive)
+		}
+		if drive.Media != driveMediaDisk {
+			return errors.Errorf("drive %q is not a disk", p.drive)
+		}
+		if drive.Status != driveStatusUnmounted {
+			return errors.Errorf("drive %q is %s", p.drive, drive.Status)
+		}
+		p.zone = client.config.region()
+		return nil
+	}
+	return errors.NotFoundf("drive %q", p.drive)
+}
+
+func (env *environ) checkPlacementImage(p *sigmaPlacement) error {
+	for _, client := range env.placementClients(p) {
+		_, err := client.imageDrive(p.image)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return errors.Trace(err)
+		}
+		p.zone = client.config.region()
+		return nil
+	}
+	return errors.NotFoundf("image %q", p.image)
+}
+
+func (env *environ) checkPlacementIP(p *sigmaPlacement) error {
+	for _, client := range env.placementClients(p) {
+		ip, err := client.staticIP(p.ip)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return errors.Annotatef(err, "cannot check IP %q", p.ip)
+		}
+		if uuid := resourceUUID(ip.Server); uuid != "" {
+			return errors.Errorf("IP %q is in use by server %q", p.ip, uuid)
+		}
+		p.zone = client.config.region()
+		return nil
+	}
+	return errors.NotFoundf("IP %q", p.ip)
+}
+
+// setServerPool sets the allocation pool of the given server. The
+// server definition is round-tripped untouched otherwise, since the
+// API requires the whole definition on update.
+func (c *environClient) setServerPool(serverUUID, pool string) error {
+	var server map[string]interface{}
+	if err := c.rest.get(servers, serverUUID, &server); err != nil {
+		return errors.Trace(err)
+	}
+	server[serverAllocationPool] = pool
+	if err := c.rest.update(servers, serverUUID, server, nil); err != nil {
+		return errors.Annotatef(err, "cannot set allocation pool of server %q", serverUUID)
+	}
+	return nil
+}
+
+// placedDriveArch returns the architecture of a drive given through
+// placement, which must be that of the image the tools of the instance
+// were chosen for. A drive that does not record its architecture is
+// taken to have the image's.
+func placedDriveArch(drv gosigma.Drive, imageArch string) (string, error) {
+	var driveArch string
+	switch drv.Arch() {
+	case "64":
+		driveArch = arch.AMD64
+	case "32":
+		driveArch = arch.I386
+	case "":
+		return imageArch, nil
+	default:
+		return "", errors.Errorf("drive %q has unknown arch %q", drv.UUID(), drv.Arch())
+	}
+	if imageArch != "" && driveArch != imageArch {
+		return "", errors.Errorf("drive %q has architecture %q, not %q", drv.UUID(), driveArch, imageArch)
+	}
+	return driveArch, nil
+}
@@ -0,0 +1,154 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cloudsigma
+
+import (
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/testing"
+)
+
+type placementSuite struct {
+	testing.BaseSuite
+	api *fakeSigmaAPI
+	env *environ
+}
+
+var _ = gc.Suite(&placementSuite{})
+
+func (s *placementSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.api = newFakeSigmaAPI()
+	s.AddCleanup(func(*gc.C) { s.api.Close() })
+
+	// Only the client of the environment's region can see the drives.
+	s.PatchValue(&newClient, func(cfg *environConfig) (*environClient, error) {
+		client := &environClient{uuid: "f54aac3a-9dcd-4a0c-86b5-24091478478c", config: cfg}
+		if cfg.region() == "zrh" {
+			client.rest = s.api.restClient()
+		} else {
+			client.rest = newRestClient(s.api.URL+"/other", "user", "password")
+		}
+		return client, nil
+	})
+	env, err := environs.New(newConfig(c, validAttrs().Merge(testing.Attrs{"zones": "lvs"})))
+	c.Assert(err, gc.IsNil)
+	s.env = env.(*environ)
+}
+
+func (s *placementSuite) addDrive(c *gc.C, drive *sigmaDrive) string {
+	var created sigmaDrive
+	err := s.api.restClient().create(drives, drive, &created)
+	c.Assert(err, gc.IsNil)
+	return created.UUID
+}
+
+var parsePlacementTests = []struct {
+	placement string
+	expect    sigmaPlacement
+	err       string
+}{{
+	placement: "",
+}, {
+	placement: "zone=lvs",
+	expect:    sigmaPlacement{zone: "lvs"},
+}, {
+	placement: "pool=licensed",
+	expect:    sigmaPlacement{pool: "licensed"},
+}, {
+	placement: "zone=zrh,pool=licensed,drive=uuid",
+	expect:    sigmaPlacement{zone: "zrh", pool: "licensed", drive: "uuid"},
+}, {
+	placement: "ip=178.22.70.40",
+	expect:    sigmaPlacement{ip: "178.22.70.40"},
+}, {
+	placement: "image=tag:golden",
+	expect:    sigmaPlacement{image: "tag:golden"},
+}, {
+	placement: "image=tag:",
+	err:       `image "tag:" without tag name not valid`,
+}, {
+	placement: "drive=uuid,image=tag:golden",
+	err:       `placement directives "drive" and "image" cannot be combined`,
+}, {
+	placement: "lvs",
+	err:       "unknown placement directive: lvs",
+}, {
+	placement: "host=abc",
+	err:       "unknown placement directive: host=abc",
+}, {
+	placement: "pool=",
+	err:       `placement directive "pool": value must not be empty`,
+}, {
+	placement: "zone=lvs,zone=zrh",
+	err:       `placement directive "zone" specified more than once`,
+}}
+
+func (s *placementSuite) TestParsePlacement(c *gc.C) {
+	for i, test := range parsePlacementTests {
+		c.Logf("test %d: %q", i, test.placement)
+		p, err := parsePlacement(test.placement)
+		if test.err != "" {
+			c.Check(err, gc.ErrorMatches, test.err)
+			continue
+		}
+		c.Check(err, gc.IsNil)
+		c.Check(*p, gc.Equals, test.expect)
+	}
+}
+
+func (s *placementSuite) TestCheckPlacementDrive(c *gc.C) {
+	uuid := s.addDrive(c, &sigmaDrive{Name: "golden", Size: 1, Media: "disk"})
+
+	p, err := s.env.checkPlacement("drive=" + uuid)
+	c.Assert(err, gc.IsNil)
+	c.Assert(*p, gc.Equals, sigmaPlacement{zone: "zrh", drive: uuid})
+
+	p, err = s.env.checkPlacement("pool=licensed,drive=" + uuid)
+	c.Assert(err, gc.IsNil)
+	c.Assert(*p, gc.Equals, sigmaPlacement{zone: "zrh", pool: "licensed", drive: uuid})
+
+	_, err = s.env.checkPlacement("zone=lvs,drive=" + uuid)
+	c.Assert(err, gc.ErrorMatches, `drive ".*" not found`)
+
+	_, err = s.env.checkPlacement("drive=missing")
+	c.Assert(err, gc.ErrorMatches, `drive "missing" not found`)
+}
+
+func (s *placementSuite) TestCheckPlacementDriveNotUsable(c *gc.C) {
+	cdrom := s.addDrive(c, &sigmaDrive{Name: "iso", Size: 1, Media: "cdrom"})
+	_, err := s.env.checkPlacement("drive=" + cdrom)
+	c.Assert(err, gc.ErrorMatches, `drive ".*" is not a disk`)
+
+	mounted := s.addDrive(c, &sigmaDrive{Name: "busy", Size: 1, Media: "disk"})
+	_, err = s.env.client.attachDrive("server-0", mounted)
+	c.Assert(err, gc.IsNil)
+	_, err = s.env.checkPlacement("drive=" + mounted)
+	c.Assert(err, gc.ErrorMatches, `drive ".*" is mounted`)
+}
+
+func (s *placementSuite) TestPrecheckInstance(c *gc.C) {
+	c.Check(s.env.PrecheckInstance("trusty", constraints.Value{}, "pool=licensed"), gc.IsNil)
+	c.Check(s.env.PrecheckInstance("trusty", constraints.Value{}, "zone=lvs,pool=licensed"), gc.IsNil)
+	c.Check(s.env.PrecheckInstance("trusty", constraints.Value{}, "zone=sjc,pool=licensed"),
+		gc.ErrorMatches, `invalid availability zone "sjc"`)
+	c.Check(s.env.PrecheckInstance("trusty", constraints.Value{}, "drive=missing"),
+		gc.ErrorMatches, `drive "missing" not found`)
+}
+
+func (s *placementSuite) TestStartZonesDrive(c *gc.C) {
+	uuid := s.addDrive(c, &sigmaDrive{Name: "golden", Size: 1, Media: "disk"})
+
+	zones, err := s.env.startZones(environs.StartInstanceParams{Placement: "drive=" + uuid})
+	c.Assert(err, gc.IsNil)
+	c.Assert(zones, gc.DeepEquals, []string{"zrh"})
+}
+
+func (s *placementSuite) TestSetServerPool(c *gc.C) {
+	err := s.env.client.setServerPool("server-0", "licensed")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.api.servers["server-0"][serverAllocationPool], gc.Equals, "licensed")
+}
@@ -46,7 +46,12 @@ func init() {
 	// except in direct tests for that provider.
 	environs.RegisterProvider("cloudsigma", providerInstance)
 	environs.RegisterImageDataSourceFunc("cloud sigma image source", getImageSource)
-	registry.RegisterEnvironStorageProviders(providerType)
+
+	// Register the CloudSigma specific providers.
+	registry.RegisterProvider(SigmaDriveProviderType, &sigmaDriveProvider{})
+
+	// Register the CloudSigma drive provider with the CloudSigma provider.
+	registry.RegisterEnvironStorageProviders(providerType, SigmaDriveProviderType)
 }
 
 // Boilerplate returns a default configuration for the environment in yaml format.
@@ -0,0 +1,161 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cloudsigma
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// Kinds of resources accessed through restClient.
+const (
+	drives     = "drives"
+	fwPolicies = "fwpolicies"
+	ips        = "ips"
+	servers    = "servers"
+	tags       = "tags"
+	vlans      = "vlans"
+)
+
+// restClient performs raw JSON requests against the CloudSigma API.
+// It is used for the parts of the API that are not covered by gosigma.
+type restClient struct {
+	endpoint string
+	username string
+	password string
+	http     *http.Client
+}
+
+// newRestClient returns a restClient talking to the given API endpoint.
+var newRestClient = func(endpoint, username, password string) *restClient {
+	if !strings.HasSuffix(endpoint, "/") {
+		endpoint += "/"
+	}
+	return &restClient{
+		endpoint: endpoint,
+		username: username,
+		password: password,
+		http:     &http.Client{Timeout: 5 * time.Minute},
+	}
+}
+
+// restObjects is the envelope CloudSigma uses for lists of resources,
+// both in requests and responses.
+type restObjects struct {
+	Objects json.RawMessage `json:"objects"`
+}
+
+// do sends a request with the JSON encoding of in as the body (unless
+// in is nil) and decodes the response into out (unless out is nil).
+// A 404 response is reported as a NotFound error.
+func (c *restClient) do(method, path string, in, out interface{}) error {
+	var body *bytes.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		body = bytes.NewReader(data)
+	} else {
+		body = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, c.endpoint+path, body)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	req.SetBasicAuth(c.username, c.password)
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	logger.Tracef("restClient: %s %s", method, req.URL)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return errors.Annotatef(err, "%s %s", method, path)
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return errors.NotFoundf("%s", path)
+	case resp.StatusCode >= 300:
+		return errors.Errorf("%s %s: %s, %s", method, path, resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return errors.Annotatef(err, "cannot decode response to %s %s", method, path)
+	}
+	return nil
+}
+
+// list fetches all objects of the given resource kind in detail.
+func (c *restClient) list(kind string, out interface{}) error {
+	var objs restObjects
+	if err := c.do("GET", fmt.Sprintf("%s/detail/?limit=0", kind), nil, &objs); err != nil {
+		return errors.Trace(err)
+	}
+	if len(objs.Objects) == 0 {
+		return nil
+	}
+	return errors.Trace(json.Unmarshal(objs.Objects, out))
+}
+
+// create posts a single new object of the given kind and decodes the
+// created object into out.
+func (c *restClient) create(kind string, in, out interface{}) error {
+	var objs restObjects
+	req := map[string]interface{}{"objects": []interface{}{in}}
+	if err := c.do("POST", kind+"/", req, &objs); err != nil {
+		return errors.Trace(err)
+	}
+	if out == nil {
+		return nil
+	}
+	var created []json.RawMessage
+	if err := json.Unmarshal(objs.Objects, &created); err != nil {
+		return errors.Trace(err)
+	}
+	if len(created) != 1 {
+		return errors.Errorf("expected 1 created %s, got %d", kind, len(created))
+	}
+	return errors.Trace(json.Unmarshal(created[0], out))
+}
+
+// get fetches a single object of the given kind.
+func (c *restClient) get(kind, uuid string, out interface{}) error {
+	return c.do("GET", fmt.Sprintf("%s/%s/", kind, uuid), nil, out)
+}
+
+// update replaces a single object of the given kind.
+func (c *restClient) update(kind, uuid string, in, out interface{}) error {
+	return c.do("PUT", fmt.Sprintf("%s/%s/", kind, uuid), in, out)
+}
+
+// remove deletes a single object of the given kind.
+func (c *restClient) remove(kind, uuid string) error {
+	return c.do("DELETE", fmt.Sprintf("%s/%s/", kind, uuid), nil, nil)
+}
+
+// action performs an action such as "resize" on a single object of the
+// given kind and decodes the response into out.
+func (c *restClient) action(kind, uuid, action string, in, out interface{}) error {
+	return c.do("POST", fmt.Sprintf("%s/%s/action/?do=%s", kind, uuid, action), in, out)
+}
@@ -0,0 +1,151 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cloudsigma
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/altoros/gosigma"
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+
+	"github.com/juju/juju/environs"
+)
+
+// Cloning and resizing a root drive can take a long time, so they are
+// polled rather than waited for, reporting progress on the way. The
+// root drive and the server of a machine are named after the
+// environment and the machine, so that an attempt to start the machine
+// that was interrupted, e.g. by a restart of the agent, can be resumed
+// by the next one.
+
+// rootDriveAttempt is used to wait for root drives being cloned or
+// resized.
+var rootDriveAttempt = utils.AttemptStrategy{
+	Total: 30 * time.Minute,
+	Delay: 10 * time.Second,
+}
+
+// progressReporter returns a function reporting progress through the
+// status callback of args, if any. Failures to report are only logged.
+func progressReporter(args environs.StartInstanceParams) func(string) {
+	return func(info string) {
+		logger.Debugf("machine %s: %s", args.InstanceConfig.MachineId, info)
+		if args.StatusCallback == nil {
+			return
+		}
+		if err := args.StatusCallback(info, nil); err != nil {
+			logger.Warningf("cannot report progress of machine %s: %v", args.InstanceConfig.MachineId, err)
+		}
+	}
+}
+
+// removeStaleServers removes the servers with the given name left
+// behind by an interrupted attempt to start the machine. A server that
+// never ran keeps its drives, so that they can be adopted; the drives
+// of any other server are removed along with it, unless keepDrives is
+// set because they belong to the user.
+func (c *environClient) removeStaleServers(name string, keepDrives bool) error {
+	stale, err := c.conn.ServersFiltered(gosigma.RequestDetail, func(s gosigma.Server) bool {
+		return s.Name() == name && c.isMyEnvironment(s)
+	})
+	if err != nil {
+		return errors.Annotate(err, "cannot list servers")
+	}
+	for _, s := range stale {
+		recurse := gosigma.RecurseNothing
+		if s.Status() != gosigma.ServerStopped {
+			logger.Infof("removing stale server %q", s.UUID())
+			if err := s.StopWait(); err != nil {
+				return errors.Annotatef(err, "cannot stop stale server %q", s.UUID())
+			}
+			if !keepDrives {
+				recurse = gosigma.RecurseAllDrives
+			}
+		} else {
+			logger.Infof("removing stale server %q, keeping its drives", s.UUID())
+		}
+		if err := s.Remove(recurse); err != nil {
+			return errors.Annotatef(err, "cannot remove stale server %q", s.UUID())
+		}
+	}
+	return nil
+}
+
+// staleDrive returns the unmounted drive with the given name left
+// behind by an interrupted attempt to start the machine, or nil.
+func (c *environClient) staleDrive(name string) (gosigma.Drive, error) {
+	all, err := c.conn.Drives(gosigma.RequestDetail, gosigma.LibraryAccount)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot list drives")
+	}
+	for _, d := range all {
+		if d.Name() == name && d.Status() != driveStatusMounted {
+			return d, nil
+		}
+	}
+	return nil, nil
+}
+
+// cloneRootDrive clones the template to a root drive with the given
+// name, adopting a clone left behind by an interrupted attempt.
+func (c *environClient) cloneRootDrive(template gosigma.Drive, name string, report func(string)) (gosigma.Drive, error) {
+	drv, err := c.staleDrive(name)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if drv != nil {
+		logger.Infof("adopting root drive %q", drv.UUID())
+		report("adopting root drive")
+	} else {
+		report("cloning root drive")
+		if drv, err = template.Clone(gosigma.CloneParams{Name: name}, nil); err != nil {
+			return nil, errors.Errorf("error cloning drive: %v", err)
+		}
+	}
+	return drv, c.waitRootDrive(drv, "cloning", report)
+}
+
+// resizeRootDrive grows the root drive to size bytes.
+func (c *environClient) resizeRootDrive(drv gosigma.Drive, size uint64, report func(string)) error {
+	report("resizing root drive")
+	if err := drv.Resize(size); err != nil {
+		return errors.Errorf("error resizing drive: %v", err)
+	}
+	return c.waitRootDrive(drv, "resizing", report)
+}
+
+// setRootDriveMeta replaces the meta of a root drive cloned by juju.
+// The API requires the whole definition on update.
+func (c *environClient) setRootDriveMeta(drv gosigma.Drive, meta map[string]string) error {
+	def := sigmaDrive{
+		Name:  drv.Name(),
+		Size:  drv.Size(),
+		Media: driveMediaDisk,
+		Meta:  meta,
+	}
+	if err := c.rest.update(drives, drv.UUID(), &def, nil); err != nil {
+		return errors.Annotatef(err, "cannot set meta of drive %q", drv.UUID())
+	}
+	return nil
+}
+
+// waitRootDrive polls the root drive until it has no operation in
+// progress, reporting the time elapsed.
+func (c *environClient) waitRootDrive(drv gosigma.Drive, operation string, report func(string)) error {
+	start := time.Now()
+	for a := rootDriveAttempt.Start(); a.Next(); {
+		if err := drv.Refresh(); err != nil {
+			return errors.Annotatef(err, "cannot refresh drive %q", drv.UUID())
+		}
+		status := drv.Status()
+		if status != driveStatusCreating && status != driveStatusResizing {
+			return nil
+		}
+		elapsed := time.Since(start) / time.Second * time.Second
+		report(fmt.Sprintf("%s root drive (%v elapsed)", operation, elapsed))
+	}
+	return errors.Errorf("timed out %s drive %q", operation, drv.UUID())
+}
@@ -0,0 +1,140 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cloudsigma
+
+import (
+	"strings"
+	"time"
+
+	"github.com/altoros/gosigma"
+	"github.com/altoros/gosigma/data"
+	"github.com/altoros/gosigma/mock"
+	"github.com/juju/utils"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/cloudconfig/instancecfg"
+	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/imagemetadata"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/tools"
+	"github.com/juju/juju/version"
+)
+
+type resumeSuite struct {
+	testing.BaseSuite
+	api    *fakeSigmaAPI
+	client *environClient
+}
+
+var _ = gc.Suite(&resumeSuite{})
+
+const resumeBaseName = "juju-" + testEnvUUID + "-1"
+
+func (s *resumeSuite) SetUpSuite(c *gc.C) {
+	s.BaseSuite.SetUpSuite(c)
+	mock.Start()
+}
+
+func (s *resumeSuite) TearDownSuite(c *gc.C) {
+	mock.Stop()
+	s.BaseSuite.TearDownSuite(c)
+}
+
+func (s *resumeSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	mock.Reset()
+	mock.ResetDrives()
+	s.PatchValue(&rootDriveAttempt, utils.AttemptStrategy{Total: 5 * time.Second, Delay: 10 * time.Millisecond})
+
+	client, err := testNewClient(c, mock.Endpoint(""), mock.TestUser, mock.TestPassword)
+	c.Assert(err, gc.IsNil)
+	client.conn.OperationTimeout(1 * time.Second)
+	s.api = newFakeSigmaAPI()
+	s.AddCleanup(func(*gc.C) { s.api.Close() })
+	client.rest = s.api.restClient()
+	s.client = client
+
+	mock.LibDrives.Add(&data.Drive{
+		Resource:     data.Resource{URI: "uri", UUID: validImageId},
+		LibraryDrive: data.LibraryDrive{Arch: "64", ImageType: "image-type", OS: "os"},
+		Size:         2200 * gosigma.Megabyte,
+		Status:       "unmounted",
+	})
+}
+
+func (s *resumeSuite) startParams(progress *[]string) environs.StartInstanceParams {
+	return environs.StartInstanceParams{
+		Constraints: constraints.Value{},
+		InstanceConfig: &instancecfg.InstanceConfig{
+			MachineId: "1",
+			Tools: &tools.Tools{
+				Version: version.Binary{Series: "trusty"},
+			},
+		},
+		StatusCallback: func(info string, data map[string]interface{}) error {
+			*progress = append(*progress, info)
+			return nil
+		},
+	}
+}
+
+func (s *resumeSuite) TestNewInstanceReportsProgress(c *gc.C) {
+	var progress []string
+	img := &imagemetadata.ImageMetadata{Id: validImageId}
+	server, drive, _, err := s.client.newInstance(s.startParams(&progress), img, utils.Gzip([]byte{}), true)
+	c.Assert(err, gc.IsNil)
+	c.Check(server.Name(), gc.Equals, resumeBaseName)
+	c.Check(drive.Name(), gc.Equals, resumeBaseName)
+
+	c.Assert(len(progress) >= 3, gc.Equals, true)
+	c.Check(progress[0], gc.Equals, "cloning root drive")
+	c.Check(progress[len(progress)-2:], gc.DeepEquals, []string{"creating server", "starting server"})
+	for _, info := range progress[1 : len(progress)-2] {
+		c.Check(strings.HasPrefix(info, "cloning root drive ("), gc.Equals, true)
+	}
+}
+
+func (s *resumeSuite) TestNewInstanceAdoptsDrive(c *gc.C) {
+	mock.Drives.Add(&data.Drive{
+		Resource: data.Resource{URI: "uri", UUID: "stale-drive"},
+		Name:     resumeBaseName,
+		Media:    "disk",
+		Size:     2200 * gosigma.Megabyte,
+		Status:   "unmounted",
+	})
+
+	var progress []string
+	img := &imagemetadata.ImageMetadata{Id: validImageId}
+	_, drive, _, err := s.client.newInstance(s.startParams(&progress), img, utils.Gzip([]byte{}), true)
+	c.Assert(err, gc.IsNil)
+	c.Check(drive.UUID(), gc.Equals, "stale-drive")
+	c.Check(progress[0], gc.Equals, "adopting root drive")
+}
+
+func (s *resumeSuite) TestRemoveStaleServers(c *gc.C) {
+	addNamedTestServer(c, resumeBaseName, testEnvUUID, "stopped")
+	addNamedTestServer(c, resumeBaseName, testEnvUUID, "running")
+	alien := addNamedTestServer(c, resumeBaseName, "alien", "stopped")
+	other := addNamedTestServer(c, "juju-"+testEnvUUID+"-2", testEnvUUID, "stopped")
+
+	err := s.client.removeStaleServers(resumeBaseName, false)
+	c.Assert(err, gc.IsNil)
+
+	servers, err := s.client.conn.Servers(gosigma.RequestShort)
+	c.Assert(err, gc.IsNil)
+	left := make(map[string]bool)
+	for _, server := range servers {
+		left[server.UUID()] = true
+	}
+	c.Check(left, gc.DeepEquals, map[string]bool{alien: true, other: true})
+}
+
+func addNamedTestServer(c *gc.C, name, env, status string) string {
+	r := strings.NewReader(`{"name": "` + name + `", "meta": {"juju-instance": "server", "juju-environment": "` + env + `"}, "status": "` + status + `"}`)
+	s, err := data.ReadServer(r)
+	c.Assert(err, gc.IsNil)
+	mock.AddServer(s)
+	return s.UUID
+}
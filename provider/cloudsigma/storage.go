@@ -0,0 +1,413 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cloudsigma
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/schema"
+
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/storage"
+	"github.com/juju/juju/storage/poolmanager"
+)
+
+const (
+	SigmaDriveProviderType = storage.ProviderType("cloudsigma-drive")
+
+	// Config attributes
+
+	// The storage type of the drive (default "dssd"):
+	//   "dssd" for SSD drives,
+	//   "magnetic" for magnetic drives.
+	// Not all locations offer both storage types.
+	SigmaDriveStorageType = "storage-type"
+
+	storageTypeSSD      = "dssd"
+	storageTypeMagnetic = "magnetic"
+
+	mib = 1024 * 1024
+)
+
+func init() {
+	magneticPool, _ := storage.NewConfig("cloudsigma-magnetic", SigmaDriveProviderType, map[string]interface{}{
+		SigmaDriveStorageType: storageTypeMagnetic,
+	})
+	poolmanager.RegisterDefaultStoragePools([]*storage.Config{magneticPool})
+}
+
+// sigmaDriveProvider creates volume sources which use CloudSigma drives.
+type sigmaDriveProvider struct{}
+
+var _ storage.Provider = (*sigmaDriveProvider)(nil)
+
+var sigmaDriveConfigChecker = schema.FieldMap(
+	schema.Fields{
+		SigmaDriveStorageType: schema.OneOf(
+			schema.Const(storageTypeSSD),
+			schema.Const(storageTypeMagnetic),
+		),
+	},
+	schema.Defaults{
+		SigmaDriveStorageType: storageTypeSSD,
+	},
+)
+
+type sigmaDriveConfig struct {
+	storageType string
+}
+
+func newSigmaDriveConfig(attrs map[string]interface{}) (*sigmaDriveConfig, error) {
+	out, err := sigmaDriveConfigChecker.Coerce(attrs, nil)
+	if err != nil {
+		return nil, errors.Annotate(err, "validating CloudSigma drive storage config")
+	}
+	coerced := out.(map[string]interface{})
+	return &sigmaDriveConfig{
+		storageType: coerced[SigmaDriveStorageType].(string),
+	}, nil
+}
+
+// ValidateConfig is defined on the Provider interface.
+func (p *sigmaDriveProvider) ValidateConfig(cfg *storage.Config) error {
+	_, err := newSigmaDriveConfig(cfg.Attrs())
+	return errors.Trace(err)
+}
+
+// Supports is defined on the Provider interface.
+func (p *sigmaDriveProvider) Supports(k storage.StorageKind) bool {
+	return k == storage.StorageKindBlock
+}
+
+// Scope is defined on the Provider interface.
+func (p *sigmaDriveProvider) Scope() storage.Scope {
+	return storage.ScopeEnviron
+}
+
+// Dynamic is defined on the Provider interface.
+func (p *sigmaDriveProvider) Dynamic() bool {
+	return true
+}
+
+// VolumeSource is defined on the Provider interface.
+func (p *sigmaDriveProvider) VolumeSource(environConfig *config.Config, cfg *storage.Config) (storage.VolumeSource, error) {
+	if err := p.ValidateConfig(cfg); err != nil {
+		return nil, errors.Trace(err)
+	}
+	ecfg, err := validateConfig(environConfig, nil)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// Drives belong to a single location, so volumes are managed
+	// through the client of the zone of the instance they are
+	// attached to.
+	var clients []*environClient
+	for _, zone := range ecfg.zones() {
+		client, err := newClient(ecfg.withRegion(zone))
+		if err != nil {
+			return nil, errors.Annotate(err, "creating CloudSigma client")
+		}
+		clients = append(clients, client)
+	}
+	return &sigmaDriveVolumeSource{client: clients[0], clients: clients}, nil
+}
+
+// FilesystemSource is defined on the Provider interface.
+func (p *sigmaDriveProvider) FilesystemSource(environConfig *config.Config, providerConfig *storage.Config) (storage.FilesystemSource, error) {
+	return nil, errors.NotSupportedf("filesystems")
+}
+
+type sigmaDriveVolumeSource struct {
+	// client is the client of the environment's region.
+	client *environClient
+
+	// clients holds the clients of all the availability zones,
+	// starting with the environment's region.
+	clients []*environClient
+}
+
+var _ storage.VolumeSource = (*sigmaDriveVolumeSource)(nil)
+
+// instanceClient returns the client of the availability zone of the
+// server with the given UUID.
+func (v *sigmaDriveVolumeSource) instanceClient(serverUUID string) (*environClient, error) {
+	for _, client := range v.clients {
+		var server map[string]interface{}
+		err := client.rest.get(servers, serverUUID, &server)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return client, nil
+	}
+	return nil, errors.NotFoundf("server %q", serverUUID)
+}
+
+// driveClient returns the client of the availability zone of the
+// drive with the given UUID.
+func (v *sigmaDriveVolumeSource) driveClient(driveUUID string) (*environClient, error) {
+	for _, client := range v.clients {
+		_, err := client.drive(driveUUID)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return client, nil
+	}
+	return nil, errors.NotFoundf("drive %q", driveUUID)
+}
+
+// environDrives returns the drives created for volumes of the
+// environment in all the availability zones.
+func (v *sigmaDriveVolumeSource) environDrives() ([]sigmaDrive, error) {
+	var result []sigmaDrive
+	for _, client := range v.clients {
+		zoneDrives, err := client.environDrives()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		result = append(result, zoneDrives...)
+	}
+	return result, nil
+}
+
+// CreateVolumes is specified on the storage.VolumeSource interface.
+func (v *sigmaDriveVolumeSource) CreateVolumes(params []storage.VolumeParams) ([]storage.CreateVolumesResult, error) {
+	results := make([]storage.CreateVolumesResult, len(params))
+	for i, p := range params {
+		if err := v.ValidateVolumeParams(p); err != nil {
+			results[i].Error = err
+			continue
+		}
+		volume, err := v.createVolume(p)
+		if err != nil {
+			results[i].Error = errors.Trace(err)
+			continue
+		}
+		results[i].Volume = volume
+	}
+	return results, nil
+}
+
+// createVolume creates a drive for the volume. If a drive was already
+// created for the volume, e.g. before the storage provisioner was
+// restarted, it is reused and grown to the requested size if needed.
+func (v *sigmaDriveVolumeSource) createVolume(p storage.VolumeParams) (*storage.Volume, error) {
+	cfg, err := newSigmaDriveConfig(p.Attributes)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	size := p.Size * mib
+
+	// The drive is created in the zone of the instance it is to be
+	// attached to, if known.
+	client := v.client
+	if p.Attachment != nil && p.Attachment.InstanceId != "" {
+		if client, err = v.instanceClient(string(p.Attachment.InstanceId)); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
+	existing, err := client.environDrives()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var drive *sigmaDrive
+	for i := range existing {
+		if existing[i].Meta[jujuMetaVolume] == p.Tag.String() {
+			drive = &existing[i]
+			break
+		}
+	}
+
+	if drive == nil {
+		meta := map[string]string{
+			jujuMetaEnvironment: client.uuid,
+			jujuMetaVolume:      p.Tag.String(),
+		}
+		for k, val := range p.ResourceTags {
+			meta[k] = val
+		}
+		drive, err = client.createDrive(&sigmaDrive{
+			Name:        v.driveName(p.Tag.Id()),
+			Size:        size,
+			Media:       driveMediaDisk,
+			StorageType: cfg.storageType,
+			Meta:        meta,
+		})
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	} else if drive, err = client.waitDrive(drive.UUID); err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	if drive.Size < size {
+		if drive, err = client.resizeDrive(drive, size); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	logger.Debugf("created drive %q for volume %q", drive.UUID, p.Tag.Id())
+	return &storage.Volume{p.Tag, driveVolumeInfo(drive)}, nil
+}
+
+func (v *sigmaDriveVolumeSource) driveName(volumeId string) string {
+	return "juju-" + v.client.uuid + "-volume-" + volumeId
+}
+
+func driveVolumeInfo(drive *sigmaDrive) storage.VolumeInfo {
+	return storage.VolumeInfo{
+		VolumeId:   drive.UUID,
+		Size:       drive.Size / mib,
+		Persistent: true,
+	}
+}
+
+// ListVolumes is specified on the storage.VolumeSource interface.
+func (v *sigmaDriveVolumeSource) ListVolumes() ([]string, error) {
+	sigmaDrives, err := v.environDrives()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	volumeIds := make([]string, len(sigmaDrives))
+	for i, drive := range sigmaDrives {
+		volumeIds[i] = drive.UUID
+	}
+	return volumeIds, nil
+}
+
+// DescribeVolumes is specified on the storage.VolumeSource interface.
+func (v *sigmaDriveVolumeSource) DescribeVolumes(volIds []string) ([]storage.DescribeVolumesResult, error) {
+	sigmaDrives, err := v.environDrives()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	drivesById := make(map[string]*sigmaDrive)
+	for i, drive := range sigmaDrives {
+		drivesById[drive.UUID] = &sigmaDrives[i]
+	}
+	results := make([]storage.DescribeVolumesResult, len(volIds))
+	for i, volId := range volIds {
+		drive, ok := drivesById[volId]
+		if !ok {
+			results[i].Error = errors.NotFoundf("volume %q", volId)
+			continue
+		}
+		info := driveVolumeInfo(drive)
+		results[i].VolumeInfo = &info
+	}
+	return results, nil
+}
+
+// DestroyVolumes is specified on the storage.VolumeSource interface.
+func (v *sigmaDriveVolumeSource) DestroyVolumes(volIds []string) ([]error, error) {
+	results := make([]error, len(volIds))
+	for i, volId := range volIds {
+		results[i] = v.destroyVolume(volId)
+	}
+	return results, nil
+}
+
+func (v *sigmaDriveVolumeSource) destroyVolume(volId string) error {
+	logger.Debugf("destroying volume %q", volId)
+	client, err := v.driveClient(volId)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	drive, err := client.waitDrive(volId)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	// A drive must be detached from all servers before it
+	// can be removed.
+	for _, server := range drive.MountedOn {
+		if err := client.detachDrive(server.UUID, volId); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return errors.Trace(client.removeDrive(volId))
+}
+
+// ValidateVolumeParams is specified on the storage.VolumeSource interface.
+func (v *sigmaDriveVolumeSource) ValidateVolumeParams(params storage.VolumeParams) error {
+	if params.Size == 0 {
+		return errors.NotValidf("volume size 0")
+	}
+	_, err := newSigmaDriveConfig(params.Attributes)
+	return errors.Trace(err)
+}
+
+// AttachVolumes is specified on the storage.VolumeSource interface.
+func (v *sigmaDriveVolumeSource) AttachVolumes(params []storage.VolumeAttachmentParams) ([]storage.AttachVolumesResult, error) {
+	results := make([]storage.AttachVolumesResult, len(params))
+	for i, p := range params {
+		attachment, err := v.attachVolume(p)
+		if err != nil {
+			results[i].Error = errors.Annotatef(
+				err, "attaching volume %s to server %s", p.VolumeId, p.InstanceId,
+			)
+			continue
+		}
+		results[i].VolumeAttachment = attachment
+	}
+	return results, nil
+}
+
+func (v *sigmaDriveVolumeSource) attachVolume(p storage.VolumeAttachmentParams) (*storage.VolumeAttachment, error) {
+	if p.InstanceId == "" {
+		return nil, errors.New("instance not provisioned")
+	}
+	client, err := v.instanceClient(string(p.InstanceId))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if _, err := client.waitDrive(p.VolumeId); err != nil {
+		return nil, errors.Trace(err)
+	}
+	channel, err := client.attachDrive(string(p.InstanceId), p.VolumeId)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	device, err := deviceName(channel)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &storage.VolumeAttachment{
+		p.Volume,
+		p.Machine,
+		storage.VolumeAttachmentInfo{
+			DeviceName: device,
+			ReadOnly:   p.ReadOnly,
+		},
+	}, nil
+}
+
+// DetachVolumes is specified on the storage.VolumeSource interface.
+func (v *sigmaDriveVolumeSource) DetachVolumes(params []storage.VolumeAttachmentParams) ([]error, error) {
+	results := make([]error, len(params))
+	for i, p := range params {
+		if err := v.detachVolume(p); err != nil {
+			results[i] = errors.Annotatef(
+				err, "detaching volume %s from server %s", p.VolumeId, p.InstanceId,
+			)
+		}
+	}
+	return results, nil
+}
+
+func (v *sigmaDriveVolumeSource) detachVolume(p storage.VolumeAttachmentParams) error {
+	client, err := v.instanceClient(string(p.InstanceId))
+	if errors.IsNotFound(err) {
+		// The server, and so the attachment, is gone.
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(client.detachDrive(string(p.InstanceId), p.VolumeId))
+}
@@ -0,0 +1,270 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cloudsigma
+
+import (
+	"github.com/juju/names"
+	"github.com/juju/utils"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/storage"
+	"github.com/juju/juju/testing"
+)
+
+type storageSuite struct {
+	testing.BaseSuite
+	api    *fakeSigmaAPI
+	source storage.VolumeSource
+}
+
+var _ = gc.Suite(&storageSuite{})
+
+func (s *storageSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.api = newFakeSigmaAPI()
+	s.AddCleanup(func(*gc.C) { s.api.Close() })
+	s.PatchValue(&driveAttempt, utils.AttemptStrategy{})
+	s.PatchValue(&newClient, func(cfg *environConfig) (*environClient, error) {
+		uuid, _ := cfg.UUID()
+		return &environClient{rest: s.api.restClient(), uuid: uuid, config: cfg}, nil
+	})
+
+	p := &sigmaDriveProvider{}
+	cfg, err := storage.NewConfig("sigma", SigmaDriveProviderType, map[string]interface{}{})
+	c.Assert(err, gc.IsNil)
+	s.source, err = p.VolumeSource(newConfig(c, validAttrs()), cfg)
+	c.Assert(err, gc.IsNil)
+}
+
+func (s *storageSuite) volumeParams(id string, size uint64) storage.VolumeParams {
+	return storage.VolumeParams{
+		Tag:          names.NewVolumeTag(id),
+		Size:         size,
+		Provider:     SigmaDriveProviderType,
+		ResourceTags: map[string]string{"juju-env-uuid": "f54aac3a-9dcd-4a0c-86b5-24091478478c"},
+	}
+}
+
+func (s *storageSuite) createVolume(c *gc.C, id string, size uint64) *storage.Volume {
+	results, err := s.source.CreateVolumes([]storage.VolumeParams{s.volumeParams(id, size)})
+	c.Assert(err, gc.IsNil)
+	c.Assert(results, gc.HasLen, 1)
+	c.Assert(results[0].Error, gc.IsNil)
+	return results[0].Volume
+}
+
+func (s *storageSuite) TestValidateConfig(c *gc.C) {
+	p := &sigmaDriveProvider{}
+	cfg, err := storage.NewConfig("sigma", SigmaDriveProviderType, map[string]interface{}{
+		"storage-type": "magnetic",
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(p.ValidateConfig(cfg), gc.IsNil)
+
+	cfg, err = storage.NewConfig("sigma", SigmaDriveProviderType, map[string]interface{}{
+		"storage-type": "floppy",
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(p.ValidateConfig(cfg), gc.ErrorMatches, `validating CloudSigma drive storage config: storage-type: .*`)
+}
+
+func (s *storageSuite) TestSupports(c *gc.C) {
+	p := &sigmaDriveProvider{}
+	c.Assert(p.Supports(storage.StorageKindBlock), gc.Equals, true)
+	c.Assert(p.Supports(storage.StorageKindFilesystem), gc.Equals, false)
+	c.Assert(p.Scope(), gc.Equals, storage.ScopeEnviron)
+	c.Assert(p.Dynamic(), gc.Equals, true)
+}
+
+func (s *storageSuite) TestCreateVolumes(c *gc.C) {
+	volume := s.createVolume(c, "0", 2048)
+	c.Assert(volume.Tag, gc.Equals, names.NewVolumeTag("0"))
+	c.Assert(volume.Size, gc.Equals, uint64(2048))
+	c.Assert(volume.Persistent, gc.Equals, true)
+	c.Assert(s.api.driveNames(), gc.DeepEquals, []string{
+		"juju-f54aac3a-9dcd-4a0c-86b5-24091478478c-volume-0",
+	})
+
+	drive := s.api.drives[volume.VolumeId]
+	c.Assert(drive.Size, gc.Equals, uint64(2048*1024*1024))
+	c.Assert(drive.StorageType, gc.Equals, "dssd")
+	c.Assert(drive.Meta, gc.DeepEquals, map[string]string{
+		"juju-environment": "f54aac3a-9dcd-4a0c-86b5-24091478478c",
+		"juju-volume":      "volume-0",
+		"juju-env-uuid":    "f54aac3a-9dcd-4a0c-86b5-24091478478c",
+	})
+}
+
+func (s *storageSuite) TestCreateVolumesExistingResized(c *gc.C) {
+	first := s.createVolume(c, "0", 1024)
+	second := s.createVolume(c, "0", 4096)
+	c.Assert(second.VolumeId, gc.Equals, first.VolumeId)
+	c.Assert(second.Size, gc.Equals, uint64(4096))
+	c.Assert(s.api.driveNames(), gc.HasLen, 1)
+}
+
+func (s *storageSuite) TestCreateVolumesInvalidSize(c *gc.C) {
+	results, err := s.source.CreateVolumes([]storage.VolumeParams{s.volumeParams("0", 0)})
+	c.Assert(err, gc.IsNil)
+	c.Assert(results[0].Error, gc.ErrorMatches, "volume size 0 not valid")
+	c.Assert(s.api.driveNames(), gc.HasLen, 0)
+}
+
+func (s *storageSuite) TestListDescribeVolumes(c *gc.C) {
+	volume := s.createVolume(c, "0", 1024)
+	err := s.api.restClient().create(drives, &sigmaDrive{Name: "alien", Size: 1, Media: "disk"}, nil)
+	c.Assert(err, gc.IsNil)
+
+	ids, err := s.source.ListVolumes()
+	c.Assert(err, gc.IsNil)
+	c.Assert(ids, gc.DeepEquals, []string{volume.VolumeId})
+
+	results, err := s.source.DescribeVolumes([]string{volume.VolumeId, "missing"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(results, gc.HasLen, 2)
+	c.Assert(results[0].Error, gc.IsNil)
+	c.Assert(*results[0].VolumeInfo, gc.DeepEquals, volume.VolumeInfo)
+	c.Assert(results[1].Error, gc.ErrorMatches, `volume "missing" not found`)
+}
+
+func (s *storageSuite) TestAttachDetachVolumes(c *gc.C) {
+	volume0 := s.createVolume(c, "0", 1024)
+	volume1 := s.createVolume(c, "1", 1024)
+
+	params := func(volume *storage.Volume) storage.VolumeAttachmentParams {
+		return storage.VolumeAttachmentParams{
+			AttachmentParams: storage.AttachmentParams{
+				Machine:    names.NewMachineTag("0"),
+				InstanceId: instance.Id("server-0"),
+			},
+			Volume:   volume.Tag,
+			VolumeId: volume.VolumeId,
+		}
+	}
+	results, err := s.source.AttachVolumes([]storage.VolumeAttachmentParams{
+		params(volume0), params(volume1), params(volume0),
+	})
+	c.Assert(err, gc.IsNil)
+	c.Assert(results, gc.HasLen, 3)
+	for _, result := range results {
+		c.Assert(result.Error, gc.IsNil)
+	}
+	c.Assert(results[0].VolumeAttachment.DeviceName, gc.Equals, "vdb")
+	c.Assert(results[1].VolumeAttachment.DeviceName, gc.Equals, "vdc")
+	c.Assert(results[2].VolumeAttachment.DeviceName, gc.Equals, "vdb")
+	c.Assert(s.api.serverDrives("server-0"), gc.DeepEquals, []string{
+		"root-server-0", volume0.VolumeId, volume1.VolumeId,
+	})
+
+	errs, err := s.source.DetachVolumes([]storage.VolumeAttachmentParams{params(volume0)})
+	c.Assert(err, gc.IsNil)
+	c.Assert(errs, gc.DeepEquals, []error{nil})
+	c.Assert(s.api.serverDrives("server-0"), gc.DeepEquals, []string{
+		"root-server-0", volume1.VolumeId,
+	})
+}
+
+func (s *storageSuite) TestAttachVolumesNotProvisioned(c *gc.C) {
+	volume := s.createVolume(c, "0", 1024)
+	results, err := s.source.AttachVolumes([]storage.VolumeAttachmentParams{{
+		Volume:   volume.Tag,
+		VolumeId: volume.VolumeId,
+	}})
+	c.Assert(err, gc.IsNil)
+	c.Assert(results[0].Error, gc.ErrorMatches, ".*instance not provisioned")
+}
+
+func (s *storageSuite) TestDestroyVolumes(c *gc.C) {
+	volume := s.createVolume(c, "0", 1024)
+	_, err := s.source.AttachVolumes([]storage.VolumeAttachmentParams{{
+		AttachmentParams: storage.AttachmentParams{InstanceId: instance.Id("server-0")},
+		Volume:           volume.Tag,
+		VolumeId:         volume.VolumeId,
+	}})
+	c.Assert(err, gc.IsNil)
+
+	errs, err := s.source.DestroyVolumes([]string{volume.VolumeId, "missing"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(errs, gc.DeepEquals, []error{nil, nil})
+	c.Assert(s.api.driveNames(), gc.HasLen, 0)
+	c.Assert(s.api.serverDrives("server-0"), gc.DeepEquals, []string{"root-server-0"})
+}
+
+func (s *storageSuite) TestVolumesInInstanceZone(c *gc.C) {
+	// Servers in the lvs zone are only known there.
+	lvs := newFakeSigmaAPI()
+	s.AddCleanup(func(*gc.C) { lvs.Close() })
+	s.api.knownServersOnly = true
+	s.PatchValue(&newClient, func(cfg *environConfig) (*environClient, error) {
+		uuid, _ := cfg.UUID()
+		api := s.api
+		if cfg.region() == "lvs" {
+			api = lvs
+		}
+		return &environClient{rest: api.restClient(), uuid: uuid, config: cfg}, nil
+	})
+	p := &sigmaDriveProvider{}
+	cfg, err := storage.NewConfig("sigma", SigmaDriveProviderType, map[string]interface{}{})
+	c.Assert(err, gc.IsNil)
+	source, err := p.VolumeSource(newConfig(c, validAttrs().Merge(testing.Attrs{"zones": "lvs"})), cfg)
+	c.Assert(err, gc.IsNil)
+
+	attachment := storage.VolumeAttachmentParams{
+		AttachmentParams: storage.AttachmentParams{
+			Machine:    names.NewMachineTag("0"),
+			InstanceId: instance.Id("server-0"),
+		},
+		Volume: names.NewVolumeTag("0"),
+	}
+	params := s.volumeParams("0", 1024)
+	params.Attachment = &attachment
+	results, err := source.CreateVolumes([]storage.VolumeParams{params})
+	c.Assert(err, gc.IsNil)
+	c.Assert(results[0].Error, gc.IsNil)
+	c.Assert(s.api.driveNames(), gc.HasLen, 0)
+	c.Assert(lvs.driveNames(), gc.HasLen, 1)
+
+	attachment.VolumeId = results[0].Volume.VolumeId
+	attachResults, err := source.AttachVolumes([]storage.VolumeAttachmentParams{attachment})
+	c.Assert(err, gc.IsNil)
+	c.Assert(attachResults[0].Error, gc.IsNil)
+	c.Assert(lvs.serverDrives("server-0"), gc.DeepEquals, []string{
+		"root-server-0", attachment.VolumeId,
+	})
+
+	errs, err := source.DestroyVolumes([]string{attachment.VolumeId})
+	c.Assert(err, gc.IsNil)
+	c.Assert(errs, gc.DeepEquals, []error{nil})
+	c.Assert(lvs.driveNames(), gc.HasLen, 0)
+}
+
+func (s *storageSuite) TestDetachVolumeDrives(c *gc.C) {
+	volume := s.createVolume(c, "0", 1024)
+	_, err := s.source.AttachVolumes([]storage.VolumeAttachmentParams{{
+		AttachmentParams: storage.AttachmentParams{InstanceId: instance.Id("server-0")},
+		Volume:           volume.Tag,
+		VolumeId:         volume.VolumeId,
+	}})
+	c.Assert(err, gc.IsNil)
+
+	// Volumes are detached before their server is removed, so that
+	// only its root drive is removed with it.
+	client := s.source.(*sigmaDriveVolumeSource).client
+	err = client.detachVolumeDrives("server-0")
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.api.serverDrives("server-0"), gc.DeepEquals, []string{"root-server-0"})
+	c.Assert(s.api.driveNames(), gc.HasLen, 1)
+}
+
+func (s *storageSuite) TestDeviceName(c *gc.C) {
+	name, err := deviceName("0:3")
+	c.Assert(err, gc.IsNil)
+	c.Assert(name, gc.Equals, "vdd")
+
+	_, err = deviceName("1:0")
+	c.Assert(err, gc.ErrorMatches, `unsupported device channel "1:0"`)
+	_, err = deviceName("x")
+	c.Assert(err, gc.ErrorMatches, `bad device channel "x": .*`)
+}
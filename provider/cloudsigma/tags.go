@@ -0,0 +1,81 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cloudsigma
+
+import (
+	"encoding/json"
+
+	"github.com/juju/errors"
+)
+
+// sigmaTag is a CloudSigma tag, which labels a set of resources of the
+// account such as servers and drives.
+type sigmaTag struct {
+	UUID      string            `json:"uuid,omitempty"`
+	Name      string            `json:"name"`
+	Resources sigmaTagResources `json:"resources"`
+}
+
+// sigmaTagResources holds the UUIDs of the resources of a tag. The API
+// accepts plain UUIDs, but reports each resource as an object.
+type sigmaTagResources []string
+
+// UnmarshalJSON is part of the json.Unmarshaler interface.
+func (r *sigmaTagResources) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return errors.Trace(err)
+	}
+	uuids := make(sigmaTagResources, len(raw))
+	for i, res := range raw {
+		if err := json.Unmarshal(res, &uuids[i]); err == nil {
+			continue
+		}
+		var ref struct {
+			UUID string `json:"uuid"`
+		}
+		if err := json.Unmarshal(res, &ref); err != nil {
+			return errors.Trace(err)
+		}
+		uuids[i] = ref.UUID
+	}
+	*r = uuids
+	return nil
+}
+
+// tagResource adds the resource with the given UUID to each of the named
+// tags, creating the tags that do not exist yet.
+func (c *environClient) tagResource(uuid string, names []string) error {
+	var existing []sigmaTag
+	if err := c.rest.list(tags, &existing); err != nil {
+		return errors.Annotate(err, "cannot list tags")
+	}
+	named := make(map[string]sigmaTag, len(existing))
+	for _, tag := range existing {
+		named[tag.Name] = tag
+	}
+
+	for _, name := range names {
+		tag, ok := named[name]
+		if !ok {
+			tag = sigmaTag{Name: name, Resources: sigmaTagResources{uuid}}
+			if err := c.rest.create(tags, tag, nil); err != nil {
+				return errors.Annotatef(err, "cannot create tag %q", name)
+			}
+			continue
+		}
+		tagged := false
+		for _, res := range tag.Resources {
+			tagged = tagged || res == uuid
+		}
+		if tagged {
+			continue
+		}
+		tag.Resources = append(tag.Resources, uuid)
+		if err := c.rest.update(tags, tag.UUID, tag, nil); err != nil {
+			return errors.Annotatef(err, "cannot update tag %q", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cloudsigma
+
+import (
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/testing"
+)
+
+type tagsSuite struct {
+	testing.BaseSuite
+	api    *fakeSigmaAPI
+	client *environClient
+}
+
+var _ = gc.Suite(&tagsSuite{})
+
+func (s *tagsSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.api = newFakeSigmaAPI()
+	s.AddCleanup(func(*gc.C) { s.api.Close() })
+	s.client = &environClient{rest: s.api.restClient()}
+}
+
+func (s *tagsSuite) TestTagResource(c *gc.C) {
+	err := s.client.tagResource("server-0", []string{"web", "db"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.api.tagResources(), gc.DeepEquals, map[string][]string{
+		"web": {"server-0"},
+		"db":  {"server-0"},
+	})
+
+	err = s.client.tagResource("server-1", []string{"web"})
+	c.Assert(err, gc.IsNil)
+	err = s.client.tagResource("server-1", []string{"web"})
+	c.Assert(err, gc.IsNil)
+	c.Assert(s.api.tagResources(), gc.DeepEquals, map[string][]string{
+		"web": {"server-0", "server-1"},
+		"db":  {"server-0"},
+	})
+}
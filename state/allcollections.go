@@ -146,6 +146,14 @@ func allCollections() collectionSchema {
 			rawAccess: true,
 		},
 
+		// This collection holds the API tokens users may log in to the
+		// environment with in place of their passwords.
+		apiTokensC: {
+			indexes: []mgo.Index{{
+				Key: []string{"env-uuid", "user"},
+			}},
+		},
+
 		// This collection contains governors that prevent certain kinds of
 		// changes from being accepted.
 		blocksC: {},
@@ -319,6 +327,18 @@ func allCollections() collectionSchema {
 		// This collection holds information about cloud image metadata.
 		cloudimagemetadataC: {},
 
+		// This collection holds records of the API calls users make that
+		// change environments. It is pruned by the auditlogpruner worker.
+		auditLogC: {
+			indexes: []mgo.Index{{
+				Key: []string{"env-uuid", "time"},
+			}, {
+				Key: []string{"env-uuid", "user", "time"},
+			}, {
+				Key: []string{"env-uuid", "entities", "time"},
+			}},
+		},
+
 		// ----------------------
 
 		// Raw-access collections
@@ -337,6 +357,8 @@ const (
 	actionresultsC         = "actionresults"
 	actionsC               = "actions"
 	annotationsC           = "annotations"
+	apiTokensC             = "apitokens"
+	auditLogC              = "auditlog"
 	blockDevicesC          = "blockdevices"
 	blocksC                = "blocks"
 	charmsC                = "charms"
@@ -0,0 +1,230 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	"github.com/juju/utils"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// APITokenPrefix starts the credentials of every API token, telling
+// them apart from passwords.
+const APITokenPrefix = "apitoken:"
+
+// APIToken represents a credential a user may log in to the API of a
+// single environment with in place of their password, until it expires
+// or is revoked. The calls that may be made with it may be limited.
+type APIToken struct {
+	st  *State
+	doc apiTokenDoc
+}
+
+type apiTokenDoc struct {
+	DocID      string    `bson:"_id"`
+	ID         string    `bson:"tokenid"`
+	EnvUUID    string    `bson:"env-uuid"`
+	UserName   string    `bson:"user"`
+	SecretHash string    `bson:"secrethash"`
+	SecretSalt string    `bson:"secretsalt"`
+	Created    time.Time `bson:"created"`
+	Expires    time.Time `bson:"expires"`
+	ReadOnly   bool      `bson:"readonly,omitempty"`
+	Facades    []string  `bson:"facades,omitempty"`
+}
+
+// APITokenParams holds the parameters for adding an API token.
+type APITokenParams struct {
+	// User is the user the token lets log in. They must have access to
+	// the environment.
+	User names.UserTag
+
+	// Expires is when the token stops being accepted.
+	Expires time.Time
+
+	// ReadOnly limits the calls made with the token to those that do
+	// not change the environment.
+	ReadOnly bool
+
+	// Facades, if set, limits the calls made with the token to those
+	// on the named facades.
+	Facades []string
+}
+
+// ID returns the id of the token, which is part of its credentials.
+func (t *APIToken) ID() string {
+	return t.doc.ID
+}
+
+// User returns the tag of the user the token lets log in.
+func (t *APIToken) User() names.UserTag {
+	return names.NewUserTag(t.doc.UserName)
+}
+
+// Created returns when the token was added.
+func (t *APIToken) Created() time.Time {
+	return t.doc.Created
+}
+
+// Expires returns when the token stops being accepted.
+func (t *APIToken) Expires() time.Time {
+	return t.doc.Expires
+}
+
+// Expired reports whether the token is no longer accepted.
+func (t *APIToken) Expired() bool {
+	return !time.Now().Before(t.doc.Expires)
+}
+
+// ReadOnly reports whether the calls made with the token are limited
+// to those that do not change the environment.
+func (t *APIToken) ReadOnly() bool {
+	return t.doc.ReadOnly
+}
+
+// Facades returns the names of the facades the calls made with the
+// token are limited to, if they are.
+func (t *APIToken) Facades() []string {
+	return t.doc.Facades
+}
+
+// SecretValid reports whether the secret is the token's.
+func (t *APIToken) SecretValid(secret string) bool {
+	return utils.UserPasswordHash(secret, t.doc.SecretSalt) == t.doc.SecretHash
+}
+
+// APITokenCredentials returns the credentials a user logs in with to use
+// the token with the given id and secret.
+func APITokenCredentials(id, secret string) string {
+	return APITokenPrefix + id + ":" + secret
+}
+
+// ParseAPITokenCredentials returns the id and secret of the API token
+// the credentials hold, and whether they hold one.
+func ParseAPITokenCredentials(credentials string) (id, secret string, ok bool) {
+	if !strings.HasPrefix(credentials, APITokenPrefix) {
+		return "", "", false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(credentials, APITokenPrefix), ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// AddAPIToken adds an API token for the environment, returning it and
+// the credentials that use it. The credentials cannot be recovered
+// later.
+func (st *State) AddAPIToken(params APITokenParams) (*APIToken, string, error) {
+	if !params.Expires.After(time.Now()) {
+		return nil, "", errors.NotValidf("expiry time %v in the past", params.Expires)
+	}
+	if _, err := st.EnvironmentUser(params.User); err != nil {
+		return nil, "", errors.Annotatef(err, "cannot add API token for user %q", params.User.Username())
+	}
+	uuid, err := utils.NewUUID()
+	if err != nil {
+		return nil, "", errors.Annotate(err, "cannot generate API token id")
+	}
+	secret, err := utils.RandomPassword()
+	if err != nil {
+		return nil, "", errors.Annotate(err, "cannot generate API token secret")
+	}
+	salt, err := utils.RandomSalt()
+	if err != nil {
+		return nil, "", errors.Annotate(err, "cannot generate API token salt")
+	}
+	id := uuid.String()
+	doc := &apiTokenDoc{
+		DocID:      st.docID(id),
+		ID:         id,
+		EnvUUID:    st.EnvironUUID(),
+		UserName:   params.User.Username(),
+		SecretHash: utils.UserPasswordHash(secret, salt),
+		SecretSalt: salt,
+		Created:    nowToTheSecond(),
+		Expires:    params.Expires.UTC(),
+		ReadOnly:   params.ReadOnly,
+		Facades:    params.Facades,
+	}
+	ops := []txn.Op{{
+		C:      apiTokensC,
+		Id:     doc.DocID,
+		Assert: txn.DocMissing,
+		Insert: doc,
+	}}
+	if err := st.runTransaction(ops); err != nil {
+		return nil, "", errors.Annotate(err, "cannot add API token")
+	}
+	return &APIToken{st: st, doc: *doc}, APITokenCredentials(id, secret), nil
+}
+
+// APIToken returns the environment's API token with the given id.
+func (st *State) APIToken(id string) (*APIToken, error) {
+	apiTokens, closer := st.getCollection(apiTokensC)
+	defer closer()
+
+	token := &APIToken{st: st}
+	err := apiTokens.FindId(id).One(&token.doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("API token %q", id)
+	}
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot get API token %q", id)
+	}
+	token.fixTimes()
+	return token, nil
+}
+
+// APITokens returns the environment's API tokens for the given user,
+// including those that have expired.
+func (st *State) APITokens(user names.UserTag) ([]*APIToken, error) {
+	apiTokens, closer := st.getCollection(apiTokensC)
+	defer closer()
+
+	var docs []apiTokenDoc
+	err := apiTokens.Find(bson.D{{"user", user.Username()}}).Sort("created", "tokenid").All(&docs)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot get API tokens for user %q", user.Username())
+	}
+	tokens := make([]*APIToken, len(docs))
+	for i, doc := range docs {
+		tokens[i] = &APIToken{st: st, doc: doc}
+		tokens[i].fixTimes()
+	}
+	return tokens, nil
+}
+
+// fixTimes converts the times in the token, which are stored as UTC but
+// read as local times, back to UTC.
+func (t *APIToken) fixTimes() {
+	t.doc.Created = t.doc.Created.UTC()
+	t.doc.Expires = t.doc.Expires.UTC()
+}
+
+// RevokeAPIToken removes the environment's API token with the given id,
+// so that it is no longer accepted.
+func (st *State) RevokeAPIToken(id string) error {
+	ops := []txn.Op{{
+		C:      apiTokensC,
+		Id:     st.docID(id),
+		Assert: txn.DocExists,
+		Remove: true,
+	}}
+	err := st.runTransaction(ops)
+	if err == txn.ErrAborted {
+		err = errors.NewNotFound(err, fmt.Sprintf("API token %q", id))
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
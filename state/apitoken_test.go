@@ -0,0 +1,170 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/names"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/testing/factory"
+)
+
+type APITokenSuite struct {
+	ConnSuite
+	user names.UserTag
+}
+
+var _ = gc.Suite(&APITokenSuite{})
+
+func (s *APITokenSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	s.user = s.Factory.MakeUser(c, &factory.UserParams{Name: "bob"}).UserTag()
+}
+
+func (s *APITokenSuite) addToken(c *gc.C, st *state.State, params state.APITokenParams) (*state.APIToken, string) {
+	token, credentials, err := st.AddAPIToken(params)
+	c.Assert(err, jc.ErrorIsNil)
+	return token, credentials
+}
+
+func (s *APITokenSuite) TestAddAPIToken(c *gc.C) {
+	expires := time.Now().Add(time.Hour).Round(time.Second).UTC()
+	token, credentials := s.addToken(c, s.State, state.APITokenParams{
+		User:     s.user,
+		Expires:  expires,
+		ReadOnly: true,
+		Facades:  []string{"Client"},
+	})
+	c.Assert(token.ID(), gc.Not(gc.Equals), "")
+	c.Assert(token.User(), gc.Equals, names.NewUserTag("bob@local"))
+	c.Assert(token.Expires(), gc.Equals, expires)
+	c.Assert(token.Expired(), jc.IsFalse)
+	c.Assert(token.ReadOnly(), jc.IsTrue)
+	c.Assert(token.Facades(), jc.DeepEquals, []string{"Client"})
+
+	id, secret, ok := state.ParseAPITokenCredentials(credentials)
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(id, gc.Equals, token.ID())
+
+	token, err := s.State.APIToken(id)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(token.User(), gc.Equals, names.NewUserTag("bob@local"))
+	c.Assert(token.Expires(), gc.Equals, expires)
+	c.Assert(token.ReadOnly(), jc.IsTrue)
+	c.Assert(token.Facades(), jc.DeepEquals, []string{"Client"})
+	c.Assert(token.SecretValid(secret), jc.IsTrue)
+	c.Assert(token.SecretValid("wrong"), jc.IsFalse)
+}
+
+func (s *APITokenSuite) TestAddAPITokenExpired(c *gc.C) {
+	_, _, err := s.State.AddAPIToken(state.APITokenParams{
+		User:    s.user,
+		Expires: time.Now().Add(-time.Second),
+	})
+	c.Assert(err, gc.ErrorMatches, "expiry time .* in the past not valid")
+}
+
+func (s *APITokenSuite) TestAddAPITokenNeedsEnvironmentUser(c *gc.C) {
+	user := s.Factory.MakeUser(c, &factory.UserParams{Name: "alice", NoEnvUser: true})
+	_, _, err := s.State.AddAPIToken(state.APITokenParams{
+		User:    user.UserTag(),
+		Expires: time.Now().Add(time.Hour),
+	})
+	c.Assert(err, gc.ErrorMatches, `cannot add API token for user "alice@local": environment user "alice@local" not found`)
+}
+
+func (s *APITokenSuite) TestAPITokenNotFound(c *gc.C) {
+	_, err := s.State.APIToken("missing")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `API token "missing" not found`)
+}
+
+func (s *APITokenSuite) TestAPITokenScopedToEnvironment(c *gc.C) {
+	token, _ := s.addToken(c, s.State, state.APITokenParams{
+		User:    s.user,
+		Expires: time.Now().Add(time.Hour),
+	})
+	st := s.Factory.MakeEnvironment(c, nil)
+	defer st.Close()
+	_, err := st.APIToken(token.ID())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	err = st.RevokeAPIToken(token.ID())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *APITokenSuite) TestAPITokens(c *gc.C) {
+	token1, _ := s.addToken(c, s.State, state.APITokenParams{
+		User:    s.user,
+		Expires: time.Now().Add(time.Hour),
+	})
+	token2, _ := s.addToken(c, s.State, state.APITokenParams{
+		User:    s.user,
+		Expires: time.Now().Add(2 * time.Hour),
+	})
+	s.addToken(c, s.State, state.APITokenParams{
+		User:    s.Owner,
+		Expires: time.Now().Add(time.Hour),
+	})
+
+	tokens, err := s.State.APITokens(s.user)
+	c.Assert(err, jc.ErrorIsNil)
+	ids := make([]string, len(tokens))
+	for i, token := range tokens {
+		ids[i] = token.ID()
+	}
+	c.Assert(ids, jc.SameContents, []string{token1.ID(), token2.ID()})
+}
+
+func (s *APITokenSuite) TestRevokeAPIToken(c *gc.C) {
+	token, _ := s.addToken(c, s.State, state.APITokenParams{
+		User:    s.user,
+		Expires: time.Now().Add(time.Hour),
+	})
+	err := s.State.RevokeAPIToken(token.ID())
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = s.State.APIToken(token.ID())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	err = s.State.RevokeAPIToken(token.ID())
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `API token ".*" not found`)
+}
+
+func (s *APITokenSuite) TestParseAPITokenCredentials(c *gc.C) {
+	for i, test := range []struct {
+		credentials string
+		id          string
+		secret      string
+		ok          bool
+	}{
+		{"apitoken:abc:secret", "abc", "secret", true},
+		{"apitoken:abc:sec:ret", "abc", "sec:ret", true},
+		{state.APITokenCredentials("abc", "secret"), "abc", "secret", true},
+		{"password", "", "", false},
+		{"apitoken:abc", "", "", false},
+		{"apitoken::secret", "", "", false},
+		{"apitoken:abc:", "", "", false},
+	} {
+		c.Logf("test %d: %q", i, test.credentials)
+		id, secret, ok := state.ParseAPITokenCredentials(test.credentials)
+		c.Check(ok, gc.Equals, test.ok)
+		c.Check(id, gc.Equals, test.id)
+		c.Check(secret, gc.Equals, test.secret)
+	}
+}
+
+func (s *APITokenSuite) TestAPITokenExpired(c *gc.C) {
+	token, _ := s.addToken(c, s.State, state.APITokenParams{
+		User:    s.user,
+		Expires: time.Now().Add(50 * time.Millisecond),
+	})
+	c.Assert(token.Expired(), jc.IsFalse)
+	time.Sleep(100 * time.Millisecond)
+	c.Assert(token.Expired(), jc.IsTrue)
+}
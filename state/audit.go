@@ -0,0 +1,191 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// AuditRecord describes an API call made by a user that changed, or
+// tried to change, an environment.
+type AuditRecord struct {
+	// Time is when the call was made.
+	Time time.Time
+
+	// User is the tag of the user that made the call.
+	User string
+
+	// Facade, Version and Method identify the API call.
+	Facade  string
+	Version int
+	Method  string
+
+	// Entities holds the tags of the entities the call refers to.
+	Entities []string
+
+	// Args holds the arguments of the call as JSON, with any secrets
+	// in them redacted.
+	Args string
+
+	// Error holds the error the call failed with, if it did.
+	Error string
+}
+
+// auditRecordDoc is how an AuditRecord is stored in MongoDB.
+type auditRecordDoc struct {
+	Id       bson.ObjectId `bson:"_id"`
+	EnvUUID  string        `bson:"env-uuid"`
+	Time     time.Time     `bson:"time"`
+	User     string        `bson:"user"`
+	Facade   string        `bson:"facade"`
+	Version  int           `bson:"version"`
+	Method   string        `bson:"method"`
+	Entities []string      `bson:"entities,omitempty"`
+	Args     string        `bson:"args,omitempty"`
+	Error    string        `bson:"error,omitempty"`
+}
+
+// AddAuditRecord records an API call made against the environment.
+func (st *State) AddAuditRecord(record AuditRecord) error {
+	auditLog, closer := st.getCollection(auditLogC)
+	defer closer()
+
+	doc := &auditRecordDoc{
+		Id:       bson.NewObjectId(),
+		Time:     record.Time.UTC(),
+		User:     record.User,
+		Facade:   record.Facade,
+		Version:  record.Version,
+		Method:   record.Method,
+		Entities: record.Entities,
+		Args:     record.Args,
+		Error:    record.Error,
+	}
+	if err := auditLog.Writeable().Insert(doc); err != nil {
+		return errors.Annotate(err, "cannot add audit record")
+	}
+	return nil
+}
+
+// AuditFilter selects the audit records returned by AuditRecords.
+// The zero value selects all of them.
+type AuditFilter struct {
+	// User, if set, selects records of calls made by the user with
+	// the given tag.
+	User string
+
+	// Entity, if set, selects records of calls that refer to the
+	// entity with the given tag.
+	Entity string
+
+	// From and To, if set, select records of calls made at or after
+	// From, and before To.
+	From time.Time
+	To   time.Time
+
+	// Limit, if positive, selects only that many of the newest
+	// matching records.
+	Limit int
+}
+
+// AuditRecords returns the environment's audit records selected by the
+// filter, oldest first.
+func (st *State) AuditRecords(filter AuditFilter) ([]AuditRecord, error) {
+	auditLog, closer := st.getCollection(auditLogC)
+	defer closer()
+
+	sel := bson.D{}
+	if filter.User != "" {
+		sel = append(sel, bson.DocElem{"user", filter.User})
+	}
+	if filter.Entity != "" {
+		sel = append(sel, bson.DocElem{"entities", filter.Entity})
+	}
+	timeSel := bson.M{}
+	if !filter.From.IsZero() {
+		timeSel["$gte"] = filter.From.UTC()
+	}
+	if !filter.To.IsZero() {
+		timeSel["$lt"] = filter.To.UTC()
+	}
+	if len(timeSel) > 0 {
+		sel = append(sel, bson.DocElem{"time", timeSel})
+	}
+
+	query := auditLog.Find(sel).Sort("-time", "-_id")
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	var docs []auditRecordDoc
+	if err := query.All(&docs); err != nil {
+		return nil, errors.Annotate(err, "cannot get audit records")
+	}
+	records := make([]AuditRecord, len(docs))
+	for i, doc := range docs {
+		// The newest records were read first.
+		records[len(docs)-1-i] = AuditRecord{
+			Time:     doc.Time.UTC(),
+			User:     doc.User,
+			Facade:   doc.Facade,
+			Version:  doc.Version,
+			Method:   doc.Method,
+			Entities: doc.Entities,
+			Args:     doc.Args,
+			Error:    doc.Error,
+		}
+	}
+	return records, nil
+}
+
+// PruneAuditLog removes the audit records of all environments made
+// before minTime, and then the oldest records of any environment with
+// more than maxRecordsPerEnv of them.
+func PruneAuditLog(st *State, minTime time.Time, maxRecordsPerEnv int) error {
+	auditLog, closer := st.getRawCollection(auditLogC)
+	defer closer()
+
+	removeInfo, err := auditLog.RemoveAll(bson.D{{"time", bson.M{"$lt": minTime.UTC()}}})
+	if err != nil {
+		return errors.Annotate(err, "cannot prune audit records by time")
+	}
+	removed := removeInfo.Removed
+
+	var envUUIDs []string
+	if err := auditLog.Find(nil).Distinct("env-uuid", &envUUIDs); err != nil {
+		return errors.Annotate(err, "cannot get environments with audit records")
+	}
+	for _, envUUID := range envUUIDs {
+		// Find the newest record to remove, if there are too many.
+		// Records made at the same time are ordered by id, so that
+		// only those over the limit are removed.
+		var doc auditRecordDoc
+		err := auditLog.Find(bson.D{{"env-uuid", envUUID}}).
+			Sort("-time", "-_id").Skip(maxRecordsPerEnv).One(&doc)
+		if err == mgo.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return errors.Annotate(err, "cannot find audit records to prune")
+		}
+		removeInfo, err := auditLog.RemoveAll(bson.D{
+			{"env-uuid", envUUID},
+			{"$or", []bson.D{
+				{{"time", bson.M{"$lt": doc.Time}}},
+				{{"time", doc.Time}, {"_id", bson.M{"$lte": doc.Id}}},
+			}},
+		})
+		if err != nil {
+			return errors.Annotate(err, "cannot prune audit records by count")
+		}
+		removed += removeInfo.Removed
+	}
+	if removed > 0 {
+		logger.Debugf("pruned %d audit records", removed)
+	}
+	return nil
+}
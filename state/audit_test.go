@@ -0,0 +1,177 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state_test
+
+import (
+	"time"
+
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+)
+
+type AuditSuite struct {
+	ConnSuite
+	start time.Time
+}
+
+var _ = gc.Suite(&AuditSuite{})
+
+func (s *AuditSuite) SetUpTest(c *gc.C) {
+	s.ConnSuite.SetUpTest(c)
+	// MongoDB stores times to the millisecond.
+	s.start = time.Date(2015, 10, 1, 12, 0, 0, 0, time.UTC)
+}
+
+func (s *AuditSuite) addRecord(c *gc.C, st *state.State, offset time.Duration, user, method string, entities ...string) state.AuditRecord {
+	record := state.AuditRecord{
+		Time:     s.start.Add(offset),
+		User:     user,
+		Facade:   "Client",
+		Version:  0,
+		Method:   method,
+		Entities: entities,
+		Args:     `{"ServiceName":"wordpress"}`,
+	}
+	err := st.AddAuditRecord(record)
+	c.Assert(err, jc.ErrorIsNil)
+	return record
+}
+
+func (s *AuditSuite) TestAddAuditRecord(c *gc.C) {
+	record := state.AuditRecord{
+		Time:     s.start,
+		User:     "user-bob@local",
+		Facade:   "Client",
+		Version:  1,
+		Method:   "ServiceDestroy",
+		Entities: []string{"service-wordpress"},
+		Args:     `{"ServiceName":"wordpress"}`,
+		Error:    `service "wordpress" not found`,
+	}
+	err := s.State.AddAuditRecord(record)
+	c.Assert(err, jc.ErrorIsNil)
+
+	records, err := s.State.AuditRecords(state.AuditFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, jc.DeepEquals, []state.AuditRecord{record})
+}
+
+func (s *AuditSuite) TestAuditRecordsFilter(c *gc.C) {
+	r0 := s.addRecord(c, s.State, 0, "user-bob@local", "ServiceDeploy", "service-wordpress")
+	r1 := s.addRecord(c, s.State, time.Minute, "user-alice@local", "AddMachines")
+	r2 := s.addRecord(c, s.State, 2*time.Minute, "user-alice@local", "ServiceDestroy", "service-wordpress")
+	r3 := s.addRecord(c, s.State, 3*time.Minute, "user-bob@local", "DestroyMachines", "machine-0", "machine-1")
+
+	for i, test := range []struct {
+		about   string
+		filter  state.AuditFilter
+		records []state.AuditRecord
+	}{{
+		about:   "all records",
+		records: []state.AuditRecord{r0, r1, r2, r3},
+	}, {
+		about:   "by user",
+		filter:  state.AuditFilter{User: "user-alice@local"},
+		records: []state.AuditRecord{r1, r2},
+	}, {
+		about:   "by entity",
+		filter:  state.AuditFilter{Entity: "service-wordpress"},
+		records: []state.AuditRecord{r0, r2},
+	}, {
+		about:   "by one of several entities",
+		filter:  state.AuditFilter{Entity: "machine-1"},
+		records: []state.AuditRecord{r3},
+	}, {
+		about:   "by time",
+		filter:  state.AuditFilter{From: s.start.Add(time.Minute), To: s.start.Add(3 * time.Minute)},
+		records: []state.AuditRecord{r1, r2},
+	}, {
+		about:   "newest records",
+		filter:  state.AuditFilter{Limit: 3},
+		records: []state.AuditRecord{r1, r2, r3},
+	}, {
+		about:   "combined",
+		filter:  state.AuditFilter{User: "user-bob@local", Entity: "service-wordpress", To: s.start.Add(time.Hour)},
+		records: []state.AuditRecord{r0},
+	}, {
+		about:  "none matching",
+		filter: state.AuditFilter{User: "user-eve@local"},
+	}} {
+		c.Logf("test %d: %s", i, test.about)
+		records, err := s.State.AuditRecords(test.filter)
+		c.Check(err, jc.ErrorIsNil)
+		if len(test.records) == 0 {
+			c.Check(records, gc.HasLen, 0)
+		} else {
+			c.Check(records, jc.DeepEquals, test.records)
+		}
+	}
+}
+
+func (s *AuditSuite) TestAuditRecordsPerEnvironment(c *gc.C) {
+	otherState := s.Factory.MakeEnvironment(c, nil)
+	defer otherState.Close()
+
+	r0 := s.addRecord(c, s.State, 0, "user-bob@local", "ServiceDeploy")
+	r1 := s.addRecord(c, otherState, 0, "user-bob@local", "AddMachines")
+
+	records, err := s.State.AuditRecords(state.AuditFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, jc.DeepEquals, []state.AuditRecord{r0})
+
+	records, err = otherState.AuditRecords(state.AuditFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, jc.DeepEquals, []state.AuditRecord{r1})
+}
+
+func (s *AuditSuite) TestPruneAuditLogByTime(c *gc.C) {
+	s.addRecord(c, s.State, 0, "user-bob@local", "ServiceDeploy")
+	r1 := s.addRecord(c, s.State, time.Hour, "user-bob@local", "AddMachines")
+
+	err := state.PruneAuditLog(s.State, s.start.Add(time.Minute), 100)
+	c.Assert(err, jc.ErrorIsNil)
+
+	records, err := s.State.AuditRecords(state.AuditFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, jc.DeepEquals, []state.AuditRecord{r1})
+}
+
+func (s *AuditSuite) TestPruneAuditLogByCount(c *gc.C) {
+	otherState := s.Factory.MakeEnvironment(c, nil)
+	defer otherState.Close()
+
+	var records []state.AuditRecord
+	for i := 0; i < 5; i++ {
+		records = append(records, s.addRecord(c, s.State, time.Duration(i)*time.Minute, "user-bob@local", "AddMachines"))
+	}
+	other := s.addRecord(c, otherState, 0, "user-bob@local", "AddMachines")
+
+	err := state.PruneAuditLog(s.State, s.start.Add(-time.Hour), 2)
+	c.Assert(err, jc.ErrorIsNil)
+
+	remaining, err := s.State.AuditRecords(state.AuditFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(remaining, jc.DeepEquals, records[3:])
+
+	// Other environments keep their own records.
+	remaining, err = otherState.AuditRecords(state.AuditFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(remaining, jc.DeepEquals, []state.AuditRecord{other})
+}
+
+func (s *AuditSuite) TestPruneAuditLogByCountSameTime(c *gc.C) {
+	var records []state.AuditRecord
+	for _, method := range []string{"AddMachines", "ServiceDeploy", "AddRelation", "DestroyMachines"} {
+		records = append(records, s.addRecord(c, s.State, 0, "user-bob@local", method))
+	}
+
+	err := state.PruneAuditLog(s.State, s.start.Add(-time.Hour), 2)
+	c.Assert(err, jc.ErrorIsNil)
+
+	remaining, err := s.State.AuditRecords(state.AuditFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(remaining, jc.DeepEquals, records[2:])
+}
@@ -37,6 +37,7 @@ package backups
 
 import (
 	"io"
+	"sort"
 	"time"
 
 	"github.com/juju/errors"
@@ -83,8 +84,10 @@ func StoreArchive(stor filestorage.FileStorage, meta *Metadata, file io.Reader)
 // Backups is an abstraction around all juju backup-related functionality.
 type Backups interface {
 	// Create creates and stores a new juju backup archive. It updates
-	// the provided metadata.
-	Create(meta *Metadata, paths *Paths, dbInfo *DBInfo) error
+	// the provided metadata. The archive is encrypted with the key, if
+	// one is given. The backup is incremental if the metadata has a
+	// Base.
+	Create(meta *Metadata, paths *Paths, dbInfo *DBInfo, key *EncryptionKey) error
 
 	// Add stores the backup archive and returns its new ID.
 	Add(archive io.Reader, meta *Metadata) (string, error)
@@ -99,6 +102,8 @@ type Backups interface {
 	Remove(id string) error
 
 	// Restore updates juju's state to the contents of the backup archive.
+	// Restoring an incremental backup, or restoring until a point in
+	// time, replays the oplog of the backups of its chain.
 	Restore(backupId string, args RestoreArgs) error
 }
 
@@ -116,8 +121,20 @@ func NewBackups(stor filestorage.FileStorage) Backups {
 
 // Create creates and stores a new juju backup archive and updates the
 // provided metadata.
-func (b *backups) Create(meta *Metadata, paths *Paths, dbInfo *DBInfo) error {
+func (b *backups) Create(meta *Metadata, paths *Paths, dbInfo *DBInfo, key *EncryptionKey) error {
 	meta.Started = time.Now().UTC()
+	if key != nil {
+		fingerprint, err := key.Fingerprint()
+		if err != nil {
+			return errors.Annotate(err, "while preparing the encryption key")
+		}
+		meta.KeyFingerprint = fingerprint
+	}
+
+	dumper, err := b.dbDumper(meta, dbInfo)
+	if err != nil {
+		return errors.Annotate(err, "while preparing for DB dump")
+	}
 
 	// The metadata file will not contain the ID or the "finished" data.
 	// However, that information is not as critical. The alternatives
@@ -134,16 +151,15 @@ func (b *backups) Create(meta *Metadata, paths *Paths, dbInfo *DBInfo) error {
 	if err != nil {
 		return errors.Annotate(err, "while listing files to back up")
 	}
-	dumper, err := getDBDumper(dbInfo)
-	if err != nil {
-		return errors.Annotate(err, "while preparing for DB dump")
-	}
-	args := createArgs{filesToBackUp, dumper, metadataFile}
+	args := createArgs{filesToBackUp, dumper, metadataFile, key}
 	result, err := runCreate(&args)
 	if err != nil {
 		return errors.Annotate(err, "while creating backup archive")
 	}
 	defer result.archiveFile.Close()
+	if span, ok := dumper.(oplogSpan); ok {
+		meta.OplogStart, meta.OplogEnd = span.span()
+	}
 
 	// Finalize the metadata.
 	err = finishMeta(meta, result)
@@ -160,6 +176,32 @@ func (b *backups) Create(meta *Metadata, paths *Paths, dbInfo *DBInfo) error {
 	return nil
 }
 
+// dbDumper returns the dumper of the database for the backup. An
+// incremental backup only dumps the oplog written since the last backup
+// of its chain, whose span is recorded in the metadata right away.
+func (b *backups) dbDumper(meta *Metadata, dbInfo *DBInfo) (DBDumper, error) {
+	if meta.Base != "" {
+		list, err := b.List()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		dumper, err := newIncrementDumper(list, meta, dbInfo)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		meta.OplogStart, meta.OplogEnd = dumper.span()
+		return dumper, nil
+	}
+	dumper, err := getDBDumper(dbInfo)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if dbInfo.Oplog == nil {
+		return dumper, nil
+	}
+	return &fullDumper{DBDumper: dumper, oplog: dbInfo.Oplog}, nil
+}
+
 // Add stores the backup archive and returns its new ID.
 func (b *backups) Add(archive io.Reader, meta *Metadata) (string, error) {
 	// Store the archive.
@@ -204,7 +246,23 @@ func (b *backups) List() ([]*Metadata, error) {
 	return result, nil
 }
 
-// Remove deletes the backup from storage.
+// Remove deletes the backup from storage. A backup that incremental
+// backups build on is not removed, since they cannot be restored
+// without it.
 func (b *backups) Remove(id string) error {
+	list, err := b.List()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var built []string
+	for _, meta := range list {
+		if meta.Base == id {
+			built = append(built, meta.ID())
+		}
+	}
+	if len(built) > 0 {
+		sort.Strings(built)
+		return errors.Errorf("cannot remove backup %q: incremental backups %v build on it", id, built)
+	}
 	return errors.Trace(b.storage.Remove(id))
 }
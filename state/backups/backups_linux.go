@@ -7,6 +7,7 @@ package backups
 
 import (
 	"fmt"
+	"path/filepath"
 
 	"github.com/juju/errors"
 	"github.com/juju/names"
@@ -25,19 +26,31 @@ import (
 // old instances
 // * updates config in all agents.
 func (b *backups) Restore(backupId string, args RestoreArgs) error {
-	meta, backupReader, err := b.Get(backupId)
+	list, err := b.List()
 	if err != nil {
-		return errors.Annotatef(err, "could not fetch backup %q", backupId)
+		return errors.Trace(err)
 	}
+	plan, err := planRestore(list, backupId, args.Until)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	meta := plan.full
 
-	defer backupReader.Close()
-
-	workspace, err := NewArchiveWorkspaceReader(backupReader)
+	workspace, err := b.unpack(meta.ID())
 	if err != nil {
-		return errors.Annotate(err, "cannot unpack backup file")
+		return errors.Trace(err)
 	}
 	defer workspace.Close()
 
+	// Replay the oplog of each incremental backup after the oplog of
+	// the full backup.
+	oplogFile := filepath.Join(workspace.DBDumpDir, oplogFilename)
+	for _, increment := range plan.increments {
+		if err := b.appendIncrement(increment.ID(), oplogFile); err != nil {
+			return errors.Trace(err)
+		}
+	}
+
 	// TODO(perrito666) Create a compatibility table of sorts.
 	version := meta.Origin.Version
 	backupMachine := names.NewMachineTag(meta.Origin.Machine)
@@ -81,7 +94,7 @@ func (b *backups) Restore(backupId string, args RestoreArgs) error {
 	}
 
 	// Restore mongodb from backup
-	if err := placeNewMongo(workspace.DBDumpDir, version); err != nil {
+	if err := placeNewMongo(workspace.DBDumpDir, version, plan.oplogLimit); err != nil {
 		return errors.Annotate(err, "error restoring state from backup")
 	}
 
@@ -148,3 +161,37 @@ func (b *backups) Restore(backupId string, args RestoreArgs) error {
 
 	return errors.Annotate(err, "failed to set status to finished")
 }
+
+// unpack fetches the identified backup and unpacks its archive.
+// Encrypted archives are never decrypted on the server, so that the
+// key to decrypt them never leaves the client.
+func (b *backups) unpack(backupId string) (*ArchiveWorkspace, error) {
+	meta, backupReader, err := b.Get(backupId)
+	if err != nil {
+		return nil, errors.Annotatef(err, "could not fetch backup %q", backupId)
+	}
+	defer backupReader.Close()
+
+	if meta.KeyFingerprint != "" {
+		return nil, errors.Errorf("backup %q is encrypted and must be decrypted by the client before it is restored", backupId)
+	}
+
+	workspace, err := NewArchiveWorkspaceReader(backupReader)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot unpack backup file")
+	}
+	return workspace, nil
+}
+
+// appendIncrement appends the oplog of the identified incremental
+// backup to the oplog file.
+func (b *backups) appendIncrement(backupId, oplogFile string) error {
+	workspace, err := b.unpack(backupId)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer workspace.Close()
+
+	err = appendOplog(oplogFile, filepath.Join(workspace.DBDumpDir, oplogFilename))
+	return errors.Annotatef(err, "cannot replay incremental backup %q", backupId)
+}
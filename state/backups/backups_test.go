@@ -10,6 +10,7 @@ import (
 
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils/filestorage"
 	"github.com/juju/utils/set"
 	gc "gopkg.in/check.v1"
 
@@ -53,10 +54,10 @@ func (s *backupsSuite) checkFailure(c *gc.C, expected string) {
 
 	paths := backups.Paths{DataDir: "/var/lib/juju"}
 	targets := set.NewStrings("juju", "admin")
-	dbInfo := backups.DBInfo{"a", "b", "c", targets}
+	dbInfo := backups.DBInfo{"a", "b", "c", targets, nil}
 	meta := backupstesting.NewMetadataStarted()
 	meta.Notes = "some notes"
-	err := s.api.Create(meta, &paths, &dbInfo)
+	err := s.api.Create(meta, &paths, &dbInfo, nil)
 
 	c.Check(err, gc.ErrorMatches, expected)
 }
@@ -92,11 +93,11 @@ func (s *backupsSuite) TestCreateOkay(c *gc.C) {
 	// Run the backup.
 	paths := backups.Paths{DataDir: "/var/lib/juju"}
 	targets := set.NewStrings("juju", "admin")
-	dbInfo := backups.DBInfo{"a", "b", "c", targets}
+	dbInfo := backups.DBInfo{"a", "b", "c", targets, nil}
 	meta := backupstesting.NewMetadataStarted()
 	backupstesting.SetOrigin(meta, "<env ID>", "<machine ID>", "<hostname>")
 	meta.Notes = "some notes"
-	err := s.api.Create(meta, &paths, &dbInfo)
+	err := s.api.Create(meta, &paths, &dbInfo, nil)
 
 	// Test the call values.
 	s.Storage.CheckCalled(c, "spam", meta, archiveFile, "Add", "Metadata")
@@ -132,6 +133,30 @@ func (s *backupsSuite) TestCreateOkay(c *gc.C) {
 	c.Check(string(data), gc.Equals, "<compressed tarball>")
 }
 
+func (s *backupsSuite) TestCreateEncrypted(c *gc.C) {
+	received, testCreate := backups.NewTestCreate(nil)
+	s.PatchValue(backups.RunCreate, testCreate)
+	s.PatchValue(backups.TestGetFilesToBackUp, func(root string, paths *backups.Paths, oldmachine string) ([]string, error) {
+		return []string{"<some file>"}, nil
+	})
+	s.PatchValue(backups.GetDBDumper, func(info *backups.DBInfo) (backups.DBDumper, error) {
+		return nil, nil
+	})
+	s.setStored("spam")
+
+	paths := backups.Paths{DataDir: "/var/lib/juju"}
+	dbInfo := backups.DBInfo{"a", "b", "c", set.NewStrings("juju"), nil}
+	meta := backupstesting.NewMetadataStarted()
+	key := &backups.EncryptionKey{Passphrase: "secret"}
+	err := s.api.Create(meta, &paths, &dbInfo, key)
+	c.Assert(err, jc.ErrorIsNil)
+
+	c.Check(backups.ExposeCreateArgsKey(received), gc.Equals, key)
+	fingerprint, err := key.Fingerprint()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(meta.KeyFingerprint, gc.Equals, fingerprint)
+}
+
 func (s *backupsSuite) TestCreateFailToListFiles(c *gc.C) {
 	s.PatchValue(backups.TestGetFilesToBackUp, func(root string, paths *backups.Paths, oldmachine string) ([]string, error) {
 		return nil, errors.New("failed!")
@@ -172,6 +197,39 @@ func (s *backupsSuite) TestCreateFailToStoreArchive(c *gc.C) {
 	s.checkFailure(c, "while storing backup archive: failed!")
 }
 
+func (s *backupsSuite) TestCreateIncrementalWithoutOplog(c *gc.C) {
+	paths := backups.Paths{DataDir: "/var/lib/juju"}
+	dbInfo := backups.DBInfo{"a", "b", "c", set.NewStrings("juju"), nil}
+	meta := backupstesting.NewMetadataStarted()
+	meta.Base = "spam"
+	err := s.api.Create(meta, &paths, &dbInfo, nil)
+
+	c.Check(err, gc.ErrorMatches, "while preparing for DB dump: cannot create incremental backup without access to the oplog")
+}
+
+func (s *backupsSuite) TestRemove(c *gc.C) {
+	full := backupstesting.NewMetadataStarted()
+	full.SetID("full")
+	s.Storage.MetaList = []filestorage.Metadata{full}
+	err := s.api.Remove("full")
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.Storage.CheckCalled(c, "full", nil, nil, "List", "Remove")
+}
+
+func (s *backupsSuite) TestRemoveIncrementBase(c *gc.C) {
+	full := backupstesting.NewMetadataStarted()
+	full.SetID("full")
+	inc := backupstesting.NewMetadataStarted()
+	inc.SetID("inc")
+	inc.Base = "full"
+	s.Storage.MetaList = []filestorage.Metadata{full, inc}
+	err := s.api.Remove("full")
+	c.Check(err, gc.ErrorMatches, `cannot remove backup "full": incremental backups \[inc\] build on it`)
+
+	s.Storage.CheckCalled(c, "", nil, nil, "List")
+}
+
 func (s *backupsSuite) TestStoreArchive(c *gc.C) {
 	stored := s.setStored("spam")
 
@@ -31,6 +31,8 @@ type createArgs struct {
 	filesToBackUp  []string
 	db             DBDumper
 	metadataReader io.Reader
+	// key is the key to encrypt the archive with, if any.
+	key *EncryptionKey
 }
 
 type createResult struct {
@@ -47,6 +49,7 @@ func create(args *createArgs) (_ *createResult, err error) {
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	builder.key = args.key
 	defer func() {
 		if cerr := builder.cleanUp(); cerr != nil {
 			cerr.Log(logger)
@@ -96,6 +99,8 @@ type builder struct {
 	filesToBackUp []string
 	// db is the wrapper around the DB dump command and args.
 	db DBDumper
+	// key is the key to encrypt the archive with, if any.
+	key *EncryptionKey
 	// checksum is the checksum of the archive file.
 	checksum string
 	// archiveFile is the backup archive file.
@@ -312,9 +317,25 @@ func (b *builder) buildArchiveAndChecksum() error {
 	// than to the uncompressed contents of the tarball.  This is so
 	// that users can compare the published checksum against the
 	// checksum of the file without having to decompress it first.
+	// Likewise, the hash of an encrypted archive corresponds to the
+	// encrypted file.
 	hasher := hash.NewHashingWriter(b.archiveFile, sha1.New())
-	if err := b.buildArchive(hasher); err != nil {
-		return errors.Trace(err)
+	if b.key == nil {
+		if err := b.buildArchive(hasher); err != nil {
+			return errors.Trace(err)
+		}
+	} else {
+		encrypter, err := b.key.encrypt(hasher)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if err := b.buildArchive(encrypter); err != nil {
+			encrypter.Close()
+			return errors.Trace(err)
+		}
+		if err := encrypter.Close(); err != nil {
+			return errors.Annotate(err, "while encrypting archive")
+		}
 	}
 
 	// Save the SHA1 checksum.
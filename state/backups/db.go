@@ -4,6 +4,7 @@
 package backups
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
@@ -11,6 +12,8 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/juju/utils/set"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
 
 	"github.com/juju/juju/agent"
 	"github.com/juju/juju/juju/paths"
@@ -42,6 +45,10 @@ type DBInfo struct {
 	Password string
 	// Targets is a list of databases to dump.
 	Targets set.Strings
+	// Oplog is the replication oplog of the DB system, if it can be
+	// read. Incremental backups are read from it, and full backups
+	// record their position in it.
+	Oplog *mgo.Collection
 }
 
 // ignoredDatabases is the list of databases that should not be
@@ -237,9 +244,19 @@ func mongoRestoreArgsForVersion(ver version.Number, dumpPath string) ([]string,
 var restorePath = paths.MongorestorePath
 var restoreArgsForVersion = mongoRestoreArgsForVersion
 
+// oplogLimitArgs returns the mongorestore args, with those replaying
+// the oplog only before oplogLimit inserted before the dump path.
+func oplogLimitArgs(args []string, oplogLimit bson.MongoTimestamp) []string {
+	limit := fmt.Sprintf("%d:%d", int64(oplogLimit)>>32, uint32(oplogLimit))
+	last := len(args) - 1
+	result := append([]string{}, args[:last]...)
+	return append(result, "--oplogLimit", limit, args[last])
+}
+
 // placeNewMongo tries to use mongorestore to replace an existing
 // mongo with the dump in newMongoDumpPath returns an error if its not possible.
-func placeNewMongo(newMongoDumpPath string, ver version.Number) error {
+// The oplog of the dump is replayed up to oplogLimit, if it is not zero.
+func placeNewMongo(newMongoDumpPath string, ver version.Number, oplogLimit bson.MongoTimestamp) error {
 	mongoRestore, err := restorePath()
 	if err != nil {
 		return errors.Annotate(err, "mongorestore not available")
@@ -249,6 +266,9 @@ func placeNewMongo(newMongoDumpPath string, ver version.Number) error {
 	if err != nil {
 		return errors.Errorf("cannot restore this backup version")
 	}
+	if oplogLimit != 0 {
+		mgoRestoreArgs = oplogLimitArgs(mgoRestoreArgs, oplogLimit)
+	}
 	err = runCommand("initctl", "stop", mongo.ServiceName(""))
 	if err != nil {
 		return errors.Annotate(err, "failed to stop mongo")
@@ -30,7 +30,7 @@ func (s *dumpSuite) SetUpTest(c *gc.C) {
 	s.BaseSuite.SetUpTest(c)
 
 	targets := set.NewStrings("juju", "admin")
-	s.dbInfo = &backups.DBInfo{"a", "b", "c", targets}
+	s.dbInfo = &backups.DBInfo{"a", "b", "c", targets, nil}
 	s.targets = targets
 	s.dumpDir = c.MkDir()
 }
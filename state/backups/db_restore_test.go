@@ -8,6 +8,7 @@ import (
 
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
+	"gopkg.in/mgo.v2/bson"
 
 	"github.com/juju/juju/agent"
 	"github.com/juju/juju/state/backups"
@@ -91,7 +92,7 @@ func (s *mongoRestoreSuite) TestPlaceNewMongo(c *gc.C) {
 	}
 	s.PatchValue(backups.RestoreArgsForVersion, restoreArgsForVersion)
 
-	err := backups.PlaceNewMongo("fakemongopath", ver)
+	err := backups.PlaceNewMongo("fakemongopath", ver, 0)
 	c.Assert(restorePathCalled, jc.IsTrue)
 	c.Assert(err, jc.ErrorIsNil)
 	c.Assert(argsVersion, gc.DeepEquals, ver)
@@ -102,3 +103,30 @@ func (s *mongoRestoreSuite) TestPlaceNewMongo(c *gc.C) {
 	expectedArgs := [][]string{{"stop", "juju-db"}, {"a", "set", "of", "args"}, {"start", "juju-db"}}
 	c.Assert(ranArgs, gc.DeepEquals, expectedArgs)
 }
+
+func (s *mongoRestoreSuite) TestPlaceNewMongoOplogLimit(c *gc.C) {
+	var ranArgs [][]string
+	s.PatchValue(backups.RunCommand, func(command string, args ...string) error {
+		ranArgs = append(ranArgs, args)
+		return nil
+	})
+	s.PatchValue(backups.RestorePath, func() (string, error) {
+		return "/fake/mongo/restore/path", nil
+	})
+
+	ver := version.Number{Major: 1, Minor: 22}
+	limit := bson.MongoTimestamp(1435132020<<32 | 3)
+	err := backups.PlaceNewMongo("fakemongopath", ver, limit)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ranArgs, gc.HasLen, 3)
+	c.Assert(ranArgs[1], jc.DeepEquals, []string{
+		"--drop",
+		"--journal",
+		"--oplogReplay",
+		"--dbpath",
+		filepath.Join(agent.DefaultDataDir, "db"),
+		"--oplogLimit",
+		"1435132020:3",
+		"fakemongopath",
+	})
+}
@@ -0,0 +1,203 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/juju/errors"
+	"golang.org/x/crypto/openpgp"
+	pgperrors "golang.org/x/crypto/openpgp/errors"
+	"golang.org/x/crypto/openpgp/packet"
+)
+
+// Backup archives may be encrypted as OpenPGP messages, either
+// symmetrically with a passphrase or to a public key. The gzipped
+// tarball is encrypted as a whole, so the checksum in the metadata is
+// that of the encrypted archive as stored. Decrypting checks the
+// integrity of the archive through the modification detection code of
+// the message, once the archive has been read in full.
+
+// passphraseFingerprint is recorded as the fingerprint of the archives
+// encrypted with a passphrase. Nothing derived from the passphrase is
+// recorded; a wrong passphrase is detected when decrypting.
+const passphraseFingerprint = "passphrase"
+
+// errWrongKey is returned when an archive cannot be decrypted with the
+// given key.
+var errWrongKey = errors.New("wrong key")
+
+// EncryptionKey holds the key material used to encrypt or decrypt a
+// backup archive.
+type EncryptionKey struct {
+	// Passphrase encrypts the archive symmetrically. When decrypting
+	// with PrivateKey, it unlocks the private key instead.
+	Passphrase string
+	// PublicKey is an armored OpenPGP public key to encrypt the
+	// archive to.
+	PublicKey string
+	// PrivateKey is an armored OpenPGP private key to decrypt the
+	// archive with.
+	PrivateKey string
+}
+
+// readEntity returns the first entity of the armored OpenPGP key.
+func readEntity(armored string) (*openpgp.Entity, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armored))
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot read OpenPGP key")
+	}
+	if len(entities) == 0 {
+		return nil, errors.New("no OpenPGP key found")
+	}
+	return entities[0], nil
+}
+
+// Fingerprint returns the fingerprint identifying the key, to be
+// recorded in the metadata of the archives it encrypts. OpenPGP keys
+// are identified by the fingerprint of their primary key, which is the
+// same for a public key and its private key. Passphrases are not
+// identified beyond being passphrases.
+func (k *EncryptionKey) Fingerprint() (string, error) {
+	armored := k.PublicKey
+	if armored == "" {
+		armored = k.PrivateKey
+	}
+	if armored != "" {
+		entity, err := readEntity(armored)
+		if err != nil {
+			return "", errors.Trace(err)
+		}
+		return fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint[:]), nil
+	}
+	if k.Passphrase != "" {
+		return passphraseFingerprint, nil
+	}
+	return "", errors.New("missing passphrase or key")
+}
+
+// encrypt returns a writer encrypting what is written to it into w.
+// The writer must be closed to complete the message.
+func (k *EncryptionKey) encrypt(w io.Writer) (io.WriteCloser, error) {
+	if k.PublicKey != "" {
+		if k.Passphrase != "" {
+			return nil, errors.New("cannot encrypt with both a passphrase and a public key")
+		}
+		entity, err := readEntity(k.PublicKey)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		plain, err := openpgp.Encrypt(w, []*openpgp.Entity{entity}, nil, nil, nil)
+		return plain, errors.Annotate(err, "cannot encrypt backup archive")
+	}
+	if k.Passphrase != "" {
+		plain, err := openpgp.SymmetricallyEncrypt(w, []byte(k.Passphrase), nil, nil)
+		return plain, errors.Annotate(err, "cannot encrypt backup archive")
+	}
+	return nil, errors.New("missing passphrase or public key")
+}
+
+// keyRing returns the key ring holding the private key, unlocked with
+// the passphrase if needed.
+func (k *EncryptionKey) keyRing() (openpgp.EntityList, error) {
+	if k.PrivateKey == "" {
+		return nil, nil
+	}
+	entity, err := readEntity(k.PrivateKey)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if entity.PrivateKey == nil {
+		return nil, errors.New("OpenPGP key is not a private key")
+	}
+	unlock := func(key *packet.PrivateKey) error {
+		if key == nil || !key.Encrypted {
+			return nil
+		}
+		if err := key.Decrypt([]byte(k.Passphrase)); err != nil {
+			return errors.New("cannot unlock OpenPGP private key: wrong passphrase")
+		}
+		return nil
+	}
+	if err := unlock(entity.PrivateKey); err != nil {
+		return nil, err
+	}
+	for _, subkey := range entity.Subkeys {
+		if err := unlock(subkey.PrivateKey); err != nil {
+			return nil, err
+		}
+	}
+	return openpgp.EntityList{entity}, nil
+}
+
+// Decrypt returns a reader of the plain archive read from the
+// encrypted archive r. Reading fails if the archive turns out to have
+// been altered once it is read in full.
+func (k *EncryptionKey) Decrypt(r io.Reader) (io.Reader, error) {
+	keyRing, err := k.keyRing()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// ReadMessage prompts again as long as the passphrase is wrong.
+	prompted := false
+	prompt := func(_ []openpgp.Key, symmetric bool) ([]byte, error) {
+		if !symmetric || k.Passphrase == "" || prompted {
+			return nil, errWrongKey
+		}
+		prompted = true
+		return []byte(k.Passphrase), nil
+	}
+	md, err := openpgp.ReadMessage(r, keyRing, prompt, nil)
+	if err == pgperrors.ErrKeyIncorrect {
+		err = errWrongKey
+	}
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot decrypt backup archive")
+	}
+	return &decryptingReader{md.UnverifiedBody}, nil
+}
+
+// decryptingReader reports the failures to read a decrypted archive as
+// failed integrity checks.
+type decryptingReader struct {
+	io.Reader
+}
+
+// Read implements io.Reader.
+func (r *decryptingReader) Read(buf []byte) (int, error) {
+	n, err := r.Reader.Read(buf)
+	if err != nil && err != io.EOF {
+		err = errors.Annotate(err, "backup archive failed integrity check")
+	}
+	return n, err
+}
+
+// DecryptArchive returns a reader of the plain archive read from
+// archive, which was encrypted with the key of the given fingerprint,
+// if any. Archives that are not encrypted are returned as they are.
+// An OpenPGP key must match the fingerprint, so that a wrong key is
+// reported before anything is read. A wrong passphrase is reported
+// when the archive fails to decrypt.
+func DecryptArchive(archive io.Reader, fingerprint string, key *EncryptionKey) (io.Reader, error) {
+	if fingerprint == "" {
+		return archive, nil
+	}
+	if key == nil {
+		return nil, errors.Errorf("backup archive is encrypted with key %s, but no key was given", fingerprint)
+	}
+	given, err := key.Fingerprint()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if given != fingerprint {
+		return nil, errors.Errorf("backup archive is encrypted with key %s, not with the given key %s", fingerprint, given)
+	}
+	plain, err := key.Decrypt(archive)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return plain, nil
+}
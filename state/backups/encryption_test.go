@@ -0,0 +1,167 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+
+	jc "github.com/juju/testing/checkers"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state/backups"
+	"github.com/juju/juju/testing"
+)
+
+type encryptionSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&encryptionSuite{})
+
+// newTestKeys returns the armored public and private keys of a new
+// OpenPGP entity.
+func newTestKeys(c *gc.C) (string, string) {
+	entity, err := openpgp.NewEntity("juju", "backups", "juju@example.com", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	var public, private bytes.Buffer
+	w, err := armor.Encode(&public, openpgp.PublicKeyType, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(entity.Serialize(w), jc.ErrorIsNil)
+	c.Assert(w.Close(), jc.ErrorIsNil)
+
+	w, err = armor.Encode(&private, openpgp.PrivateKeyType, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(entity.SerializePrivate(w, nil), jc.ErrorIsNil)
+	c.Assert(w.Close(), jc.ErrorIsNil)
+	return public.String(), private.String()
+}
+
+func encrypt(c *gc.C, key *backups.EncryptionKey, data string) []byte {
+	var encrypted bytes.Buffer
+	w, err := backups.EncryptArchive(key, &encrypted)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = w.Write([]byte(data))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(w.Close(), jc.ErrorIsNil)
+	return encrypted.Bytes()
+}
+
+func (s *encryptionSuite) TestFingerprintPassphrase(c *gc.C) {
+	// Nothing derived from the passphrase is recorded.
+	fingerprint, err := (&backups.EncryptionKey{Passphrase: "secret"}).Fingerprint()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(fingerprint, gc.Equals, "passphrase")
+
+	other, err := (&backups.EncryptionKey{Passphrase: "other"}).Fingerprint()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(other, gc.Equals, fingerprint)
+}
+
+func (s *encryptionSuite) TestFingerprintKeys(c *gc.C) {
+	public, private := newTestKeys(c)
+	fingerprint, err := (&backups.EncryptionKey{PublicKey: public}).Fingerprint()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(fingerprint, gc.Matches, "[0-9A-F]{40}")
+
+	other, err := (&backups.EncryptionKey{PrivateKey: private}).Fingerprint()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(other, gc.Equals, fingerprint)
+}
+
+func (s *encryptionSuite) TestFingerprintMissing(c *gc.C) {
+	_, err := (&backups.EncryptionKey{}).Fingerprint()
+	c.Check(err, gc.ErrorMatches, "missing passphrase or key")
+}
+
+func (s *encryptionSuite) TestPassphrase(c *gc.C) {
+	key := &backups.EncryptionKey{Passphrase: "secret"}
+	encrypted := encrypt(c, key, "<archive>")
+	c.Check(string(encrypted), gc.Not(jc.Contains), "<archive>")
+
+	fingerprint, err := key.Fingerprint()
+	c.Assert(err, jc.ErrorIsNil)
+	plain, err := backups.DecryptArchive(bytes.NewReader(encrypted), fingerprint, key)
+	c.Assert(err, jc.ErrorIsNil)
+	data, err := ioutil.ReadAll(plain)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), gc.Equals, "<archive>")
+}
+
+func (s *encryptionSuite) TestPublicKey(c *gc.C) {
+	public, private := newTestKeys(c)
+	encrypted := encrypt(c, &backups.EncryptionKey{PublicKey: public}, "<archive>")
+
+	key := &backups.EncryptionKey{PrivateKey: private}
+	fingerprint, err := key.Fingerprint()
+	c.Assert(err, jc.ErrorIsNil)
+	plain, err := backups.DecryptArchive(bytes.NewReader(encrypted), fingerprint, key)
+	c.Assert(err, jc.ErrorIsNil)
+	data, err := ioutil.ReadAll(plain)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), gc.Equals, "<archive>")
+}
+
+func (s *encryptionSuite) TestPublicKeyCannotDecrypt(c *gc.C) {
+	public, _ := newTestKeys(c)
+	key := &backups.EncryptionKey{PublicKey: public}
+	_, err := key.Decrypt(bytes.NewReader(encrypt(c, key, "<archive>")))
+	c.Check(err, gc.ErrorMatches, "cannot decrypt backup archive: wrong key")
+}
+
+func (s *encryptionSuite) TestWrongPassphrase(c *gc.C) {
+	encrypted := encrypt(c, &backups.EncryptionKey{Passphrase: "secret"}, "<archive>")
+	_, err := (&backups.EncryptionKey{Passphrase: "guess"}).Decrypt(bytes.NewReader(encrypted))
+	c.Check(err, gc.ErrorMatches, "cannot decrypt backup archive: wrong key")
+}
+
+func (s *encryptionSuite) TestDecryptArchiveNotEncrypted(c *gc.C) {
+	archive := strings.NewReader("<archive>")
+	plain, err := backups.DecryptArchive(archive, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(plain, gc.Equals, archive)
+}
+
+func (s *encryptionSuite) TestDecryptArchiveMissingKey(c *gc.C) {
+	_, err := backups.DecryptArchive(strings.NewReader("<archive>"), "passphrase", nil)
+	c.Check(err, gc.ErrorMatches, "backup archive is encrypted with key passphrase, but no key was given")
+}
+
+func (s *encryptionSuite) TestDecryptArchiveWrongKey(c *gc.C) {
+	public, private := newTestKeys(c)
+	key := &backups.EncryptionKey{PrivateKey: private}
+	fingerprint, err := key.Fingerprint()
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = backups.DecryptArchive(strings.NewReader("<archive>"), "0123456789ABCDEF0123456789ABCDEF01234567", key)
+	c.Check(err, gc.ErrorMatches, "backup archive is encrypted with key 0123456789ABCDEF0123456789ABCDEF01234567, not with the given key "+fingerprint)
+
+	encrypted := encrypt(c, &backups.EncryptionKey{Passphrase: "secret"}, "<archive>")
+	_, err = backups.DecryptArchive(bytes.NewReader(encrypted), "passphrase", key)
+	c.Check(err, gc.ErrorMatches, "backup archive is encrypted with key passphrase, not with the given key "+fingerprint)
+
+	encrypted = encrypt(c, &backups.EncryptionKey{PublicKey: public}, "<archive>")
+	_, err = backups.DecryptArchive(bytes.NewReader(encrypted), fingerprint, &backups.EncryptionKey{Passphrase: "secret"})
+	c.Check(err, gc.ErrorMatches, "backup archive is encrypted with key "+fingerprint+", not with the given key passphrase")
+}
+
+func (s *encryptionSuite) TestDecryptArchiveWrongPassphrase(c *gc.C) {
+	encrypted := encrypt(c, &backups.EncryptionKey{Passphrase: "secret"}, "<archive>")
+	_, err := backups.DecryptArchive(bytes.NewReader(encrypted), "passphrase", &backups.EncryptionKey{Passphrase: "guess"})
+	c.Check(err, gc.ErrorMatches, "cannot decrypt backup archive: wrong key")
+}
+
+func (s *encryptionSuite) TestDecryptArchiveAltered(c *gc.C) {
+	key := &backups.EncryptionKey{Passphrase: "secret"}
+	encrypted := encrypt(c, key, strings.Repeat("<archive>", 1000))
+	encrypted[len(encrypted)/2] ^= 0xff
+
+	plain, err := key.Decrypt(bytes.NewReader(encrypted))
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = ioutil.ReadAll(plain)
+	c.Check(err, gc.ErrorMatches, "backup archive failed integrity check: .*")
+}
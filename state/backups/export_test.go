@@ -12,6 +12,8 @@ import (
 	"github.com/juju/errors"
 	"github.com/juju/testing"
 	"github.com/juju/utils/filestorage"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
 
 	"github.com/juju/juju/state"
 )
@@ -30,6 +32,17 @@ var (
 	ReplaceableFolders   = &replaceableFolders
 )
 
+var (
+	ReadOplogEnd     = readOplogEnd
+	OplogFilename    = oplogFilename
+	OplogReadTimeout = &oplogReadTimeout
+)
+
+var (
+	RestoreDumpLocally = &restoreDumpLocally
+	CountDocuments     = countDocuments
+)
+
 var _ filestorage.DocStorage = (*backupsDocStorage)(nil)
 var _ filestorage.RawFileStorage = (*backupBlobStorage)(nil)
 
@@ -87,6 +100,11 @@ func SetBackupStoredTime(st *state.State, id string, stored time.Time) error {
 	return setStorageStoredTime(db, id, stored)
 }
 
+// EncryptArchive returns a writer encrypting an archive with the key.
+func EncryptArchive(key *EncryptionKey, w io.Writer) (io.WriteCloser, error) {
+	return key.encrypt(w)
+}
+
 // ExposeCreateResult extracts the values in a create() result.
 func ExposeCreateResult(result *createResult) (io.ReadCloser, int64, string) {
 	return result.archiveFile, result.size, result.checksum
@@ -102,6 +120,16 @@ func NewTestCreateArgs(filesToBackUp []string, db DBDumper, metar io.Reader) *cr
 	return &args
 }
 
+// SetCreateArgsKey sets the key a create() call encrypts the archive with.
+func SetCreateArgsKey(args *createArgs, key *EncryptionKey) {
+	args.key = key
+}
+
+// ExposeCreateArgsKey extracts the key in a create() args value.
+func ExposeCreateArgsKey(args *createArgs) *EncryptionKey {
+	return args.key
+}
+
 // ExposeCreateResult extracts the values in a create() args value.
 func ExposeCreateArgs(args *createArgs) ([]string, DBDumper) {
 	return args.filesToBackUp, args.db
@@ -168,3 +196,30 @@ var PlaceNewMongo = placeNewMongo
 var MongoRestoreArgsForVersion = mongoRestoreArgsForVersion
 var RestorePath = &restorePath
 var RestoreArgsForVersion = &restoreArgsForVersion
+
+// NewIncrementDumper returns a dumper of the oplog entries after start,
+// up to and including end.
+func NewIncrementDumper(oplog *mgo.Collection, start, end bson.MongoTimestamp) DBDumper {
+	return &incrementDumper{oplog: oplog, start: start, end: end}
+}
+
+// IncrementStart returns where the next incremental backup on the
+// identified full backup starts.
+func IncrementStart(list []*Metadata, base string) (bson.MongoTimestamp, error) {
+	return incrementStart(list, base)
+}
+
+// PlanRestore returns the ID of the full backup, the IDs of the
+// incremental backups and the oplog limit used to restore the
+// identified backup.
+func PlanRestore(list []*Metadata, id string, until time.Time) (string, []string, bson.MongoTimestamp, error) {
+	plan, err := planRestore(list, id, until)
+	if err != nil {
+		return "", nil, 0, err
+	}
+	var increments []string
+	for _, meta := range plan.increments {
+		increments = append(increments, meta.ID())
+	}
+	return plan.full.ID(), increments, plan.oplogLimit, nil
+}
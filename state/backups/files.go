@@ -23,6 +23,7 @@ const (
 
 	agentsDir    = "agents"
 	agentsConfs  = "machine-*"
+	agentConf    = "agent.conf"
 	loggingConfs = "*juju.conf"
 	toolsDir     = "tools"
 
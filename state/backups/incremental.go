@@ -0,0 +1,425 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+import (
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/set"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/juju/juju/mongo"
+)
+
+// An incremental backup holds the entries of the oplog written since
+// the last backup of its chain, which starts with a full backup. Its
+// archive is laid out as the archive of a full backup, except that the
+// database dump only holds the oplog. Restoring an incremental backup
+// restores the full backup it builds on and replays the oplog of each
+// backup of the chain in turn, up to a point in time if one is given.
+
+// oplogFilename is the name of the file holding the oplog in a
+// database dump, as written by mongodump --oplog.
+const oplogFilename = "oplog.bson"
+
+// oplogReadTimeout is how long reading the oplog of an incremental
+// backup may wait for the next entry.
+var oplogReadTimeout = time.Minute
+
+// ignoredOplogDatabases are the databases whose changes are left out
+// of incremental backups.
+var ignoredOplogDatabases = set.NewStrings(
+	"local",
+	storageDBName,
+	"presence",
+)
+
+// oplogPosition returns the timestamp of the first entry of the oplog
+// in the given order: "$natural" for the oldest entry, "-$natural" for
+// the latest.
+func oplogPosition(oplog *mgo.Collection, order string) (bson.MongoTimestamp, error) {
+	var doc struct {
+		Timestamp bson.MongoTimestamp `bson:"ts"`
+	}
+	err := oplog.Find(nil).Sort(order).Select(bson.M{"ts": 1}).One(&doc)
+	if err == mgo.ErrNotFound {
+		return 0, errors.New("oplog is empty")
+	} else if err != nil {
+		return 0, errors.Annotate(err, "cannot read oplog")
+	}
+	return doc.Timestamp, nil
+}
+
+// oplogSpan is implemented by the dumpers that know the span of the
+// oplog their dump covers, once it is written.
+type oplogSpan interface {
+	span() (start, end bson.MongoTimestamp)
+}
+
+// fullDumper dumps the whole database, recording the span of the oplog
+// the dump covers.
+type fullDumper struct {
+	DBDumper
+	oplog      *mgo.Collection
+	start, end bson.MongoTimestamp
+}
+
+// Dump implements DBDumper.
+func (d *fullDumper) Dump(dumpDir string) error {
+	start, err := oplogPosition(d.oplog, "-$natural")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err := d.DBDumper.Dump(dumpDir); err != nil {
+		return errors.Trace(err)
+	}
+	// The dump is consistent as of the last entry of its oplog, which
+	// holds the changes made while dumping.
+	end, err := readOplogEnd(filepath.Join(dumpDir, oplogFilename))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if end < start {
+		end = start
+	}
+	d.start, d.end = start, end
+	return nil
+}
+
+func (d *fullDumper) span() (bson.MongoTimestamp, bson.MongoTimestamp) {
+	return d.start, d.end
+}
+
+// incrementDumper dumps the entries of the oplog after start, up to
+// and including end.
+type incrementDumper struct {
+	oplog      *mgo.Collection
+	start, end bson.MongoTimestamp
+}
+
+// oplogEntry is an oplog entry as written to the dump of an
+// incremental backup.
+type oplogEntry struct {
+	Timestamp    bson.MongoTimestamp `bson:"ts"`
+	OperationId  int64               `bson:"h"`
+	MongoVersion int                 `bson:"v"`
+	Operation    string              `bson:"op"`
+	Namespace    string              `bson:"ns"`
+	Object       *bson.Raw           `bson:"o,omitempty"`
+	UpdateObject *bson.Raw           `bson:"o2,omitempty"`
+}
+
+// ignored reports whether the oplog entry is left out of incremental
+// backups: no-ops and changes to ignored databases.
+func ignored(doc *mongo.OplogDoc) bool {
+	if doc.Operation == "n" {
+		return true
+	}
+	dbName := strings.SplitN(doc.Namespace, ".", 2)[0]
+	return ignoredOplogDatabases.Contains(dbName)
+}
+
+// Dump implements DBDumper.
+func (d *incrementDumper) Dump(dumpDir string) error {
+	file, err := os.Create(filepath.Join(dumpDir, oplogFilename))
+	if err != nil {
+		return errors.Annotate(err, "while creating oplog file")
+	}
+	if err := d.dump(file); err != nil {
+		file.Close()
+		return errors.Trace(err)
+	}
+	return errors.Trace(file.Close())
+}
+
+func (d *incrementDumper) dump(w io.Writer) error {
+	if d.end <= d.start {
+		// Nothing was written since the last backup.
+		return nil
+	}
+	tailer := mongo.NewOplogTailer(d.oplog, nil, mongo.MongoTimestampTime(d.start))
+	defer tailer.Stop()
+
+	for {
+		var doc *mongo.OplogDoc
+		select {
+		case doc = <-tailer.Out():
+		case <-time.After(oplogReadTimeout):
+			return errors.New("timed out reading oplog")
+		}
+		if doc == nil {
+			err := tailer.Err()
+			if err == nil {
+				err = errors.New("oplog tailer stopped")
+			}
+			return errors.Annotate(err, "cannot read oplog")
+		}
+		if doc.Timestamp <= d.start {
+			// The tailer starts at the second of start.
+			continue
+		}
+		if doc.Timestamp > d.end {
+			return nil
+		}
+		if !ignored(doc) {
+			data, err := bson.Marshal(oplogEntry{
+				Timestamp:    doc.Timestamp,
+				OperationId:  doc.OperationId,
+				MongoVersion: doc.MongoVersion,
+				Operation:    doc.Operation,
+				Namespace:    doc.Namespace,
+				Object:       doc.Object,
+				UpdateObject: doc.UpdateObject,
+			})
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if _, err := w.Write(data); err != nil {
+				return errors.Annotate(err, "while writing oplog file")
+			}
+		}
+		if doc.Timestamp == d.end {
+			return nil
+		}
+	}
+}
+
+func (d *incrementDumper) span() (bson.MongoTimestamp, bson.MongoTimestamp) {
+	return d.start, d.end
+}
+
+// readOplogEnd returns the timestamp of the last entry of the oplog
+// file, or zero if it has none. The file holds BSON documents one after
+// the other, each starting with its length.
+func readOplogEnd(filename string) (bson.MongoTimestamp, error) {
+	file, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, errors.Trace(err)
+	}
+	defer file.Close()
+
+	var end bson.MongoTimestamp
+	for {
+		var size int32
+		if err := binary.Read(file, binary.LittleEndian, &size); err == io.EOF {
+			return end, nil
+		} else if err != nil {
+			return 0, errors.Annotatef(err, "cannot read %q", filename)
+		}
+		if size < 5 {
+			return 0, errors.Errorf("cannot read %q: invalid document size %d", filename, size)
+		}
+		data := make([]byte, size)
+		binary.LittleEndian.PutUint32(data, uint32(size))
+		if _, err := io.ReadFull(file, data[4:]); err != nil {
+			return 0, errors.Annotatef(err, "cannot read %q", filename)
+		}
+		var doc struct {
+			Timestamp bson.MongoTimestamp `bson:"ts"`
+		}
+		if err := bson.Unmarshal(data, &doc); err != nil {
+			return 0, errors.Annotatef(err, "cannot read %q", filename)
+		}
+		end = doc.Timestamp
+	}
+}
+
+// appendOplog appends the entries of the oplog file src to the oplog
+// file dst.
+func appendOplog(dst, src string) error {
+	in, err := os.Open(src)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return errors.Annotatef(err, "while appending to %q", dst)
+	}
+	return errors.Trace(out.Close())
+}
+
+// IncrementBase returns the latest full backup in list that incremental
+// backups can build on, or nil if there is none. Encrypted backups are
+// not built on, since restoring the changes needs the plain archive of
+// the base on the state server.
+func IncrementBase(list []*Metadata) *Metadata {
+	var base *Metadata
+	for _, meta := range list {
+		if meta.Base != "" || meta.Finished == nil || meta.OplogEnd == 0 || meta.KeyFingerprint != "" {
+			continue
+		}
+		if base == nil || meta.Started.After(base.Started) {
+			base = meta
+		}
+	}
+	return base
+}
+
+// findBackup returns the backup in list with the given ID.
+func findBackup(list []*Metadata, id string) (*Metadata, error) {
+	for _, meta := range list {
+		if meta.ID() == id {
+			return meta, nil
+		}
+	}
+	return nil, errors.NotFoundf("backup %q", id)
+}
+
+// increments returns the complete incremental backups in list built on
+// the full backup with the given ID, in the order of the oplog.
+func increments(list []*Metadata, base string) []*Metadata {
+	var result []*Metadata
+	for _, meta := range list {
+		if meta.Base == base && meta.Finished != nil {
+			result = append(result, meta)
+		}
+	}
+	sort.Sort(byOplogStart(result))
+	return result
+}
+
+type byOplogStart []*Metadata
+
+func (b byOplogStart) Len() int           { return len(b) }
+func (b byOplogStart) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byOplogStart) Less(i, j int) bool { return b[i].OplogStart < b[j].OplogStart }
+
+// incrementStart returns where the next incremental backup on the full
+// backup with the given ID starts in the oplog: where the last backup
+// of its chain ends.
+func incrementStart(list []*Metadata, base string) (bson.MongoTimestamp, error) {
+	meta, err := findBackup(list, base)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	if meta.Base != "" {
+		return 0, errors.Errorf("backup %q is incremental, not a full backup", base)
+	}
+	if meta.Finished == nil || meta.OplogEnd == 0 {
+		return 0, errors.Errorf("backup %q does not record its position in the oplog", base)
+	}
+	start := meta.OplogEnd
+	for _, meta := range increments(list, base) {
+		if meta.OplogEnd > start {
+			start = meta.OplogEnd
+		}
+	}
+	return start, nil
+}
+
+// newIncrementDumper returns a dumper of the oplog written since the
+// last backup of the chain the metadata builds on.
+func newIncrementDumper(list []*Metadata, meta *Metadata, dbInfo *DBInfo) (*incrementDumper, error) {
+	if dbInfo.Oplog == nil {
+		return nil, errors.New("cannot create incremental backup without access to the oplog")
+	}
+	start, err := incrementStart(list, meta.Base)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot create incremental backup")
+	}
+	oldest, err := oplogPosition(dbInfo.Oplog, "$natural")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if oldest > start {
+		return nil, errors.Errorf("the oplog no longer holds the changes since the last backup on %q; create a full backup", meta.Base)
+	}
+	end, err := oplogPosition(dbInfo.Oplog, "-$natural")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return &incrementDumper{
+		oplog: dbInfo.Oplog,
+		start: start,
+		end:   end,
+	}, nil
+}
+
+// restorePlan describes how a backup is restored.
+type restorePlan struct {
+	// full is the full backup to restore.
+	full *Metadata
+	// increments are the incremental backups whose oplog is replayed
+	// on the full backup, in order.
+	increments []*Metadata
+	// oplogLimit is the timestamp before which oplog entries are
+	// replayed, or zero to replay them all.
+	oplogLimit bson.MongoTimestamp
+}
+
+// planRestore returns how to restore the identified backup, with the
+// changes up to until, if it is not zero. Until may be later than an
+// incremental backup when restoring a full backup, in which case the
+// increments built on it are replayed up to until.
+func planRestore(list []*Metadata, id string, until time.Time) (*restorePlan, error) {
+	target, err := findBackup(list, id)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	plan := &restorePlan{full: target}
+	if target.Base != "" {
+		if plan.full, err = findBackup(list, target.Base); err != nil {
+			return nil, errors.Annotatef(err, "cannot find the base of incremental backup %q", id)
+		}
+	} else if until.IsZero() {
+		return plan, nil
+	}
+	full := plan.full
+	if full.OplogEnd == 0 {
+		return nil, errors.Errorf("backup %q does not record its position in the oplog", full.ID())
+	}
+	if !until.IsZero() {
+		if until.Before(mongo.MongoTimestampTime(full.OplogEnd)) {
+			return nil, errors.Errorf("cannot restore to %v, before backup %q was complete at %v",
+				until.UTC(), full.ID(), mongo.MongoTimestampTime(full.OplogEnd))
+		}
+		plan.oplogLimit = mongo.NewMongoTimestamp(until.Add(time.Second))
+	}
+
+	position := full.OplogEnd
+	for _, meta := range increments(list, full.ID()) {
+		if target.Base != "" && meta.OplogStart > target.OplogStart {
+			break
+		}
+		if plan.oplogLimit != 0 && meta.OplogStart >= plan.oplogLimit {
+			break
+		}
+		if meta.OplogStart < position {
+			// Another backup of the chain already covers it.
+			continue
+		}
+		if meta.OplogStart > position {
+			return nil, errors.Errorf("missing incremental backup of the changes between %v and %v",
+				mongo.MongoTimestampTime(position), mongo.MongoTimestampTime(meta.OplogStart))
+		}
+		plan.increments = append(plan.increments, meta)
+		position = meta.OplogEnd
+	}
+	if target.Base != "" && plan.oplogLimit == 0 && position != target.OplogEnd {
+		return nil, errors.Errorf("incremental backup %q does not follow the backups of its chain", id)
+	}
+	if !until.IsZero() && mongo.MongoTimestampTime(position).Before(until.UTC().Truncate(time.Second)) {
+		return nil, errors.Errorf("cannot restore to %v, the backups of %q end at %v",
+			until.UTC(), full.ID(), mongo.MongoTimestampTime(position))
+	}
+	return plan, nil
+}
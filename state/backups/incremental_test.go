@@ -0,0 +1,247 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	gitjujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+
+	"github.com/juju/juju/mongo"
+	"github.com/juju/juju/state/backups"
+	backupstesting "github.com/juju/juju/state/backups/testing"
+	"github.com/juju/juju/testing"
+)
+
+type incrementalSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&incrementalSuite{})
+
+// epoch is the time of the oplog timestamps used by the tests.
+var epoch = time.Date(2015, 6, 24, 7, 47, 0, 0, time.UTC)
+
+// ts returns the oplog timestamp of the given second after epoch.
+func ts(second int) bson.MongoTimestamp {
+	return mongo.NewMongoTimestamp(epoch.Add(time.Duration(second) * time.Second))
+}
+
+// chainedBackup returns the metadata of a complete backup covering the
+// oplog between the given seconds after epoch.
+func chainedBackup(id, base string, start, end int) *backups.Metadata {
+	meta := backupstesting.NewMetadataStarted()
+	meta.SetID(id)
+	meta.Started = epoch.Add(time.Duration(start) * time.Second)
+	meta.Base = base
+	meta.OplogStart = ts(start)
+	meta.OplogEnd = ts(end)
+	backupstesting.FinishMetadata(meta)
+	return meta
+}
+
+func (s *incrementalSuite) chain() []*backups.Metadata {
+	return []*backups.Metadata{
+		chainedBackup("full", "", 0, 10),
+		chainedBackup("inc-2", "full", 20, 30),
+		chainedBackup("inc-1", "full", 10, 20),
+		chainedBackup("inc-3", "full", 30, 40),
+		chainedBackup("other", "", 100, 110),
+	}
+}
+
+func (s *incrementalSuite) TestIncrementBase(c *gc.C) {
+	list := s.chain()
+	c.Check(backups.IncrementBase(list).ID(), gc.Equals, "other")
+
+	list[4].OplogEnd = 0
+	c.Check(backups.IncrementBase(list).ID(), gc.Equals, "full")
+
+	c.Check(backups.IncrementBase(list[1:4]), gc.IsNil)
+
+	list[0].KeyFingerprint = "passphrase"
+	c.Check(backups.IncrementBase(list), gc.IsNil)
+}
+
+func (s *incrementalSuite) TestIncrementStart(c *gc.C) {
+	list := s.chain()
+	start, err := backups.IncrementStart(list, "full")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(start, gc.Equals, ts(40))
+
+	start, err = backups.IncrementStart(list, "other")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(start, gc.Equals, ts(110))
+
+	_, err = backups.IncrementStart(list, "inc-1")
+	c.Check(err, gc.ErrorMatches, `backup "inc-1" is incremental, not a full backup`)
+	_, err = backups.IncrementStart(list, "missing")
+	c.Check(err, gc.ErrorMatches, `backup "missing" not found`)
+
+	list[0].OplogEnd = 0
+	_, err = backups.IncrementStart(list, "full")
+	c.Check(err, gc.ErrorMatches, `backup "full" does not record its position in the oplog`)
+}
+
+func (s *incrementalSuite) TestPlanRestore(c *gc.C) {
+	at := func(second int) time.Time {
+		return epoch.Add(time.Duration(second) * time.Second)
+	}
+	for i, test := range []struct {
+		id         string
+		until      time.Time
+		full       string
+		increments []string
+		limit      bson.MongoTimestamp
+	}{{
+		id:   "full",
+		full: "full",
+	}, {
+		id:         "inc-1",
+		full:       "full",
+		increments: []string{"inc-1"},
+	}, {
+		id:         "inc-3",
+		full:       "full",
+		increments: []string{"inc-1", "inc-2", "inc-3"},
+	}, {
+		id:         "full",
+		until:      at(25),
+		full:       "full",
+		increments: []string{"inc-1", "inc-2"},
+		limit:      ts(26),
+	}, {
+		id:         "inc-3",
+		until:      at(20),
+		full:       "full",
+		increments: []string{"inc-1", "inc-2"},
+		limit:      ts(21),
+	}, {
+		id:         "full",
+		until:      at(10),
+		full:       "full",
+		increments: []string{"inc-1"},
+		limit:      ts(11),
+	}} {
+		c.Logf("test %d: %s until %v", i, test.id, test.until)
+		full, increments, limit, err := backups.PlanRestore(s.chain(), test.id, test.until)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(full, gc.Equals, test.full)
+		c.Check(increments, jc.DeepEquals, test.increments)
+		c.Check(limit, gc.Equals, test.limit)
+	}
+}
+
+func (s *incrementalSuite) TestPlanRestoreInvalid(c *gc.C) {
+	_, _, _, err := backups.PlanRestore(s.chain(), "full", epoch.Add(5*time.Second))
+	c.Check(err, gc.ErrorMatches, `cannot restore to .*, before backup "full" was complete at .*`)
+
+	_, _, _, err = backups.PlanRestore(s.chain(), "inc-2", epoch.Add(35*time.Second))
+	c.Check(err, gc.ErrorMatches, `cannot restore to .*, the backups of "full" end at .*`)
+
+	list := s.chain()
+	list = append(list[:2], list[3:]...)
+	_, _, _, err = backups.PlanRestore(list, "inc-3", time.Time{})
+	c.Check(err, gc.ErrorMatches, `missing incremental backup of the changes between .* and .*`)
+
+	_, _, _, err = backups.PlanRestore(s.chain()[1:], "inc-1", time.Time{})
+	c.Check(err, gc.ErrorMatches, `cannot find the base of incremental backup "inc-1": backup "full" not found`)
+}
+
+type oplogDumpSuite struct {
+	gitjujutesting.MgoSuite
+	testing.BaseSuite
+	oplog *mgo.Collection
+}
+
+var _ = gc.Suite(&oplogDumpSuite{})
+
+func (s *oplogDumpSuite) SetUpSuite(c *gc.C) {
+	s.BaseSuite.SetUpSuite(c)
+	s.MgoSuite.SetUpSuite(c)
+}
+
+func (s *oplogDumpSuite) TearDownSuite(c *gc.C) {
+	s.MgoSuite.TearDownSuite(c)
+	s.BaseSuite.TearDownSuite(c)
+}
+
+func (s *oplogDumpSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.MgoSuite.SetUpTest(c)
+	s.PatchValue(backups.OplogReadTimeout, testing.LongWait)
+
+	// The tailer reads any capped collection as it would the oplog.
+	s.oplog = s.Session.DB("foo").C("oplog.fake")
+	err := s.oplog.Create(&mgo.CollectionInfo{
+		Capped:   true,
+		MaxBytes: 1024 * 1024,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *oplogDumpSuite) TearDownTest(c *gc.C) {
+	s.MgoSuite.TearDownTest(c)
+	s.BaseSuite.TearDownTest(c)
+}
+
+func (s *oplogDumpSuite) insert(c *gc.C, second int, op, ns string) {
+	err := s.oplog.Insert(&mongo.OplogDoc{
+		Timestamp:   ts(second),
+		OperationId: int64(second),
+		Operation:   op,
+		Namespace:   ns,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *oplogDumpSuite) TestDump(c *gc.C) {
+	s.insert(c, 0, "i", "juju.machines")
+	s.insert(c, 10, "i", "juju.machines")
+	s.insert(c, 11, "u", "juju.machines")
+	s.insert(c, 12, "n", "")
+	s.insert(c, 13, "i", "presence.presence.beings")
+	s.insert(c, 14, "i", "backups.metadata")
+	s.insert(c, 15, "d", "juju.units")
+	s.insert(c, 16, "i", "juju.units")
+
+	dumpDir := c.MkDir()
+	dumper := backups.NewIncrementDumper(s.oplog, ts(10), ts(15))
+	err := dumper.Dump(dumpDir)
+	c.Assert(err, jc.ErrorIsNil)
+
+	data, err := ioutil.ReadFile(filepath.Join(dumpDir, backups.OplogFilename))
+	c.Assert(err, jc.ErrorIsNil)
+	var found []string
+	for len(data) > 0 {
+		var doc mongo.OplogDoc
+		size := int(data[0]) | int(data[1])<<8 | int(data[2])<<16 | int(data[3])<<24
+		err := bson.Unmarshal(data[:size], &doc)
+		c.Assert(err, jc.ErrorIsNil)
+		found = append(found, doc.Operation+" "+doc.Namespace)
+		data = data[size:]
+	}
+	c.Check(found, jc.DeepEquals, []string{"u juju.machines", "d juju.units"})
+
+	end, err := backups.ReadOplogEnd(filepath.Join(dumpDir, backups.OplogFilename))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(end, gc.Equals, ts(15))
+}
+
+func (s *oplogDumpSuite) TestDumpNothing(c *gc.C) {
+	dumpDir := c.MkDir()
+	dumper := backups.NewIncrementDumper(s.oplog, ts(10), ts(10))
+	err := dumper.Dump(dumpDir)
+	c.Assert(err, jc.ErrorIsNil)
+
+	end, err := backups.ReadOplogEnd(filepath.Join(dumpDir, backups.OplogFilename))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(end, gc.Equals, bson.MongoTimestamp(0))
+}
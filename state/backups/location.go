@@ -0,0 +1,186 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+import (
+	"io"
+	"os"
+	"path"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/filestorage"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	envfilestorage "github.com/juju/juju/environs/filestorage"
+	"github.com/juju/juju/environs/sshstorage"
+	"github.com/juju/juju/environs/storage"
+)
+
+// Backup archives are stored where the backups-storage setting of the
+// environment says: in the environment's blobstore, which is the
+// default, in a directory of the state server, in a directory of a
+// host reached over SSH, or in the storage of the provider. Storing
+// them off the state server keeps them when the state server is lost.
+// The metadata is always kept in state, and records where each archive
+// was stored, so that changing the setting does not lose track of the
+// archives stored before.
+
+// openLocation returns the storage of the archives kept at the given
+// location, which is not in state.
+var openLocation = func(st DB, location config.BackupsStorage) (storage.Storage, error) {
+	switch location.Type {
+	case config.BackupsStorageLocal:
+		if err := os.MkdirAll(location.Dir, 0700); err != nil {
+			return nil, errors.Trace(err)
+		}
+		return envfilestorage.NewFileStorageWriter(location.Dir)
+	case config.BackupsStorageSSH:
+		return sshstorage.NewSSHStorage(sshstorage.NewSSHStorageParams{
+			Host:       location.Host,
+			StorageDir: location.Dir,
+			TmpDir:     path.Join(location.Dir, ".tmp"),
+		})
+	case config.BackupsStorageProvider:
+		cfg, err := st.EnvironConfig()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		env, err := environs.New(cfg)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		envStorage, ok := env.(environs.EnvironStorage)
+		if !ok {
+			return nil, errors.NotSupportedf("storing backups with provider %q", cfg.Type())
+		}
+		return envStorage.Storage(), nil
+	}
+	return nil, errors.NotValidf("backups storage %q", location)
+}
+
+// archiveStorage stores backup archives at their location, either in
+// state or elsewhere.
+type archiveStorage struct {
+	st     DB
+	dbWrap *storageDBWrapper
+	state  filestorage.RawFileStorage
+
+	// opened holds the storage of the locations used so far.
+	opened map[string]storage.Storage
+}
+
+func newArchiveStorage(st DB, dbWrap *storageDBWrapper, state filestorage.RawFileStorage) filestorage.RawFileStorage {
+	return &archiveStorage{
+		st:     st,
+		dbWrap: dbWrap.Copy(),
+		state:  state,
+		opened: make(map[string]storage.Storage),
+	}
+}
+
+// open returns the storage of the archives kept at the given location.
+func (s *archiveStorage) open(location config.BackupsStorage) (storage.Storage, error) {
+	if stor, ok := s.opened[location.String()]; ok {
+		return stor, nil
+	}
+	stor, err := openLocation(s.st, location)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot open backups storage %q", location)
+	}
+	s.opened[location.String()] = stor
+	return stor, nil
+}
+
+// location returns where the identified archive is stored.
+func (s *archiveStorage) location(id string) (config.BackupsStorage, error) {
+	doc, err := getStorageMetadata(s.dbWrap, id)
+	if err != nil {
+		return config.BackupsStorage{}, errors.Trace(err)
+	}
+	location, err := config.ParseBackupsStorage(doc.Location)
+	return location, errors.Trace(err)
+}
+
+func (s *archiveStorage) path(id string) string {
+	// Archives share the storage of the provider with other files.
+	return path.Join(backupStorageRoot, id)
+}
+
+// File returns the identified file from storage.
+func (s *archiveStorage) File(id string) (io.ReadCloser, error) {
+	location, err := s.location(id)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if location.Type == config.BackupsStorageState {
+		return s.state.File(id)
+	}
+	stor, err := s.open(location)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	file, err := stor.Get(s.path(id))
+	return file, errors.Trace(err)
+}
+
+// AddFile adds the file to the storage the environment config says,
+// and records where it was stored.
+func (s *archiveStorage) AddFile(id string, file io.Reader, size int64) error {
+	cfg, err := s.st.EnvironConfig()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	location := cfg.BackupsStorage()
+	if location.Type == config.BackupsStorageState {
+		err = s.state.AddFile(id, file, size)
+	} else {
+		var stor storage.Storage
+		if stor, err = s.open(location); err == nil {
+			err = stor.Put(s.path(id), file, size)
+		}
+	}
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	op := s.dbWrap.txnOpUpdate(id, bson.DocElem{"location", location.String()})
+	if err := s.dbWrap.runTransaction([]txn.Op{op}); err != nil {
+		return errors.Annotate(err, "cannot record backup location")
+	}
+	return nil
+}
+
+// RemoveFile removes the identified file from storage.
+func (s *archiveStorage) RemoveFile(id string) error {
+	location, err := s.location(id)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if location.Type == config.BackupsStorageState {
+		return s.state.RemoveFile(id)
+	}
+	stor, err := s.open(location)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(stor.Remove(s.path(id)))
+}
+
+// Close closes the storage.
+func (s *archiveStorage) Close() error {
+	for _, stor := range s.opened {
+		if closer, ok := stor.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				logger.Warningf("cannot close backups storage: %v", err)
+			}
+		}
+	}
+	if err := s.state.Close(); err != nil {
+		return errors.Trace(err)
+	}
+	return s.dbWrap.Close()
+}
@@ -0,0 +1,81 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state/backups"
+)
+
+// archiveData matches the size of the metadata from s.metadata().
+var archiveData = strings.Repeat("a", 42)
+
+func (s *storageSuite) setBackupsStorage(c *gc.C, location string) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{"backups-storage": location}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *storageSuite) addArchive(c *gc.C) string {
+	stor := backups.NewStorage(s.State)
+	defer stor.Close()
+	id, err := stor.Add(s.metadata(c), strings.NewReader(archiveData))
+	c.Assert(err, jc.ErrorIsNil)
+	return id
+}
+
+func (s *storageSuite) getArchive(c *gc.C, id string) *backups.Metadata {
+	stor := backups.NewStorage(s.State)
+	defer stor.Close()
+	meta, file, err := stor.Get(id)
+	c.Assert(err, jc.ErrorIsNil)
+	defer file.Close()
+	data, err := ioutil.ReadAll(file)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(string(data), gc.Equals, archiveData)
+	return meta.(*backups.Metadata)
+}
+
+func (s *storageSuite) TestStorageState(c *gc.C) {
+	id := s.addArchive(c)
+	meta := s.getArchive(c, id)
+	c.Check(meta.Location, gc.Equals, "state")
+}
+
+func (s *storageSuite) TestStorageLocal(c *gc.C) {
+	dir := c.MkDir()
+	s.setBackupsStorage(c, "local:"+dir)
+	id := s.addArchive(c)
+
+	_, err := os.Stat(filepath.Join(dir, "backups", id))
+	c.Assert(err, jc.ErrorIsNil)
+	meta := s.getArchive(c, id)
+	c.Check(meta.Location, gc.Equals, "local:"+dir)
+
+	stor := backups.NewStorage(s.State)
+	defer stor.Close()
+	err = stor.Remove(id)
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = os.Stat(filepath.Join(dir, "backups", id))
+	c.Check(err, jc.Satisfies, os.IsNotExist)
+	_, err = stor.Metadata(id)
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *storageSuite) TestStorageChanged(c *gc.C) {
+	id := s.addArchive(c)
+	dir := c.MkDir()
+	s.setBackupsStorage(c, "local:"+dir)
+
+	// The archive stored before is still found where it was stored.
+	meta := s.getArchive(c, id)
+	c.Check(meta.Location, gc.Equals, "state")
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/juju/utils/filestorage"
+	"gopkg.in/mgo.v2/bson"
 
 	"github.com/juju/juju/version"
 )
@@ -62,6 +63,26 @@ type Metadata struct {
 	Origin Origin
 	// Notes is an optional user-supplied annotation.
 	Notes string
+	// Scheduled is set only on the backups created by the backup
+	// schedule, which are the only ones the schedule prunes.
+	Scheduled bool
+	// KeyFingerprint identifies the key the archive is encrypted
+	// with. It is empty if the archive is not encrypted.
+	KeyFingerprint string
+	// Location is where the archive is stored, as described by
+	// config.ParseBackupsStorage. It is set by storage, once the
+	// archive is stored.
+	Location string
+
+	// Base is the ID of the full backup an incremental backup builds
+	// on. It is empty for full backups.
+	Base string
+	// OplogStart and OplogEnd delimit the span of the oplog the backup
+	// covers. A full backup is consistent as of OplogEnd, which is
+	// where the next incremental backup on it starts. They are zero
+	// if the oplog could not be read when the backup was created.
+	OplogStart bson.MongoTimestamp
+	OplogEnd   bson.MongoTimestamp
 }
 
 // NewMetadata returns a new Metadata for a state backup archive.  Only
@@ -134,6 +155,12 @@ type flatMetadata struct {
 	Machine     string
 	Hostname    string
 	Version     version.Number
+
+	KeyFingerprint string `json:",omitempty"`
+
+	Base       string `json:",omitempty"`
+	OplogStart int64  `json:",omitempty"`
+	OplogEnd   int64  `json:",omitempty"`
 }
 
 // TODO(ericsnow) Move AsJSONBuffer to filestorage.Metadata.
@@ -153,6 +180,12 @@ func (m *Metadata) AsJSONBuffer() (io.Reader, error) {
 		Machine:     m.Origin.Machine,
 		Hostname:    m.Origin.Hostname,
 		Version:     m.Origin.Version,
+
+		KeyFingerprint: m.KeyFingerprint,
+
+		Base:       m.Base,
+		OplogStart: int64(m.OplogStart),
+		OplogEnd:   int64(m.OplogEnd),
 	}
 
 	stored := m.Stored()
@@ -195,6 +228,10 @@ func NewMetadataJSONReader(in io.Reader) (*Metadata, error) {
 		meta.Finished = &flat.Finished
 	}
 	meta.Notes = flat.Notes
+	meta.KeyFingerprint = flat.KeyFingerprint
+	meta.Base = flat.Base
+	meta.OplogStart = bson.MongoTimestamp(flat.OplogStart)
+	meta.OplogEnd = bson.MongoTimestamp(flat.OplogEnd)
 	meta.Origin = Origin{
 		Environment: flat.Environment,
 		Machine:     flat.Machine,
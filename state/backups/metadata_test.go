@@ -13,6 +13,7 @@ import (
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/state/backups"
+	backupstesting "github.com/juju/juju/state/backups/testing"
 	"github.com/juju/juju/testing"
 	"github.com/juju/juju/version"
 )
@@ -89,6 +90,37 @@ func (s *metadataSuite) TestNewMetadataJSONReader(c *gc.C) {
 	c.Check(meta.Origin.Machine, gc.Equals, "0")
 	c.Check(meta.Origin.Hostname, gc.Equals, "myhost")
 	c.Check(meta.Origin.Version.String(), gc.Equals, "1.21-alpha3")
+	c.Check(meta.KeyFingerprint, gc.Equals, "")
+}
+
+func (s *metadataSuite) TestAsJSONBufferEncrypted(c *gc.C) {
+	meta := backupstesting.NewMetadataStarted()
+	meta.KeyFingerprint = "passphrase"
+
+	buf, err := meta.AsJSONBuffer()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(buf.(*bytes.Buffer).String(), jc.Contains, `"KeyFingerprint":"passphrase"`)
+
+	meta, err = backups.NewMetadataJSONReader(buf)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(meta.KeyFingerprint, gc.Equals, "passphrase")
+}
+
+func (s *metadataSuite) TestAsJSONBufferIncremental(c *gc.C) {
+	meta := backupstesting.NewMetadataStarted()
+	meta.Base = "20140909-115934.asdf-zxcv-qwe"
+	meta.OplogStart = 6163845091342417920
+	meta.OplogEnd = 6163845091342417923
+
+	buf, err := meta.AsJSONBuffer()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(buf.(*bytes.Buffer).String(), jc.Contains, `"Base":"20140909-115934.asdf-zxcv-qwe"`)
+
+	meta, err = backups.NewMetadataJSONReader(buf)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(meta.Base, gc.Equals, "20140909-115934.asdf-zxcv-qwe")
+	c.Check(int64(meta.OplogStart), gc.Equals, int64(6163845091342417920))
+	c.Check(int64(meta.OplogEnd), gc.Equals, int64(6163845091342417923))
 }
 
 func (s *metadataSuite) TestBuildMetadata(c *gc.C) {
@@ -4,6 +4,8 @@
 package backups
 
 import (
+	"time"
+
 	"github.com/juju/names"
 
 	"github.com/juju/juju/instance"
@@ -16,4 +18,8 @@ type RestoreArgs struct {
 	NewInstId      instance.Id
 	NewInstTag     names.Tag
 	NewInstSeries  string
+	// Until is the point in time up to which the oplog of the backup
+	// and of the incremental backups built on it is replayed. The
+	// whole oplog of the backup is replayed if it is zero.
+	Until time.Time
 }
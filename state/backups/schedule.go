@@ -0,0 +1,222 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/juju/errors"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+	"gopkg.in/mgo.v2/txn"
+)
+
+// Backups may be created automatically at a regular interval. The
+// retention policy of the schedule keeps the last scheduled backup of
+// each of the last few days and weeks, and prunes the others. Backups
+// created on demand are never pruned.
+
+const (
+	// ScheduledNotes are the notes of the backups created by the
+	// schedule. Scheduled backups are told apart by
+	// Metadata.Scheduled, not by their notes.
+	ScheduledNotes = "scheduled backup"
+
+	// MinScheduleInterval is the shortest interval between scheduled
+	// backups.
+	MinScheduleInterval = time.Hour
+
+	storageScheduleName = "schedule"
+)
+
+// Schedule describes when backups are created automatically and which
+// of them are kept.
+type Schedule struct {
+	// Interval is the time between scheduled backups. Zero disables
+	// scheduled backups.
+	Interval time.Duration
+	// KeepDaily is the number of days for which the last scheduled
+	// backup of the day is kept.
+	KeepDaily int
+	// KeepWeekly is the number of weeks for which the last scheduled
+	// backup of the week is kept.
+	KeepWeekly int
+	// PublicKey is the armored OpenPGP public key scheduled backups
+	// are encrypted with, if any.
+	PublicKey string
+}
+
+// Validate returns an error if the schedule is not valid.
+func (s Schedule) Validate() error {
+	if s.Interval < 0 {
+		return errors.NotValidf("negative interval %v", s.Interval)
+	}
+	if s.Interval > 0 && s.Interval < MinScheduleInterval {
+		return errors.NotValidf("interval %v shorter than %v", s.Interval, MinScheduleInterval)
+	}
+	if s.KeepDaily < 0 {
+		return errors.NotValidf("negative number of daily backups %d", s.KeepDaily)
+	}
+	if s.KeepWeekly < 0 {
+		return errors.NotValidf("negative number of weekly backups %d", s.KeepWeekly)
+	}
+	if key := s.Key(); key != nil {
+		if _, err := key.Fingerprint(); err != nil {
+			return errors.Annotate(err, "invalid public key")
+		}
+	}
+	return nil
+}
+
+// Key returns the key scheduled backups are encrypted with, or nil.
+func (s Schedule) Key() *EncryptionKey {
+	if s.PublicKey == "" {
+		return nil
+	}
+	return &EncryptionKey{PublicKey: s.PublicKey}
+}
+
+// scheduled returns the complete scheduled backups in list, newest
+// first.
+func scheduled(list []*Metadata) []*Metadata {
+	var result []*Metadata
+	for _, meta := range list {
+		if meta.Scheduled && meta.Finished != nil {
+			result = append(result, meta)
+		}
+	}
+	sort.Sort(byStartedDesc(result))
+	return result
+}
+
+type byStartedDesc []*Metadata
+
+func (b byStartedDesc) Len() int           { return len(b) }
+func (b byStartedDesc) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b byStartedDesc) Less(i, j int) bool { return b[i].Started.After(b[j].Started) }
+
+// Due reports whether a scheduled backup is due at the given time,
+// given the backups in list.
+func (s Schedule) Due(list []*Metadata, now time.Time) bool {
+	if s.Interval == 0 {
+		return false
+	}
+	all := scheduled(list)
+	return len(all) == 0 || now.Sub(all[0].Started) >= s.Interval
+}
+
+// Expired returns the scheduled backups in list that are not kept by
+// the retention policy. The last scheduled backup is always kept, as
+// are the backups incremental backups build on, and no backup expires
+// if the policy keeps neither daily nor weekly backups.
+func (s Schedule) Expired(list []*Metadata) []*Metadata {
+	if s.KeepDaily == 0 && s.KeepWeekly == 0 {
+		return nil
+	}
+	bases := make(map[string]bool)
+	for _, meta := range list {
+		if meta.Base != "" {
+			bases[meta.Base] = true
+		}
+	}
+	all := scheduled(list)
+	days := make(map[string]bool)
+	weeks := make(map[string]bool)
+	var expired []*Metadata
+	for i, meta := range all {
+		started := meta.Started.UTC()
+		keep := i == 0 || bases[meta.ID()]
+
+		day := started.Format("2006-01-02")
+		if !days[day] && len(days) < s.KeepDaily {
+			days[day] = true
+			keep = true
+		}
+		year, week := started.ISOWeek()
+		yearWeek := fmt.Sprintf("%d-%02d", year, week)
+		if !weeks[yearWeek] && len(weeks) < s.KeepWeekly {
+			weeks[yearWeek] = true
+			keep = true
+		}
+
+		if !keep {
+			expired = append(expired, meta)
+		}
+	}
+	return expired
+}
+
+// scheduleDoc is the document holding the backup schedule of an
+// environment.
+type scheduleDoc struct {
+	EnvUUID    string `bson:"_id"`
+	Interval   int64  `bson:"interval"`
+	KeepDaily  int    `bson:"keepdaily"`
+	KeepWeekly int    `bson:"keepweekly"`
+	PublicKey  string `bson:"publickey,omitempty"`
+}
+
+func newScheduleDB(st DB) *storageDBWrapper {
+	db := st.MongoSession().DB(storageDBName)
+	return newStorageDBWrapper(db, storageScheduleName, st.EnvironTag().Id())
+}
+
+// GetSchedule returns the backup schedule of the environment. Backups
+// are not scheduled until a schedule is set.
+func GetSchedule(st DB) (Schedule, error) {
+	dbWrap := newScheduleDB(st)
+	defer dbWrap.Close()
+
+	var doc scheduleDoc
+	err := dbWrap.metaColl.FindId(dbWrap.envUUID).One(&doc)
+	if err == mgo.ErrNotFound {
+		return Schedule{}, nil
+	} else if err != nil {
+		return Schedule{}, errors.Annotate(err, "cannot get backup schedule")
+	}
+	return Schedule{
+		Interval:   time.Duration(doc.Interval),
+		KeepDaily:  doc.KeepDaily,
+		KeepWeekly: doc.KeepWeekly,
+		PublicKey:  doc.PublicKey,
+	}, nil
+}
+
+// SetSchedule replaces the backup schedule of the environment.
+func SetSchedule(st DB, schedule Schedule) error {
+	if err := schedule.Validate(); err != nil {
+		return errors.Trace(err)
+	}
+	dbWrap := newScheduleDB(st)
+	defer dbWrap.Close()
+
+	doc := scheduleDoc{
+		EnvUUID:    dbWrap.envUUID,
+		Interval:   int64(schedule.Interval),
+		KeepDaily:  schedule.KeepDaily,
+		KeepWeekly: schedule.KeepWeekly,
+		PublicKey:  schedule.PublicKey,
+	}
+	buildTxn := func(attempt int) ([]txn.Op, error) {
+		count, err := dbWrap.metaColl.FindId(doc.EnvUUID).Count()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if count == 0 {
+			return []txn.Op{dbWrap.txnOpInsert(doc.EnvUUID, &doc)}, nil
+		}
+		return []txn.Op{dbWrap.txnOpUpdate(doc.EnvUUID,
+			bson.DocElem{"interval", doc.Interval},
+			bson.DocElem{"keepdaily", doc.KeepDaily},
+			bson.DocElem{"keepweekly", doc.KeepWeekly},
+			bson.DocElem{"publickey", doc.PublicKey},
+		)}, nil
+	}
+	if err := dbWrap.txnRunner.Run(buildTxn); err != nil {
+		return errors.Annotate(err, "cannot set backup schedule")
+	}
+	return nil
+}
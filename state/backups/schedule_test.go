@@ -0,0 +1,185 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state/backups"
+	backupstesting "github.com/juju/juju/state/backups/testing"
+	"github.com/juju/juju/testing"
+)
+
+type scheduleSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&scheduleSuite{})
+
+// scheduledBackup returns the metadata of a complete scheduled backup
+// started at the given time.
+func scheduledBackup(id string, started time.Time) *backups.Metadata {
+	meta := backupstesting.NewMetadataStarted()
+	meta.SetID(id)
+	meta.Started = started
+	meta.Notes = backups.ScheduledNotes
+	meta.Scheduled = true
+	backupstesting.FinishMetadata(meta)
+	return meta
+}
+
+func ids(list []*backups.Metadata) []string {
+	var result []string
+	for _, meta := range list {
+		result = append(result, meta.ID())
+	}
+	return result
+}
+
+func (s *scheduleSuite) TestValidate(c *gc.C) {
+	for i, test := range []struct {
+		schedule backups.Schedule
+		err      string
+	}{{
+		schedule: backups.Schedule{},
+	}, {
+		schedule: backups.Schedule{Interval: 24 * time.Hour, KeepDaily: 7, KeepWeekly: 4},
+	}, {
+		schedule: backups.Schedule{Interval: -time.Hour},
+		err:      "negative interval -1h0m0s not valid",
+	}, {
+		schedule: backups.Schedule{Interval: time.Minute},
+		err:      "interval 1m0s shorter than 1h0m0s not valid",
+	}, {
+		schedule: backups.Schedule{KeepDaily: -1},
+		err:      "negative number of daily backups -1 not valid",
+	}, {
+		schedule: backups.Schedule{KeepWeekly: -1},
+		err:      "negative number of weekly backups -1 not valid",
+	}, {
+		schedule: backups.Schedule{PublicKey: "<not a key>"},
+		err:      "invalid public key: .*",
+	}} {
+		c.Logf("test %d", i)
+		err := test.schedule.Validate()
+		if test.err == "" {
+			c.Check(err, jc.ErrorIsNil)
+		} else {
+			c.Check(err, gc.ErrorMatches, test.err)
+		}
+	}
+}
+
+func (s *scheduleSuite) TestDue(c *gc.C) {
+	now := time.Date(2015, time.June, 10, 12, 0, 0, 0, time.UTC)
+	schedule := backups.Schedule{Interval: 6 * time.Hour}
+	c.Check(schedule.Due(nil, now), jc.IsTrue)
+
+	onDemand := backupstesting.NewMetadataStarted()
+	onDemand.Started = now.Add(-time.Hour)
+	backupstesting.FinishMetadata(onDemand)
+	c.Check(schedule.Due([]*backups.Metadata{onDemand}, now), jc.IsTrue)
+
+	recent := scheduledBackup("recent", now.Add(-5*time.Hour))
+	c.Check(schedule.Due([]*backups.Metadata{recent, onDemand}, now), jc.IsFalse)
+
+	old := scheduledBackup("old", now.Add(-6*time.Hour))
+	c.Check(schedule.Due([]*backups.Metadata{old, onDemand}, now), jc.IsTrue)
+
+	c.Check(backups.Schedule{}.Due(nil, now), jc.IsFalse)
+}
+
+func (s *scheduleSuite) TestExpired(c *gc.C) {
+	// 2015-06-10 is a Wednesday.
+	day := func(d, h int) time.Time {
+		return time.Date(2015, time.June, d, h, 0, 0, 0, time.UTC)
+	}
+	onDemand := backupstesting.NewMetadataStarted()
+	onDemand.SetID("on-demand")
+	onDemand.Started = day(1, 0)
+	backupstesting.FinishMetadata(onDemand)
+	// Backups with the notes of scheduled backups are not pruned.
+	notScheduled := backupstesting.NewMetadataStarted()
+	notScheduled.SetID("not-scheduled")
+	notScheduled.Started = day(2, 12)
+	notScheduled.Notes = backups.ScheduledNotes
+	backupstesting.FinishMetadata(notScheduled)
+	list := []*backups.Metadata{
+		onDemand,
+		notScheduled,
+		scheduledBackup("10-12", day(10, 12)),
+		scheduledBackup("10-00", day(10, 0)),
+		scheduledBackup("09-12", day(9, 12)),
+		scheduledBackup("09-00", day(9, 0)),
+		scheduledBackup("08-00", day(8, 0)),
+		scheduledBackup("07-12", day(7, 12)),
+		scheduledBackup("07-00", day(7, 0)),
+		scheduledBackup("03-00", day(3, 0)),
+		scheduledBackup("02-00", day(2, 0)),
+	}
+
+	schedule := backups.Schedule{KeepDaily: 2, KeepWeekly: 2}
+	c.Check(ids(schedule.Expired(list)), jc.DeepEquals, []string{
+		"10-00", "09-00", "08-00", "07-00", "03-00", "02-00",
+	})
+
+	schedule = backups.Schedule{KeepWeekly: 1}
+	c.Check(ids(schedule.Expired(list)), jc.DeepEquals, []string{
+		"10-00", "09-12", "09-00", "08-00", "07-12", "07-00", "03-00", "02-00",
+	})
+
+	c.Check(backups.Schedule{}.Expired(list), gc.HasLen, 0)
+}
+
+func (s *scheduleSuite) TestExpiredKeepsIncrementBases(c *gc.C) {
+	day := func(d int) time.Time {
+		return time.Date(2015, time.June, d, 0, 0, 0, 0, time.UTC)
+	}
+	inc := backupstesting.NewMetadataStarted()
+	inc.SetID("inc")
+	inc.Started = day(9)
+	inc.Base = "08"
+	backupstesting.FinishMetadata(inc)
+	list := []*backups.Metadata{
+		inc,
+		scheduledBackup("10", day(10)),
+		scheduledBackup("09", day(9)),
+		scheduledBackup("08", day(8)),
+		scheduledBackup("07", day(7)),
+	}
+
+	schedule := backups.Schedule{KeepDaily: 1}
+	c.Check(ids(schedule.Expired(list)), jc.DeepEquals, []string{"09", "07"})
+}
+
+func (s *storageSuite) TestScheduleNotSet(c *gc.C) {
+	schedule, err := backups.GetSchedule(s.State)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(schedule, jc.DeepEquals, backups.Schedule{})
+}
+
+func (s *storageSuite) TestSetSchedule(c *gc.C) {
+	expected := backups.Schedule{Interval: 24 * time.Hour, KeepDaily: 7, KeepWeekly: 4}
+	err := backups.SetSchedule(s.State, expected)
+	c.Assert(err, jc.ErrorIsNil)
+	schedule, err := backups.GetSchedule(s.State)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(schedule, jc.DeepEquals, expected)
+
+	expected = backups.Schedule{Interval: 12 * time.Hour, KeepDaily: 3}
+	err = backups.SetSchedule(s.State, expected)
+	c.Assert(err, jc.ErrorIsNil)
+	schedule, err = backups.GetSchedule(s.State)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(schedule, jc.DeepEquals, expected)
+}
+
+func (s *storageSuite) TestSetScheduleInvalid(c *gc.C) {
+	err := backups.SetSchedule(s.State, backups.Schedule{Interval: time.Minute})
+	c.Check(err, jc.Satisfies, errors.IsNotValid)
+}
@@ -17,6 +17,7 @@ import (
 	"gopkg.in/mgo.v2/bson"
 	"gopkg.in/mgo.v2/txn"
 
+	"github.com/juju/juju/environs/config"
 	"github.com/juju/juju/version"
 )
 
@@ -45,6 +46,23 @@ type storageMetaDoc struct {
 	Finished int64  `bson:"finished,minsize"`
 	Notes    string `bson:"notes,omitempty"`
 
+	Scheduled bool `bson:"scheduled,omitempty"`
+
+	// encryption
+
+	KeyFingerprint string `bson:"keyfingerprint,omitempty"`
+
+	// Location is where the archive is stored, as described by
+	// config.ParseBackupsStorage. It is empty for archives stored in
+	// state before the location was recorded.
+	Location string `bson:"location,omitempty"`
+
+	// incremental backups
+
+	Base       string `bson:"base,omitempty"`
+	OplogStart int64  `bson:"oplogstart,omitempty"`
+	OplogEnd   int64  `bson:"oplogend,omitempty"`
+
 	// origin
 
 	Environment string         `bson:"environment"`
@@ -120,6 +138,11 @@ func docAsMetadata(doc *storageMetaDoc) *Metadata {
 	meta := NewMetadata()
 	meta.Started = metadocUnixToTime(doc.Started)
 	meta.Notes = doc.Notes
+	meta.Scheduled = doc.Scheduled
+	meta.KeyFingerprint = doc.KeyFingerprint
+	meta.Base = doc.Base
+	meta.OplogStart = bson.MongoTimestamp(doc.OplogStart)
+	meta.OplogEnd = bson.MongoTimestamp(doc.OplogEnd)
 
 	meta.Origin.Environment = doc.Environment
 	meta.Origin.Machine = doc.Machine
@@ -145,6 +168,10 @@ func docAsMetadata(doc *storageMetaDoc) *Metadata {
 	if doc.Stored != 0 {
 		stored := metadocUnixToTime(doc.Stored)
 		meta.SetStored(&stored)
+		meta.Location = doc.Location
+		if meta.Location == "" {
+			meta.Location = config.BackupsStorageState
+		}
 	}
 
 	return meta
@@ -170,6 +197,11 @@ func newStorageMetaDoc(meta *Metadata) storageMetaDoc {
 		doc.Finished = metadocTimeToUnix(*meta.Finished)
 	}
 	doc.Notes = meta.Notes
+	doc.Scheduled = meta.Scheduled
+	doc.KeyFingerprint = meta.KeyFingerprint
+	doc.Base = meta.Base
+	doc.OplogStart = int64(meta.OplogStart)
+	doc.OplogEnd = int64(meta.OplogEnd)
 
 	doc.Environment = meta.Origin.Environment
 	doc.Machine = meta.Origin.Machine
@@ -531,17 +563,22 @@ type DB interface {
 
 	// EnvironTag is the concrete environ tag for this database.
 	EnvironTag() names.EnvironTag
+
+	// EnvironConfig returns the environment config, which says where
+	// backup archives are stored.
+	EnvironConfig() (*config.Config, error)
 }
 
 // NewStorage returns a new FileStorage to use for storing backup
-// archives (and metadata).
+// archives (and metadata). The metadata is stored in state, and the
+// archives where the backups-storage setting of the environment says.
 func NewStorage(st DB) filestorage.FileStorage {
 	envUUID := st.EnvironTag().Id()
 	db := st.MongoSession().DB(storageDBName)
 	dbWrap := newStorageDBWrapper(db, storageMetaName, envUUID)
 	defer dbWrap.Close()
 
-	files := newFileStorage(dbWrap, backupStorageRoot)
+	files := newArchiveStorage(st, dbWrap, newFileStorage(dbWrap, backupStorageRoot))
 	docs := newMetadataStorage(dbWrap)
 	return filestorage.NewFileStorage(docs, files)
 }
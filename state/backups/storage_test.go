@@ -66,6 +66,11 @@ func (s *storageSuite) checkMeta(c *gc.C, meta, expected *backups.Metadata, id s
 		c.Check(meta.ID(), gc.Equals, id)
 	}
 	c.Check(meta.Notes, gc.Equals, expected.Notes)
+	c.Check(meta.Scheduled, gc.Equals, expected.Scheduled)
+	c.Check(meta.KeyFingerprint, gc.Equals, expected.KeyFingerprint)
+	c.Check(meta.Base, gc.Equals, expected.Base)
+	c.Check(meta.OplogStart, gc.Equals, expected.OplogStart)
+	c.Check(meta.OplogEnd, gc.Equals, expected.OplogEnd)
 	c.Check(meta.Started.Unix(), gc.Equals, expected.Started.Unix())
 	c.Check(meta.Checksum(), gc.Equals, expected.Checksum())
 	c.Check(meta.ChecksumFormat(), gc.Equals, expected.ChecksumFormat())
@@ -118,6 +123,44 @@ func (s *storageSuite) TestAddBackupMetadataSuccess(c *gc.C) {
 	s.checkMeta(c, meta, original, id)
 }
 
+func (s *storageSuite) TestAddBackupMetadataEncrypted(c *gc.C) {
+	original := s.metadata(c)
+	original.KeyFingerprint = "passphrase"
+	id, err := backups.AddBackupMetadata(s.State, original)
+	c.Assert(err, jc.ErrorIsNil)
+
+	meta, err := backups.GetBackupMetadata(s.State, id)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.checkMeta(c, meta, original, id)
+}
+
+func (s *storageSuite) TestAddBackupMetadataScheduled(c *gc.C) {
+	original := s.metadata(c)
+	original.Scheduled = true
+	id, err := backups.AddBackupMetadata(s.State, original)
+	c.Assert(err, jc.ErrorIsNil)
+
+	meta, err := backups.GetBackupMetadata(s.State, id)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.checkMeta(c, meta, original, id)
+}
+
+func (s *storageSuite) TestAddBackupMetadataIncremental(c *gc.C) {
+	original := s.metadata(c)
+	original.Base = "20140909-115934.spam"
+	original.OplogStart = 6163845091342417920
+	original.OplogEnd = 6163845091342417923
+	id, err := backups.AddBackupMetadata(s.State, original)
+	c.Assert(err, jc.ErrorIsNil)
+
+	meta, err := backups.GetBackupMetadata(s.State, id)
+	c.Assert(err, jc.ErrorIsNil)
+
+	s.checkMeta(c, meta, original, id)
+}
+
 func (s *storageSuite) TestAddBackupMetadataGeneratedID(c *gc.C) {
 	original := s.metadata(c)
 	original.SetID("spam")
@@ -5,6 +5,7 @@ package testing
 
 import (
 	"io"
+	"time"
 
 	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
@@ -37,6 +38,13 @@ type FakeBackups struct {
 	DBInfoArg *backups.DBInfo
 	// MetaArg holds the backup metadata that was passed in.
 	MetaArg *backups.Metadata
+	// KeyArg holds the encryption key that was passed in.
+	KeyArg *backups.EncryptionKey
+	// BaseArg holds the base of the incremental backup that was
+	// created.
+	BaseArg string
+	// UntilArg holds the point in time restore was requested up to.
+	UntilArg time.Time
 	// PrivateAddr Holds the address for the internal network of the machine.
 	PrivateAddr string
 	// InstanceId Is the id of the machine to be restored.
@@ -49,12 +57,14 @@ var _ backups.Backups = (*FakeBackups)(nil)
 
 // Create creates and stores a new juju backup archive and returns
 // its associated metadata.
-func (b *FakeBackups) Create(meta *backups.Metadata, paths *backups.Paths, dbInfo *backups.DBInfo) error {
+func (b *FakeBackups) Create(meta *backups.Metadata, paths *backups.Paths, dbInfo *backups.DBInfo, key *backups.EncryptionKey) error {
 	b.Calls = append(b.Calls, "Create")
 
 	b.PathsArg = paths
 	b.DBInfoArg = dbInfo
 	b.MetaArg = meta
+	b.KeyArg = key
+	b.BaseArg = meta.Base
 
 	if b.Meta != nil {
 		*meta = *b.Meta
@@ -100,6 +110,8 @@ func (b *FakeBackups) Restore(bkpId string, args backups.RestoreArgs) error {
 	b.Calls = append(b.Calls, "Restore")
 	b.PrivateAddr = args.PrivateAddress
 	b.InstanceId = args.NewInstId
+	b.KeyArg = args.Key
+	b.UntilArg = args.Until
 	return errors.Trace(b.Error)
 }
 
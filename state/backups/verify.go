@@ -0,0 +1,380 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/set"
+	utilstar "github.com/juju/utils/tar"
+	"gopkg.in/mgo.v2"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/cert"
+	"github.com/juju/juju/mongo"
+	"github.com/juju/juju/version"
+)
+
+// VerifyResult describes a backup archive that was found usable.
+type VerifyResult struct {
+	// Version is the version of juju that created the backup.
+	Version version.Number
+
+	// Databases holds the names of the databases in the DB dump.
+	Databases []string
+
+	// AgentConfigs holds the paths of the agent config files in the
+	// files bundle, all of which parse.
+	AgentConfigs []string
+}
+
+// CollectionCount holds the number of documents in a collection
+// restored from a DB dump.
+type CollectionCount struct {
+	Database   string
+	Collection string
+	Count      int
+}
+
+// NewVerifiedArchiveData returns the archive data read from the archive
+// stored with the given metadata, once it is found to match the size
+// and checksum in the metadata. Encrypted archives are decrypted with
+// the key, which must match the fingerprint in the metadata.
+func NewVerifiedArchiveData(archive io.Reader, meta *Metadata, key *EncryptionKey) (*ArchiveData, error) {
+	data, err := ioutil.ReadAll(archive)
+	if err != nil {
+		return nil, errors.Annotate(err, "while reading archive")
+	}
+	if size := int64(len(data)); size != meta.Size() {
+		return nil, errors.Errorf("archive is %d bytes, expected %d", size, meta.Size())
+	}
+	sum := sha1.Sum(data)
+	if checksum := base64.StdEncoding.EncodeToString(sum[:]); checksum != meta.Checksum() {
+		return nil, errors.Errorf("archive checksum %q does not match %q", checksum, meta.Checksum())
+	}
+
+	plain, err := DecryptArchive(bytes.NewReader(data), meta.KeyFingerprint, key)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	ad, err := NewArchiveDataReader(plain)
+	if err != nil {
+		return nil, errors.Annotate(err, "while unpacking archive")
+	}
+	return ad, nil
+}
+
+// archiveContents holds what VerifyArchiveData finds in an archive.
+type archiveContents struct {
+	databases   []string
+	oplog       bool
+	filesBundle []byte
+}
+
+func readArchiveContents(ad *ArchiveData) (*archiveContents, error) {
+	var contents archiveContents
+	databases := set.NewStrings()
+	dumpDir := ad.DBDumpDir + "/"
+	tarReader := tar.NewReader(ad.NewBuffer())
+	for {
+		hdr, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		name := strings.TrimSuffix(path.Clean(hdr.Name), "/")
+		switch {
+		case name == ad.FilesBundle:
+			contents.filesBundle, err = ioutil.ReadAll(tarReader)
+			if err != nil {
+				return nil, errors.Annotate(err, "while reading files bundle")
+			}
+		case name == path.Join(ad.DBDumpDir, oplogFilename):
+			contents.oplog = true
+		case strings.HasPrefix(name, dumpDir):
+			// Each database is dumped into a directory of its own.
+			rel := strings.TrimPrefix(name, dumpDir)
+			if i := strings.Index(rel, "/"); i >= 0 {
+				databases.Add(rel[:i])
+			} else if hdr.Typeflag == tar.TypeDir {
+				databases.Add(rel)
+			}
+		}
+	}
+	contents.databases = databases.SortedValues()
+	return &contents, nil
+}
+
+// VerifyArchiveData checks that the archive data holds a usable backup
+// with the given metadata, without restoring it. The metadata in the
+// archive must agree with it, the DB dump must be there, and the agent
+// config files and certificates in the files bundle must parse.
+func VerifyArchiveData(ad *ArchiveData, meta *Metadata) (*VerifyResult, error) {
+	ver, err := ad.Version()
+	if err != nil {
+		return nil, errors.Annotate(err, "while reading archive metadata")
+	}
+	if *ver != meta.Origin.Version {
+		return nil, errors.Errorf("archive was created by juju %s, not %s", ver, meta.Origin.Version)
+	}
+	archiveMeta, err := ad.Metadata()
+	if err != nil && !errors.IsNotFound(err) {
+		return nil, errors.Annotate(err, "while reading archive metadata")
+	}
+	if err == nil && archiveMeta.Origin.Environment != meta.Origin.Environment {
+		return nil, errors.Errorf("archive is a backup of environment %q, not %q", archiveMeta.Origin.Environment, meta.Origin.Environment)
+	}
+
+	contents, err := readArchiveContents(ad)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if meta.Base != "" {
+		if !contents.oplog {
+			return nil, errors.New("incremental backup has no oplog in its DB dump")
+		}
+	} else if len(contents.databases) == 0 {
+		return nil, errors.New("DB dump is empty or missing")
+	}
+	if contents.filesBundle == nil {
+		return nil, errors.New("files bundle is missing")
+	}
+
+	agentConfigs, err := verifyFilesBundle(contents.filesBundle)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	result := VerifyResult{
+		Version:      *ver,
+		Databases:    contents.databases,
+		AgentConfigs: agentConfigs,
+	}
+	return &result, nil
+}
+
+// verifyFilesBundle checks that the agent config files and the mongo
+// certificate in the files bundle parse. It returns the paths of the
+// agent config files.
+func verifyFilesBundle(bundle []byte) ([]string, error) {
+	// The files bundle holds the files by their absolute paths, with
+	// the leading slash stripped.
+	agentConfGlob := path.Join(dataDir[1:], agentsDir, agentsConfs, agentConf)
+	dbPEMFile := path.Join(dataDir[1:], dbPEM)
+
+	var agentConfigs []string
+	var sawDBPEM bool
+	tarReader := tar.NewReader(bytes.NewReader(bundle))
+	for {
+		hdr, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.Annotate(err, "while reading files bundle")
+		}
+		name := path.Clean(hdr.Name)
+		if matched, _ := path.Match(agentConfGlob, name); matched {
+			data, err := ioutil.ReadAll(tarReader)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if err := verifyAgentConfig(data); err != nil {
+				return nil, errors.Annotatef(err, "invalid agent config %q", name)
+			}
+			agentConfigs = append(agentConfigs, name)
+		} else if name == dbPEMFile {
+			data, err := ioutil.ReadAll(tarReader)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			// The file holds both the certificate and its key.
+			if _, _, err := cert.ParseCertAndKey(string(data), string(data)); err != nil {
+				return nil, errors.Annotatef(err, "invalid mongo certificate %q", name)
+			}
+			sawDBPEM = true
+		}
+	}
+	if len(agentConfigs) == 0 {
+		return nil, errors.New("files bundle holds no agent config")
+	}
+	if !sawDBPEM {
+		return nil, errors.New("files bundle holds no mongo certificate")
+	}
+	return agentConfigs, nil
+}
+
+// verifyAgentConfig checks that the agent config parses, along with
+// the certificates in it.
+func verifyAgentConfig(data []byte) error {
+	// Agent configs are only read from disk.
+	dir, err := ioutil.TempDir("", "juju-backups-verify-")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, agentConf)
+	if err := ioutil.WriteFile(filename, data, 0600); err != nil {
+		return errors.Trace(err)
+	}
+
+	conf, err := agent.ReadConfig(filename)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if _, err := cert.ParseCert(conf.CACert()); err != nil {
+		return errors.Annotate(err, "invalid CA certificate")
+	}
+	if info, ok := conf.StateServingInfo(); ok {
+		if _, _, err := cert.ParseCertAndKey(info.Cert, info.PrivateKey); err != nil {
+			return errors.Annotate(err, "invalid state server certificate")
+		}
+	}
+	return nil
+}
+
+// RestoreDumpCounts restores the DB dump in the archive data into a
+// throwaway mongod, which must be installed locally, and returns the
+// number of documents in each collection restored. The dump must be
+// that of a full backup created by juju of the given version.
+func RestoreDumpCounts(ad *ArchiveData, ver version.Number) ([]CollectionCount, error) {
+	dir, err := ioutil.TempDir("", "juju-backups-verify-")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := utilstar.UntarFiles(ad.NewBuffer(), dir); err != nil {
+		return nil, errors.Annotate(err, "while unpacking archive")
+	}
+	dumpDir := NewNonCanonicalArchivePaths(dir).DBDumpDir
+	dbDir := filepath.Join(dir, "db")
+	if err := os.Mkdir(dbDir, 0700); err != nil {
+		return nil, errors.Trace(err)
+	}
+	counts, err := restoreDumpLocally(dumpDir, dbDir, ver)
+	return counts, errors.Trace(err)
+}
+
+var restoreDumpLocally = restoreThrowawayMongo
+
+// throwawayMongoTimeout is how long to wait for the throwaway mongod
+// to start.
+const throwawayMongoTimeout = time.Minute
+
+// restoreThrowawayMongo starts a mongod on dbDir, listening on a free
+// local port, restores the DB dump into it and counts the documents
+// restored. The mongod is stopped when done.
+func restoreThrowawayMongo(dumpDir, dbDir string, ver version.Number) ([]CollectionCount, error) {
+	mongod, err := mongo.Path()
+	if err != nil {
+		return nil, errors.Annotate(err, "mongod not available")
+	}
+	mongoRestore, err := restorePath()
+	if err != nil {
+		return nil, errors.Annotate(err, "mongorestore not available")
+	}
+	port, err := freeLocalPort()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+
+	server := exec.Command(mongod,
+		"--dbpath", dbDir,
+		"--port", strconv.Itoa(port),
+		"--bind_ip", "127.0.0.1",
+		"--nohttpinterface",
+		"--noprealloc",
+		"--smallfiles",
+	)
+	if err := server.Start(); err != nil {
+		return nil, errors.Annotate(err, "failed to start mongod")
+	}
+	defer func() {
+		server.Process.Kill()
+		server.Wait()
+	}()
+
+	session, err := mgo.DialWithTimeout(addr, throwawayMongoTimeout)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot connect to mongod")
+	}
+	defer session.Close()
+
+	args := []string{"--host", addr}
+	if ver.Major == 1 && ver.Minor >= 22 {
+		args = append(args, "--oplogReplay")
+	}
+	args = append(args, dumpDir)
+	if err := runCommand(mongoRestore, args...); err != nil {
+		return nil, errors.Annotate(err, "failed to restore database dump")
+	}
+
+	counts, err := countDocuments(session)
+	return counts, errors.Trace(err)
+}
+
+// freeLocalPort returns a TCP port on the loopback interface that is
+// not in use.
+func freeLocalPort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// countDocuments returns the number of documents in each collection of
+// the databases in the session, leaving out the local database and the
+// system collections.
+func countDocuments(session *mgo.Session) ([]CollectionCount, error) {
+	dbNames, err := session.DatabaseNames()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var counts []CollectionCount
+	for _, dbName := range dbNames {
+		if dbName == "local" {
+			continue
+		}
+		db := session.DB(dbName)
+		collNames, err := db.CollectionNames()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for _, collName := range collNames {
+			if strings.HasPrefix(collName, "system.") {
+				continue
+			}
+			count, err := db.C(collName).Count()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			counts = append(counts, CollectionCount{
+				Database:   dbName,
+				Collection: collName,
+				Count:      count,
+			})
+		}
+	}
+	return counts, nil
+}
@@ -0,0 +1,276 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backups_test
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/juju/names"
+	gitjujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/agent"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/state/backups"
+	bt "github.com/juju/juju/state/backups/testing"
+	"github.com/juju/juju/testing"
+	"github.com/juju/juju/version"
+)
+
+type verifySuite struct {
+	testing.BaseSuite
+	meta *backups.Metadata
+}
+
+var _ = gc.Suite(&verifySuite{})
+
+func (s *verifySuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.meta = bt.NewMetadataStarted()
+}
+
+// agentConfig returns the content of a valid state server agent config.
+func (s *verifySuite) agentConfig(c *gc.C) string {
+	dataDir := c.MkDir()
+	conf, err := agent.NewStateMachineConfig(agent.AgentConfigParams{
+		DataDir:           dataDir,
+		Tag:               names.NewMachineTag("0"),
+		UpgradedToVersion: version.Current.Number,
+		StateAddresses:    []string{"localhost:37017"},
+		APIAddresses:      []string{"localhost:17070"},
+		CACert:            testing.CACert,
+		Password:          "sekrit",
+		Environment:       testing.EnvironmentTag,
+	}, params.StateServingInfo{
+		Cert:         testing.ServerCert,
+		PrivateKey:   testing.ServerKey,
+		CAPrivateKey: testing.CAKey,
+		APIPort:      17070,
+		StatePort:    37017,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(conf.Write(), jc.ErrorIsNil)
+
+	data, err := ioutil.ReadFile(agent.ConfigPath(dataDir, names.NewMachineTag("0")))
+	c.Assert(err, jc.ErrorIsNil)
+	return string(data)
+}
+
+func (s *verifySuite) newArchive(c *gc.C, files, dump []bt.File) *bytes.Buffer {
+	archive, err := bt.NewArchive(s.meta, files, dump)
+	c.Assert(err, jc.ErrorIsNil)
+	return archive
+}
+
+func (s *verifySuite) newArchiveData(c *gc.C, files, dump []bt.File) *backups.ArchiveData {
+	ad, err := backups.NewArchiveDataReader(s.newArchive(c, files, dump))
+	c.Assert(err, jc.ErrorIsNil)
+	return ad
+}
+
+func (s *verifySuite) files(c *gc.C) []bt.File {
+	return []bt.File{{
+		Name:    "var/lib/juju/agents/machine-0/agent.conf",
+		Content: s.agentConfig(c),
+	}, {
+		Name:    "var/lib/juju/server.pem",
+		Content: testing.ServerCert + testing.ServerKey,
+	}, {
+		Name:    "var/lib/juju/system-identity",
+		Content: "<an ssh key goes here>",
+	}}
+}
+
+var verifyDump = []bt.File{{
+	Name:    "juju/machines.bson",
+	Content: "<BSON data goes here>",
+}, {
+	Name:  "admin",
+	IsDir: true,
+}, {
+	Name:    "oplog.bson",
+	Content: "<BSON data goes here>",
+}}
+
+func (s *verifySuite) TestNewVerifiedArchiveData(c *gc.C) {
+	archive := s.newArchive(c, s.files(c), verifyDump)
+	sum := sha1.Sum(archive.Bytes())
+	meta := bt.NewMetadataStarted()
+	err := meta.MarkComplete(int64(archive.Len()), base64.StdEncoding.EncodeToString(sum[:]))
+	c.Assert(err, jc.ErrorIsNil)
+
+	ad, err := backups.NewVerifiedArchiveData(archive, meta, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	archiveMeta, err := ad.Metadata()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(archiveMeta.Origin, jc.DeepEquals, s.meta.Origin)
+}
+
+func (s *verifySuite) TestNewVerifiedArchiveDataMismatch(c *gc.C) {
+	archive := s.newArchive(c, s.files(c), verifyDump)
+	size := int64(archive.Len())
+
+	meta := bt.NewMetadataStarted()
+	err := meta.MarkComplete(size+1, "<checksum>")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = backups.NewVerifiedArchiveData(bytes.NewReader(archive.Bytes()), meta, nil)
+	c.Check(err, gc.ErrorMatches, `archive is \d+ bytes, expected \d+`)
+
+	meta = bt.NewMetadataStarted()
+	err = meta.MarkComplete(size, "<checksum>")
+	c.Assert(err, jc.ErrorIsNil)
+	_, err = backups.NewVerifiedArchiveData(bytes.NewReader(archive.Bytes()), meta, nil)
+	c.Check(err, gc.ErrorMatches, `archive checksum ".*" does not match "<checksum>"`)
+}
+
+func (s *verifySuite) TestVerifyArchiveData(c *gc.C) {
+	ad := s.newArchiveData(c, s.files(c), verifyDump)
+
+	result, err := backups.VerifyArchiveData(ad, s.meta)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(result, jc.DeepEquals, &backups.VerifyResult{
+		Version:      version.Current.Number,
+		Databases:    []string{"admin", "juju"},
+		AgentConfigs: []string{"var/lib/juju/agents/machine-0/agent.conf"},
+	})
+}
+
+func (s *verifySuite) TestVerifyArchiveDataIncremental(c *gc.C) {
+	s.meta.Base = "spam"
+	ad := s.newArchiveData(c, s.files(c), verifyDump[2:])
+
+	_, err := backups.VerifyArchiveData(ad, s.meta)
+	c.Assert(err, jc.ErrorIsNil)
+
+	ad = s.newArchiveData(c, s.files(c), nil)
+	_, err = backups.VerifyArchiveData(ad, s.meta)
+	c.Check(err, gc.ErrorMatches, "incremental backup has no oplog in its DB dump")
+}
+
+func (s *verifySuite) TestVerifyArchiveDataMetadataMismatch(c *gc.C) {
+	ad := s.newArchiveData(c, s.files(c), verifyDump)
+
+	meta := bt.NewMetadataStarted()
+	meta.Origin.Version = version.MustParse("1.22.0")
+	_, err := backups.VerifyArchiveData(ad, meta)
+	c.Check(err, gc.ErrorMatches, `archive was created by juju .*, not 1.22.0`)
+
+	meta = bt.NewMetadataStarted()
+	meta.Origin.Environment = "another-env"
+	_, err = backups.VerifyArchiveData(ad, meta)
+	c.Check(err, gc.ErrorMatches, `archive is a backup of environment ".*", not "another-env"`)
+}
+
+func (s *verifySuite) TestVerifyArchiveDataMissingDump(c *gc.C) {
+	ad := s.newArchiveData(c, s.files(c), verifyDump[2:])
+
+	_, err := backups.VerifyArchiveData(ad, s.meta)
+	c.Check(err, gc.ErrorMatches, "DB dump is empty or missing")
+}
+
+func (s *verifySuite) TestVerifyArchiveDataInvalidFiles(c *gc.C) {
+	files := s.files(c)
+
+	ad := s.newArchiveData(c, files[1:], verifyDump)
+	_, err := backups.VerifyArchiveData(ad, s.meta)
+	c.Check(err, gc.ErrorMatches, "files bundle holds no agent config")
+
+	ad = s.newArchiveData(c, append(files[:1:1], files[2:]...), verifyDump)
+	_, err = backups.VerifyArchiveData(ad, s.meta)
+	c.Check(err, gc.ErrorMatches, "files bundle holds no mongo certificate")
+
+	files = s.files(c)
+	files[0].Content = "<not an agent config>"
+	ad = s.newArchiveData(c, files, verifyDump)
+	_, err = backups.VerifyArchiveData(ad, s.meta)
+	c.Check(err, gc.ErrorMatches, `invalid agent config "var/lib/juju/agents/machine-0/agent.conf": .*`)
+
+	files = s.files(c)
+	files[1].Content = testing.ServerCert
+	ad = s.newArchiveData(c, files, verifyDump)
+	_, err = backups.VerifyArchiveData(ad, s.meta)
+	c.Check(err, gc.ErrorMatches, `invalid mongo certificate "var/lib/juju/server.pem": .*`)
+}
+
+func (s *verifySuite) TestRestoreDumpCounts(c *gc.C) {
+	ad := s.newArchiveData(c, s.files(c), verifyDump)
+	expected := []backups.CollectionCount{{
+		Database:   "juju",
+		Collection: "machines",
+		Count:      3,
+	}}
+	var dumpDir string
+	s.PatchValue(backups.RestoreDumpLocally, func(dir, dbDir string, ver version.Number) ([]backups.CollectionCount, error) {
+		dumpDir = dir
+		c.Check(dbDir, jc.IsDirectory)
+		c.Check(ver, gc.Equals, version.Current.Number)
+		data, err := ioutil.ReadFile(filepath.Join(dir, "juju", "machines.bson"))
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(string(data), gc.Equals, "<BSON data goes here>")
+		return expected, nil
+	})
+
+	counts, err := backups.RestoreDumpCounts(ad, version.Current.Number)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(counts, jc.DeepEquals, expected)
+
+	// The unpacked archive is cleaned up.
+	_, err = os.Stat(dumpDir)
+	c.Check(os.IsNotExist(err), jc.IsTrue)
+}
+
+type countDocumentsSuite struct {
+	gitjujutesting.MgoSuite
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&countDocumentsSuite{})
+
+func (s *countDocumentsSuite) SetUpSuite(c *gc.C) {
+	s.BaseSuite.SetUpSuite(c)
+	s.MgoSuite.SetUpSuite(c)
+}
+
+func (s *countDocumentsSuite) TearDownSuite(c *gc.C) {
+	s.MgoSuite.TearDownSuite(c)
+	s.BaseSuite.TearDownSuite(c)
+}
+
+func (s *countDocumentsSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.MgoSuite.SetUpTest(c)
+}
+
+func (s *countDocumentsSuite) TearDownTest(c *gc.C) {
+	s.MgoSuite.TearDownTest(c)
+	s.BaseSuite.TearDownTest(c)
+}
+
+func (s *countDocumentsSuite) TestCountDocuments(c *gc.C) {
+	machines := s.Session.DB("juju").C("machines")
+	for _, id := range []string{"0", "1"} {
+		err := machines.Insert(map[string]string{"_id": id})
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	err := s.Session.DB("juju").C("units").Insert(map[string]string{"_id": "wordpress/0"})
+	c.Assert(err, jc.ErrorIsNil)
+
+	counts, err := backups.CountDocuments(s.Session)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(counts, jc.DeepEquals, []backups.CollectionCount{{
+		Database:   "juju",
+		Collection: "machines",
+		Count:      2,
+	}, {
+		Database:   "juju",
+		Collection: "units",
+		Count:      1,
+	}})
+}
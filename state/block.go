@@ -5,6 +5,7 @@ package state
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/names"
@@ -24,7 +25,8 @@ type Block interface {
 	// EnvUUID returns the environment UUID associated with this block.
 	EnvUUID() string
 
-	// Tag returns tag for the entity that is being blocked
+	// Tag returns tag for the entity that is being blocked: the
+	// environment, or a service or machine in it.
 	Tag() (names.Tag, error)
 
 	// Type returns block type
@@ -32,6 +34,10 @@ type Block interface {
 
 	// Message returns explanation that accompanies this block.
 	Message() string
+
+	// Expires returns when the block switches itself off, or the
+	// zero time if it never does.
+	Expires() time.Time
 }
 
 // BlockType specifies block type for enum benefit.
@@ -99,6 +105,7 @@ type blockDoc struct {
 	Tag     string    `bson:"tag"`
 	Type    BlockType `bson:"type"`
 	Message string    `bson:"message,omitempty"`
+	Expires time.Time `bson:"expires,omitempty"`
 }
 
 // Id is part of the state.Block interface.
@@ -130,16 +137,74 @@ func (b *block) Type() BlockType {
 	return b.doc.Type
 }
 
+// Expires is part of the state.Block interface.
+func (b *block) Expires() time.Time {
+	if b.doc.Expires.IsZero() {
+		return time.Time{}
+	}
+	return b.doc.Expires.UTC()
+}
+
 // SwitchBlockOn enables block of specified type for the
 // current environment.
 func (st *State) SwitchBlockOn(t BlockType, msg string) error {
-	return setEnvironmentBlock(st, t, msg)
+	return setBlock(st, t, st.EnvironTag(), msg, time.Time{})
 }
 
 // SwitchBlockOff disables block of specified type for the
 // current environment.
 func (st *State) SwitchBlockOff(t BlockType) error {
-	return removeEnvironmentBlock(st, t)
+	return removeBlock(st, t, st.EnvironTag())
+}
+
+// SwitchEntityBlockOn enables block of specified type for the
+// service or machine with the given tag, or for the current
+// environment if the tag is its own. Only the environment may
+// be protected from destruction. If expires is not zero, the
+// block switches itself off at that time.
+func (st *State) SwitchEntityBlockOn(t BlockType, tag names.Tag, msg string, expires time.Time) error {
+	if err := validateBlockTarget(st, t, tag); err != nil {
+		return errors.Trace(err)
+	}
+	if !expires.IsZero() && !expires.After(time.Now()) {
+		return errors.NotValidf("block expiry time %v in the past", expires)
+	}
+	if tag != st.EnvironTag() {
+		if _, err := st.FindEntity(tag); err != nil {
+			return errors.Annotatef(err, "cannot switch block %v on for %s", t, names.ReadableString(tag))
+		}
+	}
+	return setBlock(st, t, tag, msg, expires)
+}
+
+// SwitchEntityBlockOff disables block of specified type for the
+// service or machine with the given tag, or for the current
+// environment if the tag is its own.
+func (st *State) SwitchEntityBlockOff(t BlockType, tag names.Tag) error {
+	if err := validateBlockTarget(st, t, tag); err != nil {
+		return errors.Trace(err)
+	}
+	return removeBlock(st, t, tag)
+}
+
+// validateBlockTarget returns an error if blocks of the given type
+// cannot be switched on or off for the entity with the given tag.
+func validateBlockTarget(st *State, t BlockType, tag names.Tag) error {
+	switch tag := tag.(type) {
+	case names.EnvironTag:
+		if tag != st.EnvironTag() {
+			return errors.NotValidf("block target %q outside the environment", tag)
+		}
+		return nil
+	case names.ServiceTag, names.MachineTag:
+		if t == DestroyBlock {
+			return errors.NotValidf("block %v for %s", t, names.ReadableString(tag))
+		}
+		return nil
+	case nil:
+		return errors.NotValidf("empty block target")
+	}
+	return errors.NotValidf("block target %q", tag)
 }
 
 // GetBlockForType returns the Block of the specified type for the current environment
@@ -147,12 +212,23 @@ func (st *State) SwitchBlockOff(t BlockType) error {
 //     not found -> nil, false, nil
 //     found -> block, true, nil
 //     error -> nil, false, err
+// Blocks for single services or machines, and blocks that have
+// expired, are not considered.
 func (st *State) GetBlockForType(t BlockType) (Block, bool, error) {
+	return st.GetEntityBlockForType(t, st.EnvironTag())
+}
+
+// GetEntityBlockForType returns the Block of the specified type for
+// the entity with the given tag, in the same way as GetBlockForType.
+// Only blocks for that very entity are considered, so a service
+// is not reported blocked when the whole environment is.
+func (st *State) GetEntityBlockForType(t BlockType, tag names.Tag) (Block, bool, error) {
 	all, closer := st.getCollection(blocksC)
 	defer closer()
 
 	doc := blockDoc{}
-	err := all.Find(bson.D{{"type", t}}).One(&doc)
+	query := append(bson.D{{"type", t}, {"tag", tag.String()}}, unexpiredBlocks()...)
+	err := all.Find(query).One(&doc)
 
 	switch err {
 	case nil:
@@ -164,13 +240,24 @@ func (st *State) GetBlockForType(t BlockType) (Block, bool, error) {
 	}
 }
 
-// AllBlocks returns all blocks in the environment.
+// unexpiredBlocks returns a query that selects the blocks that
+// have not expired.
+func unexpiredBlocks() bson.D {
+	return bson.D{{"$or", []bson.D{
+		{{"expires", bson.D{{"$exists", false}}}},
+		{{"expires", bson.D{{"$gt", time.Now()}}}},
+	}}}
+}
+
+// AllBlocks returns all blocks in the environment, including
+// those for single services or machines, but not those that
+// have expired.
 func (st *State) AllBlocks() ([]Block, error) {
 	blocksCollection, closer := st.getCollection(blocksC)
 	defer closer()
 
 	var bdocs []blockDoc
-	err := blocksCollection.Find(nil).All(&bdocs)
+	err := blocksCollection.Find(unexpiredBlocks()).All(&bdocs)
 	if err != nil {
 		return nil, errors.Annotatef(err, "cannot get all blocks")
 	}
@@ -223,25 +310,42 @@ func (st *State) RemoveAllBlocksForSystem() error {
 	return st.runRawTransaction(ops)
 }
 
-// setEnvironmentBlock updates the blocks collection with the
+// setBlock updates the blocks collection with the
 // specified block.
-// Only one instance of each block type can exist in environment.
-func setEnvironmentBlock(st *State, t BlockType, msg string) error {
+// Only one instance of each block type can exist for an entity.
+func setBlock(st *State, t BlockType, tag names.Tag, msg string, expires time.Time) error {
 	buildTxn := func(attempt int) ([]txn.Op, error) {
-		_, exists, err := st.GetBlockForType(t)
+		_, exists, err := st.GetEntityBlockForType(t, tag)
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
-		// Cannot create blocks of the same type more than once per environment.
+		// Cannot create blocks of the same type more than once per entity.
 		// Cannot update current blocks.
 		if exists {
-			return nil, errors.Errorf("block %v is already ON", t.String())
+			return nil, errors.Errorf("%s is already ON", blockDescription(st, t, tag))
+		}
+		ops, err := removeExpiredBlockOps(st, t, tag)
+		if err != nil {
+			return nil, errors.Trace(err)
 		}
-		return createEnvironmentBlockOps(st, t, msg)
+		createOps, err := createBlockOps(st, t, tag, msg, expires)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return append(ops, createOps...), nil
 	}
 	return st.run(buildTxn)
 }
 
+// blockDescription describes the block of the given type for the
+// entity with the given tag in errors.
+func blockDescription(st *State, t BlockType, tag names.Tag) string {
+	if tag == st.EnvironTag() {
+		return fmt.Sprintf("block %v", t.String())
+	}
+	return fmt.Sprintf("block %v for %s", t.String(), names.ReadableString(tag))
+}
+
 // newBlockId returns a sequential block id for this environment.
 func newBlockId(st *State) (string, error) {
 	seq, err := st.sequence("block")
@@ -251,7 +355,7 @@ func newBlockId(st *State) (string, error) {
 	return fmt.Sprint(seq), nil
 }
 
-func createEnvironmentBlockOps(st *State, t BlockType, msg string) ([]txn.Op, error) {
+func createBlockOps(st *State, t BlockType, tag names.Tag, msg string, expires time.Time) ([]txn.Op, error) {
 	id, err := newBlockId(st)
 	if err != nil {
 		return nil, errors.Annotatef(err, "getting new block id")
@@ -259,10 +363,13 @@ func createEnvironmentBlockOps(st *State, t BlockType, msg string) ([]txn.Op, er
 	newDoc := blockDoc{
 		DocID:   st.docID(id),
 		EnvUUID: st.EnvironUUID(),
-		Tag:     st.EnvironTag().String(),
+		Tag:     tag.String(),
 		Type:    t,
 		Message: msg,
 	}
+	if !expires.IsZero() {
+		newDoc.Expires = expires.UTC()
+	}
 	insertOp := txn.Op{
 		C:      blocksC,
 		Id:     newDoc.DocID,
@@ -272,17 +379,39 @@ func createEnvironmentBlockOps(st *State, t BlockType, msg string) ([]txn.Op, er
 	return []txn.Op{insertOp}, nil
 }
 
-func removeEnvironmentBlock(st *State, t BlockType) error {
+// removeExpiredBlockOps returns the operations that remove the
+// expired blocks of the given type for the entity with the given tag.
+func removeExpiredBlockOps(st *State, t BlockType, tag names.Tag) ([]txn.Op, error) {
+	blocksCollection, closer := st.getCollection(blocksC)
+	defer closer()
+
+	var docs []blockDoc
+	query := bson.D{{"type", t}, {"tag", tag.String()}, {"expires", bson.D{{"$lte", time.Now()}}}}
+	if err := blocksCollection.Find(query).Select(bson.D{{"_id", 1}}).All(&docs); err != nil {
+		return nil, errors.Annotatef(err, "cannot get expired blocks of type %v", t.String())
+	}
+	ops := make([]txn.Op, len(docs))
+	for i, doc := range docs {
+		ops[i] = txn.Op{
+			C:      blocksC,
+			Id:     doc.DocID,
+			Remove: true,
+		}
+	}
+	return ops, nil
+}
+
+func removeBlock(st *State, t BlockType, tag names.Tag) error {
 	buildTxn := func(attempt int) ([]txn.Op, error) {
-		return removeEnvironmentBlockOps(st, t)
+		return removeBlockOps(st, t, tag)
 	}
 	return st.run(buildTxn)
 }
 
-func removeEnvironmentBlockOps(st *State, t BlockType) ([]txn.Op, error) {
-	tBlock, exists, err := st.GetBlockForType(t)
+func removeBlockOps(st *State, t BlockType, tag names.Tag) ([]txn.Op, error) {
+	tBlock, exists, err := st.GetEntityBlockForType(t, tag)
 	if err != nil {
-		return nil, errors.Annotatef(err, "removing block %v", t.String())
+		return nil, errors.Annotatef(err, "removing %s", blockDescription(st, t, tag))
 	}
 	if exists {
 		return []txn.Op{txn.Op{
@@ -291,5 +420,5 @@ func removeEnvironmentBlockOps(st *State, t BlockType) ([]txn.Op, error) {
 			Remove: true,
 		}}, nil
 	}
-	return nil, errors.Errorf("block %v is already OFF", t.String())
+	return nil, errors.Errorf("%s is already OFF", blockDescription(st, t, tag))
 }
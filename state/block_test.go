@@ -5,6 +5,7 @@ package state_test
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/names"
@@ -14,6 +15,7 @@ import (
 
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/testing"
+	"github.com/juju/juju/testing/factory"
 )
 
 type blockSuite struct {
@@ -186,6 +188,111 @@ func (s *blockSuite) TestEnvUUID(c *gc.C) {
 	c.Assert(blocks[0].EnvUUID(), gc.Equals, st.EnvironUUID())
 }
 
+func (s *blockSuite) TestEntityBlock(c *gc.C) {
+	svc := s.Factory.MakeService(c, &factory.ServiceParams{Name: "mysql"})
+	err := s.State.SwitchEntityBlockOn(state.RemoveBlock, svc.Tag(), "keep mysql", time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+
+	dBlock, found, err := s.State.GetEntityBlockForType(state.RemoveBlock, svc.Tag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(found, jc.IsTrue)
+	tag, err := dBlock.Tag()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(tag, gc.Equals, svc.Tag())
+	c.Assert(dBlock.Message(), gc.Equals, "keep mysql")
+	c.Assert(dBlock.Expires().IsZero(), jc.IsTrue)
+
+	// The environment itself is not blocked.
+	s.assertNoTypedBlock(c, state.RemoveBlock)
+	all, err := s.State.AllBlocks()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(all, gc.HasLen, 1)
+
+	err = s.State.SwitchEntityBlockOn(state.RemoveBlock, svc.Tag(), "", time.Time{})
+	c.Assert(err, gc.ErrorMatches, `block BlockRemove for service mysql is already ON`)
+
+	err = s.State.SwitchEntityBlockOff(state.RemoveBlock, svc.Tag())
+	c.Assert(err, jc.ErrorIsNil)
+	assertNoEnvBlock(c, s.State)
+	err = s.State.SwitchEntityBlockOff(state.RemoveBlock, svc.Tag())
+	c.Assert(err, gc.ErrorMatches, `block BlockRemove for service mysql is already OFF`)
+}
+
+func (s *blockSuite) TestEntityBlockForEnvironment(c *gc.C) {
+	err := s.State.SwitchEntityBlockOn(state.ChangeBlock, s.State.EnvironTag(), "env", time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+	assertEnvHasBlock(c, s.State, state.ChangeBlock, "env")
+
+	err = s.State.SwitchBlockOff(state.ChangeBlock)
+	c.Assert(err, jc.ErrorIsNil)
+	assertNoEnvBlock(c, s.State)
+}
+
+func (s *blockSuite) TestEntityBlockMachine(c *gc.C) {
+	machine := s.Factory.MakeMachine(c, nil)
+	err := s.State.SwitchEntityBlockOn(state.ChangeBlock, machine.Tag(), "", time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+	_, found, err := s.State.GetEntityBlockForType(state.ChangeBlock, machine.Tag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(found, jc.IsTrue)
+}
+
+func (s *blockSuite) TestEntityBlockInvalid(c *gc.C) {
+	svc := s.Factory.MakeService(c, nil)
+	err := s.State.SwitchEntityBlockOn(state.DestroyBlock, svc.Tag(), "", time.Time{})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `block BlockDestroy for service .* not valid`)
+
+	err = s.State.SwitchEntityBlockOn(state.RemoveBlock, names.NewUnitTag("mysql/0"), "", time.Time{})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `block target "unit-mysql-0" not valid`)
+
+	err = s.State.SwitchEntityBlockOn(state.RemoveBlock, names.NewServiceTag("missing"), "", time.Time{})
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `cannot switch block BlockRemove on for service missing: service "missing" not found`)
+
+	err = s.State.SwitchEntityBlockOn(state.RemoveBlock, svc.Tag(), "", time.Now().Add(-time.Second))
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, `block expiry time .* in the past not valid`)
+	assertNoEnvBlock(c, s.State)
+}
+
+func (s *blockSuite) TestEntityBlockExpires(c *gc.C) {
+	svc := s.Factory.MakeService(c, nil)
+	expires := time.Now().Add(100 * time.Millisecond)
+	err := s.State.SwitchEntityBlockOn(state.RemoveBlock, svc.Tag(), "", expires)
+	c.Assert(err, jc.ErrorIsNil)
+	dBlock, found, err := s.State.GetEntityBlockForType(state.RemoveBlock, svc.Tag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(found, jc.IsTrue)
+	c.Assert(dBlock.Expires().Unix(), gc.Equals, expires.Unix())
+
+	time.Sleep(200 * time.Millisecond)
+	_, found, err = s.State.GetEntityBlockForType(state.RemoveBlock, svc.Tag())
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(found, jc.IsFalse)
+	assertNoEnvBlock(c, s.State)
+
+	// An expired block may be replaced, and is removed when it is.
+	err = s.State.SwitchEntityBlockOn(state.RemoveBlock, svc.Tag(), "again", time.Time{})
+	c.Assert(err, jc.ErrorIsNil)
+	blocks, err := s.State.AllBlocksForSystem()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(blocks, gc.HasLen, 1)
+	c.Assert(blocks[0].Message(), gc.Equals, "again")
+}
+
+func (s *blockSuite) TestEnvironmentBlockExpires(c *gc.C) {
+	err := s.State.SwitchEntityBlockOn(state.ChangeBlock, s.State.EnvironTag(), "", time.Now().Add(100*time.Millisecond))
+	c.Assert(err, jc.ErrorIsNil)
+	assertEnvHasBlock(c, s.State, state.ChangeBlock, "")
+
+	time.Sleep(200 * time.Millisecond)
+	s.assertNoTypedBlock(c, state.ChangeBlock)
+	err = s.State.SwitchBlockOff(state.ChangeBlock)
+	c.Assert(err, gc.ErrorMatches, `block BlockChange is already OFF`)
+}
+
 func (s *blockSuite) createTestEnv(c *gc.C) (*state.Environment, *state.State) {
 	uuid, err := utils.NewUUID()
 	c.Assert(err, jc.ErrorIsNil)
@@ -121,6 +121,9 @@ func (st *State) NewEnvironment(cfg *config.Config, owner names.UserTag) (_ *Env
 	if err != nil {
 		return nil, nil, errors.Annotate(err, "could not load state server environment")
 	}
+	if err := checkHostedEnvironConfig(cfg.AllAttrs()); err != nil {
+		return nil, nil, errors.Annotate(err, "cannot create environment")
+	}
 
 	uuid, ok := cfg.UUID()
 	if !ok {
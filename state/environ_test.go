@@ -44,6 +44,20 @@ func (s *EnvironSuite) TestNewEnvironmentNonExistentLocalUser(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, `cannot create environment: user "non-existent" not found`)
 }
 
+func (s *EnvironSuite) TestNewEnvironmentStateServerOnlyConfig(c *gc.C) {
+	uuid, err := utils.NewUUID()
+	c.Assert(err, jc.ErrorIsNil)
+	cfg := testing.CustomEnvironConfig(c, testing.Attrs{
+		"name":        "testing",
+		"uuid":        uuid.String(),
+		"log-max-age": "24h",
+	})
+	owner := s.Factory.MakeUser(c, nil).UserTag()
+
+	_, _, err = s.State.NewEnvironment(cfg, owner)
+	c.Assert(err, gc.ErrorMatches, "cannot create environment: log-max-age may only be set in the state server environment")
+}
+
 func (s *EnvironSuite) TestNewEnvironmentSameUserSameNameFails(c *gc.C) {
 	cfg, _ := s.createTestEnvConfig(c)
 	owner := s.Factory.MakeUser(c, nil).UserTag()
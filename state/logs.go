@@ -27,6 +27,10 @@ import (
 const logsDB = "logs"
 const logsC = "logs"
 
+// forwardedC holds the ids of the last log records forwarded to
+// each of the log sinks of each environment.
+const forwardedC = "forwarded"
+
 // LoggingState describes the methods on State required for logging to
 // the database.
 type LoggingState interface {
@@ -38,7 +42,7 @@ type LoggingState interface {
 // be called as state is opened. It is idempotent.
 func InitDbLogs(session *mgo.Session) error {
 	logsColl := session.DB(logsDB).C(logsC)
-	for _, key := range [][]string{{"e", "t"}, {"e", "n"}} {
+	for _, key := range [][]string{{"e", "t"}, {"e", "n"}, {"e", "n", "t"}} {
 		err := logsColl.EnsureIndex(mgo.Index{Key: key})
 		if err != nil {
 			return errors.Annotate(err, "cannot create index for logs collection")
@@ -126,6 +130,7 @@ type LogTailer interface {
 // LogRecord defines a single Juju log message as returned by
 // LogTailer.
 type LogRecord struct {
+	Id       bson.ObjectId
 	Time     time.Time
 	Entity   string
 	Module   string
@@ -136,14 +141,29 @@ type LogRecord struct {
 
 // LogTailerParams specifies the filtering a LogTailer should apply to
 // logs in order to decide which to return.
+//
+// If StartID is set, only logs recorded after the log with that id are
+// returned, in the order they were recorded rather than by time, so
+// that a LogTailer can carry on from the last log returned even when
+// logs are recorded some time after they were logged. If EndTime is
+// set, only logs from before it are returned and the LogTailer stops
+// once that time has passed. MessageRegexp selects the logs whose
+// messages match the regular expression, which is matched by the
+// database, and so has the syntax and semantics of PCRE. If NoTail is
+// set, the LogTailer stops after returning the logs already recorded,
+// rather than waiting for more.
 type LogTailerParams struct {
 	StartTime     time.Time
+	StartID       bson.ObjectId
+	EndTime       time.Time
 	MinLevel      loggo.Level
 	InitialLines  int
 	IncludeEntity []string
 	ExcludeEntity []string
 	IncludeModule []string
 	ExcludeModule []string
+	MessageRegexp string
+	NoTail        bool
 	Oplog         *mgo.Collection // For testing only
 }
 
@@ -194,6 +214,7 @@ type logTailer struct {
 	params    *LogTailerParams
 	logCh     chan *LogRecord
 	lastTime  time.Time
+	lastId    bson.ObjectId
 	recentIds *recentIdTracker
 }
 
@@ -223,11 +244,20 @@ func (t *logTailer) loop() error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if t.params.NoTail || t.endTimePassed() {
+		return nil
+	}
 
 	err = t.tailOplog()
 	return errors.Trace(err)
 }
 
+// endTimePassed reports whether no more logs can be returned
+// because the end of the requested time range has passed.
+func (t *logTailer) endTimePassed() bool {
+	return !t.params.EndTime.IsZero() && !t.params.EndTime.After(time.Now())
+}
+
 func (t *logTailer) processCollection() error {
 	// Create a selector from the params.
 	sel := t.paramsToSelector(t.params, "")
@@ -244,7 +274,7 @@ func (t *logTailer) processCollection() error {
 		}
 	}
 
-	iter := query.Sort("t", "_id").Iter()
+	iter := query.Sort(t.sortFields()...).Iter()
 	doc := new(logDoc)
 	for iter.Next(doc) {
 		select {
@@ -252,17 +282,39 @@ func (t *logTailer) processCollection() error {
 			return errors.Trace(tomb.ErrDying)
 		case t.logCh <- logDocToRecord(doc):
 			t.lastTime = doc.Time
+			t.lastId = doc.Id
 			t.recentIds.Add(doc.Id)
 		}
 	}
 	return errors.Trace(iter.Close())
 }
 
+// sortFields returns the fields the logs in the collection are
+// returned in the order of.
+func (t *logTailer) sortFields() []string {
+	if t.params.StartID != "" {
+		// Ids are assigned as logs are recorded.
+		return []string{"_id"}
+	}
+	return []string{"t", "_id"}
+}
+
 func (t *logTailer) tailOplog() error {
 	recentIds := t.recentIds.AsSet()
 
 	newParams := t.params
-	newParams.StartTime = t.lastTime
+	var minOplogTs time.Time
+	if newParams.StartID != "" {
+		// Logs recorded late may have been logged before the last
+		// one returned, so they are only selected by id.
+		if t.lastId != "" {
+			newParams.StartID = t.lastId
+		}
+		minOplogTs = newParams.StartID.Time().Add(-oplogOverlap)
+	} else {
+		newParams.StartTime = t.lastTime
+		minOplogTs = t.lastTime.Add(-oplogOverlap)
+	}
 	oplogSel := append(t.paramsToSelector(newParams, "o."),
 		bson.DocElem{"ns", logsDB + "." + logsC},
 	)
@@ -272,10 +324,15 @@ func (t *logTailer) tailOplog() error {
 		oplog = mongo.GetOplog(t.session)
 	}
 
-	minOplogTs := t.lastTime.Add(-oplogOverlap)
 	oplogTailer := mongo.NewOplogTailer(oplog, oplogSel, minOplogTs)
 	defer oplogTailer.Stop()
 
+	// Stop tailing at the end of the requested time range.
+	var endTimer <-chan time.Time
+	if !t.params.EndTime.IsZero() {
+		endTimer = time.After(t.params.EndTime.Sub(time.Now()))
+	}
+
 	logger.Tracef("LogTailer starting oplog tailing: recent id count=%d, lastTime=%s, minOplogTs=%s",
 		recentIds.Length(), t.lastTime, minOplogTs)
 
@@ -284,6 +341,8 @@ func (t *logTailer) tailOplog() error {
 		select {
 		case <-t.tomb.Dying():
 			return errors.Trace(tomb.ErrDying)
+		case <-endTimer:
+			return nil
 		case oplogDoc, ok := <-oplogTailer.Out():
 			if !ok {
 				return errors.Annotate(oplogTailer.Err(), "oplog tailer died")
@@ -314,9 +373,16 @@ func (t *logTailer) tailOplog() error {
 }
 
 func (t *logTailer) paramsToSelector(params *LogTailerParams, prefix string) bson.D {
+	timeSel := bson.M{"$gte": params.StartTime}
+	if !params.EndTime.IsZero() {
+		timeSel["$lt"] = params.EndTime
+	}
 	sel := bson.D{
 		{"e", t.envUUID},
-		{"t", bson.M{"$gte": params.StartTime}},
+		{"t", timeSel},
+	}
+	if params.StartID != "" {
+		sel = append(sel, bson.DocElem{"_id", bson.M{"$gt": params.StartID}})
 	}
 	if params.MinLevel > loggo.UNSPECIFIED {
 		sel = append(sel, bson.DocElem{"v", bson.M{"$gte": params.MinLevel}})
@@ -337,6 +403,9 @@ func (t *logTailer) paramsToSelector(params *LogTailerParams, prefix string) bso
 		sel = append(sel,
 			bson.DocElem{"m", bson.M{"$not": bson.RegEx{Pattern: makeModulePattern(params.ExcludeModule)}}})
 	}
+	if params.MessageRegexp != "" {
+		sel = append(sel, bson.DocElem{"x", bson.RegEx{Pattern: params.MessageRegexp}})
+	}
 
 	if prefix != "" {
 		for i, elem := range sel {
@@ -414,6 +483,7 @@ func (s *objectIdSet) Length() int {
 
 func logDocToRecord(doc *logDoc) *LogRecord {
 	return &LogRecord{
+		Id:       doc.Id,
 		Time:     doc.Time,
 		Entity:   doc.Entity,
 		Module:   doc.Module,
@@ -423,6 +493,82 @@ func logDocToRecord(doc *logDoc) *LogRecord {
 	}
 }
 
+// LogPrunePolicy specifies how log records are pruned to control the
+// size of the logs collection.
+type LogPrunePolicy struct {
+	// MaxAge is how long log records are kept.
+	MaxAge time.Duration
+
+	// LevelMaxAges holds how long log records logged at particular
+	// levels are kept, in place of MaxAge.
+	LevelMaxAges map[loggo.Level]time.Duration
+
+	// MaxEntityMB, if positive, is the size that the log records of
+	// each entity in an environment are pruned to, estimated from the
+	// average size of the records in the logs collection.
+	MaxEntityMB int
+
+	// MaxCollectionMB is the size that the logs collection is pruned
+	// to, once the other limits have been applied.
+	MaxCollectionMB int
+}
+
+// LogPruneCounts holds the numbers of log records removed by pruning
+// for each of the limits of a LogPrunePolicy.
+type LogPruneCounts struct {
+	Age    int `bson:"age"`
+	Level  int `bson:"level"`
+	Entity int `bson:"entity"`
+	Size   int `bson:"size"`
+}
+
+// LogEntityPruneCount holds the number of log records of an entity
+// removed to keep them within the per-entity limit.
+type LogEntityPruneCount struct {
+	EnvUUID string `bson:"env-uuid"`
+	Entity  string `bson:"entity"`
+	Removed int    `bson:"removed"`
+}
+
+// LogPruneStats describes the pruning of the logs collection.
+type LogPruneStats struct {
+	// Time is when the logs were last pruned.
+	Time time.Time
+
+	// CollectionMB is the size of the logs collection after the logs
+	// were last pruned.
+	CollectionMB int
+
+	// Removed holds the numbers of log records removed when the logs
+	// were last pruned.
+	Removed LogPruneCounts
+
+	// TotalRemoved holds the numbers of log records removed since the
+	// logs were first pruned.
+	TotalRemoved LogPruneCounts
+
+	// Entities holds the entities with log records removed to keep
+	// them within the per-entity limit when the logs were last pruned.
+	Entities []LogEntityPruneCount
+}
+
+// logPruneStatsDoc records the LogPruneStats of the logs collection.
+type logPruneStatsDoc struct {
+	DocID        string                `bson:"_id"`
+	Time         time.Time             `bson:"time"`
+	CollectionMB int                   `bson:"collection-mb"`
+	Removed      LogPruneCounts        `bson:"removed"`
+	TotalRemoved LogPruneCounts        `bson:"total-removed"`
+	Entities     []LogEntityPruneCount `bson:"entities"`
+}
+
+// logPruneStatsC holds the stats of the last pruning of the logs
+// collection, in a single document.
+const (
+	logPruneStatsC  = "prunestats"
+	logPruneStatsID = "logs"
+)
+
 // PruneLogs removes old log documents in order to control the size of
 // logs collection. All logs older than minLogTime are
 // removed. Further removal is also performed if the logs collection
@@ -431,7 +577,55 @@ func PruneLogs(st LoggingState, minLogTime time.Time, maxLogsMB int) error {
 	session, logsColl := initLogsSession(st)
 	defer session.Close()
 
-	envUUIDs, err := getEnvsInLogs(logsColl)
+	pruner := &logPruner{
+		logsColl:   logsColl,
+		minLogTime: minLogTime,
+		policy:     LogPrunePolicy{MaxCollectionMB: maxLogsMB},
+	}
+	return pruner.prune()
+}
+
+// PruneLogsWithPolicy removes log documents in order to control the
+// size of the logs collection as the policy specifies, measuring the
+// ages of the logs from now. It records and returns stats of the
+// records removed.
+func PruneLogsWithPolicy(st LoggingState, now time.Time, policy LogPrunePolicy) (*LogPruneStats, error) {
+	session, logsColl := initLogsSession(st)
+	defer session.Close()
+
+	pruner := &logPruner{
+		logsColl:   logsColl,
+		now:        now,
+		minLogTime: now.Add(-policy.MaxAge),
+		policy:     policy,
+	}
+	if err := pruner.prune(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	collMB, err := getCollectionMB(logsColl)
+	if err != nil {
+		return nil, errors.Annotate(err, "failed to retrieve log counts")
+	}
+	stats, err := recordLogPruneStats(session, now, collMB, pruner.removed, pruner.entities)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return stats, nil
+}
+
+// logPruner removes log documents as a LogPrunePolicy specifies,
+// counting those it removes.
+type logPruner struct {
+	logsColl   *mgo.Collection
+	now        time.Time
+	minLogTime time.Time
+	policy     LogPrunePolicy
+	removed    LogPruneCounts
+	entities   []LogEntityPruneCount
+}
+
+func (p *logPruner) prune() error {
+	envUUIDs, err := getEnvsInLogs(p.logsColl)
 	if err != nil {
 		return errors.Annotate(err, "failed to get log counts")
 	}
@@ -439,29 +633,63 @@ func PruneLogs(st LoggingState, minLogTime time.Time, maxLogsMB int) error {
 	pruneCounts := make(map[string]int)
 
 	// Remove old log entries (per environment UUID to take advantage
-	// of indexes on the logs collection).
+	// of indexes on the logs collection), other than those logged at
+	// levels with their own maximum age.
+	var levels []loggo.Level
+	for level := range p.policy.LevelMaxAges {
+		levels = append(levels, level)
+	}
 	for _, envUUID := range envUUIDs {
-		removeInfo, err := logsColl.RemoveAll(bson.M{
+		selector := bson.M{
 			"e": envUUID,
-			"t": bson.M{"$lt": minLogTime},
-		})
+			"t": bson.M{"$lt": p.minLogTime},
+		}
+		if len(levels) > 0 {
+			selector["v"] = bson.M{"$nin": levels}
+		}
+		removeInfo, err := p.logsColl.RemoveAll(selector)
 		if err != nil {
 			return errors.Annotate(err, "failed to prune logs by time")
 		}
 		pruneCounts[envUUID] = removeInfo.Removed
+		p.removed.Age += removeInfo.Removed
+	}
+
+	// Remove old entries logged at levels with their own maximum age.
+	for level, maxAge := range p.policy.LevelMaxAges {
+		minLevelTime := p.now.Add(-maxAge)
+		for _, envUUID := range envUUIDs {
+			removeInfo, err := p.logsColl.RemoveAll(bson.M{
+				"e": envUUID,
+				"t": bson.M{"$lt": minLevelTime},
+				"v": level,
+			})
+			if err != nil {
+				return errors.Annotatef(err, "failed to prune %s logs by time", level)
+			}
+			pruneCounts[envUUID] += removeInfo.Removed
+			p.removed.Level += removeInfo.Removed
+		}
+	}
+
+	// Remove the oldest entries of entities over their limit.
+	if p.policy.MaxEntityMB > 0 {
+		if err := p.pruneEntities(envUUIDs, pruneCounts); err != nil {
+			return errors.Trace(err)
+		}
 	}
 
 	// Do further pruning if the logs collection is over the maximum size.
 	for {
-		collMB, err := getCollectionMB(logsColl)
+		collMB, err := getCollectionMB(p.logsColl)
 		if err != nil {
 			return errors.Annotate(err, "failed to retrieve log counts")
 		}
-		if collMB <= maxLogsMB {
+		if collMB <= p.policy.MaxCollectionMB {
 			break
 		}
 
-		envUUID, count, err := findEnvWithMostLogs(logsColl, envUUIDs)
+		envUUID, count, err := findEnvWithMostLogs(p.logsColl, envUUIDs)
 		if err != nil {
 			return errors.Annotate(err, "log count query failed")
 		}
@@ -476,7 +704,7 @@ func PruneLogs(st LoggingState, minLogTime time.Time, maxLogsMB int) error {
 		// NOTE: this assumes that there are no more logs being added
 		// for the time range being pruned (which should be true for
 		// any realistic minimum log collection size).
-		tsQuery := logsColl.Find(bson.M{"e": envUUID}).Sort("t")
+		tsQuery := p.logsColl.Find(bson.M{"e": envUUID}).Sort("t")
 		tsQuery = tsQuery.Skip(toRemove)
 		tsQuery = tsQuery.Select(bson.M{"t": 1})
 		var doc bson.M
@@ -487,7 +715,7 @@ func PruneLogs(st LoggingState, minLogTime time.Time, maxLogsMB int) error {
 		thresholdTs := doc["t"].(time.Time)
 
 		// Remove old records.
-		removeInfo, err := logsColl.RemoveAll(bson.M{
+		removeInfo, err := p.logsColl.RemoveAll(bson.M{
 			"e": envUUID,
 			"t": bson.M{"$lt": thresholdTs},
 		})
@@ -495,6 +723,7 @@ func PruneLogs(st LoggingState, minLogTime time.Time, maxLogsMB int) error {
 			return errors.Annotate(err, "log pruning failed")
 		}
 		pruneCounts[envUUID] += removeInfo.Removed
+		p.removed.Size += removeInfo.Removed
 	}
 
 	for envUUID, count := range pruneCounts {
@@ -505,6 +734,151 @@ func PruneLogs(st LoggingState, minLogTime time.Time, maxLogsMB int) error {
 	return nil
 }
 
+// pruneEntities removes the oldest log records of each entity in an
+// environment whose records are estimated to be over the per-entity
+// limit. The records of each environment are counted by entity
+// separately, so that the indexes on the logs collection are used.
+func (p *logPruner) pruneEntities(envUUIDs []string, pruneCounts map[string]int) error {
+	avgObjSize, err := getAvgObjSize(p.logsColl)
+	if err != nil {
+		return errors.Annotate(err, "failed to retrieve log record size")
+	}
+	if avgObjSize <= 0 {
+		// There are no logs.
+		return nil
+	}
+	maxCount := p.policy.MaxEntityMB * humanize.MiByte / avgObjSize
+
+	for _, envUUID := range envUUIDs {
+		var counts []struct {
+			Entity string `bson:"_id"`
+			Count  int    `bson:"count"`
+		}
+		err = p.logsColl.Pipe([]bson.M{
+			{"$match": bson.M{"e": envUUID}},
+			{"$group": bson.M{
+				"_id":   "$n",
+				"count": bson.M{"$sum": 1},
+			}},
+			{"$match": bson.M{"count": bson.M{"$gt": maxCount}}},
+		}).All(&counts)
+		if err != nil {
+			return errors.Annotate(err, "log count by entity query failed")
+		}
+		for _, count := range counts {
+			removed, err := p.pruneEntity(envUUID, count.Entity, maxCount)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if removed == 0 {
+				continue
+			}
+			pruneCounts[envUUID] += removed
+			p.removed.Entity += removed
+			p.entities = append(p.entities, LogEntityPruneCount{
+				EnvUUID: envUUID,
+				Entity:  count.Entity,
+				Removed: removed,
+			})
+		}
+	}
+	return nil
+}
+
+// pruneEntity removes all but the newest maxCount log records of the
+// entity in the environment, and returns the number removed.
+func (p *logPruner) pruneEntity(envUUID, entity string, maxCount int) (int, error) {
+	// Find the newest record to remove. Records logged at the same
+	// time are ordered by id, so that only those over the limit are
+	// removed.
+	var doc struct {
+		Id   bson.ObjectId `bson:"_id"`
+		Time time.Time     `bson:"t"`
+	}
+	err := p.logsColl.Find(bson.M{"e": envUUID, "n": entity}).
+		Sort("-t", "-_id").Skip(maxCount).Select(bson.M{"t": 1}).One(&doc)
+	if err == mgo.ErrNotFound {
+		return 0, nil
+	} else if err != nil {
+		return 0, errors.Annotate(err, "log pruning timestamp query failed")
+	}
+	removeInfo, err := p.logsColl.RemoveAll(bson.M{
+		"e": envUUID,
+		"n": entity,
+		"$or": []bson.M{
+			{"t": bson.M{"$lt": doc.Time}},
+			{"t": doc.Time, "_id": bson.M{"$lte": doc.Id}},
+		},
+	})
+	if err != nil {
+		return 0, errors.Annotatef(err, "failed to prune logs of %s", entity)
+	}
+	return removeInfo.Removed, nil
+}
+
+// recordLogPruneStats records and returns the stats of the pruning of
+// the logs collection, adding the numbers of log records removed to
+// the totals.
+func recordLogPruneStats(
+	session *mgo.Session,
+	now time.Time,
+	collMB int,
+	removed LogPruneCounts,
+	entities []LogEntityPruneCount,
+) (*LogPruneStats, error) {
+	coll := session.DB(logsDB).C(logPruneStatsC)
+	change := mgo.Change{
+		Update: bson.M{
+			"$set": bson.M{
+				"time":          now,
+				"collection-mb": collMB,
+				"removed":       removed,
+				"entities":      entities,
+			},
+			"$inc": bson.M{
+				"total-removed.age":    removed.Age,
+				"total-removed.level":  removed.Level,
+				"total-removed.entity": removed.Entity,
+				"total-removed.size":   removed.Size,
+			},
+		},
+		Upsert:    true,
+		ReturnNew: true,
+	}
+	var doc logPruneStatsDoc
+	if _, err := coll.FindId(logPruneStatsID).Apply(change, &doc); err != nil {
+		return nil, errors.Annotate(err, "cannot record log pruning stats")
+	}
+	return doc.stats(), nil
+}
+
+// GetLogPruneStats returns the stats of the last pruning of the logs
+// collection. It returns an error satisfying errors.IsNotFound if the
+// logs have not been pruned with a LogPrunePolicy.
+func GetLogPruneStats(st LoggingState) (*LogPruneStats, error) {
+	session := st.MongoSession().Copy()
+	defer session.Close()
+
+	var doc logPruneStatsDoc
+	err := session.DB(logsDB).C(logPruneStatsC).FindId(logPruneStatsID).One(&doc)
+	if err == mgo.ErrNotFound {
+		return nil, errors.NotFoundf("log pruning stats")
+	} else if err != nil {
+		return nil, errors.Annotate(err, "cannot get log pruning stats")
+	}
+	return doc.stats(), nil
+}
+
+func (doc *logPruneStatsDoc) stats() *LogPruneStats {
+	return &LogPruneStats{
+		Time:         doc.Time,
+		CollectionMB: doc.CollectionMB,
+		Removed:      doc.Removed,
+		TotalRemoved: doc.TotalRemoved,
+		Entities:     doc.Entities,
+	}
+}
+
 // initLogsSession creates a new session suitable for logging updates,
 // returning the session and a logs mgo.Collection connected to that
 // session.
@@ -534,6 +908,28 @@ func getCollectionMB(coll *mgo.Collection) (int, error) {
 	return result["size"].(int), nil
 }
 
+// getAvgObjSize returns the average size of the documents in a
+// MongoDB collection (in bytes), or 0 if it is empty.
+func getAvgObjSize(coll *mgo.Collection) (int, error) {
+	var result bson.M
+	err := coll.Database.Run(bson.D{
+		{"collStats", coll.Name},
+	}, &result)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	// The type of the size depends on the version of MongoDB.
+	switch size := result["avgObjSize"].(type) {
+	case int:
+		return size, nil
+	case int64:
+		return int(size), nil
+	case float64:
+		return int(size), nil
+	}
+	return 0, nil
+}
+
 // getEnvsInLogs returns the unique environment UUIDs that exist in
 // the logs collection. This uses the one of the indexes on the
 // collection and should be fast.
@@ -573,3 +969,70 @@ func getLogCountForEnv(coll *mgo.Collection, envUUID string) (int, error) {
 	}
 	return count, nil
 }
+
+// lastSentDoc records the id of the last log record forwarded to a
+// log sink.
+type lastSentDoc struct {
+	// DocID is the environment UUID and the sink name, joined by ":".
+	DocID   string        `bson:"_id"`
+	EnvUUID string        `bson:"env-uuid"`
+	Sink    string        `bson:"sink"`
+	LogID   bson.ObjectId `bson:"log-id"`
+}
+
+// LastSentLogTracker records and retrieves the id of the last log
+// record of an environment forwarded to a log sink, so that forwarding
+// may carry on from there after a restart.
+type LastSentLogTracker struct {
+	session *mgo.Session
+	coll    *mgo.Collection
+	id      string
+	envUUID string
+	sink    string
+}
+
+// NewLastSentLogTracker returns a LastSentLogTracker for the logs of
+// the environment forwarded to the named sink. It should be closed
+// when no longer needed.
+func NewLastSentLogTracker(st LoggingState, sink string) *LastSentLogTracker {
+	session := st.MongoSession().Copy()
+	envUUID := st.EnvironUUID()
+	return &LastSentLogTracker{
+		session: session,
+		coll:    session.DB(logsDB).C(forwardedC),
+		id:      envUUID + ":" + sink,
+		envUUID: envUUID,
+		sink:    sink,
+	}
+}
+
+// Close releases the resources used by the tracker.
+func (t *LastSentLogTracker) Close() error {
+	t.session.Close()
+	return nil
+}
+
+// Set records the id of the last log record forwarded.
+func (t *LastSentLogTracker) Set(id bson.ObjectId) error {
+	_, err := t.coll.UpsertId(t.id, &lastSentDoc{
+		DocID:   t.id,
+		EnvUUID: t.envUUID,
+		Sink:    t.sink,
+		LogID:   id,
+	})
+	return errors.Annotatef(err, "cannot record last log forwarded to %q", t.sink)
+}
+
+// Get returns the id of the last log record forwarded. It returns an
+// error satisfying errors.IsNotFound if none has been recorded.
+func (t *LastSentLogTracker) Get() (bson.ObjectId, error) {
+	var doc lastSentDoc
+	err := t.coll.FindId(t.id).One(&doc)
+	if err == mgo.ErrNotFound {
+		return "", errors.NotFoundf("last log forwarded to %q", t.sink)
+	}
+	if err != nil {
+		return "", errors.Annotatef(err, "cannot get last log forwarded to %q", t.sink)
+	}
+	return doc.LogID, nil
+}
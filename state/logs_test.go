@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/juju/errors"
 	"github.com/juju/loggo"
 	"github.com/juju/names"
 	jc "github.com/juju/testing/checkers"
@@ -42,9 +43,10 @@ func (s *LogsSuite) TestIndexesCreated(c *gc.C) {
 		keys = append(keys, strings.Join(index.Key, "-"))
 	}
 	c.Assert(keys, jc.SameContents, []string{
-		"_id", // default index
-		"e-t", // env-uuid and timestamp
-		"e-n", // env-uuid and entity
+		"_id",   // default index
+		"e-t",   // env-uuid and timestamp
+		"e-n",   // env-uuid and entity
+		"e-n-t", // env-uuid, entity and timestamp
 	})
 }
 
@@ -154,6 +156,148 @@ func (s *LogsSuite) TestPruneLogsBySize(c *gc.C) {
 	assertLatestTs(s2)
 }
 
+func (s *LogsSuite) TestPruneLogsWithPolicyByLevel(c *gc.C) {
+	dbLogger := state.NewDbLogger(s.State, names.NewMachineTag("22"))
+	defer dbLogger.Close()
+	log := func(t time.Time, level loggo.Level, msg string) {
+		err := dbLogger.Log(t, "module", "loc", level, msg)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	now := time.Now()
+	log(now, loggo.DEBUG, "keep")
+	log(now.Add(-2*time.Hour), loggo.DEBUG, "prune")
+	log(now.Add(-2*time.Hour), loggo.INFO, "keep")
+	log(now.Add(-2*time.Hour), loggo.ERROR, "keep")
+	log(now.Add(-25*time.Hour), loggo.INFO, "prune")
+	log(now.Add(-25*time.Hour), loggo.ERROR, "keep")
+
+	stats, err := state.PruneLogsWithPolicy(s.State, now, state.LogPrunePolicy{
+		MaxAge: 24 * time.Hour,
+		LevelMaxAges: map[loggo.Level]time.Duration{
+			loggo.DEBUG: time.Hour,
+			loggo.ERROR: 48 * time.Hour,
+		},
+		MaxCollectionMB: 100,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(stats.Removed, gc.Equals, state.LogPruneCounts{Age: 1, Level: 1})
+	c.Assert(stats.Entities, gc.HasLen, 0)
+
+	var docs []bson.M
+	err = s.logsColl.Find(nil).All(&docs)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(docs, gc.HasLen, 4)
+	for _, doc := range docs {
+		c.Assert(doc["x"], gc.Equals, "keep")
+	}
+}
+
+func (s *LogsSuite) TestPruneLogsWithPolicyByEntity(c *gc.C) {
+	now := time.Now().Truncate(time.Millisecond)
+	logs := func(tag names.Tag, count int) {
+		dbLogger := state.NewDbLogger(s.State, tag)
+		defer dbLogger.Close()
+		msg := strings.Repeat("x", 1024)
+		for i := 0; i < count; i++ {
+			ts := now.Add(-time.Duration(i) * time.Second)
+			err := dbLogger.Log(ts, "module", "loc", loggo.INFO, msg)
+			c.Assert(err, jc.ErrorIsNil)
+		}
+	}
+	countLogs := func(tag names.Tag) int {
+		count, err := s.logsColl.Find(bson.M{"n": tag.String()}).Count()
+		c.Assert(err, jc.ErrorIsNil)
+		return count
+	}
+	noisy := names.NewMachineTag("0")
+	quiet := names.NewMachineTag("1")
+	logs(noisy, 2000)
+	logs(quiet, 10)
+
+	stats, err := state.PruneLogsWithPolicy(s.State, now, state.LogPrunePolicy{
+		MaxAge:          24 * time.Hour,
+		MaxEntityMB:     1,
+		MaxCollectionMB: 100,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Only the logs of the noisy entity are pruned.
+	remaining := countLogs(noisy)
+	c.Assert(remaining, jc.LessThan, 2000)
+	c.Assert(remaining, jc.GreaterThan, 0)
+	c.Assert(countLogs(quiet), gc.Equals, 10)
+	c.Assert(stats.Removed, gc.Equals, state.LogPruneCounts{Entity: 2000 - remaining})
+	c.Assert(stats.Entities, jc.DeepEquals, []state.LogEntityPruneCount{{
+		EnvUUID: s.State.EnvironUUID(),
+		Entity:  noisy.String(),
+		Removed: 2000 - remaining,
+	}})
+
+	// The latest log records are kept.
+	var doc bson.M
+	err = s.logsColl.Find(bson.M{"n": noisy.String()}).Sort("-t").One(&doc)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(doc["t"].(time.Time), gc.Equals, now)
+}
+
+func (s *LogsSuite) TestPruneLogsWithPolicyByEntitySameTime(c *gc.C) {
+	// All the records are logged at the same time, so only their ids
+	// tell which are over the limit.
+	now := time.Now().Truncate(time.Millisecond)
+	dbLogger := state.NewDbLogger(s.State, names.NewMachineTag("0"))
+	defer dbLogger.Close()
+	msg := strings.Repeat("x", 1024)
+	for i := 0; i < 2000; i++ {
+		err := dbLogger.Log(now, "module", "loc", loggo.INFO, msg)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+	var newest bson.M
+	err := s.logsColl.Find(nil).Sort("-_id").One(&newest)
+	c.Assert(err, jc.ErrorIsNil)
+
+	stats, err := state.PruneLogsWithPolicy(s.State, now, state.LogPrunePolicy{
+		MaxAge:          24 * time.Hour,
+		MaxEntityMB:     1,
+		MaxCollectionMB: 100,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	remaining := s.countLogs(c, s.State)
+	c.Assert(remaining, jc.LessThan, 2000)
+	c.Assert(remaining, jc.GreaterThan, 0)
+	c.Assert(stats.Removed, gc.Equals, state.LogPruneCounts{Entity: 2000 - remaining})
+
+	// The latest log records are kept.
+	count, err := s.logsColl.FindId(newest["_id"]).Count()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(count, gc.Equals, 1)
+}
+
+func (s *LogsSuite) TestLogPruneStats(c *gc.C) {
+	_, err := state.GetLogPruneStats(s.State)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	dbLogger := state.NewDbLogger(s.State, names.NewMachineTag("22"))
+	defer dbLogger.Close()
+	now := time.Now().Truncate(time.Millisecond)
+	policy := state.LogPrunePolicy{
+		MaxAge:          time.Hour,
+		MaxCollectionMB: 100,
+	}
+	for i := 0; i < 2; i++ {
+		err := dbLogger.Log(now.Add(-2*time.Hour), "module", "loc", loggo.INFO, "prune")
+		c.Assert(err, jc.ErrorIsNil)
+		_, err = state.PruneLogsWithPolicy(s.State, now, policy)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	stats, err := state.GetLogPruneStats(s.State)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(stats.Time.Equal(now), jc.IsTrue)
+	c.Assert(stats.Removed, gc.Equals, state.LogPruneCounts{Age: 1})
+	c.Assert(stats.TotalRemoved, gc.Equals, state.LogPruneCounts{Age: 2})
+}
+
 func (s *LogsSuite) generateLogs(c *gc.C, st *state.State, endTime time.Time, count int) {
 	dbLogger := state.NewDbLogger(st, names.NewMachineTag("0"))
 	defer dbLogger.Close()
@@ -170,6 +314,46 @@ func (s *LogsSuite) countLogs(c *gc.C, st *state.State) int {
 	return count
 }
 
+func (s *LogsSuite) TestLastSentLogTracker(c *gc.C) {
+	tracker := state.NewLastSentLogTracker(s.State, "sink0")
+	defer tracker.Close()
+
+	_, err := tracker.Get()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `last log forwarded to "sink0" not found`)
+
+	id0 := bson.NewObjectId()
+	err = tracker.Set(id0)
+	c.Assert(err, jc.ErrorIsNil)
+	id1 := bson.NewObjectId()
+	err = tracker.Set(id1)
+	c.Assert(err, jc.ErrorIsNil)
+
+	lastSent, err := tracker.Get()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(lastSent, gc.Equals, id1)
+}
+
+func (s *LogsSuite) TestLastSentLogTrackerScoped(c *gc.C) {
+	tracker0 := state.NewLastSentLogTracker(s.State, "sink0")
+	defer tracker0.Close()
+	err := tracker0.Set(bson.NewObjectId())
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Other sinks and environments have their own records.
+	tracker1 := state.NewLastSentLogTracker(s.State, "sink1")
+	defer tracker1.Close()
+	_, err = tracker1.Get()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	st := s.Factory.MakeEnvironment(c, nil)
+	defer st.Close()
+	tracker2 := state.NewLastSentLogTracker(st, "sink0")
+	defer tracker2.Close()
+	_, err = tracker2.Get()
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
 type LogTailerSuite struct {
 	ConnSuite
 	logsColl  *mgo.Collection
@@ -219,6 +403,40 @@ func (s *LogTailerSuite) TestTimeFiltering(c *gc.C) {
 
 }
 
+func (s *LogTailerSuite) TestStartID(c *gc.C) {
+	s.writeLogs(c, 2, logTemplate{Message: "dont want"})
+	tailer := state.NewLogTailer(s.State, &state.LogTailerParams{
+		NoTail: true,
+		Oplog:  s.oplogColl,
+	})
+	var lastId bson.ObjectId
+	for log := range tailer.Logs() {
+		lastId = log.Id
+	}
+	c.Assert(tailer.Err(), jc.ErrorIsNil)
+	c.Assert(lastId, gc.Not(gc.Equals), bson.ObjectId(""))
+
+	// Logs recorded after the given one are returned in the order
+	// they were recorded, even those logged before it.
+	now := time.Now()
+	want := logTemplate{Message: "want"}
+	s.writeLogsT(c, now.Add(-time.Hour), now.Add(-time.Hour), 1, want)
+	want2 := logTemplate{Message: "want 2"}
+	s.writeLogs(c, 1, want2)
+	tailer = state.NewLogTailer(s.State, &state.LogTailerParams{
+		StartID: lastId,
+		Oplog:   s.oplogColl,
+	})
+	defer tailer.Stop()
+	s.assertTailer(c, tailer, 1, want)
+	s.assertTailer(c, tailer, 1, want2)
+
+	// Write more logs. These will be read from the the oplog.
+	want3 := logTemplate{Message: "want 3"}
+	s.writeLogsT(c, now.Add(-time.Hour), now.Add(-time.Hour), 1, want3)
+	s.assertTailer(c, tailer, 1, want3)
+}
+
 func (s *LogTailerSuite) TestOplogTransition(c *gc.C) {
 	// Ensure that logs aren't repeated as the log tailer moves from
 	// reading from the logs collection to tailing the oplog.
@@ -473,6 +691,69 @@ func (s *LogTailerSuite) TestIncludeExcludeModule(c *gc.C) {
 	s.checkLogTailerFiltering(params, writeLogs, assert)
 }
 
+func (s *LogTailerSuite) TestEndTimeFiltering(c *gc.C) {
+	threshT := time.Now()
+	want := logTemplate{Message: "want"}
+	s.writeLogsT(c, threshT.Add(-10*time.Second), threshT.Add(-6*time.Second), 5, want)
+	s.writeLogsT(c, threshT.Add(-5*time.Second), threshT.Add(-time.Second), 5, logTemplate{Message: "dont want"})
+
+	tailer := state.NewLogTailer(s.State, &state.LogTailerParams{
+		StartTime: threshT.Add(-time.Minute),
+		EndTime:   threshT.Add(-5 * time.Second),
+		Oplog:     s.oplogColl,
+	})
+	defer tailer.Stop()
+	s.assertTailer(c, tailer, 5, want)
+
+	// The end time has passed, so the tailer stops.
+	s.assertTailerStops(c, tailer)
+}
+
+func (s *LogTailerSuite) TestEndTimeStopsTailing(c *gc.C) {
+	tailer := state.NewLogTailer(s.State, &state.LogTailerParams{
+		EndTime: time.Now().Add(500 * time.Millisecond),
+		Oplog:   s.oplogColl,
+	})
+	defer tailer.Stop()
+
+	want := logTemplate{Message: "want"}
+	s.writeLogs(c, 2, want)
+	s.assertTailer(c, tailer, 2, want)
+	s.assertTailerStops(c, tailer)
+}
+
+func (s *LogTailerSuite) TestNoTail(c *gc.C) {
+	want := logTemplate{Message: "want"}
+	s.writeLogs(c, 3, want)
+
+	tailer := state.NewLogTailer(s.State, &state.LogTailerParams{
+		NoTail: true,
+		Oplog:  s.oplogColl,
+	})
+	defer tailer.Stop()
+	s.assertTailer(c, tailer, 3, want)
+	s.assertTailerStops(c, tailer)
+}
+
+func (s *LogTailerSuite) TestMessageRegexp(c *gc.C) {
+	hookFailed := logTemplate{Message: `hook "install" failed`}
+	hookFailedAgain := logTemplate{Message: `hook "start" failed: exit status 1`}
+	writeLogs := func() {
+		s.writeLogs(c, 1, logTemplate{Message: "hook succeeded"})
+		s.writeLogs(c, 2, hookFailed)
+		s.writeLogs(c, 1, logTemplate{Message: "failed"})
+		s.writeLogs(c, 1, hookFailedAgain)
+	}
+	params := &state.LogTailerParams{
+		MessageRegexp: `^hook .* failed`,
+	}
+	assert := func(tailer state.LogTailer) {
+		s.assertTailer(c, tailer, 2, hookFailed)
+		s.assertTailer(c, tailer, 1, hookFailedAgain)
+	}
+	s.checkLogTailerFiltering(params, writeLogs, assert)
+}
+
 func (s *LogTailerSuite) checkLogTailerFiltering(
 	params *state.LogTailerParams,
 	writeLogs func(),
@@ -554,6 +835,18 @@ func (s *LogTailerSuite) normaliseLogTemplate(lt *logTemplate) {
 	}
 }
 
+// assertTailerStops checks that the tailer stops without error
+// without returning any more logs.
+func (s *LogTailerSuite) assertTailerStops(c *gc.C, tailer state.LogTailer) {
+	select {
+	case log, ok := <-tailer.Logs():
+		c.Assert(ok, jc.IsFalse, gc.Commentf("unexpected log: %#v", log))
+		c.Assert(tailer.Err(), jc.ErrorIsNil)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for tailer to stop")
+	}
+}
+
 func (s *LogTailerSuite) assertTailer(c *gc.C, tailer state.LogTailer, expectedCount int, lt logTemplate) {
 	s.normaliseLogTemplate(&lt)
 
@@ -515,6 +515,11 @@ func (st *State) UpdateEnvironConfig(updateAttrs map[string]interface{}, removeA
 	if len(updateAttrs)+len(removeAttrs) == 0 {
 		return nil
 	}
+	if !st.IsStateServer() {
+		if err := checkHostedEnvironConfig(updateAttrs); err != nil {
+			return errors.Trace(err)
+		}
+	}
 
 	// TODO(axw) 2013-12-6 #1167616
 	// Ensure that the settings on disk have not changed
@@ -554,6 +559,28 @@ func (st *State) UpdateEnvironConfig(updateAttrs map[string]interface{}, removeA
 	return errors.Trace(err)
 }
 
+// stateServerOnlyAttrs holds the environment settings that apply to
+// all environments, and so may only be set in the state server
+// environment.
+var stateServerOnlyAttrs = []string{
+	config.LogMaxAgeKey,
+	config.LogLevelMaxAgesKey,
+	config.LogMaxEntityMBKey,
+	config.LogMaxCollectionMBKey,
+}
+
+// checkHostedEnvironConfig returns an error if the settings of an
+// environment other than the state server environment include any
+// that only the state server environment may have.
+func checkHostedEnvironConfig(attrs map[string]interface{}) error {
+	for _, name := range stateServerOnlyAttrs {
+		if _, ok := attrs[name]; ok {
+			return errors.Errorf("%s may only be set in the state server environment", name)
+		}
+	}
+	return nil
+}
+
 // EnvironConstraints returns the current environment constraints.
 func (st *State) EnvironConstraints() (constraints.Value, error) {
 	cons, err := readConstraints(st, environGlobalKey)
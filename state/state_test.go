@@ -2185,6 +2185,22 @@ func (s *StateSuite) TestEnvironConfig(c *gc.C) {
 	c.Assert(oldCfg, gc.DeepEquals, cfg)
 }
 
+func (s *StateSuite) TestUpdateEnvironConfigStateServerOnly(c *gc.C) {
+	attrs := map[string]interface{}{
+		"log-max-entity-mb": 10,
+	}
+	st2 := s.Factory.MakeEnvironment(c, nil)
+	defer st2.Close()
+	err := st2.UpdateEnvironConfig(attrs, nil, nil)
+	c.Assert(err, gc.ErrorMatches, "log-max-entity-mb may only be set in the state server environment")
+
+	err = s.State.UpdateEnvironConfig(attrs, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	cfg, err := s.State.EnvironConfig()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(cfg.LogMaxEntityMB(), gc.Equals, 10)
+}
+
 func (s *StateSuite) TestEnvironConstraints(c *gc.C) {
 	// Environ constraints start out empty (for now).
 	cons, err := s.State.EnvironConstraints()
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/juju/errors"
+	"github.com/juju/names"
 	jujutxn "github.com/juju/txn"
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
@@ -218,12 +219,50 @@ func probablyUpdateStatusHistory(st *State, globalKey string, doc statusDoc) {
 }
 
 func statusHistory(st *State, globalKey string, size int) ([]StatusInfo, error) {
+	return filteredStatusHistory(st, globalKey, StatusHistoryFilter{Size: size})
+}
+
+// StatusHistoryFilter specifies which entries of the status history of
+// an entity are returned.
+type StatusHistoryFilter struct {
+	// Size is the maximum number of entries returned, the newest
+	// being kept. If it is zero, all the entries are returned.
+	Size int
+
+	// FromDate, if not zero, excludes the entries recorded before it.
+	FromDate time.Time
+
+	// ToDate, if not zero, excludes the entries recorded at or after it.
+	ToDate time.Time
+}
+
+// filteredStatusHistory returns the entries of the status history of
+// the entity with the given global key that the filter selects, newest
+// first.
+func filteredStatusHistory(st *State, globalKey string, filter StatusHistoryFilter) ([]StatusInfo, error) {
+	if filter.Size < 0 {
+		return nil, errors.NotValidf("status history size %d", filter.Size)
+	}
 	statusHistory, closer := st.getCollection(statusesHistoryC)
 	defer closer()
 
+	selector := bson.D{{"globalkey", globalKey}}
+	updated := bson.D{}
+	if !filter.FromDate.IsZero() {
+		updated = append(updated, bson.DocElem{"$gte", filter.FromDate.UnixNano()})
+	}
+	if !filter.ToDate.IsZero() {
+		updated = append(updated, bson.DocElem{"$lt", filter.ToDate.UnixNano()})
+	}
+	if len(updated) > 0 {
+		selector = append(selector, bson.DocElem{"updated", updated})
+	}
 	var docs []historicalStatusDoc
-	query := statusHistory.Find(bson.D{{"globalkey", globalKey}})
-	err := query.Sort("-updated").Limit(size).All(&docs)
+	query := statusHistory.Find(selector).Sort("-updated")
+	if filter.Size > 0 {
+		query = query.Limit(filter.Size)
+	}
+	err := query.All(&docs)
 	if err == mgo.ErrNotFound {
 		return []StatusInfo{}, errors.NotFoundf("status history")
 	} else if err != nil {
@@ -242,6 +281,38 @@ func statusHistory(st *State, globalKey string, size int) ([]StatusInfo, error)
 	return results, nil
 }
 
+// StatusHistory returns the entries of the status history of the
+// entity with the given tag that the filter selects, newest first.
+// The status history of a unit is that of its workload; that of its
+// agent is returned by UnitAgentStatusHistory. Machines, services,
+// volumes and filesystems are supported too. The history of an
+// entity is kept for a while after it is removed.
+func (st *State) StatusHistory(tag names.Tag, filter StatusHistoryFilter) ([]StatusInfo, error) {
+	var globalKey string
+	switch tag := tag.(type) {
+	case names.UnitTag:
+		globalKey = unitGlobalKey(tag.Id())
+	case names.MachineTag:
+		globalKey = machineGlobalKey(tag.Id())
+	case names.ServiceTag:
+		globalKey = serviceGlobalKey(tag.Id())
+	case names.VolumeTag:
+		globalKey = volumeGlobalKey(tag.Id())
+	case names.FilesystemTag:
+		globalKey = filesystemGlobalKey(tag.Id())
+	default:
+		return nil, errors.NotSupportedf("status history of %s", names.ReadableString(tag))
+	}
+	return filteredStatusHistory(st, globalKey, filter)
+}
+
+// UnitAgentStatusHistory returns the entries of the status history of
+// the agent of the unit with the given tag that the filter selects,
+// newest first.
+func (st *State) UnitAgentStatusHistory(tag names.UnitTag, filter StatusHistoryFilter) ([]StatusInfo, error) {
+	return filteredStatusHistory(st, unitAgentGlobalKey(tag.Id()), filter)
+}
+
 // PruneStatusHistory removes status history entries until
 // only the maxLogsPerEntity newest records per unit remain.
 func PruneStatusHistory(st *State, maxLogsPerEntity int) error {
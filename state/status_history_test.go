@@ -4,6 +4,8 @@
 package state_test
 
 import (
+	"github.com/juju/errors"
+	"github.com/juju/names"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 
@@ -92,3 +94,81 @@ func (s *StatusHistorySuite) TestPruneStatusHistory(c *gc.C) {
 		checkPrimedUnitAgentStatus(c, statusInfo, 9-i)
 	}
 }
+
+func (s *StatusHistorySuite) TestStatusHistoryFilter(c *gc.C) {
+	unit := s.Factory.MakeUnit(c, nil)
+	primeUnitStatusHistory(c, unit, 5)
+
+	history, err := s.State.StatusHistory(unit.UnitTag(), state.StatusHistoryFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 6)
+	checkInitialWorkloadStatus(c, history[5])
+	for i, statusInfo := range history[:5] {
+		checkPrimedUnitStatus(c, statusInfo, 4-i)
+	}
+
+	sized, err := s.State.StatusHistory(unit.UnitTag(), state.StatusHistoryFilter{Size: 2})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(sized, jc.DeepEquals, history[:2])
+
+	ranged, err := s.State.StatusHistory(unit.UnitTag(), state.StatusHistoryFilter{
+		FromDate: *history[3].Since,
+		ToDate:   *history[1].Since,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ranged, jc.DeepEquals, history[2:4])
+
+	since, err := s.State.StatusHistory(unit.UnitTag(), state.StatusHistoryFilter{
+		Size:     10,
+		FromDate: *history[1].Since,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(since, jc.DeepEquals, history[:2])
+}
+
+func (s *StatusHistorySuite) TestUnitAgentStatusHistory(c *gc.C) {
+	unit := s.Factory.MakeUnit(c, nil)
+	primeUnitAgentStatusHistory(c, unit.Agent(), 3)
+
+	history, err := s.State.UnitAgentStatusHistory(unit.UnitTag(), state.StatusHistoryFilter{Size: 3})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 3)
+	for i, statusInfo := range history {
+		checkPrimedUnitAgentStatus(c, statusInfo, 2-i)
+	}
+}
+
+func (s *StatusHistorySuite) TestMachineStatusHistory(c *gc.C) {
+	machine := s.Factory.MakeMachine(c, nil)
+	err := machine.SetStatus(state.StatusStarted, "", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	history, err := s.State.StatusHistory(machine.MachineTag(), state.StatusHistoryFilter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 2)
+	c.Assert(history[0].Status, gc.Equals, state.StatusStarted)
+	c.Assert(history[1].Status, gc.Equals, state.StatusPending)
+}
+
+func (s *StatusHistorySuite) TestServiceStatusHistory(c *gc.C) {
+	service := s.Factory.MakeService(c, nil)
+	err := service.SetStatus(state.StatusActive, "running", nil)
+	c.Assert(err, jc.ErrorIsNil)
+
+	history, err := s.State.StatusHistory(service.Tag(), state.StatusHistoryFilter{Size: 1})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(history, gc.HasLen, 1)
+	c.Assert(history[0].Status, gc.Equals, state.StatusActive)
+	c.Assert(history[0].Message, gc.Equals, "running")
+}
+
+func (s *StatusHistorySuite) TestStatusHistoryUnsupportedTag(c *gc.C) {
+	_, err := s.State.StatusHistory(names.NewUserTag("bob"), state.StatusHistoryFilter{})
+	c.Assert(err, gc.ErrorMatches, `status history of user .*bob.* not supported`)
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *StatusHistorySuite) TestStatusHistoryBadSize(c *gc.C) {
+	_, err := s.State.StatusHistory(names.NewMachineTag("0"), state.StatusHistoryFilter{Size: -1})
+	c.Assert(err, gc.ErrorMatches, "status history size -1 not valid")
+}
@@ -98,7 +98,7 @@ func AddStateUsersAsEnvironUsers(st *State) error {
 
 		_, err := st.EnvironmentUser(uTag)
 		if err != nil && errors.IsNotFound(err) {
-			_, err = st.AddEnvironmentUser(uTag, uTag, "")
+			_, err = st.AddEnvironmentUser(uTag, uTag, "", EnvironmentAdminAccess)
 			if err != nil {
 				return errors.Trace(err)
 			}
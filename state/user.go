@@ -4,7 +4,9 @@
 // NOTE: the users that are being stored in the database here are only
 // the local users, like "admin" or "bob" (@local).  In the  world
 // where we have external user providers hooked up, there are no records
-// in the databse for users that are authenticated elsewhere.
+// in the databse for users that are authenticated elsewhere.  Local
+// users may still have their passwords checked by an identity provider
+// such as an LDAP directory; juju stores no password for them.
 
 package state
 
@@ -47,18 +49,40 @@ func (st *State) AddUser(name, displayName, password, creator string) (*User, er
 	if err != nil {
 		return nil, err
 	}
-	nameToLower := strings.ToLower(name)
+	return st.addUser(userDoc{
+		Name:         name,
+		DisplayName:  displayName,
+		PasswordHash: utils.UserPasswordHash(password, salt),
+		PasswordSalt: salt,
+		CreatedBy:    creator,
+	})
+}
+
+// AddExternalUser adds a user whose password is checked by the named
+// identity provider, such as an LDAP directory, rather than by juju.
+// No password is stored for the user.
+func (st *State) AddExternalUser(name, displayName, identityProvider, creator string) (*User, error) {
+	if !names.IsValidUserName(name) {
+		return nil, errors.Errorf("invalid user name %q", name)
+	}
+	if identityProvider == "" || identityProvider == localUserProviderName {
+		return nil, errors.Errorf("invalid identity provider %q", identityProvider)
+	}
+	return st.addUser(userDoc{
+		Name:             name,
+		DisplayName:      displayName,
+		IdentityProvider: identityProvider,
+		CreatedBy:        creator,
+	})
+}
+
+func (st *State) addUser(doc userDoc) (*User, error) {
+	nameToLower := strings.ToLower(doc.Name)
+	doc.DocID = nameToLower
+	doc.DateCreated = nowToTheSecond()
 	user := &User{
-		st: st,
-		doc: userDoc{
-			DocID:        nameToLower,
-			Name:         name,
-			DisplayName:  displayName,
-			PasswordHash: utils.UserPasswordHash(password, salt),
-			PasswordSalt: salt,
-			CreatedBy:    creator,
-			DateCreated:  nowToTheSecond(),
-		},
+		st:  st,
+		doc: doc,
 	}
 	ops := []txn.Op{{
 		C:      usersC,
@@ -66,7 +90,7 @@ func (st *State) AddUser(name, displayName, password, creator string) (*User, er
 		Assert: txn.DocMissing,
 		Insert: &user.doc,
 	}}
-	err = st.runTransaction(ops)
+	err := st.runTransaction(ops)
 	if err == txn.ErrAborted {
 		err = errors.AlreadyExistsf("user")
 	}
@@ -167,6 +191,9 @@ type userDoc struct {
 	PasswordSalt string    `bson:"passwordsalt"`
 	CreatedBy    string    `bson:"createdby"`
 	DateCreated  time.Time `bson:"datecreated"`
+	// IdentityProvider names what checks the passwords of users
+	// whose passwords juju does not store.
+	IdentityProvider string `bson:"identityprovider,omitempty"`
 }
 
 type userLastLoginDoc struct {
@@ -201,6 +228,12 @@ func (u *User) CreatedBy() string {
 	return u.doc.CreatedBy
 }
 
+// IdentityProvider returns the name of the identity provider that
+// checks the User's password, or "" if juju checks it.
+func (u *User) IdentityProvider() string {
+	return u.doc.IdentityProvider
+}
+
 // DateCreated returns when this User was created in UTC.
 func (u *User) DateCreated() time.Time {
 	return u.doc.DateCreated.UTC()
@@ -300,6 +333,9 @@ func (u *User) SetPassword(password string) error {
 
 // SetPasswordHash stores the hash and the salt of the password.
 func (u *User) SetPasswordHash(pwHash string, pwSalt string) error {
+	if u.doc.IdentityProvider != "" {
+		return errors.NotSupportedf("setting password of user %q authenticated by %s", u.Name(), u.doc.IdentityProvider)
+	}
 	ops := []txn.Op{{
 		C:      usersC,
 		Id:     u.Name(),
@@ -324,6 +360,11 @@ func (u *User) PasswordValid(password string) bool {
 	if u.IsDisabled() {
 		return false
 	}
+	// Juju has no password to check for users authenticated
+	// elsewhere.
+	if u.doc.IdentityProvider != "" {
+		return false
+	}
 	if u.doc.PasswordSalt != "" {
 		return utils.UserPasswordHash(password, u.doc.PasswordSalt) == u.doc.PasswordHash
 	}
@@ -75,6 +75,50 @@ func (s *UserSuite) TestAddUser(c *gc.C) {
 	c.Assert(lastLogin, gc.DeepEquals, time.Time{})
 }
 
+func (s *UserSuite) TestAddExternalUser(c *gc.C) {
+	user, err := s.State.AddExternalUser("bob", "Bob Brown", "ldap", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.Name(), gc.Equals, "bob")
+	c.Assert(user.DisplayName(), gc.Equals, "Bob Brown")
+	c.Assert(user.IdentityProvider(), gc.Equals, "ldap")
+	c.Assert(user.CreatedBy(), gc.Equals, "admin")
+
+	user, err = s.State.User(names.NewLocalUserTag("bob"))
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(user.IdentityProvider(), gc.Equals, "ldap")
+	// Juju stores no password for the user.
+	salt, hash := state.GetUserPasswordSaltAndHash(user)
+	c.Assert(salt, gc.Equals, "")
+	c.Assert(hash, gc.Equals, "")
+	c.Assert(user.PasswordValid(""), jc.IsFalse)
+}
+
+func (s *UserSuite) TestAddExternalUserInvalid(c *gc.C) {
+	_, err := s.State.AddExternalUser("b^b", "", "ldap", "admin")
+	c.Assert(err, gc.ErrorMatches, `invalid user name "b\^b"`)
+
+	_, err = s.State.AddExternalUser("bob", "", "", "admin")
+	c.Assert(err, gc.ErrorMatches, `invalid identity provider ""`)
+
+	_, err = s.State.AddExternalUser("bob", "", "local", "admin")
+	c.Assert(err, gc.ErrorMatches, `invalid identity provider "local"`)
+}
+
+func (s *UserSuite) TestAddExternalUserExisting(c *gc.C) {
+	s.Factory.MakeUser(c, &factory.UserParams{Name: "bob"})
+	_, err := s.State.AddExternalUser("bob", "", "ldap", "admin")
+	c.Assert(err, jc.Satisfies, errors.IsAlreadyExists)
+}
+
+func (s *UserSuite) TestExternalUserSetPassword(c *gc.C) {
+	user, err := s.State.AddExternalUser("bob", "", "ldap", "admin")
+	c.Assert(err, jc.ErrorIsNil)
+	err = user.SetPassword("sekrit")
+	c.Assert(err, gc.ErrorMatches, `setting password of user "bob" authenticated by ldap not supported`)
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+	c.Assert(user.PasswordValid("sekrit"), jc.IsFalse)
+}
+
 func (s *UserSuite) TestCheckUserExists(c *gc.C) {
 	user := s.Factory.MakeUser(c, nil)
 	exists, err := state.CheckUserExists(s.State, user.Name())
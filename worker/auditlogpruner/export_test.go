@@ -0,0 +1,18 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package auditlogpruner
+
+import (
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/worker"
+)
+
+func NewPruneWorker(st *state.State, params *AuditLogPrunerParams, t worker.NewTimerFunc, pal pruneAuditLogFunc) worker.Worker {
+	w := &pruneWorker{
+		st:     st,
+		params: params,
+		pruner: pal,
+	}
+	return worker.NewPeriodicWorker(w.doPruning, w.params.PruneInterval, t)
+}
@@ -0,0 +1,68 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package auditlogpruner
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/worker"
+)
+
+// AuditLogPrunerParams specifies how the audit log should be pruned.
+type AuditLogPrunerParams struct {
+	// MaxAge is how long audit records are kept.
+	MaxAge time.Duration
+
+	// MaxRecordsPerEnv is how many audit records are kept for each
+	// environment.
+	MaxRecordsPerEnv int
+
+	PruneInterval time.Duration
+}
+
+const (
+	DefaultMaxAge           = 30 * 24 * time.Hour
+	DefaultMaxRecordsPerEnv = 100000
+	DefaultPruneInterval    = 5 * time.Minute
+)
+
+// NewAuditLogPrunerParams returns an AuditLogPrunerParams initialized
+// with default parameters.
+func NewAuditLogPrunerParams() *AuditLogPrunerParams {
+	return &AuditLogPrunerParams{
+		MaxAge:           DefaultMaxAge,
+		MaxRecordsPerEnv: DefaultMaxRecordsPerEnv,
+		PruneInterval:    DefaultPruneInterval,
+	}
+}
+
+type pruneAuditLogFunc func(*state.State, time.Time, int) error
+
+type pruneWorker struct {
+	st     *state.State
+	params *AuditLogPrunerParams
+	pruner pruneAuditLogFunc
+}
+
+// New returns a worker.Worker that prunes the audit log.
+func New(st *state.State, params *AuditLogPrunerParams) worker.Worker {
+	w := &pruneWorker{
+		st:     st,
+		params: params,
+		pruner: state.PruneAuditLog,
+	}
+	return worker.NewPeriodicWorker(w.doPruning, w.params.PruneInterval, worker.NewTimer)
+}
+
+func (w *pruneWorker) doPruning(stop <-chan struct{}) error {
+	minTime := time.Now().Add(-w.params.MaxAge)
+	err := w.pruner(w.st, minTime, w.params.MaxRecordsPerEnv)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package auditlogpruner_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/auditlogpruner"
+)
+
+type mockTimer struct {
+	period time.Duration
+	c      chan time.Time
+}
+
+func (t *mockTimer) Reset(d time.Duration) bool {
+	t.period = d
+	return true
+}
+
+func (t *mockTimer) CountDown() <-chan time.Time {
+	return t.c
+}
+
+func (t *mockTimer) fire() error {
+	select {
+	case t.c <- time.Time{}:
+	case <-time.After(coretesting.LongWait):
+		return errors.New("timed out waiting for pruner to run")
+	}
+	return nil
+}
+
+func newMockTimer(d time.Duration) worker.PeriodicTimer {
+	return &mockTimer{period: d,
+		c: make(chan time.Time),
+	}
+}
+
+var _ = gc.Suite(&auditLogPrunerSuite{})
+
+type auditLogPrunerSuite struct {
+	coretesting.BaseSuite
+}
+
+func (s *auditLogPrunerSuite) TestWorker(c *gc.C) {
+	type pruneArgs struct {
+		minTime    time.Time
+		maxRecords int
+	}
+	pruned := make(chan pruneArgs, 1)
+	fakePruner := func(_ *state.State, minTime time.Time, maxRecords int) error {
+		pruned <- pruneArgs{minTime, maxRecords}
+		return nil
+	}
+	params := auditlogpruner.AuditLogPrunerParams{
+		MaxAge:           time.Hour,
+		MaxRecordsPerEnv: 3,
+		PruneInterval:    coretesting.ShortWait,
+	}
+	fakeTimer := newMockTimer(coretesting.LongWait)
+
+	fakeTimerFunc := func(d time.Duration) worker.PeriodicTimer {
+		// construction of timer should be with 0 because we intend it to
+		// run once before waiting.
+		c.Assert(d, gc.Equals, 0*time.Nanosecond)
+		return fakeTimer
+	}
+	pruner := auditlogpruner.NewPruneWorker(
+		&state.State{},
+		&params,
+		fakeTimerFunc,
+		fakePruner,
+	)
+	s.AddCleanup(func(*gc.C) {
+		pruner.Kill()
+		c.Assert(pruner.Wait(), jc.ErrorIsNil)
+	})
+	before := time.Now().Add(-time.Hour)
+	err := fakeTimer.(*mockTimer).fire()
+	c.Check(err, jc.ErrorIsNil)
+	select {
+	case args := <-pruned:
+		c.Assert(args.maxRecords, gc.Equals, 3)
+		c.Assert(args.minTime.Before(before), jc.IsFalse)
+		c.Assert(args.minTime.After(time.Now().Add(-time.Hour)), jc.IsFalse)
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for pruner to run")
+	}
+	// Reset will have been called with the actual PruneInterval
+	c.Assert(fakeTimer.(*mockTimer).period, gc.Equals, coretesting.ShortWait)
+}
@@ -0,0 +1,32 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backupscheduler
+
+import (
+	"io"
+	"time"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/backups"
+	"github.com/juju/juju/worker"
+)
+
+func NewScheduleWorker(
+	st *state.State,
+	interval time.Duration,
+	t worker.NewTimerFunc,
+	getSchedule func(*state.State) (backups.Schedule, error),
+	open func(*state.State) (backups.Backups, io.Closer),
+	create func(backups.Backups, *backups.EncryptionKey) error,
+	now func() time.Time,
+) worker.Worker {
+	w := &scheduleWorker{
+		st:          st,
+		getSchedule: getSchedule,
+		openBackups: open,
+		create:      create,
+		now:         now,
+	}
+	return worker.NewPeriodicWorker(w.run, interval, t)
+}
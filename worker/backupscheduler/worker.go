@@ -0,0 +1,116 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backupscheduler
+
+import (
+	"io"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/mongo"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/backups"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.backupscheduler")
+
+// DefaultInterval is how often the backup schedule is checked.
+const DefaultInterval = 10 * time.Minute
+
+type getScheduleFunc func(*state.State) (backups.Schedule, error)
+
+type openBackupsFunc func(*state.State) (backups.Backups, io.Closer)
+
+type createFunc func(backups.Backups, *backups.EncryptionKey) error
+
+type scheduleWorker struct {
+	st          *state.State
+	getSchedule getScheduleFunc
+	openBackups openBackupsFunc
+	create      createFunc
+	now         func() time.Time
+}
+
+// New returns a worker.Worker which periodically creates a backup of
+// the state server when one is due according to the backup schedule,
+// and removes the scheduled backups the retention policy of the
+// schedule no longer keeps.
+func New(st *state.State, paths backups.Paths, machineId string, interval time.Duration) worker.Worker {
+	w := &scheduleWorker{
+		st: st,
+		getSchedule: func(st *state.State) (backups.Schedule, error) {
+			return backups.GetSchedule(st)
+		},
+		openBackups: openBackups,
+		create: func(b backups.Backups, key *backups.EncryptionKey) error {
+			return createBackup(st, b, &paths, machineId, key)
+		},
+		now: time.Now,
+	}
+	return worker.NewPeriodicWorker(w.run, interval, worker.NewTimer)
+}
+
+func openBackups(st *state.State) (backups.Backups, io.Closer) {
+	stor := backups.NewStorage(st)
+	return backups.NewBackups(stor), stor
+}
+
+// createBackup creates a scheduled backup of the state server.
+func createBackup(st *state.State, b backups.Backups, paths *backups.Paths, machineId string, key *backups.EncryptionKey) error {
+	session := st.MongoSession().Copy()
+	defer session.Close()
+
+	dbInfo, err := backups.NewDBInfo(st.MongoConnectionInfo(), session)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	dbInfo.Oplog = mongo.GetOplog(session)
+	meta, err := backups.NewMetadataState(st, machineId)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	meta.Notes = backups.ScheduledNotes
+	meta.Scheduled = true
+	if err := b.Create(meta, paths, dbInfo, key); err != nil {
+		return errors.Trace(err)
+	}
+	logger.Infof("created scheduled backup %q", meta.ID())
+	return nil
+}
+
+func (w *scheduleWorker) run(stop <-chan struct{}) error {
+	schedule, err := w.getSchedule(w.st)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if schedule.Interval == 0 {
+		return nil
+	}
+
+	b, closer := w.openBackups(w.st)
+	defer closer.Close()
+
+	list, err := b.List()
+	if err != nil {
+		return errors.Annotate(err, "cannot list backups")
+	}
+	if schedule.Due(list, w.now()) {
+		// Failures are retried on the next run.
+		if err := w.create(b, schedule.Key()); err != nil {
+			logger.Errorf("cannot create scheduled backup: %v", err)
+		} else if list, err = b.List(); err != nil {
+			return errors.Annotate(err, "cannot list backups")
+		}
+	}
+	for _, meta := range schedule.Expired(list) {
+		logger.Infof("removing expired backup %q", meta.ID())
+		if err := b.Remove(meta.ID()); err != nil {
+			logger.Warningf("cannot remove expired backup %q: %v", meta.ID(), err)
+		}
+	}
+	return nil
+}
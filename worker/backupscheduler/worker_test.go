@@ -0,0 +1,163 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package backupscheduler_test
+
+import (
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/backups"
+	backupstesting "github.com/juju/juju/state/backups/testing"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/backupscheduler"
+)
+
+type mockTimer struct {
+	period time.Duration
+	c      chan time.Time
+}
+
+func (t *mockTimer) Reset(d time.Duration) bool {
+	t.period = d
+	return true
+}
+
+func (t *mockTimer) CountDown() <-chan time.Time {
+	return t.c
+}
+
+func (t *mockTimer) fire() error {
+	select {
+	case t.c <- time.Time{}:
+	case <-time.After(coretesting.LongWait):
+		return errors.New("timed out waiting for scheduler to run")
+	}
+	return nil
+}
+
+// mockBackups records the backups it removes, and no longer lists
+// them.
+type mockBackups struct {
+	backupstesting.FakeBackups
+	removed []string
+}
+
+func (b *mockBackups) Remove(id string) error {
+	b.removed = append(b.removed, id)
+	var left []*backups.Metadata
+	for _, meta := range b.MetaList {
+		if meta.ID() != id {
+			left = append(left, meta)
+		}
+	}
+	b.MetaList = left
+	return nil
+}
+
+var _ = gc.Suite(&backupSchedulerSuite{})
+
+type backupSchedulerSuite struct {
+	coretesting.BaseSuite
+	now     time.Time
+	backups *mockBackups
+	created []*backups.EncryptionKey
+}
+
+func (s *backupSchedulerSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.now = time.Date(2015, time.June, 10, 12, 0, 0, 0, time.UTC)
+	s.backups = &mockBackups{}
+	s.created = nil
+}
+
+func (s *backupSchedulerSuite) addBackup(id string, started time.Time) {
+	meta := backupstesting.NewMetadataStarted()
+	meta.SetID(id)
+	meta.Started = started
+	meta.Notes = backups.ScheduledNotes
+	meta.Scheduled = true
+	backupstesting.FinishMetadata(meta)
+	s.backups.MetaList = append(s.backups.MetaList, meta)
+}
+
+func (s *backupSchedulerSuite) runTwice(c *gc.C, schedule backups.Schedule, createErr error) {
+	timer := &mockTimer{c: make(chan time.Time)}
+	getSchedule := func(*state.State) (backups.Schedule, error) {
+		return schedule, nil
+	}
+	open := func(*state.State) (backups.Backups, io.Closer) {
+		return s.backups, ioutil.NopCloser(nil)
+	}
+	create := func(_ backups.Backups, key *backups.EncryptionKey) error {
+		s.created = append(s.created, key)
+		if createErr != nil {
+			return createErr
+		}
+		s.addBackup("new", s.now)
+		return nil
+	}
+	w := backupscheduler.NewScheduleWorker(
+		&state.State{},
+		coretesting.ShortWait,
+		func(time.Duration) worker.PeriodicTimer { return timer },
+		getSchedule,
+		open,
+		create,
+		func() time.Time { return s.now },
+	)
+	c.Assert(timer.fire(), jc.ErrorIsNil)
+	// The second run starts only once the first has completed.
+	c.Assert(timer.fire(), jc.ErrorIsNil)
+	w.Kill()
+	c.Assert(w.Wait(), jc.ErrorIsNil)
+	c.Assert(timer.period, gc.Equals, coretesting.ShortWait)
+}
+
+func (s *backupSchedulerSuite) TestDisabled(c *gc.C) {
+	s.addBackup("old", s.now.Add(-48*time.Hour))
+	s.runTwice(c, backups.Schedule{KeepDaily: 1}, nil)
+	c.Check(s.created, gc.HasLen, 0)
+	c.Check(s.backups.Calls, gc.HasLen, 0)
+	c.Check(s.backups.removed, gc.HasLen, 0)
+}
+
+func (s *backupSchedulerSuite) TestCreatesDueBackup(c *gc.C) {
+	s.addBackup("old", s.now.Add(-25*time.Hour))
+	schedule := backups.Schedule{Interval: 24 * time.Hour, PublicKey: "<public key>"}
+	s.runTwice(c, schedule, nil)
+	// The new backup is not due again on the second run.
+	c.Assert(s.created, gc.HasLen, 1)
+	c.Check(s.created[0], jc.DeepEquals, schedule.Key())
+	c.Check(s.backups.removed, gc.HasLen, 0)
+}
+
+func (s *backupSchedulerSuite) TestNotDue(c *gc.C) {
+	s.addBackup("recent", s.now.Add(-time.Hour))
+	s.runTwice(c, backups.Schedule{Interval: 24 * time.Hour}, nil)
+	c.Check(s.created, gc.HasLen, 0)
+}
+
+func (s *backupSchedulerSuite) TestRemovesExpiredBackups(c *gc.C) {
+	s.addBackup("yesterday-late", s.now.Add(-14*time.Hour))
+	s.addBackup("yesterday-early", s.now.Add(-20*time.Hour))
+	s.addBackup("last-week", s.now.Add(-7*24*time.Hour))
+	s.runTwice(c, backups.Schedule{Interval: 12 * time.Hour, KeepDaily: 2}, nil)
+	c.Check(s.created, gc.HasLen, 1)
+	c.Check(s.backups.removed, jc.SameContents, []string{"yesterday-early", "last-week"})
+}
+
+func (s *backupSchedulerSuite) TestCreateFailureStillPrunes(c *gc.C) {
+	s.addBackup("yesterday-late", s.now.Add(-14*time.Hour))
+	s.addBackup("yesterday-early", s.now.Add(-20*time.Hour))
+	s.runTwice(c, backups.Schedule{Interval: 12 * time.Hour, KeepDaily: 1}, errors.New("boom"))
+	c.Check(s.created, gc.HasLen, 2)
+	c.Check(s.backups.removed, jc.DeepEquals, []string{"yesterday-early"})
+}
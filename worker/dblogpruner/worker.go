@@ -7,12 +7,15 @@ import (
 	"time"
 
 	"github.com/juju/errors"
+	"github.com/juju/loggo"
 	"launchpad.net/tomb"
 
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/worker"
 )
 
+var logger = loggo.GetLogger("juju.worker.dblogpruner")
+
 // LogPruneParams specifies how logs should be pruned.
 type LogPruneParams struct {
 	MaxLogAge       time.Duration
@@ -36,7 +39,10 @@ func NewLogPruneParams() *LogPruneParams {
 
 // New returns a worker which periodically wakes up to remove old log
 // entries stored in MongoDB. This worker is intended to run just
-// once, on the MongoDB master.
+// once, on the MongoDB master. The log pruning settings of the
+// environment of st, which must be the state server environment, take
+// the place of those in params if set, and apply to the logs of all
+// environments.
 func New(st *state.State, params *LogPruneParams) worker.Worker {
 	w := &pruneWorker{
 		st:     st,
@@ -57,11 +63,37 @@ func (w *pruneWorker) loop(stopCh <-chan struct{}) error {
 		case <-stopCh:
 			return tomb.ErrDying
 		case <-time.After(p.PruneInterval):
-			minLogTime := time.Now().Add(-p.MaxLogAge)
-			err := state.PruneLogs(w.st, minLogTime, p.MaxCollectionMB)
+			policy, err := w.policy()
 			if err != nil {
 				return errors.Trace(err)
 			}
+			stats, err := state.PruneLogsWithPolicy(w.st, time.Now(), policy)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			logger.Debugf("pruned logs: %+v", stats.Removed)
 		}
 	}
 }
+
+// policy returns the log pruning policy from the worker params and the
+// environment config.
+func (w *pruneWorker) policy() (state.LogPrunePolicy, error) {
+	cfg, err := w.st.EnvironConfig()
+	if err != nil {
+		return state.LogPrunePolicy{}, errors.Trace(err)
+	}
+	policy := state.LogPrunePolicy{
+		MaxAge:          w.params.MaxLogAge,
+		LevelMaxAges:    cfg.LogLevelMaxAges(),
+		MaxEntityMB:     cfg.LogMaxEntityMB(),
+		MaxCollectionMB: w.params.MaxCollectionMB,
+	}
+	if maxAge, ok := cfg.LogMaxAge(); ok {
+		policy.MaxAge = maxAge
+	}
+	if maxCollectionMB, ok := cfg.LogMaxCollectionMB(); ok {
+		policy.MaxCollectionMB = maxCollectionMB
+	}
+	return policy, nil
+}
@@ -7,6 +7,7 @@ import (
 	stdtesting "testing"
 	"time"
 
+	"github.com/juju/errors"
 	"github.com/juju/loggo"
 	"github.com/juju/names"
 	jc "github.com/juju/testing/checkers"
@@ -106,6 +107,44 @@ func (s *suite) TestPrunesLogsBySize(c *gc.C) {
 	c.Fatal("pruning didn't happen as expected")
 }
 
+func (s *suite) TestConfigOverridesParams(c *gc.C) {
+	err := s.State.UpdateEnvironConfig(map[string]interface{}{
+		"log-max-age":        "24h",
+		"log-level-max-ages": "DEBUG=1h",
+	}, nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	noPruneAge := 999 * time.Hour
+	noPruneMB := int(1e9)
+	s.StartWorker(c, noPruneAge, noPruneMB)
+
+	now := time.Now()
+	s.addLogs(c, now, "keep", 5)
+	s.addLogs(c, now.Add(-25*time.Hour), "prune", 5)
+	dbLogger := state.NewDbLogger(s.State, names.NewMachineTag("0"))
+	defer dbLogger.Close()
+	err = dbLogger.Log(now.Add(-2*time.Hour), "some.module", "foo.go:42", loggo.DEBUG, "prune")
+	c.Assert(err, jc.ErrorIsNil)
+
+	// Wait for the pruning of the "prune" messages to be recorded.
+	for attempt := testing.LongAttempt.Start(); attempt.Next(); {
+		stats, err := state.GetLogPruneStats(s.State)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		c.Assert(err, jc.ErrorIsNil)
+		if stats.TotalRemoved == (state.LogPruneCounts{Age: 5, Level: 1}) {
+			pruneRemaining, err := s.logsColl.Find(bson.M{"x": "prune"}).Count()
+			c.Assert(err, jc.ErrorIsNil)
+			c.Assert(pruneRemaining, gc.Equals, 0)
+			keepCount, err := s.logsColl.Find(bson.M{"x": "keep"}).Count()
+			c.Assert(err, jc.ErrorIsNil)
+			c.Assert(keepCount, gc.Equals, 5)
+			return
+		}
+	}
+	c.Fatal("pruning didn't happen as expected")
+}
+
 func (s *suite) addLogs(c *gc.C, t0 time.Time, text string, count int) {
 	dbLogger := state.NewDbLogger(s.State, names.NewMachineTag("0"))
 	defer dbLogger.Close()
@@ -0,0 +1,30 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logforwarder
+
+import (
+	"time"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/worker"
+)
+
+var SendTimeout = &sendTimeout
+
+func NewForwardWorker(
+	st *state.State,
+	saveInterval time.Duration,
+	openSink func(rawurl, caCert string) (Sink, error),
+	newLogTailer func(state.LoggingState, *state.LogTailerParams) state.LogTailer,
+	now func() time.Time,
+) worker.Worker {
+	w := &forwardWorker{
+		st:           st,
+		saveInterval: saveInterval,
+		openSink:     openSink,
+		newLogTailer: newLogTailer,
+		now:          now,
+	}
+	return worker.NewSimpleWorker(w.loop)
+}
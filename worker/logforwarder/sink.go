@@ -0,0 +1,257 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logforwarder
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/state"
+)
+
+const (
+	// defaultSyslogTCPPort is the port syslog servers listen on for
+	// messages over TCP when none is given.
+	defaultSyslogTCPPort = "514"
+
+	// defaultSyslogTLSPort is the port syslog servers listen on for
+	// messages over TLS when none is given, as assigned by RFC5425.
+	defaultSyslogTLSPort = "6514"
+
+	// syslogFacility is the syslog facility log records are sent
+	// with, that of user-level messages.
+	syslogFacility = 1
+
+	// syslogSDID identifies the structured data holding the juju
+	// specific fields of log records sent to syslog. 28978 is the
+	// private enterprise number of Canonical.
+	syslogSDID = "juju@28978"
+)
+
+// sendTimeout is how long a sink waits to connect to its endpoint, and
+// for a batch of log records to be sent, before giving up.
+var sendTimeout = time.Minute
+
+// Sink is an endpoint that log records are forwarded to.
+type Sink interface {
+	// Send forwards the log records, logged in the environment with
+	// the given UUID, in order.
+	Send(envUUID string, recs []*state.LogRecord) error
+
+	// Close releases the resources used by the sink.
+	Close() error
+}
+
+// OpenSink returns a Sink that forwards log records to the endpoint
+// with the given URL, as accepted by the log-forward-url setting. If
+// caCert is not empty, the TLS certificate of the endpoint is checked
+// with it rather than the system's CA certificates.
+func OpenSink(rawurl, caCert string) (Sink, error) {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot parse log forwarding URL")
+	}
+	tlsConfig, err := sinkTLSConfig(caCert)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	switch u.Scheme {
+	case config.LogForwardSyslogTCP:
+		conn, err := net.DialTimeout("tcp", hostPort(u.Host, defaultSyslogTCPPort), sendTimeout)
+		if err != nil {
+			return nil, errors.Annotate(err, "cannot connect to syslog server")
+		}
+		return &syslogSink{conn: conn}, nil
+	case config.LogForwardSyslogTLS:
+		dialer := &net.Dialer{Timeout: sendTimeout}
+		conn, err := tls.DialWithDialer(dialer, "tcp", hostPort(u.Host, defaultSyslogTLSPort), tlsConfig)
+		if err != nil {
+			return nil, errors.Annotate(err, "cannot connect to syslog server")
+		}
+		return &syslogSink{conn: conn}, nil
+	case config.LogForwardHTTP, config.LogForwardHTTPS:
+		client := &http.Client{
+			Transport: &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsConfig,
+			},
+			Timeout: sendTimeout,
+		}
+		return &httpSink{client: client, url: u.String()}, nil
+	}
+	return nil, errors.NotSupportedf("log forwarding URL scheme %q", u.Scheme)
+}
+
+// sinkTLSConfig returns the TLS configuration sinks connect with.
+func sinkTLSConfig(caCert string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+	if caCert != "" {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(caCert)) {
+			return nil, errors.New("cannot parse log forwarding CA certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+// hostPort returns the host and port of the URL host, adding the
+// given port if it has none.
+func hostPort(host, defaultPort string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(strings.Trim(host, "[]"), defaultPort)
+}
+
+// syslogSink forwards log records to a syslog server as RFC5424
+// messages, framed by octet counting as described in RFC5425.
+type syslogSink struct {
+	conn net.Conn
+}
+
+// Send implements Sink, writing the messages of all the records at
+// once.
+func (s *syslogSink) Send(envUUID string, recs []*state.LogRecord) error {
+	var buf bytes.Buffer
+	for _, rec := range recs {
+		msg := FormatSyslog(envUUID, rec)
+		fmt.Fprintf(&buf, "%d %s", len(msg), msg)
+	}
+	// A syslog server that stops reading would otherwise block the
+	// write, and so the forwarding of logs, forever.
+	if err := s.conn.SetWriteDeadline(time.Now().Add(sendTimeout)); err != nil {
+		return errors.Annotate(err, "cannot send log records to syslog server")
+	}
+	_, err := s.conn.Write(buf.Bytes())
+	return errors.Annotate(err, "cannot send log records to syslog server")
+}
+
+// Close implements Sink.
+func (s *syslogSink) Close() error {
+	return s.conn.Close()
+}
+
+// FormatSyslog returns the log record as an RFC5424 syslog message.
+// The host name of the message is the tag of the entity that logged
+// the record, and the module and location of the record and the
+// environment UUID are sent as structured data.
+func FormatSyslog(envUUID string, rec *state.LogRecord) string {
+	return fmt.Sprintf("<%d>1 %s %s juju - - [%s module=\"%s\" location=\"%s\" env-uuid=\"%s\"] %s",
+		syslogFacility*8+syslogSeverity(rec.Level),
+		rec.Time.UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+		syslogHeaderValue(rec.Entity),
+		syslogSDID,
+		sdParamEscaper.Replace(rec.Module),
+		sdParamEscaper.Replace(rec.Location),
+		sdParamEscaper.Replace(envUUID),
+		rec.Message,
+	)
+}
+
+// syslogSeverity returns the syslog severity of the logging level.
+func syslogSeverity(level loggo.Level) int {
+	switch level {
+	case loggo.CRITICAL:
+		return 2
+	case loggo.ERROR:
+		return 3
+	case loggo.WARNING:
+		return 4
+	case loggo.INFO:
+		return 6
+	}
+	return 7
+}
+
+// syslogHeaderValue returns the value as a syslog header field, which
+// must be printable ASCII without spaces, or "-" if empty.
+func syslogHeaderValue(value string) string {
+	value = strings.Map(func(r rune) rune {
+		if r <= ' ' || r > '~' {
+			return '_'
+		}
+		return r
+	}, value)
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// sdParamEscaper escapes the characters that may not appear unescaped
+// in syslog structured data parameter values.
+var sdParamEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// httpSink forwards log records to an HTTP endpoint, POSTing each batch
+// of them as a JSON array of HTTPRecord objects.
+type httpSink struct {
+	client *http.Client
+	url    string
+}
+
+// HTTPRecord is the JSON object sent to HTTP endpoints for each log
+// record forwarded. It holds the fields of the records sent by the
+// debug-log API, and the UUID of the environment the record was
+// logged in.
+type HTTPRecord struct {
+	params.DebugLogRecord
+	EnvUUID string `json:"env-uuid"`
+}
+
+// Send implements Sink, POSTing all the records in one request.
+func (s *httpSink) Send(envUUID string, recs []*state.LogRecord) error {
+	records := make([]HTTPRecord, len(recs))
+	for i, rec := range recs {
+		records[i] = HTTPRecord{
+			DebugLogRecord: params.DebugLogRecord{
+				Entity:    rec.Entity,
+				Timestamp: rec.Time.UTC(),
+				Level:     rec.Level.String(),
+				Module:    rec.Module,
+				Location:  rec.Location,
+				Message:   rec.Message,
+			},
+			EnvUUID: envUUID,
+		}
+	}
+	data, err := json.Marshal(records)
+	if err != nil {
+		return errors.Annotate(err, "cannot marshal log records")
+	}
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return errors.Annotate(err, "cannot send log records")
+	}
+	defer resp.Body.Close()
+	// Read the body so that the connection may be reused.
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("cannot send log records: %s", resp.Status)
+	}
+	return nil
+}
+
+// Close implements Sink.
+func (s *httpSink) Close() error {
+	if transport, ok := s.client.Transport.(*http.Transport); ok {
+		transport.CloseIdleConnections()
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package logforwarder_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"time"
+
+	"github.com/juju/loggo"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker/logforwarder"
+)
+
+type sinkSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&sinkSuite{})
+
+var testRecord = &state.LogRecord{
+	Time:     time.Date(2015, 6, 19, 15, 34, 37, 123456789, time.UTC),
+	Entity:   "unit-mysql-0",
+	Module:   "juju.worker.uniter",
+	Location: "uniter.go:42",
+	Level:    loggo.ERROR,
+	Message:  `hook "install" failed`,
+}
+
+func (s *sinkSuite) TestFormatSyslog(c *gc.C) {
+	msg := logforwarder.FormatSyslog("some-uuid", testRecord)
+	c.Assert(msg, gc.Equals, `<11>1 2015-06-19T15:34:37.123456Z unit-mysql-0 juju - - `+
+		`[juju@28978 module="juju.worker.uniter" location="uniter.go:42" env-uuid="some-uuid"] `+
+		`hook "install" failed`)
+}
+
+func (s *sinkSuite) TestFormatSyslogEscaping(c *gc.C) {
+	rec := *testRecord
+	rec.Entity = ""
+	rec.Level = loggo.INFO
+	rec.Module = `odd"module]`
+	rec.Location = `C:\code.go:1`
+	msg := logforwarder.FormatSyslog("some-uuid", &rec)
+	c.Assert(msg, gc.Equals, `<14>1 2015-06-19T15:34:37.123456Z - juju - - `+
+		`[juju@28978 module="odd\"module\]" location="C:\\code.go:1" env-uuid="some-uuid"] `+
+		`hook "install" failed`)
+}
+
+func (s *sinkSuite) TestSyslogSink(c *gc.C) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, jc.ErrorIsNil)
+	defer listener.Close()
+	received := make(chan string, 2)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		for {
+			// Messages are framed by octet counting.
+			length, err := reader.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, err := strconv.Atoi(length[:len(length)-1])
+			if err != nil {
+				return
+			}
+			msg := make([]byte, n)
+			if _, err := io.ReadFull(reader, msg); err != nil {
+				return
+			}
+			received <- string(msg)
+		}
+	}()
+
+	sink, err := logforwarder.OpenSink("syslog+tcp://"+listener.Addr().String(), "")
+	c.Assert(err, jc.ErrorIsNil)
+	defer sink.Close()
+	err = sink.Send("some-uuid", []*state.LogRecord{testRecord, testRecord})
+	c.Assert(err, jc.ErrorIsNil)
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-received:
+			c.Assert(msg, gc.Equals, logforwarder.FormatSyslog("some-uuid", testRecord))
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("timed out waiting for syslog message")
+		}
+	}
+}
+
+func (s *sinkSuite) TestSyslogSinkConnectionRefused(c *gc.C) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	c.Assert(err, jc.ErrorIsNil)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	_, err = logforwarder.OpenSink("syslog+tcp://"+addr, "")
+	c.Assert(err, gc.ErrorMatches, "cannot connect to syslog server: .*")
+}
+
+func (s *sinkSuite) TestHTTPSink(c *gc.C) {
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		received <- req
+		bodies <- body
+	}))
+	defer server.Close()
+
+	sink, err := logforwarder.OpenSink(server.URL+"/ingest", "")
+	c.Assert(err, jc.ErrorIsNil)
+	defer sink.Close()
+	err = sink.Send("some-uuid", []*state.LogRecord{testRecord})
+	c.Assert(err, jc.ErrorIsNil)
+
+	req := <-received
+	c.Assert(req.Method, gc.Equals, "POST")
+	c.Assert(req.URL.Path, gc.Equals, "/ingest")
+	c.Assert(req.Header.Get("Content-Type"), gc.Equals, "application/json")
+	var records []map[string]interface{}
+	err = json.Unmarshal(<-bodies, &records)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(records, jc.DeepEquals, []map[string]interface{}{{
+		"entity":    "unit-mysql-0",
+		"timestamp": "2015-06-19T15:34:37.123456789Z",
+		"level":     "ERROR",
+		"module":    "juju.worker.uniter",
+		"location":  "uniter.go:42",
+		"message":   `hook "install" failed`,
+		"env-uuid":  "some-uuid",
+	}})
+}
+
+func (s *sinkSuite) TestHTTPSinkErrorStatus(c *gc.C) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		http.Error(w, "go away", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sink, err := logforwarder.OpenSink(server.URL, "")
+	c.Assert(err, jc.ErrorIsNil)
+	defer sink.Close()
+	err = sink.Send("some-uuid", []*state.LogRecord{testRecord})
+	c.Assert(err, gc.ErrorMatches, "cannot send log records: 503 Service Unavailable")
+}
+
+func (s *sinkSuite) TestHTTPSinkTimeout(c *gc.C) {
+	s.PatchValue(logforwarder.SendTimeout, 10*time.Millisecond)
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	sink, err := logforwarder.OpenSink(server.URL, "")
+	c.Assert(err, jc.ErrorIsNil)
+	defer sink.Close()
+	err = sink.Send("some-uuid", []*state.LogRecord{testRecord})
+	c.Assert(err, gc.ErrorMatches, "cannot send log records: .*")
+}
+
+func (s *sinkSuite) TestOpenSinkBadCACert(c *gc.C) {
+	_, err := logforwarder.OpenSink("https://logs.example.com", "rubbish")
+	c.Assert(err, gc.ErrorMatches, "cannot parse log forwarding CA certificate")
+}
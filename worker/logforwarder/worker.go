@@ -0,0 +1,263 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package logforwarder provides a worker that forwards the logs an
+// environment stores in the database to a syslog server or an HTTP
+// endpoint.
+package logforwarder
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	"gopkg.in/mgo.v2/bson"
+	"launchpad.net/tomb"
+
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/state/watcher"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.logforwarder")
+
+// DefaultSaveInterval is how often the id of the last log record
+// forwarded is recorded while records are being forwarded.
+const DefaultSaveInterval = 5 * time.Second
+
+// maxBatchSize is the largest number of log records sent to an
+// endpoint at once.
+const maxBatchSize = 500
+
+type openSinkFunc func(rawurl, caCert string) (Sink, error)
+
+type newLogTailerFunc func(state.LoggingState, *state.LogTailerParams) state.LogTailer
+
+// forwardWorker forwards the logs of an environment to the endpoint
+// configured in its log-forward-url setting, following changes to it.
+type forwardWorker struct {
+	st           *state.State
+	saveInterval time.Duration
+	openSink     openSinkFunc
+	newLogTailer newLogTailerFunc
+	now          func() time.Time
+}
+
+// New returns a worker.Worker which forwards the logs of the
+// environment to the endpoint configured in its log-forward-url
+// setting, if any. The id of the last log record forwarded to each
+// endpoint is recorded, so that forwarding carries on from there when
+// the worker restarts. Log records are forwarded in the order they are
+// recorded, so records that reach the database late are not skipped.
+// Forwarding to a new endpoint starts with the records recorded after
+// it is configured.
+func New(st *state.State) worker.Worker {
+	w := &forwardWorker{
+		st:           st,
+		saveInterval: DefaultSaveInterval,
+		openSink:     OpenSink,
+		newLogTailer: state.NewLogTailer,
+		now:          time.Now,
+	}
+	return worker.NewSimpleWorker(w.loop)
+}
+
+func (w *forwardWorker) loop(stop <-chan struct{}) (err error) {
+	configWatcher := w.st.WatchForEnvironConfigChanges()
+	defer func() {
+		if stopErr := configWatcher.Stop(); err == nil {
+			err = stopErr
+		}
+	}()
+
+	var fwd *forwarder
+	defer func() {
+		if fwd != nil {
+			if closeErr := fwd.close(); err == nil {
+				err = closeErr
+			}
+		}
+	}()
+
+	saveTicker := time.NewTicker(w.saveInterval)
+	defer saveTicker.Stop()
+	for {
+		var logs <-chan *state.LogRecord
+		if fwd != nil {
+			logs = fwd.tailer.Logs()
+		}
+		select {
+		case <-stop:
+			return tomb.ErrDying
+		case _, ok := <-configWatcher.Changes():
+			if !ok {
+				return watcher.EnsureErr(configWatcher)
+			}
+			cfg, err := w.st.EnvironConfig()
+			if err != nil {
+				return errors.Trace(err)
+			}
+			target := forwardTarget{url: cfg.LogForwardURL(), caCert: cfg.LogForwardCACert()}
+			if fwd != nil && fwd.target == target {
+				continue
+			}
+			if fwd != nil {
+				if err := fwd.close(); err != nil {
+					return errors.Trace(err)
+				}
+				fwd = nil
+			}
+			if target.url == "" {
+				logger.Infof("not forwarding logs")
+				continue
+			}
+			if fwd, err = w.startForwarding(target); err != nil {
+				return errors.Trace(err)
+			}
+		case rec, ok := <-logs:
+			if !ok {
+				return errors.Annotate(fwd.tailer.Err(), "log tailer stopped")
+			}
+			// Send the records already waiting along with this one.
+			recs := appendReady([]*state.LogRecord{rec}, logs)
+			if err := fwd.send(recs); err != nil {
+				return errors.Trace(err)
+			}
+		case <-saveTicker.C:
+			if fwd != nil {
+				if err := fwd.save(); err != nil {
+					return errors.Trace(err)
+				}
+			}
+		}
+	}
+}
+
+// appendReady appends to recs the log records that can be received
+// from logs without waiting, up to maxBatchSize records in all.
+func appendReady(recs []*state.LogRecord, logs <-chan *state.LogRecord) []*state.LogRecord {
+	for len(recs) < maxBatchSize {
+		select {
+		case rec, ok := <-logs:
+			if !ok {
+				// The closed channel is noticed on the next receive.
+				return recs
+			}
+			recs = append(recs, rec)
+		default:
+			return recs
+		}
+	}
+	return recs
+}
+
+// forwardTarget holds the settings of the endpoint logs are forwarded
+// to.
+type forwardTarget struct {
+	url    string
+	caCert string
+}
+
+// startForwarding starts forwarding log records to the target, from
+// the last one forwarded to it.
+func (w *forwardWorker) startForwarding(target forwardTarget) (_ *forwarder, err error) {
+	name := sinkName(target.url)
+	tracker := state.NewLastSentLogTracker(w.st, name)
+	defer func() {
+		if err != nil {
+			tracker.Close()
+		}
+	}()
+	lastSent, err := tracker.Get()
+	if errors.IsNotFound(err) {
+		lastSent = bson.NewObjectIdWithTime(w.now())
+		if err := tracker.Set(lastSent); err != nil {
+			return nil, errors.Trace(err)
+		}
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	sink, err := w.openSink(target.url, target.caCert)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot forward logs to %q", name)
+	}
+	logger.Infof("forwarding logs to %q after log %s", name, lastSent.Hex())
+	tailer := w.newLogTailer(w.st, &state.LogTailerParams{
+		StartID: lastSent,
+	})
+	return &forwarder{
+		target:   target,
+		name:     name,
+		envUUID:  w.st.EnvironUUID(),
+		sink:     sink,
+		tailer:   tailer,
+		tracker:  tracker,
+		lastSent: lastSent,
+		saved:    true,
+	}, nil
+}
+
+// sinkName returns the URL of the endpoint logs are forwarded to
+// without any credentials it holds, to be logged and to record the
+// last log record forwarded to it under.
+func sinkName(rawurl string) string {
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		// The log-forward-url setting is checked when set, but the
+		// URL is not shown in case it holds credentials.
+		return "invalid URL"
+	}
+	u.User = nil
+	return u.String()
+}
+
+// forwarder forwards log records to a single target.
+type forwarder struct {
+	target   forwardTarget
+	name     string
+	envUUID  string
+	sink     Sink
+	tailer   state.LogTailer
+	tracker  *state.LastSentLogTracker
+	lastSent bson.ObjectId
+	saved    bool
+}
+
+// send forwards the log records.
+func (f *forwarder) send(recs []*state.LogRecord) error {
+	if err := f.sink.Send(f.envUUID, recs); err != nil {
+		return errors.Annotatef(err, "cannot forward logs to %q", f.name)
+	}
+	f.lastSent = recs[len(recs)-1].Id
+	f.saved = false
+	return nil
+}
+
+// save records the id of the last log record forwarded, if it has
+// not been already.
+func (f *forwarder) save() error {
+	if f.saved {
+		return nil
+	}
+	if err := f.tracker.Set(f.lastSent); err != nil {
+		return errors.Trace(err)
+	}
+	f.saved = true
+	return nil
+}
+
+// close stops forwarding, recording the id of the last log record
+// forwarded.
+func (f *forwarder) close() error {
+	err := f.save()
+	if stopErr := f.tailer.Stop(); err == nil {
+		err = stopErr
+	}
+	if closeErr := f.sink.Close(); err == nil {
+		err = closeErr
+	}
+	f.tracker.Close()
+	return errors.Trace(err)
+}
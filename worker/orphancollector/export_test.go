@@ -0,0 +1,28 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package orphancollector
+
+import (
+	"time"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/worker"
+)
+
+func NewCollectWorker(
+	st *state.State,
+	interval time.Duration,
+	t worker.NewTimerFunc,
+	find func(*state.State) (environs.OrphanCollector, []environs.Orphan, error),
+	mode func(*state.State) (config.HarvestMode, error),
+) worker.Worker {
+	w := &collectWorker{
+		st:          st,
+		findOrphans: find,
+		harvestMode: mode,
+	}
+	return worker.NewPeriodicWorker(w.collect, interval, t)
+}
@@ -0,0 +1,81 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package orphancollector
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/state"
+	"github.com/juju/juju/worker"
+)
+
+var logger = loggo.GetLogger("juju.worker.orphancollector")
+
+// DefaultInterval is how often the environment is checked for orphans.
+const DefaultInterval = time.Hour
+
+type findOrphansFunc func(*state.State) (environs.OrphanCollector, []environs.Orphan, error)
+
+type harvestModeFunc func(*state.State) (config.HarvestMode, error)
+
+type collectWorker struct {
+	st          *state.State
+	findOrphans findOrphansFunc
+	harvestMode harvestModeFunc
+}
+
+// New returns a worker.Worker which periodically looks for provider
+// resources of the environment that back no machine or volume in
+// state. The orphans are removed if the environment's harvest mode
+// includes unknown instances, and only reported otherwise.
+func New(st *state.State, interval time.Duration) worker.Worker {
+	w := &collectWorker{
+		st:          st,
+		findOrphans: environs.FindOrphans,
+		harvestMode: stateHarvestMode,
+	}
+	return worker.NewPeriodicWorker(w.collect, interval, worker.NewTimer)
+}
+
+func stateHarvestMode(st *state.State) (config.HarvestMode, error) {
+	envConfig, err := st.EnvironConfig()
+	if err != nil {
+		return 0, errors.Annotate(err, "cannot get environment config")
+	}
+	return envConfig.ProvisionerHarvestMode(), nil
+}
+
+func (w *collectWorker) collect(stop <-chan struct{}) error {
+	collector, orphans, err := w.findOrphans(w.st)
+	if errors.IsNotSupported(err) {
+		logger.Tracef("not collecting orphans: %v", err)
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	if len(orphans) == 0 {
+		return nil
+	}
+	mode, err := w.harvestMode(w.st)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !mode.HarvestUnknown() {
+		for _, o := range orphans {
+			logger.Warningf("found orphaned %s %q (%s); set %s to %q to remove it",
+				o.Kind, o.Id, o.Name, config.ProvisionerHarvestModeKey, config.HarvestUnknown.String())
+		}
+		return nil
+	}
+	// Failures are retried on the next run.
+	if err := collector.RemoveOrphans(orphans); err != nil {
+		logger.Warningf("cannot remove all orphans: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2015 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package orphancollector_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/state"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/orphancollector"
+)
+
+type mockTimer struct {
+	period time.Duration
+	c      chan time.Time
+}
+
+func (t *mockTimer) Reset(d time.Duration) bool {
+	t.period = d
+	return true
+}
+
+func (t *mockTimer) CountDown() <-chan time.Time {
+	return t.c
+}
+
+func (t *mockTimer) fire() error {
+	select {
+	case t.c <- time.Time{}:
+	case <-time.After(coretesting.LongWait):
+		return errors.New("timed out waiting for collector to run")
+	}
+	return nil
+}
+
+type mockCollector struct {
+	removed []environs.Orphan
+}
+
+func (*mockCollector) Orphans(environs.OrphanParams) ([]environs.Orphan, error) {
+	return nil, errors.New("not called by the worker")
+}
+
+func (m *mockCollector) RemoveOrphans(orphans []environs.Orphan) error {
+	m.removed = append(m.removed, orphans...)
+	return nil
+}
+
+var _ = gc.Suite(&orphanCollectorSuite{})
+
+type orphanCollectorSuite struct {
+	coretesting.BaseSuite
+}
+
+var testOrphans = []environs.Orphan{{Kind: "drive", Id: "uuid-0", Name: "juju-env-2"}}
+
+func (s *orphanCollectorSuite) runTwice(c *gc.C, mode config.HarvestMode, findErr error) *mockCollector {
+	collector := &mockCollector{}
+	timer := &mockTimer{c: make(chan time.Time)}
+	find := func(*state.State) (environs.OrphanCollector, []environs.Orphan, error) {
+		if findErr != nil {
+			return nil, nil, findErr
+		}
+		return collector, testOrphans, nil
+	}
+	harvestMode := func(*state.State) (config.HarvestMode, error) {
+		return mode, nil
+	}
+	w := orphancollector.NewCollectWorker(
+		&state.State{},
+		coretesting.ShortWait,
+		func(time.Duration) worker.PeriodicTimer { return timer },
+		find,
+		harvestMode,
+	)
+	c.Assert(timer.fire(), jc.ErrorIsNil)
+	// The second run starts only once the first has completed.
+	c.Assert(timer.fire(), jc.ErrorIsNil)
+	w.Kill()
+	c.Assert(w.Wait(), jc.ErrorIsNil)
+	c.Assert(timer.period, gc.Equals, coretesting.ShortWait)
+	return collector
+}
+
+func (s *orphanCollectorSuite) TestRemovesOrphans(c *gc.C) {
+	collector := s.runTwice(c, config.HarvestAll, nil)
+	c.Assert(collector.removed, jc.DeepEquals, append(testOrphans, testOrphans...))
+}
+
+func (s *orphanCollectorSuite) TestReportsOrphans(c *gc.C) {
+	collector := s.runTwice(c, config.HarvestDestroyed, nil)
+	c.Assert(collector.removed, gc.HasLen, 0)
+}
+
+func (s *orphanCollectorSuite) TestNotSupported(c *gc.C) {
+	collector := s.runTwice(c, config.HarvestAll, errors.NotSupportedf("dummy provider orphan collection"))
+	c.Assert(collector.removed, gc.HasLen, 0)
+}
@@ -579,6 +579,9 @@ func constructStartInstanceParams(
 		DistributionGroup: machine.DistributionGroup,
 		Volumes:           volumes,
 		SubnetsToZones:    subnetsToZones,
+		StatusCallback: func(info string, data map[string]interface{}) error {
+			return machine.SetStatus(params.StatusPending, info, data)
+		},
 	}, nil
 }
 
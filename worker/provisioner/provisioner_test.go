@@ -1490,6 +1490,33 @@ func (b *mockBroker) StartInstance(args environs.StartInstanceParams) (*environs
 	return nil, fmt.Errorf("error: some error")
 }
 
+// progressBroker reports progress before starting each instance.
+type progressBroker struct {
+	environs.Environ
+}
+
+func (b *progressBroker) StartInstance(args environs.StartInstanceParams) (*environs.StartInstanceResult, error) {
+	if err := args.StatusCallback("cloning root drive", nil); err != nil {
+		return nil, err
+	}
+	return b.Environ.StartInstance(args)
+}
+
+func (s *ProvisionerSuite) TestProvisionerReportsStartProgress(c *gc.C) {
+	broker := &progressBroker{Environ: s.Environ}
+	task := s.newProvisionerTask(c, config.HarvestAll, broker, s.provisioner, mockToolsFinder{})
+	defer stop(c, task)
+
+	m, err := s.addMachine()
+	c.Assert(err, jc.ErrorIsNil)
+	s.checkStartInstance(c, m)
+
+	statusInfo, err := m.Status()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(statusInfo.Status, gc.Equals, state.StatusPending)
+	c.Check(statusInfo.Message, gc.Equals, "cloning root drive")
+}
+
 type mockToolsFinder struct {
 }
 